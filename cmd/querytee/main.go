@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"time"
 
@@ -22,6 +23,10 @@ type Config struct {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(replay(os.Args[2:]))
+	}
+
 	// Parse CLI flags.
 	cfg := Config{}
 	flag.IntVar(&cfg.ServerMetricsPort, "server.metrics-port", 9900, "The port where metrics are exposed.")
@@ -58,21 +63,88 @@ func main() {
 	proxy.Await()
 }
 
+// replay re-issues the requests captured with -proxy.capture-mismatches-dir against the
+// backends and reports which responses still do not match. It returns the exit code.
+func replay(args []string) int {
+	var (
+		cfg = Config{}
+		dir string
+		fs  = flag.NewFlagSet("replay", flag.ExitOnError)
+	)
+	fs.StringVar(&dir, "replay.dir", "", "Directory containing the mismatches captured with -proxy.capture-mismatches-dir.")
+	cfg.LogLevel.RegisterFlags(fs)
+	cfg.ProxyConfig.RegisterFlags(fs)
+	_ = fs.Parse(args)
+
+	util_log.InitLogger(&server.Config{
+		LogLevel: cfg.LogLevel,
+	}, prometheus.DefaultRegisterer, false)
+
+	if dir == "" {
+		level.Error(util_log.Logger).Log("msg", "-replay.dir flag must be set")
+		return 1
+	}
+
+	replayer, err := querytee.NewReplayer(cfg.ProxyConfig, lokiReadRoutes(cfg), util_log.Logger)
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "Unable to initialize the replayer", "err", err.Error())
+		return 1
+	}
+
+	mismatches, err := querytee.ReadCapturedMismatches(dir)
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "Unable to read captured mismatches", "err", err.Error())
+		return 1
+	}
+
+	counts := map[string]int{}
+	for _, m := range mismatches {
+		results, err := replayer.Replay(m)
+		if err != nil {
+			level.Error(util_log.Logger).Log("msg", "Unable to replay request", "route", m.Route, "path", m.Request.Path, "query", m.Request.Query, "err", err.Error())
+			counts["error"]++
+			continue
+		}
+
+		for _, res := range results {
+			counts[res.Result]++
+			if res.Err != nil {
+				fmt.Printf("%s %s?%s backend=%s result=%s err=%q\n", res.Request.Method, res.Request.Path, res.Request.Query, res.Backend, res.Result, res.Err)
+				continue
+			}
+			fmt.Printf("%s %s?%s backend=%s result=%s\n", res.Request.Method, res.Request.Path, res.Request.Query, res.Backend, res.Result)
+		}
+	}
+
+	fmt.Printf("replayed %d requests: %d success, %d fail, %d skipped, %d errors\n", len(mismatches), counts["success"], counts["fail"], counts["skipped"], counts["error"])
+	if counts["fail"] > 0 || counts["error"] > 0 {
+		return 1
+	}
+	return 0
+}
+
 func lokiReadRoutes(cfg Config) []querytee.Route {
-	samplesComparator := querytee.NewSamplesComparator(querytee.SampleComparisonOptions{
+	opts := querytee.SampleComparisonOptions{
 		Tolerance:         cfg.ProxyConfig.ValueComparisonTolerance,
 		UseRelativeError:  cfg.ProxyConfig.UseRelativeError,
 		SkipRecentSamples: cfg.ProxyConfig.SkipRecentSamples,
 		SkipSamplesBefore: time.Time(cfg.ProxyConfig.SkipSamplesBefore),
-	})
+	}
+	samplesComparator := querytee.NewSamplesComparator(opts)
+	labelsComparator := querytee.NewLabelsComparator()
+	seriesComparator := querytee.NewSeriesComparator()
 
 	return []querytee.Route{
 		{Path: "/loki/api/v1/query_range", RouteName: "api_v1_query_range", Methods: []string{"GET"}, ResponseComparator: samplesComparator},
 		{Path: "/loki/api/v1/query", RouteName: "api_v1_query", Methods: []string{"GET"}, ResponseComparator: samplesComparator},
-		{Path: "/loki/api/v1/label", RouteName: "api_v1_label", Methods: []string{"GET"}, ResponseComparator: nil},
-		{Path: "/loki/api/v1/labels", RouteName: "api_v1_labels", Methods: []string{"GET"}, ResponseComparator: nil},
-		{Path: "/loki/api/v1/label/{name}/values", RouteName: "api_v1_label_name_values", Methods: []string{"GET"}, ResponseComparator: nil},
-		{Path: "/loki/api/v1/series", RouteName: "api_v1_series", Methods: []string{"GET"}, ResponseComparator: nil},
+		{Path: "/loki/api/v1/label", RouteName: "api_v1_label", Methods: []string{"GET"}, ResponseComparator: labelsComparator},
+		{Path: "/loki/api/v1/labels", RouteName: "api_v1_labels", Methods: []string{"GET"}, ResponseComparator: labelsComparator},
+		{Path: "/loki/api/v1/label/{name}/values", RouteName: "api_v1_label_name_values", Methods: []string{"GET"}, ResponseComparator: labelsComparator},
+		{Path: "/loki/api/v1/series", RouteName: "api_v1_series", Methods: []string{"GET"}, ResponseComparator: seriesComparator},
+		{Path: "/loki/api/v1/index/volume", RouteName: "api_v1_index_volume", Methods: []string{"GET"}, ResponseComparator: samplesComparator},
+		{Path: "/loki/api/v1/index/volume_range", RouteName: "api_v1_index_volume_range", Methods: []string{"GET"}, ResponseComparator: samplesComparator},
+		{Path: "/loki/api/v1/patterns", RouteName: "api_v1_patterns", Methods: []string{"GET"}, ResponseComparator: querytee.NewPatternsComparator(opts)},
+		{Path: "/loki/api/v1/detected_fields", RouteName: "api_v1_detected_fields", Methods: []string{"GET"}, ResponseComparator: querytee.NewDetectedFieldsComparator(opts)},
 		{Path: "/api/prom/query", RouteName: "api_prom_query", Methods: []string{"GET"}, ResponseComparator: samplesComparator},
 		{Path: "/api/prom/label", RouteName: "api_prom_label", Methods: []string{"GET"}, ResponseComparator: nil},
 		{Path: "/api/prom/label/{name}/values", RouteName: "api_prom_label_name_values", Methods: []string{"GET"}, ResponseComparator: nil},
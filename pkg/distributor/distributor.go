@@ -39,10 +39,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
 
 	"github.com/grafana/loki/v3/pkg/analytics"
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
 	"github.com/grafana/loki/v3/pkg/distributor/clientpool"
+	"github.com/grafana/loki/v3/pkg/distributor/redaction"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
 	"github.com/grafana/loki/v3/pkg/ingester"
@@ -100,6 +102,10 @@ type Config struct {
 
 	OTLPConfig push.GlobalOTLPConfig `yaml:"otlp_config"`
 
+	OTLPGRPCEnabled          bool `yaml:"otlp_grpc_enabled" category:"experimental"`
+	ElasticsearchBulkEnabled bool `yaml:"elasticsearch_bulk_enabled" category:"experimental"`
+	SplunkHECEnabled         bool `yaml:"splunk_hec_enabled" category:"experimental"`
+
 	KafkaEnabled              bool `yaml:"kafka_writes_enabled"`
 	IngesterEnabled           bool `yaml:"ingester_writes_enabled"`
 	IngestLimitsEnabled       bool `yaml:"ingest_limits_enabled"`
@@ -109,6 +115,10 @@ type Config struct {
 
 	// TODO: cleanup config
 	TenantTopic TenantTopicConfig `yaml:"tenant_topic" category:"experimental"`
+
+	WebhookTee WebhookTeeConfig `yaml:"webhook_tee" category:"experimental"`
+
+	WriteBuffer WriteBufferConfig `yaml:"write_buffer" category:"experimental"`
 }
 
 // RegisterFlags registers distributor-related flags.
@@ -118,10 +128,15 @@ func (cfg *Config) RegisterFlags(fs *flag.FlagSet) {
 	cfg.RateStore.RegisterFlagsWithPrefix("distributor.rate-store", fs)
 	cfg.WriteFailuresLogging.RegisterFlagsWithPrefix("distributor.write-failures-logging", fs)
 	cfg.TenantTopic.RegisterFlags(fs)
+	cfg.WebhookTee.RegisterFlags(fs)
+	cfg.WriteBuffer.RegisterFlags(fs)
 	fs.IntVar(&cfg.PushWorkerCount, "distributor.push-worker-count", 256, "Number of workers to push batches to ingesters.")
 	fs.BoolVar(&cfg.KafkaEnabled, "distributor.kafka-writes-enabled", false, "Enable writes to Kafka during Push requests.")
 	fs.BoolVar(&cfg.IngesterEnabled, "distributor.ingester-writes-enabled", true, "Enable writes to Ingesters during Push requests. Defaults to true.")
 	fs.BoolVar(&cfg.IngestLimitsEnabled, "distributor.ingest-limits-enabled", false, "Enable checking limits against the ingest-limits service. Defaults to false.")
+	fs.BoolVar(&cfg.OTLPGRPCEnabled, "distributor.otlp-grpc-enabled", false, "Enable the OTLP/gRPC logs service on the gRPC port of the distributor. Logs are mapped to log entries with the per-tenant otlp_config, as for the /otlp/v1/logs endpoint.")
+	fs.BoolVar(&cfg.ElasticsearchBulkEnabled, "distributor.elasticsearch-bulk-enabled", false, "Enable the Elasticsearch bulk API compatible push endpoint at /elasticsearch/_bulk. Documents are mapped to log entries with the per-tenant elasticsearch_bulk_config.")
+	fs.BoolVar(&cfg.SplunkHECEnabled, "distributor.splunk-hec-enabled", false, "Enable the Splunk HTTP Event Collector compatible push endpoint at /splunk/services/collector. Events are mapped to log entries with the per-tenant splunk_hec_config.")
 	fs.BoolVar(&cfg.IngestLimitsDryRunEnabled, "distributor.ingest-limits-dry-run-enabled", false, "Enable dry-run mode where limits are checked the ingest-limits service, but not enforced. Defaults to false.")
 }
 
@@ -132,6 +147,12 @@ func (cfg *Config) Validate() error {
 	if err := cfg.TenantTopic.Validate(); err != nil {
 		return errors.Wrap(err, "validating tenant topic config")
 	}
+	if err := cfg.WebhookTee.Validate(); err != nil {
+		return errors.Wrap(err, "validating webhook tee config")
+	}
+	if err := cfg.WriteBuffer.Validate(); err != nil {
+		return errors.Wrap(err, "validating write buffer config")
+	}
 	return nil
 }
 
@@ -159,6 +180,7 @@ type Distributor struct {
 	validator        *Validator
 	ingesterClients  *ring_client.Pool
 	tee              Tee
+	writeBuffer      *writeBuffer
 
 	rateStore    RateStore
 	shardTracker *ShardTracker
@@ -175,7 +197,12 @@ type Distributor struct {
 	subservicesWatcher *services.FailureWatcher
 	// Per-user rate limiter.
 	ingestionRateLimiter *limiter.RateLimiter
-	labelCache           *lru.Cache[string, labelData]
+	// policyIngestionRateLimiter enforces the ingestion rate limits of
+	// policies. It is keyed by tenant and policy.
+	policyIngestionRateLimiter *policyRateLimiter
+	labelCache                 *lru.Cache[string, labelData]
+	// redactors caches the compiled PII redaction rules of the tenants.
+	redactors *redaction.Redactors
 
 	// Push failures rate limiter.
 	writeFailuresManager *writefailures.Manager
@@ -188,6 +215,8 @@ type Distributor struct {
 	replicationFactor                     prometheus.Gauge
 	streamShardCount                      prometheus.Counter
 	tenantPushSanitizedStructuredMetadata *prometheus.CounterVec
+	ingestPipelineDroppedLines            *prometheus.CounterVec
+	redactedValues                        *prometheus.CounterVec
 
 	usageTracker   push.UsageTracker
 	ingesterTasks  chan pushIngesterTask
@@ -253,7 +282,7 @@ func New(
 	limitsFrontendClientFactory := limits_frontend_client.NewPoolFactory(limitsFrontendCfg)
 
 	// Create the configured ingestion rate limit strategy (local or global).
-	var ingestionRateStrategy limiter.RateLimiterStrategy
+	var ingestionRateStrategy, policyIngestionRateStrategy limiter.RateLimiterStrategy
 	var distributorsLifecycler *ring.BasicLifecycler
 	var distributorsRing *ring.Ring
 
@@ -289,6 +318,12 @@ func New(
 		}
 	}
 
+	if cfg.WebhookTee.Enabled {
+		w := NewWebhookTee(cfg.WebhookTee, overrides, registerer, logger)
+		tee = WrapTee(tee, w)
+		servs = append(servs, w)
+	}
+
 	d := &Distributor{
 		cfg:                   cfg,
 		ingesterCfg:           ingesterCfg,
@@ -301,6 +336,7 @@ func New(
 		ingesterClients:       clientpool.NewPool("ingester", clientCfg.PoolConfig, ingestersRing, ingesterClientFactory, logger, metricsNamespace),
 		labelCache:            labelCache,
 		shardTracker:          NewShardTracker(),
+		redactors:             redaction.NewRedactors(),
 		healthyInstancesCount: atomic.NewUint32(0),
 		rateLimitStrat:        rateLimitStrat,
 		tee:                   tee,
@@ -331,6 +367,16 @@ func New(
 			Name:      "distributor_push_structured_metadata_sanitized_total",
 			Help:      "The total number of times we've had to sanitize structured metadata (names or values) at ingestion time per tenant.",
 		}, []string{"tenant"}),
+		ingestPipelineDroppedLines: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_ingest_pipeline_dropped_lines_total",
+			Help:      "The total number of lines dropped by the ingest pipeline per tenant, either because their stream was dropped by relabeling or because they matched a drop lines expression.",
+		}, []string{"tenant", "reason"}),
+		redactedValues: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_redacted_values_total",
+			Help:      "The total number of sensitive values redacted from the lines and structured metadata of the pushed entries per tenant and redaction rule.",
+		}, []string{"tenant", "rule"}),
 		kafkaAppends: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
 			Namespace: constants.Loki,
 			Name:      "distributor_kafka_appends_total",
@@ -381,11 +427,14 @@ func New(
 		servs = append(servs, distributorsLifecycler, distributorsRing)
 
 		ingestionRateStrategy = newGlobalIngestionRateStrategy(overrides, d)
+		policyIngestionRateStrategy = newGlobalPolicyIngestionRateStrategy(overrides, d)
 	} else {
 		ingestionRateStrategy = newLocalIngestionRateStrategy(overrides)
+		policyIngestionRateStrategy = newLocalPolicyIngestionRateStrategy(overrides)
 	}
 
 	d.ingestionRateLimiter = limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second)
+	d.policyIngestionRateLimiter = newPolicyRateLimiter(policyIngestionRateStrategy, 10*time.Second)
 	d.distributorsRing = distributorsRing
 	d.distributorsLifecycler = distributorsLifecycler
 
@@ -409,6 +458,11 @@ func New(
 	d.rateStore = rs
 
 	servs = append(servs, d.ingesterClients, rs)
+
+	if cfg.WriteBuffer.Enabled {
+		d.writeBuffer = newWriteBuffer(cfg.WriteBuffer, overrides, d.writeStreams, registerer, logger)
+		servs = append(servs, d.writeBuffer)
+	}
 	d.subservices, err = services.NewManager(servs...)
 	if err != nil {
 		return nil, errors.Wrap(err, "services manager")
@@ -453,6 +507,9 @@ type KeyedStream struct {
 	HashKey        uint32
 	HashKeyNoShard uint64
 	Stream         logproto.Stream
+	// Policy is the ingestion policy of the stream, or empty if the stream
+	// does not match any policy.
+	Policy string
 }
 
 // TODO taken from Cortex, see if we can refactor out an usable interface.
@@ -517,14 +574,22 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 // Push a set of streams.
 // The returned error is the last one seen.
 func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRequest, streamResolver *requestScopedStreamResolver) (*logproto.PushResponse, error) {
+	resp, _, err := d.pushWithResolver(ctx, req, streamResolver)
+	return resp, err
+}
+
+// pushWithResolver pushes a set of streams and also returns the number of
+// entries accepted, which is non-zero if the request was only partially
+// rejected by validation.
+func (d *Distributor) pushWithResolver(ctx context.Context, req *logproto.PushRequest, streamResolver *requestScopedStreamResolver) (*logproto.PushResponse, int, error) {
 	tenantID, err := tenant.TenantID(ctx)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// Return early if request does not contain any streams
 	if len(req.Streams) == 0 {
-		return &logproto.PushResponse{}, httpgrpc.Errorf(http.StatusUnprocessableEntity, validation.MissingStreamsErrorMsg)
+		return &logproto.PushResponse{}, 0, httpgrpc.Errorf(http.StatusUnprocessableEntity, validation.MissingStreamsErrorMsg)
 	}
 
 	// First we flatten out the request into a list of samples.
@@ -536,38 +601,56 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 
 	now := time.Now()
 	validationContext := d.validator.getValidationContextForTime(now, tenantID)
+	tracker := newEntryTracker(ctx, req.Streams)
+	if pipeline := validationContext.ingestPipeline; pipeline != nil && !pipeline.IsEmpty() {
+		req.Streams = d.applyIngestPipeline(tenantID, pipeline, req.Streams, tracker)
+	}
+	if redactionCfg := validationContext.piiRedaction; redactionCfg != nil && len(redactionCfg.Rules) > 0 {
+		redactor, err := d.redactors.For(tenantID, redactionCfg)
+		if err != nil {
+			// Fail closed: the entries must not be stored without their sensitive values redacted.
+			level.Error(d.logger).Log("msg", "failed to compile the PII redaction rules", "tenant", tenantID, "err", err)
+			return &logproto.PushResponse{}, 0, httpgrpc.Errorf(http.StatusInternalServerError, "failed to compile the PII redaction rules: %s", err)
+		}
+		d.redactStreams(tenantID, redactor, req.Streams)
+	}
 	fieldDetector := newFieldDetector(validationContext)
 	shouldDiscoverLevels := fieldDetector.shouldDiscoverLogLevels()
 	shouldDiscoverGenericFields := fieldDetector.shouldDiscoverGenericFields()
+	shouldApplyFieldDetectionRules := fieldDetector.shouldApplyRules()
 
 	shardStreamsCfg := d.validator.Limits.ShardStreams(tenantID)
-	maybeShardByRate := func(stream logproto.Stream, pushSize int) {
+	maybeShardByRate := func(stream logproto.Stream, pushSize int, policy string) {
 		if shardStreamsCfg.Enabled {
-			streams = append(streams, d.shardStream(stream, pushSize, tenantID)...)
+			for _, s := range d.shardStream(stream, pushSize, tenantID) {
+				s.Policy = policy
+				streams = append(streams, s)
+			}
 			return
 		}
 		streams = append(streams, KeyedStream{
 			HashKey:        lokiring.TokenFor(tenantID, stream.Labels),
 			HashKeyNoShard: stream.Hash,
 			Stream:         stream,
+			Policy:         policy,
 		})
 	}
 
-	maybeShardStreams := func(stream logproto.Stream, labels labels.Labels, pushSize int) {
+	maybeShardStreams := func(stream logproto.Stream, labels labels.Labels, pushSize int, policy string) {
 		if !shardStreamsCfg.TimeShardingEnabled {
-			maybeShardByRate(stream, pushSize)
+			maybeShardByRate(stream, pushSize, policy)
 			return
 		}
 
 		ignoreRecentFrom := now.Add(-shardStreamsCfg.TimeShardingIgnoreRecent)
 		streamsByTime, ok := shardStreamByTime(stream, labels, d.ingesterCfg.MaxChunkAge/2, ignoreRecentFrom)
 		if !ok {
-			maybeShardByRate(stream, pushSize)
+			maybeShardByRate(stream, pushSize, policy)
 			return
 		}
 
 		for _, ts := range streamsByTime {
-			maybeShardByRate(ts.Stream, ts.linesTotalLen)
+			maybeShardByRate(ts.Stream, ts.linesTotalLen, policy)
 		}
 	}
 
@@ -582,14 +665,14 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 			}()
 		}
 
-		for _, stream := range req.Streams {
+		for i, stream := range req.Streams {
 			// Return early if stream does not contain any entries
 			if len(stream.Entries) == 0 {
 				continue
 			}
 
 			// Truncate first so subsequent steps have consistent line lengths
-			d.truncateLines(validationContext, &stream)
+			d.truncateLines(validationContext, &stream, streamResolver)
 
 			var lbs labels.Labels
 			var retentionHours, policy string
@@ -597,6 +680,7 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 			if err != nil {
 				d.writeFailuresManager.Log(tenantID, err)
 				validationErrors.Add(err)
+				tracker.rejectStream(i, err)
 				discardedBytes := util.EntriesTotalSize(stream.Entries)
 				d.validator.reportDiscardedDataWithTracker(ctx, validation.InvalidLabels, validationContext, lbs, retentionHours, policy, discardedBytes, len(stream.Entries))
 				continue
@@ -607,6 +691,7 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 					err := fmt.Errorf(validation.MissingEnforcedLabelsErrorMsg, strings.Join(lbsMissing, ","), tenantID, stream.Labels)
 					d.writeFailuresManager.Log(tenantID, err)
 					validationErrors.Add(err)
+					tracker.rejectStream(i, err)
 					discardedBytes := util.EntriesTotalSize(stream.Entries)
 					d.validator.reportDiscardedDataWithTracker(ctx, validation.MissingEnforcedLabels, validationContext, lbs, retentionHours, policy, discardedBytes, len(stream.Entries))
 					continue
@@ -621,23 +706,31 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 				// If the status code is 200, return no error.
 				// Note that we still log the error and increment the metrics.
 				if statusCode == http.StatusOK {
+					tracker.dropStream(i)
 					continue
 				}
 
 				// return an error but do not add it to validationErrors
 				// otherwise client will get a 400 and will log it.
 				ingestionBlockedError = httpgrpc.Errorf(statusCode, "%s", err.Error())
+				tracker.rejectStream(i, ingestionBlockedError)
 				continue
 			}
 
+			// The maximum line size of the policy of the stream takes precedence
+			// over the maximum line size of the tenant.
+			streamValidationContext := validationContext
+			streamValidationContext.maxLineSize = d.validator.maxLineSizeForPolicy(validationContext, policy)
+
 			n := 0
 			pushSize := 0
 			prevTs := stream.Entries[0].Timestamp
 
-			for _, entry := range stream.Entries {
-				if err := d.validator.ValidateEntry(ctx, validationContext, lbs, entry, retentionHours, policy); err != nil {
+			for j, entry := range stream.Entries {
+				if err := d.validator.ValidateEntry(ctx, streamValidationContext, lbs, entry, retentionHours, policy); err != nil {
 					d.writeFailuresManager.Log(tenantID, err)
 					validationErrors.Add(err)
+					tracker.reject(i, j, err)
 					continue
 				}
 
@@ -654,7 +747,14 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 						d.tenantPushSanitizedStructuredMetadata.WithLabelValues(tenantID).Inc()
 					}
 				}
-				if shouldDiscoverLevels {
+				var ruleFields []logproto.LabelAdapter
+				if shouldApplyFieldDetectionRules {
+					pprof.Do(ctx, pprof.Labels("action", "apply_field_detection_rules"), func(_ context.Context) {
+						ruleFields = fieldDetector.extractRuleFields(lbs, structuredMetadata, entry)
+						entry.StructuredMetadata = append(entry.StructuredMetadata, ruleFields...)
+					})
+				}
+				if shouldDiscoverLevels && !hasLabelAdapter(ruleFields, constants.LevelLabel) {
 					pprof.Do(ctx, pprof.Labels("action", "discover_log_level"), func(_ context.Context) {
 						logLevel, ok := fieldDetector.extractLogLevel(lbs, structuredMetadata, entry)
 						if ok {
@@ -665,6 +765,9 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 				if shouldDiscoverGenericFields {
 					pprof.Do(ctx, pprof.Labels("action", "discover_generic_fields"), func(_ context.Context) {
 						for field, hints := range fieldDetector.validationContext.discoverGenericFields {
+							if hasLabelAdapter(ruleFields, field) {
+								continue
+							}
 							extracted, ok := fieldDetector.extractGenericField(field, hints, lbs, structuredMetadata, entry)
 							if ok {
 								entry.StructuredMetadata = append(entry.StructuredMetadata, extracted)
@@ -699,7 +802,7 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 				continue
 			}
 
-			maybeShardStreams(stream, lbs, pushSize)
+			maybeShardStreams(stream, lbs, pushSize, policy)
 		}
 	}()
 
@@ -713,7 +816,7 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 
 	// Return early if none of the streams contained entries
 	if len(streams) == 0 {
-		return &logproto.PushResponse{}, validationErr
+		return &logproto.PushResponse{}, validationContext.validationMetrics.aggregatedPushStats.lineCount, validationErr
 	}
 
 	if d.cfg.IngestLimitsEnabled {
@@ -724,18 +827,27 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 			if d.cfg.IngestLimitsDryRunEnabled {
 				level.Debug(d.logger).Log("msg", "request exceeded limits", "tenant", tenantID)
 			} else {
-				return nil, httpgrpc.Error(http.StatusBadRequest, strings.Join(reasons, ","))
+				return nil, 0, httpgrpc.Error(http.StatusBadRequest, strings.Join(reasons, ","))
 			}
 		}
 	}
 
+	policyReservations, err := d.reservePolicyRateLimits(ctx, req, validationContext, tenantID, now, streamResolver)
+	if err != nil {
+		d.writeFailuresManager.Log(tenantID, err)
+		// Return a 429 to indicate to the client they are being rate limited
+		return nil, 0, httpgrpc.Errorf(http.StatusTooManyRequests, "%s", err.Error())
+	}
+
 	if !d.ingestionRateLimiter.AllowN(now, tenantID, validationContext.validationMetrics.aggregatedPushStats.lineSize) {
+		// The rejected push does not consume the ingestion rate of its policies.
+		cancelReservations(now, policyReservations)
 		d.trackDiscardedData(ctx, req, validationContext, tenantID, validationContext.validationMetrics, validation.RateLimited, streamResolver)
 
 		err = fmt.Errorf(validation.RateLimitedErrorMsg, tenantID, int(d.ingestionRateLimiter.Limit(now, tenantID)), validationContext.validationMetrics.aggregatedPushStats.lineCount, validationContext.validationMetrics.aggregatedPushStats.lineSize)
 		d.writeFailuresManager.Log(tenantID, err)
 		// Return a 429 to indicate to the client they are being rate limited
-		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, "%s", err.Error())
+		return nil, 0, httpgrpc.Errorf(http.StatusTooManyRequests, "%s", err.Error())
 	}
 
 	// Nil check for performance reasons, to avoid dynamic lookup and/or no-op
@@ -744,6 +856,25 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 		d.tee.Duplicate(tenantID, streams)
 	}
 
+	if d.writeBuffer != nil && d.writeBuffer.Buffering(tenantID) {
+		// Pushes of the tenant are buffered: the push is written after them, so that the
+		// pushes of the tenant are written in order.
+		if err := d.writeBuffer.Append(tenantID, streams); err != nil {
+			level.Warn(d.logger).Log("msg", "failed to buffer push", "tenant", tenantID, "err", err)
+			return nil, 0, httpgrpc.Errorf(http.StatusServiceUnavailable, "%s", err.Error())
+		}
+	} else if unwritten, err := d.writeStreams(ctx, tenantID, streams); err != nil {
+		if !d.bufferStreams(ctx, tenantID, unwritten, err) {
+			return nil, 0, err
+		}
+	}
+	return &logproto.PushResponse{}, validationContext.validationMetrics.aggregatedPushStats.lineCount, validationErr
+}
+
+// writeStreams writes the streams to the ingesters and to Kafka, and waits until the
+// writes succeed. If a write fails, it also returns the streams which were not written
+// yet. Some of them may still be written by the writes in progress.
+func (d *Distributor) writeStreams(ctx context.Context, tenantID string, streams []KeyedStream) ([]KeyedStream, error) {
 	const maxExpectedReplicationSet = 5 // typical replication factor 3 plus one for inactive plus one for luck
 	var descs [maxExpectedReplicationSet]ring.InstanceDesc
 
@@ -761,17 +892,22 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 	// We must correctly set streamsPending before beginning any writes to ensure we don't have a race between finishing all of one path before starting the other.
 	tracker.streamsPending.Store(int32(streamsToWrite))
 
+	var (
+		streamTrackers []streamTracker
+		kafkaWritten   []atomic.Bool
+	)
 	if d.cfg.KafkaEnabled {
 		subring, err := d.partitionRing.PartitionRing().ShuffleShard(tenantID, d.validator.IngestionPartitionsTenantShardSize(tenantID))
 		if err != nil {
-			return nil, err
+			return streams, err
 		}
+		kafkaWritten = make([]atomic.Bool, len(streams))
 		// We don't need to create a new context like the ingester writes, because we don't return unless all writes have succeeded.
-		d.sendStreamsToKafka(ctx, streams, tenantID, &tracker, subring)
+		d.sendStreamsToKafka(ctx, streams, tenantID, &tracker, subring, kafkaWritten)
 	}
 
 	if d.cfg.IngesterEnabled {
-		streamTrackers := make([]streamTracker, len(streams))
+		streamTrackers = make([]streamTracker, len(streams))
 		streamsByIngester := map[string][]*streamTracker{}
 		ingesterDescs := map[string]ring.InstanceDesc{}
 
@@ -802,7 +938,7 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 			}
 			return nil
 		}(); err != nil {
-			return nil, err
+			return streams, err
 		}
 
 		for ingester, streams := range streamsByIngester {
@@ -832,11 +968,18 @@ func (d *Distributor) PushWithResolver(ctx context.Context, req *logproto.PushRe
 
 	select {
 	case err := <-tracker.err:
-		return nil, err
+		var unwritten []KeyedStream
+		for i, stream := range streams {
+			if streamTrackers != nil && streamTrackers[i].succeeded.Load() < int32(streamTrackers[i].minSuccess) ||
+				kafkaWritten != nil && !kafkaWritten[i].Load() {
+				unwritten = append(unwritten, stream)
+			}
+		}
+		return unwritten, err
 	case <-tracker.done:
-		return &logproto.PushResponse{}, validationErr
+		return nil, nil
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return streams, ctx.Err()
 	}
 }
 
@@ -872,6 +1015,49 @@ func (d *Distributor) missingEnforcedLabels(lbs labels.Labels, tenantID string,
 	return len(missingLbs) > 0, missingLbs
 }
 
+// reservePolicyRateLimits reserves the ingestion rate of the policies of the streams
+// in the request, and returns an error if the streams of a policy exceed its rate
+// limit. In that case, the rate reserved for the other policies is given back.
+// Policies without a rate limit are only subject to the ingestion rate limit of
+// the tenant.
+func (d *Distributor) reservePolicyRateLimits(
+	ctx context.Context,
+	req *logproto.PushRequest,
+	validationContext validationContext,
+	tenantID string,
+	now time.Time,
+	streamResolver push.StreamResolver,
+) ([]*rate.Reservation, error) {
+	policies := make([]string, 0, len(validationContext.validationMetrics.policyPushStats))
+	for policy := range validationContext.validationMetrics.policyPushStats {
+		policies = append(policies, policy)
+	}
+	sort.Strings(policies)
+
+	var reservations []*rate.Reservation
+	for _, policy := range policies {
+		if d.validator.PolicyIngestionRateBytes(tenantID, policy) <= 0 {
+			continue
+		}
+
+		var stats pushStats
+		for _, s := range validationContext.validationMetrics.policyPushStats[policy] {
+			stats.lineSize += s.lineSize
+			stats.lineCount += s.lineCount
+		}
+
+		key := policyRateLimiterKey(tenantID, policy)
+		r, ok := d.policyIngestionRateLimiter.ReserveN(now, key, stats.lineSize)
+		if !ok {
+			cancelReservations(now, reservations)
+			d.trackDiscardedData(ctx, req, validationContext, tenantID, validationContext.validationMetrics, validation.PolicyRateLimited, streamResolver)
+			return nil, fmt.Errorf(validation.PolicyRateLimitedErrorMsg, policy, tenantID, int(d.policyIngestionRateLimiter.Limit(now, key)), stats.lineCount, stats.lineSize)
+		}
+		reservations = append(reservations, r)
+	}
+	return reservations, nil
+}
+
 func (d *Distributor) trackDiscardedData(
 	ctx context.Context,
 	req *logproto.PushRequest,
@@ -1107,14 +1293,17 @@ func maxT(t1, t2 time.Time) time.Time {
 	return t1
 }
 
-func (d *Distributor) truncateLines(vContext validationContext, stream *logproto.Stream) {
+// truncateLines truncates the lines of the stream to the maximum line size of its
+// policy, which takes precedence over the maximum line size of the tenant.
+func (d *Distributor) truncateLines(vContext validationContext, stream *logproto.Stream, streamResolver push.StreamResolver) {
 	if !vContext.maxLineSizeTruncate {
 		return
 	}
 
+	maxSize := d.validator.maxLineSizeForPolicy(vContext, d.policyForStream(stream.Labels, streamResolver))
 	var truncatedSamples, truncatedBytes int
 	for i, e := range stream.Entries {
-		if maxSize := vContext.maxLineSize; maxSize != 0 && len(e.Line) > maxSize {
+		if maxSize != 0 && len(e.Line) > maxSize {
 			stream.Entries[i].Line = e.Line[:maxSize]
 
 			truncatedSamples++
@@ -1252,6 +1441,7 @@ func (d *Distributor) doExceedsLimitsRPC(
 			StreamHash:             stream.HashKeyNoShard,
 			EntriesSize:            entriesSize,
 			StructuredMetadataSize: structuredMetadataSize,
+			Policy:                 stream.Policy,
 		})
 	}
 
@@ -1323,15 +1513,19 @@ func (d *Distributor) sendStreamsErr(ctx context.Context, ingester ring.Instance
 	return err
 }
 
-func (d *Distributor) sendStreamsToKafka(ctx context.Context, streams []KeyedStream, tenant string, tracker *pushTracker, subring *ring.PartitionRing) {
-	for _, s := range streams {
-		go func(s KeyedStream) {
+// sendStreamsToKafka writes the streams to Kafka, and records in written the streams
+// which are successfully written.
+func (d *Distributor) sendStreamsToKafka(ctx context.Context, streams []KeyedStream, tenant string, tracker *pushTracker, subring *ring.PartitionRing, written []atomic.Bool) {
+	for i, s := range streams {
+		go func(i int, s KeyedStream) {
 			err := d.sendStreamToKafka(ctx, s, tenant, subring)
 			if err != nil {
 				err = fmt.Errorf("failed to write stream to kafka: %w", err)
+			} else {
+				written[i].Store(true)
 			}
 			tracker.doneWithResult(err)
-		}(s)
+		}(i, s)
 	}
 }
 
@@ -1378,6 +1572,7 @@ func (d *Distributor) sendStreamToKafka(ctx context.Context, stream KeyedStream,
 		stream.HashKeyNoShard,
 		entriesSize,
 		structuredMetadataSize,
+		stream.Policy,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
@@ -1423,6 +1618,19 @@ type labelData struct {
 	hash uint64
 }
 
+// policyForStream returns the policy of the stream with the given labels. Streams with
+// invalid labels have no policy; they are rejected by parseStreamLabels.
+func (d *Distributor) policyForStream(key string, streamResolver push.StreamResolver) string {
+	if val, ok := d.labelCache.Get(key); ok {
+		return streamResolver.PolicyFor(val.ls)
+	}
+	ls, err := syntax.ParseLabels(key)
+	if err != nil {
+		return ""
+	}
+	return streamResolver.PolicyFor(ls)
+}
+
 // parseStreamLabels parses stream labels using a request-scoped policy resolver
 func (d *Distributor) parseStreamLabels(vContext validationContext, key string, stream logproto.Stream, streamResolver push.StreamResolver) (labels.Labels, string, uint64, string, string, error) {
 	if val, ok := d.labelCache.Get(key); ok {
@@ -723,6 +723,23 @@ func Test_TruncateLogLines(t *testing.T) {
 		topVal := ingester.Peek()
 		require.Len(t, topVal.Streams[0].Entries[0].Line, 5)
 	})
+
+	t.Run("it truncates lines to the max line size of the policy of the stream", func(t *testing.T) {
+		limits, ingester := setup()
+		limits.PolicyStreamMapping = validation.PolicyStreamMapping{
+			"verbose": []*validation.PriorityStream{{Selector: `{foo="bar"}`, Priority: 1}},
+		}
+		require.NoError(t, limits.PolicyStreamMapping.Validate())
+		limits.PolicyLimits = map[string]validation.PolicyLimits{
+			"verbose": {MaxLineSize: 8},
+		}
+		distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+		_, err := distributors[0].Push(ctx, makeWriteRequest(1, 10))
+		require.NoError(t, err)
+		topVal := ingester.Peek()
+		require.Len(t, topVal.Streams[0].Entries[0].Line, 8)
+	})
 }
 
 func Test_DiscardEmptyStreamsAfterValidation(t *testing.T) {
@@ -744,6 +761,24 @@ func Test_DiscardEmptyStreamsAfterValidation(t *testing.T) {
 		require.Nil(t, topVal)
 	})
 
+	t.Run("it discards entries exceeding the max line size of the policy of the stream", func(t *testing.T) {
+		limits, ingester := setup()
+		limits.MaxLineSize = 100
+		limits.PolicyStreamMapping = validation.PolicyStreamMapping{
+			"strict": []*validation.PriorityStream{{Selector: `{foo="bar"}`, Priority: 1}},
+		}
+		require.NoError(t, limits.PolicyStreamMapping.Validate())
+		limits.PolicyLimits = map[string]validation.PolicyLimits{
+			"strict": {MaxLineSize: 5},
+		}
+		distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+		_, err := distributors[0].Push(ctx, makeWriteRequest(1, 10))
+		require.Equal(t, err, httpgrpc.Errorf(http.StatusBadRequest, "%s", fmt.Sprintf(validation.LineTooLongErrorMsg, 5, "{foo=\"bar\"}", 10)))
+		topVal := ingester.Peek()
+		require.Nil(t, topVal)
+	})
+
 	t.Run("it returns unprocessable entity error if the streams is empty", func(t *testing.T) {
 		limits, ingester := setup()
 		distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
@@ -1669,6 +1704,57 @@ func TestDistributor_PushIngestionRateLimiter(t *testing.T) {
 	}
 }
 
+func TestDistributor_PushPolicyIngestionRateLimiter(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionRateMB = datasize.ByteSize(1000).MBytes()
+	limits.IngestionBurstSizeMB = datasize.ByteSize(1000).MBytes()
+	limits.PolicyStreamMapping = validation.PolicyStreamMapping{
+		"staging": []*validation.PriorityStream{{Selector: `{env="staging"}`, Priority: 1}},
+		"dev":     []*validation.PriorityStream{{Selector: `{env="dev"}`, Priority: 1}},
+	}
+	require.NoError(t, limits.PolicyStreamMapping.Validate())
+	limits.PolicyLimits = map[string]validation.PolicyLimits{
+		"staging": {
+			IngestionRateMB:      datasize.ByteSize(100).MBytes(),
+			IngestionBurstSizeMB: datasize.ByteSize(100).MBytes(),
+		},
+		"dev": {
+			IngestionRateMB:      datasize.ByteSize(100).MBytes(),
+			IngestionBurstSizeMB: datasize.ByteSize(100).MBytes(),
+		},
+	}
+
+	distributors, _ := prepare(t, 1, 5, limits, nil)
+
+	for _, push := range []struct {
+		streams       map[string]int
+		expectedError error
+	}{
+		{streams: map[string]int{`{env="staging"}`: 50}},
+		{streams: map[string]int{`{env="staging"}`: 60}, expectedError: httpgrpc.Errorf(http.StatusTooManyRequests, validation.PolicyRateLimitedErrorMsg, "staging", "test", 100, 1, 60)},
+		// Streams of other policies are only subject to the tenant rate limit.
+		{streams: map[string]int{`{env="prod"}`: 500}},
+		// Pushes rejected by the limit of another policy or of the tenant give back the rate of the policies.
+		{streams: map[string]int{`{env="staging"}`: 40, `{env="dev"}`: 120}, expectedError: httpgrpc.Errorf(http.StatusTooManyRequests, validation.PolicyRateLimitedErrorMsg, "dev", "test", 100, 1, 120)},
+		{streams: map[string]int{`{env="staging"}`: 40, `{env="prod"}`: 600}, expectedError: httpgrpc.Errorf(http.StatusTooManyRequests, validation.RateLimitedErrorMsg, "test", 1000, 2, 640)},
+		{streams: map[string]int{`{env="staging"}`: 50}},
+	} {
+		request := &logproto.PushRequest{}
+		for lbs, bytes := range push.streams {
+			request.Streams = append(request.Streams, makeWriteRequestWithLabels(1, bytes, []string{lbs}, false, false, false).Streams...)
+		}
+		response, err := distributors[0].Push(ctx, request)
+		if push.expectedError == nil {
+			require.NoError(t, err)
+			require.Equal(t, success, response)
+		} else {
+			require.Nil(t, response)
+			require.Equal(t, push.expectedError, err)
+		}
+	}
+}
+
 func TestDistributor_PushIngestionBlocked(t *testing.T) {
 	for _, tc := range []struct {
 		name               string
@@ -0,0 +1,90 @@
+package distributor
+
+import (
+	"context"
+
+	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// entryTracker records the outcome of the entries of a push request in the results
+// attached to its context, while the distributor transforms and validates the
+// streams of the request. All its methods are no-ops on a nil tracker.
+type entryTracker struct {
+	results *push.EntryResults
+	// positions[i][j] is the position in the parsed request of the entry j of the
+	// stream i of the request.
+	positions [][]push.EntryPosition
+}
+
+// newEntryTracker returns a tracker of the entries of the streams, or nil if the
+// context does not record the outcome of the entries.
+func newEntryTracker(ctx context.Context, streams []logproto.Stream) *entryTracker {
+	results := push.EntryResultsFromContext(ctx)
+	if results == nil {
+		return nil
+	}
+	positions := make([][]push.EntryPosition, len(streams))
+	for i, s := range streams {
+		positions[i] = make([]push.EntryPosition, len(s.Entries))
+		for j := range s.Entries {
+			positions[i][j] = push.EntryPosition{Stream: i, Entry: j}
+		}
+	}
+	return &entryTracker{results: results, positions: positions}
+}
+
+func (t *entryTracker) drop(stream, entry int) {
+	if t == nil {
+		return
+	}
+	t.results.Drop(t.positions[stream][entry])
+}
+
+func (t *entryTracker) dropStream(stream int) {
+	if t == nil {
+		return
+	}
+	for _, pos := range t.positions[stream] {
+		t.results.Drop(pos)
+	}
+}
+
+func (t *entryTracker) reject(stream, entry int, err error) {
+	if t == nil {
+		return
+	}
+	t.results.Reject(t.positions[stream][entry], err)
+}
+
+func (t *entryTracker) rejectStream(stream int, err error) {
+	if t == nil {
+		return
+	}
+	for _, pos := range t.positions[stream] {
+		t.results.Reject(pos, err)
+	}
+}
+
+// compact removes the dropped entries, and the streams left without entries, like
+// the ingest pipeline removes them from the streams of the request.
+func (t *entryTracker) compact() {
+	if t == nil {
+		return
+	}
+	n := 0
+	for _, positions := range t.positions {
+		kept := positions[:0]
+		for _, pos := range positions {
+			if !t.results.Result(pos).Dropped {
+				kept = append(kept, pos)
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		t.positions[n] = kept
+		n++
+	}
+	t.positions = t.positions[:n]
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logql/log/jsonexpr"
 	"github.com/grafana/loki/v3/pkg/logql/log/logfmt"
 	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/validation"
 )
 
 var (
@@ -68,6 +69,8 @@ type FieldDetector struct {
 	allowedLevelLabelsMap    map[string]struct{}
 	allowedLevelLabels       []string
 	logLevelFromJSONMaxDepth int
+	fieldDetection           *validation.FieldDetectionConfig
+	extractors               []*validation.FieldExtractor
 }
 
 func newFieldDetector(validationContext validationContext) *FieldDetector {
@@ -77,12 +80,19 @@ func newFieldDetector(validationContext validationContext) *FieldDetector {
 		allowedLevelLabelsMap[field] = struct{}{}
 	}
 
-	return &FieldDetector{
+	detector := &FieldDetector{
 		validationContext:        validationContext,
 		allowedLevelLabelsMap:    allowedLevelLabelsMap,
 		allowedLevelLabels:       allowedLevelLabels,
 		logLevelFromJSONMaxDepth: validationContext.logLevelFromJSONMaxDepth,
+		fieldDetection:           validationContext.fieldDetection,
 	}
+	if detector.fieldDetection != nil && validationContext.allowStructuredMetadata {
+		for i := range detector.fieldDetection.Rules {
+			detector.extractors = append(detector.extractors, detector.fieldDetection.Rules[i].NewExtractor())
+		}
+	}
+	return detector
 }
 
 func (l *FieldDetector) shouldDiscoverLogLevels() bool {
@@ -93,13 +103,71 @@ func (l *FieldDetector) shouldDiscoverGenericFields() bool {
 	return l.validationContext.allowStructuredMetadata && len(l.validationContext.discoverGenericFields) > 0
 }
 
+func (l *FieldDetector) shouldApplyRules() bool {
+	return len(l.extractors) > 0
+}
+
+// extractRuleFields returns the structured metadata extracted by the field detection rules
+// matching the stream. A rule is skipped when the entry already has structured metadata
+// with its name, or when a previous rule extracted it.
+func (l *FieldDetector) extractRuleFields(labels labels.Labels, structuredMetadata labels.Labels, entry logproto.Entry) []logproto.LabelAdapter {
+	var extracted []logproto.LabelAdapter
+	for _, e := range l.extractors {
+		rule := e.Rule()
+		if structuredMetadata.Has(rule.Name) || hasLabelAdapter(extracted, rule.Name) || !rule.Matches(labels) {
+			continue
+		}
+
+		source := entry.Line
+		if rule.Source != "" {
+			if labels.Has(rule.Source) {
+				source = labels.Get(rule.Source)
+			} else if structuredMetadata.Has(rule.Source) {
+				source = structuredMetadata.Get(rule.Source)
+			} else {
+				continue
+			}
+		}
+
+		value, ok := e.Extract(source)
+		if !ok {
+			continue
+		}
+		if rule.Name == constants.LevelLabel {
+			value = l.mapLogLevel(value)
+		}
+		extracted = append(extracted, logproto.LabelAdapter{Name: rule.Name, Value: value})
+	}
+	return extracted
+}
+
+func hasLabelAdapter(lbs []logproto.LabelAdapter, name string) bool {
+	for _, l := range lbs {
+		if l.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mapLogLevel applies the log level mapping of the tenant to a level value.
+func (l *FieldDetector) mapLogLevel(level string) string {
+	if l.fieldDetection == nil {
+		return level
+	}
+	if mapped, ok := l.fieldDetection.MapLogLevel(level); ok {
+		return mapped
+	}
+	return level
+}
+
 func (l *FieldDetector) extractLogLevel(labels labels.Labels, structuredMetadata labels.Labels, entry logproto.Entry) (logproto.LabelAdapter, bool) {
 	levelFromLabel, hasLevelLabel := labelsContainAny(labels, l.allowedLevelLabels)
 	var logLevel string
 	if hasLevelLabel {
-		logLevel = levelFromLabel
+		logLevel = l.mapLogLevel(levelFromLabel)
 	} else if levelFromMetadata, ok := labelsContainAny(structuredMetadata, l.allowedLevelLabels); ok {
-		logLevel = levelFromMetadata
+		logLevel = l.mapLogLevel(levelFromMetadata)
 	} else {
 		logLevel = l.detectLogLevelFromLogEntry(entry, structuredMetadata)
 	}
@@ -190,6 +258,12 @@ func (l *FieldDetector) extractLogLevelFromLogLine(log string) string {
 		return detectLevelFromLogLine(log)
 	}
 
+	if l.fieldDetection != nil && len(v) > 0 {
+		if mapped, ok := l.fieldDetection.MapLogLevel(string(v)); ok {
+			return mapped
+		}
+	}
+
 	switch {
 	case bytes.EqualFold(v, trace), bytes.EqualFold(v, traceAbbrv):
 		return constants.LogLevelTrace
@@ -3,6 +3,7 @@ package distributor
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/grafana/dskit/flagext"
 	ring_client "github.com/grafana/dskit/ring/client"
@@ -745,3 +746,75 @@ func TestGetLevelUsingJsonParser(t *testing.T) {
 		})
 	}
 }
+
+func Test_FieldDetectionRules(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.RejectOldSamples = false
+	limits.DiscoverServiceName = nil
+	limits.DiscoverLogLevels = true
+	limits.AllowStructuredMetadata = true
+	limits.FieldDetection = validation.FieldDetectionConfig{
+		LogLevelMapping: map[string]string{"W": constants.LogLevelWarn},
+		Rules: []validation.FieldDetectionRule{
+			{Name: "trace_id", Regex: `trace_id=(\w+)`},
+			{Name: "http_status", Selector: `{app="nginx"}`, Pattern: `<_> "<_>" <value> <_>`},
+			{Name: constants.LevelLabel, Source: "severity", Regex: `^(\d)$`, ValueMapping: map[string]string{"3": "E"}},
+		},
+	}
+	limits.FieldDetection.LogLevelMapping["E"] = constants.LogLevelError
+	require.NoError(t, limits.Validate())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	ts := time.Unix(1, 0)
+	_, err := distributors[0].Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{
+		{
+			Labels: `{app="nginx"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: ts, Line: `10.0.0.1 "GET /index.html" 404 512`},
+			},
+		},
+		{
+			Labels: `{app="api"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: ts, Line: `level=W msg="slow request" trace_id=abc123`},
+				{Timestamp: ts.Add(time.Second), Line: "request failed", StructuredMetadata: []logproto.LabelAdapter{{Name: "severity", Value: "3"}, {Name: "trace_id", Value: "def456"}}},
+			},
+		},
+	}})
+	require.NoError(t, err)
+
+	// The streams are replicated and may be pushed to the ingester with separate requests.
+	ingester.mu.Lock()
+	defer ingester.mu.Unlock()
+	streams := map[string]logproto.Stream{}
+	for _, req := range ingester.pushed {
+		for _, s := range req.Streams {
+			streams[s.Labels] = s
+		}
+	}
+	require.Len(t, streams, 2)
+	for _, s := range streams {
+		switch s.Labels {
+		case `{app="nginx"}`:
+			require.Equal(t, push.LabelsAdapter{
+				{Name: "http_status", Value: "404"},
+				{Name: constants.LevelLabel, Value: constants.LogLevelUnknown},
+			}, s.Entries[0].StructuredMetadata)
+		case `{app="api"}`:
+			require.Equal(t, push.LabelsAdapter{
+				{Name: "trace_id", Value: "abc123"},
+				{Name: constants.LevelLabel, Value: constants.LogLevelWarn},
+			}, s.Entries[0].StructuredMetadata)
+			require.Equal(t, push.LabelsAdapter{
+				{Name: "severity", Value: "3"},
+				{Name: "trace_id", Value: "def456"},
+				{Name: constants.LevelLabel, Value: constants.LogLevelError},
+			}, s.Entries[1].StructuredMetadata)
+		default:
+			t.Fatalf("unexpected stream %s", s.Labels)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/httpgrpc"
 
@@ -14,20 +15,37 @@ import (
 	"github.com/grafana/dskit/tenant"
 
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	"github.com/grafana/loki/v3/pkg/logproto"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 	"github.com/grafana/loki/v3/pkg/validation"
 )
 
 // PushHandler reads a snappy-compressed proto from the HTTP body.
 func (d *Distributor) PushHandler(w http.ResponseWriter, r *http.Request) {
-	d.pushHandler(w, r, push.ParseLokiRequest, push.HTTPError)
+	d.pushHandler(w, r, push.ParseLokiRequest, push.NoContentResponse, push.HTTPError)
 }
 
 func (d *Distributor) OTLPPushHandler(w http.ResponseWriter, r *http.Request) {
-	d.pushHandler(w, r, push.ParseOTLPRequest, push.OTLPError)
+	d.pushHandler(w, r, push.ParseOTLPRequest, push.NoContentResponse, push.OTLPError)
 }
 
-func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, pushRequestParser push.RequestParser, errorWriter push.ErrorWriter) {
+// ElasticsearchBulkPushHandler accepts requests of the Elasticsearch bulk API, and
+// reports the outcome of each of their documents.
+func (d *Distributor) ElasticsearchBulkPushHandler(w http.ResponseWriter, r *http.Request) {
+	results := push.NewEntryResults()
+	r = r.WithContext(push.InjectEntryResults(r.Context(), results))
+	successWriter := func(w http.ResponseWriter, _ *logproto.PushRequest, logger log.Logger) {
+		push.ElasticsearchBulkResponse(w, results, logger)
+	}
+	d.pushHandler(w, r, push.ParseElasticsearchBulkRequest, successWriter, push.ElasticsearchError)
+}
+
+// SplunkHECPushHandler accepts events sent to the Splunk HTTP Event Collector.
+func (d *Distributor) SplunkHECPushHandler(w http.ResponseWriter, r *http.Request) {
+	d.pushHandler(w, r, push.ParseSplunkHECRequest, push.SplunkHECResponse, push.SplunkHECError)
+}
+
+func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, pushRequestParser push.RequestParser, successWriter push.SuccessWriter, errorWriter push.ErrorWriter) {
 	logger := util_log.WithContext(r.Context(), util_log.Logger)
 	tenantID, err := tenant.TenantID(r.Context())
 	if err != nil {
@@ -66,7 +84,7 @@ func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, pushRe
 				"msg", "successful push request filtered all lines",
 			)
 		}
-		w.WriteHeader(http.StatusNoContent)
+		successWriter(w, nil, logger)
 		return
 	}
 
@@ -81,14 +99,26 @@ func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, pushRe
 		)
 	}
 
-	_, err = d.PushWithResolver(r.Context(), req, streamResolver)
+	pushResp, err := d.PushWithResolver(r.Context(), req, streamResolver)
+	// Handlers recording the outcome of each entry report the entries rejected by the
+	// validation in the response of the requests which were partially accepted.
+	if results := push.EntryResultsFromContext(r.Context()); err != nil && pushResp != nil && results != nil && results.Rejected() {
+		if d.tenantConfigs.LogPushRequest(tenantID) {
+			level.Debug(logger).Log(
+				"msg", "push request partially rejected",
+				"err", err,
+			)
+		}
+		successWriter(w, req, logger)
+		return
+	}
 	if err == nil {
 		if d.tenantConfigs.LogPushRequest(tenantID) {
 			level.Debug(logger).Log(
 				"msg", "push request successful",
 			)
 		}
-		w.WriteHeader(http.StatusNoContent)
+		successWriter(w, req, logger)
 		return
 	}
 
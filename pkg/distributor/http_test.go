@@ -2,9 +2,11 @@ package distributor
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-kit/log"
@@ -14,6 +16,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logproto"
 
 	"github.com/grafana/dskit/flagext"
+	ring_client "github.com/grafana/dskit/ring/client"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/validation"
@@ -80,7 +83,7 @@ func TestRequestParserWrapping(t *testing.T) {
 		require.NoError(t, err)
 
 		rec := httptest.NewRecorder()
-		distributors[0].pushHandler(rec, req, newFakeParser().parseRequest, push.HTTPError)
+		distributors[0].pushHandler(rec, req, newFakeParser().parseRequest, push.NoContentResponse, push.HTTPError)
 
 		// unprocessable code because there are no streams in the request.
 		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
@@ -107,7 +110,7 @@ func TestRequestParserWrapping(t *testing.T) {
 		parser.parseErr = push.ErrAllLogsFiltered
 
 		rec := httptest.NewRecorder()
-		distributors[0].pushHandler(rec, req, parser.parseRequest, push.HTTPError)
+		distributors[0].pushHandler(rec, req, parser.parseRequest, push.NoContentResponse, push.HTTPError)
 
 		require.True(t, called)
 		require.Equal(t, http.StatusNoContent, rec.Code)
@@ -133,3 +136,108 @@ func (p *fakeParser) parseRequest(
 ) (*logproto.PushRequest, *push.Stats, error) {
 	return &logproto.PushRequest{}, &push.Stats{}, p.parseErr
 }
+
+func TestElasticsearchBulkAndSplunkHECPushHandlers(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		handler        func(d *Distributor) http.HandlerFunc
+		limits         func(l *validation.Limits)
+		body           string
+		expectedCode   int
+		expectedBody   string
+		expectedLabels string
+		expectedLines  []string
+	}{
+		{
+			name:           "elasticsearch bulk",
+			handler:        func(d *Distributor) http.HandlerFunc { return d.ElasticsearchBulkPushHandler },
+			body:           "{\"create\":{\"_index\":\"logs-app\"}}\n{\"@timestamp\":\"2024-01-01T00:00:00Z\",\"message\":\"foo\"}\n",
+			expectedCode:   http.StatusOK,
+			expectedBody:   `{"errors":false,"items":[{"create":{"result":"created","status":201}}],"took":0}`,
+			expectedLabels: `{index="logs-app"}`,
+		},
+		{
+			name:    "elasticsearch bulk with dropped and rejected documents",
+			handler: func(d *Distributor) http.HandlerFunc { return d.ElasticsearchBulkPushHandler },
+			limits: func(l *validation.Limits) {
+				l.MaxLineSize = 10
+				l.IngestPipeline.DropLines = []string{`|= "debug"`}
+			},
+			body: strings.Join([]string{
+				`{"create":{"_index":"logs-b"}}`, `{"@timestamp":"2024-01-01T00:00:00Z","message":"foo"}`,
+				`{"create":{"_index":"logs-a"}}`, `{"@timestamp":"2024-01-01T00:00:01Z","message":"debug"}`,
+				`{"create":{"_index":"logs-b"}}`, `{"@timestamp":"2024-01-01T00:00:02Z","message":"a too long line"}`,
+				`{"create":{"_index":"logs-b"}}`, `{"@timestamp":"2024-01-01T00:00:03Z","message":"bar"}`,
+			}, "\n") + "\n",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"errors":true,"items":[
+				{"create":{"result":"created","status":201}},
+				{"create":{"result":"noop","status":200}},
+				{"create":{"error":{"reason":"` + fmt.Sprintf(validation.LineTooLongErrorMsg, 10, `{index=\"logs-b\"}`, 15) + `","type":"illegal_argument_exception"},"status":400}},
+				{"create":{"result":"created","status":201}}
+			],"took":0}`,
+			expectedLabels: `{index="logs-b"}`,
+			expectedLines:  []string{"foo", "bar"},
+		},
+		{
+			name:         "elasticsearch bulk with invalid body",
+			handler:      func(d *Distributor) http.HandlerFunc { return d.ElasticsearchBulkPushHandler },
+			body:         "{\"delete\":{\"_index\":\"logs-app\",\"_id\":\"1\"}}\n",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":{"reason":"unsupported bulk action \"delete\": only index and create actions are supported","type":"illegal_argument_exception"},"status":400}`,
+		},
+		{
+			name:           "splunk hec",
+			handler:        func(d *Distributor) http.HandlerFunc { return d.SplunkHECPushHandler },
+			body:           `{"time":1704067200,"host":"web-1","sourcetype":"access","event":"foo"}`,
+			expectedCode:   http.StatusOK,
+			expectedBody:   `{"code":0,"text":"Success"}`,
+			expectedLabels: `{sourcetype="access"}`,
+		},
+		{
+			name:         "splunk hec without event",
+			handler:      func(d *Distributor) http.HandlerFunc { return d.SplunkHECPushHandler },
+			body:         `{"time":1704067200,"host":"web-1"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"code":6,"text":"event field is required"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			limits := &validation.Limits{}
+			flagext.DefaultValues(limits)
+			limits.RejectOldSamples = false
+			if tc.limits != nil {
+				tc.limits(limits)
+				require.NoError(t, limits.IngestPipeline.Validate())
+			}
+
+			ingester := &mockIngester{}
+			distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+			req, err := http.NewRequestWithContext(user.InjectOrgID(context.Background(), "test"), http.MethodPost, "/", strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			tc.handler(distributors[0])(rec, req)
+
+			require.Equal(t, tc.expectedCode, rec.Code)
+			require.JSONEq(t, tc.expectedBody, rec.Body.String())
+
+			pushed := ingester.Peek()
+			if tc.expectedLabels == "" {
+				require.Nil(t, pushed)
+				return
+			}
+			require.Len(t, pushed.Streams, 1)
+			require.Equal(t, tc.expectedLabels, pushed.Streams[0].Labels)
+			if tc.expectedLines == nil {
+				tc.expectedLines = []string{"foo"}
+			}
+			lines := make([]string, 0, len(pushed.Streams[0].Entries))
+			for _, e := range pushed.Streams[0].Entries {
+				lines = append(lines, e.Line)
+			}
+			require.Equal(t, tc.expectedLines, lines)
+		})
+	}
+}
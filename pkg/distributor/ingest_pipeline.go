@@ -0,0 +1,88 @@
+package distributor
+
+import (
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+const (
+	ingestPipelineReasonRelabel   = "relabel"
+	ingestPipelineReasonDropLines = "drop_lines"
+)
+
+// applyIngestPipeline applies the ingest pipeline of a tenant to the streams of a push
+// request, before their labels are validated and hashed. Streams dropped by relabeling and
+// streams left without entries are removed. Streams with labels that cannot be parsed are
+// left untouched, so that they are rejected by the validation.
+func (d *Distributor) applyIngestPipeline(tenantID string, pipeline *validation.IngestPipelineConfig, streams []logproto.Stream, tracker *entryTracker) []logproto.Stream {
+	defer tracker.compact()
+
+	n := 0
+	for i, stream := range streams {
+		if len(stream.Entries) == 0 {
+			continue
+		}
+
+		lbs, err := syntax.ParseLabels(stream.Labels)
+		if err != nil || lbs.Has(push.AggregatedMetricLabel) {
+			streams[n] = stream
+			n++
+			continue
+		}
+
+		if len(pipeline.RelabelConfigs) > 0 {
+			var keep bool
+			lbs, keep = relabel.Process(lbs, pipeline.RelabelConfigs...)
+			if !keep || lbs.IsEmpty() {
+				d.ingestPipelineDroppedLines.WithLabelValues(tenantID, ingestPipelineReasonRelabel).Add(float64(len(stream.Entries)))
+				tracker.dropStream(i)
+				continue
+			}
+		}
+
+		var structuredMetadata []logproto.LabelAdapter
+		if len(pipeline.LabelsToStructuredMetadata) > 0 {
+			builder := labels.NewBuilder(lbs)
+			for _, name := range pipeline.LabelsToStructuredMetadata {
+				if value := lbs.Get(name); value != "" {
+					builder.Del(name)
+					structuredMetadata = append(structuredMetadata, logproto.LabelAdapter{Name: name, Value: value})
+				}
+			}
+			lbs = builder.Labels()
+		}
+
+		dropped := 0
+		entries := stream.Entries[:0]
+		for j, entry := range stream.Entries {
+			if pipeline.DropLine(entry.Line) {
+				dropped++
+				tracker.drop(i, j)
+				continue
+			}
+			entry.Line = pipeline.Redact(entry.Line)
+			if len(structuredMetadata) > 0 {
+				entry.StructuredMetadata = append(entry.StructuredMetadata, structuredMetadata...)
+			}
+			entries = append(entries, entry)
+		}
+		if dropped > 0 {
+			d.ingestPipelineDroppedLines.WithLabelValues(tenantID, ingestPipelineReasonDropLines).Add(float64(dropped))
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		stream.Entries = entries
+		stream.Labels = lbs.String()
+		stream.Hash = 0
+		streams[n] = stream
+		n++
+	}
+	return streams[:n]
+}
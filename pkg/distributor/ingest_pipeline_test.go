@@ -0,0 +1,78 @@
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestDistributor_IngestPipeline(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.RejectOldSamples = false
+	limits.DiscoverServiceName = nil
+	limits.DiscoverLogLevels = false
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+relabel_configs:
+  - source_labels: [namespace]
+    regex: dev
+    action: drop
+  - source_labels: [container]
+    target_label: app
+  - regex: container
+    action: labeldrop
+labels_to_structured_metadata: [pod]
+drop_lines:
+  - '|= "debug"'
+  - '|= "health" != "error"'
+redactions:
+  - regex: 'password=\S+'
+    replacement: 'password=<redacted>'
+`), &limits.IngestPipeline))
+	require.NoError(t, limits.Validate())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	ts := time.Unix(1, 0)
+	_, err := distributors[0].Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{
+		{
+			Labels: `{namespace="dev", container="api"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: ts, Line: "dropped"},
+			},
+		},
+		{
+			Labels: `{namespace="prod", container="api", pod="api-1"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: ts, Line: "debug dropped"},
+				{Timestamp: ts, Line: "health check"},
+				{Timestamp: ts, Line: "health check error"},
+				{Timestamp: ts, Line: "login user=foo password=secret"},
+			},
+		},
+		{
+			Labels: `{namespace="prod", container="db"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: ts, Line: "debug dropped"},
+			},
+		},
+	}})
+	require.NoError(t, err)
+
+	pushed := ingester.Peek()
+	require.NotNil(t, pushed)
+	require.Len(t, pushed.Streams, 1)
+	require.Equal(t, `{app="api", namespace="prod"}`, pushed.Streams[0].Labels)
+	require.Equal(t, []logproto.Entry{
+		{Timestamp: ts, Line: "health check error", StructuredMetadata: []logproto.LabelAdapter{{Name: "pod", Value: "api-1"}}},
+		{Timestamp: ts, Line: "login user=foo password=<redacted>", StructuredMetadata: []logproto.LabelAdapter{{Name: "pod", Value: "api-1"}}},
+	}, pushed.Streams[0].Entries)
+}
@@ -1,7 +1,11 @@
 package distributor
 
 import (
+	"strings"
+
 	"github.com/grafana/dskit/limiter"
+
+	"github.com/grafana/loki/v3/pkg/validation"
 )
 
 // ReadLifecycler represents the read interface to the lifecycler.
@@ -54,3 +58,64 @@ func (s *globalStrategy) Burst(userID string) int {
 	// to keep it easier to understand for users / operators.
 	return s.limits.IngestionBurstSizeBytes(userID)
 }
+
+// policyRateLimiterKey returns the key of the policy rate limiter for the
+// streams of a policy. Streams which do not match any policy are rate limited
+// with the limits of the global policy.
+func policyRateLimiterKey(tenantID, policy string) string {
+	// Tenant IDs cannot contain a slash, so the first slash separates the
+	// tenant from the policy.
+	return tenantID + "/" + validation.PolicyOrGlobal(policy)
+}
+
+func splitPolicyRateLimiterKey(key string) (string, string) {
+	tenantID, policy, _ := strings.Cut(key, "/")
+	return tenantID, policy
+}
+
+type localPolicyStrategy struct {
+	limits Limits
+}
+
+func newLocalPolicyIngestionRateStrategy(limits Limits) limiter.RateLimiterStrategy {
+	return &localPolicyStrategy{
+		limits: limits,
+	}
+}
+
+func (s *localPolicyStrategy) Limit(key string) float64 {
+	return s.limits.PolicyIngestionRateBytes(splitPolicyRateLimiterKey(key))
+}
+
+func (s *localPolicyStrategy) Burst(key string) int {
+	return s.limits.PolicyIngestionBurstSizeBytes(splitPolicyRateLimiterKey(key))
+}
+
+type globalPolicyStrategy struct {
+	limits Limits
+	ring   ReadLifecycler
+}
+
+func newGlobalPolicyIngestionRateStrategy(limits Limits, ring ReadLifecycler) limiter.RateLimiterStrategy {
+	return &globalPolicyStrategy{
+		limits: limits,
+		ring:   ring,
+	}
+}
+
+func (s *globalPolicyStrategy) Limit(key string) float64 {
+	limit := s.limits.PolicyIngestionRateBytes(splitPolicyRateLimiterKey(key))
+
+	numDistributors := s.ring.HealthyInstancesCount()
+	if numDistributors == 0 {
+		return limit
+	}
+
+	return limit / float64(numDistributors)
+}
+
+func (s *globalPolicyStrategy) Burst(key string) int {
+	// As for the tenant rate limit, the meaning of burst doesn't change for
+	// the global strategy.
+	return s.limits.PolicyIngestionBurstSizeBytes(splitPolicyRateLimiterKey(key))
+}
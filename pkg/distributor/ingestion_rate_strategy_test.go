@@ -86,6 +86,48 @@ func TestIngestionRateStrategy(t *testing.T) {
 	}
 }
 
+func TestPolicyIngestionRateStrategy(t *testing.T) {
+	limits := validation.Limits{
+		IngestionRateMB:      4.0,
+		IngestionBurstSizeMB: 6.0,
+		PolicyLimits: map[string]validation.PolicyLimits{
+			"staging":               {IngestionRateMB: 1.0, IngestionBurstSizeMB: 2.0},
+			"prod":                  {IngestionRateMB: 3.0},
+			validation.GlobalPolicy: {IngestionRateMB: 5.0},
+		},
+	}
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	ring := newReadLifecyclerMock()
+	ring.On("HealthyInstancesCount").Return(2)
+
+	local := newLocalPolicyIngestionRateStrategy(overrides)
+	global := newGlobalPolicyIngestionRateStrategy(overrides, ring)
+
+	for _, tc := range []struct {
+		policy              string
+		expectedLocalLimit  float64
+		expectedGlobalLimit float64
+		expectedBurst       int
+	}{
+		{policy: "staging", expectedLocalLimit: 1.0 * bytesInMB, expectedGlobalLimit: 0.5 * bytesInMB, expectedBurst: 2.0 * bytesInMB},
+		// The burst defaults to the tenant burst.
+		{policy: "prod", expectedLocalLimit: 3.0 * bytesInMB, expectedGlobalLimit: 1.5 * bytesInMB, expectedBurst: 6.0 * bytesInMB},
+		// Streams without a policy use the limits of the global policy.
+		{policy: "", expectedLocalLimit: 5.0 * bytesInMB, expectedGlobalLimit: 2.5 * bytesInMB, expectedBurst: 6.0 * bytesInMB},
+		{policy: "unknown", expectedLocalLimit: 0, expectedGlobalLimit: 0, expectedBurst: 6.0 * bytesInMB},
+	} {
+		t.Run(tc.policy, func(t *testing.T) {
+			key := policyRateLimiterKey("test", tc.policy)
+			assert.Equal(t, tc.expectedLocalLimit, local.Limit(key))
+			assert.Equal(t, tc.expectedGlobalLimit, global.Limit(key))
+			assert.Equal(t, tc.expectedBurst, local.Burst(key))
+			assert.Equal(t, tc.expectedBurst, global.Burst(key))
+		})
+	}
+}
+
 type readLifecyclerMock struct {
 	mock.Mock
 }
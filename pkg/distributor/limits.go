@@ -6,6 +6,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	"github.com/grafana/loki/v3/pkg/validation"
 )
 
 // Limits is an interface for distributor limits/related configs
@@ -27,15 +28,26 @@ type Limits interface {
 	DiscoverLogLevels(userID string) bool
 	LogLevelFields(userID string) []string
 	LogLevelFromJSONMaxDepth(userID string) int
+	FieldDetection(userID string) *validation.FieldDetectionConfig
 
 	ShardStreams(userID string) shardstreams.Config
 	IngestionRateStrategy() string
 	IngestionRateBytes(userID string) float64
 	IngestionBurstSizeBytes(userID string) int
+	PolicyIngestionRateBytes(userID string, policy string) float64
+	PolicyIngestionBurstSizeBytes(userID string, policy string) int
+	PolicyMaxLineSize(userID string, policy string) int
 	AllowStructuredMetadata(userID string) bool
 	MaxStructuredMetadataSize(userID string) int
 	MaxStructuredMetadataCount(userID string) int
 	OTLPConfig(userID string) push.OTLPConfig
+	ElasticsearchBulkConfig(userID string) push.FieldMappingConfig
+	SplunkHECConfig(userID string) push.FieldMappingConfig
+	SyslogConfig(userID string) push.FieldMappingConfig
+	IngestPipeline(userID string) *validation.IngestPipelineConfig
+	PIIRedaction(userID string) *validation.PIIRedactionConfig
+	WebhookTeeLimits
+	WriteBufferLimits
 
 	BlockIngestionUntil(userID string) time.Time
 	BlockIngestionStatusCode(userID string) int
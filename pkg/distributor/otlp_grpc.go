@@ -0,0 +1,86 @@
+package distributor
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// otlpLogsServer implements the OTLP/gRPC logs service on top of the distributor.
+type otlpLogsServer struct {
+	plogotlp.UnimplementedGRPCServer
+
+	d *Distributor
+}
+
+// OTLPLogsServer returns the OTLP/gRPC logs service of the distributor. It accepts the
+// same logs as the /otlp/v1/logs HTTP endpoint.
+func (d *Distributor) OTLPLogsServer() plogotlp.GRPCServer {
+	return &otlpLogsServer{d: d}
+}
+
+// Export implements plogotlp.GRPCServer. Following the OTLP specification, a request
+// whose logs were only partially accepted succeeds with the number of rejected log
+// records in its partial success, and errors are mapped to retryable gRPC codes only
+// if the request can succeed when retried.
+func (s *otlpLogsServer) Export(ctx context.Context, req plogotlp.ExportRequest) (plogotlp.ExportResponse, error) {
+	resp := plogotlp.NewExportResponse()
+
+	logger := util_log.WithContext(ctx, util_log.Logger)
+	tenantID, err := tenant.TenantID(ctx)
+	if err != nil {
+		level.Error(logger).Log("msg", "error getting tenant id", "err", err)
+		return resp, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Create a request-scoped policy and retention resolver, as for push requests received over HTTP.
+	streamResolver := newRequestScopedStreamResolver(tenantID, s.d.validator.Limits, logger)
+
+	logPushRequestStreams := s.d.tenantConfigs.LogPushRequestStreams(tenantID)
+	pushReq := push.ParseOTLPGRPCRequest(ctx, logger, tenantID, req, s.d.validator.Limits, s.d.usageTracker, streamResolver, logPushRequestStreams)
+	if len(pushReq.Streams) == 0 {
+		return resp, nil
+	}
+
+	_, accepted, err := s.d.pushWithResolver(ctx, pushReq, streamResolver)
+	if err == nil {
+		return resp, nil
+	}
+
+	code, msg := http.StatusInternalServerError, err.Error()
+	if httpResp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+		code, msg = int(httpResp.Code), string(httpResp.Body)
+	}
+	if s.d.tenantConfigs.LogPushRequest(tenantID) {
+		level.Debug(logger).Log(
+			"msg", "push request failed",
+			"code", code,
+			"err", msg,
+		)
+	}
+
+	switch {
+	case code == http.StatusTooManyRequests:
+		return resp, status.Error(codes.ResourceExhausted, msg)
+	case code >= http.StatusInternalServerError:
+		// 500 errors are never retried on the client side, so they are all mapped to Unavailable.
+		return resp, status.Error(codes.Unavailable, msg)
+	case code < http.StatusBadRequest || accepted > 0:
+		// Ingestion was blocked with a successful status code, or only some of the log
+		// records were rejected by validation. Retrying the request does not help.
+		resp.PartialSuccess().SetRejectedLogRecords(int64(req.Logs().LogRecordCount() - accepted))
+		resp.PartialSuccess().SetErrorMessage(msg)
+		return resp, nil
+	default:
+		return resp, status.Error(codes.InvalidArgument, msg)
+	}
+}
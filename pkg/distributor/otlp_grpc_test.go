@@ -0,0 +1,112 @@
+package distributor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func makeOTLPExportRequest(lines ...string) plogotlp.ExportRequest {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "checkout")
+	sl := rl.ScopeLogs().AppendEmpty()
+	for _, line := range lines {
+		lr := sl.LogRecords().AppendEmpty()
+		lr.Body().SetStr(line)
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	}
+	return plogotlp.NewExportRequestFromLogs(ld)
+}
+
+func TestDistributor_OTLPLogsServer(t *testing.T) {
+	for _, tc := range []struct {
+		name                       string
+		ctx                        context.Context
+		lines                      []string
+		ingestionRateMB            float64
+		expectedCode               codes.Code
+		expectedRejectedLogRecords int64
+		expectedPushed             int
+	}{
+		{
+			name:           "accepts logs",
+			ctx:            ctx,
+			lines:          []string{"foo", "bar"},
+			expectedCode:   codes.OK,
+			expectedPushed: 2,
+		},
+		{
+			name:                       "partial success when some log records are invalid",
+			ctx:                        ctx,
+			lines:                      []string{"foo", strings.Repeat("a", 20)},
+			expectedCode:               codes.OK,
+			expectedRejectedLogRecords: 1,
+			expectedPushed:             1,
+		},
+		{
+			name:         "invalid argument when all log records are invalid",
+			ctx:          ctx,
+			lines:        []string{strings.Repeat("a", 20)},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:            "resource exhausted when rate limited",
+			ctx:             ctx,
+			lines:           []string{"foo"},
+			ingestionRateMB: 1e-9,
+			expectedCode:    codes.ResourceExhausted,
+		},
+		{
+			name:         "invalid argument without tenant",
+			ctx:          context.Background(),
+			lines:        []string{"foo"},
+			expectedCode: codes.InvalidArgument,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			limits := &validation.Limits{}
+			flagext.DefaultValues(limits)
+			limits.MaxLineSize = 10
+			limits.OTLPConfig = push.DefaultOTLPConfig(push.GlobalOTLPConfig{
+				DefaultOTLPResourceAttributesAsIndexLabels: []string{"service.name"},
+			})
+			if tc.ingestionRateMB > 0 {
+				limits.IngestionRateMB = tc.ingestionRateMB
+				limits.IngestionBurstSizeMB = tc.ingestionRateMB
+			}
+
+			ingester := &mockIngester{}
+			distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+			resp, err := distributors[0].OTLPLogsServer().Export(tc.ctx, makeOTLPExportRequest(tc.lines...))
+			require.Equal(t, tc.expectedCode, status.Code(err))
+			require.Equal(t, tc.expectedRejectedLogRecords, resp.PartialSuccess().RejectedLogRecords())
+			if tc.expectedRejectedLogRecords > 0 {
+				require.Contains(t, resp.PartialSuccess().ErrorMessage(), "Max entry size")
+			}
+
+			pushed := ingester.Peek()
+			if tc.expectedPushed == 0 {
+				require.Nil(t, pushed)
+				return
+			}
+			require.Len(t, pushed.Streams, 1)
+			require.Equal(t, `{service_name="checkout"}`, pushed.Streams[0].Labels)
+			require.Len(t, pushed.Streams[0].Entries, tc.expectedPushed)
+		})
+	}
+}
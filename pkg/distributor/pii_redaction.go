@@ -0,0 +1,19 @@
+package distributor
+
+import (
+	"github.com/grafana/loki/v3/pkg/distributor/redaction"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// redactStreams redacts the sensitive values from the lines and structured metadata of
+// the entries of a push request, in place.
+func (d *Distributor) redactStreams(tenantID string, redactor *redaction.Redactor, streams []logproto.Stream) {
+	observe := func(rule string, count int) {
+		d.redactedValues.WithLabelValues(tenantID, rule).Add(float64(count))
+	}
+	for _, stream := range streams {
+		for i := range stream.Entries {
+			redactor.RedactEntry(&stream.Entries[i], observe)
+		}
+	}
+}
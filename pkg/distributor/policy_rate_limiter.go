@@ -0,0 +1,86 @@
+package distributor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/limiter"
+	"golang.org/x/time/rate"
+)
+
+// policyRateLimiter is a rate limiter keyed by tenant and policy. Unlike
+// limiter.RateLimiter, the tokens it allows are reservations which can be
+// cancelled, so that a push rejected by another limit does not consume them.
+type policyRateLimiter struct {
+	strategy      limiter.RateLimiterStrategy
+	recheckPeriod time.Duration
+
+	mtx      sync.Mutex
+	limiters map[string]*keyLimiter
+}
+
+type keyLimiter struct {
+	limiter   *rate.Limiter
+	recheckAt time.Time
+}
+
+func newPolicyRateLimiter(strategy limiter.RateLimiterStrategy, recheckPeriod time.Duration) *policyRateLimiter {
+	return &policyRateLimiter{
+		strategy:      strategy,
+		recheckPeriod: recheckPeriod,
+		limiters:      map[string]*keyLimiter{},
+	}
+}
+
+// ReserveN reserves n tokens of the key at time now. It returns false, without
+// consuming any token, if n tokens are not available at time now.
+func (l *policyRateLimiter) ReserveN(now time.Time, key string, n int) (*rate.Reservation, bool) {
+	r := l.getLimiter(now, key).ReserveN(now, n)
+	if !r.OK() {
+		return nil, false
+	}
+	if r.DelayFrom(now) > 0 {
+		r.CancelAt(now)
+		return nil, false
+	}
+	return r, true
+}
+
+// Limit returns the currently configured maximum rate of the key.
+func (l *policyRateLimiter) Limit(now time.Time, key string) float64 {
+	return float64(l.getLimiter(now, key).Limit())
+}
+
+func (l *policyRateLimiter) getLimiter(now time.Time, key string) *rate.Limiter {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	entry, ok := l.limiters[key]
+	if !ok {
+		entry = &keyLimiter{
+			limiter:   rate.NewLimiter(rate.Limit(l.strategy.Limit(key)), l.strategy.Burst(key)),
+			recheckAt: now.Add(l.recheckPeriod),
+		}
+		l.limiters[key] = entry
+		return entry.limiter
+	}
+
+	// The limits of the key can change at runtime and are applied every recheckPeriod.
+	if !now.Before(entry.recheckAt) {
+		if limit := rate.Limit(l.strategy.Limit(key)); entry.limiter.Limit() != limit {
+			entry.limiter.SetLimitAt(now, limit)
+		}
+		if burst := l.strategy.Burst(key); entry.limiter.Burst() != burst {
+			entry.limiter.SetBurstAt(now, burst)
+		}
+		entry.recheckAt = now.Add(l.recheckPeriod)
+	}
+	return entry.limiter
+}
+
+// cancelReservations gives back the tokens of the reservations.
+func cancelReservations(now time.Time, reservations []*rate.Reservation) {
+	for _, r := range reservations {
+		r.CancelAt(now)
+	}
+}
@@ -0,0 +1,154 @@
+package redaction
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+const (
+	DetectorRegex      = "regex"
+	DetectorEmail      = "email"
+	DetectorCreditCard = "credit_card"
+	DetectorSecrets    = "secrets"
+)
+
+// Detector finds sensitive values in a string.
+type Detector interface {
+	// Detect returns the start and end indexes of the sensitive values found in s,
+	// in order and without overlaps, or nil if none are found.
+	Detect(s string) [][]int
+}
+
+// DetectorFactory builds the detector of a rule.
+type DetectorFactory func(rule validation.PIIRedactionRule) (Detector, error)
+
+var (
+	detectorsMtx sync.RWMutex
+	detectors    = map[string]DetectorFactory{
+		DetectorRegex: func(rule validation.PIIRedactionRule) (Detector, error) {
+			if rule.Regex == "" {
+				return nil, fmt.Errorf("the %s detector requires a regex", DetectorRegex)
+			}
+			re, err := regexp.Compile(rule.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex %q: %w", rule.Regex, err)
+			}
+			return regexDetector{re: re}, nil
+		},
+		DetectorEmail: func(validation.PIIRedactionRule) (Detector, error) {
+			return regexDetector{re: emailRegex}, nil
+		},
+		DetectorCreditCard: func(validation.PIIRedactionRule) (Detector, error) {
+			return creditCardDetector{}, nil
+		},
+		DetectorSecrets: func(validation.PIIRedactionRule) (Detector, error) {
+			return regexDetector{re: secretsRegex}, nil
+		},
+	}
+)
+
+// RegisterDetector registers a detector, which can then be used by redaction rules
+// with its name. Registering a detector with the name of an existing one replaces it.
+func RegisterDetector(name string, factory DetectorFactory) {
+	detectorsMtx.Lock()
+	defer detectorsMtx.Unlock()
+	detectors[name] = factory
+}
+
+// Detectors returns the names of the registered detectors.
+func Detectors() []string {
+	detectorsMtx.RLock()
+	defer detectorsMtx.RUnlock()
+	names := make([]string, 0, len(detectors))
+	for name := range detectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newDetector(rule validation.PIIRedactionRule) (Detector, error) {
+	detectorsMtx.RLock()
+	factory, ok := detectors[rule.Detector]
+	detectorsMtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown detector %q: supported detectors are %v", rule.Detector, Detectors())
+	}
+	return factory(rule)
+}
+
+type regexDetector struct {
+	re *regexp.Regexp
+}
+
+func (d regexDetector) Detect(s string) [][]int {
+	return d.re.FindAllStringIndex(s, -1)
+}
+
+var (
+	emailRegex = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+
+	// secretsRegex matches well known formats of credentials and tokens.
+	secretsRegex = regexp.MustCompile(`` +
+		`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b` + // AWS access key IDs
+		`|\bgh[pousr]_[A-Za-z0-9]{36,255}\b` + // GitHub tokens
+		`|\bgithub_pat_[A-Za-z0-9_]{22,255}\b` + // GitHub fine-grained tokens
+		`|\bxox[abposr]-[A-Za-z0-9-]{10,}` + // Slack tokens
+		`|\bglsa_[A-Za-z0-9]{32}_[A-Fa-f0-9]{8}\b` + // Grafana service account tokens
+		`|\bglc_[A-Za-z0-9+/]{32,}={0,2}` + // Grafana Cloud tokens
+		`|\bAIza[0-9A-Za-z_-]{35}\b` + // Google API keys
+		`|\b[rs]k_(?:live|test)_[0-9A-Za-z]{24,}\b` + // Stripe keys
+		`|\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+` + // JSON web tokens
+		`|-----BEGIN[A-Z ]* PRIVATE KEY-----`, // private keys
+	)
+
+	// cardCandidateRegex matches sequences of 13 to 19 digits, optionally separated by
+	// spaces or dashes, which are then validated with the Luhn algorithm.
+	cardCandidateRegex = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// creditCardDetector detects credit card numbers, validated with the Luhn algorithm
+// to limit false positives.
+type creditCardDetector struct{}
+
+func (creditCardDetector) Detect(s string) [][]int {
+	candidates := cardCandidateRegex.FindAllStringIndex(s, -1)
+	n := 0
+	for _, c := range candidates {
+		if luhnValid(s[c[0]:c[1]]) {
+			candidates[n] = c
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return candidates[:n]
+}
+
+// luhnValid returns true if the digits of s, ignoring separators, pass the Luhn checksum.
+func luhnValid(s string) bool {
+	sum, digits := 0, 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
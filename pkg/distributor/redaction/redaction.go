@@ -0,0 +1,246 @@
+// Package redaction implements the redaction of sensitive values, such as credit card
+// numbers, email addresses and tokens, from the log entries pushed to the distributors.
+package redaction
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+const (
+	defaultReplacement = "[REDACTED]"
+	hashLength         = 16
+)
+
+// Redactor applies the compiled redaction rules of a tenant.
+type Redactor struct {
+	rules []*rule
+}
+
+type rule struct {
+	name        string
+	detector    Detector
+	hash        bool
+	replacement string
+	key         []byte
+	line        bool
+	metadata    bool
+	jsonPaths   [][]string
+	metadataSet map[string]struct{}
+}
+
+// New compiles the redaction rules of cfg, which must have been validated.
+func New(cfg *validation.PIIRedactionConfig) (*Redactor, error) {
+	rules := make([]*rule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		cr, err := compileRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %q: %w", r.Name, err)
+		}
+		rules = append(rules, cr)
+	}
+	return &Redactor{rules: rules}, nil
+}
+
+// Redactors caches the compiled redaction rules of the tenants. The rules of a tenant are
+// compiled again when its limits are reloaded.
+type Redactors struct {
+	mtx     sync.Mutex
+	tenants map[string]cachedRedactor
+}
+
+type cachedRedactor struct {
+	cfg      *validation.PIIRedactionConfig
+	redactor *Redactor
+}
+
+func NewRedactors() *Redactors {
+	return &Redactors{tenants: map[string]cachedRedactor{}}
+}
+
+// For returns the redactor of the rules of a tenant.
+func (r *Redactors) For(tenantID string, cfg *validation.PIIRedactionConfig) (*Redactor, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if cached, ok := r.tenants[tenantID]; ok && cached.cfg == cfg {
+		return cached.redactor, nil
+	}
+	redactor, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	r.tenants[tenantID] = cachedRedactor{cfg: cfg, redactor: redactor}
+	return redactor, nil
+}
+
+func compileRule(r validation.PIIRedactionRule) (*rule, error) {
+	detector, err := newDetector(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := &rule{
+		name:        r.Name,
+		detector:    detector,
+		replacement: r.Replacement,
+	}
+
+	if r.Action == validation.PIIRedactionActionHash {
+		cr.hash = true
+		cr.key = []byte(r.HashKey.String())
+	} else if cr.replacement == "" {
+		cr.replacement = defaultReplacement
+	}
+
+	switch r.Target {
+	case validation.PIIRedactionTargetLine:
+		cr.line = true
+	case validation.PIIRedactionTargetStructuredMetadata:
+		cr.metadata = true
+	default:
+		cr.line, cr.metadata = true, true
+	}
+
+	for _, path := range r.JSONPaths {
+		cr.jsonPaths = append(cr.jsonPaths, strings.Split(path, "."))
+	}
+
+	if len(r.StructuredMetadataKeys) > 0 {
+		cr.metadataSet = make(map[string]struct{}, len(r.StructuredMetadataKeys))
+		for _, key := range r.StructuredMetadataKeys {
+			cr.metadataSet[key] = struct{}{}
+		}
+	}
+	return cr, nil
+}
+
+// RedactEntry redacts the line and structured metadata of an entry. The structured
+// metadata is copied before being redacted, since it can be shared with other entries.
+// observe is called with the number of values redacted by each rule.
+func (c *Redactor) RedactEntry(entry *logproto.Entry, observe func(rule string, count int)) {
+	for _, r := range c.rules {
+		count := 0
+		if r.line {
+			var n int
+			entry.Line, n = r.redactLine(entry.Line)
+			count += n
+		}
+		if r.metadata && len(entry.StructuredMetadata) > 0 {
+			var n int
+			entry.StructuredMetadata, n = r.redactStructuredMetadata(entry.StructuredMetadata)
+			count += n
+		}
+		if count > 0 && observe != nil {
+			observe(r.name, count)
+		}
+	}
+}
+
+func (r *rule) redactLine(line string) (string, int) {
+	if len(r.jsonPaths) == 0 {
+		return r.redact(line)
+	}
+
+	data := []byte(line)
+	total := 0
+	for _, path := range r.jsonPaths {
+		value, typ, _, err := jsonparser.Get(data, path...)
+		if err != nil {
+			continue
+		}
+
+		var s string
+		switch typ {
+		case jsonparser.String:
+			if s, err = jsonparser.ParseString(value); err != nil {
+				continue
+			}
+		case jsonparser.Number:
+			s = string(value)
+		default:
+			continue
+		}
+
+		redacted, n := r.redact(s)
+		if n == 0 {
+			continue
+		}
+		quoted, err := json.Marshal(redacted)
+		if err != nil {
+			continue
+		}
+		if data, err = jsonparser.Set(data, quoted, path...); err != nil {
+			return line, 0
+		}
+		total += n
+	}
+	if total == 0 {
+		return line, 0
+	}
+	return string(data), total
+}
+
+func (r *rule) redactStructuredMetadata(metadata []logproto.LabelAdapter) ([]logproto.LabelAdapter, int) {
+	var redacted []logproto.LabelAdapter
+	total := 0
+	for i, l := range metadata {
+		if r.metadataSet != nil {
+			if _, ok := r.metadataSet[l.Name]; !ok {
+				continue
+			}
+		}
+		value, n := r.redact(l.Value)
+		if n == 0 {
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]logproto.LabelAdapter, len(metadata))
+			copy(redacted, metadata)
+		}
+		redacted[i].Value = value
+		total += n
+	}
+	if redacted == nil {
+		return metadata, 0
+	}
+	return redacted, total
+}
+
+// redact replaces the sensitive values found in s, and returns the number of values replaced.
+func (r *rule) redact(s string) (string, int) {
+	matches := r.detector.Detect(s)
+	if len(matches) == 0 {
+		return s, 0
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+	last := 0
+	for _, m := range matches {
+		sb.WriteString(s[last:m[0]])
+		sb.WriteString(r.replace(s[m[0]:m[1]]))
+		last = m[1]
+	}
+	sb.WriteString(s[last:])
+	return sb.String(), len(matches)
+}
+
+func (r *rule) replace(value string) string {
+	if !r.hash {
+		return r.replacement
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:hashLength]
+}
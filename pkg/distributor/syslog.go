@@ -0,0 +1,32 @@
+package distributor
+
+import (
+	"context"
+
+	"github.com/grafana/dskit/tenant"
+	"github.com/leodido/go-syslog/v4"
+
+	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// PushSyslog pushes syslog messages received by the syslog receiver, with the same
+// validation and rate limiting as push requests received over HTTP.
+func (d *Distributor) PushSyslog(ctx context.Context, msgs []syslog.Message) error {
+	logger := util_log.WithContext(ctx, util_log.Logger)
+	tenantID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Create a request-scoped policy and retention resolver, as for push requests received over HTTP.
+	streamResolver := newRequestScopedStreamResolver(tenantID, d.validator.Limits, logger)
+
+	req := push.ParseSyslogMessages(ctx, logger, tenantID, msgs, d.validator.Limits, d.usageTracker, streamResolver)
+	if len(req.Streams) == 0 {
+		return nil
+	}
+
+	_, err = d.PushWithResolver(ctx, req, streamResolver)
+	return err
+}
@@ -0,0 +1,101 @@
+package distributor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/httpgrpc"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/rfc5424"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestDistributor_PushSyslog(t *testing.T) {
+	parse := func(msg string) syslog.Message {
+		m, err := rfc5424.NewParser().Parse([]byte(msg))
+		require.NoError(t, err)
+		return m
+	}
+
+	for _, tc := range []struct {
+		name            string
+		ctx             context.Context
+		msgs            []string
+		ingestionRateMB float64
+		expectedErr     string
+		expectedCode    int32
+		expectedPushed  int
+	}{
+		{
+			name:           "pushes messages",
+			ctx:            ctx,
+			msgs:           []string{"foo", "bar"},
+			expectedPushed: 2,
+		},
+		{
+			name:           "validates messages",
+			ctx:            ctx,
+			msgs:           []string{"foo", strings.Repeat("a", 20)},
+			expectedCode:   400,
+			expectedPushed: 1,
+		},
+		{
+			name:            "rate limits messages",
+			ctx:             ctx,
+			msgs:            []string{"foo"},
+			ingestionRateMB: 1e-9,
+			expectedCode:    429,
+		},
+		{
+			name:        "requires a tenant",
+			ctx:         context.Background(),
+			msgs:        []string{"foo"},
+			expectedErr: "no org id",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			limits := &validation.Limits{}
+			flagext.DefaultValues(limits)
+			limits.RejectOldSamples = false
+			limits.MaxLineSize = 10
+			if tc.ingestionRateMB > 0 {
+				limits.IngestionRateMB = tc.ingestionRateMB
+				limits.IngestionBurstSizeMB = tc.ingestionRateMB
+			}
+
+			ingester := &mockIngester{}
+			distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+			msgs := make([]syslog.Message, 0, len(tc.msgs))
+			for _, msg := range tc.msgs {
+				msgs = append(msgs, parse("<165>1 2024-01-01T00:00:00Z web-1 app - - - "+msg))
+			}
+
+			err := distributors[0].PushSyslog(tc.ctx, msgs)
+			switch {
+			case tc.expectedErr != "":
+				require.ErrorContains(t, err, tc.expectedErr)
+			case tc.expectedCode != 0:
+				resp, ok := httpgrpc.HTTPResponseFromError(err)
+				require.True(t, ok)
+				require.Equal(t, tc.expectedCode, resp.Code)
+			default:
+				require.NoError(t, err)
+			}
+
+			pushed := ingester.Peek()
+			if tc.expectedPushed == 0 {
+				require.Nil(t, pushed)
+				return
+			}
+			require.Len(t, pushed.Streams, 1)
+			require.Equal(t, `{app_name="app", hostname="web-1"}`, pushed.Streams[0].Labels)
+			require.Len(t, pushed.Streams[0].Entries, tc.expectedPushed)
+		})
+	}
+}
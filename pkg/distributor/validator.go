@@ -49,6 +49,7 @@ type validationContext struct {
 	discoverLogLevels            bool
 	logLevelFields               []string
 	logLevelFromJSONMaxDepth     int
+	fieldDetection               *validation.FieldDetectionConfig
 
 	allowStructuredMetadata    bool
 	maxStructuredMetadataSize  int
@@ -58,6 +59,9 @@ type validationContext struct {
 	blockIngestionStatusCode int
 	enforcedLabels           []string
 
+	ingestPipeline *validation.IngestPipelineConfig
+	piiRedaction   *validation.PIIRedactionConfig
+
 	userID string
 
 	validationMetrics validationMetrics
@@ -81,6 +85,7 @@ func (v Validator) getValidationContextForTime(now time.Time, userID string) val
 		discoverLogLevels:            v.DiscoverLogLevels(userID),
 		logLevelFields:               v.LogLevelFields(userID),
 		logLevelFromJSONMaxDepth:     v.LogLevelFromJSONMaxDepth(userID),
+		fieldDetection:               v.FieldDetection(userID),
 		discoverGenericFields:        v.DiscoverGenericFields(userID),
 		allowStructuredMetadata:      v.AllowStructuredMetadata(userID),
 		maxStructuredMetadataSize:    v.MaxStructuredMetadataSize(userID),
@@ -88,10 +93,21 @@ func (v Validator) getValidationContextForTime(now time.Time, userID string) val
 		blockIngestionUntil:          v.BlockIngestionUntil(userID),
 		blockIngestionStatusCode:     v.BlockIngestionStatusCode(userID),
 		enforcedLabels:               v.EnforcedLabels(userID),
+		ingestPipeline:               v.IngestPipeline(userID),
+		piiRedaction:                 v.PIIRedaction(userID),
 		validationMetrics:            newValidationMetrics(retentionHours),
 	}
 }
 
+// maxLineSizeForPolicy returns the maximum line size of the streams of a policy,
+// which defaults to the maximum line size of the tenant.
+func (v Validator) maxLineSizeForPolicy(vCtx validationContext, policy string) int {
+	if maxSize := v.PolicyMaxLineSize(vCtx.userID, policy); maxSize > 0 {
+		return maxSize
+	}
+	return vCtx.maxLineSize
+}
+
 // ValidateEntry returns an error if the entry is invalid and report metrics for invalid entries accordingly.
 func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, labels labels.Labels, entry logproto.Entry, retentionHours string, policy string) error {
 	ts := entry.Timestamp.UnixNano()
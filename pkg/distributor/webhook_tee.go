@@ -0,0 +1,396 @@
+package distributor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/golang/snappy"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/util"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+const (
+	webhookTeeDropReasonBufferFull = "buffer_full"
+	webhookTeeDropReasonQueueFull  = "queue_full"
+	webhookTeeDropReasonSendFailed = "send_failed"
+
+	webhookTeeMaxResponseLength = 1024
+)
+
+// WebhookTeeConfig configures the WebhookTee.
+type WebhookTeeConfig struct {
+	Enabled          bool           `yaml:"enabled"`
+	BatchSize        flagext.Bytes  `yaml:"batch_size"`
+	BatchWait        time.Duration  `yaml:"batch_wait"`
+	MaxBufferedBytes flagext.Bytes  `yaml:"max_buffered_bytes"`
+	Workers          int            `yaml:"workers"`
+	Timeout          time.Duration  `yaml:"timeout"`
+	Backoff          backoff.Config `yaml:"backoff_config"`
+}
+
+// RegisterFlags adds the flags required to configure this flag set.
+func (cfg *WebhookTeeConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "distributor.webhook-tee.enabled", false, "Enable the webhook tee, which forwards the streams matching the per-tenant webhook_tee_targets to their downstreams.")
+	cfg.BatchSize = 1 << 20 // 1MB
+	f.Var(&cfg.BatchSize, "distributor.webhook-tee.batch-size", "Maximum size of the batches of entries forwarded to a target, in bytes.")
+	f.DurationVar(&cfg.BatchWait, "distributor.webhook-tee.batch-wait", time.Second, "Maximum time entries wait before being forwarded to a target.")
+	cfg.MaxBufferedBytes = 100 << 20 // 100MB
+	f.Var(&cfg.MaxBufferedBytes, "distributor.webhook-tee.max-buffered-bytes", "Maximum size of the entries buffered for all the targets, in bytes. Entries exceeding it are dropped.")
+	f.IntVar(&cfg.Workers, "distributor.webhook-tee.workers", 4, "Number of workers forwarding batches of entries.")
+	f.DurationVar(&cfg.Timeout, "distributor.webhook-tee.timeout", 10*time.Second, "Timeout of the requests to the targets.")
+	cfg.Backoff.RegisterFlagsWithPrefix("distributor.webhook-tee", f)
+}
+
+// Validate ensures the config is valid.
+func (cfg *WebhookTeeConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.BatchSize == 0 {
+		return errors.New("distributor.webhook-tee.batch-size must be greater than 0")
+	}
+	if cfg.BatchWait <= 0 {
+		return errors.New("distributor.webhook-tee.batch-wait must be greater than 0")
+	}
+	if cfg.Workers <= 0 {
+		return errors.New("distributor.webhook-tee.workers must be greater than 0")
+	}
+	return nil
+}
+
+// WebhookTeeLimits are the per-tenant limits of the WebhookTee.
+type WebhookTeeLimits interface {
+	WebhookTeeTargets(userID string) validation.WebhookTeeTargets
+}
+
+// WebhookTee forwards the streams matching the selectors of the webhook tee targets of
+// their tenant, in batches, with the Loki push protocol or as JSON webhooks.
+type WebhookTee struct {
+	services.Service
+
+	cfg    WebhookTeeConfig
+	limits WebhookTeeLimits
+	logger log.Logger
+	client *http.Client
+
+	mtx      sync.Mutex
+	stopped  bool
+	batches  map[webhookBatchKey]*webhookBatch
+	buffered int
+	queue    chan *webhookBatch
+
+	forwardedEntries *prometheus.CounterVec
+	droppedEntries   *prometheus.CounterVec
+	bufferedBytes    prometheus.Gauge
+}
+
+type webhookBatchKey struct {
+	tenant string
+	target string
+}
+
+type webhookBatch struct {
+	tenant  string
+	target  validation.WebhookTeeTarget
+	streams []logproto.Stream
+	entries int
+	bytes   int
+	created time.Time
+}
+
+// NewWebhookTee creates a new WebhookTee.
+func NewWebhookTee(cfg WebhookTeeConfig, limits WebhookTeeLimits, registerer prometheus.Registerer, logger log.Logger) *WebhookTee {
+	t := &WebhookTee{
+		cfg:     cfg,
+		limits:  limits,
+		logger:  log.With(logger, "component", "webhook-tee"),
+		client:  &http.Client{Timeout: cfg.Timeout},
+		batches: make(map[webhookBatchKey]*webhookBatch),
+		// The buffered bytes bound the number of queued batches.
+		queue: make(chan *webhookBatch, webhookTeeQueueSize(cfg)),
+		forwardedEntries: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_webhook_tee_forwarded_entries_total",
+			Help:      "The total number of entries forwarded by the webhook tee per tenant and target.",
+		}, []string{"tenant", "target"}),
+		droppedEntries: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_webhook_tee_dropped_entries_total",
+			Help:      "The total number of entries the webhook tee failed to forward per tenant, target and reason.",
+		}, []string{"tenant", "target", "reason"}),
+		bufferedBytes: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_webhook_tee_buffered_bytes",
+			Help:      "The size of the entries buffered by the webhook tee.",
+		}),
+	}
+	t.Service = services.NewBasicService(nil, t.running, nil)
+	return t
+}
+
+func webhookTeeQueueSize(cfg WebhookTeeConfig) int {
+	if cfg.BatchSize == 0 {
+		return cfg.Workers
+	}
+	return int(cfg.MaxBufferedBytes/cfg.BatchSize) + cfg.Workers
+}
+
+// Duplicate implements Tee.
+func (t *WebhookTee) Duplicate(tenant string, streams []KeyedStream) {
+	targets := t.limits.WebhookTeeTargets(tenant)
+	if len(targets) == 0 {
+		return
+	}
+
+	for _, stream := range streams {
+		lbs, err := syntax.ParseLabels(stream.Stream.Labels)
+		if err != nil {
+			continue
+		}
+		for i := range targets {
+			if targets[i].Matches(lbs) {
+				t.add(tenant, targets[i], stream.Stream)
+			}
+		}
+	}
+}
+
+func (t *WebhookTee) add(tenant string, target validation.WebhookTeeTarget, stream logproto.Stream) {
+	size := util.EntriesTotalSize(stream.Entries)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.stopped || t.buffered+size > int(t.cfg.MaxBufferedBytes) {
+		t.droppedEntries.WithLabelValues(tenant, target.Name, webhookTeeDropReasonBufferFull).Add(float64(len(stream.Entries)))
+		return
+	}
+	t.buffered += size
+	t.bufferedBytes.Set(float64(t.buffered))
+
+	key := webhookBatchKey{tenant: tenant, target: target.Name}
+	batch, ok := t.batches[key]
+	if !ok {
+		batch = &webhookBatch{tenant: tenant, target: target, created: time.Now()}
+		t.batches[key] = batch
+	}
+	batch.streams = append(batch.streams, stream)
+	batch.entries += len(stream.Entries)
+	batch.bytes += size
+
+	if batch.bytes >= int(t.cfg.BatchSize) {
+		delete(t.batches, key)
+		t.enqueue(batch)
+	}
+}
+
+// enqueue queues a batch to be sent by the workers, or drops it if the queue is full.
+// It must be called with the mutex held.
+func (t *WebhookTee) enqueue(batch *webhookBatch) {
+	select {
+	case t.queue <- batch:
+	default:
+		t.droppedEntries.WithLabelValues(batch.tenant, batch.target.Name, webhookTeeDropReasonQueueFull).Add(float64(batch.entries))
+		t.releaseLocked(batch)
+	}
+}
+
+func (t *WebhookTee) release(batch *webhookBatch) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.releaseLocked(batch)
+}
+
+func (t *WebhookTee) releaseLocked(batch *webhookBatch) {
+	t.buffered -= batch.bytes
+	t.bufferedBytes.Set(float64(t.buffered))
+}
+
+// flush queues the batches older than the batch wait, or all of them if force is true.
+func (t *WebhookTee) flush(force bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	now := time.Now()
+	for key, batch := range t.batches {
+		if force || now.Sub(batch.created) >= t.cfg.BatchWait {
+			delete(t.batches, key)
+			t.enqueue(batch)
+		}
+	}
+}
+
+func (t *WebhookTee) running(ctx context.Context) error {
+	var workers sync.WaitGroup
+	workers.Add(t.cfg.Workers)
+	for i := 0; i < t.cfg.Workers; i++ {
+		go func() {
+			defer workers.Done()
+			for batch := range t.queue {
+				t.forward(batch)
+				t.release(batch)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(t.cfg.BatchWait / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.flush(false)
+		case <-ctx.Done():
+			// Forward the buffered batches before stopping. The workers keep
+			// sending while the queue is drained.
+			t.mtx.Lock()
+			t.stopped = true
+			batches := make([]*webhookBatch, 0, len(t.batches))
+			for key, batch := range t.batches {
+				delete(t.batches, key)
+				batches = append(batches, batch)
+			}
+			t.mtx.Unlock()
+
+			for _, batch := range batches {
+				t.queue <- batch
+			}
+			close(t.queue)
+			workers.Wait()
+			return nil
+		}
+	}
+}
+
+// forward sends a batch to its target, retrying on network errors, rate limiting
+// and server errors.
+func (t *WebhookTee) forward(batch *webhookBatch) {
+	contentType, payload, err := encodeWebhookBatch(batch)
+	if err != nil {
+		level.Error(t.logger).Log("msg", "failed to encode batch", "tenant", batch.tenant, "target", batch.target.Name, "err", err)
+		t.droppedEntries.WithLabelValues(batch.tenant, batch.target.Name, webhookTeeDropReasonSendFailed).Add(float64(batch.entries))
+		return
+	}
+
+	retries := backoff.New(context.Background(), t.cfg.Backoff)
+	for {
+		status, err := t.send(batch, contentType, payload)
+		if err == nil {
+			t.forwardedEntries.WithLabelValues(batch.tenant, batch.target.Name).Add(float64(batch.entries))
+			return
+		}
+
+		if status > 0 && !util.IsRateLimited(status) && !util.IsServerError(status) {
+			level.Warn(t.logger).Log("msg", "failed to forward batch, target rejected it with a non-retryable status code", "tenant", batch.tenant, "target", batch.target.Name, "status", status, "err", err)
+			break
+		}
+		if !retries.Ongoing() {
+			level.Warn(t.logger).Log("msg", "failed to forward batch, retries exhausted", "tenant", batch.tenant, "target", batch.target.Name, "status", status, "err", err)
+			break
+		}
+		retries.Wait()
+	}
+	t.droppedEntries.WithLabelValues(batch.tenant, batch.target.Name, webhookTeeDropReasonSendFailed).Add(float64(batch.entries))
+}
+
+// send makes one attempt to send a batch, and returns the status code of the response,
+// or -1 if the request failed.
+func (t *WebhookTee) send(batch *webhookBatch, contentType string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, batch.target.URL, bytes.NewReader(payload))
+	if err != nil {
+		return -1, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range batch.target.Headers {
+		req.Header.Set(name, value.String())
+	}
+	tenant := batch.target.TenantID
+	if tenant == "" {
+		tenant = batch.tenant
+	}
+	req.Header.Set("X-Scope-OrgID", tenant)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return -1, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if util.IsError(resp.StatusCode) {
+		scanner := bufio.NewScanner(io.LimitReader(resp.Body, webhookTeeMaxResponseLength))
+		line := ""
+		if scanner.Scan() {
+			line = scanner.Text()
+		}
+		return resp.StatusCode, fmt.Errorf("server returned HTTP status %s: %s", resp.Status, line)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+type webhookPayload struct {
+	Tenant  string          `json:"tenant"`
+	Streams []webhookStream `json:"streams"`
+}
+
+type webhookStream struct {
+	Labels  string         `json:"labels"`
+	Entries []webhookEntry `json:"entries"`
+}
+
+type webhookEntry struct {
+	Timestamp          time.Time         `json:"timestamp"`
+	Line               string            `json:"line"`
+	StructuredMetadata map[string]string `json:"structured_metadata,omitempty"`
+}
+
+func encodeWebhookBatch(batch *webhookBatch) (string, []byte, error) {
+	if batch.target.Format != validation.WebhookTeeFormatJSON {
+		payload, err := (&logproto.PushRequest{Streams: batch.streams}).Marshal()
+		if err != nil {
+			return "", nil, err
+		}
+		return "application/x-protobuf", snappy.Encode(nil, payload), nil
+	}
+
+	p := webhookPayload{Tenant: batch.tenant, Streams: make([]webhookStream, 0, len(batch.streams))}
+	for _, stream := range batch.streams {
+		s := webhookStream{Labels: stream.Labels, Entries: make([]webhookEntry, 0, len(stream.Entries))}
+		for _, entry := range stream.Entries {
+			e := webhookEntry{Timestamp: entry.Timestamp.UTC(), Line: entry.Line}
+			if len(entry.StructuredMetadata) > 0 {
+				e.StructuredMetadata = make(map[string]string, len(entry.StructuredMetadata))
+				for _, l := range entry.StructuredMetadata {
+					e.StructuredMetadata[l.Name] = l.Value
+				}
+			}
+			s.Entries = append(s.Entries, e)
+		}
+		p.Streams = append(p.Streams, s)
+	}
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return "", nil, err
+	}
+	return "application/json", payload, nil
+}
@@ -0,0 +1,193 @@
+package distributor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/golang/snappy"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+type webhookTeeLimits map[string]validation.WebhookTeeTargets
+
+func (l webhookTeeLimits) WebhookTeeTargets(userID string) validation.WebhookTeeTargets {
+	return l[userID]
+}
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookServer(t *testing.T, failures int) (*httptest.Server, func() []webhookRequest) {
+	var (
+		mtx      sync.Mutex
+		requests []webhookRequest
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, webhookRequest{header: r.Header.Clone(), body: body})
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []webhookRequest {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return append([]webhookRequest(nil), requests...)
+	}
+}
+
+func newTestWebhookTee(t *testing.T, limits webhookTeeLimits) *WebhookTee {
+	targets := limits["tenant"]
+	require.NoError(t, targets.Validate())
+
+	cfg := WebhookTeeConfig{
+		Enabled:          true,
+		BatchSize:        1 << 20,
+		BatchWait:        time.Hour,
+		MaxBufferedBytes: 100,
+		Workers:          2,
+		Timeout:          time.Second,
+		Backoff:          backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetries: 3},
+	}
+	tee := NewWebhookTee(cfg, limits, prometheus.NewRegistry(), log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), tee))
+	return tee
+}
+
+func TestWebhookTee(t *testing.T) {
+	lokiSrv, lokiRequests := newWebhookServer(t, 1)
+	jsonSrv, jsonRequests := newWebhookServer(t, 0)
+
+	limits := webhookTeeLimits{"tenant": {
+		{Name: "loki", URL: lokiSrv.URL, Selector: `{app="api"}`, TenantID: "mirror"},
+		{Name: "siem", URL: jsonSrv.URL, Format: validation.WebhookTeeFormatJSON, Selector: `{namespace="security"}`, Headers: map[string]flagext.Secret{"Authorization": flagext.SecretWithValue("Bearer token")}},
+	}}
+	tee := newTestWebhookTee(t, limits)
+
+	ts := time.Unix(1, 0).UTC()
+	api := logproto.Stream{Labels: `{app="api", namespace="security"}`, Entries: []logproto.Entry{
+		{Timestamp: ts, Line: "login", StructuredMetadata: []logproto.LabelAdapter{{Name: "user", Value: "foo"}}},
+	}}
+	other := logproto.Stream{Labels: `{app="db"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "query"}}}
+	large := logproto.Stream{Labels: `{app="api"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: string(make([]byte, 200))}}}
+
+	tee.Duplicate("tenant", []KeyedStream{{Stream: api}, {Stream: other}, {Stream: large}})
+	tee.Duplicate("other-tenant", []KeyedStream{{Stream: api}})
+
+	// Buffered batches are forwarded when the tee stops.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), tee))
+
+	requests := lokiRequests()
+	require.Len(t, requests, 1)
+	require.Equal(t, "application/x-protobuf", requests[0].header.Get("Content-Type"))
+	require.Equal(t, "mirror", requests[0].header.Get("X-Scope-OrgID"))
+	decoded, err := snappy.Decode(nil, requests[0].body)
+	require.NoError(t, err)
+	var req logproto.PushRequest
+	require.NoError(t, req.Unmarshal(decoded))
+	require.Equal(t, []logproto.Stream{api}, req.Streams)
+
+	requests = jsonRequests()
+	require.Len(t, requests, 1)
+	require.Equal(t, "application/json", requests[0].header.Get("Content-Type"))
+	require.Equal(t, "tenant", requests[0].header.Get("X-Scope-OrgID"))
+	require.Equal(t, "Bearer token", requests[0].header.Get("Authorization"))
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(requests[0].body, &payload))
+	require.Equal(t, map[string]any{
+		"tenant": "tenant",
+		"streams": []any{map[string]any{
+			"labels": `{app="api", namespace="security"}`,
+			"entries": []any{map[string]any{
+				"timestamp":           "1970-01-01T00:00:01Z",
+				"line":                "login",
+				"structured_metadata": map[string]any{"user": "foo"},
+			}},
+		}},
+	}, payload)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(tee.forwardedEntries.WithLabelValues("tenant", "loki")))
+	require.Equal(t, 1.0, testutil.ToFloat64(tee.forwardedEntries.WithLabelValues("tenant", "siem")))
+	require.Equal(t, 1.0, testutil.ToFloat64(tee.droppedEntries.WithLabelValues("tenant", "loki", webhookTeeDropReasonBufferFull)))
+	require.Equal(t, 0.0, testutil.ToFloat64(tee.bufferedBytes))
+}
+
+func TestWebhookTee_DropsBatchesOnNonRetryableErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+
+	tee := newTestWebhookTee(t, webhookTeeLimits{"tenant": {{Name: "loki", URL: srv.URL, Selector: `{app="api"}`}}})
+	tee.Duplicate("tenant", []KeyedStream{{Stream: logproto.Stream{Labels: `{app="api"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0), Line: "foo"}}}}})
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), tee))
+
+	require.Equal(t, 0.0, testutil.ToFloat64(tee.forwardedEntries.WithLabelValues("tenant", "loki")))
+	require.Equal(t, 1.0, testutil.ToFloat64(tee.droppedEntries.WithLabelValues("tenant", "loki", webhookTeeDropReasonSendFailed)))
+}
+
+func TestWebhookTeeTargets_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		targets     validation.WebhookTeeTargets
+		expectedErr string
+	}{
+		{
+			name:    "valid",
+			targets: validation.WebhookTeeTargets{{Name: "a", URL: "https://example.com/push", Selector: `{app="api"}`}},
+		},
+		{
+			name:        "missing name",
+			targets:     validation.WebhookTeeTargets{{URL: "https://example.com/push", Selector: `{app="api"}`}},
+			expectedErr: "name cannot be empty",
+		},
+		{
+			name:        "relative URL",
+			targets:     validation.WebhookTeeTargets{{Name: "a", URL: "/push", Selector: `{app="api"}`}},
+			expectedErr: "absolute http or https URL is required",
+		},
+		{
+			name:        "unsupported format",
+			targets:     validation.WebhookTeeTargets{{Name: "a", URL: "https://example.com/push", Format: "xml", Selector: `{app="api"}`}},
+			expectedErr: `unsupported format "xml"`,
+		},
+		{
+			name:        "invalid selector",
+			targets:     validation.WebhookTeeTargets{{Name: "a", URL: "https://example.com/push", Selector: `{app=}`}},
+			expectedErr: "invalid selector",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.targets.Validate()
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
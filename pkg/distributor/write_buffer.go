@@ -0,0 +1,536 @@
+package distributor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/loki/v3/pkg/util/atomicfs"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/util/encoding"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const (
+	writeBufferRecordV1 byte = 1
+
+	writeBufferDropReasonLimit     = "limit"
+	writeBufferDropReasonRejected  = "rejected"
+	writeBufferDropReasonCorrupted = "corrupted"
+
+	// writeBufferReplayPositionsFile is the checkpoint of the replay positions of the
+	// tenants, in the directory of the write-ahead log.
+	writeBufferReplayPositionsFile = "replay_positions.json"
+)
+
+var errWriteBufferFull = errors.New("write buffer is full for the tenant")
+
+// WriteBufferConfig configures the buffering of the pushes which fail to be written to
+// the ingesters or to Kafka.
+type WriteBufferConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	Dir              string        `yaml:"dir"`
+	SegmentSize      int           `yaml:"segment_size"`
+	ReplayInterval   time.Duration `yaml:"replay_interval"`
+	ReplayMinBackoff time.Duration `yaml:"replay_min_backoff"`
+	ReplayMaxBackoff time.Duration `yaml:"replay_max_backoff"`
+}
+
+// RegisterFlags adds the flags required to configure this flag set.
+func (cfg *WriteBufferConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "distributor.write-buffer.enabled", false, "Enable the write buffer, which writes the validated pushes that fail to be written to the ingesters or to Kafka with a server error to a local write-ahead log, and replays them once the writes succeed again. The pushes of a tenant are written in order: while some are buffered, the following pushes of the tenant are buffered too. When a write partially fails, only the streams which were not written are buffered. Buffered pushes are acknowledged to the clients, and are delivered at least once: the pushes replayed since the last replay can be replayed again after a crash. The size of the buffer is limited per tenant by write_buffer_max_bytes.")
+	f.StringVar(&cfg.Dir, "distributor.write-buffer.dir", "distributor-write-buffer", "Directory of the write-ahead log of the write buffer.")
+	f.IntVar(&cfg.SegmentSize, "distributor.write-buffer.segment-size", wlog.DefaultSegmentSize, "Size of the segments of the write-ahead log, in bytes.")
+	f.DurationVar(&cfg.ReplayInterval, "distributor.write-buffer.replay-interval", 5*time.Second, "Interval at which the buffered pushes are replayed.")
+	f.DurationVar(&cfg.ReplayMinBackoff, "distributor.write-buffer.replay-min-backoff", time.Second, "Minimum delay before retrying a buffered push which failed to be replayed.")
+	f.DurationVar(&cfg.ReplayMaxBackoff, "distributor.write-buffer.replay-max-backoff", time.Minute, "Maximum delay before retrying a buffered push which failed to be replayed.")
+}
+
+// Validate ensures the config is valid.
+func (cfg *WriteBufferConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Dir == "" {
+		return errors.New("distributor.write-buffer.dir must be set")
+	}
+	if cfg.SegmentSize <= 0 {
+		return errors.New("distributor.write-buffer.segment-size must be greater than 0")
+	}
+	if cfg.ReplayInterval <= 0 {
+		return errors.New("distributor.write-buffer.replay-interval must be greater than 0")
+	}
+	return nil
+}
+
+// WriteBufferLimits are the per-tenant limits of the write buffer.
+type WriteBufferLimits interface {
+	WriteBufferMaxBytes(userID string) int
+}
+
+// writeFunc writes the streams, and returns the streams which were not written if it
+// fails.
+type writeFunc func(ctx context.Context, tenantID string, streams []KeyedStream) ([]KeyedStream, error)
+
+// writeBuffer buffers pushes in a write-ahead log, and replays them in order with the
+// write function. Replayed segments are removed from the log.
+type writeBuffer struct {
+	services.Service
+
+	cfg    WriteBufferConfig
+	limits WriteBufferLimits
+	write  writeFunc
+	logger log.Logger
+
+	mtx   sync.Mutex
+	wal   *wlog.WL
+	bytes map[string]int
+
+	// replays is the replay state of the tenants, only accessed by replay.
+	replays map[string]*tenantReplay
+
+	bufferedBytes  *prometheus.GaugeVec
+	bufferedPushes *prometheus.CounterVec
+	replayedPushes *prometheus.CounterVec
+	droppedPushes  *prometheus.CounterVec
+}
+
+func newWriteBuffer(cfg WriteBufferConfig, limits WriteBufferLimits, write writeFunc, registerer prometheus.Registerer, logger log.Logger) *writeBuffer {
+	b := &writeBuffer{
+		cfg:     cfg,
+		limits:  limits,
+		write:   write,
+		logger:  log.With(logger, "component", "write-buffer"),
+		bytes:   make(map[string]int),
+		replays: make(map[string]*tenantReplay),
+		bufferedBytes: promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_write_buffer_bytes",
+			Help:      "The size of the pushes buffered by the write buffer per tenant.",
+		}, []string{"tenant"}),
+		bufferedPushes: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_write_buffer_buffered_pushes_total",
+			Help:      "The total number of pushes buffered by the write buffer per tenant.",
+		}, []string{"tenant"}),
+		replayedPushes: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_write_buffer_replayed_pushes_total",
+			Help:      "The total number of buffered pushes successfully replayed per tenant.",
+		}, []string{"tenant"}),
+		droppedPushes: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_write_buffer_dropped_pushes_total",
+			Help:      "The total number of pushes the write buffer failed to buffer or replay per tenant and reason.",
+		}, []string{"tenant", "reason"}),
+	}
+	b.Service = services.NewBasicService(b.starting, b.running, b.stopping)
+	return b
+}
+
+func (b *writeBuffer) starting(_ context.Context) error {
+	if err := os.MkdirAll(b.cfg.Dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create the write buffer directory: %w", err)
+	}
+
+	if err := b.loadReplayPositions(); err != nil {
+		return fmt.Errorf("failed to read the replay positions of the write buffer: %w", err)
+	}
+
+	// Account for the pushes buffered before a restart which are not replayed yet. They
+	// are replayed first.
+	first, last, err := wlog.Segments(b.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read the write buffer: %w", err)
+	}
+	// The first and last segments are -1 when the log is empty.
+	for segment := max(first, 0); segment <= last; segment++ {
+		record := -1
+		if err := b.forEachRecord(segment, func(tenant string, _ []KeyedStream, size int) bool {
+			record++
+			if state, ok := b.replays[tenant]; ok && (replayPosition{segment: segment, record: record}).before(state.next) {
+				return true
+			}
+			b.bytes[tenant] += size
+			return true
+		}); err != nil {
+			return fmt.Errorf("failed to read the write buffer: %w", err)
+		}
+	}
+	for tenant, size := range b.bytes {
+		b.bufferedBytes.WithLabelValues(tenant).Set(float64(size))
+	}
+
+	// The metrics of the log are not registered, since they would conflict with the ones
+	// of the ingester write-ahead log.
+	w, err := wlog.NewSize(util_log.SlogFromGoKit(b.logger), nil, b.cfg.Dir, b.cfg.SegmentSize, wlog.CompressionSnappy)
+	if err != nil {
+		return fmt.Errorf("failed to open the write buffer: %w", err)
+	}
+	b.wal = w
+	return nil
+}
+
+func (b *writeBuffer) running(ctx context.Context) error {
+	ticker := time.NewTicker(b.cfg.ReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := b.replay(ctx); err != nil {
+				level.Error(b.logger).Log("msg", "failed to replay the write buffer", "err", err)
+			}
+		}
+	}
+}
+
+func (b *writeBuffer) stopping(_ error) error {
+	if b.wal == nil {
+		return nil
+	}
+	return b.wal.Close()
+}
+
+// Buffering returns true if pushes of the tenant are buffered. The following pushes of
+// the tenant must then be appended to the buffer, so that they are written in order.
+func (b *writeBuffer) Buffering(tenantID string) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.bytes[tenantID] > 0
+}
+
+// Append buffers a push. It returns an error if the buffer of the tenant is full.
+func (b *writeBuffer) Append(tenantID string, streams []KeyedStream) error {
+	rec, err := encodeWriteBufferRecord(tenantID, streams)
+	if err != nil {
+		return err
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.bytes[tenantID]+len(rec) > b.limits.WriteBufferMaxBytes(tenantID) {
+		b.droppedPushes.WithLabelValues(tenantID, writeBufferDropReasonLimit).Inc()
+		return errWriteBufferFull
+	}
+	if err := b.wal.Log(rec); err != nil {
+		return err
+	}
+	b.bytes[tenantID] += len(rec)
+	b.bufferedBytes.WithLabelValues(tenantID).Set(float64(b.bytes[tenantID]))
+	b.bufferedPushes.WithLabelValues(tenantID).Inc()
+	return nil
+}
+
+// replayPosition is the position of a record in the log.
+type replayPosition struct {
+	segment, record int
+}
+
+// replayPositionCheckpoint is the checkpointed replay position of a tenant.
+type replayPositionCheckpoint struct {
+	Segment int `json:"segment"`
+	Record  int `json:"record"`
+}
+
+func (p replayPosition) before(other replayPosition) bool {
+	return p.segment < other.segment || p.segment == other.segment && p.record < other.record
+}
+
+// tenantReplay is the replay state of a tenant.
+type tenantReplay struct {
+	// next is the position of the first record of the tenant which is not replayed yet.
+	next    replayPosition
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// replay replays the buffered pushes, and removes the replayed segments from the log.
+// A push failing with a server error is retried by the following replays, with a
+// backoff. Until then, the following pushes of its tenant are kept in the log, so
+// that the pushes of a tenant are replayed in order without delaying the other tenants.
+func (b *writeBuffer) replay(ctx context.Context) error {
+	b.mtx.Lock()
+	buffered := 0
+	for _, size := range b.bytes {
+		buffered += size
+	}
+	if buffered == 0 {
+		b.mtx.Unlock()
+		return nil
+	}
+	// Start a new segment, so that all the buffered pushes are in complete segments.
+	last, err := b.wal.NextSegmentSync()
+	b.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	first, _, err := wlog.Segments(b.cfg.Dir)
+	if err != nil {
+		return err
+	}
+
+	var (
+		now     = time.Now()
+		keep    = last
+		delayed = make(map[string]bool)
+	)
+	for segment := first; segment < last; segment++ {
+		record := -1
+		err := b.forEachRecord(segment, func(tenant string, streams []KeyedStream, size int) bool {
+			record++
+			pos := replayPosition{segment: segment, record: record}
+			state, ok := b.replays[tenant]
+			if !ok {
+				state = &tenantReplay{}
+				b.replays[tenant] = state
+			}
+			if pos.before(state.next) {
+				// Replayed by a previous replay, but kept in the log for another tenant.
+				return true
+			}
+			if delayed[tenant] || now.Before(state.retryAt) {
+				delayed[tenant] = true
+				keep = min(keep, segment)
+				return true
+			}
+
+			_, err := b.write(user.InjectOrgID(ctx, tenant), tenant, streams)
+			switch {
+			case err == nil:
+				b.replayedPushes.WithLabelValues(tenant).Inc()
+				state.backoff = 0
+			case ctx.Err() != nil:
+				return false
+			case isRetryableWriteError(err):
+				level.Debug(b.logger).Log("msg", "failed to replay buffered push, retrying later", "tenant", tenant, "err", err)
+				state.backoff = min(max(2*state.backoff, b.cfg.ReplayMinBackoff), b.cfg.ReplayMaxBackoff)
+				state.retryAt = now.Add(state.backoff)
+				delayed[tenant] = true
+				keep = min(keep, segment)
+				return true
+			default:
+				level.Warn(b.logger).Log("msg", "dropping buffered push rejected by the backends", "tenant", tenant, "err", err)
+				b.droppedPushes.WithLabelValues(tenant, writeBufferDropReasonRejected).Inc()
+			}
+			state.next = replayPosition{segment: segment, record: record + 1}
+			b.release(tenant, size)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return b.saveReplayPositions()
+		}
+	}
+
+	// The state of the tenants whose replayed pushes are all in the removed segments is
+	// not needed anymore.
+	for tenant, state := range b.replays {
+		if !delayed[tenant] && state.next.segment < keep {
+			delete(b.replays, tenant)
+		}
+	}
+	if err := b.saveReplayPositions(); err != nil {
+		return err
+	}
+	return b.wal.Truncate(keep)
+}
+
+// saveReplayPositions checkpoints the replay positions of the tenants, so that the pushes
+// replayed but kept in the log for other tenants are not replayed again after a restart.
+func (b *writeBuffer) saveReplayPositions() error {
+	positions := make(map[string]replayPositionCheckpoint, len(b.replays))
+	for tenant, state := range b.replays {
+		positions[tenant] = replayPositionCheckpoint{Segment: state.next.segment, Record: state.next.record}
+	}
+	data, err := json.Marshal(positions)
+	if err != nil {
+		return err
+	}
+
+	f, err := atomicfs.Create(filepath.Join(b.cfg.Dir, writeBufferReplayPositionsFile))
+	if err != nil {
+		return fmt.Errorf("failed to save the replay positions: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to save the replay positions: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to save the replay positions: %w", err)
+	}
+	return nil
+}
+
+// loadReplayPositions restores the replay positions checkpointed before a restart.
+func (b *writeBuffer) loadReplayPositions() error {
+	data, err := os.ReadFile(filepath.Join(b.cfg.Dir, writeBufferReplayPositionsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var positions map[string]replayPositionCheckpoint
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return err
+	}
+	for tenant, pos := range positions {
+		b.replays[tenant] = &tenantReplay{next: replayPosition{segment: pos.Segment, record: pos.Record}}
+	}
+	return nil
+}
+
+func (b *writeBuffer) release(tenant string, size int) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.bytes[tenant] -= size
+	if b.bytes[tenant] <= 0 {
+		delete(b.bytes, tenant)
+		b.bufferedBytes.DeleteLabelValues(tenant)
+		return
+	}
+	b.bufferedBytes.WithLabelValues(tenant).Set(float64(b.bytes[tenant]))
+}
+
+// forEachRecord calls fn with the pushes of a segment, in order, until fn returns false.
+func (b *writeBuffer) forEachRecord(segment int, fn func(tenant string, streams []KeyedStream, size int) bool) error {
+	rc, err := wlog.NewSegmentsRangeReader(wlog.SegmentRange{Dir: b.cfg.Dir, First: segment, Last: segment})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	r := wlog.NewReader(rc)
+	for r.Next() {
+		rec := r.Record()
+		tenant, streams, err := decodeWriteBufferRecord(rec)
+		if err != nil {
+			level.Warn(b.logger).Log("msg", "dropping corrupted buffered push", "err", err)
+			b.droppedPushes.WithLabelValues("", writeBufferDropReasonCorrupted).Inc()
+			continue
+		}
+		if !fn(tenant, streams, len(rec)) {
+			return nil
+		}
+	}
+	return r.Err()
+}
+
+func encodeWriteBufferRecord(tenantID string, streams []KeyedStream) ([]byte, error) {
+	enc := encoding.EncWith(nil)
+	enc.PutByte(writeBufferRecordV1)
+	enc.PutUvarintStr(tenantID)
+	enc.PutUvarint(len(streams))
+	for _, stream := range streams {
+		b, err := stream.Stream.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		enc.PutUvarintStr(stream.Policy)
+		enc.PutBE32(stream.HashKey)
+		enc.PutBE64(stream.HashKeyNoShard)
+		enc.PutUvarintBytes(b)
+	}
+	return enc.Get(), nil
+}
+
+func decodeWriteBufferRecord(rec []byte) (string, []KeyedStream, error) {
+	dec := encoding.DecWith(rec)
+	if version := dec.Byte(); version != writeBufferRecordV1 {
+		return "", nil, fmt.Errorf("unsupported write buffer record version %d", version)
+	}
+	tenantID := dec.UvarintStr()
+	n := dec.Uvarint()
+	if err := dec.Err(); err != nil {
+		return "", nil, err
+	}
+
+	streams := make([]KeyedStream, 0, n)
+	for i := 0; i < n; i++ {
+		stream := KeyedStream{
+			Policy:         dec.UvarintStr(),
+			HashKey:        dec.Be32(),
+			HashKeyNoShard: dec.Be64(),
+		}
+		b := dec.UvarintBytes()
+		if err := dec.Err(); err != nil {
+			return "", nil, err
+		}
+		if err := stream.Stream.Unmarshal(b); err != nil {
+			return "", nil, err
+		}
+		streams = append(streams, stream)
+	}
+	return tenantID, streams, nil
+}
+
+// isRetryableWriteError returns true if a write failed because the backends are
+// unavailable, and may succeed once they are available again. The writes rejected
+// because of the content of the push, such as validation errors, the canceled writes
+// and the unknown errors are not retried.
+func isRetryableWriteError(err error) bool {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	// The ingesters or the partitions are unavailable.
+	case errors.Is(err, ring.ErrEmptyRing), errors.Is(err, ring.ErrTooManyUnhealthyInstances), errors.Is(err, ring.ErrNoActivePartitionFound):
+		return true
+	// Kafka failed to produce the records in time.
+	case errors.Is(err, kgo.ErrRecordTimeout), errors.Is(err, kgo.ErrRecordRetries), errors.Is(err, kgo.ErrMaxBuffered), kerr.IsRetriable(err):
+		return true
+	}
+
+	// The ingesters reject invalid pushes with a client error.
+	if resp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+		return resp.Code/100 == 5
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+			return true
+		}
+	}
+	return false
+}
+
+// bufferStreams buffers the streams of a push which failed to be written, and returns
+// true if they are buffered.
+func (d *Distributor) bufferStreams(ctx context.Context, tenantID string, streams []KeyedStream, err error) bool {
+	if d.writeBuffer == nil || ctx.Err() != nil || !isRetryableWriteError(err) {
+		return false
+	}
+	if err := d.writeBuffer.Append(tenantID, streams); err != nil {
+		level.Warn(d.logger).Log("msg", "failed to buffer push", "tenant", tenantID, "err", err)
+		return false
+	}
+	level.Debug(d.logger).Log("msg", "buffered push which failed to be written", "tenant", tenantID, "err", err)
+	return true
+}
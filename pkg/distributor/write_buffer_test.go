@@ -0,0 +1,321 @@
+package distributor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+type writeBufferLimits int
+
+func (l writeBufferLimits) WriteBufferMaxBytes(_ string) int {
+	return int(l)
+}
+
+type fakeWriter struct {
+	mtx      sync.Mutex
+	failures int
+	failing  string // tenant whose writes fail until it is reset.
+	err      error
+	written  []string
+}
+
+func (w *fakeWriter) write(ctx context.Context, tenantID string, streams []KeyedStream) ([]KeyedStream, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	orgID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return streams, err
+	}
+	if orgID != tenantID {
+		return streams, errors.New("unexpected tenant")
+	}
+	if w.failures > 0 || w.failing == tenantID {
+		w.failures--
+		return streams, w.err
+	}
+	for _, s := range streams {
+		w.written = append(w.written, tenantID+"/"+s.Stream.Entries[0].Line)
+	}
+	return nil, nil
+}
+
+func (w *fakeWriter) SetFailing(tenantID string) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.failing = tenantID
+}
+
+func (w *fakeWriter) Written() []string {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]string(nil), w.written...)
+}
+
+func newTestWriteBuffer(t *testing.T, dir string, limit int, w *fakeWriter) *writeBuffer {
+	cfg := WriteBufferConfig{
+		Enabled:          true,
+		Dir:              dir,
+		SegmentSize:      wlog.DefaultSegmentSize,
+		ReplayInterval:   10 * time.Millisecond,
+		ReplayMinBackoff: time.Millisecond,
+		ReplayMaxBackoff: time.Millisecond,
+	}
+	return newWriteBuffer(cfg, writeBufferLimits(limit), w.write, prometheus.NewRegistry(), log.NewNopLogger())
+}
+
+func bufferedStreams(line string) []KeyedStream {
+	return []KeyedStream{{
+		HashKey:        1,
+		HashKeyNoShard: 2,
+		Policy:         "policy",
+		Stream: logproto.Stream{
+			Labels:  `{app="api"}`,
+			Hash:    2,
+			Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0).UTC(), Line: line}},
+		},
+	}}
+}
+
+func TestWriteBuffer_ReplaysInOrder(t *testing.T) {
+	w := &fakeWriter{failures: 3, err: httpgrpc.Errorf(http.StatusServiceUnavailable, "unavailable")}
+	b := newTestWriteBuffer(t, t.TempDir(), 1<<20, w)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+	})
+
+	require.NoError(t, b.Append("a", bufferedStreams("1")))
+	require.NoError(t, b.Append("b", bufferedStreams("2")))
+	require.NoError(t, b.Append("a", bufferedStreams("3")))
+
+	require.Eventually(t, func() bool {
+		return len(w.Written()) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	// The pushes of a tenant are replayed in order, and a failing push only delays the
+	// following pushes of its tenant.
+	require.Equal(t, []string{"b/2", "a/1", "a/3"}, w.Written())
+
+	require.Eventually(t, func() bool {
+		b.mtx.Lock()
+		defer b.mtx.Unlock()
+		return len(b.bytes) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 2.0, testutil.ToFloat64(b.replayedPushes.WithLabelValues("a")))
+	require.Equal(t, 1.0, testutil.ToFloat64(b.replayedPushes.WithLabelValues("b")))
+}
+
+func TestWriteBuffer_DoesNotDelayOtherTenants(t *testing.T) {
+	w := &fakeWriter{failing: "a", err: httpgrpc.Errorf(http.StatusServiceUnavailable, "unavailable")}
+	b := newTestWriteBuffer(t, t.TempDir(), 1<<20, w)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+	})
+
+	require.NoError(t, b.Append("a", bufferedStreams("1")))
+	require.NoError(t, b.Append("b", bufferedStreams("2")))
+	require.NoError(t, b.Append("a", bufferedStreams("3")))
+
+	// The pushes of b are replayed while the ones of a are retried.
+	require.Eventually(t, func() bool {
+		return len(w.Written()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"b/2"}, w.Written())
+	require.True(t, b.Buffering("a"))
+	require.False(t, b.Buffering("b"))
+
+	// The pushes of b appended meanwhile are replayed, and the ones of a are replayed in
+	// order once the writes succeed again.
+	require.NoError(t, b.Append("b", bufferedStreams("4")))
+	require.Eventually(t, func() bool {
+		return len(w.Written()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	w.SetFailing("")
+	require.Eventually(t, func() bool {
+		return len(w.Written()) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"b/2", "b/4", "a/1", "a/3"}, w.Written())
+	require.False(t, b.Buffering("a"))
+}
+
+func TestWriteBuffer_DropsRejectedPushes(t *testing.T) {
+	w := &fakeWriter{failures: 1, err: httpgrpc.Errorf(http.StatusBadRequest, "invalid")}
+	b := newTestWriteBuffer(t, t.TempDir(), 1<<20, w)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+	})
+
+	require.NoError(t, b.Append("a", bufferedStreams("1")))
+	require.NoError(t, b.Append("a", bufferedStreams("2")))
+
+	require.Eventually(t, func() bool {
+		return len(w.Written()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"a/2"}, w.Written())
+	require.Equal(t, 1.0, testutil.ToFloat64(b.droppedPushes.WithLabelValues("a", writeBufferDropReasonRejected)))
+}
+
+func TestWriteBuffer_LimitsBufferedBytesPerTenant(t *testing.T) {
+	rec, err := encodeWriteBufferRecord("a", bufferedStreams("1"))
+	require.NoError(t, err)
+
+	w := &fakeWriter{failures: 100, err: errors.New("unavailable")}
+	b := newTestWriteBuffer(t, t.TempDir(), len(rec), w)
+	b.cfg.ReplayInterval = time.Hour
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+	})
+
+	require.NoError(t, b.Append("a", bufferedStreams("1")))
+	require.ErrorIs(t, b.Append("a", bufferedStreams("2")), errWriteBufferFull)
+	require.NoError(t, b.Append("b", bufferedStreams("3")))
+	require.Equal(t, float64(len(rec)), testutil.ToFloat64(b.bufferedBytes.WithLabelValues("a")))
+	require.Equal(t, 1.0, testutil.ToFloat64(b.droppedPushes.WithLabelValues("a", writeBufferDropReasonLimit)))
+}
+
+func TestWriteBuffer_ReplaysAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	w := &fakeWriter{}
+	b := newTestWriteBuffer(t, dir, 1<<20, w)
+	b.cfg.ReplayInterval = time.Hour
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	require.NoError(t, b.Append("a", bufferedStreams("1")))
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+	require.Empty(t, w.Written())
+
+	b = newTestWriteBuffer(t, dir, 1<<20, w)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+	})
+	require.Eventually(t, func() bool {
+		return len(w.Written()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"a/1"}, w.Written())
+}
+
+func TestWriteBuffer_DoesNotReplayAgainAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	w := &fakeWriter{failing: "b", err: httpgrpc.Errorf(http.StatusServiceUnavailable, "unavailable")}
+	b := newTestWriteBuffer(t, dir, 1<<20, w)
+	b.cfg.ReplayInterval = time.Hour
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	require.NoError(t, b.Append("a", bufferedStreams("1")))
+	require.NoError(t, b.Append("b", bufferedStreams("2")))
+
+	// The push of a is replayed, but kept in the log with the push of b.
+	require.NoError(t, b.replay(context.Background()))
+	require.Equal(t, []string{"a/1"}, w.Written())
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+
+	w.SetFailing("")
+	b = newTestWriteBuffer(t, dir, 1<<20, w)
+	b.cfg.ReplayInterval = time.Hour
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+	})
+	require.False(t, b.Buffering("a"))
+	require.True(t, b.Buffering("b"))
+
+	require.NoError(t, b.replay(context.Background()))
+	require.Equal(t, []string{"a/1", "b/2"}, w.Written())
+	require.False(t, b.Buffering("b"))
+}
+
+func TestWriteBufferRecord_RoundTrip(t *testing.T) {
+	streams := bufferedStreams("foo")
+	rec, err := encodeWriteBufferRecord("tenant", streams)
+	require.NoError(t, err)
+
+	tenant, decoded, err := decodeWriteBufferRecord(rec)
+	require.NoError(t, err)
+	require.Equal(t, "tenant", tenant)
+	require.Equal(t, streams, decoded)
+
+	_, _, err = decodeWriteBufferRecord(rec[:len(rec)-1])
+	require.Error(t, err)
+}
+
+func TestIsRetryableWriteError(t *testing.T) {
+	require.True(t, isRetryableWriteError(httpgrpc.Errorf(http.StatusServiceUnavailable, "unavailable")))
+	require.True(t, isRetryableWriteError(status.Error(codes.Unavailable, "connection refused")))
+	require.True(t, isRetryableWriteError(ring.ErrTooManyUnhealthyInstances))
+	require.True(t, isRetryableWriteError(fmt.Errorf("failed to write stream to kafka: %w", kgo.ErrRecordTimeout)))
+	require.True(t, isRetryableWriteError(kerr.NotLeaderForPartition))
+
+	require.False(t, isRetryableWriteError(httpgrpc.Errorf(http.StatusTooManyRequests, "rate limited")))
+	require.False(t, isRetryableWriteError(httpgrpc.Errorf(http.StatusBadRequest, "invalid")))
+	require.False(t, isRetryableWriteError(status.Error(codes.InvalidArgument, "invalid")))
+	require.False(t, isRetryableWriteError(fmt.Errorf("failed to write stream to kafka: %w", kerr.MessageTooLarge)))
+	require.False(t, isRetryableWriteError(context.Canceled))
+	require.False(t, isRetryableWriteError(context.DeadlineExceeded))
+	require.False(t, isRetryableWriteError(errors.New("failed to marshal write request to records")))
+}
+
+func TestDistributor_PushWhileBuffering(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	distributors, ingesters := prepare(t, 1, 3, limits, nil)
+	d := distributors[0]
+
+	cfg := WriteBufferConfig{
+		Enabled:        true,
+		Dir:            t.TempDir(),
+		SegmentSize:    wlog.DefaultSegmentSize,
+		ReplayInterval: time.Hour,
+	}
+	d.writeBuffer = newWriteBuffer(cfg, writeBufferLimits(1<<20), d.writeStreams, prometheus.NewRegistry(), log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), d.writeBuffer))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), d.writeBuffer))
+	})
+	require.NoError(t, d.writeBuffer.Append("test", bufferedStreams("1")))
+
+	// The push is buffered after the buffered push of the tenant rather than written.
+	_, err := d.Push(ctx, makeWriteRequest(10, 64))
+	require.NoError(t, err)
+	require.Equal(t, 2.0, testutil.ToFloat64(d.writeBuffer.bufferedPushes.WithLabelValues("test")))
+	pushed := func(i int) int {
+		ingesters[i].mu.Lock()
+		defer ingesters[i].mu.Unlock()
+		return len(ingesters[i].pushed)
+	}
+	for i := range ingesters {
+		require.Zero(t, pushed(i))
+	}
+
+	require.NoError(t, d.writeBuffer.replay(context.Background()))
+	require.False(t, d.writeBuffer.Buffering("test"))
+	require.Eventually(t, func() bool {
+		return pushed(0) == 2 && pushed(1) == 2 && pushed(2) == 2
+	}, time.Second, 10*time.Millisecond)
+}
@@ -17,6 +17,8 @@ func canExecuteWithNewEngine(expr syntax.Expr) bool {
 				ret = false
 			case *syntax.KeepLabelsExpr, *syntax.DropLabelsExpr:
 				ret = false
+			case *syntax.InExpr:
+				ret = false
 			}
 		})
 		return ret
@@ -3,10 +3,13 @@ package ingester
 import (
 	"fmt"
 	"math"
+	"math/rand"
 	"sync"
 	"time"
+	"unsafe"
 
 	"github.com/grafana/dskit/ring"
+	"github.com/prometheus/prometheus/model/labels"
 	"golang.org/x/time/rate"
 
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
@@ -32,6 +35,7 @@ type Limits interface {
 	MaxLocalStreamsPerUser(userID string) int
 	MaxGlobalStreamsPerUser(userID string) int
 	PerStreamRateLimit(userID string) validation.RateLimit
+	StreamRateLimits(userID string) validation.StreamRateLimitRules
 	ShardStreams(userID string) shardstreams.Config
 	IngestionPartitionsTenantShardSize(userID string) int
 
@@ -238,6 +242,8 @@ func (l *streamCountLimiter) getSuppliers(tenant string) (streamCountSupplier, f
 
 type RateLimiterStrategy interface {
 	RateLimit(tenant string) validation.RateLimit
+	// StreamRateLimit returns the rate limit of a stream, and the rule it comes from if any.
+	StreamRateLimit(tenant string, lbs labels.Labels) (validation.RateLimit, *validation.StreamRateLimitRule)
 	SetDisabled(bool)
 }
 
@@ -254,6 +260,18 @@ func (l *TenantBasedStrategy) RateLimit(tenant string) validation.RateLimit {
 	return l.limits.PerStreamRateLimit(tenant)
 }
 
+func (l *TenantBasedStrategy) StreamRateLimit(tenant string, lbs labels.Labels) (validation.RateLimit, *validation.StreamRateLimitRule) {
+	if l.disabled {
+		return validation.Unlimited, nil
+	}
+
+	limit := l.limits.PerStreamRateLimit(tenant)
+	if rule := l.limits.StreamRateLimits(tenant).Match(lbs); rule != nil {
+		return rule.Limit(limit), rule
+	}
+	return limit, nil
+}
+
 func (l *TenantBasedStrategy) SetDisabled(disabled bool) {
 	l.disabled = disabled
 }
@@ -264,45 +282,173 @@ func (l *NoLimitsStrategy) RateLimit(_ string) validation.RateLimit {
 	return validation.Unlimited
 }
 
+func (l *NoLimitsStrategy) StreamRateLimit(_ string, _ labels.Labels) (validation.RateLimit, *validation.StreamRateLimitRule) {
+	return validation.Unlimited, nil
+}
+
 func (l *NoLimitsStrategy) SetDisabled(_ bool) {
 	// no-op
 }
 
+type rateLimitDecision int
+
+const (
+	rateLimitAccept rateLimitDecision = iota
+	rateLimitReject
+	rateLimitDiscard
+)
+
 type StreamRateLimiter struct {
 	recheckPeriod time.Duration
 	recheckAt     time.Time
 	strategy      RateLimiterStrategy
 	tenant        string
+	labels        labels.Labels
 	lim           *rate.Limiter
+	rule          *validation.StreamRateLimitRule
+
+	// Limiter of the priority lines accepted above the limit, with the priority overflow.
+	priorityLim *rate.Limiter
+
+	// Rate of the stream, measured over windows of one second, used to sample
+	// the lines above the limit.
+	windowStart time.Time
+	windowBytes int
+	streamRate  float64
 }
 
-func NewStreamRateLimiter(strategy RateLimiterStrategy, tenant string, recheckPeriod time.Duration) *StreamRateLimiter {
-	rl := strategy.RateLimit(tenant)
-	return &StreamRateLimiter{
+func NewStreamRateLimiter(strategy RateLimiterStrategy, tenant string, lbs labels.Labels, recheckPeriod time.Duration) *StreamRateLimiter {
+	rl, rule := strategy.StreamRateLimit(tenant, lbs)
+	l := &StreamRateLimiter{
 		recheckPeriod: recheckPeriod,
 		strategy:      strategy,
 		tenant:        tenant,
+		labels:        lbs,
 		lim:           rate.NewLimiter(rl.Limit, rl.Burst),
+		rule:          rule,
 	}
+	l.updatePriorityLimiter(rl)
+	return l
 }
 
 func (l *StreamRateLimiter) AllowN(at time.Time, n int) bool {
-	now := time.Now()
-	if now.After(l.recheckAt) {
-		l.recheckAt = now.Add(l.recheckPeriod)
+	l.recheck()
+	return l.lim.AllowN(at, n)
+}
 
-		oldLim := l.lim.Limit()
-		oldBurst := l.lim.Burst()
+// Admit returns whether an entry of n bytes is accepted, rejected or discarded,
+// according to the overflow behaviour of the rule of the stream.
+func (l *StreamRateLimiter) Admit(at time.Time, line string, n int) rateLimitDecision {
+	l.recheck()
+
+	switch l.overflow() {
+	case validation.StreamRateLimitOverflowSample:
+		// The rate of the stream includes all its lines, and only the lines above the
+		// limit are sampled. The sampled lines are charged to the limiter, so that the
+		// lines accepted within the limit and the sampled lines add up to the limit.
+		ratio := l.sampleRatio(at, n)
+		if !l.lim.AllowN(at, n) {
+			if rand.Float64() >= ratio {
+				return rateLimitDiscard
+			}
+			l.lim.ReserveN(at, n)
+		}
+	case validation.StreamRateLimitOverflowPriority:
+		if !l.lim.AllowN(at, n) && (!l.rule.IsPriorityLine(unsafe.Slice(unsafe.StringData(line), len(line))) || !l.priorityLim.AllowN(at, n)) {
+			return rateLimitDiscard
+		}
+	default:
+		if !l.lim.AllowN(at, n) {
+			return rateLimitReject
+		}
+	}
+	return rateLimitAccept
+}
 
-		next := l.strategy.RateLimit(l.tenant)
+// DiscardsOverflow returns whether the lines above the limit are discarded rather than rejected.
+func (l *StreamRateLimiter) DiscardsOverflow() bool {
+	l.recheck()
+	return l.overflow() != validation.StreamRateLimitOverflowReject
+}
 
-		if oldLim != next.Limit || oldBurst != next.Burst {
-			// Edge case: rate.Inf doesn't advance nicely when reconfigured.
-			// To simplify, we just create a new limiter after reconfiguration rather
-			// than alter the existing one.
-			l.lim = rate.NewLimiter(next.Limit, next.Burst)
+// RuleName returns the name of the stream rate limit rule of the stream, if any.
+func (l *StreamRateLimiter) RuleName() string {
+	if l.rule == nil {
+		return ""
+	}
+	return l.rule.Name
+}
+
+func (l *StreamRateLimiter) overflow() string {
+	if l.rule == nil {
+		return validation.StreamRateLimitOverflowReject
+	}
+	return l.rule.OverflowBehaviour()
+}
+
+// sampleRatio records n bytes in the rate of the stream and returns the ratio
+// of the rate limit over this rate. It returns 0 until the rate of the stream
+// is measured over a first window, so that the lines above the limit are not
+// all accepted meanwhile.
+func (l *StreamRateLimiter) sampleRatio(at time.Time, n int) float64 {
+	if l.windowStart.IsZero() {
+		l.windowStart = at
+	}
+	l.windowBytes += n
+	if elapsed := at.Sub(l.windowStart); elapsed >= time.Second {
+		current := float64(l.windowBytes) / elapsed.Seconds()
+		if l.streamRate == 0 {
+			l.streamRate = current
+		} else {
+			l.streamRate = (l.streamRate + current) / 2
 		}
+		l.windowStart = at
+		l.windowBytes = 0
 	}
 
-	return l.lim.AllowN(at, n)
+	limit := float64(l.lim.Limit())
+	switch {
+	case l.lim.Limit() == rate.Inf:
+		return 1
+	case l.streamRate == 0:
+		return 0
+	case l.streamRate <= limit:
+		return 1
+	}
+	return limit / l.streamRate
+}
+
+func (l *StreamRateLimiter) recheck() {
+	now := time.Now()
+	if !now.After(l.recheckAt) {
+		return
+	}
+	l.recheckAt = now.Add(l.recheckPeriod)
+
+	oldLim := l.lim.Limit()
+	oldBurst := l.lim.Burst()
+
+	next, rule := l.strategy.StreamRateLimit(l.tenant, l.labels)
+	l.rule = rule
+
+	if oldLim != next.Limit || oldBurst != next.Burst {
+		// Edge case: rate.Inf doesn't advance nicely when reconfigured.
+		// To simplify, we just create a new limiter after reconfiguration rather
+		// than alter the existing one.
+		l.lim = rate.NewLimiter(next.Limit, next.Burst)
+	}
+	l.updatePriorityLimiter(next)
+}
+
+// updatePriorityLimiter sets up the limiter of the priority lines accepted above the
+// limit of the stream, if its rule has the priority overflow.
+func (l *StreamRateLimiter) updatePriorityLimiter(limit validation.RateLimit) {
+	if l.overflow() != validation.StreamRateLimitOverflowPriority {
+		l.priorityLim = nil
+		return
+	}
+	next := l.rule.PriorityLimit(limit)
+	if l.priorityLim == nil || l.priorityLim.Limit() != next.Limit || l.priorityLim.Burst() != next.Burst {
+		l.priorityLim = rate.NewLimiter(next.Limit, next.Burst)
+	}
 }
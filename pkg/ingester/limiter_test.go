@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/dskit/ring"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
@@ -368,3 +369,64 @@ func TestConvertGlobalToLocalLimit_PartitionRing(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamRateLimiter_SampleRatio(t *testing.T) {
+	l := &StreamRateLimiter{lim: rate.NewLimiter(100, 100)}
+
+	start := time.Unix(0, 0)
+	// The lines above the limit are discarded until the rate of the stream is measured.
+	require.Equal(t, 0.0, l.sampleRatio(start, 300))
+	// The rate of the stream is 400B/s after the first window, four times the limit.
+	require.Equal(t, 0.25, l.sampleRatio(start.Add(time.Second), 100))
+	// The rate is averaged with the one of the next window.
+	require.Equal(t, 0.5, l.sampleRatio(start.Add(2*time.Second), 0))
+
+	l = &StreamRateLimiter{lim: rate.NewLimiter(rate.Inf, 0)}
+	l.sampleRatio(start, 1000)
+	require.Equal(t, 1.0, l.sampleRatio(start.Add(time.Second), 1000))
+}
+
+func TestStreamRateLimiter_Admit(t *testing.T) {
+	l := validation.Limits{
+		PerStreamRateLimit:      10,
+		PerStreamRateLimitBurst: 10,
+		StreamRateLimits: validation.StreamRateLimitRules{
+			{Name: "payments", Selector: `{app="payments"}`, Overflow: validation.StreamRateLimitOverflowPriority, PriorityFilter: `|= "error"`},
+			{Name: "debug", Selector: `{app="debug"}`, Overflow: validation.StreamRateLimitOverflowSample},
+		},
+	}
+	require.NoError(t, l.StreamRateLimits.Validate())
+	limits, err := validation.NewOverrides(l, nil)
+	require.NoError(t, err)
+	strategy := &TenantBasedStrategy{limits: limits}
+	start := time.Now()
+
+	t.Run("priority lines above the limit are limited", func(t *testing.T) {
+		lim := NewStreamRateLimiter(strategy, "fake", labels.FromStrings("app", "payments"), time.Minute)
+		require.Equal(t, rateLimitAccept, lim.Admit(start, "aaaaaaaaaa", 10))
+		require.Equal(t, rateLimitAccept, lim.Admit(start, "error aaaa", 10))
+		require.Equal(t, rateLimitDiscard, lim.Admit(start, "error bbbb", 10))
+		require.Equal(t, rateLimitDiscard, lim.Admit(start, "bbbbbbbbbb", 10))
+	})
+
+	t.Run("lines within the limit are not sampled", func(t *testing.T) {
+		lim := NewStreamRateLimiter(strategy, "fake", labels.FromStrings("app", "debug"), time.Minute)
+		require.Equal(t, rateLimitAccept, lim.Admit(start, "aaaaaaaaaa", 10))
+		require.Equal(t, rateLimitDiscard, lim.Admit(start, "bbbbbbbbbb", 10))
+		for i := 1; i <= 10; i++ {
+			require.Equal(t, rateLimitAccept, lim.Admit(start.Add(time.Duration(i)*time.Second), "cccccccccc", 10))
+		}
+	})
+
+	t.Run("lines above the limit are sampled down to the limit", func(t *testing.T) {
+		lim := NewStreamRateLimiter(strategy, "fake", labels.FromStrings("app", "debug"), time.Minute)
+		// The stream is pushed at 4 times the limit for 200 seconds.
+		var accepted int
+		for i := 0; i < 800; i++ {
+			if lim.Admit(start.Add(time.Duration(i)*250*time.Millisecond), "aaaaaaaaaa", 10) == rateLimitAccept {
+				accepted += 10
+			}
+		}
+		require.InDelta(t, 2000, accepted, 400)
+	})
+}
@@ -119,7 +119,7 @@ func newStream(
 ) *stream {
 	hashNoShard, _ := labels.HashWithoutLabels(make([]byte, 0, 1024), ShardLbName)
 	return &stream{
-		limiter:              NewStreamRateLimiter(limits, tenant, 10*time.Second),
+		limiter:              NewStreamRateLimiter(limits, tenant, labels, 10*time.Second),
 		cfg:                  cfg,
 		fp:                   fp,
 		labels:               labels,
@@ -393,12 +393,16 @@ func (s *stream) validateEntries(ctx context.Context, entries []logproto.Entry,
 	var (
 		outOfOrderSamples, outOfOrderBytes   int
 		rateLimitedSamples, rateLimitedBytes int
+		discardedSamples, discardedBytes     int
 		validBytes, totalBytes               int
 		failedEntriesWithError               []entryWithError
 		limit                                = s.limiter.lim.Limit()
-		lastLine                             = s.lastLine
-		highestTs                            = s.highestTs
-		toStore                              = make([]logproto.Entry, 0, len(entries))
+		// Lines above the limit are discarded one by one rather than rejected
+		// with the whole stream when the overflow of the stream rule discards them.
+		wholeStream = rateLimitWholeStream && !s.limiter.DiscardsOverflow()
+		lastLine    = s.lastLine
+		highestTs   = s.highestTs
+		toStore     = make([]logproto.Entry, 0, len(entries))
 	)
 
 	for i := range entries {
@@ -418,12 +422,19 @@ func (s *stream) validateEntries(ctx context.Context, entries []logproto.Entry,
 		totalBytes += entryBytes
 
 		now := time.Now()
-		if !rateLimitWholeStream && !s.limiter.AllowN(now, entryBytes) {
-			failedEntriesWithError = append(failedEntriesWithError, entryWithError{&entries[i], &validation.ErrStreamRateLimit{RateLimit: flagext.ByteSize(limit), Labels: s.labelsString, Bytes: flagext.ByteSize(entryBytes)}})
-			s.writeFailures.Log(s.tenant, failedEntriesWithError[len(failedEntriesWithError)-1].e)
-			rateLimitedSamples++
-			rateLimitedBytes += entryBytes
-			continue
+		if !wholeStream {
+			switch s.limiter.Admit(now, entries[i].Line, entryBytes) {
+			case rateLimitReject:
+				failedEntriesWithError = append(failedEntriesWithError, entryWithError{&entries[i], &validation.ErrStreamRateLimit{RateLimit: flagext.ByteSize(limit), Labels: s.labelsString, Bytes: flagext.ByteSize(entryBytes)}})
+				s.writeFailures.Log(s.tenant, failedEntriesWithError[len(failedEntriesWithError)-1].e)
+				rateLimitedSamples++
+				rateLimitedBytes += entryBytes
+				continue
+			case rateLimitDiscard:
+				discardedSamples++
+				discardedBytes += entryBytes
+				continue
+			}
 		}
 
 		// The validity window for unordered writes is the highest timestamp present minus 1/2 * max-chunk-age.
@@ -451,7 +462,7 @@ func (s *stream) validateEntries(ctx context.Context, entries []logproto.Entry,
 	// ingestion, the limiter should only be advanced when the whole stream can be
 	// sent
 	now := time.Now()
-	if rateLimitWholeStream && !s.limiter.AllowN(now, validBytes) {
+	if wholeStream && !s.limiter.AllowN(now, validBytes) {
 		// Report that the whole stream was rate limited
 		rateLimitedSamples = len(toStore)
 		failedEntriesWithError = make([]entryWithError, 0, len(toStore))
@@ -473,9 +484,30 @@ func (s *stream) validateEntries(ctx context.Context, entries []logproto.Entry,
 
 	s.streamRateCalculator.Record(s.tenant, s.labelHash, s.labelHashNoShard, totalBytes)
 	s.reportMetrics(ctx, outOfOrderSamples, outOfOrderBytes, rateLimitedSamples, rateLimitedBytes, usageTracker)
+	s.reportRateLimitRuleMetrics(ctx, rateLimitedSamples, discardedSamples, discardedBytes, usageTracker)
 	return toStore, failedEntriesWithError
 }
 
+// reportRateLimitRuleMetrics reports the entries above the limit of the stream rate limit rule of the stream.
+// The discarded entries are also reported as discarded by the per-stream rate limit.
+func (s *stream) reportRateLimitRuleMetrics(ctx context.Context, rejectedSamples, discardedSamples, discardedBytes int, usageTracker push.UsageTracker) {
+	rule := s.limiter.RuleName()
+	if rule == "" {
+		return
+	}
+	if rejectedSamples > 0 {
+		s.metrics.streamRateLimitedEntries.WithLabelValues(s.tenant, rule, "rejected").Add(float64(rejectedSamples))
+	}
+	if discardedSamples > 0 {
+		s.metrics.streamRateLimitedEntries.WithLabelValues(s.tenant, rule, "discarded").Add(float64(discardedSamples))
+		validation.DiscardedSamples.WithLabelValues(validation.StreamRateLimit, s.tenant, s.retentionHours, s.policy).Add(float64(discardedSamples))
+		validation.DiscardedBytes.WithLabelValues(validation.StreamRateLimit, s.tenant, s.retentionHours, s.policy).Add(float64(discardedBytes))
+		if usageTracker != nil {
+			usageTracker.DiscardedBytesAdd(ctx, s.tenant, validation.StreamRateLimit, s.labels, float64(discardedBytes))
+		}
+	}
+}
+
 func (s *stream) reportMetrics(ctx context.Context, outOfOrderSamples, outOfOrderBytes, rateLimitedSamples, rateLimitedBytes int, usageTracker push.UsageTracker) {
 	if outOfOrderSamples > 0 {
 		name := validation.OutOfOrder
@@ -29,6 +29,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/util/constants"
 	"github.com/grafana/loki/v3/pkg/util/flagext"
 	"github.com/grafana/loki/v3/pkg/validation"
 )
@@ -556,6 +557,94 @@ func TestPushRateLimitAllOrNothing(t *testing.T) {
 	require.Equal(t, 20.0, tracker.discardedBytes)
 }
 
+func TestPushRateLimitRules(t *testing.T) {
+	l := validation.Limits{
+		PerStreamRateLimit:      10,
+		PerStreamRateLimitBurst: 10,
+		StreamRateLimits: validation.StreamRateLimitRules{
+			{Name: "payments", Selector: `{app="payments"}`, Overflow: validation.StreamRateLimitOverflowPriority, PriorityFilter: `|= "error"`},
+			{Name: "debug", Selector: `{app=~".+"}`, Overflow: validation.StreamRateLimitOverflowSample},
+			{Name: "audit", Selector: `{app="audit"}`, Priority: 1, RateLimit: 20, RateLimitBurst: 20},
+		},
+	}
+	require.NoError(t, l.StreamRateLimits.Validate())
+	limits, err := validation.NewOverrides(l, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, newIngesterRingLimiterStrategy(&ringCountMock{count: 1}, 1), &TenantBasedStrategy{limits: limits})
+	retentionHours := util.RetentionHours(limiter.limits.RetentionPeriod("fake"))
+	chunkfmt, headfmt := defaultChunkFormat(t)
+	metrics := newIngesterMetrics(prometheus.NewRegistry(), constants.Loki)
+
+	newTestStream := func(app string) *stream {
+		return newStream(
+			chunkfmt,
+			headfmt,
+			defaultConfig(),
+			limiter.rateLimitStrategy,
+			"fake",
+			model.Fingerprint(0),
+			labels.Labels{
+				{Name: "app", Value: app},
+			},
+			true,
+			NewStreamRateCalculator(),
+			metrics,
+			nil,
+			nil,
+			retentionHours,
+		)
+	}
+
+	for _, tc := range []struct {
+		app                  string
+		rateLimitWholeStream bool
+		expectedErr          bool
+		expectedEntries      int
+		expectedOutcomes     map[string]float64
+	}{
+		{
+			// Lines above the limit are only kept when they match the priority filter.
+			app:              "payments",
+			expectedEntries:  2,
+			expectedOutcomes: map[string]float64{"discarded": 1},
+		},
+		{
+			// Lines above the limit are discarded rather than rejected, even with all or nothing ingestion.
+			app:                  "web",
+			rateLimitWholeStream: true,
+			expectedEntries:      1,
+			expectedOutcomes:     map[string]float64{"discarded": 2},
+		},
+		{
+			// The rule with the highest priority applies.
+			app:              "audit",
+			expectedErr:      true,
+			expectedEntries:  2,
+			expectedOutcomes: map[string]float64{"rejected": 1},
+		},
+	} {
+		t.Run(tc.app, func(t *testing.T) {
+			s := newTestStream(tc.app)
+			entries := []logproto.Entry{
+				{Timestamp: time.Unix(1, 0), Line: "aaaaaaaaaa"},
+				{Timestamp: time.Unix(2, 0), Line: "bbbbbbbbbb"},
+				{Timestamp: time.Unix(3, 0), Line: "error cccc"},
+			}
+			_, err := s.Push(context.Background(), entries, recordPool.GetRecord(), 0, true, tc.rateLimitWholeStream, nil)
+			if tc.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectedEntries, s.chunks[0].chunk.Size())
+
+			for _, outcome := range []string{"rejected", "discarded"} {
+				require.Equal(t, tc.expectedOutcomes[outcome], testutil.ToFloat64(metrics.streamRateLimitedEntries.WithLabelValues("fake", s.limiter.RuleName(), outcome)), outcome)
+			}
+		})
+	}
+}
+
 func TestReplayAppendIgnoresValidityWindow(t *testing.T) {
 	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
@@ -160,6 +160,7 @@ func TestEncodeDecodeStreamMetadata(t *testing.T) {
 		tenantID               string
 		entriesSize            uint64
 		structuredMetadataSize uint64
+		policy                 string
 		expectErr              bool
 	}{
 		{
@@ -172,6 +173,17 @@ func TestEncodeDecodeStreamMetadata(t *testing.T) {
 			structuredMetadataSize: 512,
 			expectErr:              false,
 		},
+		{
+			name:                   "Valid metadata with policy",
+			hash:                   23456,
+			partition:              1,
+			topic:                  "logs",
+			tenantID:               "tenant-1",
+			entriesSize:            1024,
+			structuredMetadataSize: 512,
+			policy:                 "staging",
+			expectErr:              false,
+		},
 		{
 			name:                   "Valid metadata with zero sizes",
 			hash:                   67890,
@@ -197,7 +209,7 @@ func TestEncodeDecodeStreamMetadata(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Encode metadata
-			record, err := EncodeStreamMetadata(tt.partition, tt.topic, tt.tenantID, tt.hash, tt.entriesSize, tt.structuredMetadataSize)
+			record, err := EncodeStreamMetadata(tt.partition, tt.topic, tt.tenantID, tt.hash, tt.entriesSize, tt.structuredMetadataSize, tt.policy)
 			if tt.expectErr {
 				require.Error(t, err)
 				require.Nil(t, record)
@@ -219,6 +231,7 @@ func TestEncodeDecodeStreamMetadata(t *testing.T) {
 			require.Equal(t, tt.hash, metadata.StreamHash)
 			require.Equal(t, tt.entriesSize, metadata.EntriesSize)
 			require.Equal(t, tt.structuredMetadataSize, metadata.StructuredMetadataSize)
+			require.Equal(t, tt.policy, metadata.Policy)
 		})
 	}
 
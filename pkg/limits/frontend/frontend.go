@@ -8,6 +8,7 @@ package frontend
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/go-kit/log"
@@ -20,6 +21,7 @@ import (
 
 	limits_client "github.com/grafana/loki/v3/pkg/limits/client"
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
 )
 
 const (
@@ -30,6 +32,14 @@ const (
 	// ReasonExceedsRateLimit is returned when a tenant exceeds their maximum
 	// rate limit as per their per-tenant limit.
 	ReasonExceedsRateLimit = "exceeds_rate_limit"
+
+	// ReasonExceedsPolicyMaxStreams is returned when the streams of a policy
+	// exceed the maximum number of active streams of the policy.
+	ReasonExceedsPolicyMaxStreams = "exceeds_policy_max_streams"
+
+	// ReasonExceedsPolicyRateLimit is returned when the streams of a policy
+	// exceed the rate limit of the policy.
+	ReasonExceedsPolicyRateLimit = "exceeds_policy_rate_limit"
 )
 
 type metrics struct {
@@ -50,8 +60,8 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 		}, []string{"tenant"}),
 		tenantRejectedStreams: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 			Name: "loki_ingest_limits_frontend_streams_rejected_total",
-			Help: "The total number of rejected streams per tenant when the global limit is exceeded.",
-		}, []string{"tenant", "reason"}),
+			Help: "The total number of rejected streams per tenant and policy when the global limit is exceeded. The policy is empty for tenant limits.",
+		}, []string{"tenant", "reason", "policy"}),
 	}
 }
 
@@ -190,31 +200,17 @@ func (f *Frontend) ExceedsLimits(ctx context.Context, req *logproto.ExceedsLimit
 	// Check if max streams limit would be exceeded.
 	maxGlobalStreams := f.limits.MaxGlobalStreamsPerUser(req.Tenant)
 	if activeStreamsTotal >= uint64(maxGlobalStreams) {
-		// Take the intersection of unknown streams from all responses by counting
-		// the number of occurrences. If the number of occurrences matches the
-		// number of responses, we know the stream was unknown to all instances.
-		unknownStreams := make(map[uint64]int)
-		for _, resp := range resps {
-			for _, unknownStream := range resp.Response.UnknownStreams {
-				unknownStreams[unknownStream]++
-			}
-		}
-		for _, resp := range resps {
-			for _, unknownStream := range resp.Response.UnknownStreams {
-				// If the stream is unknown to all instances, it must be a new
-				// stream.
-				if unknownStreams[unknownStream] == len(resps) {
-					rejectedStreams = append(rejectedStreams, &logproto.RejectedStream{
-						StreamHash: unknownStream,
-						Reason:     ReasonExceedsMaxStreams,
-					})
-				}
-			}
+		for _, streamHash := range newStreams(resps) {
+			rejectedStreams = append(rejectedStreams, &logproto.RejectedStream{
+				StreamHash: streamHash,
+				Reason:     ReasonExceedsMaxStreams,
+			})
 		}
 	}
 	f.metrics.tenantRejectedStreams.WithLabelValues(
 		req.Tenant,
 		ReasonExceedsMaxStreams,
+		"",
 	).Add(float64(len(rejectedStreams)))
 
 	// Check if rate limits would be exceeded.
@@ -230,9 +226,17 @@ func (f *Frontend) ExceedsLimits(ctx context.Context, req *logproto.ExceedsLimit
 		f.metrics.tenantRejectedStreams.WithLabelValues(
 			req.Tenant,
 			ReasonExceedsRateLimit,
+			"",
 		).Add(float64(len(streamHashes)))
 	}
 
+	// Check if the limits of the policies of the streams would be exceeded.
+	rejectedPolicyStreams, err := f.exceedsPolicyLimits(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	rejectedStreams = append(rejectedStreams, rejectedPolicyStreams...)
+
 	if len(rejectedStreams) > 0 {
 		f.metrics.tenantExceedsLimits.WithLabelValues(req.Tenant).Inc()
 	}
@@ -266,3 +270,102 @@ func (f *Frontend) Flush() {}
 func (f *Frontend) TransferOut(_ context.Context) error {
 	return nil
 }
+
+// exceedsPolicyLimits returns the streams of the request rejected because the
+// streams of their policy exceed the limits of the policy. Streams which do
+// not match any policy are subject to the limits of the global policy.
+func (f *Frontend) exceedsPolicyLimits(ctx context.Context, req *logproto.ExceedsLimitsRequest) ([]*logproto.RejectedStream, error) {
+	streamsByPolicy := make(map[string][]uint64)
+	for _, stream := range req.Streams {
+		policy := validation.PolicyOrGlobal(stream.Policy)
+		streamsByPolicy[policy] = append(streamsByPolicy[policy], stream.StreamHash)
+	}
+	policies := make([]string, 0, len(streamsByPolicy))
+	for policy := range streamsByPolicy {
+		policies = append(policies, policy)
+	}
+	sort.Strings(policies)
+
+	var rejectedStreams []*logproto.RejectedStream
+	for _, policy := range policies {
+		maxStreams := f.limits.PolicyMaxGlobalStreamsPerUser(req.Tenant, policy)
+		rateLimit := f.limits.PolicyIngestionRateBytes(req.Tenant, policy)
+		if maxStreams <= 0 && rateLimit <= 0 {
+			continue
+		}
+
+		streamHashes := streamsByPolicy[policy]
+		resps, err := f.streamUsage.GetStreamUsage(ctx, GetStreamUsageRequest{
+			Tenant:       req.Tenant,
+			StreamHashes: streamHashes,
+			Policy:       policy,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			activeStreamsTotal uint64
+			rateTotal          float64
+		)
+		for _, resp := range resps {
+			activeStreamsTotal += resp.Response.ActiveStreams
+			rateTotal += float64(resp.Response.Rate)
+		}
+
+		if maxStreams > 0 && activeStreamsTotal >= uint64(maxStreams) {
+			newPolicyStreams := newStreams(resps)
+			for _, streamHash := range newPolicyStreams {
+				rejectedStreams = append(rejectedStreams, &logproto.RejectedStream{
+					StreamHash: streamHash,
+					Reason:     ReasonExceedsPolicyMaxStreams,
+				})
+			}
+			f.metrics.tenantRejectedStreams.WithLabelValues(
+				req.Tenant,
+				ReasonExceedsPolicyMaxStreams,
+				policy,
+			).Add(float64(len(newPolicyStreams)))
+		}
+
+		if rateLimit > 0 && rateTotal > rateLimit {
+			// Rate limit would be exceeded, all streams of the policy must be rejected.
+			for _, streamHash := range streamHashes {
+				rejectedStreams = append(rejectedStreams, &logproto.RejectedStream{
+					StreamHash: streamHash,
+					Reason:     ReasonExceedsPolicyRateLimit,
+				})
+			}
+			f.metrics.tenantRejectedStreams.WithLabelValues(
+				req.Tenant,
+				ReasonExceedsPolicyRateLimit,
+				policy,
+			).Add(float64(len(streamHashes)))
+		}
+	}
+	return rejectedStreams, nil
+}
+
+// newStreams returns the streams unknown to all instances. Such streams must
+// be new streams.
+func newStreams(resps []GetStreamUsageResponse) []uint64 {
+	if len(resps) == 0 {
+		return nil
+	}
+	// Take the intersection of unknown streams from all responses by counting
+	// the number of occurrences. If the number of occurrences matches the
+	// number of responses, we know the stream was unknown to all instances.
+	unknownStreams := make(map[uint64]int)
+	for _, resp := range resps {
+		for _, unknownStream := range resp.Response.UnknownStreams {
+			unknownStreams[unknownStream]++
+		}
+	}
+	var result []uint64
+	for _, unknownStream := range resps[0].Response.UnknownStreams {
+		if unknownStreams[unknownStream] == len(resps) {
+			result = append(result, unknownStream)
+		}
+	}
+	return result
+}
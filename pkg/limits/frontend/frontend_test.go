@@ -329,3 +329,125 @@ func TestFrontend_ExceedsLimits(t *testing.T) {
 		})
 	}
 }
+
+func TestFrontend_ExceedsLimits_PolicyLimits(t *testing.T) {
+	tests := []struct {
+		name                   string
+		streams                []*logproto.StreamMetadata
+		responses              map[string][]GetStreamUsageResponse
+		policyMaxGlobalStreams map[string]int
+		policyIngestionRate    map[string]float64
+		expectedRequests       map[string][]uint64
+		expected               []*logproto.RejectedStream
+	}{{
+		name: "no policy limits",
+		streams: []*logproto.StreamMetadata{
+			{StreamHash: 0x1, Policy: "staging"},
+			{StreamHash: 0x2},
+		},
+		responses: map[string][]GetStreamUsageResponse{
+			"": {{Response: &logproto.GetStreamUsageResponse{ActiveStreams: 2, Rate: 10}}},
+		},
+		expectedRequests: map[string][]uint64{
+			"": {0x1, 0x2},
+		},
+	}, {
+		name: "exceeds policy max streams limit, rejects new streams of the policy",
+		streams: []*logproto.StreamMetadata{
+			{StreamHash: 0x1, Policy: "staging"},
+			{StreamHash: 0x2, Policy: "staging"},
+			{StreamHash: 0x3, Policy: "prod"},
+		},
+		responses: map[string][]GetStreamUsageResponse{
+			"": {{Response: &logproto.GetStreamUsageResponse{
+				ActiveStreams:  2,
+				UnknownStreams: []uint64{0x2, 0x3},
+			}}},
+			"staging": {{Response: &logproto.GetStreamUsageResponse{
+				ActiveStreams:  1,
+				UnknownStreams: []uint64{0x2},
+			}}},
+		},
+		policyMaxGlobalStreams: map[string]int{"staging": 1},
+		expectedRequests: map[string][]uint64{
+			"":        {0x1, 0x2, 0x3},
+			"staging": {0x1, 0x2},
+		},
+		expected: []*logproto.RejectedStream{
+			{StreamHash: 0x2, Reason: ReasonExceedsPolicyMaxStreams},
+		},
+	}, {
+		name: "exceeds policy rate limit, rejects all streams of the policy",
+		streams: []*logproto.StreamMetadata{
+			{StreamHash: 0x1, Policy: "staging"},
+			{StreamHash: 0x2, Policy: "staging"},
+			{StreamHash: 0x3, Policy: "prod"},
+		},
+		responses: map[string][]GetStreamUsageResponse{
+			"": {{Response: &logproto.GetStreamUsageResponse{ActiveStreams: 3, Rate: 150}}},
+			"staging": {
+				{Response: &logproto.GetStreamUsageResponse{ActiveStreams: 1, Rate: 60}},
+				{Response: &logproto.GetStreamUsageResponse{ActiveStreams: 1, Rate: 60}},
+			},
+			"prod": {{Response: &logproto.GetStreamUsageResponse{ActiveStreams: 1, Rate: 30}}},
+		},
+		policyIngestionRate: map[string]float64{"staging": 100, "prod": 100},
+		expectedRequests: map[string][]uint64{
+			"":        {0x1, 0x2, 0x3},
+			"prod":    {0x3},
+			"staging": {0x1, 0x2},
+		},
+		expected: []*logproto.RejectedStream{
+			{StreamHash: 0x1, Reason: ReasonExceedsPolicyRateLimit},
+			{StreamHash: 0x2, Reason: ReasonExceedsPolicyRateLimit},
+		},
+	}, {
+		name: "streams without a policy are subject to the limits of the global policy",
+		streams: []*logproto.StreamMetadata{
+			{StreamHash: 0x1},
+			{StreamHash: 0x2, Policy: "staging"},
+		},
+		responses: map[string][]GetStreamUsageResponse{
+			"": {{Response: &logproto.GetStreamUsageResponse{ActiveStreams: 2, Rate: 150}}},
+			"*": {{Response: &logproto.GetStreamUsageResponse{
+				ActiveStreams:  5,
+				UnknownStreams: []uint64{0x1},
+				Rate:           150,
+			}}},
+		},
+		policyMaxGlobalStreams: map[string]int{"*": 5},
+		expectedRequests: map[string][]uint64{
+			"":  {0x1, 0x2},
+			"*": {0x1},
+		},
+		expected: []*logproto.RejectedStream{
+			{StreamHash: 0x1, Reason: ReasonExceedsPolicyMaxStreams},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := &mockLimits{
+				maxGlobalStreams:       100,
+				ingestionRate:          1000,
+				policyMaxGlobalStreams: test.policyMaxGlobalStreams,
+				policyIngestionRate:    test.policyIngestionRate,
+			}
+			g := &mockPolicyStreamUsageGatherer{responses: test.responses}
+			f := Frontend{
+				limits:      l,
+				rateLimiter: limiter.NewRateLimiter(newRateLimitsAdapter(l), 10*time.Second),
+				streamUsage: g,
+				metrics:     newMetrics(prometheus.NewRegistry()),
+			}
+
+			resp, err := f.ExceedsLimits(context.Background(), &logproto.ExceedsLimitsRequest{
+				Tenant:  "test",
+				Streams: test.streams,
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, resp.RejectedStreams)
+			require.Equal(t, test.expectedRequests, g.requests)
+		})
+	}
+}
@@ -17,6 +17,9 @@ type StreamUsageGatherer interface {
 type GetStreamUsageRequest struct {
 	Tenant       string
 	StreamHashes []uint64
+	// Policy restricts the active streams and rate to those of the streams
+	// of the policy. It is empty to get the usage of all streams.
+	Policy string
 }
 
 type GetStreamUsageResponse struct {
@@ -5,6 +5,8 @@ type Limits interface {
 	IngestionRateBytes(userID string) float64
 	IngestionBurstSizeBytes(userID string) int
 	MaxGlobalStreamsPerUser(userID string) int
+	PolicyIngestionRateBytes(userID string, policy string) float64
+	PolicyMaxGlobalStreamsPerUser(userID string, policy string) int
 }
 
 // rateLimitsAdapter implements the dskit.RateLimiterStrategy interface. We use
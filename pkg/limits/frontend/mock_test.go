@@ -32,6 +32,24 @@ func (g *mockStreamUsageGatherer) GetStreamUsage(_ context.Context, r GetStreamU
 	return g.responses, nil
 }
 
+// mockPolicyStreamUsageGatherer mocks a StreamUsageGatherer that returns
+// different responses per policy.
+type mockPolicyStreamUsageGatherer struct {
+	// The mocked responses for each policy. The policy is empty when usage
+	// for all streams is requested.
+	responses map[string][]GetStreamUsageResponse
+	// The stream hashes requested for each policy.
+	requests map[string][]uint64
+}
+
+func (g *mockPolicyStreamUsageGatherer) GetStreamUsage(_ context.Context, r GetStreamUsageRequest) ([]GetStreamUsageResponse, error) {
+	if g.requests == nil {
+		g.requests = make(map[string][]uint64)
+	}
+	g.requests[r.Policy] = r.StreamHashes
+	return g.responses[r.Policy], nil
+}
+
 // mockIngestLimitsClient mocks logproto.IngestLimitsClient.
 type mockIngestLimitsClient struct {
 	logproto.IngestLimitsClient
@@ -99,8 +117,10 @@ func (m *mockReadRing) GetAllHealthy(_ ring.Operation) (ring.ReplicationSet, err
 }
 
 type mockLimits struct {
-	maxGlobalStreams int
-	ingestionRate    float64
+	maxGlobalStreams       int
+	ingestionRate          float64
+	policyMaxGlobalStreams map[string]int
+	policyIngestionRate    map[string]float64
 }
 
 func (m *mockLimits) MaxGlobalStreamsPerUser(_ string) int {
@@ -115,6 +135,14 @@ func (m *mockLimits) IngestionBurstSizeBytes(_ string) int {
 	return 1000
 }
 
+func (m *mockLimits) PolicyIngestionRateBytes(_ string, policy string) float64 {
+	return m.policyIngestionRate[policy]
+}
+
+func (m *mockLimits) PolicyMaxGlobalStreamsPerUser(_ string, policy string) int {
+	return m.policyMaxGlobalStreams[policy]
+}
+
 func newMockRingWithClientPool(_ *testing.T, name string, clients []logproto.IngestLimitsClient, instances []ring.InstanceDesc) (ring.ReadRing, *ring_client.Pool) {
 	// Set up the mock ring.
 	ring := &mockReadRing{
@@ -75,6 +75,7 @@ func (g *RingStreamUsageGatherer) forGivenReplicaSet(ctx context.Context, rs rin
 				Tenant:       r.Tenant,
 				StreamHashes: r.StreamHashes,
 				Partitions:   partitions[instance.Addr],
+				Policy:       r.Policy,
 			}
 
 			resp, err := client.(logproto.IngestLimitsClient).GetStreamUsage(ctx, protoReq)
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/validation"
 )
 
 const (
@@ -95,6 +96,9 @@ type streamMetadata struct {
 	hash       uint64
 	lastSeenAt int64
 	totalSize  uint64
+	// policy is the ingestion policy of the stream, or empty if the stream
+	// does not match any policy.
+	policy string
 	// Add a slice to track bytes per time interval for sliding window rate calculation
 	rateBuckets []rateBucket
 }
@@ -486,6 +490,7 @@ func (s *IngestLimits) updateMetadata(rec *logproto.StreamMetadata, tenant strin
 				hash:        stream.hash,
 				lastSeenAt:  recordTime,
 				totalSize:   totalSize,
+				policy:      rec.Policy,
 				rateBuckets: sb,
 			}
 			return
@@ -497,6 +502,7 @@ func (s *IngestLimits) updateMetadata(rec *logproto.StreamMetadata, tenant strin
 		hash:        rec.StreamHash,
 		lastSeenAt:  recordTime,
 		totalSize:   recTotalSize,
+		policy:      rec.Policy,
 		rateBuckets: []rateBucket{{timestamp: bucketStart, size: recTotalSize}},
 	})
 }
@@ -533,6 +539,8 @@ func (s *IngestLimits) GetAssignedPartitions(_ context.Context, _ *logproto.GetA
 
 // GetStreamUsage implements the logproto.IngestLimitsServer interface.
 // It returns the number of active streams for a tenant and the status of requested streams.
+// If the request has a policy, only the active streams and rate of the streams of the
+// policy are returned.
 func (s *IngestLimits) GetStreamUsage(_ context.Context, req *logproto.GetStreamUsageRequest) (*logproto.GetStreamUsageResponse, error) {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
@@ -585,6 +593,12 @@ func (s *IngestLimits) GetStreamUsage(_ context.Context, req *logproto.GetStream
 				continue
 			}
 
+			// Streams which do not match any policy are streams of the
+			// global policy.
+			if req.Policy != "" && validation.PolicyOrGlobal(stream.policy) != req.Policy {
+				continue
+			}
+
 			activeStreams++
 
 			// Calculate size only within the rate window
@@ -624,6 +638,7 @@ func (s *IngestLimits) GetStreamUsage(_ context.Context, req *logproto.GetStream
 	level.Debug(s.logger).Log(
 		"msg", "calculated stream usage",
 		"tenant", req.Tenant,
+		"policy", req.Policy,
 		"active_streams", activeStreams,
 		"total_size", util.HumanizeBytes(totalSize),
 		"rate_window_seconds", s.cfg.RateWindow.Seconds(),
@@ -30,6 +30,7 @@ func TestIngestLimits_GetStreamUsage(t *testing.T) {
 		tenantID     string
 		partitionIDs []int32
 		streamHashes []uint64
+		policy       string
 
 		// Expectations.
 		expectedActive         uint64
@@ -107,6 +108,31 @@ func TestIngestLimits_GetStreamUsage(t *testing.T) {
 			expectedActive: 3,
 			expectedRate:   int64(9000) / int64(5*60), // 9000 bytes / 5 minutes in seconds
 		},
+		{
+			name: "only streams of the requested policy",
+			// setup data
+			assignedPartitionIDs: []int32{0},
+			metadata: map[string]map[int32][]streamMetadata{
+				"tenant1": {
+					0: []streamMetadata{
+						{hash: 1, lastSeenAt: time.Now().UnixNano(), totalSize: 1000, policy: "staging", rateBuckets: []rateBucket{{timestamp: time.Now().UnixNano(), size: 1000}}},
+						{hash: 2, lastSeenAt: time.Now().UnixNano(), totalSize: 2000, rateBuckets: []rateBucket{{timestamp: time.Now().UnixNano(), size: 2000}}},
+						{hash: 3, lastSeenAt: time.Now().UnixNano(), totalSize: 3000, policy: "staging", rateBuckets: []rateBucket{{timestamp: time.Now().UnixNano(), size: 3000}}},
+					},
+				},
+			},
+			windowSize:     time.Hour,
+			rateWindow:     5 * time.Minute,
+			bucketDuration: time.Minute,
+			// request data
+			tenantID:     "tenant1",
+			partitionIDs: []int32{0},
+			streamHashes: []uint64{1, 2},
+			policy:       "staging",
+			// expectations
+			expectedActive: 2,
+			expectedRate:   int64(4000) / int64(5*60), // 4000 bytes / 5 minutes in seconds
+		},
 		{
 			name: "all streams expired",
 			// setup data
@@ -314,6 +340,7 @@ func TestIngestLimits_GetStreamUsage(t *testing.T) {
 				Tenant:       tt.tenantID,
 				Partitions:   tt.partitionIDs,
 				StreamHashes: tt.streamHashes,
+				Policy:       tt.policy,
 			}
 
 			resp, err := s.GetStreamUsage(context.Background(), req)
@@ -0,0 +1,185 @@
+package push
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/grafana/dskit/httpgrpc"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+const (
+	ElasticsearchBulkPath = "/elasticsearch/_bulk"
+
+	elasticsearchIndexField = "_index"
+)
+
+// DefaultElasticsearchBulkConfig maps the index of the documents to a label, and the
+// fields of the Elastic Common Schema to the timestamp and line of the log entries.
+var DefaultElasticsearchBulkConfig = FieldMappingConfig{
+	LabelFields:    []string{elasticsearchIndexField},
+	TimestampField: "@timestamp",
+	MessageField:   "message",
+}
+
+// ParseElasticsearchBulkRequest parses requests in the newline delimited JSON format of
+// the Elasticsearch bulk API. Documents of index and create actions are mapped to log
+// entries with the per-tenant Elasticsearch bulk configuration. The `_index` field of
+// the documents is the index of their action, or the one of the request path.
+func ParseElasticsearchBulkRequest(userID string, r *http.Request, limits Limits, tracker UsageTracker, streamResolver StreamResolver, _ bool, _ log.Logger) (*logproto.PushRequest, *Stats, error) {
+	pushStats := NewPushStats()
+	body, err := decodeBody(r, pushStats)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defaultIndex := mux.Vars(r)["index"]
+	mapper := newFieldMapper(limits.ElasticsearchBulkConfig(userID), time.Millisecond)
+
+	// index is the index of the current action, or nil if the next object is an action.
+	var index *string
+	err = decodeJSONObjects(body, func(obj map[string]any) error {
+		if index == nil {
+			if len(obj) != 1 {
+				return fmt.Errorf("invalid bulk action: expected a single action, got %d", len(obj))
+			}
+			for name, v := range obj {
+				if name != "index" && name != "create" {
+					return fmt.Errorf("unsupported bulk action %q: only index and create actions are supported", name)
+				}
+				actionIndex := defaultIndex
+				if meta, ok := v.(map[string]any); ok {
+					if v, ok := meta[elasticsearchIndexField].(string); ok {
+						actionIndex = v
+					}
+				}
+				index = &actionIndex
+			}
+			return nil
+		}
+
+		docIndex := *index
+		index = nil
+		return mapper.add(obj, map[string]any{elasticsearchIndexField: docIndex}, obj)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if index != nil {
+		return nil, nil, errors.New("invalid bulk request: action without document")
+	}
+
+	req := mapper.pushRequest(r.Context(), userID, tracker, streamResolver, pushStats)
+	if results := EntryResultsFromContext(r.Context()); results != nil {
+		results.Order = mapper.entryOrder()
+	}
+	return req, pushStats, nil
+}
+
+var _ RequestParser = ParseElasticsearchBulkRequest
+
+// ElasticsearchBulkResponse writes the response of a bulk request, with an item for each of
+// the documents in the order of the request. Documents rejected by the validation of the
+// distributor have the status and the reason of their error, and documents dropped by the
+// ingest pipeline the result noop.
+func ElasticsearchBulkResponse(w http.ResponseWriter, results *EntryResults, logger log.Logger) {
+	type itemError struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	type item struct {
+		Status int        `json:"status"`
+		Result string     `json:"result,omitempty"`
+		Error  *itemError `json:"error,omitempty"`
+	}
+
+	hasErrors := false
+	items := make([]map[string]item, 0)
+	if results != nil {
+		for _, pos := range results.Order {
+			res := results.Result(pos)
+			switch {
+			case res.Err != nil:
+				hasErrors = true
+				code := http.StatusBadRequest
+				if resp, ok := httpgrpc.HTTPResponseFromError(res.Err); ok {
+					code = int(resp.Code)
+				}
+				items = append(items, map[string]item{"create": {
+					Status: code,
+					Error:  &itemError{Type: elasticsearchErrorType(code), Reason: res.Err.Error()},
+				}})
+			case res.Dropped:
+				items = append(items, map[string]item{"create": {Status: http.StatusOK, Result: "noop"}})
+			default:
+				items = append(items, map[string]item{"create": {Status: http.StatusCreated, Result: "created"}})
+			}
+		}
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]any{
+		"took":   0,
+		"errors": hasErrors,
+		"items":  items,
+	}, logger)
+}
+
+// ElasticsearchError writes errors in the format of the Elasticsearch API. 500 errors are
+// mapped to 503, so that clients retry the requests.
+func ElasticsearchError(w http.ResponseWriter, errorStr string, code int, logger log.Logger) {
+	if code == http.StatusInternalServerError {
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSONResponse(w, code, map[string]any{
+		"error": map[string]string{
+			"type":   elasticsearchErrorType(code),
+			"reason": errorStr,
+		},
+		"status": code,
+	}, logger)
+}
+
+func elasticsearchErrorType(code int) string {
+	switch code {
+	case http.StatusBadRequest:
+		return "illegal_argument_exception"
+	case http.StatusTooManyRequests:
+		return "es_rejected_execution_exception"
+	}
+	return "exception"
+}
+
+var _ ErrorWriter = ElasticsearchError
+
+// ElasticsearchInfoHandler answers the requests that Elasticsearch clients send to check
+// the version of the cluster before sending bulk requests.
+func ElasticsearchInfoHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	writeJSONResponse(w, http.StatusOK, map[string]any{
+		"name":         "loki",
+		"cluster_name": "loki",
+		"version": map[string]string{
+			"number":                              "8.11.0",
+			"build_flavor":                        "default",
+			"minimum_wire_compatibility_version":  "7.17.0",
+			"minimum_index_compatibility_version": "7.0.0",
+		},
+		"tagline": "You Know, for Search",
+	}, nil)
+}
+
+func writeJSONResponse(w http.ResponseWriter, code int, v any, logger log.Logger) {
+	w.Header().Set(contentType, applicationJSON)
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil && logger != nil {
+		level.Error(logger).Log("msg", "failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package push
+
+import (
+	"context"
+)
+
+// EntryPosition identifies an entry of a push request by the index of its stream and its
+// index in the stream, as the request was parsed.
+type EntryPosition struct {
+	Stream int
+	Entry  int
+}
+
+// EntryResult is the outcome of an entry of a push request. The zero value is an entry
+// which was accepted.
+type EntryResult struct {
+	// Dropped is true if the entry was discarded without error, for example by the
+	// ingest pipeline of the tenant.
+	Dropped bool
+	// Err is the error the entry was rejected with.
+	Err error
+}
+
+// EntryResults records the outcome of each entry of a push request, for the handlers
+// which report them to the clients. It is attached to the context of the request, so
+// that the parser can record the order the entries were received in and the distributor
+// the entries it drops or rejects.
+type EntryResults struct {
+	// Order is the order the entries were received in.
+	Order   []EntryPosition
+	results map[EntryPosition]EntryResult
+}
+
+func NewEntryResults() *EntryResults {
+	return &EntryResults{results: make(map[EntryPosition]EntryResult)}
+}
+
+// Drop records that the entry was discarded without error.
+func (r *EntryResults) Drop(pos EntryPosition) {
+	r.results[pos] = EntryResult{Dropped: true}
+}
+
+// Reject records that the entry was rejected with the given error.
+func (r *EntryResults) Reject(pos EntryPosition, err error) {
+	r.results[pos] = EntryResult{Err: err}
+}
+
+// Rejected returns true if any entry was rejected.
+func (r *EntryResults) Rejected() bool {
+	for _, res := range r.results {
+		if res.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Result returns the outcome of the entry.
+func (r *EntryResults) Result(pos EntryPosition) EntryResult {
+	return r.results[pos]
+}
+
+type entryResultsContextKey struct{}
+
+// InjectEntryResults returns a context recording the outcome of the entries of the push
+// request in results.
+func InjectEntryResults(ctx context.Context, results *EntryResults) context.Context {
+	return context.WithValue(ctx, entryResultsContextKey{}, results)
+}
+
+// EntryResultsFromContext returns the results attached to the context, or nil if the
+// outcome of the entries is not recorded.
+func EntryResultsFromContext(ctx context.Context) *EntryResults {
+	results, _ := ctx.Value(entryResultsContextKey{}).(*EntryResults)
+	return results
+}
@@ -0,0 +1,335 @@
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/util/strutil"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	loki_util "github.com/grafana/loki/v3/pkg/util"
+)
+
+// FieldMappingConfig configures how the fields of the JSON events received by the
+// Elasticsearch bulk and Splunk HEC compatible endpoints are mapped to log entries.
+// Nested fields are referred to with dots, for example `host.name`.
+type FieldMappingConfig struct {
+	LabelFields              []string `yaml:"label_fields" json:"label_fields"`
+	StructuredMetadataFields []string `yaml:"structured_metadata_fields" json:"structured_metadata_fields"`
+	TimestampField           string   `yaml:"timestamp_field" json:"timestamp_field"`
+	MessageField             string   `yaml:"message_field" json:"message_field"`
+}
+
+func (cfg *FieldMappingConfig) RegisterFlagsWithPrefix(prefix string, defaults FieldMappingConfig, f *flag.FlagSet) {
+	cfg.LabelFields = append([]string(nil), defaults.LabelFields...)
+	f.Var((*flagext.StringSliceCSV)(&cfg.LabelFields), prefix+".label-fields", "Comma-separated list of event fields stored as stream labels. Label names are the field names with unsupported characters replaced by underscores and leading underscores removed. Streams without any of the fields get the label service_name=\"unknown_service\".")
+	cfg.StructuredMetadataFields = append([]string(nil), defaults.StructuredMetadataFields...)
+	f.Var((*flagext.StringSliceCSV)(&cfg.StructuredMetadataFields), prefix+".structured-metadata-fields", "Comma-separated list of event fields stored as structured metadata.")
+	f.StringVar(&cfg.TimestampField, prefix+".timestamp-field", defaults.TimestampField, "Event field used as the timestamp of the log entries. Events without a valid timestamp get the time they are received at.")
+	f.StringVar(&cfg.MessageField, prefix+".message-field", defaults.MessageField, "Event field used as the log line. If an event does not have the field, the whole event encoded in JSON is used.")
+}
+
+func (cfg *FieldMappingConfig) Validate() error {
+	for _, field := range append(append([]string{}, cfg.LabelFields...), cfg.StructuredMetadataFields...) {
+		if fieldNameToLabelName(field) == "" {
+			return fmt.Errorf("invalid field name %q: it cannot be used as a label name", field)
+		}
+	}
+	return nil
+}
+
+// decodeBody returns the uncompressed body of a request and records its size and encoding.
+func decodeBody(r *http.Request, pushStats *Stats) ([]byte, error) {
+	pushStats.ContentType = r.Header.Get(contentType)
+	pushStats.ContentEncoding = r.Header.Get(contentEnc)
+	// bodySize should always reflect the compressed size of the request body
+	bodySize := loki_util.NewSizeReader(r.Body)
+	var body io.Reader = bodySize
+	switch pushStats.ContentEncoding {
+	case "":
+	case gzipContentEncoding:
+		gzipReader, err := gzip.NewReader(bodySize)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	default:
+		return nil, fmt.Errorf("Content-Encoding %q not supported", pushStats.ContentEncoding)
+	}
+
+	buf, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	pushStats.BodySize = bodySize.Size()
+	return buf, nil
+}
+
+// fieldMapper maps JSON events to the streams of a push request.
+type fieldMapper struct {
+	cfg FieldMappingConfig
+	// numericTimestampUnit is the unit of timestamps given as numbers.
+	numericTimestampUnit time.Duration
+	now                  time.Time
+
+	streams map[string]*mappedStream
+	// order is the stream and the index in the stream of the entries, in the order
+	// of the events.
+	order []mappedEntry
+}
+
+type mappedStream struct {
+	labels labels.Labels
+	stream logproto.Stream
+	// index is the index of the stream in the push request.
+	index int
+}
+
+type mappedEntry struct {
+	stream *mappedStream
+	entry  int
+}
+
+func newFieldMapper(cfg FieldMappingConfig, numericTimestampUnit time.Duration) *fieldMapper {
+	return &fieldMapper{
+		cfg:                  cfg,
+		numericTimestampUnit: numericTimestampUnit,
+		now:                  time.Now(),
+		streams:              make(map[string]*mappedStream),
+	}
+}
+
+// add maps an event to a log entry. Fields are looked up in the given documents in
+// order, and the event itself is used as the log line if it has no message field.
+func (m *fieldMapper) add(event any, docs ...map[string]any) error {
+	lbs := make(model.LabelSet, len(m.cfg.LabelFields))
+	for _, field := range m.cfg.LabelFields {
+		if s := fieldValueToString(lookupField(field, docs)); s != "" {
+			lbs[model.LabelName(fieldNameToLabelName(field))] = model.LabelValue(s)
+		}
+	}
+	if len(lbs) == 0 {
+		lbs[LabelServiceName] = ServiceUnknown
+	}
+
+	var structuredMetadata push.LabelsAdapter
+	for _, field := range m.cfg.StructuredMetadataFields {
+		if s := fieldValueToString(lookupField(field, docs)); s != "" {
+			structuredMetadata = append(structuredMetadata, push.LabelAdapter{Name: fieldNameToLabelName(field), Value: s})
+		}
+	}
+
+	ts := m.now
+	if m.cfg.TimestampField != "" {
+		if t, ok := parseFieldTimestamp(lookupField(m.cfg.TimestampField, docs), m.numericTimestampUnit); ok {
+			ts = t
+		}
+	}
+
+	if m.cfg.MessageField != "" {
+		if v := lookupField(m.cfg.MessageField, docs); v != nil {
+			event = v
+		}
+	}
+	line, ok := event.(string)
+	if !ok {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		line = string(b)
+	}
+
+	key := lbs.String()
+	s, ok := m.streams[key]
+	if !ok {
+		s = &mappedStream{
+			labels: modelLabelsSetToLabelsList(lbs),
+			stream: logproto.Stream{Labels: key},
+		}
+		m.streams[key] = s
+	}
+	s.stream.Entries = append(s.stream.Entries, logproto.Entry{
+		Timestamp:          ts,
+		Line:               line,
+		StructuredMetadata: structuredMetadata,
+	})
+	m.order = append(m.order, mappedEntry{stream: s, entry: len(s.stream.Entries) - 1})
+	return nil
+}
+
+// pushRequest returns the push request with the mapped entries, and records its stats.
+func (m *fieldMapper) pushRequest(ctx context.Context, userID string, tracker UsageTracker, streamResolver StreamResolver, pushStats *Stats) *logproto.PushRequest {
+	keys := make([]string, 0, len(m.streams))
+	for key := range m.streams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	req := &logproto.PushRequest{Streams: make([]logproto.Stream, 0, len(keys))}
+	for _, key := range keys {
+		s := m.streams[key]
+		pushStats.StreamLabelsSize += int64(len(s.stream.Labels))
+
+		var retentionPeriod time.Duration
+		var policy string
+		if streamResolver != nil {
+			retentionPeriod = streamResolver.RetentionPeriodFor(s.labels)
+			policy = streamResolver.PolicyFor(s.labels)
+		}
+		if _, ok := pushStats.LogLinesBytes[policy]; !ok {
+			pushStats.LogLinesBytes[policy] = make(map[time.Duration]int64)
+		}
+		if _, ok := pushStats.StructuredMetadataBytes[policy]; !ok {
+			pushStats.StructuredMetadataBytes[policy] = make(map[time.Duration]int64)
+		}
+
+		var totalBytesReceived int64
+		for _, e := range s.stream.Entries {
+			pushStats.PolicyNumLines[policy]++
+			entryLabelsSize := int64(loki_util.StructuredMetadataSize(e.StructuredMetadata))
+			pushStats.LogLinesBytes[policy][retentionPeriod] += int64(len(e.Line))
+			pushStats.StructuredMetadataBytes[policy][retentionPeriod] += entryLabelsSize
+			totalBytesReceived += int64(len(e.Line)) + entryLabelsSize
+
+			if e.Timestamp.After(pushStats.MostRecentEntryTimestamp) {
+				pushStats.MostRecentEntryTimestamp = e.Timestamp
+			}
+		}
+
+		if tracker != nil {
+			tracker.ReceivedBytesAdd(ctx, userID, retentionPeriod, s.labels, float64(totalBytesReceived))
+		}
+
+		s.index = len(req.Streams)
+		req.Streams = append(req.Streams, s.stream)
+	}
+	return req
+}
+
+// entryOrder returns the positions of the entries of the push request in the order of
+// the events. It must be called after pushRequest.
+func (m *fieldMapper) entryOrder() []EntryPosition {
+	order := make([]EntryPosition, 0, len(m.order))
+	for _, e := range m.order {
+		order = append(order, EntryPosition{Stream: e.stream.index, Entry: e.entry})
+	}
+	return order
+}
+
+// lookupField returns the value of a field in the first document that has it. A dotted
+// field name is first looked up as is, and then as a path of nested objects.
+func lookupField(field string, docs []map[string]any) any {
+	for _, doc := range docs {
+		if v, ok := doc[field]; ok && v != nil {
+			return v
+		}
+		var cur any = doc
+		for _, part := range strings.Split(field, ".") {
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				cur = nil
+				break
+			}
+			cur = obj[part]
+		}
+		if cur != nil {
+			return cur
+		}
+	}
+	return nil
+}
+
+func fieldValueToString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+// fieldNameToLabelName converts a field name to a label name, for example `_index` to
+// `index` and `host.name` to `host_name`.
+func fieldNameToLabelName(field string) string {
+	name := strings.TrimLeft(strutil.SanitizeLabelName(field), "_")
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "field_" + name
+	}
+	return name
+}
+
+// parseFieldTimestamp parses timestamps given as RFC3339 strings, or as numbers of the
+// given unit since the Unix epoch. Numbers may be encoded as strings.
+func parseFieldTimestamp(v any, unit time.Duration) (time.Time, bool) {
+	var f float64
+	switch v := v.(type) {
+	case float64:
+		f = v
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, true
+		}
+		var err error
+		if f, err = strconv.ParseFloat(v, 64); err != nil {
+			return time.Time{}, false
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	ns := f * float64(unit)
+	if f <= 0 || math.IsInf(ns, 0) || ns > math.MaxInt64 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(ns)).UTC(), true
+}
+
+// decodeJSONObjects decodes a sequence of JSON objects, separated by optional whitespace.
+func decodeJSONObjects(body []byte, fn func(obj map[string]any) error) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for i := 0; ; i++ {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return errors.Wrapf(err, "invalid JSON object at index %d", i)
+		}
+		if obj == nil {
+			return fmt.Errorf("invalid JSON object at index %d: null", i)
+		}
+		if err := fn(obj); err != nil {
+			return err
+		}
+	}
+}
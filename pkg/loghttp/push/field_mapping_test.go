@@ -0,0 +1,204 @@
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+type fieldMappingLimits struct {
+	EmptyLimits
+	cfg FieldMappingConfig
+}
+
+func (l fieldMappingLimits) ElasticsearchBulkConfig(string) FieldMappingConfig {
+	return l.cfg
+}
+
+func (l fieldMappingLimits) SplunkHECConfig(string) FieldMappingConfig {
+	return l.cfg
+}
+
+func (l fieldMappingLimits) SyslogConfig(string) FieldMappingConfig {
+	return l.cfg
+}
+
+func TestParseElasticsearchBulkRequest(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name            string
+		cfg             FieldMappingConfig
+		vars            map[string]string
+		body            string
+		expectedStreams []logproto.Stream
+		expectedOrder   []EntryPosition
+		expectedErr     string
+	}{
+		{
+			name: "index of the actions",
+			cfg:  DefaultElasticsearchBulkConfig,
+			body: `{"index":{"_index":"app"}}
+{"@timestamp":"2024-01-01T00:00:00Z","message":"foo"}
+{"create":{"_index":"db"}}
+{"@timestamp":1704067200000,"message":"bar"}
+{"create":{"_index":"app"}}
+{"@timestamp":"2024-01-01T00:00:00Z","message":"baz"}
+`,
+			expectedStreams: []logproto.Stream{
+				{Labels: `{index="app"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "foo"}, {Timestamp: ts, Line: "baz"}}},
+				{Labels: `{index="db"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "bar"}}},
+			},
+			expectedOrder: []EntryPosition{{Stream: 0, Entry: 0}, {Stream: 1, Entry: 0}, {Stream: 0, Entry: 1}},
+		},
+		{
+			name: "index of the path",
+			cfg:  DefaultElasticsearchBulkConfig,
+			vars: map[string]string{"index": "app"},
+			body: `{"create":{}}
+{"@timestamp":"2024-01-01T00:00:00Z","message":"foo"}
+`,
+			expectedStreams: []logproto.Stream{
+				{Labels: `{index="app"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "foo"}}},
+			},
+			expectedOrder: []EntryPosition{{Stream: 0, Entry: 0}},
+		},
+		{
+			name: "nested fields and documents without message",
+			cfg: FieldMappingConfig{
+				LabelFields:              []string{"service.name"},
+				StructuredMetadataFields: []string{"host.name", "trace.id"},
+				TimestampField:           "@timestamp",
+				MessageField:             "message",
+			},
+			body: `{"create":{}}
+{"@timestamp":"2024-01-01T00:00:00Z","service":{"name":"checkout"},"host.name":"web-1","status":200}
+`,
+			expectedStreams: []logproto.Stream{
+				{Labels: `{service_name="checkout"}`, Entries: []logproto.Entry{{
+					Timestamp:          ts,
+					Line:               `{"@timestamp":"2024-01-01T00:00:00Z","host.name":"web-1","service":{"name":"checkout"},"status":200}`,
+					StructuredMetadata: push.LabelsAdapter{{Name: "host_name", Value: "web-1"}},
+				}}},
+			},
+			expectedOrder: []EntryPosition{{Stream: 0, Entry: 0}},
+		},
+		{
+			name:        "unsupported action",
+			cfg:         DefaultElasticsearchBulkConfig,
+			body:        `{"update":{"_id":"1"}}`,
+			expectedErr: `unsupported bulk action "update": only index and create actions are supported`,
+		},
+		{
+			name:        "action without document",
+			cfg:         DefaultElasticsearchBulkConfig,
+			body:        `{"create":{}}`,
+			expectedErr: "invalid bulk request: action without document",
+		},
+		{
+			name:        "invalid JSON",
+			cfg:         DefaultElasticsearchBulkConfig,
+			body:        `{"create":{}}` + "\n{",
+			expectedErr: "invalid JSON object at index 1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, ElasticsearchBulkPath, strings.NewReader(tc.body))
+			req = mux.SetURLVars(req, tc.vars)
+			results := NewEntryResults()
+			req = req.WithContext(InjectEntryResults(req.Context(), results))
+
+			pushReq, stats, err := ParseElasticsearchBulkRequest("fake", req, fieldMappingLimits{cfg: tc.cfg}, nil, nil, false, log.NewNopLogger())
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStreams, pushReq.Streams)
+			require.Equal(t, tc.expectedOrder, results.Order)
+			require.Equal(t, int64(len(tc.body)), stats.BodySize)
+		})
+	}
+}
+
+func TestParseSplunkHECRequest(t *testing.T) {
+	ts := time.Unix(1704067200, 500000000).UTC()
+
+	for _, tc := range []struct {
+		name            string
+		body            string
+		expectedStreams []logproto.Stream
+		expectedErr     string
+	}{
+		{
+			name: "string and object events",
+			body: `{"time":1704067200.5,"host":"web-1","source":"/var/log/app.log","sourcetype":"app","event":"foo"}
+{"time":"1704067200.5","index":"main","event":{"message":"bar","level":"info"},"fields":{"sourcetype":"db"}}`,
+			expectedStreams: []logproto.Stream{
+				{Labels: `{index="main", sourcetype="db"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: `{"level":"info","message":"bar"}`}}},
+				{Labels: `{source="/var/log/app.log", sourcetype="app"}`, Entries: []logproto.Entry{{
+					Timestamp:          ts,
+					Line:               "foo",
+					StructuredMetadata: push.LabelsAdapter{{Name: "host", Value: "web-1"}},
+				}}},
+			},
+		},
+		{
+			name: "events without labels",
+			body: `{"time":1704067200.5,"event":"foo"}`,
+			expectedStreams: []logproto.Stream{
+				{Labels: `{service_name="unknown_service"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "foo"}}},
+			},
+		},
+		{
+			name:        "event without data",
+			body:        `{"time":1704067200.5,"host":"web-1"}`,
+			expectedErr: "event field is required",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var body bytes.Buffer
+			gw := gzip.NewWriter(&body)
+			_, err := gw.Write([]byte(tc.body))
+			require.NoError(t, err)
+			require.NoError(t, gw.Close())
+
+			req := httptest.NewRequest(http.MethodPost, SplunkHECPath, &body)
+			req.Header.Set("Content-Encoding", "gzip")
+
+			pushReq, stats, err := ParseSplunkHECRequest("fake", req, fieldMappingLimits{cfg: DefaultSplunkHECConfig}, nil, nil, false, log.NewNopLogger())
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStreams, pushReq.Streams)
+			require.Equal(t, "gzip", stats.ContentEncoding)
+		})
+	}
+}
+
+func TestFieldNameToLabelName(t *testing.T) {
+	for field, expected := range map[string]string{
+		"_index":       "index",
+		"host.name":    "host_name",
+		"@timestamp":   "timestamp",
+		"1st":          "field_1st",
+		"kubernetes/x": "kubernetes_x",
+		"___":          "",
+	} {
+		require.Equal(t, expected, fieldNameToLabelName(field), field)
+	}
+}
@@ -29,6 +29,7 @@ import (
 
 const (
 	pbContentType       = "application/x-protobuf"
+	grpcContentType     = "application/grpc"
 	gzipContentEncoding = "gzip"
 	attrServiceName     = "service.name"
 
@@ -47,6 +48,21 @@ func ParseOTLPRequest(userID string, r *http.Request, limits Limits, tracker Usa
 	return req, stats, nil
 }
 
+// OTLPGRPCPath is the full method name of the OTLP/gRPC logs export endpoint.
+const OTLPGRPCPath = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+
+// ParseOTLPGRPCRequest converts the logs of an OTLP/gRPC export request into a push request,
+// applying the same per-tenant OTLP configuration as requests received over HTTP, and
+// records the same push metrics as ParseRequest.
+func ParseOTLPGRPCRequest(ctx context.Context, logger log.Logger, userID string, req plogotlp.ExportRequest, limits Limits, tracker UsageTracker, streamResolver StreamResolver, logPushRequestStreams bool) *logproto.PushRequest {
+	stats := NewPushStats()
+	stats.ContentType = grpcContentType
+
+	pushReq := otlpToLokiPushRequest(ctx, req.Logs(), userID, limits.OTLPConfig(userID), limits.DiscoverServiceName(userID), tracker, stats, logPushRequestStreams, logger, streamResolver)
+	recordPushStats(logger, userID, OTLPGRPCPath, pushReq, stats)
+	return pushReq
+}
+
 func extractLogs(r *http.Request, pushStats *Stats) (plog.Logs, error) {
 	pushStats.ContentEncoding = r.Header.Get(contentEnc)
 	// bodySize should always reflect the compressed size of the request body
@@ -78,6 +78,9 @@ type TenantsRetention interface {
 type Limits interface {
 	OTLPConfig(userID string) OTLPConfig
 	DiscoverServiceName(userID string) []string
+	ElasticsearchBulkConfig(userID string) FieldMappingConfig
+	SplunkHECConfig(userID string) FieldMappingConfig
+	SyslogConfig(userID string) FieldMappingConfig
 }
 
 type EmptyLimits struct{}
@@ -90,6 +93,18 @@ func (EmptyLimits) DiscoverServiceName(string) []string {
 	return nil
 }
 
+func (EmptyLimits) ElasticsearchBulkConfig(string) FieldMappingConfig {
+	return DefaultElasticsearchBulkConfig
+}
+
+func (EmptyLimits) SplunkHECConfig(string) FieldMappingConfig {
+	return DefaultSplunkHECConfig
+}
+
+func (EmptyLimits) SyslogConfig(string) FieldMappingConfig {
+	return DefaultSyslogConfig
+}
+
 func (EmptyLimits) PolicyFor(_ string, _ labels.Labels) string {
 	return ""
 }
@@ -106,6 +121,9 @@ type (
 	RequestParser        func(userID string, r *http.Request, limits Limits, tracker UsageTracker, streamResolver StreamResolver, logPushRequestStreams bool, logger log.Logger) (*logproto.PushRequest, *Stats, error)
 	RequestParserWrapper func(inner RequestParser) RequestParser
 	ErrorWriter          func(w http.ResponseWriter, errorStr string, code int, logger log.Logger)
+	// SuccessWriter writes the response of a successful push request. The request is nil
+	// if all of its lines were filtered during parsing.
+	SuccessWriter func(w http.ResponseWriter, req *logproto.PushRequest, logger log.Logger)
 )
 
 type PolicyWithRetentionWithBytes map[string]map[time.Duration]int64
@@ -143,6 +161,13 @@ func ParseRequest(logger log.Logger, userID string, r *http.Request, limits Limi
 		return nil, err
 	}
 
+	recordPushStats(logger, userID, r.URL.Path, req, pushStats)
+
+	return req, err
+}
+
+// recordPushStats records the metrics of a parsed push request and logs its stats.
+func recordPushStats(logger log.Logger, userID string, path string, req *logproto.PushRequest, pushStats *Stats) {
 	var (
 		entriesSize            int64
 		structuredMetadataSize int64
@@ -185,7 +210,7 @@ func ParseRequest(logger log.Logger, userID string, r *http.Request, limits Limi
 
 	logValues := []interface{}{
 		"msg", "push request parsed",
-		"path", r.URL.Path,
+		"path", path,
 		"contentType", pushStats.ContentType,
 		"contentEncoding", pushStats.ContentEncoding,
 		"bodySize", humanize.Bytes(uint64(pushStats.BodySize)),
@@ -199,8 +224,6 @@ func ParseRequest(logger log.Logger, userID string, r *http.Request, limits Limi
 	}
 	logValues = append(logValues, pushStats.Extra...)
 	level.Debug(logger).Log(logValues...)
-
-	return req, err
 }
 
 func ParseLokiRequest(userID string, r *http.Request, limits Limits, tracker UsageTracker, streamResolver StreamResolver, logPushRequestStreams bool, logger log.Logger) (*logproto.PushRequest, *Stats, error) {
@@ -416,3 +439,9 @@ func HTTPError(w http.ResponseWriter, errorStr string, code int, _ log.Logger) {
 }
 
 var _ ErrorWriter = HTTPError
+
+func NoContentResponse(w http.ResponseWriter, _ *logproto.PushRequest, _ log.Logger) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var _ SuccessWriter = NoContentResponse
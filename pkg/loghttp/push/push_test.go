@@ -605,6 +605,18 @@ func (f *fakeLimits) OTLPConfig(_ string) OTLPConfig {
 	return DefaultOTLPConfig(defaultGlobalOTLPConfig)
 }
 
+func (f *fakeLimits) ElasticsearchBulkConfig(_ string) FieldMappingConfig {
+	return DefaultElasticsearchBulkConfig
+}
+
+func (f *fakeLimits) SplunkHECConfig(_ string) FieldMappingConfig {
+	return DefaultSplunkHECConfig
+}
+
+func (f *fakeLimits) SyslogConfig(_ string) FieldMappingConfig {
+	return DefaultSyslogConfig
+}
+
 func (f *fakeLimits) PolicyFor(_ string, lbs labels.Labels) string {
 	return lbs.Get("environment")
 }
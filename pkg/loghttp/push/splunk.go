@@ -0,0 +1,110 @@
+package push
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+const (
+	SplunkHECPath = "/splunk/services/collector"
+
+	splunkHECEventField  = "event"
+	splunkHECFieldsField = "fields"
+)
+
+// Status codes of the Splunk HEC responses.
+const (
+	splunkHECCodeSuccess       = 0
+	splunkHECCodeInvalidFormat = 6
+	splunkHECCodeInternalError = 8
+	splunkHECCodeServerBusy    = 9
+	splunkHECCodeHealthy       = 17
+)
+
+// DefaultSplunkHECConfig maps the Splunk index, source and source type of the events to
+// labels, and their host to structured metadata.
+var DefaultSplunkHECConfig = FieldMappingConfig{
+	LabelFields:              []string{"index", "source", "sourcetype"},
+	StructuredMetadataFields: []string{"host"},
+	TimestampField:           "time",
+	MessageField:             splunkHECEventField,
+}
+
+// ParseSplunkHECRequest parses requests in the JSON event format of the Splunk HTTP Event
+// Collector. Events are mapped to log entries with the per-tenant Splunk HEC
+// configuration. Fields are looked up in the metadata of the events, then in their
+// indexed fields, and last in their event data. Numeric timestamps are in seconds.
+func ParseSplunkHECRequest(userID string, r *http.Request, limits Limits, tracker UsageTracker, streamResolver StreamResolver, _ bool, _ log.Logger) (*logproto.PushRequest, *Stats, error) {
+	pushStats := NewPushStats()
+	body, err := decodeBody(r, pushStats)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mapper := newFieldMapper(limits.SplunkHECConfig(userID), time.Second)
+	err = decodeJSONObjects(body, func(obj map[string]any) error {
+		event, ok := obj[splunkHECEventField]
+		if !ok || event == nil || event == "" {
+			return errors.New("event field is required")
+		}
+
+		docs := []map[string]any{obj}
+		if fields, ok := obj[splunkHECFieldsField].(map[string]any); ok {
+			docs = append(docs, fields)
+		}
+		if data, ok := event.(map[string]any); ok {
+			docs = append(docs, data)
+		}
+		return mapper.add(event, docs...)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return mapper.pushRequest(r.Context(), userID, tracker, streamResolver, pushStats), pushStats, nil
+}
+
+var _ RequestParser = ParseSplunkHECRequest
+
+// SplunkHECResponse writes the response of a successful Splunk HEC request.
+func SplunkHECResponse(w http.ResponseWriter, _ *logproto.PushRequest, logger log.Logger) {
+	writeSplunkHECResponse(w, http.StatusOK, "Success", splunkHECCodeSuccess, logger)
+}
+
+var _ SuccessWriter = SplunkHECResponse
+
+// SplunkHECError writes errors in the format of the Splunk HEC API. 500 errors are mapped
+// to 503, so that clients retry the requests.
+func SplunkHECError(w http.ResponseWriter, errorStr string, code int, logger log.Logger) {
+	if code == http.StatusInternalServerError {
+		code = http.StatusServiceUnavailable
+	}
+
+	hecCode := splunkHECCodeInternalError
+	switch {
+	case code == http.StatusBadRequest:
+		hecCode = splunkHECCodeInvalidFormat
+	case code == http.StatusTooManyRequests || code >= http.StatusInternalServerError:
+		hecCode = splunkHECCodeServerBusy
+	}
+	writeSplunkHECResponse(w, code, errorStr, hecCode, logger)
+}
+
+var _ ErrorWriter = SplunkHECError
+
+// SplunkHECHealthHandler answers the health checks of Splunk HEC clients.
+func SplunkHECHealthHandler(w http.ResponseWriter, _ *http.Request) {
+	writeSplunkHECResponse(w, http.StatusOK, "HEC is healthy", splunkHECCodeHealthy, nil)
+}
+
+func writeSplunkHECResponse(w http.ResponseWriter, code int, text string, hecCode int, logger log.Logger) {
+	writeJSONResponse(w, code, map[string]any{
+		"text": text,
+		"code": hecCode,
+	}, logger)
+}
@@ -0,0 +1,83 @@
+package push
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/rfc3164"
+	"github.com/leodido/go-syslog/v4/rfc5424"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+func TestParseSyslogMessages(t *testing.T) {
+	parse := func(p syslog.Machine, msg string) syslog.Message {
+		m, err := p.Parse([]byte(msg))
+		require.NoError(t, err)
+		return m
+	}
+	msgs := []syslog.Message{
+		parse(rfc5424.NewParser(), `<165>1 2024-01-01T00:00:00Z web-1 app 123 ID47 [origin ip="10.0.0.1"] foo`),
+		parse(rfc5424.NewParser(), `<11>1 2024-01-01T00:00:00Z web-1 app - - - bar`),
+		parse(rfc3164.NewParser(rfc3164.WithYear(rfc3164.Year{YYYY: 2024})), `<34>Jan  1 00:00:00 db-1 su: baz`),
+	}
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name            string
+		cfg             FieldMappingConfig
+		expectedStreams []logproto.Stream
+	}{
+		{
+			name: "default config",
+			cfg:  DefaultSyslogConfig,
+			expectedStreams: []logproto.Stream{
+				{Labels: `{app_name="app", hostname="web-1"}`, Entries: []logproto.Entry{
+					{Timestamp: ts, Line: "foo", StructuredMetadata: push.LabelsAdapter{
+						{Name: "facility", Value: "local4"},
+						{Name: "severity", Value: "notice"},
+						{Name: "proc_id", Value: "123"},
+						{Name: "msg_id", Value: "ID47"},
+					}},
+					{Timestamp: ts, Line: "bar", StructuredMetadata: push.LabelsAdapter{
+						{Name: "facility", Value: "user"},
+						{Name: "severity", Value: "error"},
+					}},
+				}},
+				{Labels: `{app_name="su", hostname="db-1"}`, Entries: []logproto.Entry{
+					{Timestamp: ts, Line: "baz", StructuredMetadata: push.LabelsAdapter{
+						{Name: "facility", Value: "auth"},
+						{Name: "severity", Value: "critical"},
+					}},
+				}},
+			},
+		},
+		{
+			name: "severity label and structured data",
+			cfg: FieldMappingConfig{
+				LabelFields:              []string{"severity"},
+				StructuredMetadataFields: []string{"structured_data.origin.ip"},
+				TimestampField:           "timestamp",
+				MessageField:             "message",
+			},
+			expectedStreams: []logproto.Stream{
+				{Labels: `{severity="critical"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "baz"}}},
+				{Labels: `{severity="error"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "bar"}}},
+				{Labels: `{severity="notice"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "foo", StructuredMetadata: push.LabelsAdapter{
+					{Name: "structured_data_origin_ip", Value: "10.0.0.1"},
+				}}}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := ParseSyslogMessages(context.Background(), log.NewNopLogger(), "fake", msgs, fieldMappingLimits{cfg: tc.cfg}, nil, nil)
+			require.Equal(t, tc.expectedStreams, req.Streams)
+		})
+	}
+}
@@ -233,6 +233,7 @@ type StreamMetadata struct {
 	StreamHash             uint64 `protobuf:"varint,1,opt,name=streamHash,proto3" json:"streamHash,omitempty"`
 	EntriesSize            uint64 `protobuf:"varint,2,opt,name=entriesSize,proto3" json:"entriesSize,omitempty"`
 	StructuredMetadataSize uint64 `protobuf:"varint,3,opt,name=structuredMetadataSize,proto3" json:"structuredMetadataSize,omitempty"`
+	Policy                 string `protobuf:"bytes,4,opt,name=policy,proto3" json:"policy,omitempty"`
 }
 
 func (m *StreamMetadata) Reset()      { *m = StreamMetadata{} }
@@ -288,6 +289,13 @@ func (m *StreamMetadata) GetStructuredMetadataSize() uint64 {
 	return 0
 }
 
+func (m *StreamMetadata) GetPolicy() string {
+	if m != nil {
+		return m.Policy
+	}
+	return ""
+}
+
 type ExceedsLimitsRequest struct {
 	Tenant  string            `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
 	Streams []*StreamMetadata `protobuf:"bytes,2,rep,name=streams,proto3" json:"streams,omitempty"`
@@ -445,6 +453,7 @@ type GetStreamUsageRequest struct {
 	Tenant       string   `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
 	Partitions   []int32  `protobuf:"varint,2,rep,packed,name=partitions,proto3" json:"partitions,omitempty"`
 	StreamHashes []uint64 `protobuf:"varint,3,rep,packed,name=streamHashes,proto3" json:"streamHashes,omitempty"`
+	Policy       string   `protobuf:"bytes,4,opt,name=policy,proto3" json:"policy,omitempty"`
 }
 
 func (m *GetStreamUsageRequest) Reset()      { *m = GetStreamUsageRequest{} }
@@ -500,6 +509,13 @@ func (m *GetStreamUsageRequest) GetStreamHashes() []uint64 {
 	return nil
 }
 
+func (m *GetStreamUsageRequest) GetPolicy() string {
+	if m != nil {
+		return m.Policy
+	}
+	return ""
+}
+
 type GetStreamUsageResponse struct {
 	Tenant         string   `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
 	ActiveStreams  uint64   `protobuf:"varint,2,opt,name=activeStreams,proto3" json:"activeStreams,omitempty"`
@@ -3595,204 +3611,204 @@ func init() {
 func init() { proto.RegisterFile("pkg/logproto/logproto.proto", fileDescriptor_c28a5f14f1f4c79a) }
 
 var fileDescriptor_c28a5f14f1f4c79a = []byte{
-	// 3147 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x3a, 0x4d, 0x6c, 0x1b, 0xc7,
-	0xd5, 0x5c, 0xfe, 0x89, 0x7c, 0xa4, 0x64, 0x79, 0x24, 0xcb, 0x84, 0x6c, 0x93, 0xca, 0x20, 0x9f,
-	0xed, 0xc4, 0x8e, 0x68, 0x2b, 0x5f, 0xfc, 0x25, 0xce, 0x97, 0xa6, 0xa6, 0x64, 0x2b, 0x76, 0xe4,
-	0x9f, 0x8c, 0x6c, 0x27, 0x2d, 0x1a, 0x04, 0x6b, 0x72, 0x44, 0xad, 0x45, 0xee, 0xd2, 0xbb, 0x43,
-	0xdb, 0xea, 0xa9, 0xe7, 0x02, 0x45, 0x83, 0x16, 0x45, 0xdb, 0x4b, 0x81, 0x02, 0x05, 0x5a, 0x14,
-	0xc8, 0xa5, 0xe8, 0xa1, 0x87, 0xa2, 0xbd, 0xf4, 0x90, 0xde, 0xd2, 0x53, 0x83, 0x1c, 0xd8, 0x46,
-	0xb9, 0x14, 0x02, 0x0a, 0xe4, 0xd4, 0x02, 0xe9, 0xa5, 0x98, 0xbf, 0xdd, 0xd9, 0x25, 0x19, 0x45,
-	0xae, 0x8b, 0x24, 0x17, 0x72, 0xe7, 0xcd, 0x9b, 0x37, 0xf3, 0x7e, 0xe6, 0xbd, 0x37, 0x6f, 0x06,
-	0x8e, 0xf4, 0xb6, 0xda, 0xf5, 0x8e, 0xd7, 0xee, 0xf9, 0x1e, 0xf3, 0xc2, 0x8f, 0x45, 0xf1, 0x8b,
-	0x0a, 0xba, 0x3d, 0x3f, 0xdb, 0xf6, 0xda, 0x9e, 0xc4, 0xe1, 0x5f, 0xb2, 0x7f, 0xbe, 0xd6, 0xf6,
-	0xbc, 0x76, 0x87, 0xd6, 0x45, 0xeb, 0x4e, 0x7f, 0xa3, 0xce, 0x9c, 0x2e, 0x0d, 0x98, 0xdd, 0xed,
-	0x29, 0x84, 0x05, 0x45, 0xfd, 0x5e, 0xa7, 0xeb, 0xb5, 0x68, 0xa7, 0x1e, 0x30, 0x9b, 0x05, 0xf2,
-	0x57, 0x61, 0xcc, 0x70, 0x8c, 0x5e, 0x3f, 0xd8, 0x14, 0x3f, 0x0a, 0x78, 0x86, 0x03, 0x03, 0xe6,
-	0xf9, 0x76, 0x9b, 0xd6, 0x9b, 0x9b, 0x7d, 0x77, 0xab, 0xde, 0xb4, 0x9b, 0x9b, 0xb4, 0xee, 0xd3,
-	0xa0, 0xdf, 0x61, 0x81, 0x6c, 0xb0, 0xed, 0x1e, 0x55, 0x64, 0xf0, 0xaf, 0x2d, 0x38, 0xb4, 0x66,
-	0xdf, 0xa1, 0x9d, 0x9b, 0xde, 0x6d, 0xbb, 0xd3, 0xa7, 0x01, 0xa1, 0x41, 0xcf, 0x73, 0x03, 0x8a,
-	0x96, 0x21, 0xdf, 0xe1, 0x1d, 0x41, 0xc5, 0x5a, 0xc8, 0x9c, 0x2c, 0x2d, 0x9d, 0x5a, 0x0c, 0x99,
-	0x1c, 0x39, 0x40, 0x42, 0x83, 0x8b, 0x2e, 0xf3, 0xb7, 0x89, 0x1a, 0x3a, 0x7f, 0x1b, 0x4a, 0x06,
-	0x18, 0x4d, 0x43, 0x66, 0x8b, 0x6e, 0x57, 0xac, 0x05, 0xeb, 0x64, 0x91, 0xf0, 0x4f, 0x74, 0x16,
-	0x72, 0xf7, 0x39, 0x99, 0x4a, 0x7a, 0xc1, 0x3a, 0x59, 0x5a, 0x3a, 0x12, 0x4d, 0x72, 0xcb, 0x75,
-	0xee, 0xf5, 0xa9, 0x18, 0xad, 0x26, 0x92, 0x98, 0xe7, 0xd3, 0xcf, 0x5b, 0xf8, 0x14, 0x1c, 0x1c,
-	0xea, 0x47, 0x73, 0x90, 0x17, 0x18, 0x72, 0xc5, 0x45, 0xa2, 0x5a, 0x78, 0x16, 0xd0, 0x3a, 0xf3,
-	0xa9, 0xdd, 0x25, 0x36, 0xe3, 0xeb, 0xbd, 0xd7, 0xa7, 0x01, 0xc3, 0x57, 0x61, 0x26, 0x06, 0x55,
-	0x6c, 0x9f, 0x83, 0x52, 0x10, 0x81, 0x15, 0xef, 0xb3, 0xd1, 0xb2, 0xa2, 0x31, 0xc4, 0x44, 0xc4,
-	0xdf, 0xb6, 0x60, 0x4a, 0xf6, 0x5d, 0xa5, 0xcc, 0x6e, 0xd9, 0xcc, 0x46, 0x55, 0x00, 0x89, 0xf1,
-	0x8a, 0x1d, 0x6c, 0x0a, 0xa6, 0xb3, 0xc4, 0x80, 0xa0, 0x05, 0x28, 0x51, 0x97, 0xf9, 0x0e, 0x0d,
-	0xd6, 0x9d, 0x6f, 0x4a, 0x09, 0x64, 0x89, 0x09, 0x42, 0xe7, 0x60, 0x2e, 0x60, 0x7e, 0xbf, 0xc9,
-	0xfa, 0x3e, 0x6d, 0x69, 0xba, 0x02, 0x39, 0x23, 0x90, 0xc7, 0xf4, 0xe2, 0x3b, 0x30, 0x7b, 0xf1,
-	0x61, 0x93, 0xd2, 0x56, 0xb0, 0xe6, 0x74, 0x1d, 0xa6, 0x79, 0xe6, 0x12, 0x62, 0xd4, 0xb5, 0x5d,
-	0xa6, 0x54, 0xa0, 0x5a, 0x68, 0x09, 0x26, 0xe4, 0xba, 0x82, 0x4a, 0x5a, 0x30, 0x5c, 0x49, 0x32,
-	0xac, 0xc9, 0x13, 0x8d, 0x88, 0x03, 0x38, 0x94, 0x98, 0x43, 0x49, 0x70, 0xdc, 0x24, 0x0d, 0x38,
-	0xe0, 0xd3, 0xbb, 0xb4, 0xc9, 0x68, 0x6b, 0x7d, 0xdc, 0x64, 0x24, 0x86, 0x40, 0x92, 0x03, 0xf0,
-	0x2b, 0x30, 0x15, 0x47, 0xd9, 0x53, 0xc8, 0x73, 0x90, 0xf7, 0xa9, 0x1d, 0x78, 0xae, 0x90, 0x6f,
-	0x91, 0xa8, 0x16, 0x5f, 0xfe, 0x2a, 0x65, 0x92, 0xc8, 0xad, 0xc0, 0x6e, 0xd3, 0xbd, 0x64, 0x54,
-	0x05, 0xe8, 0xd9, 0x3e, 0x73, 0x98, 0xe3, 0xb9, 0x72, 0xe5, 0x39, 0x62, 0x40, 0x10, 0x86, 0x72,
-	0x34, 0x2d, 0x0d, 0x2a, 0x99, 0x85, 0xcc, 0xc9, 0x2c, 0x89, 0xc1, 0xf0, 0xf7, 0x2c, 0x98, 0x4b,
-	0xce, 0xba, 0x87, 0xd4, 0x9e, 0x84, 0x49, 0xbb, 0xc9, 0x9c, 0xfb, 0x34, 0x92, 0x19, 0x67, 0x31,
-	0x0e, 0x44, 0x08, 0xb2, 0xbe, 0xcd, 0xa4, 0x59, 0x64, 0x88, 0xf8, 0x46, 0xc7, 0x61, 0xaa, 0xef,
-	0x6e, 0xb9, 0xde, 0x03, 0x57, 0x0f, 0xcd, 0x8a, 0x25, 0x25, 0xa0, 0xf8, 0x27, 0x16, 0x40, 0x64,
-	0xd5, 0x7b, 0x0a, 0xf4, 0x34, 0x1c, 0x8c, 0x5a, 0xd7, 0xbc, 0xf5, 0x4d, 0xdb, 0x6f, 0xa9, 0x45,
-	0x0d, 0x77, 0x8c, 0x5c, 0x58, 0xc4, 0x6a, 0x36, 0xc6, 0xea, 0x1c, 0xe4, 0xb9, 0x2f, 0xa3, 0x41,
-	0x25, 0xb7, 0x60, 0x9d, 0x9c, 0x24, 0xaa, 0x85, 0xab, 0x70, 0x74, 0x95, 0xb2, 0x0b, 0x41, 0xe0,
-	0xb4, 0x5d, 0xda, 0xba, 0x11, 0x8a, 0x5c, 0xef, 0xe4, 0x3f, 0x5b, 0x70, 0x6c, 0x0c, 0x82, 0x12,
-	0xae, 0x07, 0xc8, 0x1e, 0xea, 0x55, 0x7b, 0xfb, 0xe5, 0xc8, 0xfa, 0x3e, 0x95, 0xc8, 0xe2, 0x70,
-	0x97, 0xf4, 0x75, 0x23, 0x48, 0xcf, 0x5f, 0x84, 0xc3, 0x63, 0xd0, 0x4d, 0x1f, 0x98, 0x93, 0x3e,
-	0x70, 0xd6, 0xf4, 0x81, 0x19, 0xd3, 0xcd, 0xfd, 0x23, 0x03, 0xe5, 0xd7, 0xfa, 0xd4, 0xdf, 0xd6,
-	0xc6, 0x59, 0x85, 0x42, 0x40, 0x3b, 0xb4, 0xc9, 0x3c, 0x5f, 0xda, 0x49, 0x23, 0x5d, 0xb1, 0x48,
-	0x08, 0xe3, 0xa4, 0x3a, 0x7c, 0x37, 0x0a, 0x52, 0x93, 0x44, 0x36, 0xd0, 0x79, 0xc8, 0x05, 0xcc,
-	0xf6, 0x99, 0xd0, 0x42, 0x69, 0x69, 0x7e, 0x51, 0x86, 0x9f, 0x45, 0x1d, 0x7e, 0x16, 0x6f, 0xea,
-	0xf0, 0xd3, 0x28, 0xbc, 0x3b, 0xa8, 0xa5, 0xde, 0xfe, 0x4b, 0xcd, 0x22, 0x72, 0x08, 0x3a, 0x07,
-	0x19, 0xea, 0xb6, 0x84, 0xa6, 0x3e, 0xeb, 0x48, 0x3e, 0x00, 0x9d, 0x85, 0x62, 0xcb, 0xf1, 0x69,
-	0x93, 0x73, 0x2e, 0xf4, 0x39, 0xb5, 0x34, 0x13, 0x49, 0x7a, 0x45, 0x77, 0x91, 0x08, 0x0b, 0x9d,
-	0x86, 0x7c, 0xc0, 0x8d, 0x26, 0xa8, 0x4c, 0x70, 0xff, 0xdd, 0x98, 0xdd, 0x1d, 0xd4, 0xa6, 0x25,
-	0xe4, 0xb4, 0xd7, 0x75, 0x18, 0xed, 0xf6, 0xd8, 0x36, 0x51, 0x38, 0xe8, 0x69, 0x98, 0x68, 0xd1,
-	0x0e, 0xe5, 0x4e, 0xba, 0x20, 0x14, 0x39, 0x6d, 0x90, 0x17, 0x1d, 0x44, 0x23, 0xa0, 0x37, 0x21,
-	0xdb, 0xeb, 0xd8, 0x6e, 0xa5, 0x28, 0xb8, 0x98, 0x8a, 0x10, 0x6f, 0x74, 0x6c, 0xb7, 0xf1, 0xc2,
-	0x07, 0x83, 0xda, 0x73, 0x6d, 0x87, 0x6d, 0xf6, 0xef, 0x2c, 0x36, 0xbd, 0x6e, 0xbd, 0xed, 0xdb,
-	0x1b, 0xb6, 0x6b, 0xd7, 0x3b, 0xde, 0x96, 0x53, 0xbf, 0xff, 0x6c, 0x9d, 0x07, 0xd5, 0x7b, 0x7d,
-	0xea, 0x3b, 0xd4, 0xaf, 0x73, 0x32, 0x8b, 0x42, 0x25, 0x7c, 0x28, 0x11, 0x64, 0xd1, 0x15, 0x1e,
-	0x33, 0x3c, 0x9f, 0x2e, 0xf3, 0x88, 0x1b, 0x54, 0x40, 0xcc, 0x72, 0x38, 0x9a, 0x45, 0xc0, 0x09,
-	0xdd, 0x58, 0xf5, 0xbd, 0x7e, 0xaf, 0x71, 0x60, 0x77, 0x50, 0x33, 0xf1, 0x89, 0xd9, 0xb8, 0x92,
-	0x2d, 0xe4, 0xa7, 0x27, 0xf0, 0x3b, 0x19, 0x40, 0xeb, 0x76, 0xb7, 0xd7, 0xa1, 0xfb, 0x52, 0x7f,
-	0xa8, 0xe8, 0xf4, 0x23, 0x2b, 0x3a, 0xb3, 0x5f, 0x45, 0x47, 0x5a, 0xcb, 0xee, 0x4f, 0x6b, 0xb9,
-	0xcf, 0xaa, 0xb5, 0xfc, 0x17, 0x5e, 0x6b, 0xb8, 0x02, 0x59, 0x4e, 0x99, 0x6f, 0x6e, 0xdf, 0x7e,
-	0x20, 0x74, 0x53, 0x26, 0xfc, 0x13, 0xaf, 0x41, 0x5e, 0xf2, 0x85, 0xe6, 0x93, 0xca, 0x8b, 0xef,
-	0xdb, 0x48, 0x71, 0x19, 0xad, 0x92, 0xe9, 0x48, 0x25, 0x19, 0x21, 0x6c, 0xfc, 0x5b, 0x0b, 0x26,
-	0x95, 0x45, 0x28, 0xd7, 0x76, 0x27, 0x0a, 0xdd, 0xd2, 0x9f, 0x1d, 0x4e, 0x86, 0xee, 0x0b, 0x2d,
-	0xbb, 0xc7, 0xa8, 0xdf, 0xa8, 0xbf, 0x3b, 0xa8, 0x59, 0x1f, 0x0c, 0x6a, 0x27, 0xc6, 0x09, 0x4d,
-	0x67, 0x94, 0x3a, 0xc7, 0xd1, 0x84, 0xd1, 0x29, 0xb1, 0x3a, 0x16, 0x28, 0xb3, 0x3a, 0xb0, 0x28,
-	0x13, 0xd1, 0xcb, 0x6e, 0x9b, 0x06, 0x9c, 0x72, 0x96, 0x5b, 0x04, 0x91, 0x38, 0x9c, 0xcd, 0x07,
-	0xb6, 0xef, 0x3a, 0x6e, 0x5b, 0xc6, 0xc0, 0x22, 0x09, 0xdb, 0xf8, 0x47, 0x16, 0xcc, 0xc4, 0xcc,
-	0x5a, 0x31, 0xf1, 0x3c, 0xe4, 0x03, 0xae, 0x29, 0xcd, 0x83, 0x61, 0x14, 0xeb, 0x02, 0xde, 0x98,
-	0x52, 0x8b, 0xcf, 0xcb, 0x36, 0x51, 0xf8, 0x8f, 0x6f, 0x69, 0x7f, 0xb0, 0xa0, 0x2c, 0x92, 0x49,
-	0xbd, 0xd7, 0x10, 0x64, 0x5d, 0xbb, 0x4b, 0x95, 0xaa, 0xc4, 0xb7, 0x91, 0x61, 0xf2, 0xe9, 0x0a,
-	0x3a, 0xc3, 0xdc, 0xaf, 0x83, 0xb5, 0x1e, 0xd9, 0xc1, 0x5a, 0xd1, 0xbe, 0x9b, 0x85, 0x1c, 0x37,
-	0xef, 0x6d, 0xe1, 0x5c, 0x8b, 0x44, 0x36, 0xf0, 0x09, 0x98, 0x54, 0x5c, 0x44, 0x79, 0xc5, 0xc8,
-	0xa4, 0xb8, 0x0b, 0x79, 0xa9, 0x09, 0xf4, 0x24, 0x14, 0xc3, 0xe3, 0x87, 0xe0, 0x36, 0xd3, 0xc8,
-	0xef, 0x0e, 0x6a, 0x69, 0x16, 0x90, 0xa8, 0x03, 0xd5, 0xcc, 0x20, 0x65, 0x35, 0x8a, 0xbb, 0x83,
-	0x9a, 0x04, 0xa8, 0x78, 0x85, 0x8e, 0x42, 0x76, 0x93, 0x67, 0x0c, 0x22, 0x33, 0x6d, 0x14, 0x76,
-	0x07, 0x35, 0xd1, 0x26, 0xe2, 0x17, 0xaf, 0x42, 0x79, 0x8d, 0xb6, 0xed, 0xe6, 0xb6, 0x9a, 0x34,
-	0x8c, 0x79, 0x7c, 0x42, 0x4b, 0xd3, 0x78, 0x02, 0xca, 0xe1, 0x8c, 0x6f, 0xa9, 0x5c, 0x27, 0x43,
-	0x4a, 0x21, 0xec, 0x6a, 0x80, 0x7f, 0x6c, 0x81, 0xb2, 0x01, 0x84, 0x8d, 0x13, 0x0a, 0xf7, 0x85,
-	0xb0, 0x3b, 0xa8, 0x29, 0x88, 0x3e, 0x80, 0xa0, 0x17, 0x61, 0x22, 0x10, 0x33, 0xea, 0x64, 0xd3,
-	0x34, 0x2d, 0xd1, 0xd1, 0x38, 0xc0, 0x4d, 0x64, 0x77, 0x50, 0xd3, 0x88, 0x44, 0x7f, 0xa0, 0xc5,
-	0x58, 0x2a, 0x24, 0x19, 0x9b, 0xda, 0x1d, 0xd4, 0x0c, 0xa8, 0x99, 0x1a, 0xe1, 0x4f, 0x2c, 0x28,
-	0xdd, 0xb4, 0x9d, 0xd0, 0x84, 0x2a, 0x5a, 0x45, 0x91, 0xaf, 0x96, 0x00, 0x6e, 0x89, 0x2d, 0xda,
-	0xb1, 0xb7, 0x2f, 0x79, 0xbe, 0xa0, 0x3b, 0x49, 0xc2, 0x76, 0x14, 0xc3, 0xb3, 0x23, 0x63, 0x78,
-	0x6e, 0xff, 0xae, 0xfd, 0xbf, 0xeb, 0x48, 0xaf, 0x64, 0x0b, 0xe9, 0xe9, 0x0c, 0x7e, 0xc7, 0x82,
-	0xb2, 0x64, 0x5e, 0x59, 0xde, 0x37, 0x20, 0x2f, 0x65, 0x23, 0xd8, 0xff, 0x14, 0xc7, 0x74, 0x6a,
-	0x3f, 0x4e, 0x49, 0xd1, 0x44, 0x2f, 0xc3, 0x54, 0xcb, 0xf7, 0x7a, 0xbd, 0xe4, 0x61, 0xc2, 0x98,
-	0x65, 0xc5, 0xec, 0x27, 0x09, 0x74, 0xfc, 0x47, 0x0b, 0x26, 0x95, 0x33, 0x51, 0xea, 0x0a, 0x45,
-	0x6c, 0x3d, 0x72, 0xf4, 0x4c, 0xef, 0x37, 0x7a, 0xce, 0x41, 0xbe, 0xcd, 0xe3, 0x8b, 0x76, 0x48,
-	0xaa, 0xb5, 0xbf, 0xa8, 0x8a, 0xaf, 0xc0, 0x94, 0x66, 0x65, 0x8c, 0x47, 0x9d, 0x4f, 0x7a, 0xd4,
-	0xcb, 0x2d, 0xea, 0x32, 0x67, 0xc3, 0x09, 0x7d, 0xa4, 0xc2, 0xc7, 0xdf, 0xb5, 0x60, 0x3a, 0x89,
-	0x82, 0x56, 0x12, 0xc5, 0x80, 0xe3, 0xe3, 0xc9, 0x99, 0x75, 0x00, 0x4d, 0x5a, 0x55, 0x03, 0x9e,
-	0xdb, 0xab, 0x1a, 0x10, 0xcb, 0x84, 0x8b, 0xca, 0x2b, 0xe0, 0x1f, 0x5a, 0x30, 0x19, 0xd3, 0x25,
-	0x7a, 0x1e, 0xb2, 0x1b, 0xbe, 0xd7, 0xdd, 0x97, 0xa2, 0xc4, 0x08, 0xf4, 0xbf, 0x90, 0x66, 0xde,
-	0xbe, 0xd4, 0x94, 0x66, 0x1e, 0xd7, 0x92, 0x62, 0x3f, 0x23, 0x4f, 0x2c, 0xb2, 0x85, 0x9f, 0x83,
-	0xa2, 0x60, 0xe8, 0x86, 0xed, 0xf8, 0x23, 0x03, 0xc6, 0x68, 0x86, 0x5e, 0x84, 0x03, 0xd2, 0x19,
-	0x8e, 0x1e, 0x5c, 0x1e, 0x35, 0xb8, 0xac, 0x07, 0x1f, 0x81, 0x9c, 0x48, 0x3a, 0xf8, 0x10, 0x7e,
-	0x22, 0xd7, 0x43, 0xf8, 0x37, 0x3e, 0x04, 0x33, 0x7c, 0x0f, 0x52, 0x3f, 0x58, 0xf6, 0xfa, 0x2e,
-	0xd3, 0x27, 0xa4, 0xd3, 0x30, 0x1b, 0x07, 0x2b, 0x2b, 0x99, 0x85, 0x5c, 0x93, 0x03, 0x04, 0x8d,
-	0x49, 0x22, 0x1b, 0xf8, 0x67, 0x16, 0xa0, 0x55, 0xca, 0xc4, 0x2c, 0x97, 0x57, 0xc2, 0xed, 0x31,
-	0x0f, 0x85, 0xae, 0xcd, 0x9a, 0x9b, 0xd4, 0x0f, 0x74, 0xfe, 0xa2, 0xdb, 0x9f, 0x47, 0xe2, 0x89,
-	0xcf, 0xc2, 0x4c, 0x6c, 0x95, 0x8a, 0xa7, 0x79, 0x28, 0x34, 0x15, 0x4c, 0x85, 0xbc, 0xb0, 0x8d,
-	0x7f, 0x95, 0x86, 0x82, 0x4e, 0xeb, 0xd0, 0x59, 0x28, 0x6d, 0x38, 0x6e, 0x9b, 0xfa, 0x3d, 0xdf,
-	0x51, 0x22, 0xc8, 0xca, 0x34, 0xcf, 0x00, 0x13, 0xb3, 0x81, 0x9e, 0x81, 0x89, 0x7e, 0x40, 0xfd,
-	0xb7, 0x1c, 0xb9, 0xd3, 0x8b, 0x8d, 0xd9, 0x9d, 0x41, 0x2d, 0x7f, 0x2b, 0xa0, 0xfe, 0xe5, 0x15,
-	0x1e, 0x7c, 0xfa, 0xe2, 0x8b, 0xc8, 0xff, 0x16, 0x7a, 0x55, 0x99, 0xa9, 0x48, 0xe0, 0x1a, 0xff,
-	0xc7, 0x97, 0x9f, 0x70, 0x75, 0x3d, 0xdf, 0xeb, 0x52, 0xb6, 0x49, 0xfb, 0x41, 0xbd, 0xe9, 0x75,
-	0xbb, 0x9e, 0x5b, 0x17, 0xf5, 0x3e, 0xc1, 0x34, 0x8f, 0xa0, 0x7c, 0xb8, 0xb2, 0xdc, 0x9b, 0x30,
-	0xc1, 0x36, 0x7d, 0xaf, 0xdf, 0xde, 0x14, 0x81, 0x21, 0xd3, 0x38, 0xbf, 0x7f, 0x7a, 0x9a, 0x02,
-	0xd1, 0x1f, 0xe8, 0x09, 0x2e, 0x2d, 0xda, 0xdc, 0x0a, 0xfa, 0x5d, 0x79, 0xea, 0x6e, 0xe4, 0x76,
-	0x07, 0x35, 0xeb, 0x19, 0x12, 0x82, 0xf1, 0x05, 0x98, 0x8c, 0xa5, 0xc2, 0xe8, 0x0c, 0x64, 0x7d,
-	0xba, 0xa1, 0x5d, 0x01, 0x1a, 0xce, 0x98, 0x65, 0xf4, 0xe7, 0x38, 0x44, 0xfc, 0xe2, 0xef, 0xa4,
-	0xa1, 0x66, 0x54, 0xea, 0x2e, 0x79, 0xfe, 0x55, 0xca, 0x7c, 0xa7, 0x79, 0xcd, 0xee, 0x86, 0x75,
-	0x97, 0x1a, 0x94, 0xba, 0x02, 0xf8, 0x96, 0xb1, 0x8b, 0xa0, 0x1b, 0xe2, 0xa1, 0x63, 0x00, 0x62,
-	0xdb, 0xc9, 0x7e, 0xb9, 0xa1, 0x8a, 0x02, 0x22, 0xba, 0x97, 0x63, 0xc2, 0xae, 0xef, 0x53, 0x38,
-	0x4a, 0xc8, 0x97, 0x93, 0x42, 0xde, 0x37, 0x9d, 0x50, 0xb2, 0xe6, 0x76, 0xc9, 0xc5, 0xb7, 0x0b,
-	0xfe, 0xbb, 0x05, 0xd5, 0x35, 0xbd, 0xf2, 0x47, 0x14, 0x87, 0xe6, 0x37, 0xfd, 0x98, 0xf8, 0xcd,
-	0x3c, 0x46, 0x7e, 0xb3, 0x09, 0x7e, 0xab, 0x00, 0x6b, 0x8e, 0x4b, 0x2f, 0x39, 0x1d, 0x46, 0xfd,
-	0x11, 0x87, 0xa4, 0xef, 0x67, 0x22, 0x8f, 0x43, 0xe8, 0x86, 0x96, 0xc1, 0xb2, 0xe1, 0xe6, 0x1f,
-	0x07, 0x8b, 0xe9, 0xc7, 0xc8, 0x62, 0x26, 0xe1, 0x01, 0x5d, 0x98, 0xd8, 0x10, 0xec, 0xc9, 0x88,
-	0x1d, 0xab, 0x19, 0x47, 0xbc, 0x37, 0xbe, 0xa2, 0x26, 0x3f, 0xb7, 0x47, 0xc2, 0x25, 0x6a, 0xff,
-	0xf5, 0x60, 0xdb, 0x65, 0xf6, 0x43, 0x63, 0x3c, 0xd1, 0x93, 0x20, 0x5b, 0xe5, 0x74, 0xb9, 0x91,
-	0x39, 0xdd, 0x4b, 0x6a, 0x9a, 0xff, 0x24, 0xaf, 0xc3, 0xed, 0xc8, 0xc1, 0x0a, 0xa5, 0x28, 0x07,
-	0x7b, 0x7c, 0xaf, 0xed, 0x2f, 0x37, 0x3d, 0x3a, 0x19, 0x3f, 0x9a, 0x95, 0xc3, 0xa3, 0x59, 0x8b,
-	0x3e, 0x8c, 0x9d, 0xcb, 0xf0, 0xef, 0x2c, 0x98, 0x5e, 0xa5, 0x2c, 0x9e, 0x8d, 0x7d, 0x89, 0x94,
-	0x8f, 0x5f, 0x81, 0x83, 0xc6, 0xfa, 0x95, 0x9c, 0x9e, 0x4d, 0xa4, 0x60, 0x87, 0x22, 0x49, 0x09,
-	0x19, 0xa8, 0x93, 0x6d, 0x3c, 0xfb, 0xba, 0x01, 0x25, 0xa3, 0x13, 0x5d, 0x48, 0xe4, 0x5d, 0x33,
-	0x89, 0x4b, 0x18, 0x9e, 0x3b, 0x34, 0x66, 0x15, 0x4f, 0xf2, 0xfc, 0xaa, 0xb2, 0xea, 0x30, 0x47,
-	0x59, 0x07, 0x24, 0x14, 0x2b, 0xc8, 0x9a, 0x51, 0x52, 0x40, 0x5f, 0x0d, 0x13, 0xb0, 0xb0, 0x8d,
-	0x9e, 0x80, 0xac, 0xef, 0x3d, 0xd0, 0x09, 0xf5, 0xa4, 0x51, 0x9d, 0xf7, 0x1e, 0x10, 0xd1, 0x85,
-	0x5f, 0x84, 0x0c, 0xf1, 0x1e, 0xa0, 0x2a, 0x80, 0x6f, 0xbb, 0x6d, 0x7a, 0x3b, 0x3c, 0xca, 0x95,
-	0x89, 0x01, 0x19, 0x93, 0xc1, 0x2c, 0xc3, 0x41, 0x73, 0x45, 0x52, 0xdd, 0x8b, 0x30, 0xf1, 0x5a,
-	0xdf, 0x14, 0xd7, 0x6c, 0x42, 0x5c, 0xb2, 0x62, 0xa0, 0x91, 0xb8, 0xcd, 0x40, 0x04, 0x47, 0x47,
-	0xa1, 0xc8, 0xec, 0x3b, 0x1d, 0x7a, 0x2d, 0x72, 0x96, 0x11, 0x80, 0xf7, 0xf2, 0x53, 0xe8, 0x6d,
-	0x23, 0x15, 0x8b, 0x00, 0xe8, 0x69, 0x98, 0x8e, 0xd6, 0x7c, 0xc3, 0xa7, 0x1b, 0xce, 0x43, 0xa1,
-	0xe1, 0x32, 0x19, 0x82, 0xa3, 0x93, 0x70, 0x20, 0x82, 0xad, 0x8b, 0x94, 0x27, 0x2b, 0x50, 0x93,
-	0x60, 0x2e, 0x1b, 0xc1, 0xee, 0xc5, 0x7b, 0x7d, 0xbb, 0x23, 0xb6, 0x69, 0x99, 0x18, 0x10, 0xfc,
-	0x7b, 0x0b, 0x0e, 0x4a, 0x55, 0xf3, 0x3d, 0xf0, 0x65, 0xb4, 0xfa, 0x9f, 0x5b, 0x80, 0x4c, 0x0e,
-	0x94, 0x69, 0xfd, 0x8f, 0x59, 0x91, 0xe2, 0x39, 0x55, 0x49, 0x1c, 0xae, 0x25, 0x28, 0x2a, 0x2a,
-	0x61, 0xc8, 0x37, 0x65, 0xe5, 0x4d, 0x5c, 0x1e, 0xc8, 0xd3, 0xbb, 0x84, 0x10, 0xf5, 0x8f, 0x6a,
-	0x90, 0xbb, 0xb3, 0xcd, 0x68, 0xa0, 0xce, 0xde, 0xa2, 0xe8, 0x20, 0x00, 0x44, 0xfe, 0xf1, 0xb9,
-	0xd4, 0x7d, 0x99, 0x50, 0x83, 0x9a, 0x4b, 0x81, 0x88, 0xfe, 0xc0, 0xff, 0x4c, 0xc3, 0xe4, 0x6d,
-	0xaf, 0xd3, 0x8f, 0xc2, 0xeb, 0x97, 0x29, 0xb4, 0xc4, 0x0a, 0x02, 0x39, 0x5d, 0x10, 0x40, 0x90,
-	0x0d, 0x18, 0xed, 0x09, 0xcb, 0xca, 0x10, 0xf1, 0x8d, 0x30, 0x94, 0x99, 0xed, 0xb7, 0x29, 0x93,
-	0xc7, 0xac, 0x4a, 0x5e, 0xe4, 0xbf, 0x31, 0x18, 0x5a, 0x80, 0x92, 0xdd, 0x6e, 0xfb, 0xb4, 0x6d,
-	0x33, 0xda, 0xd8, 0xae, 0x4c, 0x88, 0xc9, 0x4c, 0x10, 0xba, 0x02, 0x53, 0x4d, 0xbb, 0xb9, 0xe9,
-	0xb8, 0xed, 0xeb, 0x3d, 0x79, 0x53, 0x52, 0x10, 0x1e, 0xfc, 0xe8, 0xa2, 0x79, 0x8d, 0xbc, 0xb8,
-	0x1c, 0xc3, 0x51, 0x7e, 0x2c, 0x31, 0x12, 0xbf, 0x01, 0x53, 0x5a, 0xf0, 0xca, 0x3c, 0xce, 0xc0,
-	0xc4, 0x7d, 0x01, 0x19, 0x51, 0xec, 0x93, 0xa8, 0x8a, 0x94, 0x46, 0x8b, 0x5f, 0x6a, 0x68, 0xfe,
-	0xf1, 0x15, 0xc8, 0x4b, 0x74, 0x74, 0xd4, 0x3c, 0x78, 0xc9, 0xdc, 0x93, 0xb7, 0xd5, 0x29, 0x0a,
-	0x43, 0x5e, 0x12, 0x52, 0x46, 0x24, 0xec, 0x4c, 0x42, 0x88, 0xfa, 0xc7, 0x3f, 0x48, 0xc3, 0xa1,
-	0x15, 0xca, 0xc4, 0xbd, 0xe2, 0x25, 0x87, 0x76, 0x5a, 0x9f, 0x6b, 0x4d, 0x20, 0xac, 0xec, 0x65,
-	0x8c, 0xca, 0x1e, 0xf7, 0x61, 0x1d, 0xc7, 0xa5, 0x6b, 0x46, 0x69, 0x28, 0x02, 0x44, 0x32, 0xca,
-	0x99, 0x45, 0x23, 0x6d, 0x23, 0x79, 0xc3, 0x46, 0xa2, 0x82, 0xe0, 0x44, 0xac, 0x86, 0xa9, 0x4f,
-	0xa0, 0x85, 0xe8, 0xf8, 0x8a, 0x7f, 0x63, 0xc1, 0x5c, 0x52, 0x2e, 0x4a, 0x8d, 0x17, 0x21, 0xbf,
-	0x21, 0x20, 0xc3, 0x65, 0xe7, 0xd8, 0x08, 0x59, 0xb9, 0x90, 0xa8, 0x66, 0xe5, 0x42, 0x42, 0xd0,
-	0x53, 0xb1, 0x0b, 0xab, 0xc6, 0xcc, 0xee, 0xa0, 0x76, 0x40, 0x00, 0x0c, 0x5c, 0xc5, 0xcc, 0xe9,
-	0x70, 0xe1, 0x99, 0xa8, 0x24, 0x22, 0x21, 0x26, 0x61, 0x55, 0xdf, 0xfc, 0x97, 0x05, 0x93, 0xb1,
-	0x85, 0x08, 0x11, 0xf1, 0x2d, 0xa0, 0xc2, 0x83, 0x6c, 0xa0, 0xa7, 0x20, 0xcb, 0xb6, 0x7b, 0x2a,
-	0x2a, 0x34, 0x0e, 0x7d, 0x32, 0xa8, 0x1d, 0x8c, 0x0d, 0xbb, 0xb9, 0xdd, 0xa3, 0x44, 0xa0, 0xf0,
-	0x9d, 0xd3, 0xb4, 0xfd, 0x96, 0xe3, 0xda, 0x1d, 0x87, 0x6d, 0xab, 0x2b, 0x78, 0x13, 0xc4, 0xdd,
-	0x51, 0xcf, 0xf6, 0x03, 0x9d, 0x04, 0x16, 0xa5, 0x3b, 0x52, 0x20, 0xa2, 0x3f, 0x44, 0x71, 0x67,
-	0x8b, 0xb2, 0xe6, 0xa6, 0x0c, 0x0b, 0xaa, 0xb8, 0x23, 0x20, 0xb1, 0xe2, 0x8e, 0x80, 0xa0, 0x25,
-	0x28, 0xdc, 0x0d, 0x3c, 0xf7, 0x86, 0xcd, 0x36, 0xe5, 0x86, 0x6e, 0xcc, 0xed, 0x0e, 0x6a, 0x48,
-	0xc3, 0x8c, 0x11, 0x21, 0x1e, 0xfe, 0xa9, 0x15, 0x19, 0xb4, 0xdc, 0xf7, 0x5f, 0x38, 0x83, 0xc6,
-	0x5f, 0x8b, 0x6c, 0x4b, 0x2f, 0x51, 0xd9, 0xd6, 0xcb, 0x30, 0xd5, 0x8a, 0xf5, 0x8c, 0xb7, 0x31,
-	0x59, 0xec, 0x4e, 0xa0, 0xe3, 0x7e, 0xa4, 0x7b, 0x01, 0x19, 0xa3, 0xfb, 0x84, 0x42, 0xd3, 0xc3,
-	0x0a, 0x8d, 0x34, 0x95, 0xd9, 0x5b, 0x53, 0x4f, 0x1f, 0x87, 0x62, 0x78, 0xb1, 0x89, 0x4a, 0x30,
-	0x71, 0xe9, 0x3a, 0x79, 0xfd, 0x02, 0x59, 0x99, 0x4e, 0xa1, 0x32, 0x14, 0x1a, 0x17, 0x96, 0x5f,
-	0x15, 0x2d, 0x6b, 0xe9, 0x97, 0x79, 0x9d, 0xec, 0xf8, 0xe8, 0xff, 0x21, 0x27, 0x33, 0x98, 0xb9,
-	0x88, 0x39, 0xf3, 0xce, 0x6f, 0xfe, 0xf0, 0x10, 0x5c, 0x4a, 0x09, 0xa7, 0xce, 0x58, 0xe8, 0x1a,
-	0x94, 0x04, 0x50, 0x55, 0xd5, 0x8f, 0x26, 0x8b, 0xdb, 0x31, 0x4a, 0xc7, 0xc6, 0xf4, 0x1a, 0xf4,
-	0xce, 0x43, 0x4e, 0x0a, 0x6c, 0x2e, 0x91, 0x68, 0x8e, 0x58, 0x4d, 0xec, 0x9e, 0x01, 0xa7, 0xd0,
-	0x0b, 0x90, 0xbd, 0x69, 0x3b, 0x1d, 0x64, 0xe4, 0xb9, 0x46, 0x31, 0x7c, 0x7e, 0x2e, 0x09, 0x36,
-	0xa6, 0x7d, 0x29, 0xac, 0xe9, 0x1f, 0x4e, 0x16, 0x16, 0xf5, 0xf0, 0xca, 0x70, 0x47, 0x38, 0xf3,
-	0x75, 0x59, 0x79, 0xd6, 0xe5, 0x2d, 0x74, 0x2c, 0x3e, 0x55, 0xa2, 0x1a, 0x36, 0x5f, 0x1d, 0xd7,
-	0x1d, 0x12, 0x5c, 0x83, 0x92, 0x51, 0x5a, 0x32, 0xc5, 0x3a, 0x5c, 0x17, 0x33, 0xc5, 0x3a, 0xa2,
-	0x1e, 0x85, 0x53, 0x68, 0x15, 0x0a, 0xe2, 0xd1, 0x87, 0xcd, 0x02, 0x74, 0x24, 0x79, 0x08, 0x30,
-	0x92, 0xbf, 0xf9, 0xa3, 0xa3, 0x3b, 0x43, 0x42, 0x5f, 0x85, 0xe2, 0x2a, 0x65, 0x2a, 0xea, 0x1d,
-	0x4e, 0x86, 0xcd, 0x11, 0x92, 0x8a, 0x87, 0x5e, 0x9c, 0x42, 0x6f, 0x88, 0x83, 0x4a, 0xdc, 0xa5,
-	0xa3, 0xda, 0x18, 0xd7, 0x1d, 0xae, 0x6b, 0x61, 0x3c, 0x42, 0x48, 0xf9, 0xf5, 0x18, 0x65, 0x95,
-	0x6b, 0xd4, 0xc6, 0x6c, 0xd8, 0x90, 0x72, 0x6d, 0x8f, 0x47, 0x65, 0x38, 0xb5, 0xf4, 0xa6, 0x7e,
-	0x9d, 0xb2, 0x62, 0x33, 0x1b, 0x5d, 0x87, 0xa9, 0xf0, 0x01, 0x8d, 0x78, 0x78, 0x15, 0xb3, 0xf9,
-	0xa1, 0x57, 0x5e, 0x31, 0x9b, 0x1f, 0x7e, 0xed, 0x85, 0x53, 0x4b, 0x77, 0x61, 0x56, 0x5e, 0x16,
-	0xca, 0x57, 0x4c, 0x97, 0x7c, 0xcf, 0x65, 0xdc, 0x67, 0x11, 0x98, 0x8c, 0x3d, 0x6f, 0x42, 0x86,
-	0xd5, 0x8c, 0x7a, 0x5b, 0x65, 0xb2, 0x32, 0xf2, 0x5d, 0x14, 0x4e, 0x2d, 0xfd, 0xc9, 0x82, 0xb2,
-	0x39, 0x19, 0xba, 0x65, 0x70, 0x23, 0x9e, 0x03, 0x99, 0x12, 0x1b, 0xf9, 0x3c, 0xc9, 0xd4, 0xc5,
-	0xe8, 0x97, 0x44, 0x38, 0x85, 0xee, 0x8a, 0xb7, 0x4d, 0xc3, 0x0f, 0x50, 0xd0, 0xf1, 0x3d, 0xdf,
-	0xba, 0xc8, 0x49, 0x4e, 0x7c, 0xc6, 0x37, 0x31, 0x38, 0xd5, 0x78, 0xf3, 0xbd, 0x0f, 0xab, 0xa9,
-	0xf7, 0x3f, 0xac, 0xa6, 0x3e, 0xfe, 0xb0, 0x6a, 0x7d, 0x6b, 0xa7, 0x6a, 0xfd, 0x62, 0xa7, 0x6a,
-	0xbd, 0xbb, 0x53, 0xb5, 0xde, 0xdb, 0xa9, 0x5a, 0x7f, 0xdd, 0xa9, 0x5a, 0x7f, 0xdb, 0xa9, 0xa6,
-	0x3e, 0xde, 0xa9, 0x5a, 0x6f, 0x7f, 0x54, 0x4d, 0xbd, 0xf7, 0x51, 0x35, 0xf5, 0xfe, 0x47, 0xd5,
-	0xd4, 0xd7, 0x4f, 0xec, 0x5d, 0xfe, 0x90, 0x61, 0x25, 0x2f, 0xfe, 0x9e, 0xfd, 0x77, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0xac, 0xff, 0x12, 0xd2, 0x6f, 0x29, 0x00, 0x00,
+	// 3143 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x3a, 0x4b, 0x8c, 0x1b, 0xc7,
+	0xb1, 0x3b, 0xfc, 0x2d, 0x59, 0xe4, 0x7e, 0xd4, 0xfb, 0x11, 0x41, 0x49, 0xe4, 0xba, 0xe1, 0x27,
+	0xad, 0x2d, 0x99, 0x94, 0xd6, 0xcf, 0x7a, 0xb6, 0xfc, 0xfc, 0xfc, 0xc4, 0x5d, 0x69, 0x2d, 0x79,
+	0xf5, 0x71, 0xaf, 0x24, 0x3b, 0x41, 0x0c, 0x63, 0x96, 0xec, 0xe5, 0x8e, 0x96, 0x9c, 0xa1, 0x66,
+	0x9a, 0x92, 0x98, 0x53, 0xee, 0x41, 0x10, 0x23, 0x41, 0x90, 0xe4, 0x12, 0x20, 0x41, 0x80, 0x04,
+	0x01, 0x7c, 0x09, 0x72, 0xc8, 0x21, 0x48, 0x2e, 0x39, 0x38, 0x37, 0xe7, 0x14, 0xc3, 0x07, 0x26,
+	0x5e, 0x5f, 0x82, 0x05, 0x02, 0xf8, 0x94, 0x00, 0xce, 0x25, 0xe8, 0xcf, 0xcc, 0xf4, 0xcc, 0x92,
+	0x5e, 0x53, 0x51, 0x60, 0xfb, 0x42, 0x4e, 0x57, 0x57, 0x57, 0x77, 0x7d, 0xba, 0xaa, 0xba, 0xba,
+	0xe1, 0x58, 0x77, 0xb7, 0x55, 0x6b, 0x3b, 0xad, 0xae, 0xeb, 0x30, 0x27, 0xf8, 0xa8, 0x8a, 0x5f,
+	0x94, 0xf5, 0xdb, 0xa5, 0xf9, 0x96, 0xd3, 0x72, 0x24, 0x0e, 0xff, 0x92, 0xfd, 0xa5, 0x4a, 0xcb,
+	0x71, 0x5a, 0x6d, 0x5a, 0x13, 0xad, 0xad, 0xde, 0x76, 0x8d, 0x59, 0x1d, 0xea, 0x31, 0xb3, 0xd3,
+	0x55, 0x08, 0x4b, 0x8a, 0xfa, 0xbd, 0x76, 0xc7, 0x69, 0xd2, 0x76, 0xcd, 0x63, 0x26, 0xf3, 0xe4,
+	0xaf, 0xc2, 0x98, 0xe3, 0x18, 0xdd, 0x9e, 0xb7, 0x23, 0x7e, 0x14, 0xf0, 0x2c, 0x07, 0x7a, 0xcc,
+	0x71, 0xcd, 0x16, 0xad, 0x35, 0x76, 0x7a, 0xf6, 0x6e, 0xad, 0x61, 0x36, 0x76, 0x68, 0xcd, 0xa5,
+	0x5e, 0xaf, 0xcd, 0x3c, 0xd9, 0x60, 0xfd, 0x2e, 0x55, 0x64, 0xf0, 0xaf, 0x0c, 0x58, 0xd8, 0x30,
+	0xb7, 0x68, 0xfb, 0x96, 0x73, 0xc7, 0x6c, 0xf7, 0xa8, 0x47, 0xa8, 0xd7, 0x75, 0x6c, 0x8f, 0xa2,
+	0x55, 0xc8, 0xb4, 0x79, 0x87, 0x57, 0x34, 0x96, 0x92, 0xcb, 0xf9, 0x95, 0xd3, 0xd5, 0x80, 0xc9,
+	0xa1, 0x03, 0x24, 0xd4, 0xbb, 0x64, 0x33, 0xb7, 0x4f, 0xd4, 0xd0, 0xd2, 0x1d, 0xc8, 0x6b, 0x60,
+	0x34, 0x0b, 0xc9, 0x5d, 0xda, 0x2f, 0x1a, 0x4b, 0xc6, 0x72, 0x8e, 0xf0, 0x4f, 0x74, 0x0e, 0xd2,
+	0xf7, 0x39, 0x99, 0x62, 0x62, 0xc9, 0x58, 0xce, 0xaf, 0x1c, 0x0b, 0x27, 0xb9, 0x6d, 0x5b, 0xf7,
+	0x7a, 0x54, 0x8c, 0x56, 0x13, 0x49, 0xcc, 0x0b, 0x89, 0xe7, 0x0d, 0x7c, 0x1a, 0x8e, 0x1c, 0xe8,
+	0x47, 0x8b, 0x90, 0x11, 0x18, 0x72, 0xc5, 0x39, 0xa2, 0x5a, 0x78, 0x1e, 0xd0, 0x26, 0x73, 0xa9,
+	0xd9, 0x21, 0x26, 0xe3, 0xeb, 0xbd, 0xd7, 0xa3, 0x1e, 0xc3, 0xd7, 0x60, 0x2e, 0x02, 0x55, 0x6c,
+	0x9f, 0x87, 0xbc, 0x17, 0x82, 0x15, 0xef, 0xf3, 0xe1, 0xb2, 0xc2, 0x31, 0x44, 0x47, 0xc4, 0x3f,
+	0x31, 0x60, 0x5a, 0xf6, 0x5d, 0xa3, 0xcc, 0x6c, 0x9a, 0xcc, 0x44, 0x65, 0x00, 0x89, 0xf1, 0x8a,
+	0xe9, 0xed, 0x08, 0xa6, 0x53, 0x44, 0x83, 0xa0, 0x25, 0xc8, 0x53, 0x9b, 0xb9, 0x16, 0xf5, 0x36,
+	0xad, 0xaf, 0x4b, 0x09, 0xa4, 0x88, 0x0e, 0x42, 0xe7, 0x61, 0xd1, 0x63, 0x6e, 0xaf, 0xc1, 0x7a,
+	0x2e, 0x6d, 0xfa, 0x74, 0x05, 0x72, 0x52, 0x20, 0x8f, 0xe8, 0xe5, 0x92, 0xe8, 0x3a, 0x6d, 0xab,
+	0xd1, 0x2f, 0xa6, 0x84, 0xa8, 0x55, 0x0b, 0x6f, 0xc1, 0xfc, 0xa5, 0x87, 0x0d, 0x4a, 0x9b, 0xde,
+	0x86, 0xd5, 0xb1, 0x98, 0x2f, 0x0b, 0x8e, 0xcf, 0xa8, 0x6d, 0xda, 0x4c, 0xa9, 0x46, 0xb5, 0xd0,
+	0x0a, 0x4c, 0xca, 0xf5, 0x7a, 0xc5, 0x84, 0x10, 0x44, 0x31, 0x2e, 0x08, 0x7f, 0x5a, 0xe2, 0x23,
+	0x62, 0x0f, 0x16, 0x62, 0x73, 0x28, 0xc9, 0x8e, 0x9a, 0xa4, 0x0e, 0x33, 0x2e, 0xbd, 0x4b, 0x1b,
+	0x8c, 0x36, 0x37, 0x47, 0x4d, 0x46, 0x22, 0x08, 0x24, 0x3e, 0x00, 0xbf, 0x02, 0xd3, 0x51, 0x94,
+	0x43, 0x85, 0xbf, 0x08, 0x19, 0x97, 0x9a, 0x9e, 0x63, 0x0b, 0xb9, 0xe7, 0x88, 0x6a, 0xe1, 0x6f,
+	0x1a, 0xb0, 0xb0, 0x4e, 0x99, 0xa4, 0x72, 0xdb, 0x33, 0x5b, 0xf4, 0x30, 0x21, 0x95, 0x01, 0xba,
+	0xa6, 0xcb, 0x2c, 0x66, 0x39, 0xb6, 0x5c, 0x7a, 0x9a, 0x68, 0x10, 0x84, 0xa1, 0x10, 0xce, 0x4b,
+	0xbd, 0x62, 0x72, 0x29, 0xb9, 0x9c, 0x22, 0x11, 0xd8, 0x48, 0x85, 0x7d, 0xc7, 0x80, 0xc5, 0xf8,
+	0x6a, 0x0e, 0x11, 0xe7, 0x93, 0x30, 0x65, 0x36, 0x98, 0x75, 0x9f, 0x86, 0xc2, 0xe4, 0xbc, 0x47,
+	0x81, 0x08, 0x41, 0xca, 0x35, 0x99, 0xb4, 0xa3, 0x24, 0x11, 0xdf, 0xe8, 0x24, 0x4c, 0xf7, 0xec,
+	0x5d, 0xdb, 0x79, 0x60, 0xfb, 0x43, 0x53, 0x62, 0xa9, 0x31, 0x28, 0xfe, 0x91, 0x01, 0x10, 0x6e,
+	0x83, 0x43, 0x25, 0x7d, 0x06, 0x8e, 0x84, 0xad, 0xeb, 0xce, 0xe6, 0x8e, 0xe9, 0x36, 0xd5, 0xa2,
+	0x0e, 0x76, 0x0c, 0x5d, 0x58, 0xc8, 0x6a, 0x2a, 0xc2, 0x2a, 0x97, 0x5a, 0x4f, 0xc8, 0x34, 0xbd,
+	0x64, 0x2c, 0x4f, 0x11, 0xd5, 0xc2, 0x65, 0x38, 0xbe, 0x4e, 0xd9, 0x45, 0xcf, 0xb3, 0x5a, 0x36,
+	0x6d, 0xde, 0x0c, 0x54, 0xe1, 0x6f, 0xfd, 0x3f, 0x19, 0x70, 0x62, 0x04, 0x82, 0x12, 0xae, 0x03,
+	0xc8, 0x3c, 0xd0, 0xab, 0x9c, 0xc1, 0xcb, 0xa1, 0x59, 0x7e, 0x2a, 0x91, 0xea, 0xc1, 0x2e, 0xe9,
+	0x1c, 0x87, 0x90, 0x2e, 0x5d, 0x82, 0xa3, 0x23, 0xd0, 0x75, 0xa7, 0x99, 0x96, 0x4e, 0x73, 0x5e,
+	0x77, 0x9a, 0x49, 0xdd, 0x2f, 0xfe, 0x3d, 0x09, 0x85, 0xd7, 0x7a, 0xd4, 0xed, 0xfb, 0x46, 0x5b,
+	0x86, 0xac, 0x47, 0xdb, 0xb4, 0xc1, 0x1c, 0x57, 0xda, 0x49, 0x3d, 0x51, 0x34, 0x48, 0x00, 0xe3,
+	0xa4, 0xda, 0x7c, 0x9b, 0x0a, 0x52, 0x53, 0x44, 0x36, 0xd0, 0x05, 0x48, 0x7b, 0xcc, 0x74, 0x99,
+	0xd0, 0x42, 0x7e, 0xa5, 0x54, 0x95, 0xf1, 0xaa, 0xea, 0xc7, 0xab, 0xea, 0x2d, 0x3f, 0x5e, 0xd5,
+	0xb3, 0xef, 0x0e, 0x2a, 0x13, 0x6f, 0xff, 0xb9, 0x62, 0x10, 0x39, 0x04, 0x9d, 0x87, 0x24, 0xb5,
+	0x9b, 0xc5, 0xd4, 0x18, 0x23, 0xf9, 0x00, 0x74, 0x0e, 0x72, 0x4d, 0xcb, 0xa5, 0x0d, 0xce, 0xb9,
+	0xd0, 0xe7, 0xf4, 0xca, 0x5c, 0x28, 0xe9, 0x35, 0xbf, 0x8b, 0x84, 0x58, 0xe8, 0x0c, 0x64, 0x3c,
+	0x6e, 0x34, 0x5e, 0x71, 0x92, 0x3b, 0xfc, 0xfa, 0xfc, 0xfe, 0xa0, 0x32, 0x2b, 0x21, 0x67, 0x9c,
+	0x8e, 0xc5, 0x68, 0xa7, 0xcb, 0xfa, 0x44, 0xe1, 0xa0, 0xa7, 0x61, 0xb2, 0x49, 0xdb, 0x94, 0x7b,
+	0xf5, 0xac, 0x50, 0xe4, 0xac, 0x46, 0x5e, 0x74, 0x10, 0x1f, 0x01, 0xbd, 0x09, 0xa9, 0x6e, 0xdb,
+	0xb4, 0x8b, 0x39, 0xc1, 0xc5, 0x74, 0x88, 0x78, 0xb3, 0x6d, 0xda, 0xf5, 0x17, 0x3e, 0x18, 0x54,
+	0x9e, 0x6b, 0x59, 0x6c, 0xa7, 0xb7, 0x55, 0x6d, 0x38, 0x9d, 0x5a, 0xcb, 0x35, 0xb7, 0x4d, 0xdb,
+	0xac, 0xb5, 0x9d, 0x5d, 0xab, 0x76, 0xff, 0xd9, 0x1a, 0x8f, 0xc2, 0xf7, 0x7a, 0xd4, 0xb5, 0xa8,
+	0x5b, 0xe3, 0x64, 0xaa, 0x42, 0x25, 0x7c, 0x28, 0x11, 0x64, 0xd1, 0x55, 0x1e, 0x64, 0x1c, 0x97,
+	0xae, 0xf2, 0x10, 0xed, 0x15, 0x41, 0xcc, 0x72, 0x34, 0x9c, 0x45, 0xc0, 0x09, 0xdd, 0x5e, 0x77,
+	0x9d, 0x5e, 0xb7, 0x3e, 0xb3, 0x3f, 0xa8, 0xe8, 0xf8, 0x44, 0x6f, 0x5c, 0x4d, 0x65, 0x33, 0xb3,
+	0x93, 0xf8, 0x9d, 0x24, 0xa0, 0x4d, 0xb3, 0xd3, 0x6d, 0xd3, 0xb1, 0xd4, 0x1f, 0x28, 0x3a, 0xf1,
+	0xc8, 0x8a, 0x4e, 0x8e, 0xab, 0xe8, 0x50, 0x6b, 0xa9, 0xf1, 0xb4, 0x96, 0xfe, 0xac, 0x5a, 0xcb,
+	0x7c, 0xe1, 0xb5, 0x86, 0x8b, 0x90, 0xe2, 0x94, 0xf9, 0xe6, 0x76, 0xcd, 0x07, 0x42, 0x37, 0x05,
+	0xc2, 0x3f, 0xf1, 0x06, 0x64, 0x24, 0x5f, 0xa8, 0x14, 0x57, 0x5e, 0x74, 0xdf, 0x86, 0x8a, 0x4b,
+	0xfa, 0x2a, 0x99, 0x0d, 0x55, 0x92, 0x14, 0xc2, 0xc6, 0xbf, 0x31, 0x60, 0x4a, 0x59, 0x84, 0x72,
+	0x6d, 0x5b, 0x61, 0x4c, 0x97, 0xfe, 0xec, 0x68, 0x3c, 0xa6, 0x5f, 0x6c, 0x9a, 0x5d, 0x46, 0xdd,
+	0x7a, 0xed, 0xdd, 0x41, 0xc5, 0xf8, 0x60, 0x50, 0x39, 0x35, 0x4a, 0x68, 0x7e, 0x0a, 0xaa, 0xc6,
+	0x05, 0x39, 0x00, 0x3a, 0x2d, 0x56, 0xc7, 0x3c, 0x65, 0x56, 0x33, 0x55, 0xd1, 0xaa, 0x5e, 0xb1,
+	0x5b, 0xd4, 0xe3, 0x94, 0x53, 0xdc, 0x22, 0x88, 0xc4, 0xe1, 0x6c, 0x3e, 0x30, 0x5d, 0xdb, 0xb2,
+	0x5b, 0x32, 0x36, 0xe6, 0x48, 0xd0, 0xc6, 0x3f, 0x30, 0x60, 0x2e, 0x62, 0xd6, 0x8a, 0x89, 0xe7,
+	0x21, 0xe3, 0x71, 0x4d, 0xf9, 0x3c, 0x68, 0x46, 0xb1, 0x29, 0xe0, 0xf5, 0x69, 0xb5, 0xf8, 0x8c,
+	0x6c, 0x13, 0x85, 0xff, 0xf8, 0x96, 0xf6, 0x7b, 0x03, 0x0a, 0x22, 0xfb, 0xf4, 0xf7, 0x1a, 0x82,
+	0x94, 0x6d, 0x76, 0xa8, 0x52, 0x95, 0xf8, 0xd6, 0x52, 0x52, 0x3e, 0x5d, 0xd6, 0x4f, 0x49, 0xc7,
+	0x75, 0xb0, 0xc6, 0x23, 0x3b, 0x58, 0x23, 0xdc, 0x77, 0xf3, 0x90, 0xe6, 0xe6, 0xdd, 0x17, 0xce,
+	0x35, 0x47, 0x64, 0x03, 0x9f, 0x82, 0x29, 0xc5, 0x45, 0x98, 0x57, 0x0c, 0xcd, 0xa2, 0x3b, 0x90,
+	0x91, 0x9a, 0x40, 0x4f, 0x42, 0x2e, 0x38, 0xaf, 0x08, 0x6e, 0x93, 0xf5, 0xcc, 0xfe, 0xa0, 0x92,
+	0x60, 0x1e, 0x09, 0x3b, 0x50, 0x45, 0x0f, 0x52, 0x46, 0x3d, 0xb7, 0x3f, 0xa8, 0x48, 0x80, 0x8a,
+	0x57, 0xe8, 0x38, 0xa4, 0x76, 0x78, 0xc6, 0x20, 0x52, 0xd9, 0x7a, 0x76, 0x7f, 0x50, 0x11, 0x6d,
+	0x22, 0x7e, 0xf1, 0x3a, 0x14, 0x36, 0x68, 0xcb, 0x6c, 0xf4, 0xd5, 0xa4, 0x41, 0xcc, 0xe3, 0x13,
+	0x1a, 0x3e, 0x8d, 0x27, 0xa0, 0x10, 0xcc, 0xf8, 0x96, 0xca, 0x75, 0x92, 0x24, 0x1f, 0xc0, 0xae,
+	0x79, 0xf8, 0x87, 0x06, 0x28, 0x1b, 0x40, 0x58, 0x3b, 0xd2, 0x70, 0x5f, 0x08, 0xfb, 0x83, 0x8a,
+	0x82, 0xf8, 0x27, 0x16, 0xf4, 0x22, 0x4c, 0x7a, 0x62, 0x46, 0x3f, 0x0b, 0xd5, 0x4d, 0x4b, 0x74,
+	0xd4, 0x67, 0xb8, 0x89, 0xec, 0x0f, 0x2a, 0x3e, 0x22, 0xf1, 0x3f, 0x50, 0x35, 0x92, 0x0a, 0x49,
+	0xc6, 0xa6, 0xf7, 0x07, 0x15, 0x0d, 0xaa, 0xa7, 0x46, 0xf8, 0x13, 0x03, 0xf2, 0xb7, 0x4c, 0x2b,
+	0x30, 0xa1, 0xa2, 0xaf, 0xa2, 0xd0, 0x57, 0x4b, 0x00, 0xb7, 0xc4, 0x26, 0x6d, 0x9b, 0xfd, 0xcb,
+	0x8e, 0x2b, 0xe8, 0x4e, 0x91, 0xa0, 0x1d, 0xc6, 0xf0, 0xd4, 0xd0, 0x18, 0x9e, 0x1e, 0xdf, 0xb5,
+	0xff, 0x67, 0x1d, 0xe9, 0xd5, 0x54, 0x36, 0x31, 0x9b, 0xc4, 0xef, 0x18, 0x50, 0x90, 0xcc, 0x2b,
+	0xcb, 0xfb, 0x1a, 0x64, 0xa4, 0x6c, 0x04, 0xfb, 0x9f, 0xe2, 0x98, 0x4e, 0x8f, 0xe3, 0x94, 0x14,
+	0x4d, 0xf4, 0x32, 0x4c, 0x37, 0x5d, 0xa7, 0xdb, 0x8d, 0x9f, 0x32, 0xb4, 0x59, 0xd6, 0xf4, 0x7e,
+	0x12, 0x43, 0xc7, 0x7f, 0x30, 0x60, 0x4a, 0x39, 0x13, 0xa5, 0xae, 0x40, 0xc4, 0xc6, 0x23, 0x47,
+	0xcf, 0xc4, 0xb8, 0xd1, 0x73, 0x11, 0x32, 0x2d, 0x1e, 0x5f, 0x7c, 0x87, 0xa4, 0x5a, 0xe3, 0x45,
+	0x55, 0x7c, 0x15, 0xa6, 0x7d, 0x56, 0x46, 0x78, 0xd4, 0x52, 0xdc, 0xa3, 0x5e, 0x69, 0x52, 0x9b,
+	0x59, 0xdb, 0x56, 0xe0, 0x23, 0x15, 0x3e, 0xfe, 0xb6, 0x01, 0xb3, 0x71, 0x14, 0xb4, 0x16, 0xab,
+	0x1e, 0x9c, 0x1c, 0x4d, 0x4e, 0x2f, 0x1c, 0xf8, 0xa4, 0x55, 0xf9, 0xe0, 0xb9, 0xc3, 0xca, 0x07,
+	0x91, 0x4c, 0x38, 0xa7, 0xbc, 0x02, 0xfe, 0xbe, 0x01, 0x53, 0x11, 0x5d, 0xa2, 0xe7, 0x21, 0xb5,
+	0xed, 0x3a, 0x9d, 0xb1, 0x14, 0x25, 0x46, 0xa0, 0xff, 0x86, 0x04, 0x73, 0xc6, 0x52, 0x53, 0x82,
+	0x39, 0x5c, 0x4b, 0x8a, 0xfd, 0xa4, 0x3c, 0xb1, 0xc8, 0x16, 0x7e, 0x0e, 0x72, 0x82, 0xa1, 0x9b,
+	0xa6, 0xe5, 0x0e, 0x0d, 0x18, 0xc3, 0x19, 0x7a, 0x11, 0x66, 0xa4, 0x33, 0x1c, 0x3e, 0xb8, 0x30,
+	0x6c, 0x70, 0xc1, 0x1f, 0x7c, 0x0c, 0xd2, 0x22, 0xe9, 0xe0, 0x43, 0xf8, 0x51, 0xdd, 0x1f, 0xc2,
+	0xbf, 0xf1, 0x02, 0xcc, 0xf1, 0x3d, 0x48, 0x5d, 0x6f, 0xd5, 0xe9, 0xd9, 0xcc, 0x3f, 0x21, 0x9d,
+	0x81, 0xf9, 0x28, 0x58, 0x59, 0xc9, 0x3c, 0xa4, 0x1b, 0x1c, 0x20, 0x68, 0x4c, 0x11, 0xd9, 0xc0,
+	0x3f, 0x35, 0x00, 0xad, 0x53, 0x26, 0x66, 0xb9, 0xb2, 0x16, 0x6c, 0x8f, 0x12, 0x64, 0x3b, 0x26,
+	0x6b, 0xec, 0x50, 0xd7, 0xf3, 0xf3, 0x17, 0xbf, 0xfd, 0x79, 0x24, 0x9e, 0xf8, 0x1c, 0xcc, 0x45,
+	0x56, 0xa9, 0x78, 0x2a, 0x41, 0xb6, 0xa1, 0x60, 0x2a, 0xe4, 0x05, 0x6d, 0xfc, 0xcb, 0x04, 0x64,
+	0xfd, 0xb4, 0x0e, 0x9d, 0x83, 0xfc, 0xb6, 0x65, 0xb7, 0xa8, 0xdb, 0x75, 0x2d, 0x25, 0x82, 0x94,
+	0x4c, 0xf3, 0x34, 0x30, 0xd1, 0x1b, 0xe8, 0x19, 0x98, 0xec, 0x79, 0xd4, 0x7d, 0xcb, 0x92, 0x3b,
+	0x3d, 0x57, 0x9f, 0xdf, 0x1b, 0x54, 0x32, 0xb7, 0x3d, 0xea, 0x5e, 0x59, 0xe3, 0xc1, 0xa7, 0x27,
+	0xbe, 0x88, 0xfc, 0x6f, 0xa2, 0x57, 0x95, 0x99, 0x8a, 0x04, 0xae, 0xfe, 0x3f, 0x7c, 0xf9, 0x31,
+	0x57, 0xd7, 0x75, 0x9d, 0x0e, 0x65, 0x3b, 0xb4, 0xe7, 0xd5, 0x1a, 0x4e, 0xa7, 0xe3, 0xd8, 0x35,
+	0x51, 0x20, 0x14, 0x4c, 0xf3, 0x08, 0xca, 0x87, 0x2b, 0xcb, 0xbd, 0x05, 0x93, 0x6c, 0xc7, 0x75,
+	0x7a, 0xad, 0x1d, 0x11, 0x18, 0x92, 0xf5, 0x0b, 0xe3, 0xd3, 0xf3, 0x29, 0x10, 0xff, 0x03, 0x3d,
+	0xc1, 0xa5, 0x45, 0x1b, 0xbb, 0x5e, 0xaf, 0x23, 0x4f, 0xdd, 0xf5, 0xf4, 0xfe, 0xa0, 0x62, 0x3c,
+	0x43, 0x02, 0x30, 0xbe, 0x08, 0x53, 0x91, 0x54, 0x18, 0x9d, 0x85, 0x94, 0x4b, 0xb7, 0x7d, 0x57,
+	0x80, 0x0e, 0x66, 0xcc, 0x32, 0xfa, 0x73, 0x1c, 0x22, 0x7e, 0xf1, 0xb7, 0x12, 0x50, 0xd1, 0x4a,
+	0x7b, 0x97, 0x1d, 0xf7, 0x1a, 0x65, 0xae, 0xd5, 0xb8, 0x6e, 0x76, 0x82, 0x7a, 0x4c, 0x05, 0xf2,
+	0x1d, 0x01, 0x7c, 0x4b, 0xdb, 0x45, 0xd0, 0x09, 0xf0, 0xd0, 0x09, 0x00, 0xb1, 0xed, 0x64, 0xbf,
+	0xdc, 0x50, 0x39, 0x01, 0x11, 0xdd, 0xab, 0x11, 0x61, 0xd7, 0xc6, 0x14, 0x8e, 0x12, 0xf2, 0x95,
+	0xb8, 0x90, 0xc7, 0xa6, 0x13, 0x48, 0x56, 0xdf, 0x2e, 0xe9, 0xe8, 0x76, 0xc1, 0x7f, 0x33, 0xa0,
+	0xbc, 0xe1, 0xaf, 0xfc, 0x11, 0xc5, 0xe1, 0xf3, 0x9b, 0x78, 0x4c, 0xfc, 0x26, 0x1f, 0x23, 0xbf,
+	0xa9, 0x18, 0xbf, 0x65, 0x80, 0x0d, 0xcb, 0xa6, 0x97, 0xad, 0x36, 0xa3, 0xee, 0x90, 0x43, 0xd2,
+	0x77, 0x93, 0xa1, 0xc7, 0x21, 0x74, 0xdb, 0x97, 0xc1, 0xaa, 0xe6, 0xe6, 0x1f, 0x07, 0x8b, 0x89,
+	0xc7, 0xc8, 0x62, 0x32, 0xe6, 0x01, 0x6d, 0x98, 0xdc, 0x16, 0xec, 0xc9, 0x88, 0x1d, 0x29, 0x32,
+	0x87, 0xbc, 0xd7, 0xff, 0x4f, 0x4d, 0x7e, 0xfe, 0x90, 0x84, 0x4b, 0x5c, 0x16, 0xd4, 0xbc, 0xbe,
+	0xcd, 0xcc, 0x87, 0xda, 0x78, 0xe2, 0x4f, 0x82, 0x4c, 0x95, 0xd3, 0xa5, 0x87, 0xe6, 0x74, 0x2f,
+	0xa9, 0x69, 0xfe, 0x9d, 0xbc, 0x0e, 0xb7, 0x60, 0x2e, 0xa2, 0x14, 0xe5, 0x60, 0x4f, 0x1e, 0xb6,
+	0xfd, 0xe5, 0xa6, 0x47, 0xcb, 0xd1, 0xa3, 0x59, 0x21, 0x38, 0x9a, 0x35, 0xe9, 0xc3, 0xc8, 0xb9,
+	0x0c, 0xff, 0xd6, 0x80, 0x59, 0x5e, 0x16, 0x8d, 0x64, 0x63, 0x5f, 0x22, 0xe5, 0xe3, 0x57, 0xe0,
+	0x88, 0xb6, 0x7e, 0x25, 0xa7, 0x67, 0x63, 0x29, 0xd8, 0x42, 0x28, 0x29, 0x21, 0x03, 0x75, 0xb2,
+	0x8d, 0x66, 0x5f, 0x37, 0x21, 0xaf, 0x75, 0xa2, 0x8b, 0xb1, 0xbc, 0x6b, 0x2e, 0x76, 0x6b, 0xc3,
+	0x73, 0x87, 0xfa, 0xbc, 0xe2, 0x49, 0x9e, 0x5f, 0x55, 0x56, 0x1d, 0xe4, 0x28, 0x9b, 0x80, 0x84,
+	0x62, 0x05, 0x59, 0x3d, 0x4a, 0x0a, 0xe8, 0xab, 0x41, 0x02, 0x16, 0xb4, 0xd1, 0x13, 0x90, 0x72,
+	0x9d, 0x07, 0x7e, 0x42, 0x3d, 0x15, 0x4e, 0x49, 0x9c, 0x07, 0x44, 0x74, 0xe1, 0x17, 0x21, 0x49,
+	0x9c, 0x07, 0xbc, 0x56, 0xec, 0x9a, 0x76, 0x8b, 0xde, 0x09, 0x8e, 0x72, 0x05, 0xa2, 0x41, 0x46,
+	0x64, 0x30, 0xab, 0x70, 0x44, 0x5f, 0x91, 0x54, 0x77, 0x15, 0x26, 0x5f, 0xeb, 0xe9, 0xe2, 0x9a,
+	0x8f, 0x89, 0x4b, 0x0c, 0x21, 0x3e, 0x12, 0xb7, 0x19, 0x08, 0xe1, 0xe8, 0x38, 0xe4, 0x98, 0xb9,
+	0xd5, 0xa6, 0xd7, 0x43, 0x67, 0x19, 0x02, 0x78, 0x2f, 0x3f, 0x85, 0xde, 0xd1, 0x52, 0xb1, 0x10,
+	0x80, 0x9e, 0x86, 0xd9, 0x70, 0xcd, 0x37, 0x5d, 0xba, 0x6d, 0x3d, 0x14, 0x1a, 0x2e, 0x90, 0x03,
+	0x70, 0xb4, 0x0c, 0x33, 0x21, 0x6c, 0x53, 0xa4, 0x3c, 0x29, 0x81, 0x1a, 0x07, 0x73, 0xd9, 0x08,
+	0x76, 0x2f, 0xdd, 0xeb, 0x99, 0x6d, 0xb1, 0x4d, 0x0b, 0x44, 0x83, 0xe0, 0xdf, 0x19, 0x70, 0x44,
+	0xaa, 0x9a, 0xef, 0x81, 0x2f, 0xa3, 0xd5, 0xff, 0xcc, 0x00, 0xa4, 0x73, 0xa0, 0x4c, 0xeb, 0xbf,
+	0xf4, 0x8a, 0x14, 0xcf, 0xa9, 0xf2, 0xe2, 0x70, 0x2d, 0x41, 0x61, 0x51, 0x09, 0x43, 0xa6, 0x21,
+	0x2b, 0x6f, 0xe2, 0xf2, 0x40, 0x9e, 0xde, 0x25, 0x84, 0xa8, 0x7f, 0x5e, 0x74, 0xd8, 0xea, 0x33,
+	0xea, 0xa9, 0xb3, 0xb7, 0x28, 0x3a, 0x08, 0x00, 0x91, 0x7f, 0x7c, 0x2e, 0x75, 0xc1, 0x56, 0x4c,
+	0x85, 0x73, 0x29, 0x10, 0xf1, 0x3f, 0xf0, 0x3f, 0x12, 0x30, 0x75, 0xc7, 0x69, 0xf7, 0x3a, 0xf4,
+	0x4b, 0x28, 0xe7, 0x68, 0x41, 0x20, 0xed, 0x17, 0x04, 0x10, 0xa4, 0x3c, 0x46, 0xbb, 0xc2, 0xb2,
+	0x92, 0x44, 0x7c, 0xf3, 0xbb, 0x29, 0x66, 0xba, 0x2d, 0xca, 0xe4, 0x31, 0xab, 0x98, 0x11, 0xf9,
+	0x6f, 0x04, 0xc6, 0xaf, 0x29, 0xcd, 0x56, 0xcb, 0xa5, 0x2d, 0x93, 0xd1, 0x7a, 0xbf, 0x38, 0x29,
+	0x26, 0xd3, 0x41, 0xe8, 0x2a, 0x4c, 0xf3, 0x9b, 0x65, 0xcb, 0x6e, 0xdd, 0xe8, 0xca, 0x9b, 0x92,
+	0xac, 0xf0, 0xe0, 0xc7, 0xab, 0xfa, 0xbd, 0x73, 0x75, 0x35, 0x82, 0xa3, 0xfc, 0x58, 0x6c, 0x24,
+	0x7e, 0x03, 0xa6, 0x7d, 0xc1, 0x2b, 0xf3, 0x38, 0x0b, 0x93, 0xf7, 0x05, 0x64, 0x48, 0xb1, 0x4f,
+	0xa2, 0x2a, 0x52, 0x3e, 0x5a, 0xf4, 0x52, 0xc3, 0xe7, 0x1f, 0x5f, 0x85, 0x8c, 0x44, 0xe7, 0x95,
+	0xa7, 0x30, 0x47, 0x92, 0xb9, 0x27, 0x6f, 0xab, 0x53, 0x14, 0x86, 0x8c, 0x24, 0x54, 0x4c, 0x86,
+	0x76, 0x26, 0x21, 0x44, 0xfd, 0xe3, 0xef, 0x25, 0x60, 0x61, 0x8d, 0x32, 0x71, 0xe1, 0x78, 0xd9,
+	0xa2, 0xed, 0xe6, 0xe7, 0x5a, 0x13, 0x08, 0x2a, 0x7b, 0x49, 0xad, 0xb2, 0xc7, 0x7d, 0x58, 0xdb,
+	0xb2, 0xe9, 0x86, 0x56, 0x1a, 0x0a, 0x01, 0xa1, 0x8c, 0xd2, 0x7a, 0xd1, 0xc8, 0xb7, 0x91, 0x8c,
+	0x66, 0x23, 0x61, 0x41, 0x70, 0x32, 0x52, 0xc3, 0xf4, 0x4f, 0xa0, 0xd9, 0xf0, 0xf8, 0x8a, 0x7f,
+	0x6d, 0xc0, 0x62, 0x5c, 0x2e, 0x4a, 0x8d, 0x97, 0x20, 0xb3, 0x2d, 0x20, 0x07, 0xcb, 0xce, 0x91,
+	0x11, 0xb2, 0x72, 0x21, 0x51, 0xf5, 0xca, 0x85, 0x84, 0xa0, 0xa7, 0x22, 0x17, 0x56, 0xf5, 0xb9,
+	0xfd, 0x41, 0x65, 0x46, 0x00, 0x34, 0x5c, 0xc5, 0xcc, 0x99, 0x60, 0xe1, 0xc9, 0xb0, 0x24, 0x22,
+	0x21, 0x3a, 0x61, 0x09, 0xc1, 0xff, 0xe4, 0x45, 0x03, 0x7d, 0x21, 0x42, 0x44, 0x7c, 0x0b, 0xa8,
+	0xf0, 0x20, 0x1b, 0xe8, 0x29, 0x48, 0xf1, 0x07, 0x14, 0xea, 0x3c, 0xb7, 0xf0, 0xc9, 0xa0, 0x72,
+	0x24, 0x32, 0xec, 0x56, 0xbf, 0x4b, 0x89, 0x40, 0xe1, 0x3b, 0xa7, 0x61, 0xba, 0x4d, 0xcb, 0x36,
+	0xdb, 0x16, 0xeb, 0xab, 0x3b, 0x7b, 0x1d, 0xc4, 0xdd, 0x51, 0xd7, 0x74, 0x3d, 0x3f, 0x09, 0xcc,
+	0x49, 0x77, 0xa4, 0x40, 0xc4, 0xff, 0xe0, 0x9c, 0x78, 0xbb, 0x94, 0x35, 0x76, 0x64, 0x58, 0x90,
+	0x9c, 0x48, 0x88, 0xce, 0x89, 0x84, 0xa0, 0x15, 0xc8, 0xde, 0xf5, 0x1c, 0xfb, 0xa6, 0xc9, 0x76,
+	0xe4, 0x86, 0xae, 0x2f, 0xee, 0x0f, 0x2a, 0xc8, 0x87, 0x69, 0x23, 0x02, 0x3c, 0xfc, 0x63, 0x23,
+	0x34, 0x68, 0xb9, 0xef, 0xbf, 0x70, 0x06, 0x8d, 0xbf, 0x02, 0x8b, 0xf1, 0x25, 0x2a, 0xdb, 0xe2,
+	0xb5, 0xbd, 0x48, 0xcf, 0x68, 0x1b, 0x13, 0xfd, 0x24, 0x86, 0x8e, 0x7b, 0xa1, 0xee, 0x05, 0x64,
+	0x84, 0xee, 0x63, 0x0a, 0x4d, 0x1c, 0x54, 0x68, 0xa8, 0xa9, 0xe4, 0xe1, 0x9a, 0x7a, 0xfa, 0x24,
+	0xe4, 0x82, 0x8b, 0x4d, 0x94, 0x87, 0xc9, 0xcb, 0x37, 0xc8, 0xeb, 0x17, 0xc9, 0xda, 0xec, 0x04,
+	0x2a, 0x40, 0xb6, 0x7e, 0x71, 0xf5, 0x55, 0xd1, 0x32, 0x56, 0x7e, 0x91, 0xf1, 0x93, 0x1d, 0x17,
+	0xfd, 0x2f, 0xa4, 0x65, 0x06, 0xb3, 0x18, 0x32, 0xa7, 0xdf, 0xf9, 0x95, 0x8e, 0x1e, 0x80, 0x4b,
+	0x29, 0xe1, 0x89, 0xb3, 0x06, 0xba, 0x0e, 0x79, 0x01, 0x54, 0x55, 0xf5, 0xe3, 0xf1, 0xe2, 0x76,
+	0x84, 0xd2, 0x89, 0x11, 0xbd, 0x1a, 0xbd, 0x0b, 0x90, 0x96, 0x02, 0x5b, 0x8c, 0x25, 0x9a, 0x43,
+	0x56, 0x13, 0xb9, 0x67, 0xc0, 0x13, 0xe8, 0x05, 0x48, 0xf1, 0x22, 0x13, 0xd2, 0xf2, 0x5c, 0xad,
+	0x18, 0x5e, 0x5a, 0x8c, 0x83, 0xb5, 0x69, 0x5f, 0x0a, 0x6a, 0xfa, 0x47, 0xe3, 0x85, 0x45, 0x7f,
+	0x78, 0xf1, 0x60, 0x47, 0x30, 0xf3, 0x0d, 0x28, 0xe8, 0xe5, 0x2d, 0x74, 0x22, 0x3a, 0x55, 0xac,
+	0x1a, 0x56, 0x2a, 0x8f, 0xea, 0x0e, 0x08, 0x6e, 0x40, 0x5e, 0x2b, 0x2d, 0xe9, 0x62, 0x3d, 0x58,
+	0x17, 0x2b, 0x9d, 0x18, 0xd1, 0x1b, 0x50, 0x5b, 0x87, 0xac, 0x78, 0xf4, 0xc1, 0xaf, 0xa0, 0x8e,
+	0xc5, 0x0f, 0x01, 0x5a, 0xf2, 0x57, 0x3a, 0x3e, 0xbc, 0x33, 0x20, 0xf4, 0xff, 0x90, 0x5b, 0xa7,
+	0x4c, 0x45, 0xbd, 0xa3, 0xf1, 0xb0, 0x39, 0x44, 0x52, 0xd1, 0xd0, 0x8b, 0x27, 0xd0, 0x1b, 0xe2,
+	0xa0, 0x12, 0x75, 0xe9, 0xa8, 0x32, 0xc2, 0x75, 0x07, 0xeb, 0x5a, 0x1a, 0x8d, 0x10, 0x50, 0x7e,
+	0x3d, 0x42, 0x59, 0xe5, 0x1a, 0x95, 0x11, 0x1b, 0x36, 0xa0, 0x5c, 0x39, 0xe4, 0x15, 0x1a, 0x9e,
+	0x58, 0x79, 0xd3, 0x7f, 0x9d, 0xb2, 0xc6, 0x1f, 0x61, 0xdd, 0x80, 0xe9, 0xe0, 0x01, 0x8d, 0x78,
+	0xa9, 0x15, 0xb1, 0xf9, 0x03, 0xcf, 0xc2, 0x4a, 0x27, 0x46, 0xf4, 0x06, 0xe4, 0xef, 0xc2, 0xbc,
+	0xbc, 0x2c, 0x94, 0xcf, 0x9b, 0x2e, 0xbb, 0x8e, 0xcd, 0xb8, 0xcf, 0x22, 0x30, 0x15, 0x79, 0xf7,
+	0x84, 0x34, 0xab, 0x19, 0xf6, 0xe8, 0xaa, 0x54, 0x19, 0xd9, 0x1f, 0xcc, 0xf5, 0x47, 0x03, 0x0a,
+	0xfa, 0x64, 0xe8, 0x36, 0x4c, 0x47, 0x9f, 0x03, 0xe9, 0x12, 0x1b, 0xfa, 0x6c, 0xa9, 0xb4, 0x34,
+	0x1a, 0x21, 0xd0, 0xc5, 0x5d, 0xf1, 0xe6, 0xe9, 0xe0, 0x03, 0x14, 0x74, 0xf2, 0xd0, 0xb7, 0x2e,
+	0x72, 0x92, 0x53, 0x9f, 0xf1, 0x4d, 0x0c, 0x9e, 0xa8, 0xbf, 0xf9, 0xde, 0x87, 0xe5, 0x89, 0xf7,
+	0x3f, 0x2c, 0x4f, 0x7c, 0xfc, 0x61, 0xd9, 0xf8, 0xc6, 0x5e, 0xd9, 0xf8, 0xf9, 0x5e, 0xd9, 0x78,
+	0x77, 0xaf, 0x6c, 0xbc, 0xb7, 0x57, 0x36, 0xfe, 0xb2, 0x57, 0x36, 0xfe, 0xba, 0x57, 0x9e, 0xf8,
+	0x78, 0xaf, 0x6c, 0xbc, 0xfd, 0x51, 0x79, 0xe2, 0xbd, 0x8f, 0xca, 0x13, 0xef, 0x7f, 0x54, 0x9e,
+	0xf8, 0xea, 0xa9, 0xc3, 0xcb, 0x1f, 0x32, 0xac, 0x64, 0xc4, 0xdf, 0xb3, 0xff, 0x1a, 0x00, 0xd4,
+	0xe8, 0x1d, 0x81, 0xa0, 0x29, 0x00, 0x00,
 }
 
 func (x Direction) String() string {
@@ -3938,6 +3954,9 @@ func (this *StreamMetadata) Equal(that interface{}) bool {
 	if this.StructuredMetadataSize != that1.StructuredMetadataSize {
 		return false
 	}
+	if this.Policy != that1.Policy {
+		return false
+	}
 	return true
 }
 func (this *ExceedsLimitsRequest) Equal(that interface{}) bool {
@@ -4069,6 +4088,9 @@ func (this *GetStreamUsageRequest) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.Policy != that1.Policy {
+		return false
+	}
 	return true
 }
 func (this *GetStreamUsageResponse) Equal(that interface{}) bool {
@@ -5923,11 +5945,12 @@ func (this *StreamMetadata) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&logproto.StreamMetadata{")
 	s = append(s, "StreamHash: "+fmt.Sprintf("%#v", this.StreamHash)+",\n")
 	s = append(s, "EntriesSize: "+fmt.Sprintf("%#v", this.EntriesSize)+",\n")
 	s = append(s, "StructuredMetadataSize: "+fmt.Sprintf("%#v", this.StructuredMetadataSize)+",\n")
+	s = append(s, "Policy: "+fmt.Sprintf("%#v", this.Policy)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -5972,11 +5995,12 @@ func (this *GetStreamUsageRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&logproto.GetStreamUsageRequest{")
 	s = append(s, "Tenant: "+fmt.Sprintf("%#v", this.Tenant)+",\n")
 	s = append(s, "Partitions: "+fmt.Sprintf("%#v", this.Partitions)+",\n")
 	s = append(s, "StreamHashes: "+fmt.Sprintf("%#v", this.StreamHashes)+",\n")
+	s = append(s, "Policy: "+fmt.Sprintf("%#v", this.Policy)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -7641,6 +7665,13 @@ func (m *StreamMetadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Policy) > 0 {
+		i -= len(m.Policy)
+		copy(dAtA[i:], m.Policy)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.Policy)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if m.StructuredMetadataSize != 0 {
 		i = encodeVarintLogproto(dAtA, i, uint64(m.StructuredMetadataSize))
 		i--
@@ -7802,6 +7833,13 @@ func (m *GetStreamUsageRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Policy) > 0 {
+		i -= len(m.Policy)
+		copy(dAtA[i:], m.Policy)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.Policy)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if len(m.StreamHashes) > 0 {
 		dAtA3 := make([]byte, len(m.StreamHashes)*10)
 		var j2 int
@@ -10436,6 +10474,10 @@ func (m *StreamMetadata) Size() (n int) {
 	if m.StructuredMetadataSize != 0 {
 		n += 1 + sovLogproto(uint64(m.StructuredMetadataSize))
 	}
+	l = len(m.Policy)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -10517,6 +10559,10 @@ func (m *GetStreamUsageRequest) Size() (n int) {
 		}
 		n += 1 + sovLogproto(uint64(l)) + l
 	}
+	l = len(m.Policy)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -11686,6 +11732,7 @@ func (this *StreamMetadata) String() string {
 		`StreamHash:` + fmt.Sprintf("%v", this.StreamHash) + `,`,
 		`EntriesSize:` + fmt.Sprintf("%v", this.EntriesSize) + `,`,
 		`StructuredMetadataSize:` + fmt.Sprintf("%v", this.StructuredMetadataSize) + `,`,
+		`Policy:` + fmt.Sprintf("%v", this.Policy) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -11741,6 +11788,7 @@ func (this *GetStreamUsageRequest) String() string {
 		`Tenant:` + fmt.Sprintf("%v", this.Tenant) + `,`,
 		`Partitions:` + fmt.Sprintf("%v", this.Partitions) + `,`,
 		`StreamHashes:` + fmt.Sprintf("%v", this.StreamHashes) + `,`,
+		`Policy:` + fmt.Sprintf("%v", this.Policy) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -12971,6 +13019,38 @@ func (m *StreamMetadata) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Policy", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Policy = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -13550,6 +13630,38 @@ func (m *GetStreamUsageRequest) Unmarshal(dAtA []byte) error {
 			} else {
 				return fmt.Errorf("proto: wrong wireType = %d for field StreamHashes", wireType)
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Policy", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Policy = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
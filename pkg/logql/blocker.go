@@ -33,7 +33,6 @@ func (qb *queryBlocker) isBlocked(ctx context.Context, tenant string) bool {
 		return false
 	}
 
-	query := qb.q.params.QueryString()
 	typ, err := QueryType(qb.q.params.GetExpression())
 	if err != nil {
 		typ = "unknown"
@@ -41,15 +40,21 @@ func (qb *queryBlocker) isBlocked(ctx context.Context, tenant string) bool {
 
 	logger := log.With(qb.logger, "user", tenant, "type", typ)
 
+	return MatchBlockedQuery(blocks, qb.q.params.QueryString(), typ, logger) != nil
+}
+
+// MatchBlockedQuery returns the policy of blocks which blocks a query of the given type, or
+// nil if the query is not blocked.
+func MatchBlockedQuery(blocks []*validation.BlockedQuery, query, typ string, logger log.Logger) *validation.BlockedQuery {
 	for _, b := range blocks {
 
 		if b.Hash > 0 {
 			if b.Hash == util.HashedQuery(query) {
 				level.Warn(logger).Log("msg", "query blocker matched with hash policy", "hash", b.Hash, "query", query)
-				return qb.block(b, typ, logger)
+				return matchBlockedQueryType(b, typ, logger)
 			}
 
-			return false
+			return nil
 		}
 
 		// if no pattern is given, assume we want to match all queries
@@ -60,7 +65,7 @@ func (qb *queryBlocker) isBlocked(ctx context.Context, tenant string) bool {
 
 		if strings.TrimSpace(b.Pattern) == strings.TrimSpace(query) {
 			level.Warn(logger).Log("msg", "query blocker matched with exact match policy", "query", query)
-			return qb.block(b, typ, logger)
+			return matchBlockedQueryType(b, typ, logger)
 		}
 
 		if b.Regex {
@@ -72,18 +77,18 @@ func (qb *queryBlocker) isBlocked(ctx context.Context, tenant string) bool {
 
 			if r.MatchString(query) {
 				level.Warn(logger).Log("msg", "query blocker matched with regex policy", "pattern", b.Pattern, "query", query)
-				return qb.block(b, typ, logger)
+				return matchBlockedQueryType(b, typ, logger)
 			}
 		}
 	}
 
-	return false
+	return nil
 }
 
-func (qb *queryBlocker) block(q *validation.BlockedQuery, typ string, logger log.Logger) bool {
+func matchBlockedQueryType(q *validation.BlockedQuery, typ string, logger log.Logger) *validation.BlockedQuery {
 	// no specific types to validate against, so query is blocked
 	if len(q.Types) == 0 {
-		return true
+		return q
 	}
 
 	matched := false
@@ -97,8 +102,8 @@ func (qb *queryBlocker) block(q *validation.BlockedQuery, typ string, logger log
 	// query would be blocked, but it didn't match specified types
 	if !matched {
 		level.Debug(logger).Log("msg", "query blocker matched pattern, but not specified types", "pattern", q.Pattern, "regex", q.Regex, "hash", q.Hash, "types", q.Types.String(), "queryType", typ)
-		return false
+		return nil
 	}
 
-	return true
+	return q
 }
@@ -82,6 +82,8 @@ func TestMappingEquivalence(t *testing.T) {
 		{`last_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, false, []string{ShardLastOverTime}},
 		{`last_over_time({a=~".+"} | logfmt | unwrap value [1s] offset 2s) by (a)`, false, []string{ShardLastOverTime}},
 		{`last_over_time({a=~".+"} | logfmt | unwrap value [1s] offset -2s) by (a)`, false, []string{ShardLastOverTime}},
+		{`max_over_time(rate({a=~".+"}[1s])[5s:2s])`, false, nil},
+		{`sum(avg_over_time(sum by (a) (rate({a=~".+"}[1s]))[5s:1s] offset 2s))`, false, nil},
 		// topk prefers already-seen values in tiebreakers. Since the test data generates
 		// the same log lines for each series & the resulting promql.Vectors aren't deterministically
 		// sorted by labels, we don't expect this to pass.
@@ -151,6 +151,9 @@ type EngineOpts struct {
 	// MaxCountMinSketchHeapSize is the maximum number of labels the heap for a topk query using a count min sketch
 	// can track. This impacts the memory usage and accuracy of a sharded probabilistic topk query.
 	MaxCountMinSketchHeapSize int `yaml:"max_count_min_sketch_heap_size"`
+
+	// MaxInValues is the maximum number of values the query of an in() stage can return.
+	MaxInValues int `yaml:"max_in_values"`
 }
 
 func (opts *EngineOpts) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
@@ -166,6 +169,12 @@ func (opts *EngineOpts) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet)
 		10_000,
 		"The maximum number of labels the heap of a topk query using a count min sketch can track.",
 	)
+	f.IntVar(
+		&opts.MaxInValues,
+		prefix+".engine.max-in-values",
+		1000,
+		"The maximum number of values the query of an in() stage can return.",
+	)
 	// Log executing query by default
 	opts.LogExecutingQuery = true
 }
@@ -174,6 +183,9 @@ func (opts *EngineOpts) applyDefault() {
 	if opts.MaxLookBackPeriod == 0 {
 		opts.MaxLookBackPeriod = 30 * time.Second
 	}
+	if opts.MaxInValues == 0 {
+		opts.MaxInValues = 1000
+	}
 }
 
 // Engine is the LogQL engine.
@@ -207,6 +219,7 @@ func (ng *Engine) Query(params Params) Query {
 		record:       true,
 		logExecQuery: ng.opts.LogExecutingQuery,
 		limits:       ng.limits,
+		maxInValues:  ng.opts.MaxInValues,
 	}
 }
 
@@ -223,6 +236,10 @@ type query struct {
 	evaluator    EvaluatorFactory
 	record       bool
 	logExecQuery bool
+	// maxInValues is the maximum number of values of an in() stage. The
+	// in() stages are left as they are if it is zero, e.g. when the query is
+	// evaluated downstream.
+	maxInValues int
 }
 
 func (q *query) resultLength(res promql_parser.Value) int {
@@ -315,6 +332,17 @@ func (q *query) Eval(ctx context.Context) (promql_parser.Value, error) {
 		return nil, logqlmodel.ErrBlocked
 	}
 
+	if q.maxInValues > 0 {
+		expr := q.params.GetExpression()
+		resolved, err := ResolveInExprs(ctx, expr, q.params.Start(), q.params.End(), q.maxInValues, q.evalInQuery)
+		if err != nil {
+			return nil, err
+		}
+		if resolved != expr {
+			q.params = ParamsWithExpressionOverride{Params: q.params, ExpressionOverride: resolved}
+		}
+	}
+
 	switch e := q.params.GetExpression().(type) {
 	// A VariantsExpr is a specific type of SampleExpr, so make sure this case is evaulated first
 	case syntax.VariantsExpr:
@@ -356,6 +384,34 @@ func (q *query) Eval(ctx context.Context) (promql_parser.Value, error) {
 	}
 }
 
+// evalInQuery evaluates the query of an in() stage as an instant query.
+func (q *query) evalInQuery(ctx context.Context, expr syntax.SampleExpr, ts time.Time) ([]labels.Labels, error) {
+	params, err := NewLiteralParams(expr.String(), ts, ts, 0, 0, q.params.Direction(), 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	inner := &query{
+		logger:      q.logger,
+		params:      params,
+		limits:      q.limits,
+		evaluator:   q.evaluator,
+		maxInValues: q.maxInValues,
+	}
+	value, err := inner.Eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vec, ok := value.(promql.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type (%s) of an in() query: expected a vector", value.Type())
+	}
+	series := make([]labels.Labels, 0, len(vec))
+	for _, s := range vec {
+		series = append(series, s.Metric)
+	}
+	return series, nil
+}
+
 func (q *query) checkBlocked(ctx context.Context, tenants []string) bool {
 	blocker := newQueryBlocker(ctx, q)
 
@@ -992,6 +992,74 @@ func TestEngine_InstantQuery(t *testing.T) {
 				{T: 60 * 1000, F: 0, Metric: labels.FromStrings("app", "foo", "machine", "fuzz", "pool", "foo")},
 			},
 		},
+		{
+			// the points of the subquery are evaluated at 10s, 20s and 30s, the point at 0s is out of the range.
+			`avg_over_time(count_over_time({app="foo"}[10s])[30s:10s] offset 30s)`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, identity, `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(-10, 0), End: time.Unix(30, 0), Selector: `count_over_time({app="foo"}[10s])`}},
+			},
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 10, Metric: labels.FromStrings("app", "foo")}},
+		},
+		{
+			// 30 lines fall in the first bucket and 60 in the second one.
+			`histogram_quantile(0.75, sum by (le) (count_over_time({app="foo"}[1m])))`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, factor(2, identity), `{app="foo", le="1"}`),
+					newSeries(testSize, identity, `{app="foo", le="2"}`),
+					newSeries(testSize, identity, `{app="foo", le="+Inf"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `sum by (le) (count_over_time({app="foo"}[1m]))`}},
+			},
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 1.5, Metric: labels.EmptyLabels()}},
+		},
+		{
+			`clamp(count_over_time({app="foo"}[1m]), 40, 50)`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, factor(2, identity), `{app="foo"}`),
+					newSeries(testSize, identity, `{app="bar"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `count_over_time({app="foo"}[1m])`}},
+			},
+			promql.Vector{
+				promql.Sample{T: 60 * 1000, F: 50, Metric: labels.FromStrings("app", "bar")},
+				promql.Sample{T: 60 * 1000, F: 40, Metric: labels.FromStrings("app", "foo")},
+			},
+		},
+		{
+			// the query of the in() stage is evaluated first and replaced by a label filter.
+			`sum(count_over_time({app="foo"} | logfmt | trace_id in ({app="bar"} | logfmt) [1m]))`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, identity, `{app="bar", trace_id="a"}`),
+					newSeries(testSize, identity, `{app="bar", trace_id="b.c"}`),
+				},
+				{newSeries(testSize, identity, `{app="foo", trace_id="a"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `sum by (trace_id) (count_over_time({app="bar"} | logfmt [1m]))`}},
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `sum(count_over_time({app="foo"} | logfmt | trace_id=~"a|b\\.c" [1m]))`}},
+			},
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 60, Metric: labels.EmptyLabels()}},
+		},
+		{
+			`timestamp(count_over_time({app="foo"}[1m]))`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, identity, `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `count_over_time({app="foo"}[1m])`}},
+			},
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 60, Metric: labels.FromStrings("app", "foo")}},
+		},
 	} {
 		t.Run(fmt.Sprintf("%s %s", test.qs, test.direction), func(t *testing.T) {
 			eng := NewEngine(EngineOpts{}, newQuerierRecorder(t, test.data, test.params), NoLimits, log.NewNopLogger())
@@ -2293,6 +2361,80 @@ func TestEngine_RangeQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			// one line per second until 30s, then one line every two seconds.
+			`max_over_time(count_over_time({app="foo"}[10s])[30s:10s])`, time.Unix(30, 0), time.Unix(60, 0), 30 * time.Second, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					newSeries(30, identity, `{app="foo"}`),
+					newSeries(15, offset(15, factor(2, identity)), `{app="foo"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(-10, 0), End: time.Unix(60, 0), Selector: `count_over_time({app="foo"}[10s])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 30 * 1000, F: 10}, {T: 60 * 1000, F: 5}},
+				},
+			},
+		},
+		{
+			`sum_over_time((sum(count_over_time({app="foo"}[10s])) > bool 5)[30s:10s])`, time.Unix(30, 0), time.Unix(60, 0), 30 * time.Second, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					newSeries(30, identity, `{app="foo"}`),
+					newSeries(15, offset(15, factor(2, identity)), `{app="foo"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(-10, 0), End: time.Unix(60, 0), Selector: `sum(count_over_time({app="foo"}[10s]))`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.EmptyLabels(),
+					Floats: []promql.FPoint{{T: 30 * 1000, F: 3}, {T: 60 * 1000, F: 0}},
+				},
+			},
+		},
+		{
+			`deriv({app="foo"} | unwrap foo [30s])`, time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, incValue(10), `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(120, 0), Selector: `deriv({app="foo"} | unwrap foo [30s])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 1}, {T: 90 * 1000, F: 1}, {T: 120 * 1000, F: 1}},
+				},
+			},
+		},
+		{
+			`changes({app="foo"} | unwrap foo [30s])`, time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, incValue(10), `{app="foo"}`),
+					newSeries(testSize, constantValue(2), `{app="bar"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(120, 0), Selector: `changes({app="foo"} | unwrap foo [30s])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "bar"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 0}, {T: 90 * 1000, F: 0}, {T: 120 * 1000, F: 0}},
+				},
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 29}, {T: 90 * 1000, F: 29}, {T: 120 * 1000, F: 29}},
+				},
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf("%s %s", test.qs, test.direction), func(t *testing.T) {
 			t.Parallel()
@@ -378,10 +378,14 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 			return nil, err
 		}
 		return newRangeAggEvaluator(iter.NewPeekingSampleIterator(it), e, q, e.Left.Offset)
+	case *syntax.SubqueryAggregationExpr:
+		return newSubqueryAggEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.BinOpExpr:
 		return newBinOpStepEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.LabelReplaceExpr:
 		return newLabelReplaceEvaluator(ctx, nextEvFactory, e, q)
+	case *syntax.FunctionExpr:
+		return newFunctionEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.VectorExpr:
 		val, err := e.Value()
 		if err != nil {
@@ -708,6 +712,84 @@ func newRangeAggEvaluator(
 	}
 }
 
+// subqueryParams overrides the time range and the step of the params with the
+// ones the inner expression of a subquery is evaluated with.
+type subqueryParams struct {
+	Params
+	start, end time.Time
+	step       time.Duration
+}
+
+func (p subqueryParams) Start() time.Time    { return p.start }
+func (p subqueryParams) End() time.Time      { return p.end }
+func (p subqueryParams) Step() time.Duration { return p.step }
+
+func newSubqueryAggEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.SubqueryAggregationExpr,
+	q Params,
+) (StepEvaluator, error) {
+	sub := expr.Left
+
+	// The steps of the subquery are aligned to multiples of its step, so that
+	// the same points are evaluated regardless of the start of the query.
+	start := q.Start().Add(-sub.Range).Add(-sub.Offset).UnixNano()
+	step := sub.Step.Nanoseconds()
+	alignedStart := start - start%step
+	if alignedStart < start {
+		alignedStart += step
+	}
+
+	nextEvaluator, err := evFactory.NewStepEvaluator(ctx, evFactory, sub.Left, subqueryParams{
+		Params: q,
+		start:  time.Unix(0, alignedStart),
+		end:    q.End().Add(-sub.Offset),
+		step:   sub.Step,
+	})
+	if err != nil {
+		return nil, err
+	}
+	it, err := newSubquerySampleIterator(nextEvaluator)
+	if err != nil {
+		return nil, err
+	}
+
+	// The points of the subquery are aggregated as unwrapped values.
+	rangeExpr := &syntax.RangeAggregationExpr{
+		Left: &syntax.LogRangeExpr{
+			Interval: sub.Range,
+			Offset:   sub.Offset,
+			Unwrap:   &syntax.UnwrapExpr{},
+		},
+		Operation: expr.Operation,
+		Params:    expr.Params,
+	}
+	rangeIter, err := newRangeVectorIterator(
+		it, rangeExpr,
+		sub.Range.Nanoseconds(),
+		q.Step().Nanoseconds(),
+		q.Start().UnixNano(), q.End().UnixNano(), sub.Offset.Nanoseconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Operation == syntax.OpRangeTypeAbsent {
+		absentLabels, err := absentLabels(expr)
+		if err != nil {
+			return nil, err
+		}
+		return &AbsentRangeVectorEvaluator{
+			iter: rangeIter,
+			lbs:  absentLabels,
+		}, nil
+	}
+	return &RangeVectorEvaluator{
+		iter: rangeIter,
+	}, nil
+}
+
 type RangeVectorEvaluator struct {
 	iter RangeVectorIterator
 
@@ -1312,6 +1394,114 @@ func (e *LabelReplaceEvaluator) Error() error {
 	return e.nextEvaluator.Error()
 }
 
+// newFunctionEvaluator
+func newFunctionEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.FunctionExpr,
+	q Params,
+) (*FunctionEvaluator, error) {
+	nextEvaluator, err := evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FunctionEvaluator{
+		nextEvaluator: nextEvaluator,
+		expr:          expr,
+		buf:           make([]byte, 0, 1024),
+	}, nil
+}
+
+// FunctionEvaluator applies a function to the vector of each step of the inner evaluator.
+type FunctionEvaluator struct {
+	nextEvaluator StepEvaluator
+	expr          *syntax.FunctionExpr
+	buf           []byte
+}
+
+func (e *FunctionEvaluator) Next() (bool, int64, StepResult) {
+	next, ts, r := e.nextEvaluator.Next()
+	if !next {
+		return false, 0, SampleVector{}
+	}
+	vec := r.SampleVector()
+	switch e.expr.Function {
+	case syntax.OpFunctionHistogramQuantile:
+		vec = e.histogramQuantile(ts, vec)
+	case syntax.OpFunctionClamp:
+		vec = clamp(vec, e.expr.Params[0], e.expr.Params[1])
+	case syntax.OpFunctionClampMin:
+		vec = clamp(vec, e.expr.Params[0], math.Inf(+1))
+	case syntax.OpFunctionClampMax:
+		vec = clamp(vec, math.Inf(-1), e.expr.Params[0])
+	case syntax.OpFunctionTimestamp:
+		for i := range vec {
+			vec[i].F = float64(ts) / 1e3
+		}
+	}
+	return next, ts, SampleVector(vec)
+}
+
+// histogramQuantile calculates the quantile of the buckets of each histogram
+// of the vector, like the PromQL function histogram_quantile. The series of a
+// histogram are the series with the same labels except the `le` label, which
+// is the upper bound of the bucket. Series without a valid `le` label are
+// ignored.
+func (e *FunctionEvaluator) histogramQuantile(ts int64, vec promql.Vector) promql.Vector {
+	type histogram struct {
+		metric  labels.Labels
+		buckets promql.Buckets
+	}
+	var (
+		histograms []*histogram
+		byHash     = map[uint64]*histogram{}
+		hash       uint64
+	)
+	for _, s := range vec {
+		upperBound, err := strconv.ParseFloat(s.Metric.Get(labels.BucketLabel), 64)
+		if err != nil {
+			continue
+		}
+		hash, e.buf = s.Metric.HashWithoutLabels(e.buf, labels.BucketLabel)
+		h, ok := byHash[hash]
+		if !ok {
+			h = &histogram{metric: labels.NewBuilder(s.Metric).Del(labels.BucketLabel).Labels()}
+			byHash[hash] = h
+			histograms = append(histograms, h)
+		}
+		h.buckets = append(h.buckets, promql.Bucket{UpperBound: upperBound, Count: s.F})
+	}
+
+	result := vec[:0]
+	for _, h := range histograms {
+		q, _, _ := promql.BucketQuantile(e.expr.Params[0], h.buckets)
+		result = append(result, promql.Sample{T: ts, F: q, Metric: h.metric})
+	}
+	return result
+}
+
+// clamp clamps the values of the vector to have a lower limit of lower and an
+// upper limit of upper. Like in PromQL, it returns an empty vector if lower is
+// greater than upper.
+func clamp(vec promql.Vector, lower, upper float64) promql.Vector {
+	if upper < lower {
+		return vec[:0]
+	}
+	for i := range vec {
+		vec[i].F = math.Max(lower, math.Min(upper, vec[i].F))
+	}
+	return vec
+}
+
+func (e *FunctionEvaluator) Close() error {
+	return e.nextEvaluator.Close()
+}
+
+func (e *FunctionEvaluator) Error() error {
+	return e.nextEvaluator.Error()
+}
+
 // This is to replace missing timeseries during absent_over_time aggregation.
 func absentLabels(expr syntax.SampleExpr) (labels.Labels, error) {
 	m := labels.Labels{}
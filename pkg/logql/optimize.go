@@ -2,6 +2,14 @@ package logql
 
 import "github.com/grafana/loki/v3/pkg/logql/syntax"
 
+// Optimize returns the expression the engine evaluates in place of expr, which produces the same result.
+func Optimize(expr syntax.Expr) (syntax.Expr, error) {
+	if e, ok := expr.(syntax.SampleExpr); ok {
+		return optimizeSampleExpr(e)
+	}
+	return expr, nil
+}
+
 // optimizeSampleExpr Attempt to optimize the SampleExpr to another that will run faster but will produce the same result.
 func optimizeSampleExpr(expr syntax.SampleExpr) (syntax.SampleExpr, error) {
 	var skip bool
@@ -12,14 +12,15 @@ import (
 	promql_parser "github.com/prometheus/prometheus/promql/parser"
 
 	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logql/vector"
 )
 
 // BatchRangeVectorAggregator aggregates samples for a given range of samples.
-// It receives the current milliseconds timestamp and the list of point within
-// the range.
-type BatchRangeVectorAggregator func([]promql.FPoint) float64
+// It receives the nanoseconds timestamp of the end of the range and the list
+// of point within the range.
+type BatchRangeVectorAggregator func(int64, []promql.FPoint) float64
 
 // RangeStreamingAgg streaming aggregates sample for each sample
 type RangeStreamingAgg interface {
@@ -55,7 +56,9 @@ func newRangeVectorIterator(
 	if selRange >= step && start != end {
 		overlap = true
 	}
-	if !overlap {
+	// predict_linear extrapolates from the end of the range, which is only
+	// passed to the batch aggregators.
+	if !overlap && expr.Operation != syntax.OpRangeTypePredictLinear {
 		_, err := streamingAggregator(expr)
 		if err != nil {
 			return nil, err
@@ -88,6 +91,40 @@ func newRangeVectorIterator(
 	}, nil
 }
 
+// newSubquerySampleIterator evaluates all the steps of the inner expression of
+// a subquery and returns an iterator over the resulting series.
+func newSubquerySampleIterator(ev StepEvaluator) (iter.PeekingSampleIterator, error) {
+	defer ev.Close()
+
+	series := map[string]*logproto.Series{}
+	for next, ts, r := ev.Next(); next; next, ts, r = ev.Next() {
+		for _, s := range r.SampleVector() {
+			lbs := s.Metric.String()
+			ss, ok := series[lbs]
+			if !ok {
+				ss = &logproto.Series{
+					Labels:     lbs,
+					StreamHash: s.Metric.Hash(),
+				}
+				series[lbs] = ss
+			}
+			ss.Samples = append(ss.Samples, logproto.Sample{
+				Timestamp: ts * int64(time.Millisecond),
+				Value:     s.F,
+			})
+		}
+	}
+	if err := ev.Error(); err != nil {
+		return nil, err
+	}
+
+	xs := make([]logproto.Series, 0, len(series))
+	for _, s := range series {
+		xs = append(xs, *s)
+	}
+	return iter.NewPeekingSampleIterator(iter.NewMultiSeriesIterator(xs)), nil
+}
+
 //batch
 
 type batchRangeVectorIterator struct {
@@ -195,7 +232,7 @@ func (r *batchRangeVectorIterator) At() (int64, StepResult) {
 	ts := r.current/1e+6 + r.offset/1e+6
 	for _, series := range r.window {
 		r.at = append(r.at, promql.Sample{
-			F:      r.agg(series.Floats),
+			F:      r.agg(r.current, series.Floats),
 			T:      ts,
 			Metric: series.Metric,
 		})
@@ -250,6 +287,14 @@ func aggregator(r *syntax.RangeAggregationExpr) (BatchRangeVectorAggregator, err
 		return last, nil
 	case syntax.OpRangeTypeAbsent:
 		return one, nil
+	case syntax.OpRangeTypeDeriv:
+		return deriv, nil
+	case syntax.OpRangeTypePredictLinear:
+		return predictLinear(*r.Params), nil
+	case syntax.OpRangeTypeChanges:
+		return changes, nil
+	case syntax.OpRangeTypeResets:
+		return resets, nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
@@ -257,8 +302,8 @@ func aggregator(r *syntax.RangeAggregationExpr) (BatchRangeVectorAggregator, err
 
 // rateLogs calculates the per-second rate of log lines or values extracted
 // from log lines
-func rateLogs(selRange time.Duration, computeValues bool) func(samples []promql.FPoint) float64 {
-	return func(samples []promql.FPoint) float64 {
+func rateLogs(selRange time.Duration, computeValues bool) BatchRangeVectorAggregator {
+	return func(_ int64, samples []promql.FPoint) float64 {
 		if !computeValues {
 			return float64(len(samples)) / selRange.Seconds()
 		}
@@ -272,8 +317,8 @@ func rateLogs(selRange time.Duration, computeValues bool) func(samples []promql.
 
 // rateCounter calculates the per-second rate of values extracted from log lines
 // and treat them like a "counter" metric.
-func rateCounter(selRange time.Duration) func(samples []promql.FPoint) float64 {
-	return func(samples []promql.FPoint) float64 {
+func rateCounter(selRange time.Duration) BatchRangeVectorAggregator {
+	return func(_ int64, samples []promql.FPoint) float64 {
 		return extrapolatedRate(samples, selRange, true, true)
 	}
 }
@@ -357,18 +402,18 @@ func durationMilliseconds(d time.Duration) int64 {
 }
 
 // rateLogBytes calculates the per-second rate of log bytes.
-func rateLogBytes(selRange time.Duration) func(samples []promql.FPoint) float64 {
-	return func(samples []promql.FPoint) float64 {
-		return sumOverTime(samples) / selRange.Seconds()
+func rateLogBytes(selRange time.Duration) BatchRangeVectorAggregator {
+	return func(ts int64, samples []promql.FPoint) float64 {
+		return sumOverTime(ts, samples) / selRange.Seconds()
 	}
 }
 
 // countOverTime counts the amount of log lines.
-func countOverTime(samples []promql.FPoint) float64 {
+func countOverTime(_ int64, samples []promql.FPoint) float64 {
 	return float64(len(samples))
 }
 
-func sumOverTime(samples []promql.FPoint) float64 {
+func sumOverTime(_ int64, samples []promql.FPoint) float64 {
 	var sum float64
 	for _, v := range samples {
 		sum += v.F
@@ -376,7 +421,7 @@ func sumOverTime(samples []promql.FPoint) float64 {
 	return sum
 }
 
-func avgOverTime(samples []promql.FPoint) float64 {
+func avgOverTime(_ int64, samples []promql.FPoint) float64 {
 	var mean, count float64
 	for _, v := range samples {
 		count++
@@ -402,7 +447,7 @@ func avgOverTime(samples []promql.FPoint) float64 {
 	return mean
 }
 
-func maxOverTime(samples []promql.FPoint) float64 {
+func maxOverTime(_ int64, samples []promql.FPoint) float64 {
 	maxVal := samples[0].F
 	for _, v := range samples {
 		if v.F > maxVal || math.IsNaN(maxVal) {
@@ -412,7 +457,7 @@ func maxOverTime(samples []promql.FPoint) float64 {
 	return maxVal
 }
 
-func minOverTime(samples []promql.FPoint) float64 {
+func minOverTime(_ int64, samples []promql.FPoint) float64 {
 	minVal := samples[0].F
 	for _, v := range samples {
 		if v.F < minVal || math.IsNaN(minVal) {
@@ -424,7 +469,7 @@ func minOverTime(samples []promql.FPoint) float64 {
 
 // stdvarOverTime calculates the variance using Welford's online algorithm.
 // See https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance#Welford's_online_algorithm
-func stdvarOverTime(samples []promql.FPoint) float64 {
+func stdvarOverTime(_ int64, samples []promql.FPoint) float64 {
 	var aux, count, mean float64
 	for _, v := range samples {
 		count++
@@ -435,7 +480,7 @@ func stdvarOverTime(samples []promql.FPoint) float64 {
 	return aux / count
 }
 
-func stddevOverTime(samples []promql.FPoint) float64 {
+func stddevOverTime(_ int64, samples []promql.FPoint) float64 {
 	var aux, count, mean float64
 	for _, v := range samples {
 		count++
@@ -446,8 +491,8 @@ func stddevOverTime(samples []promql.FPoint) float64 {
 	return math.Sqrt(aux / count)
 }
 
-func quantileOverTime(q float64) func(samples []promql.FPoint) float64 {
-	return func(samples []promql.FPoint) float64 {
+func quantileOverTime(q float64) BatchRangeVectorAggregator {
+	return func(_ int64, samples []promql.FPoint) float64 {
 		values := make(vector.HeapByMaxValue, 0, len(samples))
 		for _, v := range samples {
 			values = append(values, promql.Sample{F: v.F})
@@ -486,24 +531,111 @@ func Quantile(q float64, values vector.HeapByMaxValue) float64 {
 	return values[int(lowerIndex)].F*(1-weight) + values[int(upperIndex)].F*weight
 }
 
-func first(samples []promql.FPoint) float64 {
+func first(_ int64, samples []promql.FPoint) float64 {
 	if len(samples) == 0 {
 		return math.NaN()
 	}
 	return samples[0].F
 }
 
-func last(samples []promql.FPoint) float64 {
+func last(_ int64, samples []promql.FPoint) float64 {
 	if len(samples) == 0 {
 		return math.NaN()
 	}
 	return samples[len(samples)-1].F
 }
 
-func one(_ []promql.FPoint) float64 {
+func one(_ int64, _ []promql.FPoint) float64 {
 	return 1.0
 }
 
+// deriv calculates the per-second derivative of the values of a series using
+// a simple linear regression.
+func deriv(_ int64, samples []promql.FPoint) float64 {
+	if len(samples) == 0 {
+		return math.NaN()
+	}
+	// The intercept is computed at the first sample to avoid floating point
+	// accuracy issues with large timestamps.
+	slope, _ := linearRegression(samples, samples[0].T)
+	return slope
+}
+
+// predictLinear predicts the value of a series t seconds after the end of the
+// range using a simple linear regression.
+func predictLinear(t float64) BatchRangeVectorAggregator {
+	return func(ts int64, samples []promql.FPoint) float64 {
+		if len(samples) == 0 {
+			return math.NaN()
+		}
+		slope, intercept := linearRegression(samples, ts)
+		return slope*t + intercept
+	}
+}
+
+// changes counts the number of times the value of a series changed.
+func changes(_ int64, samples []promql.FPoint) float64 {
+	var changes float64
+	for i := 1; i < len(samples); i++ {
+		if valueChanged(samples[i-1].F, samples[i].F) {
+			changes++
+		}
+	}
+	return changes
+}
+
+// resets counts the number of times the value of a series decreased.
+func resets(_ int64, samples []promql.FPoint) float64 {
+	var resets float64
+	for i := 1; i < len(samples); i++ {
+		if samples[i].F < samples[i-1].F {
+			resets++
+		}
+	}
+	return resets
+}
+
+func valueChanged(prev, current float64) bool {
+	return current != prev && !(math.IsNaN(current) && math.IsNaN(prev))
+}
+
+// linearRegression function is taken from prometheus code promql/functions.go
+// It returns the slope per second and the intercept at interceptTime of the
+// least squares regression line of the samples.
+func linearRegression(samples []promql.FPoint, interceptTime int64) (slope, intercept float64) {
+	var (
+		n            float64
+		sumX, sumY   float64
+		sumXY, sumX2 float64
+		initY        = samples[0].F
+		constY       = true
+	)
+	for i, sample := range samples {
+		// Set constY to false if any new y values are encountered.
+		if constY && i > 0 && sample.F != initY {
+			constY = false
+		}
+		n += 1.0
+		x := float64(sample.T-interceptTime) / 1e9
+		sumX += x
+		sumY += sample.F
+		sumXY += x * sample.F
+		sumX2 += x * x
+	}
+	if constY {
+		if math.IsInf(initY, 0) {
+			return math.NaN(), math.NaN()
+		}
+		return 0, initY
+	}
+	covXY := sumXY - sumX*sumY/n
+	varX := sumX2 - sumX*sumX/n
+
+	slope = covXY / varX
+	intercept = sumY/n - slope*sumX/n
+	return slope, intercept
+}
+
 // streaming range agg
 type streamRangeVectorIterator struct {
 	iter                                 iter.PeekingSampleIterator
@@ -627,6 +759,12 @@ func streamingAggregator(r *syntax.RangeAggregationExpr) (RangeStreamingAgg, err
 		return &LastOverTime{}, nil
 	case syntax.OpRangeTypeAbsent:
 		return &OneOverTime{}, nil
+	case syntax.OpRangeTypeDeriv:
+		return &DerivOverTime{}, nil
+	case syntax.OpRangeTypeChanges:
+		return &ChangesOverTime{}, nil
+	case syntax.OpRangeTypeResets:
+		return &ResetsOverTime{}, nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
@@ -851,3 +989,51 @@ func (a *OneOverTime) agg(_ promql.FPoint) {
 func (a *OneOverTime) at() float64 {
 	return 1.0
 }
+
+type DerivOverTime struct {
+	samples []promql.FPoint
+}
+
+func (a *DerivOverTime) agg(sample promql.FPoint) {
+	a.samples = append(a.samples, sample)
+}
+
+func (a *DerivOverTime) at() float64 {
+	return deriv(0, a.samples)
+}
+
+type ChangesOverTime struct {
+	prev    float64
+	hasData bool
+	changes float64
+}
+
+func (a *ChangesOverTime) agg(sample promql.FPoint) {
+	if a.hasData && valueChanged(a.prev, sample.F) {
+		a.changes++
+	}
+	a.prev = sample.F
+	a.hasData = true
+}
+
+func (a *ChangesOverTime) at() float64 {
+	return a.changes
+}
+
+type ResetsOverTime struct {
+	prev    float64
+	hasData bool
+	resets  float64
+}
+
+func (a *ResetsOverTime) agg(sample promql.FPoint) {
+	if a.hasData && sample.F < a.prev {
+		a.resets++
+	}
+	a.prev = sample.F
+	a.hasData = true
+}
+
+func (a *ResetsOverTime) at() float64 {
+	return a.resets
+}
@@ -3,6 +3,7 @@ package logql
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"sort"
 	"testing"
@@ -535,6 +536,10 @@ func Test_InstantQueryRangeVectorAggregations(t *testing.T) {
 		{"first", 1., syntax.OpRangeTypeFirst, false},
 		{"last", 3., syntax.OpRangeTypeLast, false},
 		{"absent", 1., syntax.OpRangeTypeAbsent, false},
+		{"deriv", 1.0000000000000001e+09, syntax.OpRangeTypeDeriv, false},
+		{"changes", 2., syntax.OpRangeTypeChanges, false},
+		{"resets", 0., syntax.OpRangeTypeResets, false},
+		{"resets negative", 2., syntax.OpRangeTypeResets, true},
 	}
 
 	var start, end int64 = 4, 4 // Instant query
@@ -554,6 +559,23 @@ func Test_InstantQueryRangeVectorAggregations(t *testing.T) {
 	}
 }
 
+func Test_PredictLinear(t *testing.T) {
+	// one sample every 10 seconds growing by 5 per second.
+	points := []promql.FPoint{
+		{T: time.Unix(10, 0).UnixNano(), F: 50},
+		{T: time.Unix(20, 0).UnixNano(), F: 100},
+		{T: time.Unix(30, 0).UnixNano(), F: 150},
+	}
+	ts := time.Unix(30, 0).UnixNano()
+
+	require.Equal(t, 5., deriv(ts, points))
+	require.Equal(t, 150., predictLinear(0)(ts, points))
+	require.Equal(t, 450., predictLinear(60)(ts, points))
+	// the prediction starts from the end of the range, not from the last sample.
+	require.Equal(t, 500., predictLinear(60)(time.Unix(40, 0).UnixNano(), points))
+	require.True(t, math.IsNaN(predictLinear(60)(ts, nil)))
+}
+
 func sampleIter(negative bool) iter.PeekingSampleIterator {
 	return iter.NewPeekingSampleIterator(
 		iter.NewSortSampleIterator([]iter.SampleIterator{
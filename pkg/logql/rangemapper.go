@@ -140,6 +140,9 @@ func (m RangeMapper) Map(expr syntax.SampleExpr, vectorAggrPushdown *syntax.Vect
 		return m.mapVectorAggregationExpr(e, recorder)
 	case *syntax.RangeAggregationExpr:
 		return m.mapRangeAggregationExpr(e, vectorAggrPushdown, recorder), nil
+	case *syntax.SubqueryAggregationExpr:
+		// the points of a subquery are evaluated over its whole range, so it is not split.
+		return e, nil
 	case *syntax.BinOpExpr:
 		lhsMapped, err := m.Map(e.SampleExpr, vectorAggrPushdown, recorder)
 		if err != nil {
@@ -175,6 +178,15 @@ func (m RangeMapper) Map(expr syntax.SampleExpr, vectorAggrPushdown *syntax.Vect
 		}
 		e.Left = lhsMapped
 		return e, nil
+	case *syntax.FunctionExpr:
+		// the vector aggregation cannot be pushed down through the function,
+		// as the function must be applied to the series before they are aggregated.
+		lhsMapped, err := m.Map(e.Left, nil, recorder)
+		if err != nil {
+			return nil, err
+		}
+		e.Left = lhsMapped
+		return e, nil
 	case *syntax.LiteralExpr:
 		return e, nil
 	case *syntax.VectorExpr:
@@ -556,7 +568,11 @@ func (m RangeMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 // A vector aggregation is splittable, if the aggregation operation is
 // supported and the inner expression is also splittable.
 // A range aggregation is splittable, if the aggregation operation is
-// supported.
+// supported and it has no in() stage, which must be evaluated once for the
+// whole range.
+// A range aggregation over a subquery is not splittable.
+// A label replace or a function is splittable, if its inner expression is
+// splittable.
 // A binary expression is splittable, if both the left and the right-hand side
 // are splittable.
 func isSplittableByRange(expr syntax.SampleExpr) bool {
@@ -566,7 +582,9 @@ func isSplittableByRange(expr syntax.SampleExpr) bool {
 		return ok && isSplittableByRange(e.Left)
 	case *syntax.RangeAggregationExpr:
 		_, ok := splittableRangeVectorOp[e.Operation]
-		return ok
+		return ok && !HasInExpr(e)
+	case *syntax.SubqueryAggregationExpr:
+		return false
 	case *syntax.BinOpExpr:
 		_, literalLHS := e.SampleExpr.(*syntax.LiteralExpr)
 		_, literalRHS := e.RHS.(*syntax.LiteralExpr)
@@ -575,6 +593,8 @@ func isSplittableByRange(expr syntax.SampleExpr) bool {
 		return isSplittableByRange(e.SampleExpr) || literalLHS && isSplittableByRange(e.RHS) || literalRHS
 	case *syntax.LabelReplaceExpr:
 		return isSplittableByRange(e.Left)
+	case *syntax.FunctionExpr:
+		return isSplittableByRange(e.Left)
 	case *syntax.VectorExpr:
 		return false
 	default:
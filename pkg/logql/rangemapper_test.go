@@ -1809,6 +1809,20 @@ func Test_SplitRangeVectorMapping(t *testing.T) {
 			)`,
 			3,
 		},
+		{
+			`clamp_max(sum by (foo) (count_over_time({app="foo"}[3m])), 10)`,
+			`clamp_max(
+				sum by (foo) (
+					sum without () (
+						downstream<sum by (foo) (count_over_time({app="foo"} [1m] offset 2m0s)), shard=<nil>>
+						++ downstream<sum by (foo) (count_over_time({app="foo"} [1m] offset 1m0s)), shard=<nil>>
+						++ downstream<sum by (foo) (count_over_time({app="foo"} [1m])), shard=<nil>>
+					)
+				),
+				10
+			)`,
+			3,
+		},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			t.Parallel()
@@ -1842,6 +1856,16 @@ func Test_SplitRangeVectorMapping_Noop(t *testing.T) {
 			`sum(avg_over_time({app="foo"} | unwrap bar[3m]))`,
 		},
 
+		// Range aggregations over subqueries
+		{
+			`max_over_time(sum(bytes_over_time({app="foo"}[3m]))[1h:1m])`,
+			`max_over_time(sum(bytes_over_time({app="foo"}[3m]))[1h:1m])`,
+		},
+		{
+			`sum(bytes_over_time({app="foo"}[3m])) / max_over_time(sum(bytes_over_time({app="foo"}[3m]))[1h:1m])`,
+			`(sum(bytes_over_time({app="foo"}[3m])) / max_over_time(sum(bytes_over_time({app="foo"}[3m]))[1h:1m]))`,
+		},
+
 		// should be noop if range interval is lower or equal to split interval (1m)
 		{
 			`bytes_over_time({app="foo"}[1m])`,
@@ -1999,6 +2023,20 @@ func Test_SplitRangeVectorMapping_Noop(t *testing.T) {
 			`vector(0)`,
 			`vector(0.000000)`,
 		},
+		{
+			`deriv({app="foo"} | unwrap bar[3m])`,
+			`deriv({app="foo"} | unwrap bar[3m])`,
+		},
+		{
+			`clamp_min(predict_linear({app="foo"} | unwrap bar[3m], 60), 0)`,
+			`clamp_min(predict_linear({app="foo"} | unwrap bar[3m], 60), 0)`,
+		},
+
+		// should be noop if the query of an in() stage is not resolved
+		{
+			`sum(count_over_time({app="foo"} | logfmt | trace_id in ({app="bar"})[3m]))`,
+			`sum(count_over_time({app="foo"} | logfmt | trace_id in ({app="bar"})[3m]))`,
+		},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			t.Parallel()
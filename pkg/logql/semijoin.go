@@ -0,0 +1,155 @@
+package logql
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// InQueryEvaluator evaluates the query of an in() stage as an instant query at
+// ts and returns the labels of the resulting series.
+type InQueryEvaluator func(ctx context.Context, expr syntax.SampleExpr, ts time.Time) ([]labels.Labels, error)
+
+// HasInExpr returns true if the pipeline of expr contains an in() stage.
+func HasInExpr(expr syntax.Expr) bool {
+	var found bool
+	expr.Walk(func(e syntax.Expr) {
+		if _, ok := e.(*syntax.InExpr); ok {
+			found = true
+		}
+	})
+	return found
+}
+
+// ResolveInExprs evaluates the queries of the in() stages of expr once and
+// replaces the stages with label filters matching the values of their label
+// in the results. The original expression is not mutated.
+//
+// The query of an in() stage is evaluated as an instant query at end. A log
+// query is counted over the whole time range selected by expr, so that all of
+// its values within the range are used.
+func ResolveInExprs(ctx context.Context, expr syntax.Expr, start, end time.Time, maxValues int, eval InQueryEvaluator) (syntax.Expr, error) {
+	if !HasInExpr(expr) {
+		return expr, nil
+	}
+
+	expr, err := syntax.Clone(expr)
+	if err != nil {
+		return nil, err
+	}
+	selRange := end.Sub(start) + maxRangeAndOffset(expr)
+
+	// the same stage is resolved once, even if it is used in multiple legs of the query.
+	resolved := map[string]*syntax.LabelFilterExpr{}
+	expr.Walk(func(e syntax.Expr) {
+		pipeline, ok := e.(*syntax.PipelineExpr)
+		if !ok || err != nil {
+			return
+		}
+		for i, stage := range pipeline.MultiStages {
+			in, ok := stage.(*syntax.InExpr)
+			if !ok {
+				continue
+			}
+			key := in.String()
+			filter, ok := resolved[key]
+			if !ok {
+				filter, err = resolveInExpr(ctx, in, selRange, end, maxValues, eval)
+				if err != nil {
+					return
+				}
+				resolved[key] = filter
+			}
+			pipeline.MultiStages[i] = filter
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+func resolveInExpr(ctx context.Context, in *syntax.InExpr, selRange time.Duration, ts time.Time, maxValues int, eval InQueryEvaluator) (*syntax.LabelFilterExpr, error) {
+	series, err := eval(ctx, inQuery(in, selRange), ts)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]struct{}{}
+	for _, lbs := range series {
+		v := lbs.Get(in.Label)
+		if v == "" {
+			continue
+		}
+		values[v] = struct{}{}
+		if len(values) > maxValues {
+			return nil, logqlmodel.NewInValuesLimitError(in.Label, maxValues)
+		}
+	}
+	return inLabelFilter(in.Label, values)
+}
+
+// inQuery returns the metric query returning the values of the label of an
+// in() stage as series.
+func inQuery(in *syntax.InExpr, selRange time.Duration) syntax.SampleExpr {
+	switch e := in.Query.(type) {
+	case syntax.LogSelectorExpr:
+		// sum by (<label>) (count_over_time(<query> [<range>]))
+		return &syntax.VectorAggregationExpr{
+			Left: &syntax.RangeAggregationExpr{
+				Left: &syntax.LogRangeExpr{
+					Left:     e,
+					Interval: selRange,
+				},
+				Operation: syntax.OpRangeTypeCount,
+			},
+			Grouping:  &syntax.Grouping{Groups: []string{in.Label}},
+			Operation: syntax.OpTypeSum,
+		}
+	default:
+		return in.Query.(syntax.SampleExpr)
+	}
+}
+
+// inLabelFilter returns a label filter matching any of the values.
+// It never matches if there are no values.
+func inLabelFilter(name string, values map[string]struct{}) (*syntax.LabelFilterExpr, error) {
+	matchType, value := labels.MatchNotRegexp, ".*"
+	if len(values) > 0 {
+		quoted := make([]string, 0, len(values))
+		for v := range values {
+			quoted = append(quoted, regexp.QuoteMeta(v))
+		}
+		sort.Strings(quoted)
+		matchType, value = labels.MatchRegexp, strings.Join(quoted, "|")
+	}
+
+	m, err := labels.NewMatcher(matchType, name, value)
+	if err != nil {
+		return nil, err
+	}
+	return &syntax.LabelFilterExpr{LabelFilterer: log.NewStringLabelFilter(m)}, nil
+}
+
+// maxRangeAndOffset returns how far before the start of a query expr selects
+// data, from the ranges and offsets of its range aggregations and subqueries.
+func maxRangeAndOffset(expr syntax.Expr) time.Duration {
+	var logRange, subqueryRange time.Duration
+	expr.Walk(func(e syntax.Expr) {
+		switch r := e.(type) {
+		case *syntax.LogRangeExpr:
+			logRange = max(logRange, r.Interval+r.Offset)
+		case *syntax.SubqueryExpr:
+			subqueryRange = max(subqueryRange, r.Range+r.Offset)
+		}
+	})
+	return logRange + subqueryRange
+}
@@ -0,0 +1,117 @@
+package logql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+func TestResolveInExprs(t *testing.T) {
+	start, end := time.Unix(0, 0), time.Unix(3600, 0)
+
+	for _, tc := range []struct {
+		name      string
+		query     string
+		values    []string
+		maxValues int
+
+		expected        string
+		expectedQueries []string
+		expectedErr     error
+	}{
+		{
+			name:     "no in stage",
+			query:    `{app="foo"} | logfmt | trace_id="a"`,
+			expected: `{app="foo"} | logfmt | trace_id="a"`,
+		},
+		{
+			name:            "log query",
+			query:           `{app="foo"} | logfmt | trace_id in ({app="bar"} |= "error" | logfmt)`,
+			values:          []string{"b", "a", "a", "", "c.d"},
+			expected:        "{app=\"foo\"} | logfmt | trace_id=~`a|b|c\\.d`",
+			expectedQueries: []string{`sum by (trace_id)(count_over_time({app="bar"} |= "error" | logfmt[1h]))`},
+		},
+		{
+			name:            "metric query",
+			query:           `sum(count_over_time({app="foo"} | logfmt | trace_id in (sum by (trace_id) (count_over_time({app="bar"} | logfmt [1m])) > 10) [5m] offset 1m))`,
+			values:          []string{"a"},
+			expected:        `sum(count_over_time({app="foo"} | logfmt | trace_id=~"a"[5m] offset 1m0s))`,
+			expectedQueries: []string{`(sum by (trace_id)(count_over_time({app="bar"} | logfmt[1m])) > 10)`},
+		},
+		{
+			name:            "log query in a range aggregation",
+			query:           `count_over_time({app="foo"} | logfmt | trace_id in ({app="bar"} | logfmt) [5m] offset 1m)`,
+			values:          []string{"a"},
+			expected:        `count_over_time({app="foo"} | logfmt | trace_id=~"a"[5m] offset 1m0s)`,
+			expectedQueries: []string{`sum by (trace_id)(count_over_time({app="bar"} | logfmt[1h6m]))`},
+		},
+		{
+			name:            "same stage is resolved once",
+			query:           `count_over_time({app="foo"} | logfmt | trace_id in ({app="bar"} | logfmt) [5m]) / count_over_time({app="baz"} | logfmt | trace_id in ({app="bar"} | logfmt) [5m])`,
+			values:          []string{"a"},
+			expected:        `(count_over_time({app="foo"} | logfmt | trace_id=~"a"[5m]) / count_over_time({app="baz"} | logfmt | trace_id=~"a"[5m]))`,
+			expectedQueries: []string{`sum by (trace_id)(count_over_time({app="bar"} | logfmt[1h5m]))`},
+		},
+		{
+			name:            "no values",
+			query:           `{app="foo"} | logfmt | trace_id in ({app="bar"} | logfmt)`,
+			expected:        `{app="foo"} | logfmt | trace_id!~".*"`,
+			expectedQueries: []string{`sum by (trace_id)(count_over_time({app="bar"} | logfmt[1h]))`},
+		},
+		{
+			name:            "too many values",
+			query:           `{app="foo"} | logfmt | trace_id in ({app="bar"} | logfmt)`,
+			values:          []string{"a", "b", "c"},
+			maxValues:       2,
+			expectedQueries: []string{`sum by (trace_id)(count_over_time({app="bar"} | logfmt[1h]))`},
+			expectedErr:     logqlmodel.NewInValuesLimitError("trace_id", 2),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var queries []string
+			eval := func(_ context.Context, expr syntax.SampleExpr, ts time.Time) ([]labels.Labels, error) {
+				require.Equal(t, end, ts)
+				queries = append(queries, expr.String())
+				series := make([]labels.Labels, 0, len(tc.values))
+				for _, v := range tc.values {
+					series = append(series, labels.FromStrings("app", "bar", "trace_id", v))
+				}
+				return series, nil
+			}
+			maxValues := tc.maxValues
+			if maxValues == 0 {
+				maxValues = 10
+			}
+
+			expr := syntax.MustParseExpr(tc.query)
+			resolved, err := ResolveInExprs(context.Background(), expr, start, end, maxValues, eval)
+			require.Equal(t, tc.expectedQueries, queries)
+			if tc.expectedErr != nil {
+				require.Equal(t, tc.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, resolved.String())
+			// the original expression is not mutated
+			require.Equal(t, syntax.MustParseExpr(tc.query).String(), expr.String())
+			// the resolved expression can be sent downstream
+			require.Equal(t, resolved.String(), syntax.MustParseExpr(resolved.String()).String())
+		})
+	}
+}
+
+func TestResolveInExprs_Error(t *testing.T) {
+	expectedErr := errors.New("failed")
+	eval := func(context.Context, syntax.SampleExpr, time.Time) ([]labels.Labels, error) {
+		return nil, expectedErr
+	}
+	_, err := ResolveInExprs(context.Background(), syntax.MustParseExpr(`{app="foo"} | trace_id in ({app="bar"})`), time.Unix(0, 0), time.Unix(1, 0), 10, eval)
+	require.ErrorIs(t, err, expectedErr)
+}
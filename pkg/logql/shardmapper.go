@@ -99,8 +99,12 @@ func (m ShardMapper) Map(expr syntax.Expr, r *downstreamRecorder, topLevel bool)
 		return m.mapVectorAggregationExpr(e, r, topLevel)
 	case *syntax.LabelReplaceExpr:
 		return m.mapLabelReplaceExpr(e, r, topLevel)
+	case *syntax.FunctionExpr:
+		return m.mapFunctionExpr(e, r, topLevel)
 	case *syntax.RangeAggregationExpr:
 		return m.mapRangeAggregationExpr(e, r, topLevel)
+	case *syntax.SubqueryAggregationExpr:
+		return m.mapSubqueryAggregationExpr(e, r)
 	case *syntax.BinOpExpr:
 		return m.mapBinOpExpr(e, r, topLevel)
 	default:
@@ -161,6 +165,11 @@ func (m ShardMapper) mapBinOpExpr(e *syntax.BinOpExpr, r *downstreamRecorder, to
 }
 
 func (m ShardMapper) mapLogSelectorExpr(expr syntax.LogSelectorExpr, r *downstreamRecorder) (syntax.LogSelectorExpr, uint64, error) {
+	// e.g. the query of an in() stage must be evaluated once for all the shards.
+	if !expr.Shardable(true) {
+		return noOp(expr, m.shards.Resolver())
+	}
+
 	var head *ConcatLogSelectorExpr
 	shards, maxBytesPerShard, err := m.shards.Shards(expr)
 	if err != nil {
@@ -415,6 +424,40 @@ func (m ShardMapper) mapLabelReplaceExpr(expr *syntax.LabelReplaceExpr, r *downs
 	return &cpy, bytesPerShard, nil
 }
 
+// mapFunctionExpr attempts to shard the inner expression of the function.
+// The function itself is applied to the merged result of the shards.
+func (m ShardMapper) mapFunctionExpr(expr *syntax.FunctionExpr, r *downstreamRecorder, topLevel bool) (syntax.SampleExpr, uint64, error) {
+	subMapped, bytesPerShard, err := m.Map(expr.Left, r, topLevel)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = subMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
+// mapSubqueryAggregationExpr attempts to shard the inner expression of the subquery.
+// The range aggregation itself is never sharded, it is applied to the merged
+// result of the shards as the same series may exist on multiple shards.
+func (m ShardMapper) mapSubqueryAggregationExpr(expr *syntax.SubqueryAggregationExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
+	subMapped, bytesPerShard, err := m.Map(expr.Left.Left, r, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	sampleExpr, ok := subMapped.(syntax.SampleExpr)
+	if !ok {
+		return nil, 0, badASTMapping(subMapped)
+	}
+
+	subquery := *expr.Left
+	subquery.Left = sampleExpr
+	return &syntax.SubqueryAggregationExpr{
+		Left:      &subquery,
+		Operation: expr.Operation,
+		Params:    expr.Params,
+	}, bytesPerShard, nil
+}
+
 // These functions require a different merge strategy than the default
 // concatenation.
 // This is because the same label sets may exist on multiple shards when label-reducing parsing is applied or when
@@ -439,6 +482,10 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 	}
 
 	switch expr.Operation {
+	case syntax.OpRangeTypeDeriv, syntax.OpRangeTypePredictLinear, syntax.OpRangeTypeChanges, syntax.OpRangeTypeResets:
+		// These functions do not support grouping and are only shardable if
+		// they do not reduce labels, so each series exists on a single shard.
+		return m.mapSampleExpr(expr, r)
 
 	case syntax.OpRangeTypeCount, syntax.OpRangeTypeRate, syntax.OpRangeTypeBytes, syntax.OpRangeTypeBytesRate, syntax.OpRangeTypeSum, syntax.OpRangeTypeMax, syntax.OpRangeTypeMin:
 		// if the expr can reduce labels, it can cause the same labelset to
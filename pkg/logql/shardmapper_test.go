@@ -473,6 +473,42 @@ func TestMappingStrings(t *testing.T) {
 			in:  `count by (foo) (sum by (foo, bar) (rate({job="bar"}[1m])))`,
 			out: `countby(foo)(sumby(foo,bar)(downstream<sumby(foo,bar)(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sumby(foo,bar)(rate({job="bar"}[1m])),shard=1_of_2>))`,
 		},
+		{
+			// the range aggregation of a subquery is applied to the merged shards of its inner expression
+			in:  `max_over_time(rate({job="bar"}[1m])[1h:1m])`,
+			out: `max_over_time(downstream<rate({job="bar"}[1m]),shard=0_of_2>++downstream<rate({job="bar"}[1m]),shard=1_of_2>[1h:1m])`,
+		},
+		{
+			in:  `sum(max_over_time(sum by (foo) (rate({job="bar"}[1m]))[1h:1m] offset 5m))`,
+			out: `sum(max_over_time(sumby(foo)(downstream<sumby(foo)(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sumby(foo)(rate({job="bar"}[1m])),shard=1_of_2>)[1h:1m]offset5m0s))`,
+		},
+		{
+			// deriv is computed per series and can be sharded as long as no labels are reduced
+			in:  `deriv({job="bar"} | logfmt | unwrap latency [1m])`,
+			out: `downstream<deriv({job="bar"}|logfmt|unwraplatency[1m]),shard=0_of_2>++downstream<deriv({job="bar"}|logfmt|unwraplatency[1m]),shard=1_of_2>`,
+		},
+		{
+			in:  `sum(changes({job="bar"} | logfmt | unwrap latency [1m]))`,
+			out: `sum(downstream<sum(changes({job="bar"}|logfmt|unwraplatency[1m])),shard=0_of_2>++downstream<sum(changes({job="bar"}|logfmt|unwraplatency[1m])),shard=1_of_2>)`,
+		},
+		{
+			// the series are merged before the function is applied
+			in:  `histogram_quantile(0.99, sum by (le) (sum_over_time({job="bar"} | logfmt | unwrap bucket [1m])))`,
+			out: `histogram_quantile(0.99,sumby(le)(downstream<sumby(le)(sum_over_time({job="bar"}|logfmt|unwrapbucket[1m])),shard=0_of_2>++downstream<sumby(le)(sum_over_time({job="bar"}|logfmt|unwrapbucket[1m])),shard=1_of_2>))`,
+		},
+		{
+			in:  `clamp_max(rate({job="bar"}[1m]), 10)`,
+			out: `clamp_max(downstream<rate({job="bar"}[1m]),shard=0_of_2>++downstream<rate({job="bar"}[1m]),shard=1_of_2>,10)`,
+		},
+		{
+			// the query of an in() stage is evaluated once for all the shards
+			in:  `{foo="bar"} | logfmt | trace_id in ({app="baz"})`,
+			out: `{foo="bar"} | logfmt | trace_id in ({app="baz"})`,
+		},
+		{
+			in:  `sum(rate({foo="bar"} | logfmt | trace_id in ({app="baz"}) [1m]))`,
+			out: `sum(rate({foo="bar"} | logfmt | trace_id in ({app="baz"}) [1m]))`,
+		},
 	} {
 		t.Run(tc.in, func(t *testing.T) {
 			ast, err := syntax.ParseExpr(tc.in)
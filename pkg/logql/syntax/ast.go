@@ -48,10 +48,12 @@ type Expr interface {
 func (MatchersExpr) isExpr()               {}
 func (PipelineExpr) isExpr()               {}
 func (RangeAggregationExpr) isExpr()       {}
+func (SubqueryAggregationExpr) isExpr()    {}
 func (VectorAggregationExpr) isExpr()      {}
 func (LiteralExpr) isExpr()                {}
 func (VectorExpr) isExpr()                 {}
 func (LabelReplaceExpr) isExpr()           {}
+func (FunctionExpr) isExpr()               {}
 func (LineParserExpr) isExpr()             {}
 func (LogfmtParserExpr) isExpr()           {}
 func (LineFilterExpr) isExpr()             {}
@@ -59,12 +61,14 @@ func (LabelFilterExpr) isExpr()            {}
 func (DecolorizeExpr) isExpr()             {}
 func (DropLabelsExpr) isExpr()             {}
 func (KeepLabelsExpr) isExpr()             {}
+func (InExpr) isExpr()                     {}
 func (LineFmtExpr) isExpr()                {}
 func (LabelFmtExpr) isExpr()               {}
 func (JSONExpressionParserExpr) isExpr()   {}
 func (LogfmtExpressionParserExpr) isExpr() {}
 func (LogRangeExpr) isExpr()               {}
 func (OffsetExpr) isExpr()                 {}
+func (SubqueryExpr) isExpr()               {}
 func (UnwrapExpr) isExpr()                 {}
 func (MultiVariantExpr) isExpr()           {}
 
@@ -96,12 +100,14 @@ type SampleExpr interface {
 	isSampleExpr()
 }
 
-func (RangeAggregationExpr) isSampleExpr()  {}
-func (VectorAggregationExpr) isSampleExpr() {}
-func (LiteralExpr) isSampleExpr()           {}
-func (VectorExpr) isSampleExpr()            {}
-func (LabelReplaceExpr) isSampleExpr()      {}
-func (MultiVariantExpr) isSampleExpr()      {}
+func (RangeAggregationExpr) isSampleExpr()    {}
+func (SubqueryAggregationExpr) isSampleExpr() {}
+func (VectorAggregationExpr) isSampleExpr()   {}
+func (LiteralExpr) isSampleExpr()             {}
+func (VectorExpr) isSampleExpr()              {}
+func (LabelReplaceExpr) isSampleExpr()        {}
+func (FunctionExpr) isSampleExpr()            {}
+func (MultiVariantExpr) isSampleExpr()        {}
 
 // StageExpr is an expression defining a single step into a log pipeline
 type StageExpr interface {
@@ -119,6 +125,7 @@ func (LabelFilterExpr) isStageExpr()            {}
 func (DecolorizeExpr) isStageExpr()             {}
 func (DropLabelsExpr) isStageExpr()             {}
 func (KeepLabelsExpr) isStageExpr()             {}
+func (InExpr) isStageExpr()                     {}
 func (LineFmtExpr) isStageExpr()                {}
 func (LabelFmtExpr) isStageExpr()               {}
 func (JSONExpressionParserExpr) isStageExpr()   {}
@@ -421,7 +428,7 @@ func (e *PipelineExpr) Pipeline() (log.Pipeline, error) {
 func (e *PipelineExpr) HasFilter() bool {
 	for _, p := range e.MultiStages {
 		switch v := p.(type) {
-		case *LabelFilterExpr:
+		case *LabelFilterExpr, *InExpr:
 			return true
 		case *LineFilterExpr:
 			// ignore empty matchers as they match everything
@@ -920,6 +927,44 @@ func (e *KeepLabelsExpr) Walk(f WalkFn) { f(e) }
 
 func (e *KeepLabelsExpr) Accept(v RootVisitor) { v.VisitKeepLabel(e) }
 
+// InExpr keeps the entries whose value of a label is one of the values of the
+// same label in the result of another log or metric query, e.g.
+// `| trace_id in ({app="db"} |= "error" | logfmt)`.
+//
+// The inner query is evaluated once for the whole query, so an InExpr must be
+// replaced by a label filter matching the resulting values before its pipeline
+// is evaluated.
+type InExpr struct {
+	Label string
+	Query Expr
+}
+
+func mustNewInExpr(label string, query Expr) *InExpr {
+	if _, ok := query.(VariantsExpr); ok {
+		panic(logqlmodel.NewParseError("variants are not supported in an in() query", 0, 0))
+	}
+	if err := validateExpr(query); err != nil {
+		panic(err)
+	}
+	return &InExpr{Label: label, Query: query}
+}
+
+// Shardable returns false, as the inner query must not be evaluated once per shard.
+func (e *InExpr) Shardable(_ bool) bool { return false }
+
+func (e *InExpr) Stage() (log.Stage, error) {
+	return nil, fmt.Errorf("%s %s stage must be resolved before evaluation", e.Label, OpIn)
+}
+
+func (e *InExpr) String() string {
+	return fmt.Sprintf("%s %s %s (%s)", OpPipe, e.Label, OpIn, e.Query.String())
+}
+
+// Walk doesn't descend into the inner query, as it is a separate query.
+func (e *InExpr) Walk(f WalkFn) { f(e) }
+
+func (e *InExpr) Accept(v RootVisitor) { v.VisitIn(e) }
+
 func (e *LineFmtExpr) Shardable(_ bool) bool { return true }
 
 func (e *LineFmtExpr) Walk(f WalkFn) { f(e) }
@@ -1228,21 +1273,25 @@ const (
 	OpTypeSortDesc = "sort_desc"
 
 	// range vector ops
-	OpRangeTypeCount       = "count_over_time"
-	OpRangeTypeRate        = "rate"
-	OpRangeTypeRateCounter = "rate_counter"
-	OpRangeTypeBytes       = "bytes_over_time"
-	OpRangeTypeBytesRate   = "bytes_rate"
-	OpRangeTypeAvg         = "avg_over_time"
-	OpRangeTypeSum         = "sum_over_time"
-	OpRangeTypeMin         = "min_over_time"
-	OpRangeTypeMax         = "max_over_time"
-	OpRangeTypeStdvar      = "stdvar_over_time"
-	OpRangeTypeStddev      = "stddev_over_time"
-	OpRangeTypeQuantile    = "quantile_over_time"
-	OpRangeTypeFirst       = "first_over_time"
-	OpRangeTypeLast        = "last_over_time"
-	OpRangeTypeAbsent      = "absent_over_time"
+	OpRangeTypeCount         = "count_over_time"
+	OpRangeTypeRate          = "rate"
+	OpRangeTypeRateCounter   = "rate_counter"
+	OpRangeTypeBytes         = "bytes_over_time"
+	OpRangeTypeBytesRate     = "bytes_rate"
+	OpRangeTypeAvg           = "avg_over_time"
+	OpRangeTypeSum           = "sum_over_time"
+	OpRangeTypeMin           = "min_over_time"
+	OpRangeTypeMax           = "max_over_time"
+	OpRangeTypeStdvar        = "stdvar_over_time"
+	OpRangeTypeStddev        = "stddev_over_time"
+	OpRangeTypeQuantile      = "quantile_over_time"
+	OpRangeTypeFirst         = "first_over_time"
+	OpRangeTypeLast          = "last_over_time"
+	OpRangeTypeAbsent        = "absent_over_time"
+	OpRangeTypeDeriv         = "deriv"
+	OpRangeTypePredictLinear = "predict_linear"
+	OpRangeTypeChanges       = "changes"
+	OpRangeTypeResets        = "resets"
 
 	// vector
 	OpTypeVector = "vector"
@@ -1296,6 +1345,13 @@ const (
 
 	OpLabelReplace = "label_replace"
 
+	// functions
+	OpFunctionHistogramQuantile = "histogram_quantile"
+	OpFunctionClamp             = "clamp"
+	OpFunctionClampMin          = "clamp_min"
+	OpFunctionClampMax          = "clamp_max"
+	OpFunctionTimestamp         = "timestamp"
+
 	// function filters
 	OpFilterIP = "ip"
 
@@ -1305,6 +1361,9 @@ const (
 	// keep labels
 	OpKeep = "keep"
 
+	// semi-join
+	OpIn = "in"
+
 	// parser flags
 	OpStrict    = "--strict"
 	OpKeepEmpty = "--keep-empty"
@@ -1357,22 +1416,19 @@ type RangeAggregationExpr struct {
 }
 
 func newRangeAggregationExpr(left *LogRangeExpr, operation string, gr *Grouping, stringParams *string) SampleExpr {
-	var params *float64
-	if stringParams != nil {
-		if operation != OpRangeTypeQuantile && operation != OpRangeTypeQuantileSketch {
-			return &RangeAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0)}
-		}
-		var err error
-		params = new(float64)
-		*params, err = strconv.ParseFloat(*stringParams, 64)
-		if err != nil {
-			return &RangeAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: %s", operation, err), 0, 0)}
-		}
+	return makeRangeAggregationExpr(left, operation, gr, stringParams, false)
+}
 
-	} else {
-		if operation == OpRangeTypeQuantile {
-			return &RangeAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
-		}
+// newRangeAggregationExprWithParamAfter creates a range aggregation whose
+// parameter follows the range, e.g. predict_linear({app="foo"} | unwrap latency [1h], 3600).
+func newRangeAggregationExprWithParamAfter(left *LogRangeExpr, operation string, gr *Grouping, stringParam string) SampleExpr {
+	return makeRangeAggregationExpr(left, operation, gr, &stringParam, true)
+}
+
+func makeRangeAggregationExpr(left *LogRangeExpr, operation string, gr *Grouping, stringParams *string, paramAfter bool) SampleExpr {
+	params, err := parseRangeParams(operation, stringParams, paramAfter)
+	if err != nil {
+		return &RangeAggregationExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
 	}
 	e := &RangeAggregationExpr{
 		Left:      left,
@@ -1386,6 +1442,53 @@ func newRangeAggregationExpr(left *LogRangeExpr, operation string, gr *Grouping,
 	return e
 }
 
+// parseRangeParams parses the parameter of a range aggregation.
+// Like in PromQL, the parameter of quantile_over_time precedes the range,
+// while the parameter of predict_linear follows it.
+func parseRangeParams(operation string, stringParams *string, paramAfter bool) (*float64, error) {
+	if stringParams == nil {
+		if operation == OpRangeTypeQuantile || operation == OpRangeTypePredictLinear {
+			return nil, fmt.Errorf("parameter required for operation %s", operation)
+		}
+		return nil, nil
+	}
+	switch {
+	case paramAfter && paramAfterRange(operation):
+	case !paramAfter && (operation == OpRangeTypeQuantile || operation == OpRangeTypeQuantileSketch):
+	default:
+		return nil, fmt.Errorf("parameter %s not supported for operation %s", *stringParams, operation)
+	}
+	params, err := strconv.ParseFloat(*stringParams, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameter for operation %s: %s", operation, err)
+	}
+	return &params, nil
+}
+
+// paramAfterRange returns whether the parameter of the range operation follows the range.
+func paramAfterRange(operation string) bool {
+	return operation == OpRangeTypePredictLinear
+}
+
+// writeRangeParams writes the arguments of a range aggregation, with the
+// parameter at the position parsed by parseRangeParams.
+func writeRangeParams(sb *strings.Builder, operation string, params *float64, left fmt.Stringer) {
+	if params == nil {
+		sb.WriteString(left.String())
+		return
+	}
+	param := strconv.FormatFloat(*params, 'f', -1, 64)
+	if paramAfterRange(operation) {
+		sb.WriteString(left.String())
+		sb.WriteString(",")
+		sb.WriteString(param)
+		return
+	}
+	sb.WriteString(param)
+	sb.WriteString(",")
+	sb.WriteString(left.String())
+}
+
 func (e *RangeAggregationExpr) Selector() (LogSelectorExpr, error) {
 	if e.err != nil {
 		return nil, e.err
@@ -1425,7 +1528,8 @@ func (e RangeAggregationExpr) validate() error {
 		case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev,
 			OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeRate, OpRangeTypeRateCounter,
 			OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast, OpRangeTypeQuantileSketch,
-			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp:
+			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp, OpRangeTypeDeriv,
+			OpRangeTypePredictLinear, OpRangeTypeChanges, OpRangeTypeResets:
 			return nil
 		default:
 			return fmt.Errorf("invalid aggregation %s with unwrap", e.Operation)
@@ -1448,11 +1552,7 @@ func (e *RangeAggregationExpr) String() string {
 	var sb strings.Builder
 	sb.WriteString(e.Operation)
 	sb.WriteString("(")
-	if e.Params != nil {
-		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
-		sb.WriteString(",")
-	}
-	sb.WriteString(e.Left.String())
+	writeRangeParams(&sb, e.Operation, e.Params, e.Left)
 	sb.WriteString(")")
 	if e.Grouping != nil {
 		sb.WriteString(e.Grouping.String())
@@ -1469,9 +1569,25 @@ func (e *RangeAggregationExpr) Shardable(topLevel bool) bool {
 	if e.Operation == OpRangeTypeQuantile && !topLevel {
 		return false
 	}
+	// These functions need all the samples of a series, which may be spread
+	// across shards when labels are reduced.
+	if isSeriesFunction(e.Operation) && ReducesLabels(e) {
+		return false
+	}
 	return shardableOps[e.Operation] && e.Left.Shardable(topLevel)
 }
 
+// isSeriesFunction returns whether the range operation depends on the order
+// of the samples of a series, like the PromQL functions deriv or changes.
+func isSeriesFunction(op string) bool {
+	switch op {
+	case OpRangeTypeDeriv, OpRangeTypePredictLinear, OpRangeTypeChanges, OpRangeTypeResets:
+		return true
+	default:
+		return false
+	}
+}
+
 func (e *RangeAggregationExpr) Walk(f WalkFn) {
 	f(e)
 	if e.Left != nil {
@@ -1481,6 +1597,161 @@ func (e *RangeAggregationExpr) Walk(f WalkFn) {
 
 func (e *RangeAggregationExpr) Accept(v RootVisitor) { v.VisitRangeAggregation(e) }
 
+// SubqueryExpr evaluates a sample expression over a range at a given resolution.
+// e.g: rate({app="foo"}[1m])[1h:1m] offset 5m
+type SubqueryExpr struct {
+	Left   SampleExpr
+	Range  time.Duration
+	Step   time.Duration
+	Offset time.Duration
+}
+
+func newSubqueryExpr(left SampleExpr, r subqueryRange, o *OffsetExpr) *SubqueryExpr {
+	var offset time.Duration
+	if o != nil {
+		offset = o.Offset
+	}
+	return &SubqueryExpr{
+		Left:   left,
+		Range:  r.Range,
+		Step:   r.Step,
+		Offset: offset,
+	}
+}
+
+// impls Stringer
+func (e *SubqueryExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Left.String())
+	sb.WriteString(fmt.Sprintf("[%v:%v]", model.Duration(e.Range), model.Duration(e.Step)))
+	if e.Offset != 0 {
+		offsetExpr := OffsetExpr{Offset: e.Offset}
+		sb.WriteString(offsetExpr.String())
+	}
+	return sb.String()
+}
+
+// Shardable returns false as the same series of the inner expression can be
+// spread across multiple shards. Only the inner expression can be sharded.
+func (e *SubqueryExpr) Shardable(_ bool) bool { return false }
+
+func (e *SubqueryExpr) Walk(f WalkFn) {
+	f(e)
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *SubqueryExpr) Accept(v RootVisitor) { v.VisitSubquery(e) }
+
+// SubqueryAggregationExpr applies a range vector aggregation over the result of a subquery.
+// e.g: max_over_time(rate({app="foo"}[1m])[1h:1m])
+type SubqueryAggregationExpr struct {
+	Left      *SubqueryExpr
+	Operation string
+
+	Params *float64
+	err    error
+}
+
+func newSubqueryAggregationExpr(left *SubqueryExpr, operation string, stringParams *string) SampleExpr {
+	return makeSubqueryAggregationExpr(left, operation, stringParams, false)
+}
+
+// newSubqueryAggregationExprWithParamAfter creates a range aggregation over a
+// subquery whose parameter follows the subquery, e.g. predict_linear(rate({app="foo"}[1m])[1h:1m], 3600).
+func newSubqueryAggregationExprWithParamAfter(left *SubqueryExpr, operation string, stringParam string) SampleExpr {
+	return makeSubqueryAggregationExpr(left, operation, &stringParam, true)
+}
+
+func makeSubqueryAggregationExpr(left *SubqueryExpr, operation string, stringParams *string, paramAfter bool) SampleExpr {
+	params, err := parseRangeParams(operation, stringParams, paramAfter)
+	if err != nil {
+		return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+	e := &SubqueryAggregationExpr{
+		Left:      left,
+		Operation: operation,
+		Params:    params,
+	}
+	if err := e.validate(); err != nil {
+		return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+	return e
+}
+
+func (e *SubqueryAggregationExpr) validate() error {
+	if e.Left.Step <= 0 {
+		return fmt.Errorf("invalid subquery step %s: the step must be greater than zero", model.Duration(e.Left.Step))
+	}
+	if e.Left.Range < e.Left.Step {
+		return fmt.Errorf("invalid subquery range %s: the range must be greater than or equal to the step %s", model.Duration(e.Left.Range), model.Duration(e.Left.Step))
+	}
+	// The samples of a subquery are aggregated as unwrapped values.
+	switch e.Operation {
+	case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev,
+		OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeRate, OpRangeTypeRateCounter,
+		OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast, OpRangeTypeCount, OpRangeTypeDeriv,
+		OpRangeTypePredictLinear, OpRangeTypeChanges, OpRangeTypeResets:
+		return nil
+	default:
+		return fmt.Errorf("invalid aggregation %s over a subquery", e.Operation)
+	}
+}
+
+func (e *SubqueryAggregationExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Left.Selector()
+}
+
+func (e *SubqueryAggregationExpr) Extractors() ([]log.SampleExtractor, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Left.Extractors()
+}
+
+// MatcherGroups returns the matcher groups of the inner expression, with the
+// range and offset of the subquery added to their interval and offset.
+func (e *SubqueryAggregationExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	groups, err := e.Left.Left.MatcherGroups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		groups[i].Interval += e.Left.Range
+		groups[i].Offset += e.Left.Offset
+	}
+	return groups, nil
+}
+
+// impls Stringer
+func (e *SubqueryAggregationExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Operation)
+	sb.WriteString("(")
+	writeRangeParams(&sb, e.Operation, e.Params, e.Left)
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// impl SampleExpr
+func (e *SubqueryAggregationExpr) Shardable(topLevel bool) bool { return e.Left.Shardable(topLevel) }
+
+func (e *SubqueryAggregationExpr) Walk(f WalkFn) {
+	f(e)
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *SubqueryAggregationExpr) Accept(v RootVisitor) { v.VisitSubqueryAggregation(e) }
+
 // Grouping struct represents the grouping by/without label(s) for vector aggregators and range vector aggregators.
 // The representation is as follows:
 //   - No Grouping (labels dismissed): <operation> (<expr>) => Grouping{Without: false, Groups: nil}
@@ -2257,6 +2528,107 @@ func (e *LabelReplaceExpr) String() string {
 	return sb.String()
 }
 
+// FunctionExpr applies a PromQL function to the result of a sample expression
+// at each step.
+// e.g: histogram_quantile(0.99, sum by (le) (rate({app="foo"} | logfmt | unwrap le [1m])))
+type FunctionExpr struct {
+	Left     SampleExpr
+	Function string
+	Params   []float64
+	err      error
+}
+
+func newFunctionExpr(left SampleExpr, function string, params ...*LiteralExpr) *FunctionExpr {
+	e := &FunctionExpr{
+		Left:     left,
+		Function: function,
+	}
+	for _, p := range params {
+		if p.err != nil {
+			return &FunctionExpr{err: p.err}
+		}
+		e.Params = append(e.Params, p.Val)
+	}
+	if err := e.validate(); err != nil {
+		return &FunctionExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+	return e
+}
+
+func (e *FunctionExpr) validate() error {
+	var params int
+	switch e.Function {
+	case OpFunctionTimestamp:
+	case OpFunctionHistogramQuantile, OpFunctionClampMin, OpFunctionClampMax:
+		params = 1
+	case OpFunctionClamp:
+		params = 2
+	default:
+		return fmt.Errorf("unsupported function %s", e.Function)
+	}
+	if len(e.Params) != params {
+		return fmt.Errorf("function %s expects %d parameters, got %d", e.Function, params, len(e.Params))
+	}
+	return nil
+}
+
+func (e *FunctionExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Selector()
+}
+
+func (e *FunctionExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.MatcherGroups()
+}
+
+func (e *FunctionExpr) Extractors() ([]SampleExtractor, error) {
+	if e.err != nil {
+		return []SampleExtractor{}, e.err
+	}
+	return e.Left.Extractors()
+}
+
+// Shardable returns false as the function is applied to the merged result of
+// the shards. Only the inner expression can be sharded.
+func (e *FunctionExpr) Shardable(_ bool) bool {
+	return false
+}
+
+func (e *FunctionExpr) Walk(f WalkFn) {
+	f(e)
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *FunctionExpr) Accept(v RootVisitor) { v.VisitFunction(e) }
+
+// impls Stringer
+func (e *FunctionExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Function)
+	sb.WriteString("(")
+	// Like in PromQL, the quantile of histogram_quantile precedes the expression.
+	if e.Function == OpFunctionHistogramQuantile {
+		sb.WriteString(strconv.FormatFloat(e.Params[0], 'f', -1, 64))
+		sb.WriteString(",")
+		sb.WriteString(e.Left.String())
+	} else {
+		sb.WriteString(e.Left.String())
+		for _, p := range e.Params {
+			sb.WriteString(",")
+			sb.WriteString(strconv.FormatFloat(p, 'f', -1, 64))
+		}
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
 // shardableOps lists the operations which may be sharded, but are not
 // guaranteed to be. See the `Shardable()` implementations
 // on the respective expr types for more details.
@@ -2288,17 +2660,21 @@ var shardableOps = map[string]bool{
 	OpTypeApproxTopK: true,
 
 	// range vector ops
-	OpRangeTypeAvg:       true,
-	OpRangeTypeCount:     true,
-	OpRangeTypeFirst:     true,
-	OpRangeTypeLast:      true,
-	OpRangeTypeRate:      true,
-	OpRangeTypeBytes:     true,
-	OpRangeTypeBytesRate: true,
-	OpRangeTypeSum:       true,
-	OpRangeTypeMax:       true,
-	OpRangeTypeMin:       true,
-	OpRangeTypeQuantile:  true,
+	OpRangeTypeAvg:           true,
+	OpRangeTypeCount:         true,
+	OpRangeTypeFirst:         true,
+	OpRangeTypeLast:          true,
+	OpRangeTypeRate:          true,
+	OpRangeTypeBytes:         true,
+	OpRangeTypeBytesRate:     true,
+	OpRangeTypeSum:           true,
+	OpRangeTypeMax:           true,
+	OpRangeTypeMin:           true,
+	OpRangeTypeQuantile:      true,
+	OpRangeTypeDeriv:         true,
+	OpRangeTypePredictLinear: true,
+	OpRangeTypeChanges:       true,
+	OpRangeTypeResets:        true,
 
 	// binops - arith
 	OpTypeAdd: true,
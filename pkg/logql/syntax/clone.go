@@ -82,11 +82,33 @@ func (v *cloneVisitor) VisitRangeAggregation(e *RangeAggregationExpr) {
 	v.cloned = copied
 }
 
+func (v *cloneVisitor) VisitSubqueryAggregation(e *SubqueryAggregationExpr) {
+	copied := &SubqueryAggregationExpr{
+		Left:      MustClone[*SubqueryExpr](e.Left),
+		Operation: e.Operation,
+	}
+
+	if e.Params != nil {
+		tmp := *e.Params
+		copied.Params = &tmp
+	}
+
+	v.cloned = copied
+}
+
 func (v *cloneVisitor) VisitLabelReplace(e *LabelReplaceExpr) {
 	left := MustClone[SampleExpr](e.Left)
 	v.cloned = mustNewLabelReplaceExpr(left, e.Dst, e.Replacement, e.Src, e.Regex)
 }
 
+func (v *cloneVisitor) VisitFunction(e *FunctionExpr) {
+	v.cloned = &FunctionExpr{
+		Left:     MustClone[SampleExpr](e.Left),
+		Function: e.Function,
+		Params:   append([]float64(nil), e.Params...),
+	}
+}
+
 func (v *cloneVisitor) VisitLiteral(e *LiteralExpr) {
 	v.cloned = &LiteralExpr{Val: e.Val}
 }
@@ -95,6 +117,15 @@ func (v *cloneVisitor) VisitVector(e *VectorExpr) {
 	v.cloned = &VectorExpr{Val: e.Val}
 }
 
+func (v *cloneVisitor) VisitSubquery(e *SubqueryExpr) {
+	v.cloned = &SubqueryExpr{
+		Left:   MustClone[SampleExpr](e.Left),
+		Range:  e.Range,
+		Step:   e.Step,
+		Offset: e.Offset,
+	}
+}
+
 func (v *cloneVisitor) VisitLogRange(e *LogRangeExpr) {
 	copied := &LogRangeExpr{
 		Left:     MustClone[LogSelectorExpr](e.Left),
@@ -144,6 +175,13 @@ func (v *cloneVisitor) VisitDecolorize(*DecolorizeExpr) {
 	v.cloned = &DecolorizeExpr{}
 }
 
+func (v *cloneVisitor) VisitIn(e *InExpr) {
+	v.cloned = &InExpr{
+		Label: e.Label,
+		Query: MustClone[Expr](e.Query),
+	}
+}
+
 func (v *cloneVisitor) VisitDropLabels(e *DropLabelsExpr) {
 	copied := &DropLabelsExpr{
 		dropLabels: make([]log.NamedLabelMatcher, len(e.dropLabels)),
@@ -94,22 +94,26 @@ var parserFlags = map[string]struct{}{
 // functionTokens are tokens that needs to be suffixes with parenthesis
 var functionTokens = map[string]int{
 	// range vec ops
-	OpRangeTypeRate:        RATE,
-	OpRangeTypeRateCounter: RATE_COUNTER,
-	OpRangeTypeCount:       COUNT_OVER_TIME,
-	OpRangeTypeBytesRate:   BYTES_RATE,
-	OpRangeTypeBytes:       BYTES_OVER_TIME,
-	OpRangeTypeAvg:         AVG_OVER_TIME,
-	OpRangeTypeSum:         SUM_OVER_TIME,
-	OpRangeTypeMin:         MIN_OVER_TIME,
-	OpRangeTypeMax:         MAX_OVER_TIME,
-	OpRangeTypeStdvar:      STDVAR_OVER_TIME,
-	OpRangeTypeStddev:      STDDEV_OVER_TIME,
-	OpRangeTypeQuantile:    QUANTILE_OVER_TIME,
-	OpRangeTypeFirst:       FIRST_OVER_TIME,
-	OpRangeTypeLast:        LAST_OVER_TIME,
-	OpRangeTypeAbsent:      ABSENT_OVER_TIME,
-	OpTypeVector:           VECTOR,
+	OpRangeTypeRate:          RATE,
+	OpRangeTypeRateCounter:   RATE_COUNTER,
+	OpRangeTypeCount:         COUNT_OVER_TIME,
+	OpRangeTypeBytesRate:     BYTES_RATE,
+	OpRangeTypeBytes:         BYTES_OVER_TIME,
+	OpRangeTypeAvg:           AVG_OVER_TIME,
+	OpRangeTypeSum:           SUM_OVER_TIME,
+	OpRangeTypeMin:           MIN_OVER_TIME,
+	OpRangeTypeMax:           MAX_OVER_TIME,
+	OpRangeTypeStdvar:        STDVAR_OVER_TIME,
+	OpRangeTypeStddev:        STDDEV_OVER_TIME,
+	OpRangeTypeQuantile:      QUANTILE_OVER_TIME,
+	OpRangeTypeFirst:         FIRST_OVER_TIME,
+	OpRangeTypeLast:          LAST_OVER_TIME,
+	OpRangeTypeAbsent:        ABSENT_OVER_TIME,
+	OpRangeTypeDeriv:         DERIV,
+	OpRangeTypePredictLinear: PREDICT_LINEAR,
+	OpRangeTypeChanges:       CHANGES,
+	OpRangeTypeResets:        RESETS,
+	OpTypeVector:             VECTOR,
 
 	// vec ops
 	OpTypeSum:      SUM,
@@ -127,6 +131,13 @@ var functionTokens = map[string]int{
 
 	OpTypeApproxTopK: APPROX_TOPK,
 
+	// functions
+	OpFunctionHistogramQuantile: HISTOGRAM_QUANTILE,
+	OpFunctionClamp:             CLAMP,
+	OpFunctionClampMin:          CLAMP_MIN,
+	OpFunctionClampMax:          CLAMP_MAX,
+	OpFunctionTimestamp:         TIMESTAMP,
+
 	// conversion Op
 	OpConvBytes:           BYTES_CONV,
 	OpConvDuration:        DURATION_CONV,
@@ -134,6 +145,9 @@ var functionTokens = map[string]int{
 
 	// filterOp
 	OpFilterIP: IP,
+
+	// semi-join
+	OpIn: IN,
 }
 
 type lexer struct {
@@ -208,6 +222,9 @@ func (l *lexer) Lex(lval *syntaxSymType) int {
 		l.builder.Reset()
 		for r := l.Next(); r != scanner.EOF; r = l.Next() {
 			if r == ']' {
+				if rng, step, ok := strings.Cut(l.builder.String(), ":"); ok {
+					return l.scanSubqueryRange(rng, step, lval)
+				}
 				i, err := model.ParseDuration(l.builder.String())
 				if err != nil {
 					l.Error(err.Error())
@@ -257,6 +274,31 @@ func (l *lexer) Lex(lval *syntaxSymType) int {
 	return IDENTIFIER
 }
 
+// subqueryRange is the range and the step of a subquery, e.g. [1h:1m].
+type subqueryRange struct {
+	Range time.Duration
+	Step  time.Duration
+}
+
+func (l *lexer) scanSubqueryRange(rng, step string, lval *syntaxSymType) int {
+	r, err := model.ParseDuration(rng)
+	if err != nil {
+		l.Error(err.Error())
+		return 0
+	}
+	if step == "" {
+		l.Error("missing step in subquery range")
+		return 0
+	}
+	s, err := model.ParseDuration(step)
+	if err != nil {
+		l.Error(err.Error())
+		return 0
+	}
+	lval.subqueryRange = subqueryRange{Range: time.Duration(r), Step: time.Duration(s)}
+	return SUBQUERY_RANGE
+}
+
 func (l *lexer) Error(msg string) {
 	l.errs = append(l.errs, logqlmodel.NewParseError(msg, l.Line, l.Column))
 }
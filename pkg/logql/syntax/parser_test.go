@@ -2893,7 +2893,7 @@ var ParseTestCases = []struct {
 	},
 	{
 		in:  `quantile_over_time(foo,{namespace="tns"} |= "level=error" | json |foo>=5,bar<25ms| unwrap latency [5m])`,
-		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER, expecting NUMBER or { or (", 1, 20),
+		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER", 1, 20),
 	},
 	{
 		in:  `vector(abc)`,
@@ -3340,6 +3340,184 @@ var ParseTestCases = []struct {
 		},
 		err: nil,
 	},
+	{
+		in: `max_over_time(rate({app="foo"}[1m])[1h:1m])`,
+		exp: newSubqueryAggregationExpr(
+			newSubqueryExpr(
+				newRangeAggregationExpr(
+					newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}), time.Minute, nil, nil),
+					OpRangeTypeRate, nil, nil,
+				),
+				subqueryRange{Range: time.Hour, Step: time.Minute},
+				nil,
+			),
+			OpRangeTypeMax, nil,
+		),
+	},
+	{
+		in: `quantile_over_time(0.99, sum by (foo) (rate({app="foo"}[1m]))[1h:5m] offset 1h)`,
+		exp: newSubqueryAggregationExpr(
+			newSubqueryExpr(
+				mustNewVectorAggregationExpr(
+					newRangeAggregationExpr(
+						newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}), time.Minute, nil, nil),
+						OpRangeTypeRate, nil, nil,
+					),
+					OpTypeSum, &Grouping{Groups: []string{"foo"}}, nil,
+				),
+				subqueryRange{Range: time.Hour, Step: 5 * time.Minute},
+				newOffsetExpr(time.Hour),
+			),
+			OpRangeTypeQuantile, NewStringLabelFilter("0.99"),
+		),
+	},
+	{
+		in:  `bytes_over_time(rate({app="foo"}[1m])[1h:1m])`,
+		err: logqlmodel.NewParseError("invalid aggregation bytes_over_time over a subquery", 0, 0),
+	},
+	{
+		in:  `max_over_time(rate({app="foo"}[1m])[1m:1h])`,
+		err: logqlmodel.NewParseError("invalid subquery range 1m: the range must be greater than or equal to the step 1h", 0, 0),
+	},
+	{
+		in:  `max_over_time(rate({app="foo"}[1m])[1h:])`,
+		err: logqlmodel.NewParseError("missing step in subquery range", 0, 36),
+	},
+	{
+		in:  `rate({app="foo"}[1m])[1h:1m]`,
+		err: logqlmodel.NewParseError("syntax error: unexpected SUBQUERY_RANGE", 0, 22),
+	},
+	{
+		in: `deriv({app="foo"} | unwrap latency [5m])`,
+		exp: newRangeAggregationExpr(
+			newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}), 5*time.Minute, newUnwrapExpr("latency", ""), nil),
+			OpRangeTypeDeriv, nil, nil,
+		),
+	},
+	{
+		in: `predict_linear({app="foo"} | unwrap latency [5m], 3600)`,
+		exp: newRangeAggregationExprWithParamAfter(
+			newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}), 5*time.Minute, newUnwrapExpr("latency", ""), nil),
+			OpRangeTypePredictLinear, nil, "3600",
+		),
+	},
+	{
+		in: `changes({app="foo"} | unwrap latency [5m]) + resets({app="foo"} | unwrap latency [5m])`,
+		exp: mustNewBinOpExpr(
+			OpTypeAdd,
+			&BinOpOptions{ReturnBool: false, VectorMatching: &VectorMatching{Card: CardOneToOne}},
+			newRangeAggregationExpr(
+				newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}), 5*time.Minute, newUnwrapExpr("latency", ""), nil),
+				OpRangeTypeChanges, nil, nil,
+			),
+			newRangeAggregationExpr(
+				newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}), 5*time.Minute, newUnwrapExpr("latency", ""), nil),
+				OpRangeTypeResets, nil, nil,
+			),
+		),
+	},
+	{
+		in:  `predict_linear({app="foo"} | unwrap latency [5m])`,
+		err: logqlmodel.NewParseError("parameter required for operation predict_linear", 0, 0),
+	},
+	{
+		in:  `predict_linear(3600, {app="foo"} | unwrap latency [5m])`,
+		err: logqlmodel.NewParseError("parameter 3600 not supported for operation predict_linear", 0, 0),
+	},
+	{
+		in:  `changes({app="foo"}[5m])`,
+		err: logqlmodel.NewParseError("invalid aggregation changes without unwrap", 0, 0),
+	},
+	{
+		in: `histogram_quantile(0.99, sum by (le) (sum_over_time({app="foo"} | unwrap bucket [5m])))`,
+		exp: newFunctionExpr(
+			mustNewVectorAggregationExpr(
+				newRangeAggregationExpr(
+					newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}), 5*time.Minute, newUnwrapExpr("bucket", ""), nil),
+					OpRangeTypeSum, nil, nil,
+				),
+				OpTypeSum, &Grouping{Groups: []string{"le"}}, nil,
+			),
+			OpFunctionHistogramQuantile, mustNewLiteralExpr("0.99", false),
+		),
+	},
+	{
+		in: `clamp(count_over_time({app="foo"}[1m]), -1, 10)`,
+		exp: newFunctionExpr(
+			newRangeAggregationExpr(
+				newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}), time.Minute, nil, nil),
+				OpRangeTypeCount, nil, nil,
+			),
+			OpFunctionClamp, mustNewLiteralExpr("1", true), mustNewLiteralExpr("10", false),
+		),
+	},
+	{
+		in: `timestamp(clamp_max(count_over_time({app="foo"}[1m]), 10))`,
+		exp: newFunctionExpr(
+			newFunctionExpr(
+				newRangeAggregationExpr(
+					newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}), time.Minute, nil, nil),
+					OpRangeTypeCount, nil, nil,
+				),
+				OpFunctionClampMax, mustNewLiteralExpr("10", false),
+			),
+			OpFunctionTimestamp,
+		),
+	},
+	{
+		in: `{app="foo"} | json | trace_id in ({app="bar"} |= "error" | logfmt)`,
+		exp: newPipelineExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+			MultiStageExpr{
+				newLabelParserExpr(OpParserTypeJSON, ""),
+				&InExpr{
+					Label: "trace_id",
+					Query: newPipelineExpr(
+						newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "bar")}),
+						MultiStageExpr{
+							newLineFilterExpr(log.LineMatchEqual, "", "error"),
+							newLogfmtParserExpr(nil),
+						},
+					),
+				},
+			},
+		),
+	},
+	{
+		in: `{app="foo"} | trace_id in (sum by (trace_id) (count_over_time({app="bar"}[5m])) > 1)`,
+		exp: newPipelineExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+			MultiStageExpr{
+				&InExpr{
+					Label: "trace_id",
+					Query: mustNewBinOpExpr(
+						OpTypeGT,
+						&BinOpOptions{ReturnBool: false, VectorMatching: &VectorMatching{Card: CardOneToOne}},
+						mustNewVectorAggregationExpr(
+							newRangeAggregationExpr(
+								newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "bar")}), 5*time.Minute, nil, nil),
+								OpRangeTypeCount, nil, nil,
+							),
+							OpTypeSum, &Grouping{Groups: []string{"trace_id"}}, nil,
+						),
+						mustNewLiteralExpr("1", false),
+					),
+				},
+			},
+		),
+	},
+	{
+		in:  `{app="foo"} | in ({app="bar"})`,
+		err: logqlmodel.NewParseError("syntax error: unexpected IN", 1, 15),
+	},
+	{
+		in:  `{app="foo"} | trace_id in ({app=~".*"})`,
+		err: logqlmodel.NewParseError(errAtleastOneEqualityMatcherRequired, 0, 0),
+	},
+	{
+		in:  `clamp_min({app="foo"}, 10)`,
+		err: logqlmodel.NewParseError("syntax error: unexpected ,", 1, 22),
+	},
 }
 
 func TestParse(t *testing.T) {
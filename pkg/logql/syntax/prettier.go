@@ -121,6 +121,11 @@ func (e *KeepLabelsExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
 }
 
+// e.g: | trace_id in ({app="db"} |= "error" | logfmt)
+func (e *InExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
 // e.g: | level!="error"
 func (e *LabelFilterExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
@@ -210,13 +215,17 @@ func (e *RangeAggregationExpr) Pretty(level int) string {
 	s += "(\n"
 
 	// print args to the function.
-	if e.Params != nil {
+	if e.Params != nil && !paramAfterRange(e.Operation) {
 		s = fmt.Sprintf("%s%s%s,", s, Indent(level+1), fmt.Sprint(*e.Params))
 		s += "\n"
 	}
 
 	s += e.Left.Pretty(level + 1)
 
+	if e.Params != nil && paramAfterRange(e.Operation) {
+		s = fmt.Sprintf("%s,\n%s%s", s, Indent(level+1), fmt.Sprint(*e.Params))
+	}
+
 	s += "\n" + Indent(level) + ")"
 
 	if e.Grouping != nil {
@@ -226,6 +235,48 @@ func (e *RangeAggregationExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: max_over_time(rate({foo="bar"}[5m])[1h:1m])
+func (e *SubqueryAggregationExpr) Pretty(level int) string {
+	s := Indent(level)
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Operation // e.g: max_over_time
+
+	s += "(\n"
+
+	// print args to the function.
+	if e.Params != nil && !paramAfterRange(e.Operation) {
+		s = fmt.Sprintf("%s%s%s,", s, Indent(level+1), fmt.Sprint(*e.Params))
+		s += "\n"
+	}
+
+	s += e.Left.Pretty(level + 1)
+
+	if e.Params != nil && paramAfterRange(e.Operation) {
+		s = fmt.Sprintf("%s,\n%s%s", s, Indent(level+1), fmt.Sprint(*e.Params))
+	}
+
+	s += "\n" + Indent(level) + ")"
+
+	return s
+}
+
+// e.g: rate({foo="bar"}[5m])[1h:1m]
+func (e *SubqueryExpr) Pretty(level int) string {
+	s := e.Left.Pretty(level)
+
+	s = fmt.Sprintf("%s [%s:%s]", s, model.Duration(e.Range), model.Duration(e.Step))
+
+	if e.Offset != 0 {
+		oe := OffsetExpr{Offset: e.Offset}
+		s += oe.Pretty(level)
+	}
+
+	return s
+}
+
 // e.g:
 // sum(count_over_time({foo="bar"}[5m])) by (container)
 // topk(10, count_over_time({foo="bar"}[5m])) by (container)
@@ -338,6 +389,42 @@ func (e *LabelReplaceExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: histogram_quantile(0.99, sum by (le) (rate({app="foo"} | logfmt | unwrap le [1m])))
+func (e *FunctionExpr) Pretty(level int) string {
+	s := Indent(level)
+
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Function
+
+	s += "(\n"
+
+	params := make([]string, 0, len(e.Params)+1)
+	for _, p := range e.Params {
+		params = append(params, Indent(level+1)+fmt.Sprint(p))
+	}
+	if e.Function == OpFunctionHistogramQuantile {
+		params = append(params, e.Left.Pretty(level+1))
+	} else {
+		params = append([]string{e.Left.Pretty(level + 1)}, params...)
+	}
+
+	for i, v := range params {
+		s += v
+		// LogQL doesn't allow `,` at the end of last argument.
+		if i < len(params)-1 {
+			s += ","
+		}
+		s += "\n"
+	}
+
+	s += Indent(level) + ")"
+
+	return s
+}
+
 // e.g: vector(5)
 func (e *VectorExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
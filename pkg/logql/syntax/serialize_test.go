@@ -15,6 +15,9 @@ func TestJSONSerializationRoundTrip(t *testing.T) {
 		"simple matchers": {
 			query: `{env="prod", app=~"loki.*"}`,
 		},
+		"in stage": {
+			query: `count_over_time({app="foo"} | logfmt | trace_id in ({app="bar"} |= "error" | logfmt) [5m])`,
+		},
 		"simple aggregation": {
 			query: `count_over_time({env="prod", app=~"loki.*"}[5m])`,
 		},
@@ -59,6 +62,9 @@ func TestJSONSerializationRoundTrip(t *testing.T) {
 		"empty label filter string": {
 			query: `rate({app="foo"} |= "bar" | json | unwrap latency | path!="" [5m])`,
 		},
+		"subquery": {
+			query: `quantile_over_time(0.99, sum by (foo) (rate({app="foo"}[1m]))[1h:5m] offset 1h)`,
+		},
 		"multiple variants": {
 			query: `variants(bytes_over_time({foo="bar"}[5m]), count_over_time({foo="bar"}[5m])) of ({foo="bar"}[5m])`,
 		},
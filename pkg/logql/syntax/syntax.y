@@ -43,15 +43,17 @@ import (
   labelExtractionExpressionList []log.LabelExtractionExpr
   unwrapExpr *UnwrapExpr
   offsetExpr *OffsetExpr
+  subqueryRange subqueryRange
+  subqueryExpr *SubqueryExpr
 }
 
 %start root
 
 %type <expr> expr
 %type <logExpr> logExpr
-%type <metricExpr> metricExpr rangeAggregationExpr vectorAggregationExpr binOpExpr labelReplaceExpr vectorExpr
+%type <metricExpr> metricExpr rangeAggregationExpr vectorAggregationExpr binOpExpr labelReplaceExpr vectorExpr functionExpr
 %type <variantsExpr> variantsExpr
-%type <stage> pipelineStage logfmtParser labelParser jsonExpressionParser logfmtExpressionParser lineFormatExpr decolorizeExpr labelFormatExpr dropLabelsExpr keepLabelsExpr
+%type <stage> pipelineStage logfmtParser labelParser jsonExpressionParser logfmtExpressionParser lineFormatExpr decolorizeExpr labelFormatExpr dropLabelsExpr keepLabelsExpr inExpr
 %type <stages> pipelineExpr
 %type <lineFilterExpr> lineFilter lineFilters orFilter
 %type <op> rangeOp convOp vectorOp filterOp
@@ -73,18 +75,21 @@ import (
 %type <labelExtractionExpressionList> labelExtractionExpressionList
 %type <unwrapExpr> unwrapExpr
 %type <offsetExpr> offsetExpr
+%type <subqueryExpr> subqueryExpr
 %type <metricExprs> metricExprs
 
 %token <bytes> BYTES
 %token <str> IDENTIFIER STRING NUMBER FUNCTION_FLAG
 %token <dur> DURATION RANGE
+%token <subqueryRange> SUBQUERY_RANGE
 %token <val> MATCHERS LABELS EQ RE NRE NPA OPEN_BRACE CLOSE_BRACE OPEN_BRACKET CLOSE_BRACKET COMMA DOT PIPE_MATCH PIPE_EXACT PIPE_PATTERN
              OPEN_PARENTHESIS CLOSE_PARENTHESIS BY WITHOUT COUNT_OVER_TIME RATE RATE_COUNTER SUM SORT SORT_DESC AVG
              MAX MIN COUNT STDDEV STDVAR BOTTOMK TOPK APPROX_TOPK
              BYTES_OVER_TIME BYTES_RATE BOOL JSON REGEXP LOGFMT PIPE LINE_FMT LABEL_FMT UNWRAP AVG_OVER_TIME SUM_OVER_TIME MIN_OVER_TIME
              MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV
              FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME VECTOR LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
-             DECOLORIZE DROP KEEP VARIANTS OF
+             DECOLORIZE DROP KEEP VARIANTS OF DERIV PREDICT_LINEAR CHANGES RESETS HISTOGRAM_QUANTILE CLAMP CLAMP_MIN
+             CLAMP_MAX TIMESTAMP IN
 
 // Operators are listed with increasing precedence.
 %left <binOp> OR
@@ -118,6 +123,7 @@ metricExpr:
     | literalExpr                                   { $$ = $1 }
     | labelReplaceExpr                              { $$ = $1 }
     | vectorExpr                                    { $$ = $1 }
+    | functionExpr                                  { $$ = $1 }
     | OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS { $$ = $2 }
     ;
 
@@ -171,6 +177,16 @@ rangeAggregationExpr:
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS           { $$ = newRangeAggregationExpr($5, $1, nil, &$3) }
     | rangeOp OPEN_PARENTHESIS logRangeExpr CLOSE_PARENTHESIS grouping               { $$ = newRangeAggregationExpr($3, $1, $5, nil) }
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS grouping  { $$ = newRangeAggregationExpr($5, $1, $7, &$3) }
+    | rangeOp OPEN_PARENTHESIS logRangeExpr COMMA NUMBER CLOSE_PARENTHESIS           { $$ = newRangeAggregationExprWithParamAfter($3, $1, nil, $5) }
+    | rangeOp OPEN_PARENTHESIS logRangeExpr COMMA NUMBER CLOSE_PARENTHESIS grouping  { $$ = newRangeAggregationExprWithParamAfter($3, $1, $7, $5) }
+    | rangeOp OPEN_PARENTHESIS subqueryExpr CLOSE_PARENTHESIS                        { $$ = newSubqueryAggregationExpr($3, $1, nil) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA subqueryExpr CLOSE_PARENTHESIS           { $$ = newSubqueryAggregationExpr($5, $1, &$3) }
+    | rangeOp OPEN_PARENTHESIS subqueryExpr COMMA NUMBER CLOSE_PARENTHESIS           { $$ = newSubqueryAggregationExprWithParamAfter($3, $1, $5) }
+    ;
+
+subqueryExpr:
+      metricExpr SUBQUERY_RANGE              { $$ = newSubqueryExpr($1, $2, nil) }
+    | metricExpr SUBQUERY_RANGE offsetExpr   { $$ = newSubqueryExpr($1, $2, $3) }
     ;
 
 vectorAggregationExpr:
@@ -189,6 +205,14 @@ labelReplaceExpr:
       { $$ = mustNewLabelReplaceExpr($3, $5, $7, $9, $11)}
     ;
 
+functionExpr:
+      HISTOGRAM_QUANTILE OPEN_PARENTHESIS literalExpr COMMA metricExpr CLOSE_PARENTHESIS         { $$ = newFunctionExpr($5, OpFunctionHistogramQuantile, $3) }
+    | CLAMP OPEN_PARENTHESIS metricExpr COMMA literalExpr COMMA literalExpr CLOSE_PARENTHESIS   { $$ = newFunctionExpr($3, OpFunctionClamp, $5, $7) }
+    | CLAMP_MIN OPEN_PARENTHESIS metricExpr COMMA literalExpr CLOSE_PARENTHESIS                 { $$ = newFunctionExpr($3, OpFunctionClampMin, $5) }
+    | CLAMP_MAX OPEN_PARENTHESIS metricExpr COMMA literalExpr CLOSE_PARENTHESIS                 { $$ = newFunctionExpr($3, OpFunctionClampMax, $5) }
+    | TIMESTAMP OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS                                   { $$ = newFunctionExpr($3, OpFunctionTimestamp) }
+    ;
+
 selector:
       OPEN_BRACE matchers CLOSE_BRACE  { $$ = $2 }
     | OPEN_BRACE matchers error        { $$ = $2 }
@@ -224,6 +248,7 @@ pipelineStage:
   | PIPE labelFormatExpr         { $$ = $2 }
   | PIPE dropLabelsExpr          { $$ = $2 }
   | PIPE keepLabelsExpr          { $$ = $2 }
+  | PIPE inExpr                  { $$ = $2 }
   ;
 
 filter:
@@ -372,6 +397,8 @@ dropLabelsExpr: DROP namedMatchers { $$ = newDropLabelsExpr($2) }
 
 keepLabelsExpr: KEEP namedMatchers { $$ = newKeepLabelsExpr($2) }
 
+inExpr: IDENTIFIER IN OPEN_PARENTHESIS expr CLOSE_PARENTHESIS { $$ = mustNewInExpr($1, $4) }
+
 // Operator precedence only works if each of these is listed separately.
 binOpExpr:
          expr OR binOpModifier expr          { $$ = mustNewBinOpExpr("or", $3, $1, $4) }
@@ -505,6 +532,10 @@ rangeOp:
     | FIRST_OVER_TIME    { $$ = OpRangeTypeFirst }
     | LAST_OVER_TIME     { $$ = OpRangeTypeLast }
     | ABSENT_OVER_TIME   { $$ = OpRangeTypeAbsent }
+    | DERIV              { $$ = OpRangeTypeDeriv }
+    | PREDICT_LINEAR     { $$ = OpRangeTypePredictLinear }
+    | CHANGES            { $$ = OpRangeTypeChanges }
+    | RESETS             { $$ = OpRangeTypeResets }
     ;
 
 offsetExpr:
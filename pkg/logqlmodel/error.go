@@ -97,6 +97,12 @@ func NewSeriesLimitError(limit int) *LimitError {
 	}
 }
 
+func NewInValuesLimitError(label string, limit int) *LimitError {
+	return &LimitError{
+		error: fmt.Errorf("maximum of values (%d) reached for the label %s of an in() query", limit, label),
+	}
+}
+
 // Is allows to use errors.Is(err,ErrLimit) on this error.
 func (e LimitError) Is(target error) bool {
 	return target == ErrLimit
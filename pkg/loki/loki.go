@@ -67,6 +67,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	"github.com/grafana/loki/v3/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/bloomshipper"
+	"github.com/grafana/loki/v3/pkg/syslogreceiver"
 	"github.com/grafana/loki/v3/pkg/tracing"
 	"github.com/grafana/loki/v3/pkg/ui"
 	"github.com/grafana/loki/v3/pkg/util"
@@ -121,6 +122,8 @@ type Config struct {
 	IngestLimitsFrontend       limits_frontend.Config        `yaml:"ingest_limits_frontend,omitempty" category:"experimental"`
 	IngestLimitsFrontendClient limits_frontend_client.Config `yaml:"ingest_limits_frontend_client,omitempty" category:"experimental"`
 
+	SyslogReceiver syslogreceiver.Config `yaml:"syslog_receiver,omitempty" category:"experimental"`
+
 	RuntimeConfig     runtimeconfig.Config `yaml:"runtime_config,omitempty"`
 	OperationalConfig runtime.Config       `yaml:"operational_config,omitempty"`
 	Tracing           tracing.Config       `yaml:"tracing"`
@@ -204,6 +207,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.IngestLimits.RegisterFlags(f)
 	c.IngestLimitsFrontend.RegisterFlags(f)
 	c.IngestLimitsFrontendClient.RegisterFlags(f)
+	c.SyslogReceiver.RegisterFlags(f)
 	c.UI.RegisterFlags(f)
 	c.DataObj.RegisterFlags(f)
 }
@@ -299,6 +303,9 @@ func (c *Config) Validate() error {
 	if err := c.IngestLimitsFrontendClient.Validate(); err != nil {
 		errs = append(errs, errors.Wrap(err, "CONFIG ERROR: invalid ingest_limits_frontend_client config"))
 	}
+	if err := c.SyslogReceiver.Validate(); err != nil {
+		errs = append(errs, errors.Wrap(err, "CONFIG ERROR: invalid syslog_receiver config"))
+	}
 	if err := c.Worker.Validate(); err != nil {
 		errs = append(errs, errors.Wrap(err, "CONFIG ERROR: invalid frontend_worker config"))
 	}
@@ -396,6 +403,7 @@ type Loki struct {
 	ingestLimitsRing          *ring.Ring
 	ingestLimitsFrontend      *limits_frontend.Frontend
 	ingestLimitsFrontendRing  *ring.Ring
+	syslogReceiver            *syslogreceiver.Receiver
 	Ingester                  ingester.Interface
 	PatternIngester           *pattern.Ingester
 	PatternRingClient         pattern.RingClient
@@ -417,6 +425,7 @@ type Loki struct {
 	MemberlistKV              *memberlist.KVInitService
 	compactor                 *compactor.Compactor
 	QueryFrontEndMiddleware   queryrangebase.Middleware
+	asyncQueries              *queryrange.AsyncQueries
 	queryScheduler            *scheduler.Scheduler
 	querySchedulerRingManager *lokiring.RingManager
 	usageReport               *analytics.Reporter
@@ -733,6 +742,7 @@ func (t *Loki) setupModuleManager() error {
 	mm.RegisterModule(Distributor, t.initDistributor)
 	mm.RegisterModule(IngestLimits, t.initIngestLimits)
 	mm.RegisterModule(IngestLimitsFrontend, t.initIngestLimitsFrontend)
+	mm.RegisterModule(SyslogReceiver, t.initSyslogReceiver)
 	mm.RegisterModule(Store, t.initStore, modules.UserInvisibleModule)
 	mm.RegisterModule(Querier, t.initQuerier)
 	mm.RegisterModule(Ingester, t.initIngester)
@@ -785,6 +795,7 @@ func (t *Loki) setupModuleManager() error {
 		IngestLimits:             {MemberlistKV, Server},
 		IngestLimitsFrontend:     {IngestLimitsRing, Overrides, Server, MemberlistKV},
 		IngestLimitsFrontendRing: {RuntimeConfig, Server, MemberlistKV},
+		SyslogReceiver:           {Distributor},
 		Store:                    {Overrides, IndexGatewayRing},
 		Ingester:                 {Store, Server, MemberlistKV, TenantConfigs, Analytics, PartitionRing, UI},
 		Querier:                  {Store, Ring, Server, IngesterQuerier, PatternRingClient, Overrides, Analytics, CacheGenerationLoader, QuerySchedulerRing, UI},
@@ -824,6 +835,11 @@ func (t *Loki) setupModuleManager() error {
 		deps[All] = append(deps[All], IngestLimits, IngestLimitsFrontend)
 	}
 
+	if t.Cfg.SyslogReceiver.Enabled {
+		deps[All] = append(deps[All], SyslogReceiver)
+		deps[Write] = append(deps[Write], SyslogReceiver)
+	}
+
 	if t.Cfg.Querier.PerRequestLimitsEnabled {
 		level.Debug(util_log.Logger).Log("msg", "per-query request limits support enabled")
 		mm.RegisterModule(QueryLimiter, t.initQueryLimiter, modules.UserInvisibleModule)
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/NYTimes/gziphandler"
+	"github.com/apache/arrow-go/v18/arrow/flight"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/dns"
@@ -35,6 +36,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/collectors/version"
 	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 
@@ -64,6 +66,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/kafka/partition"
 	"github.com/grafana/loki/v3/pkg/limits"
 	limits_frontend "github.com/grafana/loki/v3/pkg/limits/frontend"
+	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
@@ -94,6 +97,7 @@ import (
 	boltdbcompactor "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/boltdb/compactor"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
 	"github.com/grafana/loki/v3/pkg/storage/types"
+	"github.com/grafana/loki/v3/pkg/syslogreceiver"
 	"github.com/grafana/loki/v3/pkg/ui"
 	"github.com/grafana/loki/v3/pkg/util/constants"
 	"github.com/grafana/loki/v3/pkg/util/httpreq"
@@ -121,6 +125,7 @@ const (
 	IngestLimitsRing         = "ingest-limits-ring"
 	IngestLimitsFrontend     = "ingest-limits-frontend"
 	IngestLimitsFrontendRing = "ingest-limits-frontend-ring"
+	SyslogReceiver           = "syslog-receiver"
 	Ingester                 = "ingester"
 	PatternIngester          = "pattern-ingester"
 	PatternRingClient        = "pattern-ring-client"
@@ -389,6 +394,11 @@ func (t *Loki) initDistributor() (services.Service, error) {
 		logproto.RegisterPusherServer(t.Server.GRPC, t.distributor)
 	}
 
+	// Register the distributor to receive OTLP logs over GRPC.
+	if t.Cfg.Distributor.OTLPGRPCEnabled {
+		plogotlp.RegisterGRPCServer(t.Server.GRPC, t.distributor.OTLPLogsServer())
+	}
+
 	httpPushHandlerMiddleware := middleware.Merge(
 		serverutil.RecoveryHTTPMiddleware,
 		t.HTTPAuthMiddleware,
@@ -406,9 +416,31 @@ func (t *Loki) initDistributor() (services.Service, error) {
 	t.Server.HTTP.Path("/api/prom/push").Methods("POST").Handler(lokiPushHandler)
 	t.Server.HTTP.Path("/loki/api/v1/push").Methods("POST").Handler(lokiPushHandler)
 	t.Server.HTTP.Path("/otlp/v1/logs").Methods("POST").Handler(otlpPushHandler)
+
+	if t.Cfg.Distributor.ElasticsearchBulkEnabled {
+		esBulkPushHandler := httpPushHandlerMiddleware.Wrap(http.HandlerFunc(t.distributor.ElasticsearchBulkPushHandler))
+		t.Server.HTTP.Path("/elasticsearch/").Methods("GET", "HEAD").Handler(httpPushHandlerMiddleware.Wrap(http.HandlerFunc(push.ElasticsearchInfoHandler)))
+		t.Server.HTTP.Path(push.ElasticsearchBulkPath).Methods("POST", "PUT").Handler(esBulkPushHandler)
+		t.Server.HTTP.Path("/elasticsearch/{index}/_bulk").Methods("POST", "PUT").Handler(esBulkPushHandler)
+	}
+	if t.Cfg.Distributor.SplunkHECEnabled {
+		splunkHECPushHandler := httpPushHandlerMiddleware.Wrap(http.HandlerFunc(t.distributor.SplunkHECPushHandler))
+		t.Server.HTTP.Path(push.SplunkHECPath + "/health").Methods("GET").Handler(httpPushHandlerMiddleware.Wrap(http.HandlerFunc(push.SplunkHECHealthHandler)))
+		t.Server.HTTP.Path(push.SplunkHECPath).Methods("POST").Handler(splunkHECPushHandler)
+		t.Server.HTTP.Path(push.SplunkHECPath + "/event").Methods("POST").Handler(splunkHECPushHandler)
+	}
 	return t.distributor, nil
 }
 
+func (t *Loki) initSyslogReceiver() (services.Service, error) {
+	if !t.Cfg.SyslogReceiver.Enabled {
+		return nil, nil
+	}
+
+	t.syslogReceiver = syslogreceiver.New(t.Cfg.SyslogReceiver, t.distributor, util_log.Logger, prometheus.DefaultRegisterer)
+	return t.syslogReceiver, nil
+}
+
 func (t *Loki) initIngestLimitsRing() (_ services.Service, err error) {
 	if !t.Cfg.IngestLimits.Enabled {
 		return nil, nil
@@ -1298,7 +1330,16 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		level.Debug(util_log.Logger).Log("msg", "no query frontend configured")
 	}
 
-	roundTripper := queryrange.NewSerializeRoundTripper(t.QueryFrontEndMiddleware.Wrap(frontendTripper), queryrange.DefaultCodec, t.Cfg.Frontend.SupportParquetEncoding)
+	// The active queries registry tracks the queries and their subqueries sent to the queriers, so
+	// they can be listed and canceled.
+	activeQueries := queryrange.NewActiveQueries(prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace, util_log.Logger)
+	queryHandler := activeQueries.Middleware().Wrap(t.QueryFrontEndMiddleware.Wrap(activeQueries.DownstreamMiddleware().Wrap(frontendTripper)))
+
+	roundTripper := queryrange.NewSerializeRoundTripper(queryHandler, queryrange.DefaultCodec, t.Cfg.Frontend.SupportParquetEncoding, t.Cfg.Frontend.SupportArrowEncoding)
+
+	if t.Cfg.Frontend.ArrowFlightEnabled {
+		flight.RegisterFlightServiceServer(t.Server.GRPC, queryrange.NewFlightServer(queryHandler, queryrange.DefaultCodec, t.Overrides, util_log.Logger))
+	}
 
 	frontendHandler := transport.NewHandler(t.Cfg.Frontend.Handler, roundTripper, util_log.Logger, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace)
 	if t.Cfg.Frontend.CompressResponses {
@@ -1364,6 +1405,7 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	t.Server.HTTP.Path("/loki/api/v1/series").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/patterns").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/detected_labels").Methods("GET", "POST").Handler(frontendHandler)
+	t.Server.HTTP.Path("/loki/api/v1/explain").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/detected_fields").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/detected_field/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/index/stats").Methods("GET", "POST").Handler(frontendHandler)
@@ -1383,8 +1425,61 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		t.Server.HTTP.Path("/api/prom/tail").Methods("GET", "POST").Handler(defaultHandler)
 	}
 
+	// The active queries are listed and canceled by the tenant running them.
+	activeQueriesMiddleware := middleware.Merge(
+		serverutil.RecoveryHTTPMiddleware,
+		t.HTTPAuthMiddleware,
+	)
+	t.Server.HTTP.Path("/frontend/active_queries").Methods("GET").Handler(activeQueriesMiddleware.Wrap(http.HandlerFunc(activeQueries.ListHandler)))
+	t.Server.HTTP.Path("/frontend/active_queries/{id}").Methods("DELETE").Handler(activeQueriesMiddleware.Wrap(http.HandlerFunc(activeQueries.CancelHandler)))
+
+	if t.Cfg.QueryRange.AsyncQueries.Enabled {
+		store, err := storage.NewObjectClient(t.Cfg.QueryRange.AsyncQueries.Store, "async-queries", t.Cfg.StorageConfig, t.ClientMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create async queries object client: %w", err)
+		}
+		t.asyncQueries = queryrange.NewAsyncQueries(t.Cfg.QueryRange.AsyncQueries, t.Overrides, queryHandler, store, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace, util_log.Logger)
+
+		asyncQueriesMiddleware := middleware.Merge(
+			httpreq.ExtractQueryTagsMiddleware(),
+			serverutil.RecoveryHTTPMiddleware,
+			t.HTTPAuthMiddleware,
+		)
+		t.Server.HTTP.Path("/loki/api/v1/query_jobs").Methods("POST").Handler(asyncQueriesMiddleware.Wrap(http.HandlerFunc(t.asyncQueries.SubmitHandler)))
+		t.Server.HTTP.Path("/loki/api/v1/query_jobs").Methods("GET").Handler(asyncQueriesMiddleware.Wrap(http.HandlerFunc(t.asyncQueries.ListHandler)))
+		t.Server.HTTP.Path("/loki/api/v1/query_jobs/{id}").Methods("GET").Handler(asyncQueriesMiddleware.Wrap(http.HandlerFunc(t.asyncQueries.GetHandler)))
+		t.Server.HTTP.Path("/loki/api/v1/query_jobs/{id}").Methods("DELETE").Handler(asyncQueriesMiddleware.Wrap(http.HandlerFunc(t.asyncQueries.DeleteHandler)))
+		t.Server.HTTP.Path("/loki/api/v1/query_jobs/{id}/results").Methods("GET").Handler(asyncQueriesMiddleware.Wrap(http.HandlerFunc(t.asyncQueries.ResultsHandler)))
+	}
+
+	if t.Cfg.QueryRange.Export.Enabled {
+		exporter := queryrange.NewExporter(t.Cfg.QueryRange.Export, t.Overrides, queryHandler, util_log.Logger)
+		exportMiddleware := middleware.Merge(
+			httpreq.ExtractQueryTagsMiddleware(),
+			serverutil.RecoveryHTTPMiddleware,
+			t.HTTPAuthMiddleware,
+		)
+		t.Server.HTTP.Path("/loki/api/v1/export").Methods("GET", "POST").Handler(exportMiddleware.Wrap(http.HandlerFunc(exporter.Handler)))
+	}
+
+	startAsyncQueries := func(ctx context.Context) error {
+		if t.asyncQueries == nil {
+			return nil
+		}
+		return services.StartAndAwaitRunning(ctx, t.asyncQueries)
+	}
+	stopAsyncQueries := func() {
+		if t.asyncQueries == nil {
+			return
+		}
+		if err := services.StopAndAwaitTerminated(context.Background(), t.asyncQueries); err != nil {
+			level.Warn(util_log.Logger).Log("msg", "failed to stop async queries service", "err", err)
+		}
+	}
+
 	if t.frontend == nil {
-		return services.NewIdleService(nil, func(_ error) error {
+		return services.NewIdleService(startAsyncQueries, func(_ error) error {
+			stopAsyncQueries()
 			if t.stopper != nil {
 				t.stopper.Stop()
 				t.stopper = nil
@@ -1394,8 +1489,14 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	}
 
 	return services.NewIdleService(func(ctx context.Context) error {
-		return services.StartAndAwaitRunning(ctx, t.frontend)
+		if err := services.StartAndAwaitRunning(ctx, t.frontend); err != nil {
+			return err
+		}
+		return startAsyncQueries(ctx)
 	}, func(_ error) error {
+		// Stop the async queries first, as their jobs run through the frontend.
+		stopAsyncQueries()
+
 		// Log but not return in case of error, so that other following dependencies
 		// are stopped too.
 		if err := services.StopAndAwaitTerminated(context.Background(), t.frontend); err != nil {
@@ -22,6 +22,8 @@ type Config struct {
 	TLS          tls.ClientConfig `yaml:"tail_tls_config"`
 
 	SupportParquetEncoding bool `yaml:"support_parquet_encoding" doc:"description=Support 'application/vnd.apache.parquet' content type in HTTP responses."`
+	SupportArrowEncoding   bool `yaml:"support_arrow_encoding"`
+	ArrowFlightEnabled     bool `yaml:"arrow_flight_enabled"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
@@ -36,4 +38,6 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&cfg.TailProxyURL, "frontend.tail-proxy-url", "", "URL of querier for tail proxy.")
 
 	f.BoolVar(&cfg.CompressResponses, "frontend.support-parquet-encoding", false, "Support 'application/vnd.apache.parquet' content type in HTTP responses.")
+	f.BoolVar(&cfg.SupportArrowEncoding, "frontend.support-arrow-encoding", false, "Support 'application/vnd.apache.arrow.stream' content type in HTTP responses.")
+	f.BoolVar(&cfg.ArrowFlightEnabled, "frontend.arrow-flight-enabled", false, "Enable the Arrow Flight gRPC service streaming the results of the queries as Arrow record batches.")
 }
@@ -50,6 +50,7 @@ type Config struct {
 
 type Limits interface {
 	PatternIngesterTokenizableJSONFields(userID string) []string
+	PatternIngesterTokenizerConfig(userID string) TokenizerConfig
 }
 
 func createLogClusterCache(maxSize int, onEvict func(int, *LogCluster)) *LogClusterCache {
@@ -155,16 +156,31 @@ func New(tenantID string, config *Config, limits Limits, format string, metrics
 
 	limiter := newLimiter(config.MaxEvictionRatio)
 
+	tokenizerCfg := limits.PatternIngesterTokenizerConfig(tenantID)
+	tokenizerFormat := format
+	switch tokenizerCfg.Tokenizer {
+	case TokenizerJSON:
+		tokenizerFormat = FormatJSON
+	case TokenizerLogfmt:
+		tokenizerFormat = FormatLogfmt
+	case TokenizerPunctuation:
+		tokenizerFormat = FormatUnknown
+	}
+
 	var tokenizer LineTokenizer
-	switch format {
+	switch tokenizerFormat {
 	case FormatJSON:
 		fieldsToTokenize := limits.PatternIngesterTokenizableJSONFields(tenantID)
-		tokenizer = newJSONTokenizer(config.ParamString, config.MaxAllowedLineLength, fieldsToTokenize)
+		jsonTokenizer := newJSONTokenizer(config.ParamString, config.MaxAllowedLineLength, fieldsToTokenize)
+		jsonTokenizer.withDelimiters(tokenizerCfg.ExtraDelimiters, tokenizerCfg.ExcludedDelimiters)
+		tokenizer = jsonTokenizer
 	case FormatLogfmt:
 		tokenizer = newLogfmtTokenizer(config.ParamString, config.MaxAllowedLineLength)
 	default:
-		tokenizer = newPunctuationTokenizer(config.MaxAllowedLineLength)
+		tokenizer = newPunctuationTokenizer(config.MaxAllowedLineLength).
+			withDelimiters(tokenizerCfg.ExtraDelimiters, tokenizerCfg.ExcludedDelimiters)
 	}
+	tokenizer = newMaskingTokenizer(tokenizer, tokenizerCfg)
 
 	d.idToCluster = createLogClusterCache(config.MaxClusters, func(int, *LogCluster) {
 		if metrics != nil {
@@ -690,8 +690,13 @@ func countNodes(node *Node) int {
 
 type fakeLimits struct {
 	Limits
+	tokenizerConfig TokenizerConfig
 }
 
 func (f *fakeLimits) PatternIngesterTokenizableJSONFields(_ string) []string {
 	return []string{"log", "message", "msg", "msg_", "_msg", "content"}
 }
+
+func (f *fakeLimits) PatternIngesterTokenizerConfig(_ string) TokenizerConfig {
+	return f.tokenizerConfig
+}
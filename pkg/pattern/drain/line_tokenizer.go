@@ -57,6 +57,23 @@ func newPunctuationTokenizer(maxLineLength int) *punctuationTokenizer {
 	}
 }
 
+// withDelimiters splits tokens on the extra characters and stops splitting on
+// the excluded ones.
+func (p *punctuationTokenizer) withDelimiters(extra, excluded string) *punctuationTokenizer {
+	for _, char := range extra {
+		if char < 128 {
+			p.includeDelimiters[char] = 1
+			p.excludeDelimiters[char] = 0
+		}
+	}
+	for _, char := range excluded {
+		if char < 128 {
+			p.excludeDelimiters[char] = 1
+		}
+	}
+	return p
+}
+
 func (p *punctuationTokenizer) Tokenize(
 	line string,
 	tokens []string,
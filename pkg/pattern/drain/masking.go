@@ -0,0 +1,271 @@
+package drain
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	TokenizerAuto        = "auto"
+	TokenizerPunctuation = "punctuation"
+	TokenizerLogfmt      = "logfmt"
+	TokenizerJSON        = "json"
+
+	MaskIP       = "ip"
+	MaskUUID     = "uuid"
+	MaskHex      = "hex"
+	MaskDuration = "duration"
+)
+
+var (
+	builtinMasks = map[string]func(string) bool{
+		MaskIP:       isIP,
+		MaskUUID:     isUUID,
+		MaskHex:      isHexID,
+		MaskDuration: isDuration,
+	}
+
+	maskNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// TokenizerConfig configures how log lines are split into tokens and which tokens
+// are replaced by typed placeholders before they are used to train Drain.
+type TokenizerConfig struct {
+	Tokenizer          string         `yaml:"tokenizer" json:"tokenizer" doc:"description=Tokenizer used to split log lines. Supported values are 'auto', 'punctuation', 'logfmt' and 'json'. An empty value or 'auto' picks the tokenizer based on the log format detected from the first line of the stream."`
+	ExtraDelimiters    string         `yaml:"extra_delimiters" json:"extra_delimiters" doc:"description=ASCII punctuation or symbol characters that split tokens in addition to spaces and punctuation. Only used by the punctuation and json tokenizers."`
+	ExcludedDelimiters string         `yaml:"excluded_delimiters" json:"excluded_delimiters" doc:"description=ASCII punctuation characters that do not split tokens. Only used by the punctuation and json tokenizers."`
+	Masks              []string       `yaml:"masks,omitempty" json:"masks,omitempty" doc:"description=Built-in masks replacing matching tokens with a typed placeholder before training. Supported values are 'ip', 'uuid', 'hex' and 'duration'. Masks are applied in order and the first matching mask wins."`
+	CustomMasks        []*MaskingRule `yaml:"custom_masks,omitempty" json:"custom_masks,omitempty" doc:"description=Regular expression masks replacing matching tokens with the placeholder '<name>'. Custom masks are applied before built-in masks. Example:\n custom_masks:\n - name: session\n   regex: 'sess-[a-z0-9]+'"`
+}
+
+// MaskingRule replaces any token fully matching Regex with the placeholder <Name>.
+type MaskingRule struct {
+	Name  string `yaml:"name" json:"name" doc:"description=Name of the placeholder. Must be a valid identifier."`
+	Regex string `yaml:"regex" json:"regex" doc:"description=Regular expression a token must fully match to be masked."`
+
+	regex *regexp.Regexp // populated during validation.
+}
+
+func (c *TokenizerConfig) Validate() error {
+	switch c.Tokenizer {
+	case "", TokenizerAuto, TokenizerPunctuation, TokenizerLogfmt, TokenizerJSON:
+	default:
+		return fmt.Errorf("invalid pattern ingester tokenizer %q", c.Tokenizer)
+	}
+
+	for _, delimiters := range []string{c.ExtraDelimiters, c.ExcludedDelimiters} {
+		for _, r := range delimiters {
+			if r >= 128 || unicode.IsLetter(r) || unicode.IsNumber(r) {
+				return fmt.Errorf("pattern ingester delimiters must be ASCII punctuation or symbols, got %q", r)
+			}
+		}
+	}
+
+	for _, mask := range c.Masks {
+		if _, ok := builtinMasks[mask]; !ok {
+			return fmt.Errorf("unknown pattern ingester mask %q", mask)
+		}
+	}
+
+	for _, rule := range c.CustomMasks {
+		if rule.Name == "_" || !maskNameRegex.MatchString(rule.Name) {
+			return fmt.Errorf("invalid pattern ingester mask name %q", rule.Name)
+		}
+		re, err := regexp.Compile("^(?:" + rule.Regex + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid regex for pattern ingester mask %q: %w", rule.Name, err)
+		}
+		rule.regex = re
+	}
+	return nil
+}
+
+type mask struct {
+	placeholder string
+	matches     func(string) bool
+}
+
+// masker replaces tokens matching one of its masks with the mask's placeholder.
+type masker struct {
+	masks        []mask
+	placeholders map[string]string
+}
+
+func newMasker(cfg TokenizerConfig) *masker {
+	m := &masker{
+		placeholders: make(map[string]string),
+	}
+	for _, rule := range cfg.CustomMasks {
+		// Rules are compiled during validation.
+		if rule.regex == nil {
+			continue
+		}
+		m.add(rule.Name, rule.regex.MatchString)
+	}
+	for _, name := range cfg.Masks {
+		if matches, ok := builtinMasks[name]; ok {
+			m.add(name, matches)
+		}
+	}
+	return m
+}
+
+func (m *masker) add(name string, matches func(string) bool) {
+	placeholder := "<" + name + ">"
+	m.masks = append(m.masks, mask{placeholder: placeholder, matches: matches})
+	m.placeholders[placeholder] = name
+}
+
+func (m *masker) Mask(token string) string {
+	for _, mask := range m.masks {
+		if mask.matches(token) {
+			return mask.placeholder
+		}
+	}
+	return token
+}
+
+// maskingTokenizer masks the tokens produced by the wrapped tokenizer.
+type maskingTokenizer struct {
+	LineTokenizer
+	masker *masker
+}
+
+func newMaskingTokenizer(tokenizer LineTokenizer, cfg TokenizerConfig) LineTokenizer {
+	if len(cfg.Masks) == 0 && len(cfg.CustomMasks) == 0 {
+		return tokenizer
+	}
+	return &maskingTokenizer{
+		LineTokenizer: tokenizer,
+		masker:        newMasker(cfg),
+	}
+}
+
+func (t *maskingTokenizer) Tokenize(
+	line string,
+	tokens []string,
+	state interface{},
+	linesDropped *prometheus.CounterVec,
+) ([]string, interface{}) {
+	tokens, state = t.LineTokenizer.Tokenize(line, tokens, state, linesDropped)
+	for i, token := range tokens {
+		tokens[i] = t.masker.Mask(token)
+	}
+	return tokens, state
+}
+
+// Join numbers repeated placeholders, so that the resulting pattern remains
+// a valid LogQL pattern expression, e.g. `<ip> -> <ip_2>`.
+func (t *maskingTokenizer) Join(tokens []string, state interface{}) string {
+	var (
+		seen    map[string]int
+		renamed []string
+	)
+	for i, token := range tokens {
+		name, ok := t.masker.placeholders[token]
+		if !ok {
+			continue
+		}
+		if seen == nil {
+			seen = make(map[string]int)
+		}
+		seen[token]++
+		if seen[token] == 1 {
+			continue
+		}
+		if renamed == nil {
+			renamed = make([]string, len(tokens))
+			copy(renamed, tokens)
+		}
+		renamed[i] = "<" + name + "_" + strconv.Itoa(seen[token]) + ">"
+	}
+	if renamed != nil {
+		tokens = renamed
+	}
+	return t.LineTokenizer.Join(tokens, state)
+}
+
+func isIP(s string) bool {
+	if len(s) < 3 || s == "::" {
+		return false
+	}
+	if _, err := netip.ParseAddr(s); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddrPort(s)
+	return err == nil
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHexChar(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isHexID matches 0x prefixed hex numbers and hex strings of at least 8
+// characters containing both digits and letters, like trace or commit IDs.
+func isHexID(s string) bool {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+		if len(s) == 0 {
+			return false
+		}
+		for i := 0; i < len(s); i++ {
+			if !isHexChar(s[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if len(s) < 8 {
+		return false
+	}
+	var digits, letters bool
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case isHexChar(c):
+			letters = true
+		default:
+			return false
+		}
+	}
+	return digits && letters
+}
+
+func isHexChar(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isDuration(s string) bool {
+	if len(s) < 2 || s[0] < '0' || s[0] > '9' {
+		return false
+	}
+	if last := s[len(s)-1]; last >= '0' && last <= '9' {
+		return false
+	}
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
@@ -0,0 +1,141 @@
+package drain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinMasks(t *testing.T) {
+	for _, tc := range []struct {
+		mask    string
+		matches []string
+		misses  []string
+	}{
+		{
+			mask:    MaskIP,
+			matches: []string{"10.0.0.1", "192.168.1.254:8080", "::1", "fe80::1ff:fe23:4567:890a", "[::1]:443"},
+			misses:  []string{"::", "10.0.0", "1.2.3.4.5", "foo::bar", "v1.2.3"},
+		},
+		{
+			mask:    MaskUUID,
+			matches: []string{"123e4567-e89b-12d3-a456-426614174000", "123E4567-E89B-12D3-A456-426614174000"},
+			misses:  []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"},
+		},
+		{
+			mask:    MaskHex,
+			matches: []string{"0x1f", "0XDEADBEEF", "4bf92f3577b34da6", "a3ce929d0e0e4736"},
+			misses:  []string{"0x", "deadbeef", "12345678", "4bf92f3", "4bf92f3577b34dag"},
+		},
+		{
+			mask:    MaskDuration,
+			matches: []string{"12ms", "1.5s", "1h2m3s", "250µs"},
+			misses:  []string{"0", "ms", "-1s", "12", "1d"},
+		},
+	} {
+		t.Run(tc.mask, func(t *testing.T) {
+			matches := builtinMasks[tc.mask]
+			for _, s := range tc.matches {
+				require.True(t, matches(s), s)
+			}
+			for _, s := range tc.misses {
+				require.False(t, matches(s), s)
+			}
+		})
+	}
+}
+
+func TestTokenizerConfig_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  TokenizerConfig
+		err  string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			cfg: TokenizerConfig{
+				Tokenizer:       TokenizerPunctuation,
+				ExtraDelimiters: "|",
+				Masks:           []string{MaskIP, MaskUUID},
+				CustomMasks:     []*MaskingRule{{Name: "session", Regex: "sess-[a-z0-9]+"}},
+			},
+		},
+		{
+			name: "unknown tokenizer",
+			cfg:  TokenizerConfig{Tokenizer: "spaces"},
+			err:  `invalid pattern ingester tokenizer "spaces"`,
+		},
+		{
+			name: "letter delimiter",
+			cfg:  TokenizerConfig{ExtraDelimiters: "x"},
+			err:  `pattern ingester delimiters must be ASCII punctuation or symbols, got 'x'`,
+		},
+		{
+			name: "unknown mask",
+			cfg:  TokenizerConfig{Masks: []string{"email"}},
+			err:  `unknown pattern ingester mask "email"`,
+		},
+		{
+			name: "invalid mask name",
+			cfg:  TokenizerConfig{CustomMasks: []*MaskingRule{{Name: "my-mask", Regex: "foo"}}},
+			err:  `invalid pattern ingester mask name "my-mask"`,
+		},
+		{
+			name: "invalid regex",
+			cfg:  TokenizerConfig{CustomMasks: []*MaskingRule{{Name: "foo", Regex: "("}}},
+			err:  `invalid regex for pattern ingester mask "foo"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestDrain_TrainWithMasks(t *testing.T) {
+	cfg := TokenizerConfig{
+		Masks:       []string{MaskIP, MaskDuration},
+		CustomMasks: []*MaskingRule{{Name: "path", Regex: `/api/v1/users/\d+`}},
+	}
+	require.NoError(t, cfg.Validate())
+
+	d := New(testTenant, DefaultConfig(), &fakeLimits{tokenizerConfig: cfg}, "", nil)
+	lines := []string{
+		`10.0.0.1 -> 10.0.0.2 GET /api/v1/users/1 took 12ms`,
+		`10.0.0.3 -> 10.0.0.4 GET /api/v1/users/2 took 1.5s`,
+		`192.168.1.1 -> 192.168.1.2 GET /api/v1/users/3 took 3ms`,
+	}
+	ts := time.Now()
+	for _, line := range lines {
+		d.Train(line, ts.UnixNano())
+	}
+
+	clusters := d.Clusters()
+	require.Len(t, clusters, 1)
+	require.Equal(t, `<ip> -> <ip_2> GET <path> took <duration>`, clusters[0].String())
+}
+
+func TestDrain_TokenizerOverride(t *testing.T) {
+	line := `{"msg":"user=foo|action=login"}`
+
+	d := New(testTenant, DefaultConfig(), &fakeLimits{}, FormatJSON, nil)
+	tokens, _ := d.tokenizer.Tokenize(line, nil, nil, nil)
+	require.Equal(t, []string{"user", "=", "foo|action", "=", "login"}, tokens)
+
+	d = New(testTenant, DefaultConfig(), &fakeLimits{tokenizerConfig: TokenizerConfig{ExtraDelimiters: "|"}}, FormatJSON, nil)
+	tokens, _ = d.tokenizer.Tokenize(line, nil, nil, nil)
+	require.Equal(t, []string{"user", "=", "foo", "|", "action", "=", "login"}, tokens)
+
+	d = New(testTenant, DefaultConfig(), &fakeLimits{tokenizerConfig: TokenizerConfig{Tokenizer: TokenizerPunctuation}}, FormatJSON, nil)
+	tokens, _ = d.tokenizer.Tokenize(line, nil, nil, nil)
+	require.Equal(t, "{", tokens[0])
+}
@@ -354,6 +354,10 @@ func (f *fakeLimits) PatternIngesterTokenizableJSONFields(_ string) []string {
 	return []string{"log", "message", "msg", "msg_", "_msg", "content"}
 }
 
+func (f *fakeLimits) PatternIngesterTokenizerConfig(_ string) drain.TokenizerConfig {
+	return drain.TokenizerConfig{}
+}
+
 func (f *fakeLimits) MetricAggregationEnabled(_ string) bool {
 	return f.metricAggregationEnabled
 }
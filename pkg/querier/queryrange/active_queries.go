@@ -0,0 +1,222 @@
+package queryrange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+)
+
+var (
+	errActiveQueryNotFound = httpgrpc.Errorf(http.StatusNotFound, "active query not found")
+	errActiveQueryCanceled = errors.New("the query was canceled")
+)
+
+type activeQueryContextKey int
+
+const activeQueryIDKey activeQueryContextKey = 0
+
+// ActiveQuery is a query running on the query frontend.
+type ActiveQuery struct {
+	ID        string    `json:"id"`
+	Tenant    string    `json:"tenant"`
+	Query     string    `json:"query"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	StartedAt time.Time `json:"started_at"`
+
+	// SubqueriesInFlight are the splits and shards of the query sent to the queriers and not
+	// completed yet.
+	SubqueriesInFlight  int   `json:"subqueries_in_flight"`
+	SubqueriesCompleted int   `json:"subqueries_completed"`
+	BytesProcessed      int64 `json:"bytes_processed"`
+	Canceled            bool  `json:"canceled"`
+}
+
+type activeQuery struct {
+	ActiveQuery
+	cancel context.CancelCauseFunc
+}
+
+// ActiveQueries is the registry of the queries running on a query frontend. Each query frontend
+// replica only tracks the queries it runs. Canceling a query cancels its context, which cancels
+// its subqueries queued in the scheduler or executed by the queriers.
+type ActiveQueries struct {
+	logger log.Logger
+	now    func() time.Time
+
+	mtx     sync.Mutex
+	queries map[string]*activeQuery
+
+	canceled *prometheus.CounterVec
+}
+
+// NewActiveQueries creates the registry of the queries running on a query frontend.
+func NewActiveQueries(registerer prometheus.Registerer, metricsNamespace string, logger log.Logger) *ActiveQueries {
+	return &ActiveQueries{
+		logger:  log.With(logger, "component", "active-queries"),
+		now:     time.Now,
+		queries: make(map[string]*activeQuery),
+		canceled: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_active_queries_canceled_total",
+			Help:      "Total number of running queries canceled through the active queries API.",
+		}, []string{"tenant"}),
+	}
+}
+
+// Middleware registers the log and metric queries while they are executed by the next handler.
+func (a *ActiveQueries) Middleware() base.Middleware {
+	return base.MiddlewareFunc(func(next base.Handler) base.Handler {
+		return base.HandlerFunc(func(ctx context.Context, r base.Request) (base.Response, error) {
+			switch r.(type) {
+			case *LokiRequest, *LokiInstantRequest:
+			default:
+				return next.Do(ctx, r)
+			}
+			if _, ok := ctx.Value(activeQueryIDKey).(string); ok {
+				return next.Do(ctx, r)
+			}
+			tenantIDs, err := tenant.TenantIDs(ctx)
+			if err != nil {
+				return next.Do(ctx, r)
+			}
+
+			ctx, cancel := context.WithCancelCause(ctx)
+			defer cancel(nil)
+			q := &activeQuery{
+				ActiveQuery: ActiveQuery{
+					ID:        uuid.NewString(),
+					Tenant:    tenant.JoinTenantIDs(tenantIDs),
+					Query:     r.GetQuery(),
+					Start:     r.GetStart(),
+					End:       r.GetEnd(),
+					StartedAt: a.now().UTC(),
+				},
+				cancel: cancel,
+			}
+			a.mtx.Lock()
+			a.queries[q.ID] = q
+			a.mtx.Unlock()
+			defer func() {
+				a.mtx.Lock()
+				delete(a.queries, q.ID)
+				a.mtx.Unlock()
+			}()
+
+			res, err := next.Do(context.WithValue(ctx, activeQueryIDKey, q.ID), r)
+			if err != nil && errors.Is(context.Cause(ctx), errActiveQueryCanceled) {
+				return nil, httpgrpc.Errorf(serverutil.StatusClientClosedRequest, "%s", context.Cause(ctx).Error())
+			}
+			return res, err
+		})
+	})
+}
+
+// DownstreamMiddleware tracks the subqueries of the registered queries sent to the queriers.
+func (a *ActiveQueries) DownstreamMiddleware() base.Middleware {
+	return base.MiddlewareFunc(func(next base.Handler) base.Handler {
+		return base.HandlerFunc(func(ctx context.Context, r base.Request) (base.Response, error) {
+			id, ok := ctx.Value(activeQueryIDKey).(string)
+			if !ok {
+				return next.Do(ctx, r)
+			}
+
+			a.update(id, func(q *ActiveQuery) { q.SubqueriesInFlight++ })
+			res, err := next.Do(ctx, r)
+			a.update(id, func(q *ActiveQuery) {
+				q.SubqueriesInFlight--
+				q.SubqueriesCompleted++
+				if statistics, ok := responseStatistics(res); ok && err == nil {
+					q.BytesProcessed += statistics.Summary.TotalBytesProcessed
+				}
+			})
+			return res, err
+		})
+	})
+}
+
+func (a *ActiveQueries) update(id string, f func(*ActiveQuery)) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if q, ok := a.queries[id]; ok {
+		f(&q.ActiveQuery)
+	}
+}
+
+// List returns the running queries of a tenant, the oldest first.
+func (a *ActiveQueries) List(tenantID string) []ActiveQuery {
+	a.mtx.Lock()
+	queries := make([]ActiveQuery, 0, len(a.queries))
+	for _, q := range a.queries {
+		if q.Tenant == tenantID {
+			queries = append(queries, q.ActiveQuery)
+		}
+	}
+	a.mtx.Unlock()
+
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].StartedAt.Equal(queries[j].StartedAt) {
+			return queries[i].ID < queries[j].ID
+		}
+		return queries[i].StartedAt.Before(queries[j].StartedAt)
+	})
+	return queries
+}
+
+// Cancel cancels a running query of a tenant and its subqueries.
+func (a *ActiveQueries) Cancel(tenantID, id string) error {
+	a.mtx.Lock()
+	q, ok := a.queries[id]
+	ok = ok && q.Tenant == tenantID
+	if ok {
+		q.Canceled = true
+	}
+	a.mtx.Unlock()
+	if !ok {
+		return errActiveQueryNotFound
+	}
+
+	level.Info(a.logger).Log("msg", "canceling query", "id", id, "tenant", q.Tenant, "query", q.Query)
+	a.canceled.WithLabelValues(q.Tenant).Inc()
+	q.cancel(errActiveQueryCanceled)
+	return nil
+}
+
+// ListHandler lists the running queries of the tenant of the request.
+func (a *ActiveQueries) ListHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := asyncQueryTenant(r.Context())
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	writeStatusResponse(w, http.StatusOK, a.List(tenantID))
+}
+
+// CancelHandler cancels a running query of the tenant of the request.
+func (a *ActiveQueries) CancelHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := asyncQueryTenant(r.Context())
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	if err := a.Cancel(tenantID, mux.Vars(r)["id"]); err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
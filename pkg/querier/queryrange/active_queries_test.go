@@ -0,0 +1,171 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+)
+
+// newTestActiveQueries returns a registry and a handler executing the queries as two
+// subqueries sent to h.
+func newTestActiveQueries(h base.Handler) (*ActiveQueries, base.Handler) {
+	a := NewActiveQueries(prometheus.NewRegistry(), "loki", log.NewNopLogger())
+	downstream := a.DownstreamMiddleware().Wrap(h)
+	split := base.HandlerFunc(func(ctx context.Context, r base.Request) (base.Response, error) {
+		req := r.(*LokiRequest)
+		mid := req.StartTs.Add(req.EndTs.Sub(req.StartTs) / 2)
+		if _, err := downstream.Do(ctx, req.WithStartEnd(req.StartTs, mid)); err != nil {
+			return nil, err
+		}
+		return downstream.Do(ctx, req.WithStartEnd(mid, req.EndTs))
+	})
+	return a, a.Middleware().Wrap(split)
+}
+
+func TestActiveQueries_List(t *testing.T) {
+	h := &asyncQueryHandler{block: make(chan struct{})}
+	a, handler := newTestActiveQueries(h)
+
+	done := make(chan error)
+	for _, tenantID := range []string{"1", "2"} {
+		ctx := user.InjectOrgID(context.Background(), tenantID)
+		go func() {
+			_, err := handler.Do(ctx, newAsyncQueryRequest(`{app="foo"}`, 100, logproto.FORWARD))
+			done <- err
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return len(a.List("1")) == 1 && len(a.List("2")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	queries := a.List("1")
+	require.Len(t, queries, 1)
+	require.Equal(t, "1", queries[0].Tenant)
+	require.Equal(t, `{app="foo"}`, queries[0].Query)
+	require.Equal(t, 1, queries[0].SubqueriesInFlight)
+	require.Equal(t, 0, queries[0].SubqueriesCompleted)
+
+	// Unblock the first subquery of each query.
+	h.block <- struct{}{}
+	h.block <- struct{}{}
+	require.Eventually(t, func() bool {
+		queries := append(a.List("1"), a.List("2")...)
+		return len(queries) == 2 && queries[0].SubqueriesCompleted == 1 && queries[1].SubqueriesCompleted == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, int64(100), a.List("2")[0].BytesProcessed)
+
+	close(h.block)
+	require.NoError(t, <-done)
+	require.NoError(t, <-done)
+	require.Empty(t, a.List("1"))
+	require.Empty(t, a.List("2"))
+}
+
+func TestActiveQueries_Cancel(t *testing.T) {
+	h := &asyncQueryHandler{block: make(chan struct{})}
+	a, handler := newTestActiveQueries(h)
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	done := make(chan error)
+	go func() {
+		_, err := handler.Do(ctx, newAsyncQueryRequest(`{app="foo"}`, 100, logproto.FORWARD))
+		done <- err
+	}()
+	var queries []ActiveQuery
+	require.Eventually(t, func() bool {
+		queries = a.List("1")
+		return len(queries) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The queries are only canceled by their tenant.
+	err := a.Cancel("2", queries[0].ID)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusNotFound), resp.Code)
+
+	require.NoError(t, a.Cancel("1", queries[0].ID))
+	err = <-done
+	resp, ok = httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(serverutil.StatusClientClosedRequest), resp.Code)
+	require.Contains(t, string(resp.Body), "the query was canceled")
+	require.Equal(t, 0, h.Calls())
+	require.Empty(t, a.List("1"))
+
+	err = a.Cancel("1", queries[0].ID)
+	resp, ok = httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusNotFound), resp.Code)
+}
+
+func TestActiveQueries_Handlers(t *testing.T) {
+	h := &asyncQueryHandler{block: make(chan struct{})}
+	a, handler := newTestActiveQueries(h)
+
+	router := mux.NewRouter()
+	router.Path("/frontend/active_queries").Methods(http.MethodGet).HandlerFunc(a.ListHandler)
+	router.Path("/frontend/active_queries/{id}").Methods(http.MethodDelete).HandlerFunc(a.CancelHandler)
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	done := make(chan error)
+	go func() {
+		_, err := handler.Do(ctx, newAsyncQueryRequest(`{app="foo"}`, 100, logproto.FORWARD))
+		done <- err
+	}()
+	require.Eventually(t, func() bool {
+		return len(a.List("1")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	serve := func(method, path, tenantID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if tenantID != "" {
+			r = r.WithContext(user.InjectOrgID(r.Context(), tenantID))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	// The handlers are scoped to the tenant of the request.
+	w := serve(http.MethodGet, "/frontend/active_queries", "")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(http.MethodGet, "/frontend/active_queries", "2")
+	require.Equal(t, http.StatusOK, w.Code)
+	var res struct {
+		Status string        `json:"status"`
+		Data   []ActiveQuery `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Empty(t, res.Data)
+
+	w = serve(http.MethodGet, "/frontend/active_queries", "1")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 1)
+	require.Equal(t, "1", res.Data[0].Tenant)
+
+	w = serve(http.MethodDelete, "/frontend/active_queries/"+res.Data[0].ID, "2")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(http.MethodDelete, "/frontend/active_queries/"+res.Data[0].ID, "1")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Error(t, <-done)
+
+	w = serve(http.MethodDelete, "/frontend/active_queries/"+res.Data[0].ID, "1")
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
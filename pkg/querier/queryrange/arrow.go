@@ -0,0 +1,262 @@
+package queryrange
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+)
+
+// The record batches of the Arrow encoded responses are limited in rows and in
+// bytes, to be streamed in gRPC messages below the default max message size.
+const (
+	arrowRecordBatchSize  = 8192
+	arrowRecordBatchBytes = 1 << 20
+)
+
+func encodeResponseArrow(ctx context.Context, res queryrangebase.Response) (*http.Response, error) {
+	sp, _ := opentracing.StartSpanFromContext(ctx, "codec.EncodeResponse")
+	defer sp.Finish()
+
+	var buf bytes.Buffer
+
+	err := encodeResponseArrowTo(ctx, res, &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := http.Response{
+		Header: http.Header{
+			"Content-Type": []string{ArrowType},
+		},
+		Body:       io.NopCloser(&buf),
+		StatusCode: http.StatusOK,
+	}
+	return &resp, nil
+}
+
+// encodeResponseArrowTo writes the response as an Arrow IPC stream.
+func encodeResponseArrowTo(_ context.Context, res queryrangebase.Response, w io.Writer) error {
+	return encodeResponseRowsTo(res, arrowStreamSink(w), "Arrow")
+}
+
+// arrowStreamSink writes the rows to w as an Arrow IPC stream.
+func arrowStreamSink(w io.Writer) rowSink {
+	return rowSink{
+		arrow: func(schema *arrow.Schema) (arrowRecordWriter, error) {
+			return ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(memory.DefaultAllocator)), nil
+		},
+	}
+}
+
+type arrowRecordWriter interface {
+	Write(rec arrow.Record) error
+	Close() error
+}
+
+// arrowRowWriter writes rows as Arrow record batches of at most
+// arrowRecordBatchSize rows and about arrowRecordBatchBytes bytes.
+type arrowRowWriter[T any] struct {
+	w       arrowRecordWriter
+	b       *array.RecordBuilder
+	columns []arrowColumn
+	rows    int
+	bytes   int
+}
+
+func newArrowRowWriter[T any](newRecordWriter func(*arrow.Schema) (arrowRecordWriter, error)) (*arrowRowWriter[T], error) {
+	schema, columns, err := arrowSchemaOf(reflect.TypeOf(new(T)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	w, err := newRecordWriter(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &arrowRowWriter[T]{
+		w:       w,
+		b:       array.NewRecordBuilder(memory.DefaultAllocator, schema),
+		columns: columns,
+	}, nil
+}
+
+func (w *arrowRowWriter[T]) Write(rows []T) (int, error) {
+	for i := range rows {
+		row := reflect.ValueOf(&rows[i]).Elem()
+		for j, c := range w.columns {
+			w.bytes += c.append(w.b.Field(j), row.Field(c.field))
+		}
+		w.rows++
+		if w.rows == arrowRecordBatchSize || w.bytes >= arrowRecordBatchBytes {
+			if err := w.flush(); err != nil {
+				return i, err
+			}
+		}
+	}
+	return len(rows), nil
+}
+
+func (w *arrowRowWriter[T]) flush() error {
+	rec := w.b.NewRecord()
+	defer rec.Release()
+	w.rows, w.bytes = 0, 0
+	return w.w.Write(rec)
+}
+
+// Flush writes the buffered rows as a record batch.
+func (w *arrowRowWriter[T]) Flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+	return w.flush()
+}
+
+// Close writes the remaining rows and closes the stream. A stream without rows
+// still contains the schema.
+func (w *arrowRowWriter[T]) Close() error {
+	defer w.b.Release()
+	if w.rows > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	return w.w.Close()
+}
+
+// arrowColumn appends a field of the rows to a column, and returns the number
+// of bytes appended.
+type arrowColumn struct {
+	field  int
+	append func(b array.Builder, v reflect.Value) int
+}
+
+// arrowSchemaOf returns the Arrow schema equivalent to the Parquet schema of
+// the row type t, and how to append the fields of the rows to the columns.
+func arrowSchemaOf(t reflect.Type) (*arrow.Schema, []arrowColumn, error) {
+	fields := make([]arrow.Field, 0, t.NumField())
+	columns := make([]arrowColumn, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, options, _ := strings.Cut(f.Tag.Get("parquet"), ",")
+		if name == "" {
+			name = f.Name
+		}
+
+		var (
+			dataType arrow.DataType
+			appendFn func(b array.Builder, v reflect.Value) int
+		)
+		switch kind := f.Type.Kind(); {
+		case kind == reflect.Int64:
+			if unit, ok := arrowTimestampUnit(options); ok {
+				dataType = &arrow.TimestampType{Unit: unit, TimeZone: "UTC"}
+				appendFn = func(b array.Builder, v reflect.Value) int {
+					b.(*array.TimestampBuilder).Append(arrow.Timestamp(v.Int()))
+					return 8
+				}
+				break
+			}
+			dataType = arrow.PrimitiveTypes.Int64
+			appendFn = func(b array.Builder, v reflect.Value) int {
+				b.(*array.Int64Builder).Append(v.Int())
+				return 8
+			}
+		case kind == reflect.Uint64:
+			dataType = arrow.PrimitiveTypes.Uint64
+			appendFn = func(b array.Builder, v reflect.Value) int {
+				b.(*array.Uint64Builder).Append(v.Uint())
+				return 8
+			}
+		case kind == reflect.Float64:
+			dataType = arrow.PrimitiveTypes.Float64
+			appendFn = func(b array.Builder, v reflect.Value) int {
+				b.(*array.Float64Builder).Append(v.Float())
+				return 8
+			}
+		case kind == reflect.Bool:
+			dataType = arrow.FixedWidthTypes.Boolean
+			appendFn = func(b array.Builder, v reflect.Value) int {
+				b.(*array.BooleanBuilder).Append(v.Bool())
+				return 1
+			}
+		case kind == reflect.String:
+			dataType = arrow.BinaryTypes.String
+			appendFn = func(b array.Builder, v reflect.Value) int {
+				b.(*array.StringBuilder).Append(v.String())
+				return v.Len()
+			}
+		case f.Type == reflect.TypeOf([]string(nil)):
+			dataType = arrow.ListOf(arrow.BinaryTypes.String)
+			appendFn = appendArrowStrings
+		case f.Type == reflect.TypeOf(map[string]string(nil)):
+			dataType = arrow.MapOf(arrow.BinaryTypes.String, arrow.BinaryTypes.String)
+			appendFn = appendArrowStringMap
+		default:
+			return nil, nil, fmt.Errorf("unsupported type %s of the field %s of %s", f.Type, f.Name, t)
+		}
+
+		fields = append(fields, arrow.Field{Name: name, Type: dataType})
+		columns = append(columns, arrowColumn{field: i, append: appendFn})
+	}
+	return arrow.NewSchema(fields, nil), columns, nil
+}
+
+func arrowTimestampUnit(options string) (arrow.TimeUnit, bool) {
+	for _, option := range strings.Split(options, ",") {
+		switch option {
+		case "timestamp(millisecond)":
+			return arrow.Millisecond, true
+		case "timestamp(microsecond)":
+			return arrow.Microsecond, true
+		case "timestamp(nanosecond)":
+			return arrow.Nanosecond, true
+		}
+	}
+	return 0, false
+}
+
+func appendArrowStrings(b array.Builder, v reflect.Value) int {
+	lb := b.(*array.ListBuilder)
+	lb.Append(true)
+	vb := lb.ValueBuilder().(*array.StringBuilder)
+	var n int
+	for _, s := range v.Interface().([]string) {
+		vb.Append(s)
+		n += len(s)
+	}
+	return n
+}
+
+// appendArrowStringMap appends the entries of a map sorted by key, so that the
+// same labels are always encoded the same way.
+func appendArrowStringMap(b array.Builder, v reflect.Value) int {
+	m := v.Interface().(map[string]string)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	mb := b.(*array.MapBuilder)
+	mb.Append(true)
+	kb, ib := mb.KeyBuilder().(*array.StringBuilder), mb.ItemBuilder().(*array.StringBuilder)
+	var n int
+	for _, k := range keys {
+		kb.Append(k)
+		ib.Append(m[k])
+		n += len(k) + len(m[k])
+	}
+	return n
+}
@@ -0,0 +1,179 @@
+package queryrange
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+)
+
+// readArrowStream returns the schema of an Arrow IPC stream, and its rows as JSON.
+func readArrowStream(t *testing.T, r *ipc.Reader) (*arrow.Schema, string, int) {
+	defer r.Release()
+
+	rows := []json.RawMessage{}
+	var records int
+	for r.Next() {
+		records++
+		b, err := r.Record().MarshalJSON()
+		require.NoError(t, err)
+		var batch []json.RawMessage
+		require.NoError(t, json.Unmarshal(b, &batch))
+		rows = append(rows, batch...)
+	}
+	require.NoError(t, r.Err())
+
+	b, err := json.Marshal(rows)
+	require.NoError(t, err)
+	return r.Schema(), string(b), records
+}
+
+func TestEncodeResponseArrow(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		res            queryrangebase.Response
+		expectedSchema string
+		expectedRows   string
+	}{
+		{
+			name: "metrics",
+			res: &LokiPromResponse{
+				Response: &queryrangebase.PrometheusResponse{
+					Status: loghttp.QueryStatusSuccess,
+					Data: queryrangebase.PrometheusData{
+						ResultType: loghttp.ResultTypeMatrix,
+						Result: []queryrangebase.SampleStream{{
+							Labels:  []logproto.LabelAdapter{{Name: "foo", Value: "bar"}, {Name: "app", Value: "baz"}},
+							Samples: []logproto.LegacySample{{TimestampMs: 1000, Value: 1.5}, {TimestampMs: 2000, Value: 2}},
+						}},
+					},
+				},
+			},
+			expectedSchema: `timestamp: type=timestamp[ms, tz=UTC], labels: type=map<utf8, utf8, items_nullable>, value: type=float64`,
+			expectedRows: `[
+				{"labels":[{"key":"app","value":"baz"},{"key":"foo","value":"bar"}],"timestamp":"1970-01-01 00:00:01Z","value":1.5},
+				{"labels":[{"key":"app","value":"baz"},{"key":"foo","value":"bar"}],"timestamp":"1970-01-01 00:00:02Z","value":2}
+			]`,
+		},
+		{
+			name: "logs",
+			res: &LokiResponse{
+				Status:    loghttp.QueryStatusSuccess,
+				Direction: logproto.FORWARD,
+				Data: LokiData{
+					ResultType: loghttp.ResultTypeStream,
+					Result: []logproto.Stream{{
+						Labels:  `{app="foo"}`,
+						Entries: []logproto.Entry{{Timestamp: time.Unix(0, 1), Line: "line 1"}, {Timestamp: time.Unix(0, 2), Line: "line 2"}},
+					}},
+				},
+			},
+			expectedSchema: `timestamp: type=timestamp[ns, tz=UTC], labels: type=map<utf8, utf8, items_nullable>, line: type=utf8`,
+			expectedRows: `[
+				{"labels":[{"key":"app","value":"foo"}],"line":"line 1","timestamp":"1970-01-01 00:00:00.000000001Z"},
+				{"labels":[{"key":"app","value":"foo"}],"line":"line 2","timestamp":"1970-01-01 00:00:00.000000002Z"}
+			]`,
+		},
+		{
+			name:           "labels",
+			res:            &LokiLabelNamesResponse{Status: loghttp.QueryStatusSuccess, Data: []string{"app", "env"}},
+			expectedSchema: `value: type=utf8`,
+			expectedRows:   `[{"value":"app"},{"value":"env"}]`,
+		},
+		{
+			name: "volume",
+			res: &VolumeResponse{Response: &logproto.VolumeResponse{Volumes: []logproto.Volume{
+				{Name: `{app="foo"}`, Volume: 38},
+			}}},
+			expectedSchema: `name: type=utf8, volume: type=uint64`,
+			expectedRows:   `[{"name":"{app=\"foo\"}","volume":38}]`,
+		},
+		{
+			name: "detected fields",
+			res: &DetectedFieldsResponse{Response: &logproto.DetectedFieldsResponse{Fields: []*logproto.DetectedField{
+				{Label: "status", Type: logproto.DetectedFieldInt, Cardinality: 3, Parsers: []string{"json", "logfmt"}},
+			}}},
+			expectedSchema: `label: type=utf8, type: type=utf8, cardinality: type=uint64, parsers: type=list<item: utf8, nullable>`,
+			expectedRows:   `[{"cardinality":3,"label":"status","parsers":["json","logfmt"],"type":"int"}]`,
+		},
+		{
+			name: "patterns",
+			res: &QueryPatternsResponse{Response: &logproto.QueryPatternsResponse{Series: []*logproto.PatternSeries{
+				{Pattern: "GET <_>", Samples: []*logproto.PatternSample{{Timestamp: 1000, Value: 2}}},
+			}}},
+			expectedSchema: `timestamp: type=timestamp[ms, tz=UTC], pattern: type=utf8, value: type=int64`,
+			expectedRows:   `[{"pattern":"GET <_>","timestamp":"1970-01-01 00:00:01Z","value":2}]`,
+		},
+		{
+			name: "explain",
+			res: &ExplainResponse{Status: loghttp.QueryStatusSuccess, Data: ExplainData{Splits: []ExplainSplit{
+				{Start: time.Unix(0, 0), End: time.Unix(3600, 0), Cache: "miss", Query: `{app="foo"}`, Sharded: true},
+			}}},
+			expectedSchema: `start: type=timestamp[ns, tz=UTC], end: type=timestamp[ns, tz=UTC], cache: type=utf8, query: type=utf8, sharded: type=bool, bytes_per_shard: type=uint64`,
+			expectedRows:   `[{"bytes_per_shard":0,"cache":"miss","end":"1970-01-01 01:00:00Z","query":"{app=\"foo\"}","sharded":true,"start":"1970-01-01 00:00:00Z"}]`,
+		},
+		{
+			name:           "empty",
+			res:            &LokiSeriesResponse{Status: loghttp.QueryStatusSuccess},
+			expectedSchema: `labels: type=map<utf8, utf8, items_nullable>`,
+			expectedRows:   `[]`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, encodeResponseArrowTo(context.Background(), tc.res, &buf))
+
+			r, err := ipc.NewReader(&buf)
+			require.NoError(t, err)
+			schema, rows, _ := readArrowStream(t, r)
+
+			fields := make([]string, 0, len(schema.Fields()))
+			for _, f := range schema.Fields() {
+				fields = append(fields, f.String())
+			}
+			require.Equal(t, tc.expectedSchema, strings.ReplaceAll(strings.Join(fields, ", "), "name=", ""))
+			require.JSONEq(t, tc.expectedRows, rows)
+		})
+	}
+}
+
+func TestEncodeResponseArrow_RecordBatches(t *testing.T) {
+	values := make([]string, 2*arrowRecordBatchSize+1)
+	for i := range values {
+		values[i] = "value"
+	}
+	var buf bytes.Buffer
+	require.NoError(t, encodeResponseArrowTo(context.Background(), &LokiLabelNamesResponse{Data: values}, &buf))
+
+	r, err := ipc.NewReader(&buf)
+	require.NoError(t, err)
+	_, _, records := readArrowStream(t, r)
+	require.Equal(t, 3, records)
+
+	// the record batches are limited in bytes too.
+	line := strings.Repeat("a", arrowRecordBatchBytes/2)
+	buf.Reset()
+	require.NoError(t, encodeResponseArrowTo(context.Background(), &LokiLabelNamesResponse{Data: []string{line, line, line}}, &buf))
+
+	r, err = ipc.NewReader(&buf)
+	require.NoError(t, err)
+	_, _, records = readArrowStream(t, r)
+	require.Equal(t, 2, records)
+}
+
+func TestEncodeResponseArrow_Unsupported(t *testing.T) {
+	var buf bytes.Buffer
+	err := encodeResponseArrowTo(context.Background(), &TopKSketchesResponse{}, &buf)
+	require.Equal(t, serverutil.UserError("request does not support Arrow responses"), err)
+}
@@ -0,0 +1,821 @@
+package queryrange
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/tenant"
+	"github.com/grafana/dskit/user"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	queryrange_limits "github.com/grafana/loki/v3/pkg/querier/queryrange/limits"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+// States of the asynchronous query jobs.
+const (
+	AsyncQueryStateQueued    = "queued"
+	AsyncQueryStateRunning   = "running"
+	AsyncQueryStateSucceeded = "succeeded"
+	AsyncQueryStateFailed    = "failed"
+)
+
+// Formats of the results of the asynchronous query jobs.
+const (
+	AsyncQueryFormatJSON    = "json"
+	AsyncQueryFormatParquet = "parquet"
+	AsyncQueryFormatArrow   = "arrow"
+)
+
+const asyncQueryJobObject = "job.json"
+
+var errAsyncQueryNotFound = httpgrpc.Errorf(http.StatusNotFound, "async query job not found")
+
+// AsyncQueriesConfig configures the asynchronous query jobs of the query frontend.
+type AsyncQueriesConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	Store            string        `yaml:"store"`
+	Prefix           string        `yaml:"prefix"`
+	SplitInterval    time.Duration `yaml:"split_interval"`
+	SplitParallelism int           `yaml:"split_parallelism"`
+	CleanupInterval  time.Duration `yaml:"cleanup_interval"`
+}
+
+// RegisterFlags adds the flags required to configure this flag set.
+func (cfg *AsyncQueriesConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "querier.async-queries.enabled", false, "Enable the asynchronous query jobs API of the query frontend, which runs range queries in the background and persists their results to the object store.")
+	f.StringVar(&cfg.Store, "querier.async-queries.store", "", "Object store the jobs and their results are persisted to. Supported values are the object stores of the schema config, for example s3, gcs, azure or filesystem.")
+	f.StringVar(&cfg.Prefix, "querier.async-queries.prefix", "async-queries", "Prefix of the objects of the jobs in the object store.")
+	f.DurationVar(&cfg.SplitInterval, "querier.async-queries.split-interval", time.Hour, "Interval the time range of the jobs is split by. Each split is executed as a range query by the query frontend, and is subject to its query timeout.")
+	f.IntVar(&cfg.SplitParallelism, "querier.async-queries.split-parallelism", 4, "Maximum number of splits of a metric query job executed concurrently. The splits of log query jobs are executed sequentially, until the limit of the query is reached.")
+	f.DurationVar(&cfg.CleanupInterval, "querier.async-queries.cleanup-interval", 10*time.Minute, "Interval at which the expired jobs are deleted from the object store.")
+}
+
+// Validate validates the config.
+func (cfg *AsyncQueriesConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Store == "" {
+		return errors.New("querier.async-queries.store must be set when asynchronous queries are enabled")
+	}
+	if cfg.SplitInterval <= 0 {
+		return errors.New("querier.async-queries.split-interval must be greater than 0")
+	}
+	if cfg.SplitParallelism <= 0 {
+		return errors.New("querier.async-queries.split-parallelism must be greater than 0")
+	}
+	if cfg.CleanupInterval <= 0 {
+		return errors.New("querier.async-queries.cleanup-interval must be greater than 0")
+	}
+	return nil
+}
+
+// AsyncQueryLimits are the per-tenant limits of the asynchronous query jobs.
+type AsyncQueryLimits queryrange_limits.AsyncQueryLimits
+
+// AsyncQueryJob is the persisted state of an asynchronous query job.
+type AsyncQueryJob struct {
+	ID        string         `json:"id"`
+	Query     string         `json:"query"`
+	Start     time.Time      `json:"start"`
+	End       time.Time      `json:"end"`
+	Step      model.Duration `json:"step,omitempty"`
+	Interval  model.Duration `json:"interval,omitempty"`
+	Limit     uint32         `json:"limit"`
+	Direction string         `json:"direction"`
+	Format    string         `json:"format"`
+
+	State      string           `json:"state"`
+	Error      string           `json:"error,omitempty"`
+	Progress   AsyncQueryStatus `json:"progress"`
+	CreatedAt  time.Time        `json:"created_at"`
+	StartedAt  *time.Time       `json:"started_at,omitempty"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	ExpiresAt  *time.Time       `json:"expires_at,omitempty"`
+}
+
+// AsyncQueryStatus is the progress of an asynchronous query job.
+type AsyncQueryStatus struct {
+	SplitsTotal int `json:"splits_total"`
+	// SplitsCompleted is the number of splits whose results are persisted.
+	SplitsCompleted int    `json:"splits_completed"`
+	BytesProcessed  int64  `json:"bytes_processed"`
+	LinesProcessed  int64  `json:"lines_processed"`
+	ResultsBytes    uint64 `json:"results_bytes,omitempty"`
+}
+
+func (j *AsyncQueryJob) finished() bool {
+	return j.State == AsyncQueryStateSucceeded || j.State == AsyncQueryStateFailed
+}
+
+func (j *AsyncQueryJob) expired(now time.Time) bool {
+	return j.ExpiresAt != nil && !now.Before(*j.ExpiresAt)
+}
+
+type asyncQueryJob struct {
+	AsyncQueryJob
+
+	tenantID string
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	// putMtx orders the updates of the persisted state of the job.
+	putMtx sync.Mutex
+}
+
+// AsyncQueries runs range queries in the background through the query frontend, splitting them
+// by time to report their progress, and persists their results to the object store. The results
+// of each split are persisted as soon as the split completes, and are only encoded in the format
+// of the job when they are read.
+//
+// The jobs are queued and run by the query frontend they are submitted to, and their limits
+// are enforced by each query frontend independently.
+type AsyncQueries struct {
+	services.Service
+
+	cfg     AsyncQueriesConfig
+	limits  AsyncQueryLimits
+	handler base.Handler
+	store   client.ObjectClient
+	logger  log.Logger
+	now     func() time.Time
+
+	// ctx is canceled when the service stops, to stop the running jobs.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mtx    sync.Mutex
+	jobs   map[string]*asyncQueryJob
+	active map[string]int
+	freed  chan struct{}
+	wg     sync.WaitGroup
+
+	jobsTotal *prometheus.CounterVec
+	jobsState *prometheus.GaugeVec
+}
+
+// NewAsyncQueries creates the asynchronous query jobs of the query frontend, executing their
+// splits with the handler.
+func NewAsyncQueries(cfg AsyncQueriesConfig, limits AsyncQueryLimits, handler base.Handler, store client.ObjectClient, registerer prometheus.Registerer, metricsNamespace string, logger log.Logger) *AsyncQueries {
+	q := &AsyncQueries{
+		cfg:     cfg,
+		limits:  limits,
+		handler: handler,
+		store:   store,
+		logger:  log.With(logger, "component", "async-queries"),
+		now:     time.Now,
+		jobs:    make(map[string]*asyncQueryJob),
+		active:  make(map[string]int),
+		freed:   make(chan struct{}),
+		jobsTotal: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_async_query_jobs_total",
+			Help:      "Total number of asynchronous query jobs completed per state.",
+		}, []string{"state"}),
+		jobsState: promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_async_query_jobs",
+			Help:      "Number of queued and running asynchronous query jobs per state.",
+		}, []string{"state"}),
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	q.Service = services.NewTimerService(cfg.CleanupInterval, nil, q.cleanup, q.stopping)
+	return q
+}
+
+func (q *AsyncQueries) stopping(_ error) error {
+	q.cancel()
+	q.wg.Wait()
+	q.store.Stop()
+	return nil
+}
+
+// Submit starts a job running the query of a request, and returns its initial state.
+func (q *AsyncQueries) Submit(ctx context.Context, req *LokiRequest, format string) (*AsyncQueryJob, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	tenantID := tenant.JoinTenantIDs(tenantIDs)
+	for _, id := range tenantIDs {
+		if q.limits.AsyncQueryMaxConcurrentJobs(id) <= 0 {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, "asynchronous queries are disabled for tenant %s", id)
+		}
+	}
+
+	switch format {
+	case "":
+		format = AsyncQueryFormatJSON
+	case AsyncQueryFormatJSON, AsyncQueryFormatParquet, AsyncQueryFormatArrow:
+	default:
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "unsupported format %q: supported formats are %s, %s and %s", format, AsyncQueryFormatJSON, AsyncQueryFormatParquet, AsyncQueryFormatArrow)
+	}
+
+	job := &asyncQueryJob{
+		AsyncQueryJob: AsyncQueryJob{
+			ID:        uuid.NewString(),
+			Query:     req.Query,
+			Start:     req.StartTs,
+			End:       req.EndTs,
+			Step:      model.Duration(time.Duration(req.Step) * time.Millisecond),
+			Interval:  model.Duration(time.Duration(req.Interval) * time.Millisecond),
+			Limit:     req.Limit,
+			Direction: req.Direction.String(),
+			Format:    format,
+			State:     AsyncQueryStateQueued,
+			CreatedAt: q.now().UTC(),
+		},
+		tenantID: tenantID,
+		done:     make(chan struct{}),
+	}
+
+	// The job outlives the request, so it only keeps the tenant and query tags of its context.
+	jobCtx := user.InjectOrgID(q.ctx, tenantID)
+	if tags := httpreq.ExtractQueryTagsFromContext(ctx); tags != "" {
+		jobCtx = httpreq.InjectQueryTags(jobCtx, tags)
+	}
+	jobCtx, job.cancel = context.WithCancel(jobCtx)
+
+	// The job is registered before it is persisted, so that the jobs submitted concurrently
+	// are counted in the queue limit.
+	key := q.key(tenantID, job.ID)
+	q.mtx.Lock()
+	if limit := validation.SmallestPositiveIntPerTenant(tenantIDs, q.limits.AsyncQueryMaxQueuedJobs); limit > 0 && q.queued(tenantID) >= limit {
+		q.mtx.Unlock()
+		job.cancel()
+		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, "too many queued asynchronous query jobs for tenant %s: the limit is %d", tenantID, limit)
+	}
+	q.jobs[key] = job
+	state := job.AsyncQueryJob
+	q.mtx.Unlock()
+
+	if err := q.put(ctx, tenantID, state); err != nil {
+		q.mtx.Lock()
+		delete(q.jobs, key)
+		q.mtx.Unlock()
+		job.cancel()
+		close(job.done)
+		return nil, err
+	}
+
+	q.wg.Add(1)
+	q.jobsState.WithLabelValues(AsyncQueryStateQueued).Inc()
+	go q.run(jobCtx, job, tenantIDs, req)
+	return &state, nil
+}
+
+func (q *AsyncQueries) run(ctx context.Context, job *asyncQueryJob, tenantIDs []string, req *LokiRequest) {
+	defer q.wg.Done()
+	defer close(job.done)
+	defer func() {
+		q.mtx.Lock()
+		delete(q.jobs, q.key(job.tenantID, job.ID))
+		q.mtx.Unlock()
+	}()
+
+	jobErr := q.acquire(ctx, job.tenantID, tenantIDs)
+	q.jobsState.WithLabelValues(AsyncQueryStateQueued).Dec()
+	if jobErr == nil {
+		q.jobsState.WithLabelValues(AsyncQueryStateRunning).Inc()
+		jobErr = q.execute(ctx, job, tenantIDs, req)
+		q.jobsState.WithLabelValues(AsyncQueryStateRunning).Dec()
+		q.release(job.tenantID)
+	}
+
+	if ctx.Err() != nil && q.ctx.Err() == nil {
+		// The job was canceled and its objects deleted.
+		return
+	}
+
+	// The final state is persisted even when the service stops.
+	putCtx, cancel := context.WithTimeout(user.InjectOrgID(context.Background(), job.tenantID), time.Minute)
+	defer cancel()
+	var state AsyncQueryJob
+	err := q.persist(putCtx, job, func(j *AsyncQueryJob) {
+		now := q.now().UTC()
+		expiresAt := now.Add(validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, q.limits.AsyncQueryResultsTTL))
+		j.State = AsyncQueryStateSucceeded
+		if jobErr != nil {
+			j.State = AsyncQueryStateFailed
+			j.Error = jobErr.Error()
+			if q.ctx.Err() != nil {
+				j.Error = "the query frontend running the job stopped"
+			}
+		}
+		j.FinishedAt = &now
+		j.ExpiresAt = &expiresAt
+		state = *j
+	})
+	q.jobsTotal.WithLabelValues(state.State).Inc()
+	if state.Error != "" {
+		level.Warn(q.logger).Log("msg", "async query job failed", "tenant", job.tenantID, "job", job.ID, "err", state.Error)
+	}
+	if err != nil {
+		level.Error(q.logger).Log("msg", "failed to persist async query job", "tenant", job.tenantID, "job", job.ID, "err", err)
+	}
+}
+
+// queued returns the number of queued jobs of the tenant. It must be called with the
+// lock held.
+func (q *AsyncQueries) queued(tenantID string) int {
+	n := 0
+	for _, job := range q.jobs {
+		if job.tenantID == tenantID && job.State == AsyncQueryStateQueued {
+			n++
+		}
+	}
+	return n
+}
+
+// acquire waits until fewer jobs of the tenant than its limit are running on this query
+// frontend.
+func (q *AsyncQueries) acquire(ctx context.Context, tenantID string, tenantIDs []string) error {
+	for {
+		q.mtx.Lock()
+		limit := validation.SmallestPositiveIntPerTenant(tenantIDs, q.limits.AsyncQueryMaxConcurrentJobs)
+		if q.active[tenantID] < limit {
+			q.active[tenantID]++
+			q.mtx.Unlock()
+			return nil
+		}
+		freed := q.freed
+		q.mtx.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-freed:
+		}
+	}
+}
+
+func (q *AsyncQueries) release(tenantID string) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.active[tenantID]--
+	if q.active[tenantID] <= 0 {
+		delete(q.active, tenantID)
+	}
+	close(q.freed)
+	q.freed = make(chan struct{})
+}
+
+func (q *AsyncQueries) execute(ctx context.Context, job *asyncQueryJob, tenantIDs []string, req *LokiRequest) error {
+	splits := splitInOrder(q.limits, q.now().UTC(), tenantIDs, req, q.cfg.SplitInterval)
+	now := q.now().UTC()
+	if err := q.persist(ctx, job, func(j *AsyncQueryJob) {
+		j.State = AsyncQueryStateRunning
+		j.StartedAt = &now
+		j.Progress.SplitsTotal = len(splits)
+	}); err != nil {
+		return err
+	}
+
+	timeoutCapture := func(id string) time.Duration { return q.limits.QueryTimeout(ctx, id) }
+	timeout := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, timeoutCapture)
+
+	do := func(ctx context.Context, i int) (base.Response, error) {
+		splitCtx := ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			splitCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return q.handler.Do(splitCtx, splits[i])
+	}
+
+	// store persists the results of a split as soon as it completes, so that the results of
+	// the whole query are never kept in memory.
+	store := func(ctx context.Context, i int, res base.Response) error {
+		wrapped, err := QueryResponseWrap(res)
+		if err != nil {
+			return err
+		}
+		b, err := wrapped.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := q.store.PutObject(ctx, q.resultsKey(job.tenantID, job.ID, i), bytes.NewReader(b)); err != nil {
+			return errors.Wrap(err, "failed to persist the results")
+		}
+		return q.persist(ctx, job, func(j *AsyncQueryJob) {
+			j.Progress.SplitsCompleted++
+			j.Progress.ResultsBytes += uint64(len(b))
+			if s, ok := responseStatistics(res); ok {
+				j.Progress.BytesProcessed += s.Summary.TotalBytesProcessed
+				j.Progress.LinesProcessed += s.Summary.TotalLinesProcessed
+			}
+		})
+	}
+
+	if _, ok := req.Plan.AST.(syntax.SampleExpr); ok {
+		return concurrency.ForEachJob(ctx, len(splits), q.cfg.SplitParallelism, func(ctx context.Context, i int) error {
+			res, err := do(ctx, i)
+			if err != nil {
+				return err
+			}
+			return store(ctx, i, res)
+		})
+	}
+
+	// The splits of log queries are executed in the direction of the query, until
+	// enough lines are returned. The lines of the last split above the limit are dropped.
+	var lines uint32
+	for i := range splits {
+		res, err := do(ctx, i)
+		if err != nil {
+			return err
+		}
+		res, lines = limitSplitResponse(res, req.Limit, lines)
+		if err := store(ctx, i, res); err != nil {
+			return err
+		}
+		if req.Limit > 0 && lines >= req.Limit {
+			break
+		}
+	}
+	return nil
+}
+
+func responseStatistics(res base.Response) (stats.Result, bool) {
+	switch r := res.(type) {
+	case *LokiResponse:
+		return r.Statistics, true
+	case *LokiPromResponse:
+		return r.Statistics, true
+	default:
+		return stats.Result{}, false
+	}
+}
+
+// update applies a change to the state of a running job, and returns the new state.
+func (q *AsyncQueries) update(job *asyncQueryJob, f func(*AsyncQueryJob)) AsyncQueryJob {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	f(&job.AsyncQueryJob)
+	return job.AsyncQueryJob
+}
+
+// persist applies a change to the state of a running job, and persists the new state.
+func (q *AsyncQueries) persist(ctx context.Context, job *asyncQueryJob, f func(*AsyncQueryJob)) error {
+	job.putMtx.Lock()
+	defer job.putMtx.Unlock()
+	return q.put(ctx, job.tenantID, q.update(job, f))
+}
+
+// Get returns the state of a job, from the object store if it is not running on this query frontend.
+func (q *AsyncQueries) Get(ctx context.Context, id string) (*AsyncQueryJob, error) {
+	tenantID, err := asyncQueryTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mtx.Lock()
+	job, ok := q.jobs[q.key(tenantID, id)]
+	if ok {
+		state := job.AsyncQueryJob
+		q.mtx.Unlock()
+		return &state, nil
+	}
+	q.mtx.Unlock()
+
+	state, err := q.get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if state.expired(q.now()) {
+		return nil, errAsyncQueryNotFound
+	}
+	return state, nil
+}
+
+// List returns the jobs of the tenant, the most recent first.
+func (q *AsyncQueries) List(ctx context.Context) ([]AsyncQueryJob, error) {
+	tenantID, err := asyncQueryTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, prefixes, err := q.store.List(ctx, q.tenantPrefix(tenantID), "/")
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]AsyncQueryJob, 0, len(prefixes))
+	for _, p := range prefixes {
+		id := path.Base(string(p))
+		state, err := q.Get(ctx, id)
+		if errors.Is(err, errAsyncQueryNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *state)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs, nil
+}
+
+// Results returns the results of a succeeded job and their content type.
+func (q *AsyncQueries) Results(ctx context.Context, id string) (io.ReadCloser, string, error) {
+	state, err := q.Get(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if state.State != AsyncQueryStateSucceeded {
+		return nil, "", httpgrpc.Errorf(http.StatusConflict, "async query job is %s", state.State)
+	}
+
+	tenantID, err := asyncQueryTenant(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The results of the splits are encoded in the format of the job while they are read.
+	pr, pw := io.Pipe()
+	var (
+		w           splitResultsWriter
+		contentType = "application/json; charset=UTF-8"
+	)
+	switch state.Format {
+	case AsyncQueryFormatParquet:
+		w, contentType = newRowsSplitResultsWriter(rowSink{parquet: pw}), ParquetType
+	case AsyncQueryFormatArrow:
+		w, contentType = newRowsSplitResultsWriter(arrowStreamSink(pw)), ArrowType
+	default:
+		w = newJSONSplitResultsWriter(pw)
+	}
+	go func() {
+		pw.CloseWithError(q.writeResults(ctx, tenantID, state, w))
+	}()
+	return pr, contentType, nil
+}
+
+// writeResults reads the results of the splits of a job in order and writes them to w.
+func (q *AsyncQueries) writeResults(ctx context.Context, tenantID string, state *AsyncQueryJob, w splitResultsWriter) error {
+	for i := 0; i < state.Progress.SplitsCompleted; i++ {
+		res, err := q.readResults(ctx, tenantID, state.ID, i)
+		if err != nil {
+			return err
+		}
+		if err := w.Write(res); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func (q *AsyncQueries) readResults(ctx context.Context, tenantID, id string, i int) (base.Response, error) {
+	rc, _, err := q.store.GetObject(ctx, q.resultsKey(tenantID, id, i))
+	if err != nil {
+		if q.store.IsObjectNotFoundErr(err) {
+			return nil, errAsyncQueryNotFound
+		}
+		return nil, err
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	var wrapped QueryResponse
+	if err := wrapped.Unmarshal(b); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the results")
+	}
+	return QueryResponseUnwrap(&wrapped)
+}
+
+// Delete cancels a job if it is running, and deletes its state and results.
+func (q *AsyncQueries) Delete(ctx context.Context, id string) error {
+	tenantID, err := asyncQueryTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	q.mtx.Lock()
+	job, running := q.jobs[q.key(tenantID, id)]
+	q.mtx.Unlock()
+	if running {
+		job.cancel()
+		<-job.done
+	}
+
+	state, err := q.get(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	return q.delete(ctx, tenantID, state)
+}
+
+// cleanup deletes the jobs which results expired.
+func (q *AsyncQueries) cleanup(ctx context.Context) error {
+	_, tenants, err := q.store.List(ctx, q.cfg.Prefix+"/", "/")
+	if err != nil {
+		level.Warn(q.logger).Log("msg", "failed to list async query tenants", "err", err)
+		return nil
+	}
+
+	now := q.now()
+	for _, t := range tenants {
+		tenantID := path.Base(string(t))
+		_, jobs, err := q.store.List(ctx, q.tenantPrefix(tenantID), "/")
+		if err != nil {
+			level.Warn(q.logger).Log("msg", "failed to list async query jobs", "tenant", tenantID, "err", err)
+			continue
+		}
+		for _, j := range jobs {
+			state, err := q.get(ctx, tenantID, path.Base(string(j)))
+			if err != nil {
+				if !errors.Is(err, errAsyncQueryNotFound) {
+					level.Warn(q.logger).Log("msg", "failed to get async query job", "tenant", tenantID, "job", path.Base(string(j)), "err", err)
+				}
+				continue
+			}
+			if !state.finished() || !state.expired(now) {
+				continue
+			}
+			if err := q.delete(ctx, tenantID, state); err != nil {
+				level.Warn(q.logger).Log("msg", "failed to delete expired async query job", "tenant", tenantID, "job", state.ID, "err", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (q *AsyncQueries) get(ctx context.Context, tenantID, id string) (*AsyncQueryJob, error) {
+	rc, _, err := q.store.GetObject(ctx, q.jobKey(tenantID, id))
+	if err != nil {
+		if q.store.IsObjectNotFoundErr(err) {
+			return nil, errAsyncQueryNotFound
+		}
+		return nil, err
+	}
+	defer rc.Close()
+
+	var state AsyncQueryJob
+	if err := json.NewDecoder(rc).Decode(&state); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the async query job")
+	}
+	return &state, nil
+}
+
+func (q *AsyncQueries) put(ctx context.Context, tenantID string, state AsyncQueryJob) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := q.store.PutObject(ctx, q.jobKey(tenantID, state.ID), bytes.NewReader(b)); err != nil {
+		return errors.Wrap(err, "failed to persist the async query job")
+	}
+	return nil
+}
+
+func (q *AsyncQueries) delete(ctx context.Context, tenantID string, state *AsyncQueryJob) error {
+	objects, _, err := q.store.List(ctx, path.Join(q.cfg.Prefix, tenantID, state.ID)+"/", "")
+	if err != nil {
+		return err
+	}
+	// The state of the job is deleted last, so that a failed deletion is retried by the cleanup.
+	jobKey := q.jobKey(tenantID, state.ID)
+	for _, o := range objects {
+		if o.Key == jobKey {
+			continue
+		}
+		if err := q.store.DeleteObject(ctx, o.Key); err != nil && !q.store.IsObjectNotFoundErr(err) {
+			return err
+		}
+	}
+	if err := q.store.DeleteObject(ctx, jobKey); err != nil && !q.store.IsObjectNotFoundErr(err) {
+		return err
+	}
+	return nil
+}
+
+func (q *AsyncQueries) key(tenantID, id string) string {
+	return tenantID + "/" + id
+}
+
+func (q *AsyncQueries) tenantPrefix(tenantID string) string {
+	return path.Join(q.cfg.Prefix, tenantID) + "/"
+}
+
+func (q *AsyncQueries) jobKey(tenantID, id string) string {
+	return path.Join(q.cfg.Prefix, tenantID, id, asyncQueryJobObject)
+}
+
+func (q *AsyncQueries) resultsKey(tenantID, id string, split int) string {
+	return path.Join(q.cfg.Prefix, tenantID, id, "results", strconv.Itoa(split))
+}
+
+func asyncQueryTenant(ctx context.Context) (string, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return "", httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	return tenant.JoinTenantIDs(tenantIDs), nil
+}
+
+type statusResponse struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
+func writeStatusResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(statusResponse{Status: loghttp.QueryStatusSuccess, Data: data})
+}
+
+// SubmitHandler submits a job running the range query of the request. The format parameter
+// selects the format of the results, json, parquet or arrow.
+func (q *AsyncQueries) SubmitHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error()), w)
+		return
+	}
+	req, err := parseRangeQuery(r)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error()), w)
+		return
+	}
+	job, err := q.Submit(r.Context(), req, strings.ToLower(r.Form.Get("format")))
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	writeStatusResponse(w, http.StatusAccepted, job)
+}
+
+// ListHandler lists the jobs of the tenant.
+func (q *AsyncQueries) ListHandler(w http.ResponseWriter, r *http.Request) {
+	jobs, err := q.List(r.Context())
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	writeStatusResponse(w, http.StatusOK, jobs)
+}
+
+// GetHandler returns the state and the progress of a job.
+func (q *AsyncQueries) GetHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := q.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	writeStatusResponse(w, http.StatusOK, job)
+}
+
+// ResultsHandler returns the results of a succeeded job.
+func (q *AsyncQueries) ResultsHandler(w http.ResponseWriter, r *http.Request) {
+	rc, contentType, err := q.Results(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rc); err != nil {
+		level.Warn(q.logger).Log("msg", "failed to write async query results", "err", err)
+	}
+}
+
+// DeleteHandler cancels a job and deletes its results.
+func (q *AsyncQueries) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if err := q.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
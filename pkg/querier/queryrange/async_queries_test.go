@@ -0,0 +1,389 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
+)
+
+type asyncQueryLimits struct {
+	fakeLimits
+	maxConcurrentJobs int
+	maxQueuedJobs     int
+}
+
+func (l asyncQueryLimits) AsyncQueryMaxConcurrentJobs(string) int {
+	return l.maxConcurrentJobs
+}
+
+func (l asyncQueryLimits) AsyncQueryMaxQueuedJobs(string) int {
+	return l.maxQueuedJobs
+}
+
+func (l asyncQueryLimits) AsyncQueryResultsTTL(string) time.Duration {
+	return time.Hour
+}
+
+// asyncQueryHandler returns a line at the start of each log query, and a sample at the start
+// of each metric query.
+type asyncQueryHandler struct {
+	mtx   sync.Mutex
+	calls int
+	block chan struct{}
+}
+
+func (h *asyncQueryHandler) Do(ctx context.Context, r base.Request) (base.Response, error) {
+	if h.block != nil {
+		select {
+		case <-h.block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	h.mtx.Lock()
+	h.calls++
+	h.mtx.Unlock()
+
+	req := r.(*LokiRequest)
+	statistics := stats.Result{Summary: stats.Summary{TotalBytesProcessed: 100, TotalLinesProcessed: 10}}
+	if _, ok := req.Plan.AST.(syntax.SampleExpr); ok {
+		return &LokiPromResponse{
+			Response: &base.PrometheusResponse{
+				Status: loghttp.QueryStatusSuccess,
+				Data: base.PrometheusData{
+					ResultType: loghttp.ResultTypeMatrix,
+					Result: []base.SampleStream{{
+						Labels:  []logproto.LabelAdapter{{Name: "app", Value: "foo"}},
+						Samples: []logproto.LegacySample{{TimestampMs: req.StartTs.UnixMilli(), Value: 1}},
+					}},
+				},
+			},
+			Statistics: statistics,
+		}, nil
+	}
+	return &LokiResponse{
+		Status:    loghttp.QueryStatusSuccess,
+		Direction: req.Direction,
+		Limit:     req.Limit,
+		Version:   uint32(loghttp.VersionV1),
+		Data: LokiData{
+			ResultType: loghttp.ResultTypeStream,
+			Result: []logproto.Stream{{
+				Labels:  `{app="foo"}`,
+				Entries: []logproto.Entry{{Timestamp: req.StartTs, Line: req.StartTs.Format(time.RFC3339)}},
+			}},
+		},
+		Statistics: statistics,
+	}, nil
+}
+
+func (h *asyncQueryHandler) Calls() int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.calls
+}
+
+func newTestAsyncQueries(t *testing.T, maxConcurrentJobs int, h base.Handler) *AsyncQueries {
+	cfg := AsyncQueriesConfig{
+		Enabled:          true,
+		Prefix:           "async-queries",
+		SplitInterval:    time.Hour,
+		SplitParallelism: 2,
+		CleanupInterval:  time.Hour,
+	}
+	limits := asyncQueryLimits{fakeLimits: fakeLimits{queryTimeout: time.Minute}, maxConcurrentJobs: maxConcurrentJobs}
+	q := NewAsyncQueries(cfg, limits, h, testutils.NewInMemoryObjectClient(), prometheus.NewRegistry(), "loki", log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), q))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), q))
+	})
+	return q
+}
+
+func newAsyncQueryRequest(query string, limit uint32, direction logproto.Direction) *LokiRequest {
+	return &LokiRequest{
+		Query:     query,
+		Limit:     limit,
+		Step:      (5 * time.Minute).Milliseconds(),
+		StartTs:   testTime.Add(-4 * time.Hour).Truncate(time.Hour),
+		EndTs:     testTime.Truncate(time.Hour),
+		Direction: direction,
+		Path:      "/loki/api/v1/query_jobs",
+		Plan: &plan.QueryPlan{
+			AST: syntax.MustParseExpr(query),
+		},
+	}
+}
+
+func waitAsyncQuery(t *testing.T, ctx context.Context, q *AsyncQueries, id, state string) *AsyncQueryJob {
+	var job *AsyncQueryJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = q.Get(ctx, id)
+		require.NoError(t, err)
+		return job.State == state
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestAsyncQueries_LogQuery(t *testing.T) {
+	h := &asyncQueryHandler{}
+	q := newTestAsyncQueries(t, 1, h)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	job, err := q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 2, logproto.BACKWARD), "")
+	require.NoError(t, err)
+	require.Equal(t, AsyncQueryStateQueued, job.State)
+	require.Equal(t, AsyncQueryFormatJSON, job.Format)
+
+	job = waitAsyncQuery(t, ctx, q, job.ID, AsyncQueryStateSucceeded)
+	require.NotNil(t, job.ExpiresAt)
+	require.Equal(t, 4, job.Progress.SplitsTotal)
+	// The splits are executed backward until the limit of the query is reached.
+	require.Equal(t, 2, job.Progress.SplitsCompleted)
+	require.Equal(t, int64(200), job.Progress.BytesProcessed)
+	require.Equal(t, int64(20), job.Progress.LinesProcessed)
+	require.Equal(t, 2, h.Calls())
+
+	rc, contentType, err := q.Results(ctx, job.ID)
+	require.NoError(t, err)
+	defer rc.Close()
+	require.Equal(t, "application/json; charset=UTF-8", contentType)
+
+	var res loghttp.QueryResponse
+	require.NoError(t, json.NewDecoder(rc).Decode(&res))
+	// The streams are listed once for each split they have lines in.
+	streams := res.Data.Result.(loghttp.Streams)
+	require.Len(t, streams, 2)
+	require.Len(t, streams[0].Entries, 1)
+	require.Equal(t, testTime.Truncate(time.Hour).Add(-time.Hour).Unix(), streams[0].Entries[0].Timestamp.Unix())
+	require.Equal(t, testTime.Truncate(time.Hour).Add(-2*time.Hour).Unix(), streams[1].Entries[0].Timestamp.Unix())
+	require.Equal(t, int64(2), res.Data.Statistics.Summary.Splits)
+
+	// The jobs are only visible to their tenant.
+	_, err = q.Get(user.InjectOrgID(context.Background(), "2"), job.ID)
+	require.ErrorIs(t, err, errAsyncQueryNotFound)
+
+	jobs, err := q.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, job.ID, jobs[0].ID)
+}
+
+func TestAsyncQueries_MetricQueryParquet(t *testing.T) {
+	h := &asyncQueryHandler{}
+	q := newTestAsyncQueries(t, 1, h)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	job, err := q.Submit(ctx, newAsyncQueryRequest(`count_over_time({app="foo"}[5m])`, 100, logproto.FORWARD), AsyncQueryFormatParquet)
+	require.NoError(t, err)
+
+	job = waitAsyncQuery(t, ctx, q, job.ID, AsyncQueryStateSucceeded)
+	require.Equal(t, job.Progress.SplitsTotal, job.Progress.SplitsCompleted)
+	require.Equal(t, job.Progress.SplitsTotal, h.Calls())
+	require.Greater(t, job.Progress.ResultsBytes, uint64(0))
+
+	rc, contentType, err := q.Results(ctx, job.ID)
+	require.NoError(t, err)
+	defer rc.Close()
+	require.Equal(t, ParquetType, contentType)
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "PAR1", string(b[:4]))
+}
+
+func TestAsyncQueries_LogQueryArrow(t *testing.T) {
+	h := &asyncQueryHandler{}
+	q := newTestAsyncQueries(t, 1, h)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	job, err := q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 100, logproto.FORWARD), AsyncQueryFormatArrow)
+	require.NoError(t, err)
+	waitAsyncQuery(t, ctx, q, job.ID, AsyncQueryStateSucceeded)
+
+	rc, contentType, err := q.Results(ctx, job.ID)
+	require.NoError(t, err)
+	defer rc.Close()
+	require.Equal(t, ArrowType, contentType)
+
+	r, err := ipc.NewReader(rc)
+	require.NoError(t, err)
+	defer r.Release()
+	var rows int64
+	for r.Next() {
+		rows += r.Record().NumRows()
+	}
+	require.NoError(t, r.Err())
+	require.Greater(t, rows, int64(0))
+}
+
+func TestAsyncQueries_ConcurrencyLimit(t *testing.T) {
+	h := &asyncQueryHandler{block: make(chan struct{})}
+	q := newTestAsyncQueries(t, 1, h)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	first, err := q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.NoError(t, err)
+	waitAsyncQuery(t, ctx, q, first.ID, AsyncQueryStateRunning)
+
+	second, err := q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	job, err := q.Get(ctx, second.ID)
+	require.NoError(t, err)
+	require.Equal(t, AsyncQueryStateQueued, job.State)
+
+	// Jobs of other tenants are not limited by the running jobs of the tenant.
+	otherCtx := user.InjectOrgID(context.Background(), "2")
+	other, err := q.Submit(otherCtx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.NoError(t, err)
+	waitAsyncQuery(t, otherCtx, q, other.ID, AsyncQueryStateRunning)
+
+	close(h.block)
+	waitAsyncQuery(t, ctx, q, first.ID, AsyncQueryStateSucceeded)
+	waitAsyncQuery(t, ctx, q, second.ID, AsyncQueryStateSucceeded)
+	waitAsyncQuery(t, otherCtx, q, other.ID, AsyncQueryStateSucceeded)
+}
+
+func TestAsyncQueries_QueueLimit(t *testing.T) {
+	h := &asyncQueryHandler{block: make(chan struct{})}
+	q := newTestAsyncQueries(t, 1, h)
+	q.limits = asyncQueryLimits{fakeLimits: fakeLimits{queryTimeout: time.Minute}, maxConcurrentJobs: 1, maxQueuedJobs: 1}
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	running, err := q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.NoError(t, err)
+	waitAsyncQuery(t, ctx, q, running.ID, AsyncQueryStateRunning)
+
+	queued, err := q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.NoError(t, err)
+
+	// The queue of the tenant is full, and the rejected job is not persisted.
+	_, err = q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.ErrorContains(t, err, "too many queued asynchronous query jobs")
+	jobs, err := q.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+
+	// The queues of the tenants are independent.
+	otherCtx := user.InjectOrgID(context.Background(), "2")
+	_, err = q.Submit(otherCtx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.NoError(t, err)
+
+	close(h.block)
+	waitAsyncQuery(t, ctx, q, running.ID, AsyncQueryStateSucceeded)
+	waitAsyncQuery(t, ctx, q, queued.ID, AsyncQueryStateSucceeded)
+}
+
+func TestAsyncQueries_Disabled(t *testing.T) {
+	q := newTestAsyncQueries(t, 0, &asyncQueryHandler{})
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	_, err := q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.ErrorContains(t, err, "asynchronous queries are disabled")
+}
+
+func TestAsyncQueries_Delete(t *testing.T) {
+	h := &asyncQueryHandler{block: make(chan struct{})}
+	q := newTestAsyncQueries(t, 1, h)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	job, err := q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.NoError(t, err)
+	waitAsyncQuery(t, ctx, q, job.ID, AsyncQueryStateRunning)
+
+	require.NoError(t, q.Delete(ctx, job.ID))
+	_, err = q.Get(ctx, job.ID)
+	require.ErrorIs(t, err, errAsyncQueryNotFound)
+	require.Empty(t, q.store.(*testutils.InMemoryObjectClient).Internals())
+	require.Equal(t, 0, h.Calls())
+}
+
+func TestAsyncQueries_Cleanup(t *testing.T) {
+	q := newTestAsyncQueries(t, 1, &asyncQueryHandler{})
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	job, err := q.Submit(ctx, newAsyncQueryRequest(`{app="foo"}`, 1, logproto.FORWARD), "")
+	require.NoError(t, err)
+	waitAsyncQuery(t, ctx, q, job.ID, AsyncQueryStateSucceeded)
+
+	require.NoError(t, q.cleanup(context.Background()))
+	require.Len(t, q.store.(*testutils.InMemoryObjectClient).Internals(), 2)
+
+	q.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = q.Get(ctx, job.ID)
+	require.ErrorIs(t, err, errAsyncQueryNotFound)
+
+	require.NoError(t, q.cleanup(context.Background()))
+	require.Empty(t, q.store.(*testutils.InMemoryObjectClient).Internals())
+}
+
+func TestAsyncQueries_SubmitHandler(t *testing.T) {
+	q := newTestAsyncQueries(t, 1, &asyncQueryHandler{})
+
+	submit := func(format string) *asyncQueryResponseRecorder {
+		form := url.Values{}
+		form.Set("query", `{app="foo"}`)
+		form.Set("start", "0")
+		form.Set("end", "3600")
+		form.Set("format", format)
+		r, err := http.NewRequest(http.MethodPost, "/loki/api/v1/query_jobs", strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r = r.WithContext(user.InjectOrgID(r.Context(), "1"))
+		w := &asyncQueryResponseRecorder{header: http.Header{}}
+		q.SubmitHandler(w, r)
+		return w
+	}
+
+	w := submit("xml")
+	require.Equal(t, http.StatusBadRequest, w.status)
+
+	w = submit("parquet")
+	require.Equal(t, http.StatusAccepted, w.status)
+
+	var res struct {
+		Status string        `json:"status"`
+		Data   AsyncQueryJob `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.body, &res))
+	require.Equal(t, AsyncQueryFormatParquet, res.Data.Format)
+	require.Equal(t, `{app="foo"}`, res.Data.Query)
+	require.NotEmpty(t, res.Data.ID)
+}
+
+type asyncQueryResponseRecorder struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+func (r *asyncQueryResponseRecorder) Header() http.Header { return r.header }
+
+func (r *asyncQueryResponseRecorder) WriteHeader(status int) { r.status = status }
+
+func (r *asyncQueryResponseRecorder) Write(b []byte) (int, error) {
+	r.body = append(r.body, b...)
+	return len(b), nil
+}
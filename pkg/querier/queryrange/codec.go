@@ -343,6 +343,15 @@ func (Codec) DecodeRequest(_ context.Context, r *http.Request, _ []string) (quer
 			return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
 		}
 		return req, nil
+	case ExplainOp:
+		req, err := parseRangeQuery(r)
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+		}
+		req.CachingOptions = queryrangebase.CachingOptions{
+			Disabled: disableCacheReq,
+		}
+		return &ExplainRequest{LokiRequest: req}, nil
 	case InstantQueryOp:
 		req, err := parseInstantQuery(r)
 		if err != nil {
@@ -1280,16 +1289,30 @@ func decodeResponseProtobuf(r *http.Response, req queryrangebase.Request) (query
 }
 
 func (Codec) EncodeResponse(ctx context.Context, req *http.Request, res queryrangebase.Response) (*http.Response, error) {
-	if req.Header.Get("Accept") == ProtobufType {
-		return encodeResponseProtobuf(ctx, res)
-	} else if req.Header.Get("Accept") == ParquetType {
-		return encodeResponseParquet(ctx, res)
+	var (
+		resp *http.Response
+		err  error
+	)
+	switch req.Header.Get("Accept") {
+	case ProtobufType:
+		resp, err = encodeResponseProtobuf(ctx, res)
+	case ParquetType:
+		resp, err = encodeResponseParquet(ctx, res)
+	case ArrowType:
+		resp, err = encodeResponseArrow(ctx, res)
+	default:
+		version := loghttp.GetVersion(req.RequestURI)
+		encodingFlags := httpreq.ExtractEncodingFlags(req)
+		resp, err = encodeResponseJSON(ctx, version, res, encodingFlags)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Default to JSON.
-	version := loghttp.GetVersion(req.RequestURI)
-	encodingFlags := httpreq.ExtractEncodingFlags(req)
-	return encodeResponseJSON(ctx, version, res, encodingFlags)
+	for name, values := range scanQuotaHeaders(res) {
+		resp.Header[name] = values
+	}
+	return resp, nil
 }
 
 func encodeResponseJSON(ctx context.Context, version loghttp.Version, res queryrangebase.Response, encodeFlags httpreq.EncodingFlags) (*http.Response, error) {
@@ -1382,6 +1405,10 @@ func encodeResponseJSONTo(version loghttp.Version, res queryrangebase.Response,
 		if err := marshal.WriteDetectedLabelsResponseJSON(response.Response, w); err != nil {
 			return err
 		}
+	case *ExplainResponse:
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			return err
+		}
 	default:
 		return httpgrpc.Errorf(http.StatusInternalServerError, "%s", fmt.Sprintf("invalid response format, got (%T)", res))
 	}
@@ -270,6 +270,21 @@ func Test_codec_EncodeDecodeRequest(t *testing.T) {
 			End:   end,
 			Step:  30 * 1e3, // step is expected in ms; default is 0 or no step
 		}, false},
+		{"explain", func() (*http.Request, error) {
+			return http.NewRequest(http.MethodGet,
+				fmt.Sprintf(`/loki/api/v1/explain?start=%d&end=%d&query={foo="bar"}&step=10&limit=200&direction=FORWARD`, start.UnixNano(), end.UnixNano()), nil)
+		}, &ExplainRequest{LokiRequest: &LokiRequest{
+			Query:     `{foo="bar"}`,
+			Limit:     200,
+			Step:      10000, // step is expected in ms
+			Direction: logproto.FORWARD,
+			Path:      "/loki/api/v1/explain",
+			StartTs:   start,
+			EndTs:     end,
+			Plan: &plan.QueryPlan{
+				AST: syntax.MustParseExpr(`{foo="bar"}`),
+			},
+		}}, false},
 		{"detected_labels", func() (*http.Request, error) {
 			return DefaultCodec.EncodeRequest(ctx, &DetectedLabelsRequest{
 				"/loki/api/v1/detected_labels",
@@ -0,0 +1,659 @@
+package queryrange
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/proto"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/timestamp"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache/resultscache"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+	"github.com/grafana/loki/v3/pkg/util"
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+// Results cache outcomes of the splits of an explained query.
+const (
+	ExplainCacheHit      = "hit"
+	ExplainCachePartial  = "partial"
+	ExplainCacheMiss     = "miss"
+	ExplainCacheDisabled = "disabled"
+	// ExplainCacheTooRecent is the outcome of the splits within the max cache freshness,
+	// which are never cached.
+	ExplainCacheTooRecent = "too_recent"
+)
+
+// Reasons of the rejections of an explained query.
+const (
+	ExplainReasonBlocked             = "blocked_queries"
+	ExplainReasonMaxQueryLookback    = "max_query_lookback"
+	ExplainReasonMaxQueryLength      = "max_query_length"
+	ExplainReasonMaxQueryRange       = "max_query_range"
+	ExplainReasonMaxEntries          = "max_entries_limit_per_query"
+	ExplainReasonRequiredLabels      = "required_labels"
+	ExplainReasonMaxQueryBytesRead   = "max_query_bytes_read"
+	ExplainReasonMaxQuerierBytesRead = "max_querier_bytes_read"
+)
+
+const (
+	explainMetricQuery = iota
+	explainFilterQuery
+	explainLimitedQuery
+)
+
+// ExplainRequest is a range query request to explain rather than to execute.
+type ExplainRequest struct {
+	*LokiRequest
+}
+
+// ExplainResponse describes how the query-frontend would execute a range query.
+type ExplainResponse struct {
+	Status  string                          `json:"status"`
+	Data    ExplainData                     `json:"data"`
+	Headers []base.PrometheusResponseHeader `json:"-"`
+}
+
+type ExplainData struct {
+	Query string `json:"query"`
+	// Expr is the expression evaluated by the engine, after optimisation, and AST its syntax tree.
+	Expr string          `json:"expr"`
+	AST  json.RawMessage `json:"ast"`
+	Type string          `json:"type"`
+	// Start and End are the time range of the query after applying the max query lookback
+	// and the step alignment.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Bytes is the number of bytes the query would read, estimated from the index stats.
+	Bytes      uint64             `json:"bytes,omitempty"`
+	Splits     []ExplainSplit     `json:"splits"`
+	Rejections []ExplainRejection `json:"rejections"`
+}
+
+// ExplainSplit describes the execution of a time split of a query.
+type ExplainSplit struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Cache string    `json:"cache"`
+	// Query is the query executed for the split, which is the sharded query if Sharded is set.
+	Query   string `json:"query"`
+	Sharded bool   `json:"sharded"`
+	// BytesPerShard is the largest number of bytes a querier would read for the split,
+	// estimated from the index stats.
+	BytesPerShard uint64             `json:"bytes_per_shard,omitempty"`
+	Shards        []ExplainShards    `json:"shards,omitempty"`
+	Rejections    []ExplainRejection `json:"rejections,omitempty"`
+
+	downstream []syntax.Expr
+}
+
+// ExplainShards describes the sharding of a subexpression of the query of a split.
+type ExplainShards struct {
+	Expr          string `json:"expr"`
+	Shards        int    `json:"shards"`
+	BytesPerShard uint64 `json:"bytes_per_shard"`
+}
+
+// ExplainRejection describes why the query, or a part of it, would be rejected.
+type ExplainRejection struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Query is the rejected downstream query, when it is not the one of the request.
+	Query string `json:"query,omitempty"`
+}
+
+func (m *ExplainResponse) Reset()         { *m = ExplainResponse{} }
+func (m *ExplainResponse) String() string { return fmt.Sprintf("%+v", m.Data) }
+func (*ExplainResponse) ProtoMessage()    {}
+
+func (m *ExplainResponse) GetHeaders() []*base.PrometheusResponseHeader {
+	if m != nil {
+		return convertPrometheusResponseHeadersToPointers(m.Headers)
+	}
+	return nil
+}
+
+func (m *ExplainResponse) SetHeader(name, value string) {
+	m.Headers = setHeader(m.Headers, name, value)
+}
+
+func (m *ExplainResponse) WithHeaders(h []base.PrometheusResponseHeader) base.Response {
+	m.Headers = h
+	return m
+}
+
+// NewExplainTripperware creates a new frontend tripperware explaining how range queries would be
+// split, sharded, cached and limited, without executing them. Only the index stats and shards
+// of the queries are requested downstream.
+func NewExplainTripperware(
+	cfg Config,
+	engineOpts logql.EngineOpts,
+	logger log.Logger,
+	limits Limits,
+	schema config.SchemaConfig,
+	iqo util.IngesterQueryOptions,
+	c cache.Cache,
+	cacheGenNumLoader base.CacheGenNumberLoader,
+	retentionEnabled bool,
+	indexStatsTripperware base.Middleware,
+) (base.Middleware, error) {
+	if c != nil && cacheGenNumLoader != nil {
+		c = cache.NewCacheGenNumMiddleware(c)
+	}
+	metrics := logql.NewShardMapperMetrics(nil)
+
+	return base.MiddlewareFunc(func(next base.Handler) base.Handler {
+		return &explainHandler{
+			cfg:               cfg,
+			engineOpts:        engineOpts,
+			logger:            logger,
+			limits:            limits,
+			confs:             schema.Configs,
+			iqo:               iqo,
+			cache:             c,
+			cacheGenNumLoader: cacheGenNumLoader,
+			retentionEnabled:  retentionEnabled,
+			metrics:           metrics,
+			next:              next,
+			statsHandler:      indexStatsTripperware.Wrap(next),
+			now:               time.Now,
+		}
+	}), nil
+}
+
+type explainHandler struct {
+	cfg               Config
+	engineOpts        logql.EngineOpts
+	logger            log.Logger
+	limits            Limits
+	confs             ShardingConfigs
+	iqo               util.IngesterQueryOptions
+	cache             cache.Cache
+	cacheGenNumLoader base.CacheGenNumberLoader
+	retentionEnabled  bool
+	metrics           *logql.MapperMetrics
+
+	next, statsHandler base.Handler
+	now                func() time.Time
+}
+
+func (h *explainHandler) Do(ctx context.Context, r base.Request) (base.Response, error) {
+	req, ok := r.(*ExplainRequest)
+	if !ok {
+		return nil, httpgrpc.Errorf(http.StatusInternalServerError, "invalid request type %T", r)
+	}
+	if req.Plan == nil {
+		return nil, errors.New("query plan is empty")
+	}
+
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+
+	expr := req.Plan.AST
+	kind, err := explainQueryKind(ctx, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	optimized, err := logql.Optimize(expr)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	var ast bytes.Buffer
+	if err := syntax.EncodeJSON(optimized, &ast); err != nil {
+		return nil, err
+	}
+	typ, err := logql.QueryType(expr)
+	if err != nil || typ == "" {
+		typ = "unknown"
+	}
+
+	res := &ExplainResponse{
+		Status: loghttp.QueryStatusSuccess,
+		Data: ExplainData{
+			Query:      req.Query,
+			Expr:       optimized.String(),
+			AST:        ast.Bytes(),
+			Type:       typ,
+			Start:      req.StartTs,
+			End:        req.EndTs,
+			Splits:     []ExplainSplit{},
+			Rejections: h.checkQuery(ctx, tenantIDs, req.LokiRequest, expr, typ),
+		},
+	}
+
+	lokiReq := req.LokiRequest
+	lookbackCapture := func(id string) time.Duration { return h.limits.MaxQueryLookback(ctx, id) }
+	if maxQueryLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, lookbackCapture); maxQueryLookback > 0 {
+		minStartTime := h.now().Add(-maxQueryLookback)
+		if lokiReq.EndTs.Before(minStartTime) {
+			res.Data.Rejections = append(res.Data.Rejections, ExplainRejection{
+				Reason:  ExplainReasonMaxQueryLookback,
+				Message: fmt.Sprintf("the time range of the query is before the max query lookback (%s), an empty response would be returned", model.Duration(maxQueryLookback)),
+			})
+			return res, nil
+		}
+		if lokiReq.StartTs.Before(minStartTime) {
+			lokiReq = lokiReq.WithStartEnd(minStartTime, lokiReq.EndTs).(*LokiRequest)
+		}
+	}
+
+	lengthCapture := func(id string) time.Duration { return h.limits.MaxQueryLength(ctx, id) }
+	if maxQueryLength := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, lengthCapture); maxQueryLength > 0 {
+		queryLen := timestamp.Time(lokiReq.EndTs.UnixMilli()).Sub(timestamp.Time(lokiReq.StartTs.UnixMilli()))
+		if queryLen > maxQueryLength {
+			// The query would be rejected before being split.
+			res.Data.Rejections = append(res.Data.Rejections, ExplainRejection{
+				Reason:  ExplainReasonMaxQueryLength,
+				Message: fmt.Sprintf(validation.ErrQueryTooLong, queryLen, model.Duration(maxQueryLength)),
+			})
+			return res, nil
+		}
+	}
+
+	if kind == explainMetricQuery && h.cfg.AlignQueriesWithStep && lokiReq.Step > 0 {
+		start := (lokiReq.StartTs.UnixMilli() / lokiReq.Step) * lokiReq.Step
+		end := (lokiReq.EndTs.UnixMilli() / lokiReq.Step) * lokiReq.Step
+		lokiReq = lokiReq.WithStartEnd(time.UnixMilli(start), time.UnixMilli(end)).(*LokiRequest)
+	}
+	res.Data.Start, res.Data.End = lokiReq.StartTs, lokiReq.EndTs
+
+	if kind != explainLimitedQuery {
+		bytesRead, ok, err := h.querySize(ctx, lokiReq)
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "Failed to get bytes read stats for query: %s", err.Error())
+		}
+		if ok {
+			res.Data.Bytes = bytesRead
+			if rejection := h.checkBytesRead(ctx, tenantIDs, bytesRead, h.limits.MaxQueryBytesRead, ExplainReasonMaxQueryBytesRead, limErrQueryTooManyBytesTmpl); rejection != nil {
+				res.Data.Rejections = append(res.Data.Rejections, *rejection)
+			}
+		}
+	}
+
+	splits := h.split(tenantIDs, kind, lokiReq)
+	res.Data.Splits = make([]ExplainSplit, len(splits))
+	parallelism := MinWeightedParallelism(ctx, tenantIDs, h.confs, h.limits, model.Time(lokiReq.StartTs.UnixMilli()), model.Time(lokiReq.EndTs.UnixMilli()))
+	if err := concurrency.ForEachJob(ctx, len(splits), max(parallelism, 1), func(ctx context.Context, i int) error {
+		split, err := h.explainSplit(ctx, tenantIDs, kind, splits[i], expr)
+		if err != nil {
+			return err
+		}
+		res.Data.Splits[i] = split
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	res.Data.Rejections = append(res.Data.Rejections, h.checkBlockedDownstream(ctx, tenantIDs, req.Query, res.Data.Splits)...)
+	return res, nil
+}
+
+// explainQueryKind returns the tripperware the roundtripper would route a query to.
+func explainQueryKind(ctx context.Context, expr syntax.Expr) (int, error) {
+	switch e := expr.(type) {
+	case syntax.VariantsExpr, syntax.SampleExpr:
+		return explainMetricQuery, nil
+	case syntax.LogSelectorExpr:
+		// Some queries we don't want to parallelize as aggressively, like limited queries and `datasample` queries
+		tags := httpreq.ExtractQueryTagsFromContext(ctx)
+		if !e.HasFilter() || strings.Contains(tags, "datasample") {
+			return explainLimitedQuery, nil
+		}
+		return explainFilterQuery, nil
+	default:
+		return 0, httpgrpc.Errorf(http.StatusBadRequest, "unsupported query type %T", expr)
+	}
+}
+
+// checkQuery returns the rejections of the limits applied to the whole query, before splitting.
+func (h *explainHandler) checkQuery(ctx context.Context, tenantIDs []string, req *LokiRequest, expr syntax.Expr, typ string) []ExplainRejection {
+	rejections := []ExplainRejection{}
+
+	var groups []syntax.MatcherRange
+	switch e := expr.(type) {
+	case syntax.VariantsExpr:
+		groups = append(groups, syntax.MatcherRange{Matchers: e.Matchers()})
+		for _, v := range e.Variants() {
+			variantGroups, _ := v.MatcherGroups()
+			groups = append(groups, variantGroups...)
+		}
+	case syntax.SampleExpr:
+		groups, _ = e.MatcherGroups()
+	case syntax.LogSelectorExpr:
+		groups = append(groups, syntax.MatcherRange{Matchers: e.Matchers()})
+	}
+
+	if _, ok := expr.(syntax.SampleExpr); !ok {
+		if err := validateMaxEntriesLimits(ctx, req.Limit, h.limits); err != nil {
+			rejections = append(rejections, ExplainRejection{Reason: ExplainReasonMaxEntries, Message: err.Error()})
+		}
+	}
+	for _, g := range groups {
+		if err := validateMatchers(ctx, h.limits, g.Matchers); err != nil {
+			rejections = append(rejections, ExplainRejection{Reason: ExplainReasonRequiredLabels, Message: err.Error()})
+			break
+		}
+	}
+
+	if e, ok := expr.(syntax.SampleExpr); ok {
+		maxIntervalCapture := func(id string) time.Duration { return h.limits.MaxQueryRange(ctx, id) }
+		if maxQueryInterval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, maxIntervalCapture); maxQueryInterval > 0 {
+			var maxInterval time.Duration
+			e.Walk(func(e syntax.Expr) {
+				if r, ok := e.(*syntax.LogRangeExpr); ok && r.Interval > maxInterval {
+					maxInterval = r.Interval
+				}
+			})
+			if maxInterval > maxQueryInterval {
+				rejections = append(rejections, ExplainRejection{
+					Reason:  ExplainReasonMaxQueryRange,
+					Message: fmt.Sprintf("%s: [%s] > [%s]", logqlmodel.ErrIntervalLimit, model.Duration(maxInterval), model.Duration(maxQueryInterval)),
+				})
+			}
+		}
+	}
+
+	if rejection := h.checkBlocked(ctx, tenantIDs, req.Query, typ); rejection != nil {
+		rejections = append(rejections, *rejection)
+	}
+	return rejections
+}
+
+func (h *explainHandler) checkBlocked(ctx context.Context, tenantIDs []string, query, typ string) *ExplainRejection {
+	for _, tenantID := range tenantIDs {
+		blocks := h.limits.BlockedQueries(ctx, tenantID)
+		if len(blocks) == 0 {
+			continue
+		}
+		b := logql.MatchBlockedQuery(blocks, query, typ, log.NewNopLogger())
+		if b == nil {
+			continue
+		}
+		policy := fmt.Sprintf("pattern %q", b.Pattern)
+		if b.Hash > 0 {
+			policy = fmt.Sprintf("hash %d", b.Hash)
+		}
+		return &ExplainRejection{
+			Reason:  ExplainReasonBlocked,
+			Message: fmt.Sprintf("query blocked for tenant %s by the policy with %s", tenantID, policy),
+		}
+	}
+	return nil
+}
+
+// checkBlockedDownstream checks the blocked queries against the downstream queries of the
+// sharded splits, which are evaluated by the queriers in place of the query of the request.
+func (h *explainHandler) checkBlockedDownstream(ctx context.Context, tenantIDs []string, query string, splits []ExplainSplit) []ExplainRejection {
+	var (
+		rejections []ExplainRejection
+		seen       = map[string]struct{}{query: {}}
+	)
+	for _, split := range splits {
+		for _, e := range split.downstream {
+			downstream := e.String()
+			if _, ok := seen[downstream]; ok {
+				continue
+			}
+			seen[downstream] = struct{}{}
+
+			typ, err := logql.QueryType(e)
+			if err != nil {
+				typ = "unknown"
+			}
+			if rejection := h.checkBlocked(ctx, tenantIDs, downstream, typ); rejection != nil {
+				rejection.Query = downstream
+				rejections = append(rejections, *rejection)
+			}
+		}
+	}
+	return rejections
+}
+
+// querySize returns the number of bytes the query of r would read according to the index stats,
+// or false if the index of the time range of the request has no stats.
+func (h *explainHandler) querySize(ctx context.Context, r *LokiRequest) (uint64, bool, error) {
+	q := newQuerySizeLimiter(h.next, h.confs, h.engineOpts, h.logger, nil, "", h.statsHandler)
+	schemaCfg, err := q.getSchemaCfg(r)
+	if err != nil || schemaCfg.IndexType != types.TSDBType {
+		return 0, false, nil
+	}
+	bytesRead, err := q.getBytesReadForRequest(ctx, r)
+	if err != nil {
+		return 0, false, err
+	}
+	return bytesRead, true, nil
+}
+
+func (h *explainHandler) checkBytesRead(ctx context.Context, tenantIDs []string, bytesRead uint64, limitFunc func(context.Context, string) int, reason, errTmpl string) *ExplainRejection {
+	limitFuncCapture := func(id string) int { return limitFunc(ctx, id) }
+	maxBytesRead := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, limitFuncCapture)
+	if maxBytesRead <= 0 || bytesRead <= uint64(maxBytesRead) {
+		return nil
+	}
+	return &ExplainRejection{
+		Reason:  reason,
+		Message: fmt.Sprintf(errTmpl, humanize.IBytes(bytesRead), humanize.IBytes(uint64(maxBytesRead))),
+	}
+}
+
+// split returns the time splits the query would be executed as.
+func (h *explainHandler) split(tenantIDs []string, kind int, r *LokiRequest) []*LokiRequest {
+	interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, h.limits.QuerySplitDuration)
+	if interval == 0 {
+		return []*LokiRequest{r}
+	}
+
+	var s splitter = newDefaultSplitter(h.limits, h.iqo)
+	if kind == explainMetricQuery {
+		s = newMetricQuerySplitter(h.limits, h.iqo)
+	}
+	reqs := s.split(h.now().UTC(), tenantIDs, r, interval)
+	if len(reqs) == 0 {
+		return []*LokiRequest{r}
+	}
+
+	splits := make([]*LokiRequest, 0, len(reqs))
+	for _, req := range reqs {
+		splits = append(splits, req.(*LokiRequest))
+	}
+	return splits
+}
+
+func (h *explainHandler) explainSplit(ctx context.Context, tenantIDs []string, kind int, r *LokiRequest, expr syntax.Expr) (ExplainSplit, error) {
+	split := ExplainSplit{
+		Start: r.StartTs,
+		End:   r.EndTs,
+		Query: r.Query,
+		Cache: h.cacheStatus(ctx, tenantIDs, kind, r),
+	}
+
+	if !h.cfg.ShardedQueries || !hasShards(h.confs) {
+		// Without sharding, the querier size limit is enforced on each split.
+		if kind == explainLimitedQuery {
+			return split, nil
+		}
+		bytesRead, ok, err := h.querySize(ctx, r)
+		if err != nil || !ok {
+			return split, err
+		}
+		split.BytesPerShard = bytesRead
+		if rejection := h.checkBytesRead(ctx, tenantIDs, bytesRead, h.limits.MaxQuerierBytesRead, ExplainReasonMaxQuerierBytesRead, limErrQuerierTooManyBytesTmpl); rejection != nil {
+			split.Rejections = append(split.Rejections, *rejection)
+		}
+		return split, nil
+	}
+
+	// Only the splits older than the sharding lookback are sharded.
+	minShardingLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, h.limits.MinShardingLookback)
+	if minShardingLookback > 0 && !util.TimeFromMillis(r.EndTs.UnixMilli()).Before(h.now().Add(-minShardingLookback)) {
+		return split, nil
+	}
+
+	maxShards := 0
+	if kind == explainLimitedQuery {
+		maxShards = 32
+	}
+	ast := newASTMapperware(h.confs, h.engineOpts, h.next, h.next, h.statsHandler, h.logger, h.metrics, h.limits, maxShards, h.cfg.ShardAggregations)
+	strategy, ok := ast.shardingStrategy(ctx, r, expr, tenantIDs, ast.logger)
+	if !ok {
+		return split, nil
+	}
+
+	recorder := &explainShardingStrategy{ShardingStrategy: strategy}
+	noop, bytesPerShard, mapped, err := logql.NewShardMapper(recorder, h.metrics, ast.mergedShardAggregations(tenantIDs)).Parse(expr)
+	if err != nil {
+		return split, err
+	}
+
+	split.BytesPerShard = bytesPerShard
+	if err := ast.checkQuerySizeLimit(ctx, bytesPerShard, noop); err != nil {
+		split.Rejections = append(split.Rejections, ExplainRejection{Reason: ExplainReasonMaxQuerierBytesRead, Message: explainErrorMessage(err)})
+	}
+	if !noop {
+		split.Sharded = true
+		split.Query = mapped.String()
+		split.Shards = recorder.shards
+		split.downstream = recorder.exprs
+	}
+	return split, nil
+}
+
+// cacheStatus returns whether the results of a split would be fetched from the results cache.
+func (h *explainHandler) cacheStatus(ctx context.Context, tenantIDs []string, kind int, r *LokiRequest) string {
+	if !h.cfg.CacheResults || h.cache == nil || kind == explainLimitedQuery || r.CachingOptions.Disabled {
+		return ExplainCacheDisabled
+	}
+
+	if h.cacheGenNumLoader != nil && h.retentionEnabled {
+		ctx = cache.InjectCacheGenNumber(ctx, h.cacheGenNumLoader.GetResultsCacheGenNumber(tenantIDs))
+	}
+
+	cacheFreshnessCapture := func(id string) time.Duration { return h.limits.MaxCacheFreshness(ctx, id) }
+	maxCacheFreshness := validation.MaxDurationPerTenant(tenantIDs, cacheFreshnessCapture)
+	maxCacheTime := int64(model.Now().Add(-maxCacheFreshness))
+
+	if kind == explainFilterQuery {
+		interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, h.limits.QuerySplitDuration)
+		if interval == 0 || r.Limit == 0 {
+			return ExplainCacheDisabled
+		}
+		if r.EndTs.UnixMilli() > maxCacheTime {
+			return ExplainCacheTooRecent
+		}
+
+		key := logResultCacheKey(ctx, tenantIDs, h.cfg.Transformer, r, interval)
+		_, bufs, _, err := h.cache.Fetch(ctx, []string{cache.HashKey(key)})
+		if err != nil || len(bufs) != 1 {
+			return ExplainCacheMiss
+		}
+		var cached LokiRequest
+		if err := proto.Unmarshal(bufs[0], &cached); err != nil {
+			return ExplainCacheMiss
+		}
+		return cacheCoverage(r.StartTs.UnixMilli(), r.EndTs.UnixMilli(), [][2]int64{{cached.StartTs.UnixMilli(), cached.EndTs.UnixMilli()}})
+	}
+
+	if r.StartTs.UnixMilli() > maxCacheTime {
+		return ExplainCacheTooRecent
+	}
+
+	keyGen := cacheKeyLimits{h.limits, h.cfg.Transformer, h.iqo}
+	key := keyGen.GenerateCacheKey(ctx, tenant.JoinTenantIDs(tenantIDs), r)
+	_, bufs, _, err := h.cache.Fetch(ctx, []string{cache.HashKey(key)})
+	if err != nil || len(bufs) != 1 {
+		return ExplainCacheMiss
+	}
+	var cached resultscache.CachedResponse
+	if err := proto.Unmarshal(bufs[0], &cached); err != nil || cached.Key != key {
+		return ExplainCacheMiss
+	}
+	extents := make([][2]int64, 0, len(cached.Extents))
+	for _, e := range cached.Extents {
+		extents = append(extents, [2]int64{e.Start, e.End})
+	}
+	return cacheCoverage(r.StartTs.UnixMilli(), r.EndTs.UnixMilli(), extents)
+}
+
+// cacheCoverage returns whether the cached extents cover the range from start to end.
+func cacheCoverage(start, end int64, extents [][2]int64) string {
+	sort.Slice(extents, func(i, j int) bool { return extents[i][0] < extents[j][0] })
+
+	var covered int64
+	cursor := start
+	for _, e := range extents {
+		if e[1] < cursor || e[0] > end {
+			continue
+		}
+		from, through := max(e[0], cursor), min(e[1], end)
+		if from == start && through == end {
+			return ExplainCacheHit
+		}
+		if through > from {
+			covered += through - from
+			cursor = through
+		}
+	}
+
+	switch {
+	case covered > 0 && covered >= end-start:
+		return ExplainCacheHit
+	case covered > 0:
+		return ExplainCachePartial
+	default:
+		return ExplainCacheMiss
+	}
+}
+
+// explainShardingStrategy records the shards of the subexpressions of a mapped query.
+type explainShardingStrategy struct {
+	logql.ShardingStrategy
+
+	mtx    sync.Mutex
+	shards []ExplainShards
+	exprs  []syntax.Expr
+}
+
+func (s *explainShardingStrategy) Shards(expr syntax.Expr) ([]logql.ShardWithChunkRefs, uint64, error) {
+	shards, bytesPerShard, err := s.ShardingStrategy.Shards(expr)
+	if err != nil {
+		return shards, bytesPerShard, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.shards = append(s.shards, ExplainShards{Expr: expr.String(), Shards: len(shards), BytesPerShard: bytesPerShard})
+	s.exprs = append(s.exprs, expr)
+	return shards, bytesPerShard, nil
+}
+
+func explainErrorMessage(err error) string {
+	if resp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+		return string(resp.Body)
+	}
+	return err.Error()
+}
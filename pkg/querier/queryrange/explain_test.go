@@ -0,0 +1,181 @@
+package queryrange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+type explainLimits struct {
+	fakeLimits
+	blocked []*validation.BlockedQuery
+}
+
+func (l explainLimits) BlockedQueries(context.Context, string) []*validation.BlockedQuery {
+	return l.blocked
+}
+
+func newExplainRequest(query string, start, end time.Time) *ExplainRequest {
+	return &ExplainRequest{LokiRequest: &LokiRequest{
+		Query:     query,
+		Limit:     1000,
+		Step:      30000,
+		StartTs:   start,
+		EndTs:     end,
+		Direction: logproto.FORWARD,
+		Path:      "/loki/api/v1/explain",
+		Plan: &plan.QueryPlan{
+			AST: syntax.MustParseExpr(query),
+		},
+	}}
+}
+
+func newExplainMiddleware(t *testing.T, l Limits) base.Middleware {
+	cfg := testConfig
+	cfg.ShardedQueries = true
+	tpw, stopper, err := NewMiddleware(cfg, testEngineOpts, nil, util_log.Logger, l, config.SchemaConfig{
+		Configs: testSchemasTSDB,
+	}, nil, false, nil, constants.Loki)
+	if stopper != nil {
+		t.Cleanup(stopper.Stop)
+	}
+	require.NoError(t, err)
+	return tpw
+}
+
+func TestExplainTripperware(t *testing.T) {
+	l := fakeLimits{
+		maxQueryParallelism:     1,
+		tsdbMaxQueryParallelism: 1,
+		splitDuration:           map[string]time.Duration{"1": time.Hour},
+	}
+	tpw := newExplainMiddleware(t, l)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	query := `sum by (app) (rate({app="foo"} |= "foo"[1m]))`
+	req := newExplainRequest(query, testTime.Add(-2*time.Hour), testTime)
+
+	statsCount, statsHandler := indexStatsResult(logproto.IndexStatsResponse{Bytes: 2 << 30})
+	queryCount, queryHandler := counter()
+	resp, err := tpw.Wrap(getQueryAndStatsHandler(queryHandler, statsHandler)).Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 0, *queryCount)
+	require.Greater(t, *statsCount, 0)
+
+	data := resp.(*ExplainResponse).Data
+	require.Equal(t, query, data.Query)
+	require.Equal(t, "metric", data.Type)
+	require.Equal(t, uint64(2<<30), data.Bytes)
+	require.Empty(t, data.Rejections)
+	require.NotEmpty(t, data.AST)
+
+	// The query is split by hour, and the splits are sharded according to their share of the bytes.
+	require.Len(t, data.Splits, 3)
+	for i, shards := range []int{2, 2, 0} {
+		split := data.Splits[i]
+		require.Equal(t, ExplainCacheMiss, split.Cache)
+		require.True(t, split.Sharded)
+		require.Contains(t, split.Query, "downstream")
+		require.Len(t, split.Shards, 1)
+		require.Equal(t, shards, split.Shards[0].Shards)
+		require.Equal(t, split.BytesPerShard, split.Shards[0].BytesPerShard)
+	}
+}
+
+func TestExplainTripperware_Rejections(t *testing.T) {
+	l := explainLimits{
+		fakeLimits: fakeLimits{
+			maxQueryParallelism:     1,
+			tsdbMaxQueryParallelism: 1,
+			maxQueryLength:          time.Hour,
+			maxQueryBytesRead:       1000,
+		},
+		blocked: []*validation.BlockedQuery{{Pattern: `.*foo.*`, Regex: true}},
+	}
+	tpw := newExplainMiddleware(t, l)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	_, statsHandler := indexStatsResult(logproto.IndexStatsResponse{Bytes: 2000})
+	queryCount, queryHandler := counter()
+	h := tpw.Wrap(getQueryAndStatsHandler(queryHandler, statsHandler))
+
+	for _, tc := range []struct {
+		name    string
+		start   time.Time
+		reasons []string
+	}{
+		{
+			name:    "too long",
+			start:   testTime.Add(-2 * time.Hour),
+			reasons: []string{ExplainReasonBlocked, ExplainReasonMaxQueryLength},
+		},
+		{
+			name:    "too many bytes",
+			start:   testTime.Add(-30 * time.Minute),
+			reasons: []string{ExplainReasonBlocked, ExplainReasonMaxQueryBytesRead},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := h.Do(ctx, newExplainRequest(`{app="foo"} |= "foo"`, tc.start, testTime))
+			require.NoError(t, err)
+
+			data := resp.(*ExplainResponse).Data
+			require.Equal(t, "filter", data.Type)
+			var reasons []string
+			for _, r := range data.Rejections {
+				reasons = append(reasons, r.Reason)
+			}
+			require.Equal(t, tc.reasons, reasons)
+		})
+	}
+	require.Equal(t, 0, *queryCount)
+}
+
+func TestExplainTripperware_CacheHit(t *testing.T) {
+	l := fakeLimits{
+		maxQueryParallelism:     1,
+		tsdbMaxQueryParallelism: 1,
+		splitDuration:           map[string]time.Duration{"1": time.Hour},
+		queryTimeout:            time.Minute,
+	}
+	tpw := newExplainMiddleware(t, l)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	query := `rate({app="foo"} |= "foo"[1m])`
+	explain := newExplainRequest(query, testTime.Add(-2*time.Hour), testTime)
+
+	// Executing the query caches the results of its splits.
+	_, statsHandler := indexStatsResult(logproto.IndexStatsResponse{Bytes: 10})
+	_, queryHandler := promqlResult(matrix)
+	h := tpw.Wrap(getQueryAndStatsHandler(queryHandler, statsHandler))
+	_, err := h.Do(ctx, explain.LokiRequest)
+	require.NoError(t, err)
+
+	resp, err := h.Do(ctx, explain)
+	require.NoError(t, err)
+	splits := resp.(*ExplainResponse).Data.Splits
+	require.Len(t, splits, 3)
+	for _, split := range splits {
+		require.Equal(t, ExplainCacheHit, split.Cache)
+	}
+}
+
+func TestCacheCoverage(t *testing.T) {
+	require.Equal(t, ExplainCacheHit, cacheCoverage(10, 20, [][2]int64{{0, 30}}))
+	require.Equal(t, ExplainCacheHit, cacheCoverage(10, 20, [][2]int64{{15, 20}, {10, 15}}))
+	require.Equal(t, ExplainCachePartial, cacheCoverage(10, 20, [][2]int64{{12, 15}}))
+	require.Equal(t, ExplainCacheMiss, cacheCoverage(10, 20, [][2]int64{{21, 30}}))
+	require.Equal(t, ExplainCacheMiss, cacheCoverage(10, 20, nil))
+}
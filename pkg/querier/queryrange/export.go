@@ -0,0 +1,512 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/parquet-go/parquet-go"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	queryrange_limits "github.com/grafana/loki/v3/pkg/querier/queryrange/limits"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+// Formats of the streaming export API.
+const (
+	ExportFormatNDJSON   = "ndjson"
+	ExportFormatProtobuf = "protobuf"
+	ExportFormatParquet  = "parquet"
+
+	NDJSONType = "application/x-ndjson"
+)
+
+// Trailers of the responses of the streaming export API.
+const (
+	// ExportCursorTrailer is the cursor to resume an incomplete export from. It is empty when the
+	// export is complete.
+	ExportCursorTrailer         = "X-Loki-Export-Cursor"
+	ExportBytesProcessedTrailer = "X-Loki-Export-Bytes-Processed"
+	ExportErrorTrailer          = "X-Loki-Export-Error"
+)
+
+// ExportConfig configures the streaming export API of the query frontend.
+type ExportConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	SplitInterval time.Duration `yaml:"split_interval"`
+	PageSize      int           `yaml:"page_size"`
+}
+
+// RegisterFlags adds the flags required to configure this flag set.
+func (cfg *ExportConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "querier.export.enabled", false, "Enable the streaming export API of the query frontend, which streams the results of log queries in time order.")
+	f.DurationVar(&cfg.SplitInterval, "querier.export.split-interval", time.Hour, "Interval the time range of the export requests is split by. The splits are executed in the direction of the query, one page at a time.")
+	f.IntVar(&cfg.PageSize, "querier.export.page-size", 5000, "Maximum number of lines requested by each page of an export request. The pages are also limited by max_entries_limit_per_query.")
+}
+
+// Validate validates the config.
+func (cfg *ExportConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.SplitInterval <= 0 {
+		return errors.New("querier.export.split-interval must be greater than 0")
+	}
+	if cfg.PageSize <= 0 {
+		return errors.New("querier.export.page-size must be greater than 0")
+	}
+	return nil
+}
+
+// ExportLimits are the per-tenant limits of the streaming export API.
+type ExportLimits queryrange_limits.ExportLimits
+
+// exportCursor is the position of an export: the timestamp of the last exported lines, and the
+// hashes of the lines exported at this timestamp.
+type exportCursor struct {
+	Timestamp int64    `json:"ts"`
+	Seen      []uint64 `json:"seen,omitempty"`
+}
+
+func (c *exportCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func parseExportCursor(s string) (*exportCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "invalid cursor: %s", err.Error())
+	}
+	var c exportCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "invalid cursor: %s", err.Error())
+	}
+	return &c, nil
+}
+
+func (c *exportCursor) seen(hash uint64) bool {
+	for _, h := range c.Seen {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// advance moves the cursor to an exported line.
+func (c *exportCursor) advance(e exportEntry) {
+	if ts := e.Timestamp.UnixNano(); ts != c.Timestamp {
+		c.Timestamp = ts
+		c.Seen = c.Seen[:0]
+	}
+	c.Seen = append(c.Seen, e.hash())
+}
+
+// exportEntry is a line of an exported stream.
+type exportEntry struct {
+	Labels string
+	logproto.Entry
+}
+
+func (e exportEntry) hash() uint64 {
+	h := xxhash.New()
+	_, _ = h.WriteString(e.Labels)
+	_, _ = h.Write([]byte{0xff})
+	_, _ = h.WriteString(e.Line)
+	return h.Sum64()
+}
+
+// exportEntries returns the lines of a response in the direction of the query.
+func exportEntries(res *LokiResponse, direction logproto.Direction) []exportEntry {
+	var entries []exportEntry
+	for _, stream := range res.Data.Result {
+		for _, entry := range stream.Entries {
+			entries = append(entries, exportEntry{Labels: stream.Labels, Entry: entry})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if direction == logproto.BACKWARD {
+			return entries[i].Timestamp.After(entries[j].Timestamp)
+		}
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries
+}
+
+// Exporter streams the lines of log queries through the query frontend, one page at a time, in
+// the direction of the queries.
+type Exporter struct {
+	cfg     ExportConfig
+	limits  ExportLimits
+	handler base.Handler
+	logger  log.Logger
+	now     func() time.Time
+}
+
+// NewExporter creates the streaming export API of the query frontend, executing the pages of the
+// exports with the handler.
+func NewExporter(cfg ExportConfig, limits ExportLimits, handler base.Handler, logger log.Logger) *Exporter {
+	return &Exporter{
+		cfg:     cfg,
+		limits:  limits,
+		handler: handler,
+		logger:  log.With(logger, "component", "export"),
+		now:     time.Now,
+	}
+}
+
+// Export executes a log query from the cursor, if any, and calls write with the lines of each page
+// in the direction of the query. The next page is only requested once write returns.
+// It returns the cursor to resume the export from when it is stopped by the bytes scanned quota
+// of the tenant or by an error, and the number of bytes scanned.
+func (e *Exporter) Export(ctx context.Context, req *LokiRequest, cursor *exportCursor, write func([]exportEntry) error) (*exportCursor, int64, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, 0, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	if _, ok := req.Plan.AST.(syntax.SampleExpr); ok {
+		return nil, 0, httpgrpc.Errorf(http.StatusBadRequest, "only log queries can be exported")
+	}
+
+	// The lines of the range are within [start, end).
+	if cursor == nil {
+		cursor = &exportCursor{Timestamp: req.StartTs.UnixNano()}
+		if req.Direction == logproto.BACKWARD {
+			cursor.Timestamp = req.EndTs.UnixNano() - 1
+		}
+	}
+	if cursor.Timestamp < req.StartTs.UnixNano() || cursor.Timestamp >= req.EndTs.UnixNano() {
+		return nil, 0, httpgrpc.Errorf(http.StatusBadRequest, "the cursor is outside of the time range of the query")
+	}
+	req = e.remaining(req, cursor)
+
+	pageSize := e.cfg.PageSize
+	maxEntries := validation.SmallestPositiveIntPerTenant(tenantIDs, func(id string) int { return e.limits.MaxEntriesLimitPerQuery(ctx, id) })
+	if maxEntries > 0 && maxEntries < pageSize {
+		pageSize = maxEntries
+	}
+	quota := int64(validation.SmallestPositiveIntPerTenant(tenantIDs, e.limits.ExportMaxBytesScanned))
+	timeoutCapture := func(id string) time.Duration { return e.limits.QueryTimeout(ctx, id) }
+	timeout := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, timeoutCapture)
+
+	var scanned int64
+	for _, split := range splitInOrder(e.limits, e.now().UTC(), tenantIDs, req, e.cfg.SplitInterval) {
+		for {
+			if quota > 0 && scanned >= quota {
+				return cursor, scanned, nil
+			}
+
+			page := e.remaining(split, cursor)
+			page.Limit = uint32(pageSize)
+			res, err := e.do(ctx, page, timeout)
+			if err != nil {
+				return cursor, scanned, err
+			}
+			scanned += res.Statistics.Summary.TotalBytesProcessed
+
+			entries := exportEntries(res, req.Direction)
+			exported := make([]exportEntry, 0, len(entries))
+			for _, entry := range entries {
+				if entry.Timestamp.UnixNano() == cursor.Timestamp && cursor.seen(entry.hash()) {
+					continue
+				}
+				exported = append(exported, entry)
+			}
+			if len(exported) > 0 {
+				if err := write(exported); err != nil {
+					return cursor, scanned, err
+				}
+				for _, entry := range exported {
+					cursor.advance(entry)
+				}
+			}
+
+			if len(entries) < pageSize {
+				break
+			}
+			if len(exported) == 0 {
+				return cursor, scanned, fmt.Errorf("more than %d lines have the timestamp %s: increase max_entries_limit_per_query to export them", pageSize, time.Unix(0, cursor.Timestamp).UTC().Format(time.RFC3339Nano))
+			}
+		}
+
+		// The next split starts right after the end of the split.
+		cursor = &exportCursor{Timestamp: split.EndTs.UnixNano()}
+		if req.Direction == logproto.BACKWARD {
+			cursor.Timestamp = split.StartTs.UnixNano() - 1
+		}
+	}
+	return nil, scanned, nil
+}
+
+// remaining returns the part of the range of a request which is not exported yet.
+func (e *Exporter) remaining(req *LokiRequest, cursor *exportCursor) *LokiRequest {
+	start, end := req.StartTs, req.EndTs
+	ts := time.Unix(0, cursor.Timestamp).UTC()
+	if req.Direction == logproto.BACKWARD {
+		if next := ts.Add(time.Nanosecond); next.Before(end) {
+			end = next
+		}
+	} else if ts.After(start) {
+		start = ts
+	}
+	return req.WithStartEnd(start, end).(*LokiRequest)
+}
+
+func (e *Exporter) do(ctx context.Context, req *LokiRequest, timeout time.Duration) (*LokiResponse, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	res, err := e.handler.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	lokiRes, ok := res.(*LokiResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", res)
+	}
+	return lokiRes, nil
+}
+
+// Handler streams the lines of a log query. It takes the parameters of range queries, the format
+// of the lines and the cursor to resume an export from.
+func (e *Exporter) Handler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error()), w)
+		return
+	}
+	req, err := parseRangeQuery(r)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error()), w)
+		return
+	}
+	format := strings.ToLower(r.Form.Get("format"))
+	if format == "" {
+		format = ExportFormatNDJSON
+	}
+	newWriter, contentType, err := newExportWriter(format)
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	var cursor *exportCursor
+	if s := r.Form.Get("cursor"); s != "" {
+		if cursor, err = parseExportCursor(s); err != nil {
+			serverutil.WriteError(err, w)
+			return
+		}
+	}
+
+	// Exports outlive the write timeout of the server.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	flusher, _ := w.(http.Flusher)
+
+	// The response is only started with the first page, so that invalid requests are answered
+	// with an error status.
+	var writer exportWriter
+	start := func() {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Trailer", strings.Join([]string{ExportCursorTrailer, ExportBytesProcessedTrailer, ExportErrorTrailer}, ", "))
+		w.WriteHeader(http.StatusOK)
+		writer = newWriter(w)
+	}
+	next, scanned, err := e.Export(r.Context(), req, cursor, func(entries []exportEntry) error {
+		if writer == nil {
+			start()
+		}
+		if err := writer.Write(entries); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil && writer == nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	if writer == nil {
+		start()
+	}
+
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if next != nil {
+		w.Header().Set(ExportCursorTrailer, next.String())
+	}
+	w.Header().Set(ExportBytesProcessedTrailer, strconv.FormatInt(scanned, 10))
+	if err != nil {
+		level.Warn(e.logger).Log("msg", "export stopped", "query", req.Query, "err", err)
+		w.Header().Set(ExportErrorTrailer, err.Error())
+	}
+}
+
+// exportWriter encodes the lines of an export.
+type exportWriter interface {
+	Write([]exportEntry) error
+	Close() error
+}
+
+func newExportWriter(format string) (func(io.Writer) exportWriter, string, error) {
+	switch format {
+	case ExportFormatNDJSON:
+		return func(w io.Writer) exportWriter { return &ndjsonExportWriter{enc: json.NewEncoder(w)} }, NDJSONType, nil
+	case ExportFormatProtobuf:
+		return func(w io.Writer) exportWriter { return &protobufExportWriter{w: w} }, ProtobufType, nil
+	case ExportFormatParquet:
+		return func(w io.Writer) exportWriter {
+			return &parquetExportWriter{w: parquet.NewGenericWriter[LogStreamRowType](w, parquet.SchemaOf(new(LogStreamRowType)))}
+		}, ParquetType, nil
+	default:
+		return nil, "", httpgrpc.Errorf(http.StatusBadRequest, "unsupported format %q: supported formats are %s, %s and %s", format, ExportFormatNDJSON, ExportFormatProtobuf, ExportFormatParquet)
+	}
+}
+
+// exportLabels parses the labels of the streams of a page.
+type exportLabels map[string]map[string]string
+
+func (l exportLabels) get(s string) (map[string]string, error) {
+	if lbls, ok := l[s]; ok {
+		return lbls, nil
+	}
+	parsed, err := syntax.ParseLabels(s)
+	if err != nil {
+		return nil, err
+	}
+	lbls := parsed.Map()
+	l[s] = lbls
+	return lbls, nil
+}
+
+// ndjsonExportWriter writes a JSON object per line.
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+type ndjsonExportEntry struct {
+	Labels             map[string]string `json:"labels"`
+	Timestamp          string            `json:"timestamp"`
+	Line               string            `json:"line"`
+	StructuredMetadata map[string]string `json:"structured_metadata,omitempty"`
+}
+
+func (w *ndjsonExportWriter) Write(entries []exportEntry) error {
+	labels := exportLabels{}
+	for _, entry := range entries {
+		lbls, err := labels.get(entry.Labels)
+		if err != nil {
+			return err
+		}
+		line := ndjsonExportEntry{
+			Labels:    lbls,
+			Timestamp: strconv.FormatInt(entry.Timestamp.UnixNano(), 10),
+			Line:      entry.Line,
+		}
+		if len(entry.StructuredMetadata) > 0 {
+			line.StructuredMetadata = make(map[string]string, len(entry.StructuredMetadata))
+			for _, l := range entry.StructuredMetadata {
+				line.StructuredMetadata[l.Name] = l.Value
+			}
+		}
+		if err := w.enc.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// protobufExportWriter writes the lines of each page as logproto.Stream messages, each prefixed
+// by its size as an unsigned varint.
+type protobufExportWriter struct {
+	w io.Writer
+}
+
+func (w *protobufExportWriter) Write(entries []exportEntry) error {
+	var streams []*logproto.Stream
+	byLabels := make(map[string]*logproto.Stream)
+	for _, entry := range entries {
+		stream, ok := byLabels[entry.Labels]
+		if !ok {
+			stream = &logproto.Stream{Labels: entry.Labels}
+			byLabels[entry.Labels] = stream
+			streams = append(streams, stream)
+		}
+		stream.Entries = append(stream.Entries, entry.Entry)
+	}
+
+	var size [binary.MaxVarintLen64]byte
+	for _, stream := range streams {
+		b, err := stream.Marshal()
+		if err != nil {
+			return err
+		}
+		n := binary.PutUvarint(size[:], uint64(len(b)))
+		if _, err := w.w.Write(size[:n]); err != nil {
+			return err
+		}
+		if _, err := w.w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *protobufExportWriter) Close() error {
+	return nil
+}
+
+// parquetExportWriter writes the lines in the Parquet encoding of the log query responses, with
+// a row group per page.
+type parquetExportWriter struct {
+	w *parquet.GenericWriter[LogStreamRowType]
+}
+
+func (w *parquetExportWriter) Write(entries []exportEntry) error {
+	labels := exportLabels{}
+	rows := make([]LogStreamRowType, 0, len(entries))
+	for _, entry := range entries {
+		lbls, err := labels.get(entry.Labels)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, LogStreamRowType{
+			Timestamp: entry.Timestamp.UnixNano(),
+			Labels:    lbls,
+			Line:      entry.Line,
+		})
+	}
+	if _, err := w.w.Write(rows); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *parquetExportWriter) Close() error {
+	return w.w.Close()
+}
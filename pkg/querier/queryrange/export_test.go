@@ -0,0 +1,307 @@
+package queryrange
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+)
+
+type exportLimits struct {
+	fakeLimits
+	maxBytesScanned int
+}
+
+func (l exportLimits) ExportMaxBytesScanned(string) int {
+	return l.maxBytesScanned
+}
+
+// exportHandler returns the lines of its streams within the range of the requests, limited
+// by their limit, and scans 100 bytes per request.
+type exportHandler struct {
+	streams []logproto.Stream
+	calls   int
+}
+
+func (h *exportHandler) Do(_ context.Context, r base.Request) (base.Response, error) {
+	h.calls++
+	req := r.(*LokiRequest)
+
+	var entries []exportEntry
+	for _, stream := range h.streams {
+		for _, entry := range stream.Entries {
+			if !entry.Timestamp.Before(req.StartTs) && entry.Timestamp.Before(req.EndTs) {
+				entries = append(entries, exportEntry{Labels: stream.Labels, Entry: entry})
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if req.Direction == logproto.BACKWARD {
+			return entries[i].Timestamp.After(entries[j].Timestamp)
+		}
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	if len(entries) > int(req.Limit) {
+		entries = entries[:req.Limit]
+	}
+
+	var streams []logproto.Stream
+	for _, e := range entries {
+		i := sort.Search(len(streams), func(i int) bool { return streams[i].Labels >= e.Labels })
+		if i == len(streams) || streams[i].Labels != e.Labels {
+			streams = append(streams, logproto.Stream{})
+			copy(streams[i+1:], streams[i:])
+			streams[i] = logproto.Stream{Labels: e.Labels}
+		}
+		streams[i].Entries = append(streams[i].Entries, e.Entry)
+	}
+	return &LokiResponse{
+		Status:     loghttp.QueryStatusSuccess,
+		Direction:  req.Direction,
+		Limit:      req.Limit,
+		Version:    uint32(loghttp.VersionV1),
+		Data:       LokiData{ResultType: loghttp.ResultTypeStream, Result: streams},
+		Statistics: stats.Result{Summary: stats.Summary{TotalBytesProcessed: 100}},
+	}, nil
+}
+
+// exportStreams returns two streams with a line every 20 minutes over 4 hours, and three lines
+// with the same timestamp.
+func exportStreams() []logproto.Stream {
+	start := testTime.Truncate(time.Hour).Add(-4 * time.Hour)
+	streams := []logproto.Stream{{Labels: `{app="bar"}`}, {Labels: `{app="foo"}`}}
+	for ts := start; ts.Before(testTime.Truncate(time.Hour)); ts = ts.Add(20 * time.Minute) {
+		for i := range streams {
+			streams[i].Entries = append(streams[i].Entries, logproto.Entry{Timestamp: ts, Line: ts.Format(time.RFC3339)})
+		}
+	}
+	streams[0].Entries = append(streams[0].Entries, logproto.Entry{Timestamp: start.Add(20 * time.Minute), Line: "same timestamp"})
+	sort.SliceStable(streams[0].Entries, func(i, j int) bool {
+		return streams[0].Entries[i].Timestamp.Before(streams[0].Entries[j].Timestamp)
+	})
+	return streams
+}
+
+func newExportRequest(query string, direction logproto.Direction) *LokiRequest {
+	return &LokiRequest{
+		Query:     query,
+		Limit:     100,
+		StartTs:   testTime.Truncate(time.Hour).Add(-4 * time.Hour),
+		EndTs:     testTime.Truncate(time.Hour),
+		Direction: direction,
+		Path:      "/loki/api/v1/export",
+		Plan: &plan.QueryPlan{
+			AST: syntax.MustParseExpr(query),
+		},
+	}
+}
+
+func newTestExporter(h base.Handler, maxBytesScanned int) *Exporter {
+	cfg := ExportConfig{Enabled: true, SplitInterval: time.Hour, PageSize: 10}
+	limits := exportLimits{
+		fakeLimits:      fakeLimits{maxEntriesLimitPerQuery: 4, queryTimeout: time.Minute},
+		maxBytesScanned: maxBytesScanned,
+	}
+	return NewExporter(cfg, limits, h, log.NewNopLogger())
+}
+
+func TestExporter_Export(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	streams := exportStreams()
+	var want []exportEntry
+	for _, stream := range streams {
+		for _, entry := range stream.Entries {
+			want = append(want, exportEntry{Labels: stream.Labels, Entry: entry})
+		}
+	}
+
+	for _, direction := range []logproto.Direction{logproto.FORWARD, logproto.BACKWARD} {
+		t.Run(direction.String(), func(t *testing.T) {
+			h := &exportHandler{streams: streams}
+			e := newTestExporter(h, 0)
+
+			var got []exportEntry
+			cursor, scanned, err := e.Export(ctx, newExportRequest(`{app=~".+"}`, direction), nil, func(entries []exportEntry) error {
+				// The pages are limited by max_entries_limit_per_query.
+				require.LessOrEqual(t, len(entries), 4)
+				got = append(got, entries...)
+				return nil
+			})
+			require.NoError(t, err)
+			require.Nil(t, cursor)
+			require.Equal(t, int64(h.calls*100), scanned)
+
+			// All the lines are exported once, in the direction of the query.
+			require.Len(t, got, len(want))
+			require.ElementsMatch(t, want, got)
+			require.True(t, sort.SliceIsSorted(got, func(i, j int) bool {
+				if direction == logproto.BACKWARD {
+					return got[i].Timestamp.After(got[j].Timestamp)
+				}
+				return got[i].Timestamp.Before(got[j].Timestamp)
+			}))
+		})
+	}
+}
+
+func TestExporter_ExportResume(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	h := &exportHandler{streams: exportStreams()}
+	e := newTestExporter(h, 200)
+	req := newExportRequest(`{app=~".+"}`, logproto.FORWARD)
+
+	var got []exportEntry
+	write := func(entries []exportEntry) error {
+		got = append(got, entries...)
+		return nil
+	}
+
+	// Each export stops after scanning its quota, and returns the cursor to resume from.
+	var cursor *exportCursor
+	var exports int
+	for {
+		next, scanned, err := e.Export(ctx, req, cursor, write)
+		require.NoError(t, err)
+		require.Equal(t, int64(200), scanned)
+		exports++
+		if next == nil {
+			break
+		}
+		cursor, err = parseExportCursor(next.String())
+		require.NoError(t, err)
+	}
+	require.Greater(t, exports, 1)
+	require.Len(t, got, 25)
+	require.Equal(t, `{app="bar"}`, got[0].Labels)
+	require.Equal(t, req.StartTs, got[0].Timestamp)
+}
+
+func TestExporter_ExportErrors(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	write := func([]exportEntry) error { return nil }
+
+	_, _, err := newTestExporter(&exportHandler{}, 0).Export(ctx, newExportRequest(`count_over_time({app="foo"}[1m])`, logproto.FORWARD), nil, write)
+	require.ErrorContains(t, err, "only log queries can be exported")
+
+	req := newExportRequest(`{app="foo"}`, logproto.FORWARD)
+	_, _, err = newTestExporter(&exportHandler{}, 0).Export(ctx, req, &exportCursor{Timestamp: req.EndTs.UnixNano()}, write)
+	require.ErrorContains(t, err, "the cursor is outside of the time range of the query")
+
+	// The lines of a timestamp must fit in a page.
+	stream := logproto.Stream{Labels: `{app="foo"}`}
+	for i := 0; i < 5; i++ {
+		stream.Entries = append(stream.Entries, logproto.Entry{Timestamp: req.StartTs, Line: strconv.Itoa(i)})
+	}
+	var got []exportEntry
+	cursor, _, err := newTestExporter(&exportHandler{streams: []logproto.Stream{stream}}, 0).Export(ctx, req, nil, func(entries []exportEntry) error {
+		got = append(got, entries...)
+		return nil
+	})
+	require.ErrorContains(t, err, "more than 4 lines have the timestamp")
+	require.Len(t, got, 4)
+	require.Len(t, cursor.Seen, 4)
+}
+
+func exportHTTPRequest(t *testing.T, e *Exporter, params url.Values) *http.Response {
+	params.Set("query", `{app=~".+"}`)
+	params.Set("start", strconv.FormatInt(testTime.Truncate(time.Hour).Add(-4*time.Hour).UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(testTime.Truncate(time.Hour).UnixNano(), 10))
+	params.Set("direction", "forward")
+	r := httptest.NewRequest(http.MethodGet, "/loki/api/v1/export?"+params.Encode(), nil)
+	r = r.WithContext(user.InjectOrgID(r.Context(), "1"))
+	w := httptest.NewRecorder()
+	e.Handler(w, r)
+	return w.Result()
+}
+
+func TestExporter_Handler(t *testing.T) {
+	t.Run("ndjson", func(t *testing.T) {
+		res := exportHTTPRequest(t, newTestExporter(&exportHandler{streams: exportStreams()}, 0), url.Values{})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, NDJSONType, res.Header.Get("Content-Type"))
+
+		scanner := bufio.NewScanner(res.Body)
+		var lines []ndjsonExportEntry
+		for scanner.Scan() {
+			var line ndjsonExportEntry
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+		require.Len(t, lines, 25)
+		require.Equal(t, map[string]string{"app": "bar"}, lines[0].Labels)
+		require.Equal(t, strconv.FormatInt(testTime.Truncate(time.Hour).Add(-4*time.Hour).UnixNano(), 10), lines[0].Timestamp)
+
+		require.Empty(t, res.Trailer.Get(ExportCursorTrailer))
+		require.Empty(t, res.Trailer.Get(ExportErrorTrailer))
+		require.NotEmpty(t, res.Trailer.Get(ExportBytesProcessedTrailer))
+	})
+
+	t.Run("protobuf", func(t *testing.T) {
+		res := exportHTTPRequest(t, newTestExporter(&exportHandler{streams: exportStreams()}, 0), url.Values{"format": []string{"protobuf"}})
+		require.Equal(t, ProtobufType, res.Header.Get("Content-Type"))
+
+		r := bufio.NewReader(res.Body)
+		var lines int
+		for {
+			size, err := binary.ReadUvarint(r)
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			b := make([]byte, size)
+			_, err = io.ReadFull(r, b)
+			require.NoError(t, err)
+			var stream logproto.Stream
+			require.NoError(t, stream.Unmarshal(b))
+			lines += len(stream.Entries)
+		}
+		require.Equal(t, 25, lines)
+	})
+
+	t.Run("parquet with quota", func(t *testing.T) {
+		res := exportHTTPRequest(t, newTestExporter(&exportHandler{streams: exportStreams()}, 100), url.Values{"format": []string{"parquet"}})
+		require.Equal(t, ParquetType, res.Header.Get("Content-Type"))
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "PAR1", string(b[:4]))
+
+		cursor := res.Trailer.Get(ExportCursorTrailer)
+		require.NotEmpty(t, cursor)
+		require.Equal(t, "100", res.Trailer.Get(ExportBytesProcessedTrailer))
+
+		res = exportHTTPRequest(t, newTestExporter(&exportHandler{streams: exportStreams()}, 100), url.Values{"format": []string{"parquet"}, "cursor": []string{cursor}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NotEqual(t, cursor, res.Trailer.Get(ExportCursorTrailer))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		res := exportHTTPRequest(t, newTestExporter(&exportHandler{}, 0), url.Values{"format": []string{"csv"}})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		res = exportHTTPRequest(t, newTestExporter(&exportHandler{}, 0), url.Values{"cursor": []string{"!"}})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(b), "invalid cursor"))
+	})
+}
@@ -0,0 +1,137 @@
+package queryrange
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+// FlightServer is an Arrow Flight service streaming the results of the requests
+// to the query API as Arrow record batches, with the same schemas as the Arrow
+// encoded HTTP responses.
+//
+// The ticket of a DoGet call is the path and the parameters of the request, for
+// example /loki/api/v1/query_range?query={app="foo"}&limit=10000. The tenant is
+// authenticated like for the other gRPC services.
+//
+// The splits of log range queries are executed in the direction of the query, by
+// the split interval of the tenant, and the lines of each split are streamed as
+// soon as it completes, until the limit of the query is reached. The responses of
+// the other requests, such as metric queries, are merged by the query frontend
+// before being streamed, so they are kept in memory until the end of the request.
+type FlightServer struct {
+	flight.BaseFlightServer
+
+	next   queryrangebase.Handler
+	codec  queryrangebase.Codec
+	limits Limits
+	logger log.Logger
+	now    func() time.Time
+}
+
+func NewFlightServer(next queryrangebase.Handler, codec queryrangebase.Codec, limits Limits, logger log.Logger) *FlightServer {
+	return &FlightServer{
+		next:   next,
+		codec:  codec,
+		limits: limits,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *FlightServer) DoGet(ticket *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	sp, ctx := opentracing.StartSpanFromContext(stream.Context(), "FlightServer.DoGet")
+	defer sp.Finish()
+
+	u, err := url.ParseRequestURI(string(ticket.GetTicket()))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid ticket: %s", err)
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid ticket: %s", err)
+	}
+	r.RequestURI = u.RequestURI()
+
+	req, err := s.codec.DecodeRequest(ctx, r, nil)
+	if err != nil {
+		return serverutil.ClientGrpcStatusAndError(err)
+	}
+
+	sink := rowSink{
+		arrow: func(schema *arrow.Schema) (arrowRecordWriter, error) {
+			return flight.NewRecordWriter(stream, ipc.WithSchema(schema), ipc.WithAllocator(memory.DefaultAllocator)), nil
+		},
+	}
+	if lokiReq, ok := req.(*LokiRequest); ok && isLogQuery(lokiReq) {
+		err = s.streamSplits(ctx, lokiReq, sink)
+	} else {
+		err = s.stream(ctx, req, sink)
+	}
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to stream the response", "path", u.Path, "err", err)
+		return serverutil.ClientGrpcStatusAndError(err)
+	}
+	return nil
+}
+
+// streamSplits executes the splits of a log range query in the direction of the
+// query, and writes the lines of each split as soon as it completes.
+func (s *FlightServer) streamSplits(ctx context.Context, req *LokiRequest, sink rowSink) error {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, s.limits.QuerySplitDuration)
+
+	w := newRowsSplitResultsWriter(sink)
+	var lines uint32
+	for _, split := range splitInOrder(s.limits, s.now().UTC(), tenantIDs, req, interval) {
+		res, err := s.next.Do(ctx, split)
+		if err != nil {
+			return err
+		}
+		res, lines = limitSplitResponse(res, req.Limit, lines)
+		if err := w.Write(res); err != nil {
+			return err
+		}
+		if req.Limit > 0 && lines >= req.Limit {
+			break
+		}
+	}
+	return w.Close()
+}
+
+func (s *FlightServer) stream(ctx context.Context, req queryrangebase.Request, sink rowSink) error {
+	res, err := s.next.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	return encodeResponseRowsTo(res, sink, "Arrow")
+}
+
+func isLogQuery(req *LokiRequest) bool {
+	if req.Plan == nil {
+		return false
+	}
+	_, ok := req.Plan.AST.(syntax.SampleExpr)
+	return !ok
+}
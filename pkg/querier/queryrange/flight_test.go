@@ -0,0 +1,130 @@
+package queryrange
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/middleware"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+func TestFlightServer(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		requests []*LokiRequest
+	)
+	// Each split returns two lines, at its start and a nanosecond later, in the
+	// direction of the query.
+	handler := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		req, ok := r.(*LokiRequest)
+		if !ok || req.Query != `{app="foo"}` {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, "unexpected request %v", r)
+		}
+		mtx.Lock()
+		requests = append(requests, req)
+		mtx.Unlock()
+
+		entries := []logproto.Entry{
+			{Timestamp: req.StartTs, Line: fmt.Sprintf("%d-1", req.StartTs.Unix())},
+			{Timestamp: req.StartTs.Add(time.Nanosecond), Line: fmt.Sprintf("%d-2", req.StartTs.Unix())},
+		}
+		if req.Direction == logproto.BACKWARD {
+			entries[0], entries[1] = entries[1], entries[0]
+		}
+		return &LokiResponse{
+			Status:    loghttp.QueryStatusSuccess,
+			Direction: req.Direction,
+			Limit:     req.Limit,
+			Data: LokiData{
+				ResultType: loghttp.ResultTypeStream,
+				Result:     []logproto.Stream{{Labels: `{app="foo"}`, Entries: entries}},
+			},
+		}, nil
+	})
+
+	limits := fakeLimits{splitDuration: map[string]time.Duration{"fake": time.Hour}}
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.StreamInterceptor(middleware.StreamServerUserHeaderInterceptor))
+	flight.RegisterFlightServiceServer(server, NewFlightServer(handler, DefaultCodec, limits, util_log.Logger))
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	client, err := flight.NewClientWithMiddleware(lis.Addr().String(), nil, nil,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStreamInterceptor(middleware.StreamClientUserHeaderInterceptor),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	doGet := func(ticket string) ([]string, []*LokiRequest) {
+		mtx.Lock()
+		requests = nil
+		mtx.Unlock()
+
+		stream, err := client.DoGet(ctx, &flight.Ticket{Ticket: []byte(ticket)})
+		require.NoError(t, err)
+		r, err := flight.NewRecordReader(stream)
+		require.NoError(t, err)
+		defer r.Release()
+
+		var lines []string
+		for r.Next() {
+			rec := r.Record()
+			col := rec.Column(rec.Schema().FieldIndices("line")[0])
+			for i := 0; i < col.Len(); i++ {
+				lines = append(lines, col.ValueStr(i))
+			}
+		}
+		require.NoError(t, r.Err())
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		return lines, requests
+	}
+
+	// The splits are executed and streamed in the direction of the query.
+	lines, reqs := doGet(`/loki/api/v1/query_range?query={app="foo"}&start=0&end=10800&direction=forward&limit=100`)
+	require.Equal(t, []string{"0-1", "0-2", "3600-1", "3600-2", "7200-1", "7200-2"}, lines)
+	require.Len(t, reqs, 3)
+
+	lines, reqs = doGet(`/loki/api/v1/query_range?query={app="foo"}&start=0&end=10800&direction=backward&limit=100`)
+	require.Equal(t, []string{"7200-2", "7200-1", "3600-2", "3600-1", "0-2", "0-1"}, lines)
+	require.Len(t, reqs, 3)
+
+	// The splits stop once the limit is reached, and the lines above it are dropped.
+	lines, reqs = doGet(`/loki/api/v1/query_range?query={app="foo"}&start=0&end=10800&direction=forward&limit=3`)
+	require.Equal(t, []string{"0-1", "0-2", "3600-1"}, lines)
+	require.Len(t, reqs, 2)
+
+	// The errors of the requests are returned with their status code.
+	stream, err := client.DoGet(ctx, &flight.Ticket{Ticket: []byte(`/loki/api/v1/query_range?query={app="bar"}&start=0&end=10`)})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+
+	stream, err = client.DoGet(ctx, &flight.Ticket{Ticket: []byte(`/loki/api/v1/query_range?query={app=`)})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	resp, ok = httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+}
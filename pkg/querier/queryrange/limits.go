@@ -311,6 +311,29 @@ func (q *querySizeLimiter) getBytesReadForRequest(ctx context.Context, r queryra
 	return combinedStats.Bytes, nil
 }
 
+type queryBytesReadKey struct{}
+
+// queryBytesRead is the number of bytes a query would read, estimated from the index stats,
+// for the limits of the same query down the middleware chain.
+type queryBytesRead struct {
+	query      string
+	start, end time.Time
+	bytes      uint64
+}
+
+func injectQueryBytesRead(ctx context.Context, r queryrangebase.Request, bytes uint64) context.Context {
+	return context.WithValue(ctx, queryBytesReadKey{}, queryBytesRead{query: r.GetQuery(), start: r.GetStart(), end: r.GetEnd(), bytes: bytes})
+}
+
+// queryBytesReadFromContext returns the bytes read estimated for r by a previous limit, if any.
+func queryBytesReadFromContext(ctx context.Context, r queryrangebase.Request) (uint64, bool) {
+	b, ok := ctx.Value(queryBytesReadKey{}).(queryBytesRead)
+	if !ok || b.query != r.GetQuery() || !b.start.Equal(r.GetStart()) || !b.end.Equal(r.GetEnd()) {
+		return 0, false
+	}
+	return b.bytes, true
+}
+
 func (q *querySizeLimiter) getSchemaCfg(r queryrangebase.Request) (config.PeriodConfig, error) {
 	maxRVDuration, maxOffset, err := maxRangeVectorAndOffsetDurationFromQueryString(r.GetQuery())
 	if err != nil {
@@ -367,6 +390,7 @@ func (q *querySizeLimiter) Do(ctx context.Context, r queryrangebase.Request) (qu
 			level.Warn(log).Log("msg", "Query exceeds limits", "status", "rejected", "limit_name", q.guessLimitName(), "limit_bytes", maxBytesReadStr, "resolved_bytes", statsBytesStr)
 			return nil, httpgrpc.Errorf(http.StatusBadRequest, q.limitErrorTmpl, statsBytesStr, maxBytesReadStr)
 		}
+		ctx = injectQueryBytesRead(ctx, r, bytesRead)
 	}
 
 	return q.next.Do(ctx, r)
@@ -33,9 +33,26 @@ type Limits interface {
 	RequiredNumberLabels(context.Context, string) int
 	MaxQueryBytesRead(context.Context, string) int
 	MaxQuerierBytesRead(context.Context, string) int
+	QueryScanQuotaPerFrontendHourly(string) int
+	QueryScanQuotaPerFrontendDaily(string) int
+	QueryScanQuotaSoftRatio(string) float64
 	MaxStatsCacheFreshness(context.Context, string) time.Duration
 	MaxMetadataCacheFreshness(context.Context, string) time.Duration
 	VolumeEnabled(string) bool
 
 	ShardAggregations(string) []string
 }
+
+// AsyncQueryLimits are the per-tenant limits of the asynchronous query jobs of the query frontend.
+type AsyncQueryLimits interface {
+	Limits
+	AsyncQueryMaxConcurrentJobs(userID string) int
+	AsyncQueryMaxQueuedJobs(userID string) int
+	AsyncQueryResultsTTL(userID string) time.Duration
+}
+
+// ExportLimits are the per-tenant limits of the streaming export API of the query frontend.
+type ExportLimits interface {
+	Limits
+	ExportMaxBytesScanned(userID string) int
+}
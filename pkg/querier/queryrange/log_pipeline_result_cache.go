@@ -0,0 +1,425 @@
+package queryrange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"text/template/parse"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+// logPipelineResultTruncated is the status of the cached results of a split whose lines matching
+// the prefix of the pipeline exceed the max entries limit. Such splits are not cached, and their
+// queries are executed by the queriers.
+const logPipelineResultTruncated = "truncated"
+
+// nonDeterministicFunctions are the template functions of line_format and label_format stages
+// whose results do not only depend on the log line.
+var nonDeterministicFunctions = map[string]struct{}{
+	"now": {},
+}
+
+// LogPipelineResultCacheMetrics is the metrics wrapper used in the log pipeline result cache.
+type LogPipelineResultCacheMetrics struct {
+	CacheHit       prometheus.Counter
+	CacheMiss      prometheus.Counter
+	CacheTruncated prometheus.Counter
+}
+
+// NewLogPipelineResultCacheMetrics creates metrics to be used in the log pipeline result cache.
+func NewLogPipelineResultCacheMetrics(registerer prometheus.Registerer) *LogPipelineResultCacheMetrics {
+	return &LogPipelineResultCacheMetrics{
+		CacheHit: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "query_frontend_log_pipeline_result_cache_hit_total",
+		}),
+		CacheMiss: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "query_frontend_log_pipeline_result_cache_miss_total",
+		}),
+		CacheTruncated: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "query_frontend_log_pipeline_result_cache_truncated_total",
+			Help:      "Total number of splits not cached because the lines matching the prefix of their pipeline exceed the max entries limit.",
+		}),
+	}
+}
+
+// NewLogPipelineResultCache creates a new log pipeline result cache middleware.
+// It caches the lines of a split matching the stream selector and the line filters at the start
+// of the pipeline of log queries, and applies the remaining stages of the pipeline to the cached
+// lines. Queries sharing the same selector and line filters, such as `{app="x"} |= "err"` and
+// `{app="x"} |= "err" | json`, reuse the same cached results.
+//
+// The lines are cached only when all the lines of the split matching the prefix fit in the max
+// entries limit of the tenant, and only for queries whose remaining stages are deterministic.
+// The cache keys contain the results cache generation number of the tenants, so the lines
+// removed by delete requests are not served from the cache.
+func NewLogPipelineResultCache(logger log.Logger, limits Limits, c cache.Cache, shouldCache queryrangebase.ShouldCacheFn,
+	transformer UserIDTransformer, cacheGenNumLoader queryrangebase.CacheGenNumberLoader, retentionEnabled bool,
+	metrics *LogPipelineResultCacheMetrics) queryrangebase.Middleware {
+	if metrics == nil {
+		metrics = NewLogPipelineResultCacheMetrics(nil)
+	}
+	if cacheGenNumLoader != nil {
+		c = cache.NewCacheGenNumMiddleware(c)
+	}
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return &logPipelineResultCache{
+			next:              next,
+			limits:            limits,
+			cache:             c,
+			logger:            logger,
+			shouldCache:       shouldCache,
+			transformer:       transformer,
+			cacheGenNumLoader: cacheGenNumLoader,
+			retentionEnabled:  retentionEnabled,
+			metrics:           metrics,
+		}
+	})
+}
+
+type logPipelineResultCache struct {
+	next              queryrangebase.Handler
+	limits            Limits
+	cache             cache.Cache
+	shouldCache       queryrangebase.ShouldCacheFn
+	transformer       UserIDTransformer
+	cacheGenNumLoader queryrangebase.CacheGenNumberLoader
+	retentionEnabled  bool
+
+	metrics *LogPipelineResultCacheMetrics
+	logger  log.Logger
+}
+
+func (l *logPipelineResultCache) Do(ctx context.Context, req queryrangebase.Request) (queryrangebase.Response, error) {
+	sp, ctx := opentracing.StartSpanFromContext(ctx, "logPipelineResultCache.Do")
+	defer sp.Finish()
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+
+	if l.shouldCache != nil && !l.shouldCache(ctx, req) {
+		return l.next.Do(ctx, req)
+	}
+
+	lokiReq, ok := req.(*LokiRequest)
+	if !ok {
+		return nil, httpgrpc.Errorf(http.StatusInternalServerError, "invalid request type %T", req)
+	}
+	// Sampled log queries and sharded requests are not cached.
+	if lokiReq.Interval != 0 || len(lokiReq.Shards) > 0 || lokiReq.Limit == 0 {
+		return l.next.Do(ctx, req)
+	}
+
+	var expr syntax.Expr
+	if lokiReq.Plan != nil {
+		expr = lokiReq.Plan.AST
+	} else if expr, err = syntax.ParseExpr(lokiReq.Query); err != nil {
+		return l.next.Do(ctx, req)
+	}
+	prefix, stages, ok := splitLogPipeline(expr)
+	if !ok {
+		return l.next.Do(ctx, req)
+	}
+
+	interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.limits.QuerySplitDuration)
+	maxEntries := validation.SmallestPositiveIntPerTenant(tenantIDs, func(id string) int { return l.limits.MaxEntriesLimitPerQuery(ctx, id) })
+	if interval == 0 || maxEntries == 0 {
+		return l.next.Do(ctx, req)
+	}
+
+	// The lines of the whole split interval of the request are cached, so they are reused by the
+	// first and last splits of queries, which might not be aligned.
+	alignedStart := time.Unix(0, lokiReq.StartTs.UnixNano()-(lokiReq.StartTs.UnixNano()%interval.Nanoseconds()))
+	alignedEnd := alignedStart.Add(interval)
+	cacheFreshnessCapture := func(id string) time.Duration { return l.limits.MaxCacheFreshness(ctx, id) }
+	maxCacheFreshness := validation.MaxDurationPerTenant(tenantIDs, cacheFreshnessCapture)
+	if lokiReq.EndTs.After(alignedEnd) || alignedEnd.UnixMilli() > int64(model.Now().Add(-maxCacheFreshness)) {
+		return l.next.Do(ctx, req)
+	}
+
+	prefixReq := lokiReq.WithStartEnd(alignedStart, alignedEnd).(*LokiRequest)
+	prefixReq.Query = prefix.String()
+	prefixReq.Plan = &plan.QueryPlan{AST: prefix}
+	prefixReq.Limit = uint32(maxEntries)
+	cacheKey := "pipeline:" + logResultCacheKey(ctx, tenantIDs, l.transformer, prefixReq, interval)
+	if l.cacheGenNumLoader != nil && l.retentionEnabled {
+		ctx = cache.InjectCacheGenNumber(ctx, l.cacheGenNumLoader.GetResultsCacheGenNumber(tenantIDs))
+	}
+
+	_, buff, _, err := l.cache.Fetch(ctx, []string{cache.HashKey(cacheKey)})
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "error fetching cache", "err", err, "cacheKey", cacheKey)
+		return l.next.Do(ctx, req)
+	}
+	if len(buff) != 1 {
+		return l.handleMiss(ctx, cacheKey, lokiReq, prefixReq, stages)
+	}
+
+	var cached LokiResponse
+	if err := proto.Unmarshal(buff[0], &cached); err != nil {
+		level.Warn(l.logger).Log("msg", "error unmarshalling response from cache", "err", err)
+		return l.next.Do(ctx, req)
+	}
+	if cached.Status == logPipelineResultTruncated {
+		return l.next.Do(ctx, req)
+	}
+	l.metrics.CacheHit.Inc()
+	return applyLogPipeline(ctx, lokiReq, &cached, stages)
+}
+
+func (l *logPipelineResultCache) handleMiss(ctx context.Context, cacheKey string, req, prefixReq *LokiRequest, stages syntax.MultiStageExpr) (queryrangebase.Response, error) {
+	l.metrics.CacheMiss.Inc()
+	level.Debug(l.logger).Log("msg", "cache miss", "key", cacheKey)
+
+	// The stream labels of the cached lines must not contain their structured metadata, to apply
+	// the remaining stages to them.
+	flags := httpreq.ExtractEncodingFlagsFromCtx(ctx)
+	flags.Set(httpreq.FlagCategorizeLabels)
+	resp, err := l.next.Do(httpreq.AddEncodingFlagsToContext(ctx, flags), prefixReq)
+	if err != nil {
+		return nil, err
+	}
+	prefixRes, ok := resp.(*LokiResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", resp)
+	}
+	if prefixRes.Status != loghttp.QueryStatusSuccess {
+		return prefixRes, nil
+	}
+
+	cached := &LokiResponse{
+		Status: prefixRes.Status,
+		Data:   prefixRes.Data,
+	}
+	var entries int
+	for _, stream := range prefixRes.Data.Result {
+		entries += len(stream.Entries)
+	}
+	truncated := entries >= int(prefixReq.Limit)
+	if truncated {
+		l.metrics.CacheTruncated.Inc()
+		cached = &LokiResponse{Status: logPipelineResultTruncated}
+	}
+
+	data, err := proto.Marshal(cached)
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "error marshalling response", "err", err)
+	} else if err := l.cache.Store(ctx, []string{cache.HashKey(cacheKey)}, [][]byte{data}); err != nil {
+		level.Warn(l.logger).Log("msg", "error storing cache", "err", err)
+	}
+
+	if truncated {
+		return l.next.Do(ctx, req)
+	}
+	res, err := applyLogPipeline(ctx, req, cached, stages)
+	if err != nil {
+		return nil, err
+	}
+	res.Statistics = prefixRes.Statistics
+	return res, nil
+}
+
+// splitLogPipeline splits a log query into its stream selector and the line filters at the start
+// of its pipeline, and the remaining stages of the pipeline. It returns false if the query has no
+// such line filters, or if the remaining stages are not deterministic.
+func splitLogPipeline(expr syntax.Expr) (syntax.LogSelectorExpr, syntax.MultiStageExpr, bool) {
+	if _, ok := expr.(*syntax.PipelineExpr); !ok {
+		return nil, nil, false
+	}
+	// Building the pipeline of the stages modifies their line filters, so the expression is
+	// parsed again not to modify the plan of the request.
+	parsed, err := syntax.ParseLogSelector(expr.String(), true)
+	if err != nil {
+		return nil, nil, false
+	}
+	p, ok := parsed.(*syntax.PipelineExpr)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var i int
+	for ; i < len(p.MultiStages); i++ {
+		if _, ok := p.MultiStages[i].(*syntax.LineFilterExpr); !ok {
+			break
+		}
+	}
+	if i == 0 {
+		return nil, nil, false
+	}
+
+	stages := p.MultiStages[i:]
+	for _, stage := range stages {
+		switch s := stage.(type) {
+		case *syntax.LineFmtExpr:
+			if !deterministicTemplate(s.Value) {
+				return nil, nil, false
+			}
+		case *syntax.LabelFmtExpr:
+			for _, f := range s.Formats {
+				if !f.Rename && !deterministicTemplate(f.Value) {
+					return nil, nil, false
+				}
+			}
+		}
+	}
+
+	prefix := &syntax.PipelineExpr{
+		Left:        p.Left,
+		MultiStages: p.MultiStages[:i:i],
+	}
+	return prefix, stages, true
+}
+
+// applyLogPipeline applies the stages of a pipeline to the cached lines of a split, and returns
+// the lines of the request in the order of its direction, up to its limit.
+func applyLogPipeline(ctx context.Context, req *LokiRequest, cached *LokiResponse, stages syntax.MultiStageExpr) (*LokiResponse, error) {
+	pipeline, err := stages.Pipeline()
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	flags := httpreq.ExtractEncodingFlagsFromCtx(ctx)
+	categorizeLabels := flags.Has(httpreq.FlagCategorizeLabels)
+
+	streams := make(map[string]*logproto.Stream)
+	for _, stream := range cached.Data.Result {
+		lbls, err := syntax.ParseLabels(stream.Labels)
+		if err != nil {
+			return nil, err
+		}
+		streamPipeline := pipeline.ForStream(lbls)
+		for _, entry := range stream.Entries {
+			if entry.Timestamp.Before(req.StartTs) || !entry.Timestamp.Before(req.EndTs) {
+				continue
+			}
+			structuredMetadata := make([]labels.Label, len(entry.StructuredMetadata))
+			copy(structuredMetadata, logproto.FromLabelAdaptersToLabels(entry.StructuredMetadata))
+			line, result, ok := streamPipeline.Process(entry.Timestamp.UnixNano(), []byte(entry.Line), structuredMetadata...)
+			if !ok {
+				continue
+			}
+
+			key := result.String()
+			if categorizeLabels {
+				key = result.Stream().String()
+			}
+			s, ok := streams[key]
+			if !ok {
+				s = &logproto.Stream{Labels: key}
+				streams[key] = s
+			}
+			s.Entries = append(s.Entries, logproto.Entry{
+				Timestamp:          entry.Timestamp,
+				Line:               string(line),
+				StructuredMetadata: logproto.FromLabelsToLabelAdapters(result.StructuredMetadata()),
+				Parsed:             logproto.FromLabelsToLabelAdapters(result.Parsed()),
+			})
+		}
+	}
+
+	res := emptyResponse(req)
+	for _, s := range streams {
+		sort.SliceStable(s.Entries, func(i, j int) bool {
+			if req.Direction == logproto.BACKWARD {
+				return s.Entries[i].Timestamp.After(s.Entries[j].Timestamp)
+			}
+			return s.Entries[i].Timestamp.Before(s.Entries[j].Timestamp)
+		})
+		res.Data.Result = append(res.Data.Result, *s)
+	}
+	res.Data.Result = mergeOrderedNonOverlappingStreams([]*LokiResponse{res}, req.Limit, req.Direction)
+	return res, nil
+}
+
+// deterministicTemplate returns whether the template of a line_format or label_format stage
+// does not call any of the nonDeterministicFunctions. Templates that cannot be parsed are not
+// deterministic.
+func deterministicTemplate(tmpl string) bool {
+	t := parse.New("template")
+	t.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := t.Parse(tmpl, "", "", trees); err != nil {
+		return false
+	}
+	for _, tree := range trees {
+		if callsNonDeterministicFunction(tree.Root) {
+			return false
+		}
+	}
+	return true
+}
+
+func callsNonDeterministicFunction(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, node := range n.Nodes {
+			if callsNonDeterministicFunction(node) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return callsNonDeterministicFunction(n.Pipe)
+	case *parse.TemplateNode:
+		return callsNonDeterministicFunction(n.Pipe)
+	case *parse.IfNode:
+		return branchCallsNonDeterministicFunction(&n.BranchNode)
+	case *parse.RangeNode:
+		return branchCallsNonDeterministicFunction(&n.BranchNode)
+	case *parse.WithNode:
+		return branchCallsNonDeterministicFunction(&n.BranchNode)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if callsNonDeterministicFunction(cmd) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if callsNonDeterministicFunction(arg) {
+				return true
+			}
+		}
+	case *parse.ChainNode:
+		return callsNonDeterministicFunction(n.Node)
+	case *parse.IdentifierNode:
+		_, ok := nonDeterministicFunctions[n.Ident]
+		return ok
+	}
+	return false
+}
+
+func branchCallsNonDeterministicFunction(n *parse.BranchNode) bool {
+	return callsNonDeterministicFunction(n.Pipe) ||
+		callsNonDeterministicFunction(n.List) ||
+		callsNonDeterministicFunction(n.ElseList)
+}
@@ -0,0 +1,273 @@
+package queryrange
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
+
+	"github.com/grafana/loki/pkg/push"
+)
+
+// pipelineCacheHandler returns the lines of the split of testTime for the prefix of the pipeline,
+// and records the requests.
+type pipelineCacheHandler struct {
+	mtx      sync.Mutex
+	requests []*LokiRequest
+	flags    []httpreq.EncodingFlags
+}
+
+func (h *pipelineCacheHandler) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+	req := r.(*LokiRequest)
+	h.mtx.Lock()
+	h.requests = append(h.requests, req)
+	h.flags = append(h.flags, httpreq.ExtractEncodingFlagsFromCtx(ctx))
+	h.mtx.Unlock()
+
+	start := testTime.Truncate(time.Hour)
+	res := emptyResponse(req)
+	res.Data.Result = []logproto.Stream{{
+		Labels: `{app="foo"}`,
+		Entries: []logproto.Entry{
+			{Timestamp: start.Add(time.Minute), Line: `{"level":"error","msg":"err a"}`},
+			{Timestamp: start.Add(2 * time.Minute), Line: `{"level":"info","msg":"err b"}`},
+			{
+				Timestamp:          start.Add(3 * time.Minute),
+				Line:               `{"level":"error","msg":"err c"}`,
+				StructuredMetadata: []logproto.LabelAdapter{{Name: "trace_id", Value: "abc"}},
+			},
+		},
+	}}
+	return res, nil
+}
+
+func (h *pipelineCacheHandler) Requests() []*LokiRequest {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.requests
+}
+
+func newPipelineCacheRequest(query string, limit uint32, direction logproto.Direction) *LokiRequest {
+	start := testTime.Truncate(time.Hour)
+	return &LokiRequest{
+		Query:     query,
+		Limit:     limit,
+		StartTs:   start.Add(90 * time.Second),
+		EndTs:     start.Add(time.Hour),
+		Direction: direction,
+		Path:      "/loki/api/v1/query_range",
+		Plan: &plan.QueryPlan{
+			AST: syntax.MustParseExpr(query),
+		},
+	}
+}
+
+func newTestLogPipelineResultCache(maxEntries int) (*LogPipelineResultCacheMetrics, queryrangebase.Middleware) {
+	metrics := NewLogPipelineResultCacheMetrics(prometheus.NewRegistry())
+	limits := fakeLimits{
+		splitDuration:           map[string]time.Duration{"foo": time.Hour},
+		maxEntriesLimitPerQuery: maxEntries,
+	}
+	return metrics, NewLogPipelineResultCache(log.NewNopLogger(), limits, cache.NewMockCache(), nil, nil, nil, false, metrics)
+}
+
+func TestLogPipelineResultCache_ReusePrefix(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "foo")
+	metrics, m := newTestLogPipelineResultCache(100)
+	h := &pipelineCacheHandler{}
+	handler := m.Wrap(h)
+
+	res, err := handler.Do(ctx, newPipelineCacheRequest(`{app="foo"} |= "err"`, 1, logproto.FORWARD))
+	require.NoError(t, err)
+	lokiRes := res.(*LokiResponse)
+	require.Len(t, lokiRes.Data.Result, 1)
+	require.Equal(t, `{app="foo"}`, lokiRes.Data.Result[0].Labels)
+	require.Len(t, lokiRes.Data.Result[0].Entries, 1)
+	require.Equal(t, testTime.Truncate(time.Hour).Add(2*time.Minute), lokiRes.Data.Result[0].Entries[0].Timestamp)
+	require.Equal(t, `{"level":"info","msg":"err b"}`, lokiRes.Data.Result[0].Entries[0].Line)
+
+	// The prefix of the pipeline is requested for the whole split, with the max entries limit
+	// and the stream labels categorized.
+	requests := h.Requests()
+	require.Len(t, requests, 1)
+	require.Equal(t, `{app="foo"} |= "err"`, requests[0].Query)
+	require.Equal(t, testTime.Truncate(time.Hour), requests[0].StartTs)
+	require.Equal(t, testTime.Truncate(time.Hour).Add(time.Hour), requests[0].EndTs)
+	require.Equal(t, uint32(100), requests[0].Limit)
+	require.True(t, h.flags[0].Has(httpreq.FlagCategorizeLabels))
+
+	// The remaining stages of the pipeline are applied to the cached lines.
+	res, err = handler.Do(ctx, newPipelineCacheRequest(`{app="foo"} |= "err" | json | level="error"`, 10, logproto.BACKWARD))
+	require.NoError(t, err)
+	lokiRes = res.(*LokiResponse)
+	require.Equal(t, loghttp.QueryStatusSuccess, lokiRes.Status)
+	require.Len(t, lokiRes.Data.Result, 1)
+	require.Equal(t, `{app="foo", level="error", msg="err c", trace_id="abc"}`, lokiRes.Data.Result[0].Labels)
+	require.Len(t, lokiRes.Data.Result[0].Entries, 1)
+	require.Equal(t, `{"level":"error","msg":"err c"}`, lokiRes.Data.Result[0].Entries[0].Line)
+
+	// With categorized labels, the stream labels do not contain the structured metadata and the
+	// parsed labels.
+	categorizedCtx := httpreq.InjectHeader(ctx, httpreq.LokiEncodingFlagsHeader, string(httpreq.FlagCategorizeLabels))
+	res, err = handler.Do(categorizedCtx, newPipelineCacheRequest(`{app="foo"} |= "err" | json | line_format "{{.msg}}"`, 10, logproto.FORWARD))
+	require.NoError(t, err)
+	lokiRes = res.(*LokiResponse)
+	require.Len(t, lokiRes.Data.Result, 1)
+	require.Equal(t, `{app="foo"}`, lokiRes.Data.Result[0].Labels)
+	require.Len(t, lokiRes.Data.Result[0].Entries, 2)
+	require.Equal(t, "err b", lokiRes.Data.Result[0].Entries[0].Line)
+	require.Equal(t, "err c", lokiRes.Data.Result[0].Entries[1].Line)
+	require.Equal(t, push.LabelsAdapter{{Name: "trace_id", Value: "abc"}}, lokiRes.Data.Result[0].Entries[1].StructuredMetadata)
+	require.Equal(t, push.LabelsAdapter{{Name: "level", Value: "error"}, {Name: "msg", Value: "err c"}}, lokiRes.Data.Result[0].Entries[1].Parsed)
+
+	require.Len(t, h.Requests(), 1)
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.CacheMiss))
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.CacheHit))
+}
+
+// pipelineCacheGenNumberLoader returns the same results cache generation number for all the tenants.
+type pipelineCacheGenNumberLoader struct {
+	gen string
+}
+
+func (l *pipelineCacheGenNumberLoader) GetResultsCacheGenNumber([]string) string {
+	return l.gen
+}
+
+func (l *pipelineCacheGenNumberLoader) Stop() {}
+
+func TestLogPipelineResultCache_CacheGenNumber(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "foo")
+	metrics := NewLogPipelineResultCacheMetrics(prometheus.NewRegistry())
+	limits := fakeLimits{
+		splitDuration:           map[string]time.Duration{"foo": time.Hour},
+		maxEntriesLimitPerQuery: 100,
+	}
+	loader := &pipelineCacheGenNumberLoader{gen: "1"}
+	h := &pipelineCacheHandler{}
+	handler := NewLogPipelineResultCache(log.NewNopLogger(), limits, cache.NewMockCache(), nil, nil, loader, true, metrics).Wrap(h)
+
+	query := `{app="foo"} |= "err" | json`
+	for _, gen := range []string{"1", "1", "2"} {
+		loader.gen = gen
+		_, err := handler.Do(ctx, newPipelineCacheRequest(query, 10, logproto.FORWARD))
+		require.NoError(t, err)
+	}
+
+	// A delete request changes the generation number, so the cached lines are requested again.
+	require.Len(t, h.Requests(), 2)
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.CacheMiss))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.CacheHit))
+}
+
+func TestLogPipelineResultCache_Truncated(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "foo")
+	metrics, m := newTestLogPipelineResultCache(3)
+	h := &pipelineCacheHandler{}
+	handler := m.Wrap(h)
+
+	query := `{app="foo"} |= "err" | json`
+	_, err := handler.Do(ctx, newPipelineCacheRequest(query, 10, logproto.FORWARD))
+	require.NoError(t, err)
+	_, err = handler.Do(ctx, newPipelineCacheRequest(query, 10, logproto.FORWARD))
+	require.NoError(t, err)
+
+	// The lines of the prefix reach the max entries limit, so the queries are executed instead,
+	// and the prefix is not requested again.
+	requests := h.Requests()
+	require.Len(t, requests, 3)
+	require.Equal(t, `{app="foo"} |= "err"`, requests[0].Query)
+	require.Equal(t, query, requests[1].Query)
+	require.Equal(t, query, requests[2].Query)
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.CacheTruncated))
+}
+
+func TestLogPipelineResultCache_NotCached(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "foo")
+	for _, tc := range []struct {
+		name string
+		req  *LokiRequest
+	}{
+		{
+			name: "no line filter",
+			req:  newPipelineCacheRequest(`{app="foo"} | json |= "err"`, 10, logproto.FORWARD),
+		},
+		{
+			name: "non deterministic stage",
+			req:  newPipelineCacheRequest(`{app="foo"} |= "err" | line_format "{{ now }}"`, 10, logproto.FORWARD),
+		},
+		{
+			name: "spanning multiple splits",
+			req: func() *LokiRequest {
+				r := newPipelineCacheRequest(`{app="foo"} |= "err"`, 10, logproto.FORWARD)
+				r.EndTs = r.EndTs.Add(time.Hour)
+				return r
+			}(),
+		},
+		{
+			name: "recent",
+			req: func() *LokiRequest {
+				r := newPipelineCacheRequest(`{app="foo"} |= "err"`, 10, logproto.FORWARD)
+				r.StartTs, r.EndTs = time.Now().Add(-time.Minute), time.Now()
+				return r
+			}(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics, m := newTestLogPipelineResultCache(100)
+			h := &pipelineCacheHandler{}
+			_, err := m.Wrap(h).Do(ctx, tc.req)
+			require.NoError(t, err)
+
+			requests := h.Requests()
+			require.Len(t, requests, 1)
+			require.Equal(t, tc.req, requests[0])
+			require.Equal(t, float64(0), testutil.ToFloat64(metrics.CacheMiss))
+		})
+	}
+}
+
+func TestSplitLogPipeline(t *testing.T) {
+	for _, tc := range []struct {
+		query  string
+		prefix string
+		stages string
+	}{
+		{query: `{app="foo"}`},
+		{query: `{app="foo"} | json`},
+		{query: `{app="foo"} |= "err"`, prefix: `{app="foo"} |= "err"`},
+		{query: `{app="foo"} |= "err" != "debug" | logfmt | level="error"`, prefix: `{app="foo"} |= "err" != "debug"`, stages: `| logfmt | level="error"`},
+		{query: `{app="foo"} |~ "err.*" | json | line_format "{{.msg}}" |= "timeout"`, prefix: `{app="foo"} |~ "err.*"`, stages: `| json | line_format "{{.msg}}" |= "timeout"`},
+		{query: `{app="foo"} |= "err" | label_format ts="{{ now }}"`},
+		{query: `{app="foo"} |= "err" | line_format "{{ if .level }}{{ now | date \"15:04\" }}{{ end }}"`},
+		{query: `{app="foo"} |= "err" | line_format "{{ .now }}"`, prefix: `{app="foo"} |= "err"`, stages: `| line_format "{{ .now }}"`},
+		{query: `{app="foo"} |= "err" | label_format dst=src`, prefix: `{app="foo"} |= "err"`, stages: `| label_format dst=src`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			expr := syntax.MustParseExpr(tc.query)
+			prefix, stages, ok := splitLogPipeline(expr)
+			require.Equal(t, tc.prefix != "", ok)
+			if !ok {
+				return
+			}
+			require.Equal(t, tc.prefix, prefix.String())
+			require.Equal(t, tc.stages, stages.String())
+			// The plan of the request is not modified.
+			require.Equal(t, syntax.MustParseExpr(tc.query).String(), expr.String())
+		})
+	}
+}
@@ -111,23 +111,8 @@ func (l *logResultCache) Do(ctx context.Context, req queryrangebase.Request) (qu
 	if interval == 0 || lokiReq.Limit == 0 {
 		return l.next.Do(ctx, req)
 	}
-	// The first subquery might not be aligned.
-	alignedStart := time.Unix(0, lokiReq.GetStartTs().UnixNano()-(lokiReq.GetStartTs().UnixNano()%interval.Nanoseconds()))
 	// generate the cache key based on query, tenant and start time.
-
-	transformedTenantIDs := tenantIDs
-	if l.transformer != nil {
-		transformedTenantIDs = make([]string, 0, len(tenantIDs))
-
-		for _, tenantID := range tenantIDs {
-			transformedTenantIDs = append(transformedTenantIDs, l.transformer(ctx, tenantID))
-		}
-	}
-
-	cacheKey := fmt.Sprintf("log:%s:%s:%d:%d", tenant.JoinTenantIDs(transformedTenantIDs), req.GetQuery(), interval.Nanoseconds(), alignedStart.UnixNano()/(interval.Nanoseconds()))
-	if httpreq.ExtractHeader(ctx, httpreq.LokiDisablePipelineWrappersHeader) == "true" {
-		cacheKey = "pipeline-disabled:" + cacheKey
-	}
+	cacheKey := logResultCacheKey(ctx, tenantIDs, l.transformer, lokiReq, interval)
 
 	_, buff, _, err := l.cache.Fetch(ctx, []string{cache.HashKey(cacheKey)})
 	if err != nil {
@@ -155,6 +140,28 @@ func (l *logResultCache) Do(ctx context.Context, req queryrangebase.Request) (qu
 	return l.handleHit(ctx, cacheKey, &cachedRequest, lokiReq)
 }
 
+// logResultCacheKey returns the cache key of a log request, based on its query, tenants and start time.
+func logResultCacheKey(ctx context.Context, tenantIDs []string, transformer UserIDTransformer, req *LokiRequest, interval time.Duration) string {
+	// The first subquery might not be aligned.
+	alignedStart := time.Unix(0, req.GetStartTs().UnixNano()-(req.GetStartTs().UnixNano()%interval.Nanoseconds()))
+
+	transformedTenantIDs := tenantIDs
+	if transformer != nil {
+		transformedTenantIDs = make([]string, 0, len(tenantIDs))
+
+		for _, tenantID := range tenantIDs {
+			transformedTenantIDs = append(transformedTenantIDs, transformer(ctx, tenantID))
+		}
+	}
+
+	cacheKey := fmt.Sprintf("log:%s:%s:%d:%d", tenant.JoinTenantIDs(transformedTenantIDs), req.GetQuery(), interval.Nanoseconds(), alignedStart.UnixNano()/(interval.Nanoseconds()))
+	if httpreq.ExtractHeader(ctx, httpreq.LokiDisablePipelineWrappersHeader) == "true" {
+		cacheKey = "pipeline-disabled:" + cacheKey
+	}
+
+	return cacheKey
+}
+
 func (l *logResultCache) handleMiss(ctx context.Context, cacheKey string, req *LokiRequest) (queryrangebase.Response, error) {
 	l.metrics.CacheMiss.Inc()
 	level.Debug(l.logger).Log("msg", "cache miss", "key", cacheKey)
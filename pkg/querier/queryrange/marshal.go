@@ -33,6 +33,7 @@ import (
 const (
 	JSONType     = `application/json; charset=utf-8`
 	ParquetType  = `application/vnd.apache.parquet`
+	ArrowType    = `application/vnd.apache.arrow.stream`
 	ProtobufType = `application/vnd.google.protobuf`
 )
 
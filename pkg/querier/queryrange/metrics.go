@@ -18,6 +18,7 @@ type Metrics struct {
 	*MiddlewareMapperMetrics
 	*SplitByMetrics
 	*LogResultCacheMetrics
+	*LogPipelineResultCacheMetrics
 	*QueryMetrics
 	*queryrangebase.ResultsCacheMetrics
 }
@@ -36,13 +37,14 @@ func NewMiddlewareMapperMetrics(registerer prometheus.Registerer) *MiddlewareMap
 
 func NewMetrics(registerer prometheus.Registerer, metricsNamespace string) *Metrics {
 	return &Metrics{
-		InstrumentMiddlewareMetrics: queryrangebase.NewInstrumentMiddlewareMetrics(registerer, metricsNamespace),
-		RetryMiddlewareMetrics:      queryrangebase.NewRetryMiddlewareMetrics(registerer, metricsNamespace),
-		MiddlewareMapperMetrics:     NewMiddlewareMapperMetrics(registerer),
-		SplitByMetrics:              NewSplitByMetrics(registerer),
-		LogResultCacheMetrics:       NewLogResultCacheMetrics(registerer),
-		QueryMetrics:                NewMiddlewareQueryMetrics(registerer, metricsNamespace),
-		ResultsCacheMetrics:         queryrangebase.NewResultsCacheMetrics(registerer),
+		InstrumentMiddlewareMetrics:   queryrangebase.NewInstrumentMiddlewareMetrics(registerer, metricsNamespace),
+		RetryMiddlewareMetrics:        queryrangebase.NewRetryMiddlewareMetrics(registerer, metricsNamespace),
+		MiddlewareMapperMetrics:       NewMiddlewareMapperMetrics(registerer),
+		SplitByMetrics:                NewSplitByMetrics(registerer),
+		LogResultCacheMetrics:         NewLogResultCacheMetrics(registerer),
+		LogPipelineResultCacheMetrics: NewLogPipelineResultCacheMetrics(registerer),
+		QueryMetrics:                  NewMiddlewareQueryMetrics(registerer, metricsNamespace),
+		ResultsCacheMetrics:           queryrangebase.NewResultsCacheMetrics(registerer),
 	}
 }
 
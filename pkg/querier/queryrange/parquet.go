@@ -3,9 +3,12 @@ package queryrange
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
+	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/opentracing/opentracing-go"
 	"github.com/parquet-go/parquet-go"
 	"github.com/prometheus/prometheus/promql/parser"
@@ -37,13 +40,61 @@ func encodeResponseParquet(ctx context.Context, res queryrangebase.Response) (*h
 }
 
 func encodeResponseParquetTo(_ context.Context, res queryrangebase.Response, w io.Writer) error {
+	return encodeResponseRowsTo(res, rowSink{parquet: w}, "Parquet")
+}
+
+// rowSink is where the rows of a response are written, either as a Parquet
+// file or as a stream of Arrow record batches. The columns of both formats are
+// derived from the parquet tags of the row types.
+type rowSink struct {
+	parquet io.Writer
+	arrow   func(*arrow.Schema) (arrowRecordWriter, error)
+}
+
+type rowWriter[T any] interface {
+	Write(rows []T) (int, error)
+	Close() error
+}
+
+func newRowWriter[T any](sink rowSink) (rowWriter[T], error) {
+	if sink.arrow != nil {
+		return newArrowRowWriter[T](sink.arrow)
+	}
+	return parquet.NewGenericWriter[T](sink.parquet, parquet.SchemaOf(new(T))), nil
+}
+
+// encodeResponseRowsTo writes the rows of a response to the sink. A response is
+// encoded as a single table, with a row per sample, entry, series, label...
+func encodeResponseRowsTo(res queryrangebase.Response, sink rowSink, format string) error {
 	switch response := res.(type) {
 	case *LokiPromResponse:
-		return encodeMetricsParquetTo(response, w)
+		return encodeMetricsTo(response, sink)
 	case *LokiResponse:
-		return encodeLogsParquetTo(response, w)
+		return encodeLogsTo(response, sink)
+	case *LokiSeriesResponse:
+		return encodeSeriesTo(response, sink)
+	case *LokiSeriesResponseView:
+		return encodeSeriesViewTo(response.ForEachSeries, sink)
+	case *MergedSeriesResponseView:
+		return encodeSeriesViewTo(response.ForEachUniqueSeries, sink)
+	case *LokiLabelNamesResponse:
+		return encodeValuesTo(response.Data, sink)
+	case *IndexStatsResponse:
+		return encodeIndexStatsTo(response, sink)
+	case *ShardsResponse:
+		return encodeShardsTo(response, sink)
+	case *VolumeResponse:
+		return encodeVolumesTo(response, sink)
+	case *DetectedFieldsResponse:
+		return encodeDetectedFieldsTo(response, sink)
+	case *DetectedLabelsResponse:
+		return encodeDetectedLabelsTo(response, sink)
+	case *QueryPatternsResponse:
+		return encodePatternsTo(response, sink)
+	case *ExplainResponse:
+		return encodeExplainTo(response, sink)
 	default:
-		return serverutil.UserError("request does not support Parquet responses")
+		return serverutil.UserError(fmt.Sprintf("request does not support %s responses", format))
 	}
 }
 
@@ -59,10 +110,84 @@ type LogStreamRowType struct {
 	Line      string            `parquet:"line,lz4"`
 }
 
-func encodeMetricsParquetTo(response *LokiPromResponse, w io.Writer) error {
-	schema := parquet.SchemaOf(new(MetricRowType))
-	writer := parquet.NewGenericWriter[MetricRowType](w, schema)
+// SeriesRowType is a row of the series responses.
+type SeriesRowType struct {
+	Labels map[string]string `parquet:"labels"`
+}
+
+// ValueRowType is a row of the label names, label values and detected field values responses.
+type ValueRowType struct {
+	Value string `parquet:"value"`
+}
+
+// IndexStatsRowType is the single row of the index stats responses.
+type IndexStatsRowType struct {
+	Streams uint64 `parquet:"streams"`
+	Chunks  uint64 `parquet:"chunks"`
+	Bytes   uint64 `parquet:"bytes"`
+	Entries uint64 `parquet:"entries"`
+}
+
+// ShardRowType is a row of the shards responses, with the fingerprint bounds of a shard.
+type ShardRowType struct {
+	Min     uint64 `parquet:"min"`
+	Max     uint64 `parquet:"max"`
+	Streams uint64 `parquet:"streams"`
+	Chunks  uint64 `parquet:"chunks"`
+	Bytes   uint64 `parquet:"bytes"`
+	Entries uint64 `parquet:"entries"`
+}
+
+// VolumeRowType is a row of the volume responses.
+type VolumeRowType struct {
+	Name   string `parquet:"name"`
+	Volume uint64 `parquet:"volume"`
+}
+
+// DetectedFieldRowType is a row of the detected fields responses.
+type DetectedFieldRowType struct {
+	Label       string   `parquet:"label"`
+	Type        string   `parquet:"type"`
+	Cardinality uint64   `parquet:"cardinality"`
+	Parsers     []string `parquet:"parsers,list"`
+}
+
+// DetectedLabelRowType is a row of the detected labels responses.
+type DetectedLabelRowType struct {
+	Label       string `parquet:"label"`
+	Cardinality uint64 `parquet:"cardinality"`
+}
+
+// PatternRowType is a row of the patterns responses, with a sample of a pattern.
+type PatternRowType struct {
+	Timestamp int64  `parquet:"timestamp,timestamp(millisecond),delta"`
+	Pattern   string `parquet:"pattern"`
+	Value     int64  `parquet:"value"`
+}
+
+// ExplainSplitRowType is a row of the explain responses, with a split of the query.
+type ExplainSplitRowType struct {
+	Start         int64  `parquet:"start,timestamp(nanosecond)"`
+	End           int64  `parquet:"end,timestamp(nanosecond)"`
+	Cache         string `parquet:"cache"`
+	Query         string `parquet:"query"`
+	Sharded       bool   `parquet:"sharded"`
+	BytesPerShard uint64 `parquet:"bytes_per_shard"`
+}
+
+func encodeMetricsTo(response *LokiPromResponse, sink rowSink) error {
+	writer, err := newRowWriter[MetricRowType](sink)
+	if err != nil {
+		return err
+	}
+	if err := writeMetricRows(writer, response); err != nil {
+		return err
+	}
+	return writer.Close()
+}
 
+// writeMetricRows writes a row per sample of the response.
+func writeMetricRows(writer rowWriter[MetricRowType], response *LokiPromResponse) error {
 	for _, stream := range response.Response.Data.Result {
 		lbls := make(map[string]string)
 		for _, keyValue := range stream.Labels {
@@ -79,13 +204,22 @@ func encodeMetricsParquetTo(response *LokiPromResponse, w io.Writer) error {
 			}
 		}
 	}
-	return writer.Close()
+	return nil
 }
 
-func encodeLogsParquetTo(response *LokiResponse, w io.Writer) error {
-	schema := parquet.SchemaOf(new(LogStreamRowType))
-	writer := parquet.NewGenericWriter[LogStreamRowType](w, schema)
+func encodeLogsTo(response *LokiResponse, sink rowSink) error {
+	writer, err := newRowWriter[LogStreamRowType](sink)
+	if err != nil {
+		return err
+	}
+	if err := writeLogRows(writer, response); err != nil {
+		return err
+	}
+	return writer.Close()
+}
 
+// writeLogRows writes a row per entry of the response.
+func writeLogRows(writer rowWriter[LogStreamRowType], response *LokiResponse) error {
 	for _, stream := range response.Data.Result {
 		lbls, err := parser.ParseMetric(stream.Labels)
 		if err != nil {
@@ -107,6 +241,208 @@ func encodeLogsParquetTo(response *LokiResponse, w io.Writer) error {
 			}
 		}
 	}
+	return nil
+}
+
+func encodeSeriesTo(response *LokiSeriesResponse, sink rowSink) error {
+	writer, err := newRowWriter[SeriesRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	for _, series := range response.Data {
+		lbls := make(map[string]string, len(series.Labels))
+		for _, keyValue := range series.Labels {
+			lbls[keyValue.Key] = keyValue.Value
+		}
+		if _, err := writer.Write([]SeriesRowType{{Labels: lbls}}); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func encodeSeriesViewTo(forEach func(func(*SeriesIdentifierView) error) error, sink rowSink) error {
+	writer, err := newRowWriter[SeriesRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	err = forEach(func(series *SeriesIdentifierView) error {
+		lbls := make(map[string]string)
+		// The strings are views on the buffer of the response and must be copied.
+		if err := series.ForEachLabel(func(name, value string) error {
+			lbls[strings.Clone(name)] = strings.Clone(value)
+			return nil
+		}); err != nil {
+			return err
+		}
+		_, err := writer.Write([]SeriesRowType{{Labels: lbls}})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func encodeValuesTo(values []string, sink rowSink) error {
+	writer, err := newRowWriter[ValueRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]ValueRowType, 0, len(values))
+	for _, v := range values {
+		rows = append(rows, ValueRowType{Value: v})
+	}
+	if _, err := writer.Write(rows); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func encodeIndexStatsTo(response *IndexStatsResponse, sink rowSink) error {
+	writer, err := newRowWriter[IndexStatsRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	stats := response.Response
+	row := IndexStatsRowType{
+		Streams: stats.GetStreams(),
+		Chunks:  stats.GetChunks(),
+		Bytes:   stats.GetBytes(),
+		Entries: stats.GetEntries(),
+	}
+	if _, err := writer.Write([]IndexStatsRowType{row}); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func encodeShardsTo(response *ShardsResponse, sink rowSink) error {
+	writer, err := newRowWriter[ShardRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]ShardRowType, 0, len(response.Response.GetShards()))
+	for _, shard := range response.Response.GetShards() {
+		rows = append(rows, ShardRowType{
+			Min:     uint64(shard.Bounds.Min),
+			Max:     uint64(shard.Bounds.Max),
+			Streams: shard.Stats.GetStreams(),
+			Chunks:  shard.Stats.GetChunks(),
+			Bytes:   shard.Stats.GetBytes(),
+			Entries: shard.Stats.GetEntries(),
+		})
+	}
+	if _, err := writer.Write(rows); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func encodeVolumesTo(response *VolumeResponse, sink rowSink) error {
+	writer, err := newRowWriter[VolumeRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]VolumeRowType, 0, len(response.Response.GetVolumes()))
+	for _, volume := range response.Response.GetVolumes() {
+		rows = append(rows, VolumeRowType{Name: volume.Name, Volume: volume.Volume})
+	}
+	if _, err := writer.Write(rows); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func encodeDetectedFieldsTo(response *DetectedFieldsResponse, sink rowSink) error {
+	// The values of a detected field are requested without the fields.
+	if len(response.Response.GetFields()) == 0 && len(response.Response.GetValues()) > 0 {
+		return encodeValuesTo(response.Response.GetValues(), sink)
+	}
+
+	writer, err := newRowWriter[DetectedFieldRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]DetectedFieldRowType, 0, len(response.Response.GetFields()))
+	for _, field := range response.Response.GetFields() {
+		rows = append(rows, DetectedFieldRowType{
+			Label:       field.Label,
+			Type:        string(field.Type),
+			Cardinality: field.Cardinality,
+			Parsers:     field.Parsers,
+		})
+	}
+	if _, err := writer.Write(rows); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func encodeDetectedLabelsTo(response *DetectedLabelsResponse, sink rowSink) error {
+	writer, err := newRowWriter[DetectedLabelRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]DetectedLabelRowType, 0, len(response.Response.GetDetectedLabels()))
+	for _, label := range response.Response.GetDetectedLabels() {
+		rows = append(rows, DetectedLabelRowType{Label: label.Label, Cardinality: label.Cardinality})
+	}
+	if _, err := writer.Write(rows); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func encodePatternsTo(response *QueryPatternsResponse, sink rowSink) error {
+	writer, err := newRowWriter[PatternRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	for _, series := range response.Response.GetSeries() {
+		rows := make([]PatternRowType, 0, len(series.Samples))
+		for _, sample := range series.Samples {
+			rows = append(rows, PatternRowType{
+				Timestamp: int64(sample.Timestamp),
+				Pattern:   series.Pattern,
+				Value:     sample.Value,
+			})
+		}
+		if _, err := writer.Write(rows); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
 
+func encodeExplainTo(response *ExplainResponse, sink rowSink) error {
+	writer, err := newRowWriter[ExplainSplitRowType](sink)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]ExplainSplitRowType, 0, len(response.Data.Splits))
+	for _, split := range response.Data.Splits {
+		rows = append(rows, ExplainSplitRowType{
+			Start:         split.Start.UnixNano(),
+			End:           split.End.UnixNano(),
+			Cache:         split.Cache,
+			Query:         split.Query,
+			Sharded:       split.Sharded,
+			BytesPerShard: split.BytesPerShard,
+		})
+	}
+	if _, err := writer.Write(rows); err != nil {
+		return err
+	}
 	return writer.Close()
 }
@@ -1,8 +1,11 @@
 package queryrange
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/parquet-go/parquet-go"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
 )
 
 func TestEncodeMetricsParquet(t *testing.T) {
@@ -28,7 +32,7 @@ func TestEncodeMetricsParquet(t *testing.T) {
 	defer f.Close() // nolint:staticcheck
 
 	require.NoError(t, err)
-	err = encodeMetricsParquetTo(resp, f)
+	err = encodeResponseParquetTo(context.Background(), resp, f)
 	require.NoError(t, err)
 
 	rows, err := parquet.ReadFile[MetricRowType](f.Name())
@@ -53,7 +57,7 @@ func TestEncodeLogsParquet(t *testing.T) {
 	defer f.Close() // nolint:staticcheck
 
 	require.NoError(t, err)
-	err = encodeLogsParquetTo(resp, f)
+	err = encodeResponseParquetTo(context.Background(), resp, f)
 	require.NoError(t, err)
 
 	rows, err := parquet.ReadFile[LogStreamRowType](f.Name())
@@ -61,3 +65,154 @@ func TestEncodeLogsParquet(t *testing.T) {
 
 	require.Len(t, rows, 3)
 }
+
+func seriesResponseView(t *testing.T) *LokiSeriesResponseView {
+	p := QueryResponse{
+		Response: &QueryResponse_Series{
+			Series: &LokiSeriesResponse{
+				Status: "success",
+				Data: []logproto.SeriesIdentifier{
+					{Labels: []logproto.SeriesIdentifier_LabelsEntry{{Key: "app", Value: "foo"}}},
+					{Labels: []logproto.SeriesIdentifier_LabelsEntry{{Key: "app", Value: "bar"}, {Key: "env", Value: "prod"}}},
+				},
+			},
+		},
+	}
+	buf, err := p.Marshal()
+	require.NoError(t, err)
+	view, err := GetLokiSeriesResponseView(buf)
+	require.NoError(t, err)
+	return view
+}
+
+func readParquetRows[T any](t *testing.T, buf []byte) any {
+	rows, err := parquet.Read[T](bytes.NewReader(buf), int64(len(buf)))
+	require.NoError(t, err)
+	return rows
+}
+
+func TestEncodeResponseParquet(t *testing.T) {
+	ts := time.Unix(1700000000, 0).UTC()
+
+	for _, tc := range []struct {
+		name     string
+		res      queryrangebase.Response
+		read     func(*testing.T, []byte) any
+		expected any
+	}{
+		{
+			name: "series",
+			res: &LokiSeriesResponse{
+				Status: loghttp.QueryStatusSuccess,
+				Data: []logproto.SeriesIdentifier{
+					{Labels: []logproto.SeriesIdentifier_LabelsEntry{{Key: "app", Value: "foo"}}},
+				},
+			},
+			read:     readParquetRows[SeriesRowType],
+			expected: []SeriesRowType{{Labels: map[string]string{"app": "foo"}}},
+		},
+		{
+			name: "series view",
+			res:  &MergedSeriesResponseView{responses: []*LokiSeriesResponseView{seriesResponseView(t), seriesResponseView(t)}},
+			read: readParquetRows[SeriesRowType],
+			expected: []SeriesRowType{
+				{Labels: map[string]string{"app": "foo"}},
+				{Labels: map[string]string{"app": "bar", "env": "prod"}},
+			},
+		},
+		{
+			name:     "labels",
+			res:      &LokiLabelNamesResponse{Status: loghttp.QueryStatusSuccess, Data: []string{"app", "env"}},
+			read:     readParquetRows[ValueRowType],
+			expected: []ValueRowType{{Value: "app"}, {Value: "env"}},
+		},
+		{
+			name:     "index stats",
+			res:      &IndexStatsResponse{Response: &logproto.IndexStatsResponse{Streams: 1, Chunks: 2, Bytes: 3, Entries: 4}},
+			read:     readParquetRows[IndexStatsRowType],
+			expected: []IndexStatsRowType{{Streams: 1, Chunks: 2, Bytes: 3, Entries: 4}},
+		},
+		{
+			name: "shards",
+			res: &ShardsResponse{Response: &logproto.ShardsResponse{Shards: []logproto.Shard{
+				{Bounds: logproto.FPBounds{Min: 0, Max: 10}, Stats: &logproto.IndexStatsResponse{Streams: 1, Chunks: 2, Bytes: 3, Entries: 4}},
+				{Bounds: logproto.FPBounds{Min: 11, Max: 20}},
+			}}},
+			read: readParquetRows[ShardRowType],
+			expected: []ShardRowType{
+				{Min: 0, Max: 10, Streams: 1, Chunks: 2, Bytes: 3, Entries: 4},
+				{Min: 11, Max: 20},
+			},
+		},
+		{
+			name: "volume",
+			res: &VolumeResponse{Response: &logproto.VolumeResponse{Volumes: []logproto.Volume{
+				{Name: `{app="foo"}`, Volume: 38},
+				{Name: `{app="bar"}`, Volume: 12},
+			}}},
+			read:     readParquetRows[VolumeRowType],
+			expected: []VolumeRowType{{Name: `{app="foo"}`, Volume: 38}, {Name: `{app="bar"}`, Volume: 12}},
+		},
+		{
+			name: "detected fields",
+			res: &DetectedFieldsResponse{Response: &logproto.DetectedFieldsResponse{Fields: []*logproto.DetectedField{
+				{Label: "duration", Type: logproto.DetectedFieldDuration, Cardinality: 10, Parsers: []string{"logfmt"}},
+				{Label: "status", Type: logproto.DetectedFieldInt, Cardinality: 3, Parsers: []string{"json", "logfmt"}},
+			}}},
+			read: readParquetRows[DetectedFieldRowType],
+			expected: []DetectedFieldRowType{
+				{Label: "duration", Type: "duration", Cardinality: 10, Parsers: []string{"logfmt"}},
+				{Label: "status", Type: "int", Cardinality: 3, Parsers: []string{"json", "logfmt"}},
+			},
+		},
+		{
+			name:     "detected field values",
+			res:      &DetectedFieldsResponse{Response: &logproto.DetectedFieldsResponse{Values: []string{"200", "500"}}},
+			read:     readParquetRows[ValueRowType],
+			expected: []ValueRowType{{Value: "200"}, {Value: "500"}},
+		},
+		{
+			name: "detected labels",
+			res: &DetectedLabelsResponse{Response: &logproto.DetectedLabelsResponse{DetectedLabels: []*logproto.DetectedLabel{
+				{Label: "app", Cardinality: 2},
+			}}},
+			read:     readParquetRows[DetectedLabelRowType],
+			expected: []DetectedLabelRowType{{Label: "app", Cardinality: 2}},
+		},
+		{
+			name: "patterns",
+			res: &QueryPatternsResponse{Response: &logproto.QueryPatternsResponse{Series: []*logproto.PatternSeries{
+				{Pattern: "GET <_>", Samples: []*logproto.PatternSample{{Timestamp: 1000, Value: 2}, {Timestamp: 2000, Value: 3}}},
+				{Pattern: "POST <_>", Samples: []*logproto.PatternSample{{Timestamp: 1000, Value: 1}}},
+			}}},
+			read: readParquetRows[PatternRowType],
+			expected: []PatternRowType{
+				{Timestamp: 1000, Pattern: "GET <_>", Value: 2},
+				{Timestamp: 2000, Pattern: "GET <_>", Value: 3},
+				{Timestamp: 1000, Pattern: "POST <_>", Value: 1},
+			},
+		},
+		{
+			name: "explain",
+			res: &ExplainResponse{Status: loghttp.QueryStatusSuccess, Data: ExplainData{Splits: []ExplainSplit{
+				{Start: ts, End: ts.Add(time.Hour), Cache: "miss", Query: `sum(rate({app="foo"}[1m]))`, Sharded: true, BytesPerShard: 100},
+			}}},
+			read: readParquetRows[ExplainSplitRowType],
+			expected: []ExplainSplitRowType{
+				{Start: ts.UnixNano(), End: ts.Add(time.Hour).UnixNano(), Cache: "miss", Query: `sum(rate({app="foo"}[1m]))`, Sharded: true, BytesPerShard: 100},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, encodeResponseParquetTo(context.Background(), tc.res, &buf))
+			require.Equal(t, tc.expected, tc.read(t, buf.Bytes()))
+		})
+	}
+}
+
+func TestEncodeResponseParquet_Unsupported(t *testing.T) {
+	var buf bytes.Buffer
+	err := encodeResponseParquetTo(context.Background(), &TopKSketchesResponse{}, &buf)
+	require.Equal(t, serverutil.UserError("request does not support Parquet responses"), err)
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/v3/pkg/querier/astmapper"
@@ -162,15 +163,6 @@ func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (que
 		return nil, err
 	}
 
-	maxRVDuration, maxOffset := maxRangeVectorAndOffsetDuration(params.GetExpression())
-
-	conf, err := ast.confs.GetConf(int64(model.Time(r.GetStart().UnixMilli()).Add(-maxRVDuration).Add(-maxOffset)), int64(model.Time(r.GetEnd().UnixMilli()).Add(-maxOffset)))
-	// cannot shard with this timerange
-	if err != nil {
-		level.Warn(spLogger).Log("err", err.Error(), "msg", "skipped AST mapper for request")
-		return ast.next.Do(ctx, r)
-	}
-
 	tenants, err := tenant.TenantIDs(ctx)
 	if err != nil {
 		return nil, err
@@ -183,49 +175,12 @@ func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (que
 	// will merge with the stats returned from the engine.
 	resolverStats, ctx := stats.NewContext(ctx)
 
-	resolver, ok := shardResolverForConf(
-		ctx,
-		conf,
-		ast.ng.Opts().MaxLookBackPeriod,
-		ast.logger,
-		MinWeightedParallelism(ctx, tenants, ast.confs, ast.limits, model.Time(r.GetStart().UnixMilli()), model.Time(r.GetEnd().UnixMilli())),
-		ast.maxShards,
-		r,
-		ast.statsHandler,
-		ast.retryNextHandler,
-		ast.next,
-		ast.limits,
-	)
+	strategy, ok := ast.shardingStrategy(ctx, r, params.GetExpression(), tenants, spLogger)
 	if !ok {
 		return ast.next.Do(ctx, r)
 	}
 
-	var strategy logql.ShardingStrategy
-
-	if conf.IndexType == types.TSDBType {
-		v := ast.limits.TSDBShardingStrategy(tenants[0])
-		version, err := logql.ParseShardVersion(v)
-		if err != nil {
-			level.Warn(spLogger).Log(
-				"msg", "failed to parse shard version",
-				"fallback", version.String(),
-				"err", err.Error(),
-				"user", tenants[0],
-				"query", r.GetQuery(),
-			)
-		}
-		strategy = version.Strategy(resolver, uint64(ast.limits.TSDBMaxBytesPerShard(tenants[0])))
-	} else {
-		strategy = logql.NewPowerOfTwoStrategy(resolver)
-	}
-
-	// Merge global shard aggregations and tenant overrides.
-	limitShardAggregation := validation.IntersectionPerTenant(tenants, func(tenant string) []string {
-		return ast.limits.ShardAggregations(tenant)
-	})
-	mergedShardAggregation := slices.Compact(append(limitShardAggregation, ast.shardAggregation...))
-
-	mapper := logql.NewShardMapper(strategy, ast.metrics, mergedShardAggregation)
+	mapper := logql.NewShardMapper(strategy, ast.metrics, ast.mergedShardAggregations(tenants))
 
 	noop, bytesPerShard, parsed, err := mapper.Parse(params.GetExpression())
 	if err != nil {
@@ -319,6 +274,61 @@ func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (que
 	}
 }
 
+// shardingStrategy returns the strategy sharding the expression of a request, or false if
+// the request cannot be sharded.
+func (ast *astMapperware) shardingStrategy(ctx context.Context, r queryrangebase.Request, expr syntax.Expr, tenants []string, logger log.Logger) (logql.ShardingStrategy, bool) {
+	maxRVDuration, maxOffset := maxRangeVectorAndOffsetDuration(expr)
+
+	conf, err := ast.confs.GetConf(int64(model.Time(r.GetStart().UnixMilli()).Add(-maxRVDuration).Add(-maxOffset)), int64(model.Time(r.GetEnd().UnixMilli()).Add(-maxOffset)))
+	// cannot shard with this timerange
+	if err != nil {
+		level.Warn(logger).Log("err", err.Error(), "msg", "skipped AST mapper for request")
+		return nil, false
+	}
+
+	resolver, ok := shardResolverForConf(
+		ctx,
+		conf,
+		ast.ng.Opts().MaxLookBackPeriod,
+		ast.logger,
+		MinWeightedParallelism(ctx, tenants, ast.confs, ast.limits, model.Time(r.GetStart().UnixMilli()), model.Time(r.GetEnd().UnixMilli())),
+		ast.maxShards,
+		r,
+		ast.statsHandler,
+		ast.retryNextHandler,
+		ast.next,
+		ast.limits,
+	)
+	if !ok {
+		return nil, false
+	}
+
+	if conf.IndexType != types.TSDBType {
+		return logql.NewPowerOfTwoStrategy(resolver), true
+	}
+
+	v := ast.limits.TSDBShardingStrategy(tenants[0])
+	version, err := logql.ParseShardVersion(v)
+	if err != nil {
+		level.Warn(logger).Log(
+			"msg", "failed to parse shard version",
+			"fallback", version.String(),
+			"err", err.Error(),
+			"user", tenants[0],
+			"query", r.GetQuery(),
+		)
+	}
+	return version.Strategy(resolver, uint64(ast.limits.TSDBMaxBytesPerShard(tenants[0]))), true
+}
+
+// mergedShardAggregations merges the global shard aggregations and the tenant overrides.
+func (ast *astMapperware) mergedShardAggregations(tenants []string) []string {
+	limitShardAggregation := validation.IntersectionPerTenant(tenants, func(tenant string) []string {
+		return ast.limits.ShardAggregations(tenant)
+	})
+	return slices.Compact(append(limitShardAggregation, ast.shardAggregation...))
+}
+
 // shardSplitter middleware will only shard appropriate requests that do not extend past the MinShardingLookback interval.
 // This is used to send nonsharded requests to the ingesters in order to not overload them.
 // TODO(owen-d): export in cortex so we don't duplicate code
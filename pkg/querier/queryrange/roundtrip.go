@@ -58,7 +58,10 @@ type Config struct {
 	CacheSeriesResults           bool                     `yaml:"cache_series_results"`
 	SeriesCacheConfig            SeriesCacheConfig        `yaml:"series_results_cache" doc:"description=If series_results_cache is not configured and cache_series_results is true, the config for the results cache is used."`
 	CacheLabelResults            bool                     `yaml:"cache_label_results"`
+	CacheLogPipelineResults      bool                     `yaml:"cache_log_pipeline_results" category:"experimental"`
 	LabelsCacheConfig            LabelsCacheConfig        `yaml:"label_results_cache" doc:"description=If label_results_cache is not configured and cache_label_results is true, the config for the results cache is used."`
+	AsyncQueries                 AsyncQueriesConfig       `yaml:"async_queries" category:"experimental" doc:"description=Asynchronous query jobs, running range queries in the background and persisting their results to the object store. The number of concurrent jobs and the retention of their results are limited per tenant by async_query_max_concurrent_jobs and async_query_results_ttl."`
+	Export                       ExportConfig             `yaml:"export" category:"experimental" doc:"description=Streaming export API, streaming the results of log queries without the max_entries_limit_per_query limit. The bytes scanned by an export request are limited per tenant by export_max_bytes_scanned."`
 }
 
 // RegisterFlags adds the flags required to configure this flag set.
@@ -75,6 +78,9 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.SeriesCacheConfig.RegisterFlags(f)
 	f.BoolVar(&cfg.CacheLabelResults, "querier.cache-label-results", true, "Cache label query results.")
 	cfg.LabelsCacheConfig.RegisterFlags(f)
+	f.BoolVar(&cfg.CacheLogPipelineResults, "querier.cache-log-pipeline-results", false, "Cache the log lines matching the stream selector and the line filters at the start of the pipeline of log queries, per split, and apply the remaining stages of the pipeline to the cached lines. Queries sharing the same stream selector and line filters reuse the same cached lines. Requires cache_results.")
+	cfg.AsyncQueries.RegisterFlags(f)
+	cfg.Export.RegisterFlags(f)
 }
 
 // Validate validates the config.
@@ -88,6 +94,13 @@ func (cfg *Config) Validate() error {
 			return errors.Wrap(err, "invalid index_stats_results_cache config")
 		}
 	}
+
+	if err := cfg.AsyncQueries.Validate(); err != nil {
+		return errors.Wrap(err, "invalid async_queries config")
+	}
+	if err := cfg.Export.Validate(); err != nil {
+		return errors.Wrap(err, "invalid export config")
+	}
 	return nil
 }
 
@@ -199,8 +212,10 @@ func NewMiddleware(
 		return nil, nil, err
 	}
 
+	scanQuotas := NewScanQuotas(limits, registerer, metricsNamespace)
+
 	metricsTripperware, err := NewMetricTripperware(cfg, engineOpts, log, limits, schema, codec, iqo, resultsCache,
-		cacheGenNumLoader, retentionEnabled, PrometheusExtractor{}, metrics, indexStatsTripperware, metricsNamespace)
+		cacheGenNumLoader, retentionEnabled, PrometheusExtractor{}, metrics, indexStatsTripperware, scanQuotas, metricsNamespace)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -212,7 +227,7 @@ func NewMiddleware(
 
 	// NOTE: When we would start caching response from non-metric queries we would have to consider cache gen headers as well in
 	// MergeResponse implementation for Loki codecs same as it is done in Cortex at https://github.com/cortexproject/cortex/blob/21bad57b346c730d684d6d0205efef133422ab28/pkg/querier/queryrange/query_range.go#L170
-	logFilterTripperware, err := NewLogFilterTripperware(cfg, engineOpts, log, limits, schema, codec, iqo, resultsCache, metrics, indexStatsTripperware, metricsNamespace)
+	logFilterTripperware, err := NewLogFilterTripperware(cfg, engineOpts, log, limits, schema, codec, iqo, resultsCache, cacheGenNumLoader, retentionEnabled, metrics, indexStatsTripperware, scanQuotas, metricsNamespace)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -227,7 +242,7 @@ func NewMiddleware(
 		return nil, nil, err
 	}
 
-	instantMetricTripperware, err := NewInstantMetricTripperware(cfg, engineOpts, log, limits, schema, metrics, codec, instantMetricCache, cacheGenNumLoader, retentionEnabled, indexStatsTripperware, metricsNamespace)
+	instantMetricTripperware, err := NewInstantMetricTripperware(cfg, engineOpts, log, limits, schema, metrics, codec, instantMetricCache, cacheGenNumLoader, retentionEnabled, indexStatsTripperware, scanQuotas, metricsNamespace)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -258,6 +273,11 @@ func NewMiddleware(
 		return nil, nil, err
 	}
 
+	explainTripperware, err := NewExplainTripperware(cfg, engineOpts, log, limits, schema, iqo, resultsCache, cacheGenNumLoader, retentionEnabled, indexStatsTripperware)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return base.MiddlewareFunc(func(next base.Handler) base.Handler {
 		var (
 			metricRT         = metricsTripperware.Wrap(next)
@@ -270,6 +290,7 @@ func NewMiddleware(
 			seriesVolumeRT   = seriesVolumeTripperware.Wrap(next)
 			detectedFieldsRT = detectedFieldsTripperware.Wrap(next)
 			detectedLabelsRT = detectedLabelsTripperware.Wrap(next)
+			explainRT        = explainTripperware.Wrap(next)
 		)
 
 		return newRoundTripper(
@@ -285,7 +306,9 @@ func NewMiddleware(
 			seriesVolumeRT,
 			detectedFieldsRT,
 			detectedLabelsRT,
+			explainRT,
 			limits,
+			engineOpts.MaxInValues,
 		)
 	}), StopperWrapper{resultsCache, statsCache, volumeCache}, nil
 }
@@ -340,16 +363,18 @@ func NewDetectedLabelsCardinalityFilter(rt queryrangebase.Handler) queryrangebas
 type roundTripper struct {
 	logger log.Logger
 
-	next, limited, log, metric, series, labels, instantMetric, indexStats, seriesVolume, detectedFields, detectedLabels base.Handler
+	next, limited, log, metric, series, labels, instantMetric, indexStats, seriesVolume, detectedFields, detectedLabels, explain base.Handler
 
-	limits Limits
+	limits      Limits
+	maxInValues int
 }
 
 // newRoundTripper creates a new queryrange roundtripper
 func newRoundTripper(
 	logger log.Logger,
-	next, limited, log, metric, series, labels, instantMetric, indexStats, seriesVolume, detectedFields, detectedLabels base.Handler,
+	next, limited, log, metric, series, labels, instantMetric, indexStats, seriesVolume, detectedFields, detectedLabels, explain base.Handler,
 	limits Limits,
+	maxInValues int,
 ) roundTripper {
 	return roundTripper{
 		logger:         logger,
@@ -364,7 +389,9 @@ func newRoundTripper(
 		seriesVolume:   seriesVolume,
 		detectedFields: detectedFields,
 		detectedLabels: detectedLabels,
+		explain:        explain,
 		next:           next,
+		maxInValues:    maxInValues,
 	}
 }
 
@@ -382,6 +409,11 @@ func logQueryExecution(ctx context.Context, logger log.Logger, values ...interfa
 func (r roundTripper) Do(ctx context.Context, req base.Request) (base.Response, error) {
 	logger := logutil.WithContext(ctx, r.logger)
 
+	req, err := r.resolveInExprs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	switch op := req.(type) {
 	case *LokiRequest:
 		queryHash := util.HashedQuery(op.Query)
@@ -524,6 +556,16 @@ func (r roundTripper) Do(ctx context.Context, req base.Request) (base.Response,
 			"start", op.Start,
 		)
 		return r.detectedLabels.Do(ctx, req)
+	case *ExplainRequest:
+		logQueryExecution(ctx, logger,
+			"type", "explain",
+			"end", op.EndTs,
+			"length", op.EndTs.Sub(op.StartTs),
+			"query", op.Query,
+			"start", op.StartTs,
+			"step", op.Step,
+		)
+		return r.explain.Do(ctx, req)
 	default:
 		return r.next.Do(ctx, req)
 	}
@@ -560,6 +602,7 @@ const (
 	DetectedFieldsOp = "detected_fields"
 	PatternsQueryOp  = "patterns"
 	DetectedLabelsOp = "detected_labels"
+	ExplainOp        = "explain"
 )
 
 func getOperation(path string) string {
@@ -586,6 +629,8 @@ func getOperation(path string) string {
 		return PatternsQueryOp
 	case strings.HasSuffix(path, "/detected_labels"):
 		return DetectedLabelsOp
+	case strings.HasSuffix(path, "/explain"):
+		return ExplainOp
 	case strings.HasSuffix(path, "/values"):
 		if strings.Contains(path, "/label") {
 			return LabelNamesOp
@@ -600,7 +645,7 @@ func getOperation(path string) string {
 }
 
 // NewLogFilterTripperware creates a new frontend tripperware responsible for handling log requests.
-func NewLogFilterTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Logger, limits Limits, schema config.SchemaConfig, merger base.Merger, iqo util.IngesterQueryOptions, c cache.Cache, metrics *Metrics, indexStatsTripperware base.Middleware, metricsNamespace string) (base.Middleware, error) {
+func NewLogFilterTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Logger, limits Limits, schema config.SchemaConfig, merger base.Merger, iqo util.IngesterQueryOptions, c cache.Cache, cacheGenNumLoader base.CacheGenNumberLoader, retentionEnabled bool, metrics *Metrics, indexStatsTripperware base.Middleware, scanQuotas *ScanQuotas, metricsNamespace string) (base.Middleware, error) {
 	return base.MiddlewareFunc(func(next base.Handler) base.Handler {
 		statsHandler := indexStatsTripperware.Wrap(next)
 		retryNextHandler := next
@@ -615,6 +660,7 @@ func NewLogFilterTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Lo
 			StatsCollectorMiddleware(),
 			NewLimitsMiddleware(limits),
 			NewQuerySizeLimiterMiddleware(schema.Configs, engineOpts, log, limits, statsHandler),
+			NewScanQuotaMiddleware(schema.Configs, engineOpts, log, scanQuotas, statsHandler),
 			base.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
 			SplitByIntervalMiddleware(schema.Configs, limits, merger, newDefaultSplitter(limits, iqo), metrics.SplitByMetrics),
 		}
@@ -632,9 +678,31 @@ func NewLogFilterTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Lo
 			)
 			queryRangeMiddleware = append(
 				queryRangeMiddleware,
+				scanQuotaRequestsMiddleware(false),
 				base.InstrumentMiddleware("log_results_cache", metrics.InstrumentMiddlewareMetrics),
 				queryCacheMiddleware,
 			)
+
+			if cfg.CacheLogPipelineResults {
+				pipelineCacheMiddleware := NewLogPipelineResultCache(
+					log,
+					limits,
+					c,
+					func(_ context.Context, r base.Request) bool {
+						return !r.GetCachingOptions().Disabled
+					},
+					cfg.Transformer,
+					cacheGenNumLoader,
+					retentionEnabled,
+					metrics.LogPipelineResultCacheMetrics,
+				)
+				queryRangeMiddleware = append(
+					queryRangeMiddleware,
+					base.InstrumentMiddleware("log_pipeline_results_cache", metrics.InstrumentMiddlewareMetrics),
+					pipelineCacheMiddleware,
+				)
+			}
+			queryRangeMiddleware = append(queryRangeMiddleware, scanQuotaRequestsMiddleware(true))
 		}
 
 		if cfg.ShardedQueries {
@@ -881,7 +949,7 @@ func NewLabelsTripperware(
 }
 
 // NewMetricTripperware creates a new frontend tripperware responsible for handling metric queries
-func NewMetricTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Logger, limits Limits, schema config.SchemaConfig, merger base.Merger, iqo util.IngesterQueryOptions, c cache.Cache, cacheGenNumLoader base.CacheGenNumberLoader, retentionEnabled bool, extractor base.Extractor, metrics *Metrics, indexStatsTripperware base.Middleware, metricsNamespace string) (base.Middleware, error) {
+func NewMetricTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Logger, limits Limits, schema config.SchemaConfig, merger base.Merger, iqo util.IngesterQueryOptions, c cache.Cache, cacheGenNumLoader base.CacheGenNumberLoader, retentionEnabled bool, extractor base.Extractor, metrics *Metrics, indexStatsTripperware base.Middleware, scanQuotas *ScanQuotas, metricsNamespace string) (base.Middleware, error) {
 	cacheKey := cacheKeyLimits{limits, cfg.Transformer, iqo}
 	var queryCacheMiddleware base.Middleware
 	if cfg.CacheResults {
@@ -942,6 +1010,7 @@ func NewMetricTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Logge
 		queryRangeMiddleware = append(
 			queryRangeMiddleware,
 			NewQuerySizeLimiterMiddleware(schema.Configs, engineOpts, log, limits, statsHandler),
+			NewScanQuotaMiddleware(schema.Configs, engineOpts, log, scanQuotas, statsHandler),
 			base.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
 			SplitByIntervalMiddleware(schema.Configs, limits, merger, newMetricQuerySplitter(limits, iqo), metrics.SplitByMetrics),
 		)
@@ -949,8 +1018,10 @@ func NewMetricTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Logge
 		if cfg.CacheResults {
 			queryRangeMiddleware = append(
 				queryRangeMiddleware,
+				scanQuotaRequestsMiddleware(false),
 				base.InstrumentMiddleware("results_cache", metrics.InstrumentMiddlewareMetrics),
 				queryCacheMiddleware,
+				scanQuotaRequestsMiddleware(true),
 			)
 		}
 
@@ -1012,6 +1083,7 @@ func NewInstantMetricTripperware(
 	cacheGenNumLoader base.CacheGenNumberLoader,
 	retentionEnabled bool,
 	indexStatsTripperware base.Middleware,
+	scanQuotas *ScanQuotas,
 	metricsNamespace string,
 ) (base.Middleware, error) {
 	var cacheMiddleware base.Middleware
@@ -1058,14 +1130,17 @@ func NewInstantMetricTripperware(
 			StatsCollectorMiddleware(),
 			NewLimitsMiddleware(limits),
 			NewQuerySizeLimiterMiddleware(schema.Configs, engineOpts, log, limits, statsHandler),
+			NewScanQuotaMiddleware(schema.Configs, engineOpts, log, scanQuotas, statsHandler),
 			NewSplitByRangeMiddleware(log, engineOpts, limits, cfg.InstantMetricQuerySplitAlign, metrics.MiddlewareMapperMetrics.rangeMapper),
 		}
 
 		if cfg.CacheInstantMetricResults {
 			queryRangeMiddleware = append(
 				queryRangeMiddleware,
+				scanQuotaRequestsMiddleware(false),
 				base.InstrumentMiddleware("instant_metric_results_cache", metrics.InstrumentMiddlewareMetrics),
 				cacheMiddleware,
+				scanQuotaRequestsMiddleware(true),
 			)
 		}
 
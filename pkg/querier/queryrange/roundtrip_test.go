@@ -1005,11 +1005,86 @@ func TestPostQueries(t *testing.T) {
 		handler,
 		handler,
 		handler,
+		handler,
 		fakeLimits{},
+		0,
 	).Do(ctx, lreq)
 	require.NoError(t, err)
 }
 
+func TestInExprQueries(t *testing.T) {
+	lreq := &LokiRequest{
+		Query:   `{app="foo"} | logfmt | trace_id in ({app="bar"} | logfmt)`,
+		StartTs: testTime.Add(-time.Hour),
+		EndTs:   testTime,
+		Path:    "/loki/api/v1/query_range",
+		Plan: &plan.QueryPlan{
+			AST: syntax.MustParseExpr(`{app="foo"} | logfmt | trace_id in ({app="bar"} | logfmt)`),
+		},
+	}
+	ctx := user.InjectOrgID(context.Background(), "1")
+	handler := base.HandlerFunc(func(context.Context, base.Request) (base.Response, error) {
+		t.Error("unexpected default roundtripper called")
+		return nil, nil
+	})
+
+	var inQuery *LokiInstantRequest
+	instantMetric := base.HandlerFunc(func(_ context.Context, r base.Request) (base.Response, error) {
+		inQuery = r.(*LokiInstantRequest)
+		return &LokiPromResponse{
+			Response: &base.PrometheusResponse{
+				Status: loghttp.QueryStatusSuccess,
+				Data: base.PrometheusData{
+					ResultType: loghttp.ResultTypeVector,
+					Result: []base.SampleStream{
+						{Labels: []logproto.LabelAdapter{{Name: "trace_id", Value: "b"}}},
+						{Labels: []logproto.LabelAdapter{{Name: "trace_id", Value: "a"}}},
+					},
+				},
+			},
+		}, nil
+	})
+	var outer *LokiRequest
+	log := base.HandlerFunc(func(_ context.Context, r base.Request) (base.Response, error) {
+		outer = r.(*LokiRequest)
+		return &LokiResponse{Status: loghttp.QueryStatusSuccess}, nil
+	})
+
+	rt := newRoundTripper(
+		util_log.Logger,
+		handler,
+		handler,
+		log,
+		handler,
+		handler,
+		handler,
+		instantMetric,
+		handler,
+		handler,
+		handler,
+		handler,
+		handler,
+		fakeLimits{},
+		10,
+	)
+	_, err := rt.Do(ctx, lreq)
+	require.NoError(t, err)
+
+	// the inner query is evaluated once at the end of the outer query
+	require.Equal(t, `sum by (trace_id)(count_over_time({app="bar"} | logfmt[1h]))`, inQuery.Query)
+	require.Equal(t, testTime, inQuery.TimeTs)
+	// and the outer query is sent downstream with the resolved label filter
+	require.Equal(t, "{app=\"foo\"} | logfmt | trace_id=~`a|b`", outer.Query)
+	require.Equal(t, outer.Query, outer.Plan.AST.String())
+	// the original request is not mutated
+	require.Equal(t, `{app="foo"} | logfmt | trace_id in ({app="bar"} | logfmt)`, lreq.Query)
+
+	// the values of the inner query are limited
+	rt.maxInValues = 1
+	_, err = rt.Do(ctx, lreq)
+	require.Equal(t, logqlmodel.NewInValuesLimitError("trace_id", 1), err)
+}
+
 func TestTripperware_EntriesLimit(t *testing.T) {
 	tpw, stopper, err := NewMiddleware(testConfig, testEngineOpts, nil, util_log.Logger, fakeLimits{maxEntriesLimitPerQuery: 5000, maxQueryParallelism: 1}, config.SchemaConfig{Configs: testSchemas}, nil, false, nil, constants.Loki)
 	if stopper != nil {
@@ -1221,6 +1296,11 @@ func Test_getOperation(t *testing.T) {
 			path:       "/loki/api/v1/query_range",
 			expectedOp: QueryRangeOp,
 		},
+		{
+			name:       "explain",
+			path:       "/loki/api/v1/explain",
+			expectedOp: ExplainOp,
+		},
 		{
 			name:       "series_query",
 			path:       "/loki/api/v1/series",
@@ -1409,6 +1489,9 @@ type fakeLimits struct {
 	requiredNumberLabels        int
 	maxQueryBytesRead           int
 	maxQuerierBytesRead         int
+	scanQuotaHourly             int
+	scanQuotaDaily              int
+	scanQuotaSoftRatio          float64
 	maxStatsCacheFreshness      time.Duration
 	maxMetadataCacheFreshness   time.Duration
 	volumeEnabled               bool
@@ -1504,6 +1587,18 @@ func (f fakeLimits) MaxQuerierBytesRead(context.Context, string) int {
 	return f.maxQuerierBytesRead
 }
 
+func (f fakeLimits) QueryScanQuotaPerFrontendHourly(string) int {
+	return f.scanQuotaHourly
+}
+
+func (f fakeLimits) QueryScanQuotaPerFrontendDaily(string) int {
+	return f.scanQuotaDaily
+}
+
+func (f fakeLimits) QueryScanQuotaSoftRatio(string) float64 {
+	return f.scanQuotaSoftRatio
+}
+
 func (f fakeLimits) QueryTimeout(context.Context, string) time.Duration {
 	return f.queryTimeout
 }
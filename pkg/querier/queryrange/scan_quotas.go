@@ -0,0 +1,392 @@
+package queryrange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+	"github.com/grafana/loki/v3/pkg/util/spanlogger"
+)
+
+// Windows of the scan quotas.
+const (
+	ScanQuotaHourly = "hourly"
+	ScanQuotaDaily  = "daily"
+)
+
+// Headers of the responses of the queries subject to scan quotas. The remaining quotas are the
+// smallest remaining quotas of the tenants of the query.
+const (
+	scanQuotaHeaderPrefix          = "X-Loki-Scan-Quota-"
+	ScanQuotaHourlyRemainingHeader = scanQuotaHeaderPrefix + "Hourly-Remaining"
+	ScanQuotaDailyRemainingHeader  = scanQuotaHeaderPrefix + "Daily-Remaining"
+	ScanQuotaWarningHeader         = scanQuotaHeaderPrefix + "Warning"
+)
+
+const (
+	limErrScanQuotaExceededTmpl = "the query would exceed the %s scan quota of tenant %s (query: %s, used: %s, quota: %s); retry later or reduce the time range of the query"
+	scanQuotaWarningTmpl        = "the queries of tenant %s have used %s of their %s scan quota of %s"
+
+	// scanQuotaBuckets is the number of buckets the sliding windows are divided in.
+	scanQuotaBuckets = 60
+)
+
+// slidingWindow sums the bytes added over its size, with the precision of a bucket.
+type slidingWindow struct {
+	size    time.Duration
+	buckets [scanQuotaBuckets]uint64
+	// indexes of the buckets since the epoch.
+	indexes [scanQuotaBuckets]int64
+}
+
+func (w *slidingWindow) index(now time.Time) int64 {
+	return now.UnixNano() / int64(w.size/scanQuotaBuckets)
+}
+
+func (w *slidingWindow) sum(now time.Time) uint64 {
+	idx := w.index(now)
+	var sum uint64
+	for i, bucketIdx := range w.indexes {
+		if bucketIdx > idx-scanQuotaBuckets {
+			sum += w.buckets[i]
+		}
+	}
+	return sum
+}
+
+func (w *slidingWindow) add(now time.Time, bytes uint64) {
+	idx := w.index(now)
+	i := idx % scanQuotaBuckets
+	if w.indexes[i] != idx {
+		w.indexes[i] = idx
+		w.buckets[i] = 0
+	}
+	w.buckets[i] += bytes
+}
+
+// remove removes bytes added at a time, if they are still in the window.
+func (w *slidingWindow) remove(at time.Time, bytes uint64) {
+	idx := w.index(at)
+	i := idx % scanQuotaBuckets
+	if w.indexes[i] != idx {
+		return
+	}
+	w.buckets[i] -= min(bytes, w.buckets[i])
+}
+
+type tenantScanQuotas struct {
+	hourly slidingWindow
+	daily  slidingWindow
+}
+
+// ScanQuotas tracks the bytes fetched by the queries of the tenants over sliding windows of an
+// hour and a day, and enforces their hourly and daily scan quotas. The windows are kept in
+// memory: each query frontend enforces the quotas on the queries it receives, and the quotas
+// are not shared between the query frontends.
+type ScanQuotas struct {
+	limits Limits
+	now    func() time.Time
+
+	mtx     sync.Mutex
+	tenants map[string]*tenantScanQuotas
+
+	remaining *prometheus.GaugeVec
+	exceeded  *prometheus.CounterVec
+}
+
+// NewScanQuotas creates the scan quotas of the tenants on a query frontend.
+func NewScanQuotas(limits Limits, registerer prometheus.Registerer, metricsNamespace string) *ScanQuotas {
+	return &ScanQuotas{
+		limits:  limits,
+		now:     time.Now,
+		tenants: make(map[string]*tenantScanQuotas),
+		remaining: promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_scan_quota_remaining_bytes",
+			Help:      "Remaining scan quota of the tenant on this query frontend per window, updated by the queries of the tenant.",
+		}, []string{"tenant", "window"}),
+		exceeded: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_scan_quota_exceeded_total",
+			Help:      "Total number of queries exceeding the soft or hard scan quota of the tenant per window.",
+		}, []string{"tenant", "window", "threshold"}),
+	}
+}
+
+func (q *ScanQuotas) enabled(tenantIDs []string) bool {
+	for _, id := range tenantIDs {
+		if q.limits.QueryScanQuotaPerFrontendHourly(id) > 0 || q.limits.QueryScanQuotaPerFrontendDaily(id) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// scanQuotaUsage is the usage of a scan quota by a query.
+type scanQuotaUsage struct {
+	window    string
+	remaining uint64
+	warning   string
+}
+
+// scanQuotaReservation is the charge of a query to the scan quotas of its tenants.
+type scanQuotaReservation struct {
+	tenantIDs []string
+	at        time.Time
+	bytes     uint64
+	usages    []scanQuotaUsage
+}
+
+// reserve charges the bytes of a query to the scan quotas of its tenants. It rejects the query
+// without charging it when it would exceed one of the quotas.
+func (q *ScanQuotas) reserve(tenantIDs []string, bytes uint64) (*scanQuotaReservation, error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	now := q.now()
+	var usages []scanQuotaUsage
+	for _, id := range tenantIDs {
+		quotas, ok := q.tenants[id]
+		if !ok {
+			quotas = &tenantScanQuotas{
+				hourly: slidingWindow{size: time.Hour},
+				daily:  slidingWindow{size: 24 * time.Hour},
+			}
+			q.tenants[id] = quotas
+		}
+
+		ratio := q.limits.QueryScanQuotaSoftRatio(id)
+		for _, w := range []struct {
+			name   string
+			quota  int
+			window *slidingWindow
+		}{
+			{ScanQuotaHourly, q.limits.QueryScanQuotaPerFrontendHourly(id), &quotas.hourly},
+			{ScanQuotaDaily, q.limits.QueryScanQuotaPerFrontendDaily(id), &quotas.daily},
+		} {
+			if w.quota <= 0 {
+				continue
+			}
+			quota := uint64(w.quota)
+			used := w.window.sum(now)
+			if used+bytes > quota {
+				q.exceeded.WithLabelValues(id, w.name, "hard").Inc()
+				return nil, httpgrpc.Errorf(http.StatusTooManyRequests, limErrScanQuotaExceededTmpl, w.name, id, humanize.IBytes(bytes), humanize.IBytes(used), humanize.IBytes(quota))
+			}
+
+			usage := scanQuotaUsage{window: w.name, remaining: quota - used - bytes}
+			if ratio > 0 && float64(used+bytes) > ratio*float64(quota) {
+				q.exceeded.WithLabelValues(id, w.name, "soft").Inc()
+				usage.warning = fmt.Sprintf(scanQuotaWarningTmpl, id, humanize.IBytes(used+bytes), w.name, humanize.IBytes(quota))
+			}
+			usages = append(usages, usage)
+		}
+	}
+
+	for _, id := range tenantIDs {
+		quotas := q.tenants[id]
+		quotas.hourly.add(now, bytes)
+		quotas.daily.add(now, bytes)
+		q.updateRemaining(id, now)
+	}
+	return &scanQuotaReservation{tenantIDs: tenantIDs, at: now, bytes: bytes, usages: usages}, nil
+}
+
+// refund gives back bytes of a reservation to the scan quotas of its tenants.
+func (q *ScanQuotas) refund(r *scanQuotaReservation, bytes uint64) {
+	if bytes == 0 {
+		return
+	}
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	now := q.now()
+	for _, id := range r.tenantIDs {
+		quotas, ok := q.tenants[id]
+		if !ok {
+			continue
+		}
+		quotas.hourly.remove(r.at, bytes)
+		quotas.daily.remove(r.at, bytes)
+		q.updateRemaining(id, now)
+	}
+	for i := range r.usages {
+		r.usages[i].remaining += bytes
+	}
+}
+
+func (q *ScanQuotas) updateRemaining(tenantID string, now time.Time) {
+	quotas := q.tenants[tenantID]
+	if quota := q.limits.QueryScanQuotaPerFrontendHourly(tenantID); quota > 0 {
+		q.remaining.WithLabelValues(tenantID, ScanQuotaHourly).Set(float64(quota) - float64(quotas.hourly.sum(now)))
+	}
+	if quota := q.limits.QueryScanQuotaPerFrontendDaily(tenantID); quota > 0 {
+		q.remaining.WithLabelValues(tenantID, ScanQuotaDaily).Set(float64(quota) - float64(quotas.daily.sum(now)))
+	}
+}
+
+// scanQuotaRequests records the requests of a query charged to the scan quotas which are sent
+// to the results cache, and the ones which are executed because they are not cached, weighted
+// by their time range.
+type scanQuotaRequests struct {
+	mtx       sync.Mutex
+	requested time.Duration
+	executed  time.Duration
+}
+
+func (u *scanQuotaRequests) add(r queryrangebase.Request, executed bool) {
+	// Instant queries have no time range, so they are weighted equally.
+	weight := max(r.GetEnd().Sub(r.GetStart()), 1)
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if executed {
+		u.executed += weight
+	} else {
+		u.requested += weight
+	}
+}
+
+// charged returns the part of the bytes of the query which is not answered by the results cache.
+func (u *scanQuotaRequests) charged(bytes uint64) uint64 {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if u.requested == 0 || u.executed >= u.requested {
+		return bytes
+	}
+	return uint64(float64(bytes) * float64(u.executed) / float64(u.requested))
+}
+
+type scanQuotaRequestsKey struct{}
+
+// scanQuotaRequestsMiddleware records the requests of the queries charged to the scan quotas.
+// It is set before the results cache to record the requests sent to the cache, and after the
+// results cache, with executed set, to record the requests which are not cached.
+func scanQuotaRequestsMiddleware(executed bool) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return queryrangebase.HandlerFunc(func(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+			if u, ok := ctx.Value(scanQuotaRequestsKey{}).(*scanQuotaRequests); ok {
+				u.add(r, executed)
+			}
+			return next.Do(ctx, r)
+		})
+	})
+}
+
+type scanQuotaLimiter struct {
+	*querySizeLimiter
+	quotas *ScanQuotas
+}
+
+// NewScanQuotaMiddleware creates a new Middleware that charges the bytes a query would fetch,
+// estimated from the index stats, to the scan quotas of its tenants, and rejects the queries
+// exceeding them. The bytes are refunded when the query fails, and in proportion of the part
+// of the query answered by the results cache when it succeeds. The remaining quotas are set
+// as headers of the responses.
+func NewScanQuotaMiddleware(
+	cfg []config.PeriodConfig,
+	engineOpts logql.EngineOpts,
+	logger log.Logger,
+	quotas *ScanQuotas,
+	statsHandler ...queryrangebase.Handler,
+) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return &scanQuotaLimiter{
+			querySizeLimiter: newQuerySizeLimiter(next, cfg, engineOpts, logger, nil, "", statsHandler...),
+			quotas:           quotas,
+		}
+	})
+}
+
+func (q *scanQuotaLimiter) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+	log := spanlogger.FromContext(ctx)
+
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	if !q.quotas.enabled(tenantIDs) {
+		return q.next.Do(ctx, r)
+	}
+
+	// Only support TSDB
+	schemaCfg, err := q.getSchemaCfg(r)
+	if err != nil {
+		level.Warn(log).Log("msg", "failed to get schema config, not applying scan quotas", "err", err)
+		return q.next.Do(ctx, r)
+	}
+	if schemaCfg.IndexType != types.TSDBType {
+		return q.next.Do(ctx, r)
+	}
+
+	// The bytes are estimated once per query, by the query size limiter when it is enabled.
+	bytesRead, ok := queryBytesReadFromContext(ctx, r)
+	if !ok {
+		bytesRead, err = q.getBytesReadForRequest(ctx, r)
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "Failed to get bytes read stats for query: %s", err.Error())
+		}
+	}
+	reservation, err := q.quotas.reserve(tenantIDs, bytesRead)
+	if err != nil {
+		level.Warn(log).Log("msg", "Query exceeds scan quota", "status", "rejected", "resolved_bytes", humanize.IBytes(bytesRead), "err", err)
+		return nil, err
+	}
+
+	requests := &scanQuotaRequests{}
+	res, err := q.next.Do(context.WithValue(ctx, scanQuotaRequestsKey{}, requests), r)
+	if err != nil {
+		q.quotas.refund(reservation, reservation.bytes)
+		return nil, err
+	}
+	q.quotas.refund(reservation, reservation.bytes-requests.charged(reservation.bytes))
+
+	remaining := make(map[string]uint64, 2)
+	var warnings []string
+	for _, u := range reservation.usages {
+		if v, ok := remaining[u.window]; !ok || u.remaining < v {
+			remaining[u.window] = u.remaining
+		}
+		if u.warning != "" {
+			warnings = append(warnings, u.warning)
+		}
+	}
+	if v, ok := remaining[ScanQuotaHourly]; ok {
+		res.SetHeader(ScanQuotaHourlyRemainingHeader, strconv.FormatUint(v, 10))
+	}
+	if v, ok := remaining[ScanQuotaDaily]; ok {
+		res.SetHeader(ScanQuotaDailyRemainingHeader, strconv.FormatUint(v, 10))
+	}
+	if len(warnings) > 0 {
+		res.SetHeader(ScanQuotaWarningHeader, strings.Join(warnings, "; "))
+	}
+	return res, nil
+}
+
+// scanQuotaHeaders returns the scan quota headers of a response, to forward them to the client.
+func scanQuotaHeaders(res queryrangebase.Response) http.Header {
+	h := http.Header{}
+	for _, header := range res.GetHeaders() {
+		if header != nil && strings.HasPrefix(header.Name, scanQuotaHeaderPrefix) {
+			for _, v := range header.Values {
+				h.Add(header.Name, v)
+			}
+		}
+	}
+	return h
+}
@@ -0,0 +1,224 @@
+package queryrange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	w := slidingWindow{size: time.Hour}
+	now := testTime
+
+	w.add(now, 10)
+	w.add(now.Add(30*time.Minute), 20)
+	require.Equal(t, uint64(30), w.sum(now.Add(30*time.Minute)))
+	require.Equal(t, uint64(30), w.sum(now.Add(59*time.Minute)))
+	require.Equal(t, uint64(20), w.sum(now.Add(61*time.Minute)))
+	require.Equal(t, uint64(0), w.sum(now.Add(91*time.Minute)))
+
+	// The bucket of an expired index is reset when it is reused.
+	w.add(now.Add(2*time.Hour), 5)
+	require.Equal(t, uint64(5), w.sum(now.Add(2*time.Hour)))
+
+	// The bytes are only removed while their bucket is in the window.
+	w.remove(now.Add(30*time.Minute), 20)
+	w.remove(now.Add(2*time.Hour), 10)
+	require.Equal(t, uint64(0), w.sum(now.Add(2*time.Hour)))
+}
+
+func TestScanQuotas_Reserve(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	quotas := NewScanQuotas(fakeLimits{scanQuotaHourly: 1000, scanQuotaDaily: 1500, scanQuotaSoftRatio: 0.5}, reg, "loki")
+	now := testTime
+	quotas.now = func() time.Time { return now }
+
+	r, err := quotas.reserve([]string{"1"}, 400)
+	require.NoError(t, err)
+	require.Equal(t, []scanQuotaUsage{
+		{window: ScanQuotaHourly, remaining: 600},
+		{window: ScanQuotaDaily, remaining: 1100},
+	}, r.usages)
+
+	r, err = quotas.reserve([]string{"1"}, 200)
+	require.NoError(t, err)
+	require.Equal(t, uint64(400), r.usages[0].remaining)
+	require.Contains(t, r.usages[0].warning, "hourly scan quota")
+	require.Empty(t, r.usages[1].warning)
+
+	// The refunded bytes are given back to the quotas.
+	quotas.refund(r, 200)
+	require.Equal(t, uint64(600), r.usages[0].remaining)
+	require.Equal(t, float64(600), testutil.ToFloat64(quotas.remaining.WithLabelValues("1", ScanQuotaHourly)))
+	r, err = quotas.reserve([]string{"1"}, 200)
+	require.NoError(t, err)
+	require.Equal(t, uint64(400), r.usages[0].remaining)
+
+	// Queries exceeding a quota are rejected and not charged.
+	_, err = quotas.reserve([]string{"1"}, 500)
+	require.Error(t, err)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	require.Contains(t, string(resp.Body), "hourly scan quota of tenant 1")
+
+	// Other tenants have their own quotas.
+	_, err = quotas.reserve([]string{"2"}, 500)
+	require.NoError(t, err)
+
+	// The hourly window slides, but not the daily window.
+	now = now.Add(61 * time.Minute)
+	_, err = quotas.reserve([]string{"1"}, 500)
+	require.NoError(t, err)
+	_, err = quotas.reserve([]string{"1"}, 500)
+	require.ErrorContains(t, err, "daily scan quota of tenant 1")
+
+	require.Equal(t, float64(400), testutil.ToFloat64(quotas.remaining.WithLabelValues("1", ScanQuotaDaily)))
+	require.Equal(t, float64(500), testutil.ToFloat64(quotas.remaining.WithLabelValues("1", ScanQuotaHourly)))
+	require.Equal(t, float64(1), testutil.ToFloat64(quotas.exceeded.WithLabelValues("1", ScanQuotaHourly, "hard")))
+	require.Equal(t, float64(1), testutil.ToFloat64(quotas.exceeded.WithLabelValues("1", ScanQuotaDaily, "hard")))
+}
+
+// newScanQuotaRequest returns a request of the query over the hour before testTime.
+func newScanQuotaRequest(query string) *LokiRequest {
+	return &LokiRequest{
+		Query:     query,
+		Limit:     1000,
+		StartTs:   testTime.Add(-time.Hour),
+		EndTs:     testTime,
+		Direction: logproto.FORWARD,
+		Path:      "/loki/api/v1/query_range",
+		Plan: &plan.QueryPlan{
+			AST: syntax.MustParseExpr(query),
+		},
+	}
+}
+
+func TestScanQuotaMiddleware(t *testing.T) {
+	limits := fakeLimits{scanQuotaHourly: 2500, scanQuotaSoftRatio: 0.5}
+	quotas := NewScanQuotas(limits, nil, "loki")
+	statsCount, statsHandler := indexStatsResult(logproto.IndexStatsResponse{Bytes: 1000})
+	queryCount, queryHandler := promqlResult(matrix)
+	handler := NewScanQuotaMiddleware(testSchemasTSDB, testEngineOpts, util_log.Logger, quotas, statsHandler).Wrap(queryHandler)
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	query := `rate({app="foo"} |= "foo"[1m])`
+	req := newScanQuotaRequest(query)
+
+	res, err := handler.Do(ctx, req)
+	require.NoError(t, err)
+	headers := scanQuotaHeaders(res)
+	require.Equal(t, "1500", headers.Get(ScanQuotaHourlyRemainingHeader))
+	require.Empty(t, headers.Get(ScanQuotaDailyRemainingHeader))
+	require.Empty(t, headers.Get(ScanQuotaWarningHeader))
+
+	res, err = handler.Do(ctx, req)
+	require.NoError(t, err)
+	headers = scanQuotaHeaders(res)
+	require.Equal(t, "500", headers.Get(ScanQuotaHourlyRemainingHeader))
+	require.NotEmpty(t, headers.Get(ScanQuotaWarningHeader))
+
+	// The headers are forwarded to the client.
+	httpReq := httptest.NewRequest(http.MethodGet, "/loki/api/v1/query_range", nil)
+	httpRes, err := DefaultCodec.EncodeResponse(ctx, httpReq, res)
+	require.NoError(t, err)
+	require.Equal(t, "500", httpRes.Header.Get(ScanQuotaHourlyRemainingHeader))
+
+	_, err = handler.Do(ctx, req)
+	require.ErrorContains(t, err, "the query would exceed the hourly scan quota of tenant 1")
+	require.Equal(t, 3, *statsCount)
+	require.Equal(t, 2, *queryCount)
+
+	// Tenants without quotas do not request the index stats.
+	handler = NewScanQuotaMiddleware(testSchemasTSDB, testEngineOpts, util_log.Logger, NewScanQuotas(fakeLimits{}, nil, "loki"), statsHandler).Wrap(queryHandler)
+	_, err = handler.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 3, *statsCount)
+}
+
+func TestScanQuotaMiddleware_Refund(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	query := `rate({app="foo"} |= "foo"[1m])`
+	req := newScanQuotaRequest(query)
+	_, statsHandler := indexStatsResult(logproto.IndexStatsResponse{Bytes: 1000})
+	_, queryHandler := promqlResult(matrix)
+
+	for _, tc := range []struct {
+		name      string
+		handler   queryrangebase.Handler
+		remaining float64
+	}{
+		{
+			name: "failed",
+			handler: queryrangebase.HandlerFunc(func(context.Context, queryrangebase.Request) (queryrangebase.Response, error) {
+				return nil, errors.New("failed")
+			}),
+			remaining: 2500,
+		},
+		{
+			name: "cached",
+			handler: queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+				// The results cache answers the query without executing it.
+				return queryHandler.Do(context.Background(), r)
+			}),
+			remaining: 2500,
+		},
+		{
+			name: "partially cached",
+			handler: queryrangebase.HandlerFunc(func(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+				// The results cache executes the last quarter of the query.
+				return scanQuotaRequestsMiddleware(true).Wrap(queryHandler).Do(ctx, r.WithStartEnd(testTime.Add(-15*time.Minute), testTime))
+			}),
+			remaining: 2250,
+		},
+		{
+			name:      "not cached",
+			handler:   scanQuotaRequestsMiddleware(true).Wrap(queryHandler),
+			remaining: 1500,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			quotas := NewScanQuotas(fakeLimits{scanQuotaHourly: 2500}, nil, "loki")
+			handler := queryrangebase.MergeMiddlewares(
+				NewScanQuotaMiddleware(testSchemasTSDB, testEngineOpts, util_log.Logger, quotas, statsHandler),
+				scanQuotaRequestsMiddleware(false),
+			).Wrap(tc.handler)
+			_, _ = handler.Do(ctx, req)
+			require.Equal(t, tc.remaining, testutil.ToFloat64(quotas.remaining.WithLabelValues("1", ScanQuotaHourly)))
+		})
+	}
+}
+
+func TestScanQuotaMiddleware_ReuseQuerySizeStats(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	query := `rate({app="foo"} |= "foo"[1m])`
+	req := newScanQuotaRequest(query)
+	statsCount, statsHandler := indexStatsResult(logproto.IndexStatsResponse{Bytes: 1000})
+	_, queryHandler := promqlResult(matrix)
+
+	limits := fakeLimits{maxQueryBytesRead: 2000, scanQuotaHourly: 2500}
+	handler := queryrangebase.MergeMiddlewares(
+		NewQuerySizeLimiterMiddleware(testSchemasTSDB, testEngineOpts, util_log.Logger, limits, statsHandler),
+		NewScanQuotaMiddleware(testSchemasTSDB, testEngineOpts, util_log.Logger, NewScanQuotas(limits, nil, "loki"), statsHandler),
+	).Wrap(queryHandler)
+
+	res, err := handler.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, "1500", scanQuotaHeaders(res).Get(ScanQuotaHourlyRemainingHeader))
+	require.Equal(t, 1, *statsCount)
+}
@@ -0,0 +1,80 @@
+package queryrange
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+)
+
+// resolveInExprs evaluates the queries of the in() stages of a query once in
+// the frontend, so that all the splits and shards of the query get the
+// resulting label filters instead of evaluating the queries themselves.
+// The queries are sent through the round tripper, so they are split, sharded
+// and cached like any other instant query.
+func (r roundTripper) resolveInExprs(ctx context.Context, req base.Request) (base.Request, error) {
+	if r.maxInValues == 0 {
+		return req, nil
+	}
+
+	var p *plan.QueryPlan
+	switch op := req.(type) {
+	case *LokiRequest:
+		p = op.Plan
+	case *LokiInstantRequest:
+		p = op.Plan
+	}
+	if p == nil || p.AST == nil || !logql.HasInExpr(p.AST) {
+		return req, nil
+	}
+
+	resolved, err := logql.ResolveInExprs(ctx, p.AST, req.GetStart(), req.GetEnd(), r.maxInValues, r.evalInQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op := req.(type) {
+	case *LokiRequest:
+		clone := *op
+		clone.Query = resolved.String()
+		clone.Plan = &plan.QueryPlan{AST: resolved}
+		return &clone, nil
+	case *LokiInstantRequest:
+		clone := *op
+		clone.Query = resolved.String()
+		clone.Plan = &plan.QueryPlan{AST: resolved}
+		return &clone, nil
+	default:
+		return req, nil
+	}
+}
+
+func (r roundTripper) evalInQuery(ctx context.Context, expr syntax.SampleExpr, ts time.Time) ([]labels.Labels, error) {
+	resp, err := r.Do(ctx, &LokiInstantRequest{
+		Query:     expr.String(),
+		TimeTs:    ts,
+		Direction: logproto.BACKWARD,
+		Path:      "/loki/api/v1/query",
+		Plan:      &plan.QueryPlan{AST: expr},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	promResp, ok := resp.(*LokiPromResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type (%T) of an in() query", resp)
+	}
+	series := make([]labels.Labels, 0, len(promResp.Response.Data.Result))
+	for _, s := range promResp.Response.Data.Result {
+		series = append(series, logproto.FromLabelAdaptersToLabels(s.Labels))
+	}
+	return series, nil
+}
@@ -15,13 +15,15 @@ type serializeRoundTripper struct {
 	codec          queryrangebase.Codec
 	next           queryrangebase.Handler
 	parquetSupport bool
+	arrowSupport   bool
 }
 
-func NewSerializeRoundTripper(next queryrangebase.Handler, codec queryrangebase.Codec, parquetSupport bool) http.RoundTripper {
+func NewSerializeRoundTripper(next queryrangebase.Handler, codec queryrangebase.Codec, parquetSupport, arrowSupport bool) http.RoundTripper {
 	return &serializeRoundTripper{
 		next:           next,
 		codec:          codec,
 		parquetSupport: parquetSupport,
+		arrowSupport:   arrowSupport,
 	}
 }
 
@@ -43,6 +45,9 @@ func (rt *serializeRoundTripper) RoundTrip(r *http.Request) (*http.Response, err
 	if r.Header.Get("Accept") == ParquetType && !rt.parquetSupport {
 		return nil, serverutil.UserError("support for Parquet encoded responses is disabled. Enable with -frontend.support-parquet-encoding=true")
 	}
+	if r.Header.Get("Accept") == ArrowType && !rt.arrowSupport {
+		return nil, serverutil.UserError("support for Arrow encoded responses is disabled. Enable with -frontend.support-arrow-encoding=true")
+	}
 
 	return rt.codec.EncodeResponse(ctx, r, response)
 }
@@ -77,12 +82,19 @@ func (rt *serializeHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 
 	// TODO(karsten): use rt.codec.EncodeResponse(ctx, r, response) which is the central encoding logic instead.
-	if r.Header.Get("Accept") == ParquetType {
+	switch r.Header.Get("Accept") {
+	case ParquetType:
 		w.Header().Add("Content-Type", ParquetType)
 		if err := encodeResponseParquetTo(ctx, response, w); err != nil {
 			serverutil.WriteError(err, w)
 		}
 		return
+	case ArrowType:
+		w.Header().Add("Content-Type", ArrowType)
+		if err := encodeResponseArrowTo(ctx, response, w); err != nil {
+			serverutil.WriteError(err, w)
+		}
+		return
 	}
 	version := loghttp.GetVersion(r.RequestURI)
 	encodingFlags := httpreq.ExtractEncodingFlags(r)
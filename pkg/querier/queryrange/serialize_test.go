@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
 )
 
 func TestResponseFormat(t *testing.T) {
@@ -130,3 +131,35 @@ func TestResponseFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializeRoundTripper_ColumnarFormats(t *testing.T) {
+	handler := queryrangebase.HandlerFunc(func(_ context.Context, _ queryrangebase.Request) (queryrangebase.Response, error) {
+		return &LokiLabelNamesResponse{Status: "success", Data: []string{"foo"}}, nil
+	})
+
+	for _, tc := range []struct {
+		accept                       string
+		parquetSupport, arrowSupport bool
+		expectedErr                  error
+	}{
+		{accept: ParquetType, parquetSupport: true},
+		{accept: ParquetType, expectedErr: serverutil.UserError("support for Parquet encoded responses is disabled. Enable with -frontend.support-parquet-encoding=true")},
+		{accept: ArrowType, arrowSupport: true},
+		{accept: ArrowType, expectedErr: serverutil.UserError("support for Arrow encoded responses is disabled. Enable with -frontend.support-arrow-encoding=true")},
+	} {
+		t.Run(fmt.Sprintf("%s parquet=%t arrow=%t", tc.accept, tc.parquetSupport, tc.arrowSupport), func(t *testing.T) {
+			rt := NewSerializeRoundTripper(handler, DefaultCodec, tc.parquetSupport, tc.arrowSupport)
+
+			req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/labels?start=0&end=1", nil)
+			req.Header.Set("Accept", tc.accept)
+			req = req.WithContext(user.InjectOrgID(context.Background(), "1"))
+			resp, err := rt.RoundTrip(req)
+			if tc.expectedErr != nil {
+				require.Equal(t, tc.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.accept, resp.Header.Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package queryrange
+
+import (
+	"fmt"
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/util/marshal"
+)
+
+// splitResultsWriter encodes the responses of the splits of a range query as a single
+// response. The responses are written one at a time, in the order of the splits, so
+// that they do not have to be kept in memory until the end of the query.
+type splitResultsWriter interface {
+	Write(res base.Response) error
+	Close() error
+}
+
+// rowsSplitResultsWriter writes the rows of the responses of the splits to a Parquet
+// file, with a row group per split, or to a stream of Arrow record batches.
+type rowsSplitResultsWriter struct {
+	sink    rowSink
+	logs    rowWriter[LogStreamRowType]
+	metrics rowWriter[MetricRowType]
+}
+
+func newRowsSplitResultsWriter(sink rowSink) *rowsSplitResultsWriter {
+	return &rowsSplitResultsWriter{sink: sink}
+}
+
+func (w *rowsSplitResultsWriter) Write(res base.Response) error {
+	switch r := res.(type) {
+	case *LokiResponse:
+		if w.logs == nil {
+			writer, err := newRowWriter[LogStreamRowType](w.sink)
+			if err != nil {
+				return err
+			}
+			w.logs = writer
+		}
+		if err := writeLogRows(w.logs, r); err != nil {
+			return err
+		}
+		return flushRows(w.logs)
+	case *LokiPromResponse:
+		if w.metrics == nil {
+			writer, err := newRowWriter[MetricRowType](w.sink)
+			if err != nil {
+				return err
+			}
+			w.metrics = writer
+		}
+		if err := writeMetricRows(w.metrics, r); err != nil {
+			return err
+		}
+		return flushRows(w.metrics)
+	default:
+		return fmt.Errorf("unexpected response type %T", res)
+	}
+}
+
+func (w *rowsSplitResultsWriter) Close() error {
+	if w.logs != nil {
+		return w.logs.Close()
+	}
+	if w.metrics != nil {
+		return w.metrics.Close()
+	}
+	return nil
+}
+
+// limitSplitResponse drops the lines of the response of a split of a log query above the
+// limit of the query, given the number of lines returned by the previous splits. It returns
+// the response and the number of lines returned by the splits so far.
+func limitSplitResponse(res base.Response, limit, lines uint32) (base.Response, uint32) {
+	lokiRes, ok := res.(*LokiResponse)
+	if !ok {
+		return res, lines
+	}
+	if limit > 0 && lines+uint32(lokiRes.Count()) > limit {
+		lokiRes.Limit = limit - lines
+		lokiRes = mergeLokiResponse(lokiRes)
+	}
+	return lokiRes, lines + uint32(lokiRes.Count())
+}
+
+// flushRows writes the buffered rows of a Parquet writer as a row group, or of an Arrow
+// writer as a record batch.
+func flushRows[T any](writer rowWriter[T]) error {
+	if f, ok := writer.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// jsonSplitResultsWriter writes the responses of the splits in the format of the query
+// range API. The streams of log queries are written split by split, so a stream has an
+// entry in the result for each split it has lines in. The matrices of metric queries are
+// merged by series, which requires to keep the responses of all the splits in memory;
+// their size is bounded by the number of series and steps of the query.
+type jsonSplitResultsWriter struct {
+	w io.Writer
+	s *jsoniter.Stream
+
+	started    bool
+	streams    int
+	statistics stats.Result
+	warnings   []string
+	seen       map[string]struct{}
+
+	metrics []base.Response
+}
+
+func newJSONSplitResultsWriter(w io.Writer) *jsonSplitResultsWriter {
+	return &jsonSplitResultsWriter{
+		w:    w,
+		s:    jsoniter.ConfigFastest.BorrowStream(w),
+		seen: make(map[string]struct{}),
+	}
+}
+
+func (w *jsonSplitResultsWriter) Write(res base.Response) error {
+	switch r := res.(type) {
+	case *LokiResponse:
+		if !w.started {
+			w.s.WriteRaw(`{"status":"success","data":{"resultType":"streams","result":[`)
+			w.started = true
+		}
+		for _, stream := range r.Data.Result {
+			if w.streams > 0 {
+				w.s.WriteMore()
+			}
+			if err := marshal.EncodeStream(stream, w.s, nil); err != nil {
+				return err
+			}
+			w.streams++
+		}
+		w.statistics.MergeSplit(r.Statistics)
+		for _, warning := range r.Warnings {
+			if _, ok := w.seen[warning]; !ok {
+				w.seen[warning] = struct{}{}
+				w.warnings = append(w.warnings, warning)
+			}
+		}
+		return w.s.Flush()
+	case *LokiPromResponse:
+		w.metrics = append(w.metrics, r)
+		return nil
+	default:
+		return fmt.Errorf("unexpected response type %T", res)
+	}
+}
+
+func (w *jsonSplitResultsWriter) Close() error {
+	defer jsoniter.ConfigFastest.ReturnStream(w.s)
+
+	if len(w.metrics) > 0 {
+		res, err := DefaultCodec.MergeResponse(w.metrics...)
+		if err != nil {
+			return err
+		}
+		return encodeResponseJSONTo(loghttp.VersionV1, res, w.w, nil)
+	}
+	if !w.started {
+		return nil
+	}
+
+	w.s.WriteRaw(`],"stats":`)
+	w.s.WriteVal(w.statistics)
+	w.s.WriteObjectEnd()
+	if len(w.warnings) > 0 {
+		w.s.WriteMore()
+		w.s.WriteObjectField("warnings")
+		w.s.WriteVal(w.warnings)
+	}
+	w.s.WriteObjectEnd()
+	w.s.WriteRaw("\n")
+	return w.s.Flush()
+}
@@ -10,6 +10,7 @@ import (
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/validation"
@@ -369,3 +370,27 @@ func ingesterQueryBounds(execTime time.Time, iqo util.IngesterQueryOptions, req
 
 	return ingesterWindow, end, true
 }
+
+// splitInOrder splits a range query by interval, and returns its splits in the direction of the
+// query: the splits of backward log queries are returned from the most recent to the oldest.
+func splitInOrder(limits Limits, execTime time.Time, tenantIDs []string, req *LokiRequest, interval time.Duration) []*LokiRequest {
+	var s splitter = newDefaultSplitter(limits, nil)
+	if _, ok := req.Plan.AST.(syntax.SampleExpr); ok {
+		s = newMetricQuerySplitter(limits, nil)
+	}
+	reqs := s.split(execTime, tenantIDs, req, interval)
+	if len(reqs) == 0 {
+		return []*LokiRequest{req}
+	}
+
+	splits := make([]*LokiRequest, 0, len(reqs))
+	for _, r := range reqs {
+		splits = append(splits, r.(*LokiRequest))
+	}
+	if req.Direction == logproto.BACKWARD {
+		for i, j := 0, len(splits)-1; i < j; i, j = i+1, j-1 {
+			splits[i], splits[j] = splits[j], splits[i]
+		}
+	}
+	return splits
+}
@@ -0,0 +1,272 @@
+package ruler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/rules"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+const (
+	// AlertSampleLinesAnnotation opts an alerting rule into having sample log lines attached to its firing alerts.
+	// Its value is the number of lines to attach, capped by the ruler_alert_sample_lines_limit limit.
+	AlertSampleLinesAnnotation = "loki_attach_sample_lines"
+	// AlertSampleQueryAnnotation holds the log query the sample log lines were selected with.
+	AlertSampleQueryAnnotation = "loki_sample_query"
+	// AlertSampleLogLinesAnnotation holds the sample log lines, most recent first and separated by newlines.
+	AlertSampleLogLinesAnnotation = "loki_sample_lines"
+
+	maxSampleLineLength = 1024
+	sampleQueryTimeout  = 10 * time.Second
+	// sampleQueryConcurrency is the maximum number of sample queries run in parallel for a notification.
+	sampleQueryConcurrency = 4
+	// sampledAlertTTL is how long the samples of an alert are kept once it stopped being sent.
+	sampledAlertTTL = time.Hour
+)
+
+var errLogsEvaluationUnsupported = errors.New("evaluator does not support log queries")
+
+// alertSampler attaches sample log lines to firing alerts of rules which opt in with the
+// AlertSampleLinesAnnotation annotation. Samples are queried once per alert activation and
+// reused when the alert is resent.
+type alertSampler struct {
+	userID    string
+	evaluator LogsEvaluator
+	limits    RulesLimits
+	rules     RuleIter
+	logger    log.Logger
+
+	mtx     sync.Mutex
+	sampled map[uint64]*sampledAlert
+}
+
+type sampledAlert struct {
+	activeAt    time.Time
+	annotations map[string]string
+	lastSeen    time.Time
+}
+
+func newAlertSampler(userID string, evaluator LogsEvaluator, limits RulesLimits, rules RuleIter, logger log.Logger) *alertSampler {
+	return &alertSampler{
+		userID:    userID,
+		evaluator: evaluator,
+		limits:    limits,
+		rules:     rules,
+		logger:    log.With(logger, "subcomponent", "alert-sampler"),
+		sampled:   make(map[uint64]*sampledAlert),
+	}
+}
+
+// wrap returns a rules.NotifyFunc which enriches the alerts before handing them to next.
+func (s *alertSampler) wrap(next rules.NotifyFunc) rules.NotifyFunc {
+	return func(ctx context.Context, expr string, alerts ...*rules.Alert) {
+		s.enrich(ctx, expr, alerts)
+		next(ctx, expr, alerts...)
+	}
+}
+
+func (s *alertSampler) enrich(ctx context.Context, expr string, alerts []*rules.Alert) {
+	limit := s.limits.RulerAlertSampleLinesLimit(s.userID)
+	now := time.Now()
+
+	// counts holds the number of lines to attach to each alert, 0 if none.
+	counts := make([]int, len(alerts))
+	var jobs []*sampleJob
+
+	s.mtx.Lock()
+	for i, alert := range alerts {
+		value := alert.Annotations.Get(AlertSampleLinesAnnotation)
+		if value == "" {
+			continue
+		}
+
+		// The annotation only configures the ruler and is not forwarded to the Alertmanager.
+		b := labels.NewBuilder(alert.Annotations)
+		b.Del(AlertSampleLinesAnnotation)
+		alert.Annotations = b.Labels()
+
+		n, err := strconv.Atoi(value)
+		if limit <= 0 || err != nil || n <= 0 || !alert.ResolvedAt.IsZero() {
+			continue
+		}
+		counts[i] = min(n, limit)
+
+		key := alert.Labels.Hash()
+		if sampled, ok := s.sampled[key]; !ok || !sampled.activeAt.Equal(alert.ActiveAt) {
+			jobs = append(jobs, &sampleJob{key: key, alert: alert, n: counts[i]})
+		}
+	}
+	s.mtx.Unlock()
+
+	// The queries run without holding the lock, so that the other rule groups of the
+	// tenant are not blocked by them, and in parallel to bound the notification delay.
+	_ = concurrency.ForEachJob(ctx, len(jobs), sampleQueryConcurrency, func(ctx context.Context, idx int) error {
+		jobs[idx].annotations = s.sample(ctx, expr, jobs[idx].alert, jobs[idx].n)
+		return nil
+	})
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, job := range jobs {
+		s.sampled[job.key] = &sampledAlert{
+			activeAt:    job.alert.ActiveAt,
+			annotations: job.annotations,
+		}
+	}
+
+	for i, alert := range alerts {
+		if counts[i] == 0 {
+			continue
+		}
+		sampled, ok := s.sampled[alert.Labels.Hash()]
+		if !ok {
+			continue
+		}
+		sampled.lastSeen = now
+
+		b := labels.NewBuilder(alert.Annotations)
+		for name, value := range sampled.annotations {
+			b.Set(name, value)
+		}
+		alert.Annotations = b.Labels()
+	}
+
+	for key, sampled := range s.sampled {
+		if now.Sub(sampled.lastSeen) > sampledAlertTTL {
+			delete(s.sampled, key)
+		}
+	}
+}
+
+// sampleJob is a sample query to run for a new activation of an alert.
+type sampleJob struct {
+	key         uint64
+	alert       *rules.Alert
+	n           int
+	annotations map[string]string
+}
+
+// sample runs the log query of the alert and returns the annotations to attach to it.
+func (s *alertSampler) sample(ctx context.Context, expr string, alert *rules.Alert, n int) map[string]string {
+	logger := log.With(s.logger, "alert", alert.Labels.Get(labels.AlertName))
+
+	query, lookback, err := sampleQuery(expr, alert.Labels, s.ruleLabels(alert.Labels.Get(labels.AlertName)))
+	if err != nil {
+		level.Warn(logger).Log("msg", "failed to build sample query", "err", err)
+		return nil
+	}
+	annotations := map[string]string{AlertSampleQueryAnnotation: query}
+
+	end := alert.LastSentAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	ctx, cancel := context.WithTimeout(user.InjectOrgID(ctx, s.userID), sampleQueryTimeout)
+	defer cancel()
+
+	res, err := s.evaluator.EvalLogs(ctx, query, end.Add(-lookback), end, uint32(n))
+	if err != nil {
+		level.Warn(logger).Log("msg", "failed to query sample log lines", "query", query, "err", err)
+		return annotations
+	}
+
+	if lines := sampleLines(res, n); lines != "" {
+		annotations[AlertSampleLogLinesAnnotation] = lines
+	}
+	return annotations
+}
+
+// ruleLabels returns the names of the labels set by the alerting rules with the given name. These labels
+// are not part of the query result and therefore cannot be used to filter log lines.
+func (s *alertSampler) ruleLabels(alertName string) map[string]struct{} {
+	names := map[string]struct{}{labels.AlertName: {}}
+	for _, r := range s.rules.AlertingRules() {
+		if r.Alert != alertName {
+			continue
+		}
+		for name := range r.Labels {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+// sampleQuery derives the log query selecting the lines which caused an alert from the rule expression: the
+// log selector and pipeline of its first range aggregation, filtered by the labels of the alert. The returned
+// lookback is the range of the aggregation, including its offset.
+func sampleQuery(expr string, alertLabels labels.Labels, excluded map[string]struct{}) (string, time.Duration, error) {
+	sampleExpr, err := syntax.ParseSampleExpr(expr)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var logRange *syntax.LogRangeExpr
+	sampleExpr.Walk(func(e syntax.Expr) {
+		if r, ok := e.(*syntax.LogRangeExpr); ok && logRange == nil {
+			logRange = r
+		}
+	})
+	if logRange == nil {
+		return "", 0, fmt.Errorf("expression %q has no range aggregation", expr)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(logRange.Left.String())
+	alertLabels.Range(func(l labels.Label) {
+		if _, ok := excluded[l.Name]; ok {
+			return
+		}
+		sb.WriteString(" | ")
+		sb.WriteString(labels.MustNewMatcher(labels.MatchEqual, l.Name, l.Value).String())
+	})
+
+	query := sb.String()
+	if _, err := syntax.ParseLogSelector(query, true); err != nil {
+		return "", 0, fmt.Errorf("invalid sample query %q: %w", query, err)
+	}
+	return query, logRange.Interval + logRange.Offset, nil
+}
+
+// sampleLines returns the n most recent lines of a log query result.
+func sampleLines(res *logqlmodel.Result, n int) string {
+	streams, ok := res.Data.(logqlmodel.Streams)
+	if !ok {
+		return ""
+	}
+
+	var entries []logproto.Entry
+	for _, s := range streams {
+		entries = append(entries, s.Entries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.After(entries[j].Timestamp) })
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		line := e.Line
+		if len(line) > maxSampleLineLength {
+			line = line[:maxSampleLineLength] + "..."
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,197 @@
+package ruler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+type fakeLogsEvaluator struct {
+	mtx     sync.Mutex
+	queries []string
+	start   time.Time
+	end     time.Time
+	limit   uint32
+
+	delay       time.Duration
+	inflight    int
+	maxInflight int
+}
+
+func (e *fakeLogsEvaluator) EvalLogs(_ context.Context, qs string, start, end time.Time, limit uint32) (*logqlmodel.Result, error) {
+	e.mtx.Lock()
+	e.queries = append(e.queries, qs)
+	e.start, e.end, e.limit = start, end, limit
+	e.inflight++
+	e.maxInflight = max(e.maxInflight, e.inflight)
+	e.mtx.Unlock()
+
+	time.Sleep(e.delay)
+
+	e.mtx.Lock()
+	e.inflight--
+	e.mtx.Unlock()
+
+	return &logqlmodel.Result{
+		Data: logqlmodel.Streams{
+			{Labels: `{job="api"}`, Entries: []logproto.Entry{
+				{Timestamp: end.Add(-3 * time.Second), Line: "error 1"},
+				{Timestamp: end.Add(-time.Second), Line: "error 3"},
+			}},
+			{Labels: `{job="api", pod="b"}`, Entries: []logproto.Entry{
+				{Timestamp: end.Add(-2 * time.Second), Line: "error 2"},
+			}},
+		},
+	}, nil
+}
+
+type fakeRuleIter []rulefmt.Rule
+
+func (r fakeRuleIter) AlertingRules() []rulefmt.Rule { return r }
+
+func TestSampleQuery(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expr     string
+		lbs      labels.Labels
+		query    string
+		lookback time.Duration
+	}{
+		{
+			name:     "aggregation",
+			expr:     `sum by (job) (count_over_time({app="foo"} |= "error" [5m])) > 2`,
+			lbs:      labels.FromStrings(labels.AlertName, "HighErrors", "job", "api", "severity", "page"),
+			query:    `{app="foo"} |= "error" | job="api"`,
+			lookback: 5 * time.Minute,
+		},
+		{
+			name:     "unwrap with offset",
+			expr:     `max_over_time({app="foo"} | logfmt | unwrap latency [1m] offset 1m) > 10`,
+			lbs:      labels.FromStrings(labels.AlertName, "HighErrors", "app", "foo", "level", "error"),
+			query:    `{app="foo"} | logfmt | app="foo" | level="error"`,
+			lookback: 2 * time.Minute,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			query, lookback, err := sampleQuery(tc.expr, tc.lbs, map[string]struct{}{labels.AlertName: {}, "severity": {}})
+			require.NoError(t, err)
+			require.Equal(t, tc.query, query)
+			require.Equal(t, tc.lookback, lookback)
+		})
+	}
+
+	_, _, err := sampleQuery(`vector(1)`, labels.EmptyLabels(), nil)
+	require.Error(t, err)
+}
+
+func TestAlertSampler(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.RulerAlertSampleLinesLimit = 2
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	evaluator := &fakeLogsEvaluator{}
+	ruleIter := fakeRuleIter{{Alert: "HighErrors", Labels: map[string]string{"severity": "page"}}}
+	sampler := newAlertSampler("fake", evaluator, overrides, ruleIter, log.NewNopLogger())
+
+	var sent []*rules.Alert
+	notify := sampler.wrap(func(_ context.Context, _ string, alerts ...*rules.Alert) {
+		sent = alerts
+	})
+
+	now := time.Now()
+	alert := &rules.Alert{
+		State:       rules.StateFiring,
+		Labels:      labels.FromStrings(labels.AlertName, "HighErrors", "job", "api", "severity", "page"),
+		Annotations: labels.FromStrings("summary", "errors", AlertSampleLinesAnnotation, "5"),
+		ActiveAt:    now.Add(-10 * time.Minute),
+		LastSentAt:  now,
+	}
+	other := &rules.Alert{
+		State:       rules.StateFiring,
+		Labels:      labels.FromStrings(labels.AlertName, "Other"),
+		Annotations: labels.FromStrings("summary", "other"),
+	}
+	expr := `sum by (job) (count_over_time({app="foo"} |= "error" [5m])) > 2`
+
+	notify(context.Background(), expr, alert, other)
+	require.Len(t, sent, 2)
+	require.Equal(t, labels.FromStrings(
+		AlertSampleLogLinesAnnotation, "error 3\nerror 2",
+		AlertSampleQueryAnnotation, `{app="foo"} |= "error" | job="api"`,
+		"summary", "errors",
+	), sent[0].Annotations)
+	require.Equal(t, labels.FromStrings("summary", "other"), sent[1].Annotations)
+	require.Equal(t, []string{`{app="foo"} |= "error" | job="api"`}, evaluator.queries)
+	require.Equal(t, uint32(2), evaluator.limit)
+	require.Equal(t, now.Add(-5*time.Minute), evaluator.start)
+
+	// Resending the same alert reuses the samples.
+	alert.Annotations = labels.FromStrings("summary", "errors", AlertSampleLinesAnnotation, "5")
+	notify(context.Background(), expr, alert)
+	require.Equal(t, "error 3\nerror 2", sent[0].Annotations.Get(AlertSampleLogLinesAnnotation))
+	require.Len(t, evaluator.queries, 1)
+
+	// A new activation of the alert is sampled again.
+	alert.Annotations = labels.FromStrings("summary", "errors", AlertSampleLinesAnnotation, "5")
+	alert.ActiveAt = now
+	notify(context.Background(), expr, alert)
+	require.Len(t, evaluator.queries, 2)
+
+	// Resolved alerts are not sampled.
+	alert.Annotations = labels.FromStrings("summary", "errors", AlertSampleLinesAnnotation, "5")
+	alert.ActiveAt = now.Add(time.Minute)
+	alert.ResolvedAt = now.Add(2 * time.Minute)
+	notify(context.Background(), expr, alert)
+	require.Len(t, evaluator.queries, 2)
+	require.Equal(t, labels.FromStrings("summary", "errors"), sent[0].Annotations)
+}
+
+func TestAlertSampler_Concurrency(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.RulerAlertSampleLinesLimit = 2
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	evaluator := &fakeLogsEvaluator{delay: 10 * time.Millisecond}
+	sampler := newAlertSampler("fake", evaluator, overrides, fakeRuleIter{}, log.NewNopLogger())
+
+	var sent []*rules.Alert
+	notify := sampler.wrap(func(_ context.Context, _ string, alerts ...*rules.Alert) {
+		sent = alerts
+	})
+
+	now := time.Now()
+	alerts := make([]*rules.Alert, 3*sampleQueryConcurrency)
+	for i := range alerts {
+		alerts[i] = &rules.Alert{
+			State:       rules.StateFiring,
+			Labels:      labels.FromStrings(labels.AlertName, "HighErrors", "job", fmt.Sprintf("api-%d", i)),
+			Annotations: labels.FromStrings(AlertSampleLinesAnnotation, "1"),
+			ActiveAt:    now,
+			LastSentAt:  now,
+		}
+	}
+
+	notify(context.Background(), `sum by (job) (count_over_time({app="foo"} [5m])) > 2`, alerts...)
+	require.Len(t, sent, len(alerts))
+	for i, alert := range sent {
+		require.Equal(t, fmt.Sprintf(`{app="foo"} | job="api-%d"`, i), alert.Annotations.Get(AlertSampleQueryAnnotation))
+		require.Equal(t, "error 3", alert.Annotations.Get(AlertSampleLogLinesAnnotation))
+	}
+	require.Len(t, evaluator.queries, len(alerts))
+	require.Greater(t, evaluator.maxInflight, 1)
+	require.LessOrEqual(t, evaluator.maxInflight, sampleQueryConcurrency)
+}
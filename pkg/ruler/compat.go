@@ -3,6 +3,7 @@ package ruler
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -55,6 +56,8 @@ type RulesLimits interface {
 
 	RulerRemoteEvaluationTimeout(userID string) time.Duration
 	RulerRemoteEvaluationMaxResponseSize(userID string) int64
+
+	RulerAlertSampleLinesLimit(userID string) int
 }
 
 // queryFunc returns a new query function using the rules.EngineQueryFunc function
@@ -152,19 +155,24 @@ func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimi
 
 		logger = log.With(logger, "user", userID)
 		queryFn := queryFunc(evaluator, registry, userID, logger)
-		memStore := NewMemStore(userID, queryFn, newMemstoreMetrics(reg), 5*time.Minute, log.With(logger, "subcomponent", "MemStore"))
+		memStore := NewMemStore(userID, queryFn, NewMemStoreMetrics(reg), 5*time.Minute, log.With(logger, "subcomponent", "MemStore"))
 
 		// GroupLoader builds a cache of the rules as they're loaded by the
 		// manager.This is used to back the memstore
 		groupLoader := NewCachingGroupLoader(GroupLoader{})
 
+		notifyFunc := ruler.SendAlerts(notifier, cfg.ExternalURL.URL.String(), cfg.DatasourceUID)
+		if logsEvaluator, ok := evaluator.(LogsEvaluator); ok {
+			notifyFunc = newAlertSampler(userID, logsEvaluator, overrides, groupLoader, logger).wrap(notifyFunc)
+		}
+
 		mgr := rules.NewManager(&rules.ManagerOptions{
 			Appendable:               registry,
 			Queryable:                memStore,
 			QueryFunc:                queryFn,
 			Context:                  user.InjectOrgID(ctx, userID),
 			ExternalURL:              cfg.ExternalURL.URL,
-			NotifyFunc:               ruler.SendAlerts(notifier, cfg.ExternalURL.URL.String(), cfg.DatasourceUID),
+			NotifyFunc:               notifyFunc,
 			Logger:                   util_log.SlogFromGoKit(logger),
 			Registerer:               reg,
 			OutageTolerance:          cfg.OutageTolerance,
@@ -291,6 +299,12 @@ func validateRuleNode(r *rulefmt.RuleNode, groupName string) error {
 		}
 	}
 
+	if v, ok := r.Annotations[AlertSampleLinesAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+			return errors.Errorf("invalid %s annotation: %q must be a positive integer", AlertSampleLinesAnnotation, v)
+		}
+	}
+
 	for _, err := range testTemplateParsing(r) {
 		return err
 	}
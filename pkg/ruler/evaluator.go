@@ -16,6 +16,12 @@ type Evaluator interface {
 	Eval(ctx context.Context, qs string, now time.Time) (*logqlmodel.Result, error)
 }
 
+// LogsEvaluator is implemented by evaluators which can run log queries, used to attach sample log lines to firing alerts.
+type LogsEvaluator interface {
+	// EvalLogs returns up to limit log lines matching the given log query between start and end, most recent first.
+	EvalLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (*logqlmodel.Result, error)
+}
+
 type EvaluationConfig struct {
 	Mode      string        `yaml:"mode,omitempty"`
 	MaxJitter time.Duration `yaml:"max_jitter"`
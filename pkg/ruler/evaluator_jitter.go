@@ -55,6 +55,15 @@ func (e *EvaluatorWithJitter) Eval(ctx context.Context, qs string, now time.Time
 	return e.inner.Eval(ctx, qs, now)
 }
 
+// EvalLogs is not delayed by jitter, as log queries are only run when alerts fire.
+func (e *EvaluatorWithJitter) EvalLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (*logqlmodel.Result, error) {
+	inner, ok := e.inner.(LogsEvaluator)
+	if !ok {
+		return nil, errLogsEvaluationUnsupported
+	}
+	return inner.EvalLogs(ctx, qs, start, end, limit)
+}
+
 func (e *EvaluatorWithJitter) calculateJitter(qs string, logger log.Logger) time.Duration {
 	var h uint32
 
@@ -63,3 +63,26 @@ func (l *LocalEvaluator) Eval(ctx context.Context, qs string, now time.Time) (*l
 	level.Info(l.insightsLogger).Log("msg", "request timings", "insight", "true", "source", "loki_ruler", "rule_name", ruleName, "rule_type", ruleType, "total", res.Statistics.Summary.ExecTime, "total_bytes", res.Statistics.Summary.TotalBytesProcessed, "query_hash", util.HashedQuery(qs))
 	return &res, nil
 }
+
+func (l *LocalEvaluator) EvalLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (*logqlmodel.Result, error) {
+	params, err := logql.NewLiteralParams(
+		qs,
+		start,
+		end,
+		0,
+		0,
+		logproto.BACKWARD,
+		limit,
+		nil,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := l.engine.Query(params).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -48,9 +48,10 @@ const (
 	keepAlive        = time.Second * 10
 	keepAliveTimeout = time.Second * 5
 
-	serviceConfig     = `{"loadBalancingPolicy": "round_robin"}`
-	queryEndpointPath = "/loki/api/v1/query"
-	mimeTypeFormPost  = "application/x-www-form-urlencoded"
+	serviceConfig          = `{"loadBalancingPolicy": "round_robin"}`
+	queryEndpointPath      = "/loki/api/v1/query"
+	queryRangeEndpointPath = "/loki/api/v1/query_range"
+	mimeTypeFormPost       = "application/x-www-form-urlencoded"
 
 	EvalModeRemote = "remote"
 )
@@ -173,6 +174,69 @@ func (r *RemoteEvaluator) Eval(ctx context.Context, qs string, now time.Time) (*
 	}
 }
 
+// EvalLogs runs a log query against the query frontend. Unlike Eval, it does not record evaluation metrics
+// as it is not a rule evaluation.
+func (r *RemoteEvaluator) EvalLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (*logqlmodel.Result, error) {
+	orgID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tenant ID from context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.overrides.RulerRemoteEvaluationTimeout(orgID))
+	defer cancel()
+
+	args := make(url.Values)
+	args.Set("query", qs)
+	args.Set("direction", "backward")
+	args.Set("start", start.Format(time.RFC3339Nano))
+	args.Set("end", end.Format(time.RFC3339Nano))
+	args.Set("limit", strconv.FormatUint(uint64(limit), 10))
+	body := []byte(args.Encode())
+
+	req := httpgrpc.HTTPRequest{
+		Method: http.MethodPost,
+		Url:    queryRangeEndpointPath,
+		Body:   body,
+		Headers: []*httpgrpc.Header{
+			{Key: textproto.CanonicalMIMEHeaderKey("User-Agent"), Values: []string{userAgent}},
+			{Key: textproto.CanonicalMIMEHeaderKey("Content-Type"), Values: []string{mimeTypeFormPost}},
+			{Key: textproto.CanonicalMIMEHeaderKey("Content-Length"), Values: []string{strconv.Itoa(len(body))}},
+			{Key: textproto.CanonicalMIMEHeaderKey(string(httpreq.QueryTagsHTTPHeader)), Values: []string{"source=ruler,rule_type=alerting_samples"}},
+			{Key: textproto.CanonicalMIMEHeaderKey(user.OrgIDHeaderName), Values: []string{orgID}},
+		},
+	}
+
+	resp, err := r.client.Handle(ctx, &req)
+	if err != nil {
+		return nil, fmt.Errorf("log query failed: %w", err)
+	}
+	if resp.Code/100 != 2 {
+		return nil, fmt.Errorf("unsuccessful/unexpected response - status code %d", resp.Code)
+	}
+
+	maxSize := r.overrides.RulerRemoteEvaluationMaxResponseSize(orgID)
+	if maxSize > 0 && int64(len(resp.Body)) >= maxSize {
+		return nil, fmt.Errorf("%d bytes exceeds response size limit of %d (defined by ruler_remote_evaluation_max_response_size)", len(resp.Body), maxSize)
+	}
+
+	var decoded loghttp.QueryResponse
+	if err := json.Unmarshal(resp.Body, &decoded); err != nil {
+		return nil, fmt.Errorf("unexpected body encoding, not valid JSON: %w", err)
+	}
+	if decoded.Status != loghttp.QueryStatusSuccess {
+		return nil, fmt.Errorf("query response error: status %q", decoded.Status)
+	}
+	streams, ok := decoded.Data.Result.(loghttp.Streams)
+	if !ok {
+		return nil, fmt.Errorf("unsupported result type: %q", decoded.Data.ResultType)
+	}
+
+	return &logqlmodel.Result{
+		Statistics: decoded.Data.Statistics,
+		Data:       logqlmodel.Streams(streams.ToProto()),
+	}, nil
+}
+
 // DialQueryFrontend creates and initializes a new httpgrpc.HTTPClient taking a QueryFrontendConfig configuration.
 func DialQueryFrontend(cfg *QueryFrontendConfig) (httpgrpc.HTTPClient, error) {
 	tlsDialOptions, err := cfg.TLS.GetGRPCDialOptions(cfg.TLSEnabled)
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/util/log"
 	"github.com/grafana/loki/v3/pkg/validation"
 )
@@ -426,3 +428,39 @@ func defaultLimitsTestConfig() validation.Limits {
 	flagext.DefaultValues(&limits)
 	return limits
 }
+
+func TestRemoteEvalLogs(t *testing.T) {
+	defaultLimits := defaultLimitsTestConfig()
+	limits, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	cli := mockClient{
+		handleFn: func(_ context.Context, req *httpgrpc.HTTPRequest, _ ...grpc.CallOption) (*httpgrpc.HTTPResponse, error) {
+			require.Equal(t, queryRangeEndpointPath, req.Url)
+			args, err := url.ParseQuery(string(req.Body))
+			require.NoError(t, err)
+			require.Equal(t, "backward", args.Get("direction"))
+			require.Equal(t, "3", args.Get("limit"))
+
+			out := fmt.Sprintf(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"foo":"bar"},"values":[["%d","error"]]}]}}`, now.UnixNano())
+
+			return &httpgrpc.HTTPResponse{
+				Code:    http.StatusOK,
+				Headers: nil,
+				Body:    []byte(out),
+			}, nil
+		},
+	}
+
+	ev, err := NewRemoteEvaluator(cli, limits, log.Logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	res, err := ev.EvalLogs(ctx, `{foo="bar"} |= "error"`, now.Add(-time.Minute), now, 3)
+	require.NoError(t, err)
+	require.IsType(t, logqlmodel.Streams{}, res.Data)
+	streams := res.Data.(logqlmodel.Streams)
+	require.Len(t, streams, 1)
+	require.Equal(t, "error", streams[0].Entries[0].Line)
+}
@@ -37,14 +37,14 @@ func ForStateMetric(base labels.Labels, alertName string) labels.Labels {
 	return b.Labels()
 }
 
-type memstoreMetrics struct {
+type MemStoreMetrics struct {
 	evaluations *prometheus.CounterVec
 	samples     prometheus.Gauge       // in memory samples
 	cacheHits   *prometheus.CounterVec // cache hits on in memory samples
 }
 
-func newMemstoreMetrics(r prometheus.Registerer) *memstoreMetrics {
-	return &memstoreMetrics{
+func NewMemStoreMetrics(r prometheus.Registerer) *MemStoreMetrics {
+	return &MemStoreMetrics{
 		evaluations: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
 			Namespace: constants.Loki,
 			Name:      "ruler_memory_for_state_evaluations_total",
@@ -68,7 +68,7 @@ type MemStore struct {
 	mtx       sync.Mutex
 	userID    string
 	queryFunc rules.QueryFunc
-	metrics   *memstoreMetrics
+	metrics   *MemStoreMetrics
 	mgr       RuleIter
 	logger    log.Logger
 	rules     map[string]*RuleCache
@@ -78,7 +78,7 @@ type MemStore struct {
 	cleanupInterval time.Duration
 }
 
-func NewMemStore(userID string, queryFunc rules.QueryFunc, metrics *memstoreMetrics, cleanupInterval time.Duration, logger log.Logger) *MemStore {
+func NewMemStore(userID string, queryFunc rules.QueryFunc, metrics *MemStoreMetrics, cleanupInterval time.Duration, logger log.Logger) *MemStore {
 	s := &MemStore{
 		userID:          userID,
 		metrics:         metrics,
@@ -311,11 +311,11 @@ func (*memStoreQuerier) Close() error { return nil }
 
 type RuleCache struct {
 	mtx     sync.Mutex
-	metrics *memstoreMetrics
+	metrics *MemStoreMetrics
 	data    map[int64]map[uint64]promql.Sample
 }
 
-func NewRuleCache(metrics *memstoreMetrics) *RuleCache {
+func NewRuleCache(metrics *MemStoreMetrics) *RuleCache {
 	return &RuleCache{
 		data:    make(map[int64]map[uint64]promql.Sample),
 		metrics: metrics,
@@ -0,0 +1,46 @@
+package syslogreceiver
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+type metrics struct {
+	receivedMessages *prometheus.CounterVec
+	parseErrors      *prometheus.CounterVec
+	droppedMessages  prometheus.Counter
+	pushFailures     prometheus.Counter
+	openConnections  prometheus.Gauge
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	return &metrics{
+		receivedMessages: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "syslog_receiver_messages_received_total",
+			Help:      "The total number of syslog messages received.",
+		}, []string{"protocol"}),
+		parseErrors: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "syslog_receiver_parse_errors_total",
+			Help:      "The total number of syslog messages that could not be parsed.",
+		}, []string{"protocol"}),
+		droppedMessages: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "syslog_receiver_messages_dropped_total",
+			Help:      "The total number of syslog messages dropped because they could not be pushed.",
+		}),
+		pushFailures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "syslog_receiver_push_failures_total",
+			Help:      "The total number of batches of syslog messages that could not be pushed.",
+		}),
+		openConnections: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Name:      "syslog_receiver_open_connections",
+			Help:      "The number of open TCP connections.",
+		}),
+	}
+}
@@ -0,0 +1,276 @@
+// Package syslogreceiver implements a syslog server that pushes the messages it receives
+// to the distributor, so that syslog clients can send logs to Loki without an agent in
+// front of it.
+package syslogreceiver
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/rfc3164"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	FormatRFC5424 = "rfc5424"
+	FormatRFC3164 = "rfc3164"
+
+	protocolTCP = "tcp"
+	protocolUDP = "udp"
+)
+
+type Config struct {
+	Enabled          bool          `yaml:"enabled"`
+	TCPListenAddress string        `yaml:"tcp_listen_address"`
+	UDPListenAddress string        `yaml:"udp_listen_address"`
+	TLS              TLSConfig     `yaml:"tls"`
+	Format           string        `yaml:"format"`
+	TenantID         string        `yaml:"tenant_id"`
+	MaxMessageLength int           `yaml:"max_message_length"`
+	IdleTimeout      time.Duration `yaml:"idle_timeout"`
+	BatchSize        int           `yaml:"batch_size"`
+	BatchWait        time.Duration `yaml:"batch_wait"`
+}
+
+type TLSConfig struct {
+	CertPath     string `yaml:"cert_path"`
+	KeyPath      string `yaml:"key_path"`
+	ClientCAPath string `yaml:"client_ca_path"`
+}
+
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "syslog-receiver.enabled", false, "Enable the syslog receiver, which accepts syslog messages on the distributors and pushes them with the same validation and rate limiting as HTTP push requests. Messages are mapped to log entries with the per-tenant syslog_config.")
+	f.StringVar(&cfg.TCPListenAddress, "syslog-receiver.tcp-listen-address", ":6514", "Address to listen on for syslog messages over TCP, with octet counting or newline framing. Empty to disable the TCP listener.")
+	f.StringVar(&cfg.UDPListenAddress, "syslog-receiver.udp-listen-address", "", "Address to listen on for syslog messages over UDP, with one message per datagram. Empty to disable the UDP listener.")
+	f.StringVar(&cfg.TLS.CertPath, "syslog-receiver.tls.cert-path", "", "Path to the server certificate. When set, the TCP listener only accepts TLS connections.")
+	f.StringVar(&cfg.TLS.KeyPath, "syslog-receiver.tls.key-path", "", "Path to the server key.")
+	f.StringVar(&cfg.TLS.ClientCAPath, "syslog-receiver.tls.client-ca-path", "", "Path to the CA certificate used to verify client certificates. When set, clients must present a valid certificate.")
+	f.StringVar(&cfg.Format, "syslog-receiver.format", FormatRFC5424, "Format of the syslog messages. Supported values: rfc5424, rfc3164.")
+	f.StringVar(&cfg.TenantID, "syslog-receiver.tenant-id", "fake", "Tenant the received messages are pushed for. Use 'fake' if authentication is disabled.")
+	f.IntVar(&cfg.MaxMessageLength, "syslog-receiver.max-message-length", 8192, "Maximum length of a syslog message, in bytes.")
+	f.DurationVar(&cfg.IdleTimeout, "syslog-receiver.idle-timeout", 120*time.Second, "Timeout after which idle TCP connections are closed.")
+	f.IntVar(&cfg.BatchSize, "syslog-receiver.batch-size", 1000, "Maximum number of messages pushed at once.")
+	f.DurationVar(&cfg.BatchWait, "syslog-receiver.batch-wait", time.Second, "Maximum time a message waits before it is pushed.")
+}
+
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.TCPListenAddress == "" && cfg.UDPListenAddress == "" {
+		return errors.New("at least one of the TCP and UDP listen addresses must be set")
+	}
+	if cfg.Format != FormatRFC5424 && cfg.Format != FormatRFC3164 {
+		return fmt.Errorf("unsupported format %q: supported values are %s and %s", cfg.Format, FormatRFC5424, FormatRFC3164)
+	}
+	if cfg.TenantID == "" {
+		return errors.New("tenant ID must be set")
+	}
+	if (cfg.TLS.CertPath == "") != (cfg.TLS.KeyPath == "") {
+		return errors.New("both the TLS certificate and key paths must be set")
+	}
+	if cfg.TLS.ClientCAPath != "" && cfg.TLS.CertPath == "" {
+		return errors.New("the TLS client CA requires the TLS certificate and key paths to be set")
+	}
+	if cfg.MaxMessageLength <= 0 {
+		return errors.New("max message length must be greater than 0")
+	}
+	if cfg.BatchSize <= 0 {
+		return errors.New("batch size must be greater than 0")
+	}
+	return nil
+}
+
+// Pusher pushes the received syslog messages. It is implemented by the distributor.
+type Pusher interface {
+	PushSyslog(ctx context.Context, msgs []syslog.Message) error
+}
+
+// Receiver listens for syslog messages over TCP and UDP, and pushes them in batches.
+type Receiver struct {
+	services.Service
+
+	cfg     Config
+	pusher  Pusher
+	logger  log.Logger
+	metrics *metrics
+
+	tcpListener net.Listener
+	udpConn     net.PacketConn
+	messages    chan syslog.Message
+
+	// ctx is canceled when the receiver stops, to close open connections.
+	ctx         context.Context
+	cancel      context.CancelFunc
+	connections sync.WaitGroup
+}
+
+func New(cfg Config, pusher Pusher, logger log.Logger, reg prometheus.Registerer) *Receiver {
+	r := &Receiver{
+		cfg:      cfg,
+		pusher:   pusher,
+		logger:   log.With(logger, "component", "syslog-receiver"),
+		metrics:  newMetrics(reg),
+		messages: make(chan syslog.Message, cfg.BatchSize),
+	}
+	r.Service = services.NewBasicService(r.starting, r.running, r.stopping)
+	return r
+}
+
+// TCPAddr returns the address of the TCP listener, or nil if it is disabled.
+func (r *Receiver) TCPAddr() net.Addr {
+	if r.tcpListener == nil {
+		return nil
+	}
+	return r.tcpListener.Addr()
+}
+
+// UDPAddr returns the address of the UDP listener, or nil if it is disabled.
+func (r *Receiver) UDPAddr() net.Addr {
+	if r.udpConn == nil {
+		return nil
+	}
+	return r.udpConn.LocalAddr()
+}
+
+func (r *Receiver) starting(_ context.Context) error {
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	if r.cfg.TCPListenAddress != "" {
+		l, err := listenTCP(r.cfg.TCPListenAddress, r.cfg.TLS)
+		if err != nil {
+			return err
+		}
+		r.tcpListener = l
+		level.Info(r.logger).Log("msg", "listening for syslog messages", "protocol", protocolTCP, "address", l.Addr(), "tls", r.cfg.TLS.CertPath != "")
+
+		r.connections.Add(1)
+		go r.acceptConnections()
+	}
+
+	if r.cfg.UDPListenAddress != "" {
+		conn, err := net.ListenPacket(protocolUDP, r.cfg.UDPListenAddress)
+		if err != nil {
+			r.closeListeners()
+			return fmt.Errorf("failed to listen on %s: %w", r.cfg.UDPListenAddress, err)
+		}
+		r.udpConn = conn
+		level.Info(r.logger).Log("msg", "listening for syslog messages", "protocol", protocolUDP, "address", conn.LocalAddr())
+
+		r.connections.Add(1)
+		go r.readPackets()
+	}
+
+	return nil
+}
+
+// running pushes the received messages in batches, until the receiver stops.
+func (r *Receiver) running(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.BatchWait)
+	defer ticker.Stop()
+
+	batch := make([]syslog.Message, 0, r.cfg.BatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			r.closeListeners()
+			// Push the messages received until all connections are closed.
+			go func() {
+				r.connections.Wait()
+				close(r.messages)
+			}()
+			for msg := range r.messages {
+				batch = append(batch, msg)
+				if len(batch) >= r.cfg.BatchSize {
+					batch = r.push(batch)
+				}
+			}
+			r.push(batch)
+			return nil
+		case msg := <-r.messages:
+			batch = append(batch, msg)
+			if len(batch) >= r.cfg.BatchSize {
+				batch = r.push(batch)
+			}
+		case <-ticker.C:
+			batch = r.push(batch)
+		}
+	}
+}
+
+func (r *Receiver) stopping(_ error) error {
+	r.closeListeners()
+	r.connections.Wait()
+	return nil
+}
+
+func (r *Receiver) closeListeners() {
+	r.cancel()
+	if r.tcpListener != nil {
+		_ = r.tcpListener.Close()
+	}
+	if r.udpConn != nil {
+		_ = r.udpConn.Close()
+	}
+}
+
+// push pushes a batch of messages and returns the emptied batch. Failed batches are
+// dropped, since syslog clients cannot be asked to retry them.
+func (r *Receiver) push(batch []syslog.Message) []syslog.Message {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx := user.InjectOrgID(context.Background(), r.cfg.TenantID)
+	if err := r.pusher.PushSyslog(ctx, batch); err != nil {
+		level.Warn(r.logger).Log("msg", "failed to push syslog messages", "messages", len(batch), "err", err)
+		r.metrics.pushFailures.Inc()
+		r.metrics.droppedMessages.Add(float64(len(batch)))
+	}
+	return batch[:0]
+}
+
+func (r *Receiver) handleResult(protocol string) func(res *syslog.Result) {
+	return func(res *syslog.Result) {
+		if res.Error != nil {
+			r.metrics.parseErrors.WithLabelValues(protocol).Inc()
+			level.Debug(r.logger).Log("msg", "failed to parse syslog message", "protocol", protocol, "err", res.Error)
+			return
+		}
+		if msg, ok := res.Message.(*rfc3164.SyslogMessage); ok {
+			setRFC3164Year(msg, time.Now())
+		}
+		r.metrics.receivedMessages.WithLabelValues(protocol).Inc()
+		r.messages <- res.Message
+	}
+}
+
+// rfc3164FutureTolerance is how far in the future the timestamp of an RFC3164 message can
+// be before it is considered to be from the previous year. It accounts for the time zones
+// of the senders, as RFC3164 timestamps are in their local time.
+const rfc3164FutureTolerance = 24 * time.Hour
+
+// setRFC3164Year sets the year of the timestamp of an RFC3164 message, which does not have
+// one, to the current year, or to the previous year for dates in the future, such as the
+// messages of December 31st received on January 1st.
+func setRFC3164Year(msg *rfc3164.SyslogMessage, now time.Time) {
+	if msg.Timestamp == nil || msg.Timestamp.Year() != 0 {
+		return
+	}
+	ts := *msg.Timestamp
+	ts = time.Date(now.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), ts.Location())
+	if ts.After(now.Add(rfc3164FutureTolerance)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	msg.Timestamp = &ts
+}
@@ -0,0 +1,215 @@
+package syslogreceiver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/tenant"
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/rfc3164"
+	"github.com/leodido/go-syslog/v4/rfc5424"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePusher struct {
+	mtx        sync.Mutex
+	tenants    []string
+	msgs       []string
+	timestamps []time.Time
+}
+
+func (p *fakePusher) PushSyslog(ctx context.Context, msgs []syslog.Message) error {
+	tenantID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.tenants = append(p.tenants, tenantID)
+	for _, msg := range msgs {
+		var base *syslog.Base
+		switch m := msg.(type) {
+		case *rfc5424.SyslogMessage:
+			base = &m.Base
+		case *rfc3164.SyslogMessage:
+			base = &m.Base
+		}
+		p.msgs = append(p.msgs, *base.Message)
+		p.timestamps = append(p.timestamps, *base.Timestamp)
+	}
+	return nil
+}
+
+func (p *fakePusher) messages() []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]string(nil), p.msgs...)
+}
+
+func rfc5424Message(msg string) string {
+	return fmt.Sprintf("<165>1 2024-01-01T00:00:00Z web-1 app 123 ID47 - %s", msg)
+}
+
+func TestReceiver(t *testing.T) {
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+	cfg.TCPListenAddress = "127.0.0.1:0"
+	cfg.UDPListenAddress = "127.0.0.1:0"
+	cfg.TenantID = "tenant-a"
+	cfg.BatchWait = 10 * time.Millisecond
+	require.NoError(t, cfg.Validate())
+
+	pusher := &fakePusher{}
+	r := New(cfg, pusher, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), r))
+
+	// Octet counting framing over TCP.
+	tcpConn, err := net.Dial("tcp", r.TCPAddr().String())
+	require.NoError(t, err)
+	for _, msg := range []string{"foo", "bar"} {
+		line := rfc5424Message(msg)
+		_, err = fmt.Fprintf(tcpConn, "%d %s", len(line), line)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tcpConn.Close())
+
+	require.Eventually(t, func() bool {
+		return len(pusher.messages()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// One message per datagram over UDP.
+	udpConn, err := net.Dial("udp", r.UDPAddr().String())
+	require.NoError(t, err)
+	_, err = udpConn.Write([]byte(rfc5424Message("baz")))
+	require.NoError(t, err)
+	require.NoError(t, udpConn.Close())
+
+	require.Eventually(t, func() bool {
+		return len(pusher.messages()) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), r))
+	require.Equal(t, []string{"foo", "bar", "baz"}, pusher.messages())
+	for _, tenantID := range pusher.tenants {
+		require.Equal(t, "tenant-a", tenantID)
+	}
+}
+
+func TestReceiver_RFC3164(t *testing.T) {
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+	cfg.Format = FormatRFC3164
+	cfg.TCPListenAddress = "127.0.0.1:0"
+	cfg.BatchWait = 10 * time.Millisecond
+	require.NoError(t, cfg.Validate())
+
+	pusher := &fakePusher{}
+	r := New(cfg, pusher, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), r))
+
+	// RFC3164 timestamps do not have a year: they get the current year, or the previous
+	// one for dates in the future.
+	now := time.Now().UTC().Truncate(time.Second)
+	past, future := now.Add(-time.Hour), now.Add(48*time.Hour)
+
+	conn, err := net.Dial("tcp", r.TCPAddr().String())
+	require.NoError(t, err)
+	for _, ts := range []time.Time{past, future} {
+		_, err = fmt.Fprintf(conn, "<34>%s db-1 su: foo\n", ts.Format(time.Stamp))
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		return len(pusher.messages()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), r))
+	require.Equal(t, []string{"foo", "foo"}, pusher.messages())
+	require.Equal(t, past, pusher.timestamps[0])
+	require.Equal(t, future.AddDate(-1, 0, 0), pusher.timestamps[1])
+}
+
+func TestReceiver_PushesPendingMessagesOnStop(t *testing.T) {
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+	cfg.TCPListenAddress = "127.0.0.1:0"
+	cfg.BatchWait = time.Hour
+
+	pusher := &fakePusher{}
+	r := New(cfg, pusher, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), r))
+
+	conn, err := net.Dial("tcp", r.TCPAddr().String())
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(conn, "%s\n", rfc5424Message("foo"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(r.metrics.receivedMessages.WithLabelValues(protocolTCP)) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Empty(t, pusher.messages())
+
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), r))
+	require.Equal(t, []string{"foo"}, pusher.messages())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:   "disabled",
+			modify: func(cfg *Config) { cfg.Enabled = false; cfg.Format = "foo" },
+		},
+		{
+			name:   "valid",
+			modify: func(_ *Config) {},
+		},
+		{
+			name:        "no listener",
+			modify:      func(cfg *Config) { cfg.TCPListenAddress = "" },
+			expectedErr: "at least one of the TCP and UDP listen addresses must be set",
+		},
+		{
+			name:        "unsupported format",
+			modify:      func(cfg *Config) { cfg.Format = "foo" },
+			expectedErr: `unsupported format "foo"`,
+		},
+		{
+			name:        "TLS key without certificate",
+			modify:      func(cfg *Config) { cfg.TLS.KeyPath = "key.pem" },
+			expectedErr: "both the TLS certificate and key paths must be set",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{}
+			flagext.DefaultValues(&cfg)
+			cfg.Enabled = true
+			tc.modify(&cfg)
+
+			err := cfg.Validate()
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package syslogreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/nontransparent"
+	"github.com/leodido/go-syslog/v4/octetcounting"
+)
+
+func listenTCP(address string, cfg TLSConfig) (net.Listener, error) {
+	var tlsConfig *tls.Config
+	if cfg.CertPath != "" {
+		var err error
+		if tlsConfig, err = newTLSConfig(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen(protocolTCP, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+	return l, nil
+}
+
+func newTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TLS certificate and key: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	if cfg.ClientCAPath != "" {
+		caCert, err := os.ReadFile(cfg.ClientCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("failed to parse the TLS client CA")
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+func (r *Receiver) acceptConnections() {
+	defer r.connections.Done()
+
+	for {
+		conn, err := r.tcpListener.Accept()
+		if err != nil {
+			if r.ctx.Err() != nil {
+				return
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			level.Error(r.logger).Log("msg", "failed to accept syslog connection", "err", err)
+			return
+		}
+
+		r.metrics.openConnections.Inc()
+		r.connections.Add(1)
+		go r.handleConnection(conn)
+	}
+}
+
+func (r *Receiver) handleConnection(conn net.Conn) {
+	defer r.connections.Done()
+	defer r.metrics.openConnections.Dec()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-r.ctx.Done():
+		case <-done:
+		}
+		_ = conn.Close()
+	}()
+
+	c := &idleTimeoutConn{Conn: conn, idleTimeout: r.cfg.IdleTimeout}
+	if err := r.parseStream(c, r.handleResult(protocolTCP)); err != nil && !errors.Is(err, io.EOF) && r.ctx.Err() == nil {
+		level.Warn(r.logger).Log("msg", "failed to read syslog stream", "remote", conn.RemoteAddr(), "err", err)
+	}
+}
+
+func (r *Receiver) readPackets() {
+	defer r.connections.Done()
+
+	buf := make([]byte, r.cfg.MaxMessageLength)
+	for {
+		n, _, err := r.udpConn.ReadFrom(buf)
+		if err != nil {
+			if r.ctx.Err() != nil {
+				return
+			}
+			level.Warn(r.logger).Log("msg", "failed to read syslog packet", "err", err)
+			continue
+		}
+		if n == 0 {
+			continue
+		}
+		if err := r.parseStream(bytes.NewReader(buf[:n]), r.handleResult(protocolUDP)); err != nil {
+			r.metrics.parseErrors.WithLabelValues(protocolUDP).Inc()
+		}
+	}
+}
+
+// parseStream parses the messages of a stream, detecting octet counting framing from
+// its first byte. It returns once the stream is consumed.
+func (r *Receiver) parseStream(rd io.Reader, listener syslog.ParserListener) error {
+	buf := bufio.NewReaderSize(rd, 1<<10)
+	b, err := buf.ReadByte()
+	if err != nil {
+		return err
+	}
+	_ = buf.UnreadByte()
+
+	opts := []syslog.ParserOption{
+		syslog.WithListener(listener),
+		syslog.WithMaxMessageLength(r.cfg.MaxMessageLength),
+		syslog.WithBestEffort(),
+	}
+	rfc3164 := r.cfg.Format == FormatRFC3164
+
+	var parser syslog.Parser
+	switch {
+	case b == '<' && rfc3164:
+		parser = nontransparent.NewParserRFC3164(opts...)
+	case b == '<':
+		parser = nontransparent.NewParser(opts...)
+	case b >= '0' && b <= '9' && rfc3164:
+		parser = octetcounting.NewParserRFC3164(opts...)
+	case b >= '0' && b <= '9':
+		parser = octetcounting.NewParser(opts...)
+	default:
+		return fmt.Errorf("invalid or unsupported framing, first byte: %q", b)
+	}
+	parser.Parse(buf)
+	return nil
+}
+
+// idleTimeoutConn closes connections that are idle for longer than the timeout.
+type idleTimeoutConn struct {
+	net.Conn
+	idleTimeout time.Duration
+}
+
+func (c *idleTimeoutConn) Read(b []byte) (int, error) {
+	if c.idleTimeout > 0 {
+		_ = c.Conn.SetReadDeadline(time.Now().Add(c.idleTimeout))
+	}
+	return c.Conn.Read(b)
+}
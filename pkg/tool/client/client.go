@@ -217,5 +217,6 @@ func buildRequest(ctx context.Context, p, m string, endpoint url.URL, payload []
 		endpoint.RawPath = joinPath(endpoint.EscapedPath(), pURL.EscapedPath())
 	}
 	endpoint.Path = joinPath(endpoint.Path, pURL.Path)
+	endpoint.RawQuery = pURL.RawQuery
 	return http.NewRequestWithContext(ctx, m, endpoint.String(), bytes.NewBuffer(payload))
 }
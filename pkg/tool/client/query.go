@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+const queryRangePath = "/loki/api/v1/query_range"
+
+// QueryRange executes a LogQL metric query over a time range and returns the resulting matrix.
+func (r *LokiClient) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (loghttp.Matrix, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	res, err := r.doRequest(ctx, queryRangePath+"?"+params.Encode(), "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp loghttp.QueryResponse
+	if err := resp.UnmarshalJSON(body); err != nil {
+		return nil, err
+	}
+	if resp.Status != loghttp.QueryStatusSuccess {
+		return nil, fmt.Errorf("query %q returned status %q", query, resp.Status)
+	}
+
+	matrix, ok := resp.Data.Result.(loghttp.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("query %q returned %s instead of a matrix", query, resp.Data.ResultType)
+	}
+	return matrix, nil
+}
@@ -6,9 +6,12 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
+	gokitlog "github.com/go-kit/log"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/model/rulefmt"
@@ -19,6 +22,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/tool/printer"
 	"github.com/grafana/loki/v3/pkg/tool/rules"
 	"github.com/grafana/loki/v3/pkg/tool/rules/rwrulefmt"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
 )
 
 const (
@@ -79,6 +83,19 @@ type RuleCommand struct {
 	// List Rules Config
 	Format string
 
+	// Test Rules Config
+	TestFilesList []string
+
+	// Backfill Rules Config
+	BackfillStart              string
+	BackfillEnd                string
+	BackfillEvalInterval       time.Duration
+	BackfillBlockDuration      time.Duration
+	BackfillOutputDir          string
+	BackfillRemoteWriteURL     string
+	BackfillRemoteWriteHeaders map[string]string
+	BackfillRemoteWriteTimeout time.Duration
+
 	DisableColor bool
 
 	// Diff Rules Config
@@ -123,9 +140,15 @@ func (r *RuleCommand) Register(app *kingpin.Application) {
 	checkCmd := rulesCmd.
 		Command("check", "runs various best practice checks against rules.").
 		Action(r.checkRecordingRuleNames)
+	testCmd := rulesCmd.
+		Command("test", "unit tests a set of rules against synthetic log streams.").
+		Action(r.testRules)
+	backfillCmd := rulesCmd.
+		Command("backfill", "evaluates the recording rules of a set of rule files over a past time range and writes the resulting samples to TSDB blocks or remote write.").
+		Action(r.backfillRules)
 
 	// Require Loki cluster address and tentant ID on all these commands
-	for _, c := range []*kingpin.CmdClause{listCmd, printRulesCmd, getRuleGroupCmd, deleteRuleGroupCmd, loadRulesCmd, diffRulesCmd, syncRulesCmd} {
+	for _, c := range []*kingpin.CmdClause{listCmd, printRulesCmd, getRuleGroupCmd, deleteRuleGroupCmd, loadRulesCmd, diffRulesCmd, syncRulesCmd, backfillCmd} {
 		c.Flag("address", "Address of the loki cluster, alternatively set LOKI_ADDRESS.").
 			Envar("LOKI_ADDRESS").
 			Required().
@@ -227,6 +250,25 @@ func (r *RuleCommand) Register(app *kingpin.Application) {
 	).StringVar(&r.RuleFilesPath)
 	checkCmd.Flag("strict", "fails rules checks that do not match best practices exactly").BoolVar(&r.Strict)
 
+	// Test Command
+	testCmd.Arg("test-files", "The unit test files to run.").Required().ExistingFilesVar(&r.TestFilesList)
+
+	// Backfill Command
+	backfillCmd.Arg("rule-files", "The rule files to backfill.").ExistingFilesVar(&r.RuleFilesList)
+	backfillCmd.Flag("rule-files", "The rule files to backfill. Flag can be reused to load multiple files.").StringVar(&r.RuleFiles)
+	backfillCmd.Flag(
+		"rule-dirs",
+		"Comma separated list of paths to directories containing rules yaml files. Each file in a directory with a .yml or .yaml suffix will be parsed.",
+	).StringVar(&r.RuleFilesPath)
+	backfillCmd.Flag("start", "Start of the time range to backfill, in RFC3339 format.").Required().StringVar(&r.BackfillStart)
+	backfillCmd.Flag("end", "End of the time range to backfill, in RFC3339 format.").Required().StringVar(&r.BackfillEnd)
+	backfillCmd.Flag("eval-interval", "Evaluation interval of rule groups which do not set an interval.").Default("1m").DurationVar(&r.BackfillEvalInterval)
+	backfillCmd.Flag("block-duration", "Time range covered by each TSDB block or batch of remote write requests.").Default("2h").DurationVar(&r.BackfillBlockDuration)
+	backfillCmd.Flag("output-dir", "Directory to write TSDB blocks to. Cannot be used together with --remote-write-url.").StringVar(&r.BackfillOutputDir)
+	backfillCmd.Flag("remote-write-url", "Prometheus remote write endpoint to push samples to. The endpoint must accept out of order samples. Cannot be used together with --output-dir.").StringVar(&r.BackfillRemoteWriteURL)
+	backfillCmd.Flag("remote-write-header", "Header to add to remote write requests, as name=value. Flag can be reused to add multiple headers.").StringMapVar(&r.BackfillRemoteWriteHeaders)
+	backfillCmd.Flag("remote-write-timeout", "Timeout of remote write requests.").Default("30s").DurationVar(&r.BackfillRemoteWriteTimeout)
+
 	// List Command
 	listCmd.Flag("format", "Backend type to interact with: <json|yaml|table>").Default("table").EnumVar(&r.Format, formats...)
 	listCmd.Flag("disable-color", "disable colored output").BoolVar(&r.DisableColor)
@@ -688,6 +730,63 @@ func (r *RuleCommand) lint(_ *kingpin.ParseContext) error {
 	return nil
 }
 
+func (r *RuleCommand) testRules(_ *kingpin.ParseContext) error {
+	return rules.RunUnitTests(os.Stdout, r.TestFilesList...)
+}
+
+func (r *RuleCommand) backfillRules(_ *kingpin.ParseContext) error {
+	start, err := time.Parse(time.RFC3339, r.BackfillStart)
+	if err != nil {
+		return errors.Wrap(err, "invalid --start")
+	}
+	end, err := time.Parse(time.RFC3339, r.BackfillEnd)
+	if err != nil {
+		return errors.Wrap(err, "invalid --end")
+	}
+
+	var w rules.BackfillWriter
+	switch {
+	case r.BackfillOutputDir != "" && r.BackfillRemoteWriteURL != "":
+		return errors.New("--output-dir and --remote-write-url cannot be set at the same time")
+	case r.BackfillOutputDir != "":
+		if err := os.MkdirAll(r.BackfillOutputDir, 0o755); err != nil {
+			return err
+		}
+		w = rules.NewTSDBBackfillWriter(r.BackfillOutputDir, r.BackfillBlockDuration, util_log.SlogFromGoKit(gokitlog.NewNopLogger()))
+	case r.BackfillRemoteWriteURL != "":
+		w, err = rules.NewRemoteWriteBackfillWriter(r.BackfillRemoteWriteURL, r.BackfillRemoteWriteHeaders, r.BackfillRemoteWriteTimeout)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("one of --output-dir or --remote-write-url must be set")
+	}
+
+	if err := r.setupFiles(); err != nil {
+		return errors.Wrap(err, "backfill operation unsuccessful, unable to load rules files")
+	}
+	namespaces, err := rules.ParseFiles(r.RuleFilesList)
+	if err != nil {
+		return errors.Wrap(err, "backfill operation unsuccessful, unable to parse rules files")
+	}
+	nss := make([]rules.RuleNamespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		nss = append(nss, ns)
+	}
+	sort.Slice(nss, func(i, j int) bool { return nss[i].Namespace < nss[j].Namespace })
+
+	cfg := rules.BackfillConfig{
+		Start:           start,
+		End:             end,
+		DefaultInterval: r.BackfillEvalInterval,
+		BlockDuration:   r.BackfillBlockDuration,
+	}
+	if err := rules.Backfill(context.Background(), cfg, nss, r.cli.QueryRange, w); err != nil {
+		return errors.Wrap(err, "backfill operation unsuccessful")
+	}
+	return nil
+}
+
 func (r *RuleCommand) checkRecordingRuleNames(_ *kingpin.ParseContext) error {
 	err := r.setupFiles()
 	if err != nil {
@@ -0,0 +1,339 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/dskit/backoff"
+	config_util "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage/remote"
+	"github.com/prometheus/prometheus/tsdb"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+const (
+	// maxPointsPerQuery mirrors the maximum number of points per series the query frontend accepts.
+	maxPointsPerQuery = 11000
+
+	defaultRemoteWriteBatchSize = 5000
+)
+
+// QueryRangeFunc executes a LogQL metric query over a time range.
+type QueryRangeFunc func(ctx context.Context, query string, start, end time.Time, step time.Duration) (loghttp.Matrix, error)
+
+// BackfillWriter persists the samples produced by a backfill.
+type BackfillWriter interface {
+	// Write is called once per block with the samples recorded within it.
+	// Samples of each series are sorted by timestamp.
+	Write(ctx context.Context, series []promql.Series) error
+}
+
+// BackfillConfig configures the time range a backfill covers.
+type BackfillConfig struct {
+	Start time.Time
+	End   time.Time
+
+	// DefaultInterval is used for rule groups which do not set an interval.
+	DefaultInterval time.Duration
+	// BlockDuration is the time range covered by each call to BackfillWriter.Write.
+	BlockDuration time.Duration
+}
+
+func (cfg BackfillConfig) Validate() error {
+	if !cfg.Start.Before(cfg.End) {
+		return fmt.Errorf("backfill start %s must be before end %s", cfg.Start.Format(time.RFC3339), cfg.End.Format(time.RFC3339))
+	}
+	if cfg.DefaultInterval <= 0 {
+		return fmt.Errorf("backfill evaluation interval must be positive")
+	}
+	if cfg.BlockDuration <= 0 || cfg.BlockDuration%time.Millisecond != 0 {
+		return fmt.Errorf("backfill block duration must be a positive number of milliseconds")
+	}
+	return nil
+}
+
+// Backfill evaluates the recording rules of the given namespaces between
+// cfg.Start and cfg.End using range queries, as the ruler would have evaluated
+// them at each interval of their group, and hands the recorded samples to w
+// block by block. Alerting rules are ignored.
+func Backfill(ctx context.Context, cfg BackfillConfig, nss []RuleNamespace, query QueryRangeFunc, w BackfillWriter) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	for blockStart := cfg.Start.Truncate(cfg.BlockDuration); blockStart.Before(cfg.End); blockStart = blockStart.Add(cfg.BlockDuration) {
+		start := maxTime(blockStart, cfg.Start)
+		end := minTime(blockStart.Add(cfg.BlockDuration), cfg.End)
+
+		series := map[uint64]*promql.Series{}
+		for _, ns := range nss {
+			for _, g := range ns.Groups {
+				interval := time.Duration(g.Interval)
+				if interval == 0 {
+					interval = cfg.DefaultInterval
+				}
+
+				for _, r := range g.Rules {
+					if r.Record.Value == "" {
+						continue
+					}
+
+					log.WithFields(log.Fields{
+						"namespace": ns.Namespace,
+						"group":     g.Name,
+						"record":    r.Record.Value,
+						"start":     start.Format(time.RFC3339),
+						"end":       end.Format(time.RFC3339),
+					}).Debugln("backfilling recording rule")
+
+					ruleLabels := labels.FromMap(r.Labels)
+					err := backfillRule(ctx, query, r.Expr.Value, start, end, interval, func(metric model.Metric, t int64, v float64) {
+						lbs := recordedLabels(r.Record.Value, metric, ruleLabels)
+						h := lbs.Hash()
+						s, ok := series[h]
+						if !ok {
+							s = &promql.Series{Metric: lbs}
+							series[h] = s
+						}
+						s.Floats = append(s.Floats, promql.FPoint{T: t, F: v})
+					})
+					if err != nil {
+						return fmt.Errorf("backfilling rule %s of group %s in namespace %s: %w", r.Record.Value, g.Name, ns.Namespace, err)
+					}
+				}
+			}
+		}
+
+		if len(series) == 0 {
+			continue
+		}
+
+		result := make([]promql.Series, 0, len(series))
+		for _, s := range series {
+			sort.Slice(s.Floats, func(i, j int) bool { return s.Floats[i].T < s.Floats[j].T })
+			result = append(result, *s)
+		}
+		sort.Slice(result, func(i, j int) bool { return labels.Compare(result[i].Metric, result[j].Metric) < 0 })
+
+		if err := w.Write(ctx, result); err != nil {
+			return fmt.Errorf("writing block starting at %s: %w", blockStart.Format(time.RFC3339), err)
+		}
+	}
+	return nil
+}
+
+// backfillRule evaluates expr at every multiple of interval within [start, end).
+func backfillRule(ctx context.Context, query QueryRangeFunc, expr string, start, end time.Time, interval time.Duration, fn func(model.Metric, int64, float64)) error {
+	first := start.Truncate(interval)
+	if first.Before(start) {
+		first = first.Add(interval)
+	}
+
+	for queryStart := first; queryStart.Before(end); queryStart = queryStart.Add(maxPointsPerQuery * interval) {
+		queryEnd := minTime(queryStart.Add((maxPointsPerQuery-1)*interval), end.Add(-time.Nanosecond))
+
+		matrix, err := query(ctx, expr, queryStart, queryEnd, interval)
+		if err != nil {
+			return err
+		}
+		for _, s := range matrix {
+			for _, p := range s.Values {
+				t := p.Timestamp.Time()
+				if t.Before(start) || !t.Before(end) {
+					continue
+				}
+				fn(s.Metric, int64(p.Timestamp), float64(p.Value))
+			}
+		}
+	}
+	return nil
+}
+
+// recordedLabels builds the labels of a recorded series the same way the
+// ruler does: the rule labels override the result labels and the metric name
+// is set to the record name.
+func recordedLabels(record string, metric model.Metric, ruleLabels labels.Labels) labels.Labels {
+	b := labels.NewScratchBuilder(len(metric))
+	for name, value := range metric {
+		b.Add(string(name), string(value))
+	}
+	b.Sort()
+
+	lb := labels.NewBuilder(b.Labels())
+	ruleLabels.Range(func(l labels.Label) {
+		lb.Set(l.Name, l.Value)
+	})
+	lb.Set(labels.MetricName, record)
+	return lb.Labels()
+}
+
+// TSDBBackfillWriter writes each block of backfilled samples as a Prometheus
+// TSDB block to a directory, from where it can be uploaded.
+type TSDBBackfillWriter struct {
+	dir           string
+	blockDuration time.Duration
+	logger        *slog.Logger
+}
+
+func NewTSDBBackfillWriter(dir string, blockDuration time.Duration, logger *slog.Logger) *TSDBBackfillWriter {
+	return &TSDBBackfillWriter{
+		dir:           dir,
+		blockDuration: blockDuration,
+		logger:        logger,
+	}
+}
+
+func (w *TSDBBackfillWriter) Write(ctx context.Context, series []promql.Series) (err error) {
+	bw, err := tsdb.NewBlockWriter(w.logger, w.dir, w.blockDuration.Milliseconds())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := bw.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	app := bw.Appender(ctx)
+	for _, s := range series {
+		for _, p := range s.Floats {
+			if _, err := app.Append(0, s.Metric, p.T, p.F); err != nil {
+				_ = app.Rollback()
+				return fmt.Errorf("appending sample of series %s: %w", s.Metric, err)
+			}
+		}
+	}
+	if err := app.Commit(); err != nil {
+		return err
+	}
+
+	id, err := bw.Flush(ctx)
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"block":  id.String(),
+		"series": len(series),
+	}).Infoln("created block")
+	return nil
+}
+
+// RemoteWriteBackfillWriter pushes backfilled samples with their original
+// timestamps to a Prometheus remote write endpoint. The endpoint must accept
+// out of order samples if it already ingested newer samples of the same series.
+type RemoteWriteBackfillWriter struct {
+	client    remote.WriteClient
+	batchSize int
+	backoff   backoff.Config
+}
+
+func NewRemoteWriteBackfillWriter(endpoint string, headers map[string]string, timeout time.Duration) (*RemoteWriteBackfillWriter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote write URL: %w", err)
+	}
+
+	client, err := remote.NewWriteClient("backfill", &remote.ClientConfig{
+		URL:              &config_util.URL{URL: u},
+		Timeout:          model.Duration(timeout),
+		HTTPClientConfig: config_util.DefaultHTTPClientConfig,
+		Headers:          headers,
+		WriteProtoMsg:    config.RemoteWriteProtoMsgV1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RemoteWriteBackfillWriter{
+		client:    client,
+		batchSize: defaultRemoteWriteBatchSize,
+		backoff: backoff.Config{
+			MinBackoff: 100 * time.Millisecond,
+			MaxBackoff: 10 * time.Second,
+			MaxRetries: 10,
+		},
+	}, nil
+}
+
+func (w *RemoteWriteBackfillWriter) Write(ctx context.Context, series []promql.Series) error {
+	var (
+		req     prompb.WriteRequest
+		samples int
+	)
+	for _, s := range series {
+		ts := prompb.TimeSeries{
+			Labels: make([]prompb.Label, 0, s.Metric.Len()),
+		}
+		s.Metric.Range(func(l labels.Label) {
+			ts.Labels = append(ts.Labels, prompb.Label{Name: l.Name, Value: l.Value})
+		})
+		for _, p := range s.Floats {
+			ts.Samples = append(ts.Samples, prompb.Sample{Timestamp: p.T, Value: p.F})
+		}
+		req.Timeseries = append(req.Timeseries, ts)
+
+		samples += len(s.Floats)
+		if samples >= w.batchSize {
+			if err := w.send(ctx, &req); err != nil {
+				return err
+			}
+			req.Timeseries = req.Timeseries[:0]
+			samples = 0
+		}
+	}
+	if len(req.Timeseries) == 0 {
+		return nil
+	}
+	return w.send(ctx, &req)
+}
+
+func (w *RemoteWriteBackfillWriter) send(ctx context.Context, req *prompb.WriteRequest) error {
+	data, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+	compressed := snappy.Encode(nil, data)
+
+	b := backoff.New(ctx, w.backoff)
+	for b.Ongoing() {
+		_, err = w.client.Store(ctx, compressed, b.NumRetries())
+		if err == nil {
+			return nil
+		}
+
+		var recoverable remote.RecoverableError
+		if !errors.As(err, &recoverable) {
+			return err
+		}
+		log.WithError(err).Warnln("remote write failed, retrying")
+		b.Wait()
+	}
+	return fmt.Errorf("remote write failed after %d retries: %w", b.NumRetries(), err)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+type capturingBackfillWriter struct {
+	blocks [][]promql.Series
+}
+
+func (w *capturingBackfillWriter) Write(_ context.Context, series []promql.Series) error {
+	w.blocks = append(w.blocks, series)
+	return nil
+}
+
+// stepQuery returns one series per job, whose value is the evaluation timestamp in seconds.
+func stepQuery(_ context.Context, _ string, start, end time.Time, step time.Duration) (loghttp.Matrix, error) {
+	var matrix loghttp.Matrix
+	for _, job := range []string{"a", "b"} {
+		s := model.SampleStream{Metric: model.Metric{"job": model.LabelValue(job)}}
+		for t := start; !t.After(end); t = t.Add(step) {
+			s.Values = append(s.Values, model.SamplePair{Timestamp: model.TimeFromUnixNano(t.UnixNano()), Value: model.SampleValue(t.Unix())})
+		}
+		matrix = append(matrix, s)
+	}
+	return matrix, nil
+}
+
+func TestBackfill(t *testing.T) {
+	nss, errs := ParseLoki("testdata/loki_unittest_rules.yaml")
+	require.Empty(t, errs)
+
+	w := &capturingBackfillWriter{}
+	cfg := BackfillConfig{
+		Start:           time.Unix(0, 0).Add(90 * time.Minute),
+		End:             time.Unix(0, 0).Add(5 * time.Hour),
+		DefaultInterval: time.Minute,
+		BlockDuration:   2 * time.Hour,
+	}
+	require.NoError(t, Backfill(context.Background(), cfg, nss, stepQuery, w))
+
+	// [1h30m, 2h), [2h, 4h) and [4h, 5h)
+	require.Len(t, w.blocks, 3)
+	for i, expected := range []int{30, 120, 60} {
+		require.Len(t, w.blocks[i], 2)
+		for _, s := range w.blocks[i] {
+			require.Equal(t, "job:errors:count1m", s.Metric.Get(labels.MetricName))
+			require.Len(t, s.Floats, expected)
+		}
+	}
+	require.Equal(t, `{__name__="job:errors:count1m", job="a"}`, w.blocks[0][0].Metric.String())
+	require.Equal(t, int64(90*time.Minute/time.Millisecond), w.blocks[0][0].Floats[0].T)
+	require.Equal(t, float64(5*time.Hour/time.Second-60), w.blocks[2][1].Floats[59].F)
+}
+
+func TestTSDBBackfillWriter(t *testing.T) {
+	dir := t.TempDir()
+	w := NewTSDBBackfillWriter(dir, 2*time.Hour, util_log.SlogFromGoKit(log.NewNopLogger()))
+
+	series := []promql.Series{{
+		Metric: labels.FromStrings(labels.MetricName, "job:errors:count1m", "job", "a"),
+		Floats: []promql.FPoint{{T: 0, F: 1}, {T: 60000, F: 2}, {T: 120000, F: 3}},
+	}}
+	require.NoError(t, w.Write(context.Background(), series))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	block, err := tsdb.OpenBlock(nil, filepath.Join(dir, entries[0].Name()), nil, nil)
+	require.NoError(t, err)
+	defer block.Close()
+	require.Equal(t, uint64(1), block.Meta().Stats.NumSeries)
+	require.Equal(t, uint64(3), block.Meta().Stats.NumSamples)
+	require.Equal(t, int64(0), block.MinTime())
+	require.Equal(t, int64(120001), block.MaxTime())
+}
@@ -0,0 +1,585 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	promrules "github.com/prometheus/prometheus/rules"
+	"github.com/prometheus/prometheus/storage"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/ruler"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const (
+	unitTestTenant            = "fake"
+	defaultEvaluationInterval = model.Duration(time.Minute)
+	sampleValueEpsilon        = 1e-9
+)
+
+// UnitTestFile is the format of a rules unit test file, modelled after
+// the format used by `promtool test rules`.
+type UnitTestFile struct {
+	// RuleFiles are resolved relative to the directory of the test file.
+	RuleFiles          []string       `yaml:"rule_files"`
+	EvaluationInterval model.Duration `yaml:"evaluation_interval,omitempty"`
+	Tests              []TestGroup    `yaml:"tests"`
+}
+
+// TestGroup is a set of input streams and the alerts and recorded samples
+// expected when evaluating the rules against them.
+type TestGroup struct {
+	Name               string              `yaml:"name,omitempty"`
+	InputStreams       []InputStream       `yaml:"input_streams"`
+	AlertRuleTests     []AlertTestCase     `yaml:"alert_rule_test,omitempty"`
+	RecordingRuleTests []RecordingTestCase `yaml:"recording_rule_test,omitempty"`
+}
+
+// InputStream is a synthetic log stream, e.g. `{app="foo", env="prod"}`.
+type InputStream struct {
+	Labels  string       `yaml:"labels"`
+	Entries []InputEntry `yaml:"entries"`
+}
+
+// InputEntry describes one or more log lines. The timestamp is an offset from
+// the start of the test. If Count is greater than 1 the line is repeated
+// every Interval.
+type InputEntry struct {
+	Timestamp model.Duration `yaml:"ts"`
+	Line      string         `yaml:"line"`
+	Count     int            `yaml:"count,omitempty"`
+	Interval  model.Duration `yaml:"interval,omitempty"`
+}
+
+// AlertTestCase lists the alerts expected to be firing for an alerting rule
+// at a given evaluation time.
+type AlertTestCase struct {
+	EvalTime  model.Duration  `yaml:"eval_time"`
+	Alertname string          `yaml:"alertname"`
+	ExpAlerts []ExpectedAlert `yaml:"exp_alerts"`
+}
+
+type ExpectedAlert struct {
+	ExpLabels      map[string]string `yaml:"exp_labels"`
+	ExpAnnotations map[string]string `yaml:"exp_annotations"`
+}
+
+// RecordingTestCase lists the samples expected to be recorded by a recording
+// rule at a given evaluation time.
+type RecordingTestCase struct {
+	EvalTime   model.Duration   `yaml:"eval_time"`
+	Record     string           `yaml:"record"`
+	ExpSamples []ExpectedSample `yaml:"exp_samples"`
+}
+
+type ExpectedSample struct {
+	// Labels of the sample, excluding the metric name, e.g. `{job="foo"}`.
+	Labels string  `yaml:"labels"`
+	Value  float64 `yaml:"value"`
+}
+
+// RunUnitTests runs the rules unit tests in the given files and writes the
+// results to w. It returns an error if any of the tests failed.
+func RunUnitTests(w io.Writer, files ...string) error {
+	var failed int
+	for _, f := range files {
+		fmt.Fprintln(w, "Unit Testing: ", f)
+
+		errs := runUnitTestFile(f)
+		if len(errs) > 0 {
+			failed++
+			fmt.Fprintln(w, "  FAILED:")
+			for _, err := range errs {
+				fmt.Fprintln(w, indent(err.Error(), "    "))
+			}
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintln(w, "  SUCCESS")
+		fmt.Fprintln(w)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test files failed", failed, len(files))
+	}
+	return nil
+}
+
+func runUnitTestFile(filename string) []error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return []error{err}
+	}
+
+	var utf UnitTestFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&utf); err != nil {
+		return []error{err}
+	}
+	if utf.EvaluationInterval == 0 {
+		utf.EvaluationInterval = defaultEvaluationInterval
+	}
+
+	ruleFiles := make([]string, 0, len(utf.RuleFiles))
+	for _, rf := range utf.RuleFiles {
+		if !filepath.IsAbs(rf) {
+			rf = filepath.Join(filepath.Dir(filename), rf)
+		}
+		ruleFiles = append(ruleFiles, rf)
+	}
+
+	var errs []error
+	for _, tg := range utf.Tests {
+		for _, err := range tg.run(ruleFiles, time.Duration(utf.EvaluationInterval)) {
+			if tg.Name != "" {
+				err = fmt.Errorf("name: %s,\n%w", tg.Name, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (tg *TestGroup) validate(interval time.Duration) error {
+	evalTimes := make([]model.Duration, 0, len(tg.AlertRuleTests)+len(tg.RecordingRuleTests))
+	for _, tc := range tg.AlertRuleTests {
+		evalTimes = append(evalTimes, tc.EvalTime)
+	}
+	for _, tc := range tg.RecordingRuleTests {
+		evalTimes = append(evalTimes, tc.EvalTime)
+	}
+	for _, t := range evalTimes {
+		if time.Duration(t)%interval != 0 {
+			return fmt.Errorf("eval_time %s is not a multiple of the evaluation interval %s", t, model.Duration(interval))
+		}
+	}
+	for _, s := range tg.InputStreams {
+		if _, err := syntax.ParseLabels(s.Labels); err != nil {
+			return fmt.Errorf("invalid input stream labels %q: %w", s.Labels, err)
+		}
+		for _, e := range s.Entries {
+			if e.Count > 1 && e.Interval <= 0 {
+				return fmt.Errorf("input stream %s: an interval is required to repeat line %q", s.Labels, e.Line)
+			}
+		}
+	}
+	return nil
+}
+
+func (tg *TestGroup) maxEvalTime() time.Duration {
+	var maxt model.Duration
+	for _, tc := range tg.AlertRuleTests {
+		maxt = max(maxt, tc.EvalTime)
+	}
+	for _, tc := range tg.RecordingRuleTests {
+		maxt = max(maxt, tc.EvalTime)
+	}
+	return time.Duration(maxt)
+}
+
+// streams converts the input streams into sorted logproto streams.
+func (tg *TestGroup) streams() []logproto.Stream {
+	byLabels := map[string]*logproto.Stream{}
+	for _, s := range tg.InputStreams {
+		// Labels are validated beforehand.
+		lbs, _ := syntax.ParseLabels(s.Labels)
+		key := lbs.String()
+		stream, ok := byLabels[key]
+		if !ok {
+			stream = &logproto.Stream{Labels: key, Hash: lbs.Hash()}
+			byLabels[key] = stream
+		}
+		for _, e := range s.Entries {
+			count := max(e.Count, 1)
+			for i := 0; i < count; i++ {
+				ts := time.Duration(e.Timestamp) + time.Duration(i)*time.Duration(e.Interval)
+				stream.Entries = append(stream.Entries, logproto.Entry{
+					Timestamp: time.Unix(0, 0).Add(ts),
+					Line:      e.Line,
+				})
+			}
+		}
+	}
+
+	streams := make([]logproto.Stream, 0, len(byLabels))
+	for _, s := range byLabels {
+		sort.SliceStable(s.Entries, func(i, j int) bool {
+			return s.Entries[i].Timestamp.Before(s.Entries[j].Timestamp)
+		})
+		streams = append(streams, *s)
+	}
+	sort.Slice(streams, func(i, j int) bool { return streams[i].Labels < streams[j].Labels })
+	return streams
+}
+
+func (tg *TestGroup) run(ruleFiles []string, interval time.Duration) []error {
+	if err := tg.validate(interval); err != nil {
+		return []error{err}
+	}
+
+	logger := log.NewNopLogger()
+	engine := logql.NewEngine(logql.EngineOpts{}, logql.NewMockQuerier(0, tg.streams()), logql.NoLimits, logger)
+	queryFn := unitTestQueryFunc(engine)
+
+	groupLoader := ruler.NewCachingGroupLoader(namespaceGroupLoader{})
+	memStore := ruler.NewMemStore(unitTestTenant, queryFn, ruler.NewMemStoreMetrics(nil), time.Minute, logger)
+	defer memStore.Stop()
+
+	appendable := &capturingAppendable{}
+	ctx := user.InjectOrgID(context.Background(), unitTestTenant)
+	mgr := promrules.NewManager(&promrules.ManagerOptions{
+		Appendable:               appendable,
+		Queryable:                memStore,
+		QueryFunc:                queryFn,
+		Context:                  ctx,
+		NotifyFunc:               func(context.Context, string, ...*promrules.Alert) {},
+		Logger:                   util_log.SlogFromGoKit(logger),
+		GroupLoader:              groupLoader,
+		RuleDependencyController: noopRuleDependencyController{},
+	})
+
+	groupsMap, errs := mgr.LoadGroups(interval, labels.EmptyLabels(), "", nil, false, ruleFiles...)
+	if len(errs) > 0 {
+		return errs
+	}
+	memStore.Start(groupLoader)
+
+	groups := make([]*promrules.Group, 0, len(groupsMap))
+	for _, g := range groupsMap {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].File() != groups[j].File() {
+			return groups[i].File() < groups[j].File()
+		}
+		return groups[i].Name() < groups[j].Name()
+	})
+
+	alertTests := map[time.Duration][]AlertTestCase{}
+	for _, tc := range tg.AlertRuleTests {
+		alertTests[time.Duration(tc.EvalTime)] = append(alertTests[time.Duration(tc.EvalTime)], tc)
+	}
+	recordingTests := map[time.Duration][]RecordingTestCase{}
+	for _, tc := range tg.RecordingRuleTests {
+		recordingTests[time.Duration(tc.EvalTime)] = append(recordingTests[time.Duration(tc.EvalTime)], tc)
+	}
+
+	var (
+		failures  []error
+		lastEvals = make([]time.Time, len(groups))
+		maxt      = tg.maxEvalTime()
+	)
+	for offset := time.Duration(0); offset <= maxt; offset += interval {
+		ts := time.Unix(0, 0).Add(offset)
+
+		for i, g := range groups {
+			if offset > 0 && ts.Sub(lastEvals[i]) < g.Interval() {
+				continue
+			}
+			g.Eval(ctx, ts)
+			// Mirror the ruler, which restores the `for` state of alerts
+			// through the memstore after the first evaluation of a group.
+			if offset == 0 {
+				g.RestoreForState(ts)
+			}
+			lastEvals[i] = ts
+		}
+
+		for _, tc := range alertTests[offset] {
+			if err := tc.check(groups); err != nil {
+				failures = append(failures, err)
+			}
+		}
+		for _, tc := range recordingTests[offset] {
+			if err := tc.check(appendable.samplesAt(ts)); err != nil {
+				failures = append(failures, err)
+			}
+		}
+	}
+	return failures
+}
+
+func (tc AlertTestCase) check(groups []*promrules.Group) error {
+	var got []labelsAndAnnotations
+	for _, g := range groups {
+		for _, r := range g.Rules() {
+			ar, ok := r.(*promrules.AlertingRule)
+			if !ok || ar.Name() != tc.Alertname {
+				continue
+			}
+			for _, a := range ar.ActiveAlerts() {
+				if a.State == promrules.StateFiring {
+					got = append(got, labelsAndAnnotations{Labels: a.Labels, Annotations: a.Annotations})
+				}
+			}
+		}
+	}
+
+	exp := make([]labelsAndAnnotations, 0, len(tc.ExpAlerts))
+	for _, a := range tc.ExpAlerts {
+		b := labels.NewBuilder(labels.FromMap(a.ExpLabels))
+		b.Set(labels.AlertName, tc.Alertname)
+		exp = append(exp, labelsAndAnnotations{
+			Labels:      b.Labels(),
+			Annotations: labels.FromMap(a.ExpAnnotations),
+		})
+	}
+
+	sortLabelsAndAnnotations(got)
+	sortLabelsAndAnnotations(exp)
+	if !equalLabelsAndAnnotations(exp, got) {
+		return fmt.Errorf("alertname: %s, time: %s,\n    exp:%s,\n    got:%s",
+			tc.Alertname, tc.EvalTime, formatLabelsAndAnnotations(exp), formatLabelsAndAnnotations(got))
+	}
+	return nil
+}
+
+func (tc RecordingTestCase) check(samples []promql.Sample) error {
+	var got []promql.Sample
+	for _, s := range samples {
+		if s.Metric.Get(labels.MetricName) == tc.Record {
+			got = append(got, s)
+		}
+	}
+
+	exp := make([]promql.Sample, 0, len(tc.ExpSamples))
+	for _, s := range tc.ExpSamples {
+		lbs, err := syntax.ParseLabels(s.Labels)
+		if err != nil {
+			return fmt.Errorf("record: %s, time: %s, invalid expected labels %q: %w", tc.Record, tc.EvalTime, s.Labels, err)
+		}
+		b := labels.NewBuilder(lbs)
+		b.Set(labels.MetricName, tc.Record)
+		exp = append(exp, promql.Sample{Metric: b.Labels(), F: s.Value})
+	}
+
+	sortSamples(got)
+	sortSamples(exp)
+	if !equalSamples(exp, got) {
+		return fmt.Errorf("record: %s, time: %s,\n    exp:%s,\n    got:%s",
+			tc.Record, tc.EvalTime, formatSamples(exp), formatSamples(got))
+	}
+	return nil
+}
+
+// unitTestQueryFunc evaluates rule expressions as instant queries against
+// the given engine.
+func unitTestQueryFunc(engine *logql.Engine) promrules.QueryFunc {
+	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		params, err := logql.NewLiteralParams(qs, t, t, 0, 0, logproto.FORWARD, 0, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := engine.Query(params).Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+		switch v := res.Data.(type) {
+		case promql.Vector:
+			return v, nil
+		case promql.Scalar:
+			return promql.Vector{promql.Sample{T: v.T, F: v.V, Metric: labels.Labels{}}}, nil
+		default:
+			return nil, errors.New("rule result is not a vector or scalar")
+		}
+	}
+}
+
+// namespaceGroupLoader loads rule groups from lokitool rule files, which may
+// set a namespace in addition to the groups.
+type namespaceGroupLoader struct{}
+
+func (namespaceGroupLoader) Load(identifier string, _ bool) (*rulefmt.RuleGroups, []error) {
+	nss, errs := ParseLoki(identifier)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	var rgs rulefmt.RuleGroups
+	for _, ns := range nss {
+		for _, g := range ns.Groups {
+			rgs.Groups = append(rgs.Groups, g.RuleGroup)
+		}
+	}
+	return &rgs, nil
+}
+
+func (namespaceGroupLoader) Parse(query string) (parser.Expr, error) {
+	return ruler.GroupLoader{}.Parse(query)
+}
+
+// noopRuleDependencyController disables the analysis of dependencies between
+// rules, which does not apply to LogQL expressions.
+type noopRuleDependencyController struct{}
+
+func (noopRuleDependencyController) AnalyseRules([]promrules.Rule) {}
+
+// capturingAppendable records the samples appended by recording rules.
+type capturingAppendable struct {
+	mtx     sync.Mutex
+	samples []promql.Sample
+}
+
+func (a *capturingAppendable) Appender(_ context.Context) storage.Appender {
+	return &capturingAppender{appendable: a}
+}
+
+func (a *capturingAppendable) samplesAt(ts time.Time) []promql.Sample {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	t := ts.UnixMilli()
+	var res []promql.Sample
+	for _, s := range a.samples {
+		if s.T == t {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+type capturingAppender struct {
+	appendable *capturingAppendable
+	pending    []promql.Sample
+}
+
+func (a *capturingAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	// Stale markers are appended for series which disappeared since the previous evaluation.
+	if value.IsStaleNaN(v) {
+		return ref, nil
+	}
+	a.pending = append(a.pending, promql.Sample{Metric: l, T: t, F: v})
+	return ref, nil
+}
+
+func (a *capturingAppender) AppendExemplar(ref storage.SeriesRef, _ labels.Labels, _ exemplar.Exemplar) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+func (a *capturingAppender) UpdateMetadata(ref storage.SeriesRef, _ labels.Labels, _ metadata.Metadata) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+func (a *capturingAppender) AppendHistogram(ref storage.SeriesRef, _ labels.Labels, _ int64, _ *histogram.Histogram, _ *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+func (a *capturingAppender) AppendCTZeroSample(ref storage.SeriesRef, _ labels.Labels, _, _ int64) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+func (a *capturingAppender) AppendHistogramCTZeroSample(ref storage.SeriesRef, _ labels.Labels, _, _ int64, _ *histogram.Histogram, _ *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+func (a *capturingAppender) SetOptions(_ *storage.AppendOptions) {}
+
+func (a *capturingAppender) Commit() error {
+	a.appendable.mtx.Lock()
+	defer a.appendable.mtx.Unlock()
+	a.appendable.samples = append(a.appendable.samples, a.pending...)
+	a.pending = nil
+	return nil
+}
+
+func (a *capturingAppender) Rollback() error {
+	a.pending = nil
+	return nil
+}
+
+type labelsAndAnnotations struct {
+	Labels      labels.Labels
+	Annotations labels.Labels
+}
+
+func sortLabelsAndAnnotations(s []labelsAndAnnotations) {
+	sort.Slice(s, func(i, j int) bool {
+		if c := labels.Compare(s[i].Labels, s[j].Labels); c != 0 {
+			return c < 0
+		}
+		return labels.Compare(s[i].Annotations, s[j].Annotations) < 0
+	})
+}
+
+func equalLabelsAndAnnotations(a, b []labelsAndAnnotations) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !labels.Equal(a[i].Labels, b[i].Labels) || !labels.Equal(a[i].Annotations, b[i].Annotations) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatLabelsAndAnnotations(s []labelsAndAnnotations) string {
+	if len(s) == 0 {
+		return "[]"
+	}
+	var sb strings.Builder
+	for i, la := range s {
+		fmt.Fprintf(&sb, "\n        %d:\n          Labels:%s\n          Annotations:%s", i, la.Labels, la.Annotations)
+	}
+	return sb.String()
+}
+
+func sortSamples(s []promql.Sample) {
+	sort.Slice(s, func(i, j int) bool { return labels.Compare(s[i].Metric, s[j].Metric) < 0 })
+}
+
+func equalSamples(a, b []promql.Sample) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !labels.Equal(a[i].Metric, b[i].Metric) {
+			return false
+		}
+		if a[i].F != b[i].F && math.Abs(a[i].F-b[i].F) > sampleValueEpsilon*math.Max(1, math.Abs(a[i].F)) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatSamples(s []promql.Sample) string {
+	if len(s) == 0 {
+		return "[]"
+	}
+	var sb strings.Builder
+	for i, smpl := range s {
+		fmt.Fprintf(&sb, "\n        %d: %s %g", i, smpl.Metric, smpl.F)
+	}
+	return sb.String()
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
@@ -0,0 +1,22 @@
+package rules
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUnitTests(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, RunUnitTests(&out, "testdata/loki_unittest.yaml"))
+	require.Contains(t, out.String(), "SUCCESS")
+}
+
+func TestRunUnitTests_Failure(t *testing.T) {
+	var out bytes.Buffer
+	err := RunUnitTests(&out, "testdata/loki_unittest_failure.yaml")
+	require.EqualError(t, err, "1 of 1 test files failed")
+	require.Contains(t, out.String(), "FAILED")
+	require.Contains(t, out.String(), "alertname: HighErrorRate, time: 3m")
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/dskit/user"
+	"golang.org/x/sync/errgroup"
+)
+
+// ActiveQuery is a query running on a query frontend of the cluster.
+// Queries are canceled with a DELETE request to the proxy path
+// /ui/api/v1/proxy/{node}/frontend/active_queries/{id}, with the
+// X-Scope-OrgID header of the tenant of the query.
+type ActiveQuery struct {
+	Node                string    `json:"node"`
+	ID                  string    `json:"id"`
+	Tenant              string    `json:"tenant"`
+	Query               string    `json:"query"`
+	Start               time.Time `json:"start"`
+	End                 time.Time `json:"end"`
+	StartedAt           time.Time `json:"started_at"`
+	SubqueriesInFlight  int       `json:"subqueries_in_flight"`
+	SubqueriesCompleted int       `json:"subqueries_completed"`
+	BytesProcessed      int64     `json:"bytes_processed"`
+	Canceled            bool      `json:"canceled"`
+}
+
+// ActiveQueries are the queries running in the cluster, and the errors of the nodes they could
+// not be fetched from.
+type ActiveQueries struct {
+	Queries []ActiveQuery     `json:"queries"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+// fetchActiveQueries retrieves the queries of a tenant running on all the query frontends of the
+// cluster, since each query frontend only tracks its own queries. The nodes not running a query
+// frontend are skipped.
+func (s *Service) fetchActiveQueries(ctx context.Context, tenant string) ActiveQueries {
+	var (
+		mu     sync.Mutex
+		result = ActiveQueries{Queries: []ActiveQuery{}}
+	)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(16)
+	for _, p := range s.node.Peers() {
+		peer := p
+		g.Go(func() error {
+			queries, err := s.fetchNodeActiveQueries(ctx, peer, tenant)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if result.Errors == nil {
+					result.Errors = make(map[string]string)
+				}
+				result.Errors[peer.Name] = err.Error()
+				return nil
+			}
+			result.Queries = append(result.Queries, queries...)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	sort.Slice(result.Queries, func(i, j int) bool {
+		return result.Queries[i].StartedAt.Before(result.Queries[j].StartedAt)
+	})
+	return result
+}
+
+// fetchNodeActiveQueries retrieves the queries of a tenant running on a cluster member.
+func (s *Service) fetchNodeActiveQueries(ctx context.Context, peer peer.Peer, tenant string) ([]ActiveQuery, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.buildProxyPath(peer, "/frontend/active_queries"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set(user.OrgIDHeaderName, tenant)
+
+	// Unknown paths are redirected to the UI, so redirects are not followed.
+	client := *s.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	// The node does not run a query frontend.
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusTemporaryRedirect {
+		resp.Body.Close()
+		return nil, nil
+	}
+	if err := readResponseError(resp, "fetch active queries"); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Data []ActiveQuery `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	for i := range body.Data {
+		body.Data[i].Node = peer.Name
+	}
+	return body.Data, nil
+}
+
+func (s *Service) activeQueriesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The query frontends only list the queries of the tenant of the request.
+		tenant := r.URL.Query().Get("tenant")
+		if tenant == "" {
+			tenant = r.Header.Get(user.OrgIDHeaderName)
+		}
+		if tenant == "" {
+			s.writeJSONError(w, http.StatusBadRequest, "the tenant parameter or the "+user.OrgIDHeaderName+" header is required")
+			return
+		}
+		queries := s.fetchActiveQueries(r.Context(), tenant)
+		w.Header().Set("Content-Type", contentTypeJSON)
+		if err := json.NewEncoder(w).Encode(queries); err != nil {
+			level.Error(s.logger).Log("msg", "failed to encode active queries", "err", err)
+			s.writeJSONError(w, http.StatusInternalServerError, "failed to encode response")
+			return
+		}
+	})
+}
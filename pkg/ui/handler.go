@@ -17,14 +17,15 @@ import (
 )
 
 const (
-	proxyScheme     = "http"
-	prefixPath      = "/ui"
-	proxyPath       = prefixPath + "/api/v1/proxy/{nodename}/"
-	clusterPath     = prefixPath + "/api/v1/cluster/nodes"
-	clusterSelfPath = prefixPath + "/api/v1/cluster/nodes/self/details"
-	analyticsPath   = prefixPath + "/api/v1/analytics"
-	notFoundPath    = prefixPath + "/api/v1/404"
-	contentTypeJSON = "application/json"
+	proxyScheme       = "http"
+	prefixPath        = "/ui"
+	proxyPath         = prefixPath + "/api/v1/proxy/{nodename}/"
+	clusterPath       = prefixPath + "/api/v1/cluster/nodes"
+	clusterSelfPath   = prefixPath + "/api/v1/cluster/nodes/self/details"
+	analyticsPath     = prefixPath + "/api/v1/analytics"
+	activeQueriesPath = prefixPath + "/api/v1/active_queries"
+	notFoundPath      = prefixPath + "/api/v1/404"
+	contentTypeJSON   = "application/json"
 )
 
 //go:embed frontend/dist
@@ -39,6 +40,7 @@ func (s *Service) RegisterHandler() {
 	s.router.Path(analyticsPath).Handler(analytics.Handler())
 	s.router.Path(clusterPath).Handler(s.clusterMembersHandler())
 	s.router.Path(clusterSelfPath).Handler(s.clusterSelfHandler())
+	s.router.Path(activeQueriesPath).Methods(http.MethodGet).Handler(s.activeQueriesHandler())
 
 	s.router.PathPrefix(proxyPath).Handler(s.clusterProxyHandler())
 	s.router.PathPrefix(notFoundPath).Handler(s.notFoundHandler())
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/distributor"
 	"github.com/grafana/loki/v3/pkg/indexgateway"
 	"github.com/grafana/loki/v3/pkg/ingester"
+	limits_frontend "github.com/grafana/loki/v3/pkg/limits/frontend"
 	"github.com/grafana/loki/v3/pkg/pattern"
 	querier_limits "github.com/grafana/loki/v3/pkg/querier/limits"
 	queryrange_limits "github.com/grafana/loki/v3/pkg/querier/queryrange/limits"
@@ -21,8 +22,10 @@ type CombinedLimits interface {
 	compactor.Limits
 	distributor.Limits
 	ingester.Limits
+	limits_frontend.Limits
 	querier_limits.Limits
-	queryrange_limits.Limits
+	queryrange_limits.AsyncQueryLimits
+	queryrange_limits.ExportLimits
 	ruler.RulesLimits
 	scheduler_limits.Limits
 	storage.StoreLimits
@@ -395,6 +395,12 @@ func encodeLabels(labels []logproto.LabelAdapter, s *jsoniter.Stream) {
 	}
 }
 
+// EncodeStream encodes a stream of the result of a log query to JSON, for the responses
+// encoded one stream at a time.
+func EncodeStream(stream logproto.Stream, s *jsoniter.Stream, encodeFlags httpreq.EncodingFlags) error {
+	return encodeStream(stream, s, encodeFlags)
+}
+
 // encodeStream encodes a logproto.Stream to JSON.
 // If the FlagCategorizeLabels is set, the stream labels are grouped by their group name.
 // Otherwise, the stream labels are written one after the other.
@@ -0,0 +1,186 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logql/log/pattern"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// FieldDetectionValueCapture is the name of the capture holding the value extracted by a
+// field detection rule, in its regular expression or pattern.
+const FieldDetectionValueCapture = "value"
+
+// FieldDetectionConfig configures the rules the distributors use to derive structured
+// metadata from the pushed lines, in addition to the built-in field and log level detection.
+type FieldDetectionConfig struct {
+	LogLevelMapping map[string]string    `yaml:"log_level_mapping" json:"log_level_mapping" doc:"description=Mapping of non-standard log level values to the detected level. The values are matched case-insensitively and apply to the levels found in labels, structured metadata and lines, as well as to the values extracted by the rules named detected_level."`
+	Rules           []FieldDetectionRule `yaml:"rules" json:"rules" doc:"description=Rules extracting values from the lines, stream labels or structured metadata of the pushed entries into structured metadata. A rule is skipped for an entry which already has structured metadata with the name of the rule. A rule named detected_level sets the detected log level of the entry, in place of the built-in log level detection."`
+
+	levelMapping map[string]string // populated during validation.
+}
+
+type FieldDetectionRule struct {
+	Name         string            `yaml:"name" json:"name" doc:"description=Name of the structured metadata set by the rule."`
+	Selector     string            `yaml:"selector" json:"selector" doc:"description=Stream selector of the streams the rule applies to. Defaults to all the streams."`
+	Source       string            `yaml:"source" json:"source" doc:"description=Name of the stream label or structured metadata the value is extracted from. Defaults to the log line."`
+	Regex        string            `yaml:"regex" json:"regex" doc:"description=Regular expression extracting the value, from its capture group named value or else from its first capture group."`
+	Pattern      string            `yaml:"pattern" json:"pattern" doc:"description=Pattern extracting the value from its capture named value, with the syntax of the LogQL pattern parser. Only one of regex and pattern can be set."`
+	ValueMapping map[string]string `yaml:"value_mapping" json:"value_mapping" doc:"description=Mapping of the extracted values to the values of the structured metadata. Values without a mapping are kept as is."`
+
+	Matchers []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+	re       *regexp.Regexp
+	group    int
+}
+
+// Validate validates the rules and compiles their selectors and regular expressions.
+func (c *FieldDetectionConfig) Validate() error {
+	c.levelMapping = make(map[string]string, len(c.LogLevelMapping))
+	for from, to := range c.LogLevelMapping {
+		if to == "" {
+			return fmt.Errorf("log level mapping of %q cannot be empty", from)
+		}
+		c.levelMapping[strings.ToLower(from)] = to
+	}
+
+	if len(c.Rules) == 0 {
+		return nil
+	}
+	rules, err := compileCopies(c.Rules, (*FieldDetectionRule).compile)
+	if err != nil {
+		return err
+	}
+	c.Rules = rules
+	return nil
+}
+
+func (r *FieldDetectionRule) compile() error {
+	if r.Name == "" {
+		return errors.New("field detection rule name cannot be empty")
+	}
+
+	if r.Selector != "" {
+		matchers, err := syntax.ParseMatchers(r.Selector, true)
+		if err != nil {
+			return fmt.Errorf("invalid selector for field detection rule %q: %w", r.Name, err)
+		}
+		r.Matchers = matchers
+	}
+
+	switch {
+	case r.Regex != "" && r.Pattern != "":
+		return fmt.Errorf("only one of regex and pattern can be set for field detection rule %q", r.Name)
+	case r.Regex != "":
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex for field detection rule %q: %w", r.Name, err)
+		}
+		if re.NumSubexp() == 0 {
+			return fmt.Errorf("regex of field detection rule %q requires a capture group", r.Name)
+		}
+		r.re = re
+		r.group = 1
+		if i := re.SubexpIndex(FieldDetectionValueCapture); i > 0 {
+			r.group = i
+		}
+	case r.Pattern != "":
+		m, err := pattern.New(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern for field detection rule %q: %w", r.Name, err)
+		}
+		if patternCaptureIndex(m) < 0 {
+			return fmt.Errorf("pattern of field detection rule %q requires a capture named %s", r.Name, FieldDetectionValueCapture)
+		}
+	default:
+		return fmt.Errorf("one of regex and pattern is required for field detection rule %q", r.Name)
+	}
+	return nil
+}
+
+// Enabled returns whether the config has level mappings or rules.
+func (c *FieldDetectionConfig) Enabled() bool {
+	return len(c.levelMapping) > 0 || len(c.Rules) > 0
+}
+
+// MapLogLevel returns the level a log level value is mapped to, if any.
+func (c *FieldDetectionConfig) MapLogLevel(level string) (string, bool) {
+	if len(c.levelMapping) == 0 {
+		return "", false
+	}
+	mapped, ok := c.levelMapping[strings.ToLower(level)]
+	return mapped, ok
+}
+
+// Matches returns whether the rule applies to a stream.
+func (r *FieldDetectionRule) Matches(lbs labels.Labels) bool {
+	for _, m := range r.Matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// FieldExtractor extracts the value of a rule from its source. Extractors of pattern rules
+// are not safe for concurrent use, so they are created for each push request.
+type FieldExtractor struct {
+	rule    *FieldDetectionRule
+	matcher *pattern.Matcher
+	capture int
+}
+
+// NewExtractor returns an extractor for a validated rule.
+func (r *FieldDetectionRule) NewExtractor() *FieldExtractor {
+	e := &FieldExtractor{rule: r}
+	if r.re == nil {
+		// The pattern was already parsed during validation.
+		e.matcher, _ = pattern.New(r.Pattern)
+		e.capture = patternCaptureIndex(e.matcher)
+	}
+	return e
+}
+
+func (e *FieldExtractor) Rule() *FieldDetectionRule {
+	return e.rule
+}
+
+// Extract returns the value extracted from s, after the value mapping of the rule.
+func (e *FieldExtractor) Extract(s string) (string, bool) {
+	var value string
+	if e.rule.re != nil {
+		match := e.rule.re.FindStringSubmatchIndex(s)
+		if match == nil || match[2*e.rule.group] < 0 {
+			return "", false
+		}
+		// The value is cloned as it is kept in the structured metadata of the entry.
+		value = strings.Clone(s[match[2*e.rule.group]:match[2*e.rule.group+1]])
+	} else {
+		captures := e.matcher.Matches([]byte(s))
+		if e.capture >= len(captures) {
+			return "", false
+		}
+		value = string(captures[e.capture])
+	}
+
+	if mapped, ok := e.rule.ValueMapping[value]; ok {
+		value = mapped
+	}
+	if value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+func patternCaptureIndex(m *pattern.Matcher) int {
+	for i, name := range m.Names() {
+		if name == FieldDetectionValueCapture {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,106 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldDetectionConfig_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		cfg         FieldDetectionConfig
+		expectedErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			cfg: FieldDetectionConfig{
+				LogLevelMapping: map[string]string{"W": "warn"},
+				Rules: []FieldDetectionRule{
+					{Name: "trace_id", Regex: `trace_id=(\w+)`},
+					{Name: "http_status", Selector: `{app="nginx"}`, Pattern: `<_> "<_>" <value> <_>`},
+				},
+			},
+		},
+		{
+			name:        "empty level mapping",
+			cfg:         FieldDetectionConfig{LogLevelMapping: map[string]string{"W": ""}},
+			expectedErr: `log level mapping of "W" cannot be empty`,
+		},
+		{
+			name:        "missing name",
+			cfg:         FieldDetectionConfig{Rules: []FieldDetectionRule{{Regex: `id=(\w+)`}}},
+			expectedErr: "name cannot be empty",
+		},
+		{
+			name:        "invalid selector",
+			cfg:         FieldDetectionConfig{Rules: []FieldDetectionRule{{Name: "id", Selector: `{app=}`, Regex: `id=(\w+)`}}},
+			expectedErr: "invalid selector",
+		},
+		{
+			name:        "regex and pattern",
+			cfg:         FieldDetectionConfig{Rules: []FieldDetectionRule{{Name: "id", Regex: `id=(\w+)`, Pattern: `id=<value>`}}},
+			expectedErr: "only one of regex and pattern can be set",
+		},
+		{
+			name:        "missing extractor",
+			cfg:         FieldDetectionConfig{Rules: []FieldDetectionRule{{Name: "id"}}},
+			expectedErr: "one of regex and pattern is required",
+		},
+		{
+			name:        "regex without capture group",
+			cfg:         FieldDetectionConfig{Rules: []FieldDetectionRule{{Name: "id", Regex: `id=\w+`}}},
+			expectedErr: "requires a capture group",
+		},
+		{
+			name:        "pattern without value capture",
+			cfg:         FieldDetectionConfig{Rules: []FieldDetectionRule{{Name: "id", Pattern: `id=<id>`}}},
+			expectedErr: "requires a capture named value",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFieldExtractor_Extract(t *testing.T) {
+	cfg := FieldDetectionConfig{
+		LogLevelMapping: map[string]string{"W": "warn"},
+		Rules: []FieldDetectionRule{
+			{Name: "first_group", Regex: `(\w+)=(?P<value>\w+)`},
+			{Name: "pattern", Pattern: `<method> <_> <value>`, ValueMapping: map[string]string{"200": "ok"}},
+			{Name: "regex", Regex: `status=(\d+)`},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	value, ok := cfg.Rules[0].NewExtractor().Extract("id=foo")
+	require.True(t, ok)
+	require.Equal(t, "foo", value)
+
+	value, ok = cfg.Rules[1].NewExtractor().Extract("GET /index.html 200")
+	require.True(t, ok)
+	require.Equal(t, "ok", value)
+
+	value, ok = cfg.Rules[2].NewExtractor().Extract("status=503")
+	require.True(t, ok)
+	require.Equal(t, "503", value)
+
+	_, ok = cfg.Rules[2].NewExtractor().Extract("no status")
+	require.False(t, ok)
+
+	level, ok := cfg.MapLogLevel("w")
+	require.True(t, ok)
+	require.Equal(t, "warn", level)
+	_, ok = cfg.MapLogLevel("info")
+	require.False(t, ok)
+}
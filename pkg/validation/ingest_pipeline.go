@@ -0,0 +1,132 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// IngestPipelineConfig configures the transformations applied by the distributors to the
+// streams of a tenant, before they are validated and hashed.
+type IngestPipelineConfig struct {
+	RelabelConfigs             []*relabel.Config `yaml:"relabel_configs,omitempty" json:"relabel_configs,omitempty" doc:"description=Relabeling rules applied to the labels of the pushed streams. Streams dropped by the rules are discarded."`
+	LabelsToStructuredMetadata []string          `yaml:"labels_to_structured_metadata" json:"labels_to_structured_metadata" doc:"description=Labels removed from the pushed streams and added to the structured metadata of their entries, after relabeling."`
+	DropLines                  []string          `yaml:"drop_lines" json:"drop_lines" doc:"description=LogQL line filter expressions, each made of one or more line filters. Lines matching any of the expressions are discarded."`
+	Redactions                 []Redaction       `yaml:"redactions" json:"redactions" doc:"description=Regular expressions whose matches in the lines are replaced, in order. The replacement can reference the capture groups of the expression, for example $1."`
+
+	dropFilters []log.Filterer // populated during validation.
+}
+
+type Redaction struct {
+	Regex       string `yaml:"regex" json:"regex" doc:"description=Regular expression matching the text to redact."`
+	Replacement string `yaml:"replacement" json:"replacement" doc:"description=Replacement of the matched text."`
+
+	re *regexp.Regexp // populated during validation.
+}
+
+// Validate validates the pipeline and compiles its line filters and redactions.
+func (c *IngestPipelineConfig) Validate() error {
+	for _, cfg := range c.RelabelConfigs {
+		if cfg == nil {
+			return errors.New("empty relabel config")
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid relabel config: %w", err)
+		}
+	}
+
+	for _, name := range c.LabelsToStructuredMetadata {
+		if name == "" {
+			return errors.New("labels to structured metadata cannot contain an empty label name")
+		}
+	}
+
+	c.dropFilters = make([]log.Filterer, 0, len(c.DropLines))
+	for _, expr := range c.DropLines {
+		filter, err := parseLineFilters(expr)
+		if err != nil {
+			return fmt.Errorf("invalid drop lines expression %q: %w", expr, err)
+		}
+		c.dropFilters = append(c.dropFilters, filter)
+	}
+
+	if len(c.Redactions) == 0 {
+		return nil
+	}
+	redactions, err := compileCopies(c.Redactions, func(r *Redaction) error {
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid redaction regex %q: %w", r.Regex, err)
+		}
+		r.re = re
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.Redactions = redactions
+	return nil
+}
+
+// parseLineFilters parses a chain of LogQL line filters into a single filter.
+func parseLineFilters(expr string) (log.Filterer, error) {
+	if expr == "" {
+		return nil, errors.New("empty expression")
+	}
+	sel, err := syntax.ParseLogSelector(`{_=""} `+expr, false)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, ok := sel.(*syntax.PipelineExpr)
+	if !ok {
+		return nil, errors.New("expression must contain line filters")
+	}
+
+	filters := make([]log.Filterer, 0, len(pipeline.MultiStages))
+	for _, stage := range pipeline.MultiStages {
+		lineFilter, ok := stage.(*syntax.LineFilterExpr)
+		if !ok {
+			return nil, fmt.Errorf("only line filters are supported, got %s", stage.String())
+		}
+		filter, err := lineFilter.Filter()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+	return log.NewAndFilters(filters), nil
+}
+
+// IsEmpty returns true if the pipeline does not transform the streams.
+func (c *IngestPipelineConfig) IsEmpty() bool {
+	return len(c.RelabelConfigs) == 0 && len(c.LabelsToStructuredMetadata) == 0 && len(c.DropLines) == 0 && len(c.Redactions) == 0
+}
+
+// DropLine returns true if the line matches any of the drop lines expressions.
+func (c *IngestPipelineConfig) DropLine(line string) bool {
+	for _, filter := range c.dropFilters {
+		if filter.Filter([]byte(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact applies the redactions to the line.
+func (c *IngestPipelineConfig) Redact(line string) string {
+	for _, r := range c.Redactions {
+		if r.re == nil {
+			continue
+		}
+		line = r.re.ReplaceAllString(line, r.Replacement)
+	}
+	return line
+}
@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestPipelineConfig_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		cfg         IngestPipelineConfig
+		expectedErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			cfg: IngestPipelineConfig{
+				LabelsToStructuredMetadata: []string{"pod"},
+				DropLines:                  []string{`|= "debug"`, `|~ "health.*" != "error"`},
+				Redactions:                 []Redaction{{Regex: `\d{4}-\d{4}`, Replacement: "<card>"}},
+			},
+		},
+		{
+			name:        "empty label",
+			cfg:         IngestPipelineConfig{LabelsToStructuredMetadata: []string{""}},
+			expectedErr: "empty label name",
+		},
+		{
+			name:        "invalid line filter",
+			cfg:         IngestPipelineConfig{DropLines: []string{`|= debug`}},
+			expectedErr: "invalid drop lines expression",
+		},
+		{
+			name:        "not a line filter",
+			cfg:         IngestPipelineConfig{DropLines: []string{`| json`}},
+			expectedErr: "only line filters are supported",
+		},
+		{
+			name:        "invalid redaction",
+			cfg:         IngestPipelineConfig{Redactions: []Redaction{{Regex: "("}}},
+			expectedErr: "invalid redaction regex",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestIngestPipelineConfig_DropLineAndRedact(t *testing.T) {
+	cfg := IngestPipelineConfig{
+		DropLines: []string{`|= "debug"`, `|= "health" != "error"`},
+		Redactions: []Redaction{
+			{Regex: `password=\S+`, Replacement: "password=<redacted>"},
+			{Regex: `user=(\w)\w*`, Replacement: "user=$1***"},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	require.True(t, cfg.DropLine("level=debug msg=foo"))
+	require.True(t, cfg.DropLine("health check"))
+	require.False(t, cfg.DropLine("health check error"))
+	require.False(t, cfg.DropLine("msg=foo"))
+
+	require.Equal(t, "login user=f*** password=<redacted>", cfg.Redact("login user=foo password=secret"))
+}
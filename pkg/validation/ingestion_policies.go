@@ -1,12 +1,14 @@
 package validation
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 
 	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/util/flagext"
 )
 
 const (
@@ -92,3 +94,34 @@ func (p *PolicyStreamMapping) PolicyFor(lbs labels.Labels) []string {
 
 	return policies
 }
+
+// PolicyOrGlobal returns the given policy, or the global policy for streams which
+// do not match any policy.
+func PolicyOrGlobal(policy string) string {
+	if policy == "" {
+		return GlobalPolicy
+	}
+	return policy
+}
+
+// PolicyLimits are the ingestion limits applied to the streams of a policy. A limit set to 0
+// is not enforced for the policy.
+type PolicyLimits struct {
+	IngestionRateMB         float64          `yaml:"ingestion_rate_mb" json:"ingestion_rate_mb"`
+	IngestionBurstSizeMB    float64          `yaml:"ingestion_burst_size_mb" json:"ingestion_burst_size_mb"`
+	MaxGlobalStreamsPerUser int              `yaml:"max_global_streams_per_user" json:"max_global_streams_per_user"`
+	MaxLineSize             flagext.ByteSize `yaml:"max_line_size" json:"max_line_size"`
+}
+
+func (l PolicyLimits) Validate() error {
+	if l.IngestionRateMB < 0 {
+		return errors.New("ingestion_rate_mb must not be negative")
+	}
+	if l.IngestionBurstSizeMB < 0 {
+		return errors.New("ingestion_burst_size_mb must not be negative")
+	}
+	if l.MaxGlobalStreamsPerUser < 0 {
+		return errors.New("max_global_streams_per_user must not be negative")
+	}
+	return nil
+}
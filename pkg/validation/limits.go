@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logql"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/pattern/drain"
 	ruler_config "github.com/grafana/loki/v3/pkg/ruler/config"
 	"github.com/grafana/loki/v3/pkg/ruler/util"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/sharding"
@@ -90,19 +91,21 @@ type Limits struct {
 	EnableMultiVariantQueries bool `yaml:"enable_multi_variant_queries" json:"enable_multi_variant_queries"`
 
 	// Metadata field extraction
-	DiscoverGenericFields    FieldDetectorConfig `yaml:"discover_generic_fields" json:"discover_generic_fields" doc:"description=Experimental: Detect fields from stream labels, structured metadata, or json/logfmt formatted log line and put them into structured metadata of the log entry."`
-	DiscoverServiceName      []string            `yaml:"discover_service_name" json:"discover_service_name"`
-	DiscoverLogLevels        bool                `yaml:"discover_log_levels" json:"discover_log_levels"`
-	LogLevelFields           []string            `yaml:"log_level_fields" json:"log_level_fields"`
-	LogLevelFromJSONMaxDepth int                 `yaml:"log_level_from_json_max_depth" json:"log_level_from_json_max_depth"`
+	DiscoverGenericFields    FieldDetectorConfig  `yaml:"discover_generic_fields" json:"discover_generic_fields" doc:"description=Experimental: Detect fields from stream labels, structured metadata, or json/logfmt formatted log line and put them into structured metadata of the log entry."`
+	DiscoverServiceName      []string             `yaml:"discover_service_name" json:"discover_service_name"`
+	DiscoverLogLevels        bool                 `yaml:"discover_log_levels" json:"discover_log_levels"`
+	LogLevelFields           []string             `yaml:"log_level_fields" json:"log_level_fields"`
+	LogLevelFromJSONMaxDepth int                  `yaml:"log_level_from_json_max_depth" json:"log_level_from_json_max_depth"`
+	FieldDetection           FieldDetectionConfig `yaml:"field_detection" json:"field_detection" category:"experimental" doc:"description=Rules deriving structured metadata, such as trace IDs, HTTP status codes or log levels, from the pushed entries, and mapping of non-standard log level values. Example:\n field_detection: \n  log_level_mapping: \n    W: warn \n    E: error \n  rules: \n    - name: trace_id \n      regex: 'trace_id=(\\w+)' \n    - name: http_status \n      selector: '{app=\"nginx\"}' \n      pattern: '<_> \"<_>\" <value> <_>'"`
 
 	// Ingester enforced limits.
-	UseOwnedStreamCount     bool             `yaml:"use_owned_stream_count" json:"use_owned_stream_count"`
-	MaxLocalStreamsPerUser  int              `yaml:"max_streams_per_user" json:"max_streams_per_user"`
-	MaxGlobalStreamsPerUser int              `yaml:"max_global_streams_per_user" json:"max_global_streams_per_user"`
-	UnorderedWrites         bool             `yaml:"unordered_writes" json:"unordered_writes"`
-	PerStreamRateLimit      flagext.ByteSize `yaml:"per_stream_rate_limit" json:"per_stream_rate_limit"`
-	PerStreamRateLimitBurst flagext.ByteSize `yaml:"per_stream_rate_limit_burst" json:"per_stream_rate_limit_burst"`
+	UseOwnedStreamCount     bool                 `yaml:"use_owned_stream_count" json:"use_owned_stream_count"`
+	MaxLocalStreamsPerUser  int                  `yaml:"max_streams_per_user" json:"max_streams_per_user"`
+	MaxGlobalStreamsPerUser int                  `yaml:"max_global_streams_per_user" json:"max_global_streams_per_user"`
+	UnorderedWrites         bool                 `yaml:"unordered_writes" json:"unordered_writes"`
+	PerStreamRateLimit      flagext.ByteSize     `yaml:"per_stream_rate_limit" json:"per_stream_rate_limit"`
+	PerStreamRateLimitBurst flagext.ByteSize     `yaml:"per_stream_rate_limit_burst" json:"per_stream_rate_limit_burst"`
+	StreamRateLimits        StreamRateLimitRules `yaml:"stream_rate_limits" json:"stream_rate_limits" category:"experimental" doc:"description=Per-stream rate limits applied to the streams matching a selector instead of per_stream_rate_limit, with the behaviour for the lines above the limit. Example:\n stream_rate_limits: \n  - name: debug \n    selector: '{env=\"dev\"}' \n    rate_limit: 512KB \n    overflow: sample \n  - name: payments \n    selector: '{app=\"payments\"}' \n    priority: 1 \n    overflow: priority \n    priority_filter: '!= \"debug\"' \n    priority_rate_limit: 256KB"`
 
 	// Querier enforced limits.
 	MaxChunksPerQuery          int              `yaml:"max_chunks_per_query" json:"max_chunks_per_query"`
@@ -137,8 +140,15 @@ type Limits struct {
 	MinShardingLookback              model.Duration   `yaml:"min_sharding_lookback" json:"min_sharding_lookback"`
 	MaxQueryBytesRead                flagext.ByteSize `yaml:"max_query_bytes_read" json:"max_query_bytes_read"`
 	MaxQuerierBytesRead              flagext.ByteSize `yaml:"max_querier_bytes_read" json:"max_querier_bytes_read"`
+	QueryScanQuotaPerFrontendHourly  flagext.ByteSize `yaml:"query_scan_quota_per_frontend_hourly" json:"query_scan_quota_per_frontend_hourly" category:"experimental"`
+	QueryScanQuotaPerFrontendDaily   flagext.ByteSize `yaml:"query_scan_quota_per_frontend_daily" json:"query_scan_quota_per_frontend_daily" category:"experimental"`
+	QueryScanQuotaSoftRatio          float64          `yaml:"query_scan_quota_soft_ratio" json:"query_scan_quota_soft_ratio" category:"experimental"`
 	VolumeEnabled                    bool             `yaml:"volume_enabled" json:"volume_enabled" doc:"description=Enable log-volume endpoints."`
 	VolumeMaxSeries                  int              `yaml:"volume_max_series" json:"volume_max_series" doc:"description=The maximum number of aggregated series in a log-volume response"`
+	AsyncQueryMaxConcurrentJobs      int              `yaml:"async_query_max_concurrent_jobs" json:"async_query_max_concurrent_jobs" category:"experimental"`
+	AsyncQueryMaxQueuedJobs          int              `yaml:"async_query_max_queued_jobs" json:"async_query_max_queued_jobs" category:"experimental"`
+	AsyncQueryResultsTTL             model.Duration   `yaml:"async_query_results_ttl" json:"async_query_results_ttl" category:"experimental"`
+	ExportMaxBytesScanned            flagext.ByteSize `yaml:"export_max_bytes_scanned" json:"export_max_bytes_scanned" category:"experimental"`
 
 	// Ruler defaults and limits.
 	RulerMaxRulesPerRuleGroup   int                              `yaml:"ruler_max_rules_per_rule_group" json:"ruler_max_rules_per_rule_group"`
@@ -187,6 +197,8 @@ type Limits struct {
 	RulerRemoteEvaluationTimeout         time.Duration `yaml:"ruler_remote_evaluation_timeout" json:"ruler_remote_evaluation_timeout" doc:"description=Timeout for a remote rule evaluation. Defaults to the value of 'querier.query-timeout'."`
 	RulerRemoteEvaluationMaxResponseSize int64         `yaml:"ruler_remote_evaluation_max_response_size" json:"ruler_remote_evaluation_max_response_size" doc:"description=Maximum size (in bytes) of the allowable response size from a remote rule evaluation. Set to 0 to allow any response size (default)."`
 
+	RulerAlertSampleLinesLimit int `yaml:"ruler_alert_sample_lines_limit" json:"ruler_alert_sample_lines_limit" category:"experimental"`
+
 	// Global and per tenant deletion mode
 	DeletionMode string `yaml:"deletion_mode" json:"deletion_mode"`
 
@@ -232,12 +244,24 @@ type Limits struct {
 	OTLPConfig                        push.OTLPConfig       `yaml:"otlp_config" json:"otlp_config" doc:"description=OTLP log ingestion configurations"`
 	GlobalOTLPConfig                  push.GlobalOTLPConfig `yaml:"-" json:"-"`
 
+	ElasticsearchBulkConfig push.FieldMappingConfig `yaml:"elasticsearch_bulk_config" json:"elasticsearch_bulk_config" category:"experimental" doc:"description=Mapping of the documents received by the Elasticsearch bulk API compatible endpoint to log entries. The endpoint is enabled with -distributor.elasticsearch-bulk-enabled."`
+	SplunkHECConfig         push.FieldMappingConfig `yaml:"splunk_hec_config" json:"splunk_hec_config" category:"experimental" doc:"description=Mapping of the events received by the Splunk HTTP Event Collector compatible endpoint to log entries. The endpoint is enabled with -distributor.splunk-hec-enabled."`
+	SyslogConfig            push.FieldMappingConfig `yaml:"syslog_config" json:"syslog_config" category:"experimental" doc:"description=Mapping of the messages received by the syslog receiver to log entries. The fields of the messages are facility, severity, hostname, app_name, proc_id, msg_id, timestamp and message. The structured data of RFC5424 messages is available under structured_data, for example structured_data.origin.ip."`
+
+	IngestPipeline IngestPipelineConfig `yaml:"ingest_pipeline" json:"ingest_pipeline" category:"experimental" doc:"description=Transformations applied by the distributors to the pushed streams before they are validated: relabeling of the stream labels, moving labels to structured metadata, dropping lines and redacting lines, in this order."`
+	PIIRedaction   PIIRedactionConfig   `yaml:"pii_redaction" json:"pii_redaction" category:"experimental" doc:"description=Redaction of sensitive values, such as credit card numbers, email addresses and tokens, from the lines and structured metadata of the pushed entries. The rules are applied by the distributors after the ingest pipeline. Example:\n pii_redaction: \n  rules: \n    - name: cards \n      detector: credit_card \n    - name: emails \n      detector: email \n      action: hash \n      json_paths: [user.email]"`
+
+	WebhookTeeTargets WebhookTeeTargets `yaml:"webhook_tee_targets" json:"webhook_tee_targets" category:"experimental" doc:"description=Downstreams the streams of the tenant are forwarded to by the webhook tee of the distributors, when it is enabled. Streams matching the selector of a target are batched and pushed to its URL, with the Loki push protocol or as a JSON webhook. Example:\n webhook_tee_targets: \n  - name: siem \n    url: https://siem.example.com/ingest \n    format: json \n    selector: '{namespace=\"security\"}' \n    headers: \n      Authorization: Bearer token"`
+
+	WriteBufferMaxBytes flagext.ByteSize `yaml:"write_buffer_max_bytes" json:"write_buffer_max_bytes" category:"experimental"`
+
 	BlockIngestionPolicyUntil map[string]dskit_flagext.Time `yaml:"block_ingestion_policy_until" json:"block_ingestion_policy_until" category:"experimental" doc:"description=Block ingestion for policy until the configured date. The policy '*' is the global policy, which is applied to all streams not matching a policy and can be overridden by other policies. The time should be in RFC3339 format. The policy is based on the policy_stream_mapping configuration."`
 	BlockIngestionUntil       dskit_flagext.Time            `yaml:"block_ingestion_until" json:"block_ingestion_until" category:"experimental"`
 	BlockIngestionStatusCode  int                           `yaml:"block_ingestion_status_code" json:"block_ingestion_status_code"`
 	EnforcedLabels            []string                      `yaml:"enforced_labels" json:"enforced_labels" category:"experimental"`
 	PolicyEnforcedLabels      map[string][]string           `yaml:"policy_enforced_labels" json:"policy_enforced_labels" category:"experimental" doc:"description=Map of policies to enforced labels. The policy '*' is the global policy, which is applied to all streams and can be extended by other policies. Example:\n policy_enforced_labels: \n  policy1: \n    - label1 \n    - label2 \n  policy2: \n    - label3 \n    - label4\n  '*':\n    - label5"`
 	PolicyStreamMapping       PolicyStreamMapping           `yaml:"policy_stream_mapping" json:"policy_stream_mapping" category:"experimental" doc:"description=Map of policies to stream selectors with a priority. Experimental.  Example:\n policy_stream_mapping: \n  finance: \n    - selector: '{namespace=\"prod\", container=\"billing\"}' \n      priority: 2 \n  ops: \n    - selector: '{namespace=\"prod\", container=\"ops\"}' \n      priority: 1 \n  staging: \n    - selector: '{namespace=\"staging\"}' \n      priority: 1"`
+	PolicyLimits              map[string]PolicyLimits       `yaml:"policy_limits" json:"policy_limits" category:"experimental" doc:"description=Map of policies to ingestion limits applied to the streams of the policy, in addition to the tenant-wide limits. The policy '*' is the global policy, which is applied to all streams not matching a policy. Supported limits are ingestion_rate_mb, ingestion_burst_size_mb, max_global_streams_per_user and max_line_size. A limit which is not set or set to 0 is not enforced for the policy; ingestion_burst_size_mb defaults to the tenant burst size, and max_line_size takes precedence over the tenant max line size. The policy is based on the policy_stream_mapping configuration. Example:\n policy_limits: \n  staging: \n    ingestion_rate_mb: 2 \n    max_global_streams_per_user: 1000 \n    max_line_size: 64KB"`
 
 	IngestionPartitionsTenantShardSize int `yaml:"ingestion_partitions_tenant_shard_size" json:"ingestion_partitions_tenant_shard_size" category:"experimental"`
 
@@ -246,6 +270,7 @@ type Limits struct {
 	PatternIngesterTokenizableJSONFieldsDefault dskit_flagext.StringSliceCSV `yaml:"pattern_ingester_tokenizable_json_fields_default" json:"pattern_ingester_tokenizable_json_fields_default" doc:"hidden"`
 	PatternIngesterTokenizableJSONFieldsAppend  dskit_flagext.StringSliceCSV `yaml:"pattern_ingester_tokenizable_json_fields_append"  json:"pattern_ingester_tokenizable_json_fields_append"  doc:"hidden"`
 	PatternIngesterTokenizableJSONFieldsDelete  dskit_flagext.StringSliceCSV `yaml:"pattern_ingester_tokenizable_json_fields_delete"  json:"pattern_ingester_tokenizable_json_fields_delete"  doc:"hidden"`
+	PatternIngesterTokenizer                    drain.TokenizerConfig        `yaml:"pattern_ingester_tokenizer" json:"pattern_ingester_tokenizer" category:"experimental" doc:"description=Configures how the pattern ingester tokenizes log lines and which tokens are masked with typed placeholders before patterns are detected."`
 	MetricAggregationEnabled                    bool                         `yaml:"metric_aggregation_enabled"                       json:"metric_aggregation_enabled"`
 
 	// This config doesn't have a CLI flag registered here because they're registered in
@@ -282,11 +307,16 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	_ = l.MaxLineSize.Set("256KB")
 	f.Var(&l.MaxLineSize, "distributor.max-line-size", "Maximum line size on ingestion path. Example: 256kb. Any log line exceeding this limit will be discarded unless `distributor.max-line-size-truncate` is set which in case it is truncated instead of discarding it completely. There is no limit when unset or set to 0.")
 	f.BoolVar(&l.MaxLineSizeTruncate, "distributor.max-line-size-truncate", false, "Whether to truncate lines that exceed max_line_size.")
+	_ = l.WriteBufferMaxBytes.Set("100MB")
+	f.Var(&l.WriteBufferMaxBytes, "distributor.write-buffer-max-bytes", "Maximum size of the pushes of the tenant buffered by the write buffer of each distributor, when it is enabled. Pushes exceeding it fail. Set to 0 to disable buffering for the tenant.")
 	f.IntVar(&l.MaxLabelNameLength, "validation.max-length-label-name", 1024, "Maximum length accepted for label names.")
 	f.IntVar(&l.MaxLabelValueLength, "validation.max-length-label-value", 2048, "Maximum length accepted for label value. This setting also applies to the metric name.")
 	f.IntVar(&l.MaxLabelNamesPerSeries, "validation.max-label-names-per-series", 15, "Maximum number of label names per series.")
 	f.BoolVar(&l.RejectOldSamples, "validation.reject-old-samples", true, "Whether or not old samples will be rejected.")
 	f.BoolVar(&l.IncrementDuplicateTimestamp, "validation.increment-duplicate-timestamps", false, "Alter the log line timestamp during ingestion when the timestamp is the same as the previous entry for the same stream. When enabled, if a log line in a push request has the same timestamp as the previous line for the same stream, one nanosecond is added to the log line. This will preserve the received order of log lines with the exact same timestamp when they are queried, by slightly altering their stored timestamp. NOTE: This is imperfect, because Loki accepts out of order writes, and another push request for the same stream could contain duplicate timestamps to existing entries and they will not be incremented.")
+	l.ElasticsearchBulkConfig.RegisterFlagsWithPrefix("distributor.elasticsearch-bulk", push.DefaultElasticsearchBulkConfig, f)
+	l.SplunkHECConfig.RegisterFlagsWithPrefix("distributor.splunk-hec", push.DefaultSplunkHECConfig, f)
+	l.SyslogConfig.RegisterFlagsWithPrefix("syslog-receiver", push.DefaultSyslogConfig, f)
 	l.DiscoverServiceName = []string{
 		"service",
 		"app",
@@ -364,6 +394,17 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	_ = l.MaxQuerierBytesRead.Set("150GB")
 	f.Var(&l.MaxQuerierBytesRead, "frontend.max-querier-bytes-read", "Max number of bytes a query can fetch after splitting and sharding. Enforced in log and metric queries only when TSDB is used. This limit is not enforced on log queries without filters. The default value of 0 disables this limit.")
 
+	f.Var(&l.QueryScanQuotaPerFrontendHourly, "frontend.query-scan-quota-per-frontend-hourly", "Maximum number of bytes the queries of the tenant can fetch through each query frontend over a sliding window of one hour, estimated from the index stats before the queries are executed. The quota is tracked in memory by each query frontend and is not shared between them. Queries exceeding the quota are rejected. The queries which fail are not charged, and the parts of the queries answered by the results cache are not charged. Enforced in log and metric queries only when TSDB is used, and not on log queries without filters. The default value of 0 disables this limit.")
+	f.Var(&l.QueryScanQuotaPerFrontendDaily, "frontend.query-scan-quota-per-frontend-daily", "Maximum number of bytes the queries of the tenant can fetch through each query frontend over a sliding window of one day, estimated from the index stats before the queries are executed. The quota is tracked in memory by each query frontend and is not shared between them. Queries exceeding the quota are rejected. The queries which fail are not charged, and the parts of the queries answered by the results cache are not charged. Enforced in log and metric queries only when TSDB is used, and not on log queries without filters. The default value of 0 disables this limit.")
+	f.Float64Var(&l.QueryScanQuotaSoftRatio, "frontend.query-scan-quota-soft-ratio", 0.8, "Ratio of the hourly and daily scan quotas of the tenant above which the queries are executed with a warning header. Set to 0 to disable the warnings.")
+
+	f.IntVar(&l.AsyncQueryMaxConcurrentJobs, "frontend.async-query-max-concurrent-jobs", 2, "Maximum number of asynchronous query jobs of the tenant running concurrently on each query frontend, when asynchronous queries are enabled. The limit is not shared between the query frontends. Further jobs are queued until a running job completes. Set to 0 to disable asynchronous queries for the tenant.")
+	f.IntVar(&l.AsyncQueryMaxQueuedJobs, "frontend.async-query-max-queued-jobs", 10, "Maximum number of asynchronous query jobs of the tenant queued on each query frontend. Further jobs are rejected until a queued job starts. Set to 0 to disable this limit.")
+	_ = l.AsyncQueryResultsTTL.Set("24h")
+	f.Var(&l.AsyncQueryResultsTTL, "frontend.async-query-results-ttl", "Duration the results of the asynchronous query jobs of the tenant are kept in the object store after the jobs complete.")
+	_ = l.ExportMaxBytesScanned.Set("1TB")
+	f.Var(&l.ExportMaxBytesScanned, "frontend.export-max-bytes-scanned", "Maximum number of bytes an export request of the tenant can scan. When the quota is reached, the export stops and returns a cursor to resume it from. The default value of 0 disables this limit.")
+
 	_ = l.MaxCacheFreshness.Set("10m")
 	f.Var(&l.MaxCacheFreshness, "frontend.max-cache-freshness", "Most recent allowed cacheable result per-tenant, to prevent caching very recent results that might still be in flux.")
 
@@ -381,6 +422,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.RulerMaxRuleGroupsPerTenant, "ruler.max-rule-groups-per-tenant", 0, "Maximum number of rule groups per-tenant. 0 to disable.")
 	f.IntVar(&l.RulerTenantShardSize, "ruler.tenant-shard-size", 0, "The default tenant's shard size when shuffle-sharding is enabled in the ruler. When this setting is specified in the per-tenant overrides, a value of 0 disables shuffle sharding for the tenant.")
 	f.BoolVar(&l.RulerEnableWALReplay, "ruler.enable-wal-replay", true, "Enable WAL replay on ruler startup. Disabling this can reduce memory usage on startup at the cost of not recovering in-memory WAL metrics on restart.")
+	f.IntVar(&l.RulerAlertSampleLinesLimit, "ruler.alert-sample-lines-limit", 10, "Maximum number of sample log lines attached to a firing alert. Alerting rules opt in by setting the 'loki_attach_sample_lines' annotation to the number of lines to attach. 0 disables attaching sample log lines.")
 
 	f.StringVar(&l.PerTenantOverrideConfig, "limits.per-user-override-config", "", "Feature renamed to 'runtime configuration', flag deprecated in favor of -runtime-config.file (runtime_config.file in YAML).")
 	_ = l.RetentionPeriod.Set("0s")
@@ -513,6 +555,21 @@ func (l *Limits) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// compileCopies returns a copy of items with each item compiled by compile. The limits
+// of a tenant are copied from the defaults, and the slices of the copies share their
+// arrays with the defaults: compiled items are stored in a new array so that the
+// defaults are left untouched.
+func compileCopies[T any](items []T, compile func(*T) error) ([]T, error) {
+	compiled := make([]T, 0, len(items))
+	for _, item := range items {
+		if err := compile(&item); err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, item)
+	}
+	return compiled, nil
+}
+
 // Validate validates that this limits config is valid.
 func (l *Limits) Validate() error {
 	if l.StreamRetention != nil {
@@ -529,12 +586,30 @@ func (l *Limits) Validate() error {
 		}
 	}
 
+	if err := l.StreamRateLimits.Validate(); err != nil {
+		return err
+	}
+
 	if l.PolicyStreamMapping != nil {
 		if err := l.PolicyStreamMapping.Validate(); err != nil {
 			return err
 		}
 	}
 
+	for policy, limits := range l.PolicyLimits {
+		if err := limits.Validate(); err != nil {
+			return fmt.Errorf("invalid limits for policy %s: %w", policy, err)
+		}
+	}
+
+	if err := l.PatternIngesterTokenizer.Validate(); err != nil {
+		return err
+	}
+
+	if l.QueryScanQuotaSoftRatio < 0 || l.QueryScanQuotaSoftRatio > 1 {
+		return fmt.Errorf("query_scan_quota_soft_ratio must be between 0 and 1, was %v", l.QueryScanQuotaSoftRatio)
+	}
+
 	if _, err := deletionmode.ParseMode(l.DeletionMode); err != nil {
 		return err
 	}
@@ -557,6 +632,34 @@ func (l *Limits) Validate() error {
 		return err
 	}
 
+	if err := l.ElasticsearchBulkConfig.Validate(); err != nil {
+		return errors.Wrap(err, "invalid elasticsearch_bulk_config")
+	}
+
+	if err := l.SplunkHECConfig.Validate(); err != nil {
+		return errors.Wrap(err, "invalid splunk_hec_config")
+	}
+
+	if err := l.SyslogConfig.Validate(); err != nil {
+		return errors.Wrap(err, "invalid syslog_config")
+	}
+
+	if err := l.FieldDetection.Validate(); err != nil {
+		return errors.Wrap(err, "invalid field_detection")
+	}
+
+	if err := l.IngestPipeline.Validate(); err != nil {
+		return errors.Wrap(err, "invalid ingest_pipeline")
+	}
+
+	if err := l.PIIRedaction.Validate(); err != nil {
+		return errors.Wrap(err, "invalid pii_redaction")
+	}
+
+	if err := l.WebhookTeeTargets.Validate(); err != nil {
+		return err
+	}
+
 	if _, err := logql.ParseShardVersion(l.TSDBShardingStrategy); err != nil {
 		return errors.Wrap(err, "invalid tsdb sharding strategy")
 	}
@@ -803,6 +906,41 @@ func (o *Overrides) MaxQuerierBytesRead(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).MaxQuerierBytesRead.Val()
 }
 
+// QueryScanQuotaPerFrontendHourly returns the maximum number of bytes the queries of a tenant can fetch through a query frontend over an hour.
+func (o *Overrides) QueryScanQuotaPerFrontendHourly(userID string) int {
+	return o.getOverridesForUser(userID).QueryScanQuotaPerFrontendHourly.Val()
+}
+
+// QueryScanQuotaPerFrontendDaily returns the maximum number of bytes the queries of a tenant can fetch through a query frontend over a day.
+func (o *Overrides) QueryScanQuotaPerFrontendDaily(userID string) int {
+	return o.getOverridesForUser(userID).QueryScanQuotaPerFrontendDaily.Val()
+}
+
+// QueryScanQuotaSoftRatio returns the ratio of the scan quotas of a tenant above which the queries are warned.
+func (o *Overrides) QueryScanQuotaSoftRatio(userID string) float64 {
+	return o.getOverridesForUser(userID).QueryScanQuotaSoftRatio
+}
+
+// AsyncQueryMaxConcurrentJobs returns the maximum number of asynchronous query jobs of a tenant running concurrently.
+func (o *Overrides) AsyncQueryMaxConcurrentJobs(userID string) int {
+	return o.getOverridesForUser(userID).AsyncQueryMaxConcurrentJobs
+}
+
+// AsyncQueryMaxQueuedJobs returns the maximum number of asynchronous query jobs of a tenant queued on each query frontend.
+func (o *Overrides) AsyncQueryMaxQueuedJobs(userID string) int {
+	return o.getOverridesForUser(userID).AsyncQueryMaxQueuedJobs
+}
+
+// AsyncQueryResultsTTL returns the duration the results of the asynchronous query jobs of a tenant are kept.
+func (o *Overrides) AsyncQueryResultsTTL(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).AsyncQueryResultsTTL)
+}
+
+// ExportMaxBytesScanned returns the maximum number of bytes an export request of a tenant can scan.
+func (o *Overrides) ExportMaxBytesScanned(userID string) int {
+	return o.getOverridesForUser(userID).ExportMaxBytesScanned.Val()
+}
+
 // MaxConcurrentTailRequests returns the limit to number of concurrent tail requests.
 func (o *Overrides) MaxConcurrentTailRequests(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).MaxConcurrentTailRequests
@@ -980,6 +1118,11 @@ func (o *Overrides) RulerRemoteEvaluationMaxResponseSize(userID string) int64 {
 	return o.getOverridesForUser(userID).RulerRemoteEvaluationMaxResponseSize
 }
 
+// RulerAlertSampleLinesLimit returns the maximum number of sample log lines attached to a firing alert for a given user.
+func (o *Overrides) RulerAlertSampleLinesLimit(userID string) int {
+	return o.getOverridesForUser(userID).RulerAlertSampleLinesLimit
+}
+
 // RetentionPeriod returns the retention period for a given user.
 func (o *Overrides) RetentionPeriod(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).RetentionPeriod)
@@ -1027,6 +1170,11 @@ func (o *Overrides) PerStreamRateLimit(userID string) RateLimit {
 	}
 }
 
+// StreamRateLimits returns the selector-scoped per-stream rate limits of the tenant.
+func (o *Overrides) StreamRateLimits(userID string) StreamRateLimitRules {
+	return o.getOverridesForUser(userID).StreamRateLimits
+}
+
 func (o *Overrides) IncrementDuplicateTimestamps(userID string) bool {
 	return o.getOverridesForUser(userID).IncrementDuplicateTimestamp
 }
@@ -1047,6 +1195,11 @@ func (o *Overrides) LogLevelFields(userID string) []string {
 	return o.getOverridesForUser(userID).LogLevelFields
 }
 
+// FieldDetection returns the field detection rules of the tenant.
+func (o *Overrides) FieldDetection(userID string) *FieldDetectionConfig {
+	return &o.getOverridesForUser(userID).FieldDetection
+}
+
 func (o *Overrides) LogLevelFromJSONMaxDepth(userID string) int {
 	return o.getOverridesForUser(userID).LogLevelFromJSONMaxDepth
 }
@@ -1128,6 +1281,36 @@ func (o *Overrides) OTLPConfig(userID string) push.OTLPConfig {
 	return o.getOverridesForUser(userID).OTLPConfig
 }
 
+func (o *Overrides) ElasticsearchBulkConfig(userID string) push.FieldMappingConfig {
+	return o.getOverridesForUser(userID).ElasticsearchBulkConfig
+}
+
+func (o *Overrides) SplunkHECConfig(userID string) push.FieldMappingConfig {
+	return o.getOverridesForUser(userID).SplunkHECConfig
+}
+
+func (o *Overrides) SyslogConfig(userID string) push.FieldMappingConfig {
+	return o.getOverridesForUser(userID).SyslogConfig
+}
+
+// IngestPipeline returns the transformations applied to the streams pushed by the tenant.
+func (o *Overrides) IngestPipeline(userID string) *IngestPipelineConfig {
+	return &o.getOverridesForUser(userID).IngestPipeline
+}
+
+// PIIRedaction returns the redaction rules applied to the entries pushed by the tenant.
+func (o *Overrides) PIIRedaction(userID string) *PIIRedactionConfig {
+	return &o.getOverridesForUser(userID).PIIRedaction
+}
+
+func (o *Overrides) WebhookTeeTargets(userID string) WebhookTeeTargets {
+	return o.getOverridesForUser(userID).WebhookTeeTargets
+}
+
+func (o *Overrides) WriteBufferMaxBytes(userID string) int {
+	return o.getOverridesForUser(userID).WriteBufferMaxBytes.Val()
+}
+
 func (o *Overrides) BlockIngestionUntil(userID string) time.Time {
 	return time.Time(o.getOverridesForUser(userID).BlockIngestionUntil)
 }
@@ -1171,6 +1354,39 @@ func (o *Overrides) PoliciesStreamMapping(userID string) PolicyStreamMapping {
 	return o.getOverridesForUser(userID).PolicyStreamMapping
 }
 
+// policyLimits returns the limits of a policy for a given user. The limits of the global
+// policy are applied to streams not matching any policy.
+func (o *Overrides) policyLimits(userID string, policy string) PolicyLimits {
+	return o.getOverridesForUser(userID).PolicyLimits[PolicyOrGlobal(policy)]
+}
+
+// PolicyIngestionRateBytes returns the ingestion rate limit of a policy in bytes per second,
+// or 0 if the policy has no rate limit.
+func (o *Overrides) PolicyIngestionRateBytes(userID string, policy string) float64 {
+	return o.policyLimits(userID, policy).IngestionRateMB * bytesInMB
+}
+
+// PolicyIngestionBurstSizeBytes returns the burst size for the ingestion rate of a policy.
+// It defaults to the burst size of the tenant.
+func (o *Overrides) PolicyIngestionBurstSizeBytes(userID string, policy string) int {
+	if burst := o.policyLimits(userID, policy).IngestionBurstSizeMB; burst > 0 {
+		return int(burst * bytesInMB)
+	}
+	return o.IngestionBurstSizeBytes(userID)
+}
+
+// PolicyMaxGlobalStreamsPerUser returns the maximum number of active streams of a policy
+// across the cluster, or 0 if the policy has no streams limit.
+func (o *Overrides) PolicyMaxGlobalStreamsPerUser(userID string, policy string) int {
+	return o.policyLimits(userID, policy).MaxGlobalStreamsPerUser
+}
+
+// PolicyMaxLineSize returns the maximum line size of the streams of a policy, or 0 if the
+// tenant maximum line size applies.
+func (o *Overrides) PolicyMaxLineSize(userID string, policy string) int {
+	return o.policyLimits(userID, policy).MaxLineSize.Val()
+}
+
 func (o *Overrides) ShardAggregations(userID string) []string {
 	return o.getOverridesForUser(userID).ShardAggregations
 }
@@ -1202,6 +1418,10 @@ func (o *Overrides) PatternIngesterTokenizableJSONFields(userID string) []string
 	return output
 }
 
+func (o *Overrides) PatternIngesterTokenizerConfig(userID string) drain.TokenizerConfig {
+	return o.getOverridesForUser(userID).PatternIngesterTokenizer
+}
+
 func (o *Overrides) PatternIngesterTokenizableJSONFieldsAppend(userID string) []string {
 	return o.getOverridesForUser(userID).PatternIngesterTokenizableJSONFieldsAppend
 }
@@ -172,6 +172,24 @@ func TestOverwriteMarshalingStringMapYAML(t *testing.T) {
 	require.Equal(t, m, back)
 }
 
+func TestWebhookTeeTargetHeadersAreRedacted(t *testing.T) {
+	var limits Limits
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+webhook_tee_targets:
+  - name: siem
+    url: https://siem.example.com/ingest
+    selector: '{namespace="security"}'
+    headers:
+      Authorization: Bearer token
+`), &limits))
+	require.Equal(t, "Bearer token", limits.WebhookTeeTargets[0].Headers["Authorization"].String())
+
+	out, err := yaml.Marshal(limits.WebhookTeeTargets)
+	require.NoError(t, err)
+	require.NotContains(t, string(out), "Bearer token")
+	require.Contains(t, string(out), "Authorization: '********'")
+}
+
 func TestLimitsDoesNotMutate(t *testing.T) {
 	initialDefault := defaultLimits
 	defer func() {
@@ -199,7 +217,10 @@ func TestLimitsDoesNotMutate(t *testing.T) {
 				Selector: `{a="b"}`,
 			},
 		},
-		OTLPConfig: defaultOTLPConfig,
+		OTLPConfig:              defaultOTLPConfig,
+		ElasticsearchBulkConfig: push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+		SplunkHECConfig:         push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+		SyslogConfig:            push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
 	}
 	SetDefaultLimitsForYAMLUnmarshalling(newDefaults)
 
@@ -228,9 +249,18 @@ ruler_remote_write_headers:
 					},
 				},
 				OTLPConfig:                defaultOTLPConfig,
+				ElasticsearchBulkConfig:   push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SplunkHECConfig:           push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SyslogConfig:              push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				StreamRateLimits:          StreamRateLimitRules{},
+				FieldDetection:            FieldDetectionConfig{LogLevelMapping: map[string]string{}, Rules: []FieldDetectionRule{}},
+				IngestPipeline:            IngestPipelineConfig{LabelsToStructuredMetadata: []string{}, DropLines: []string{}, Redactions: []Redaction{}},
+				PIIRedaction:              PIIRedactionConfig{Rules: []PIIRedactionRule{}},
+				WebhookTeeTargets:         WebhookTeeTargets{},
 				EnforcedLabels:            []string{},
 				PolicyEnforcedLabels:      map[string][]string{},
 				PolicyStreamMapping:       PolicyStreamMapping{},
+				PolicyLimits:              map[string]PolicyLimits{},
 				BlockIngestionPolicyUntil: map[string]dskit_flagext.Time{},
 			},
 		},
@@ -251,9 +281,18 @@ ruler_remote_write_headers:
 					},
 				},
 				OTLPConfig:                defaultOTLPConfig,
+				ElasticsearchBulkConfig:   push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SplunkHECConfig:           push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SyslogConfig:              push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				StreamRateLimits:          StreamRateLimitRules{},
+				FieldDetection:            FieldDetectionConfig{LogLevelMapping: map[string]string{}, Rules: []FieldDetectionRule{}},
+				IngestPipeline:            IngestPipelineConfig{LabelsToStructuredMetadata: []string{}, DropLines: []string{}, Redactions: []Redaction{}},
+				PIIRedaction:              PIIRedactionConfig{Rules: []PIIRedactionRule{}},
+				WebhookTeeTargets:         WebhookTeeTargets{},
 				EnforcedLabels:            []string{},
 				PolicyEnforcedLabels:      map[string][]string{},
 				PolicyStreamMapping:       PolicyStreamMapping{},
+				PolicyLimits:              map[string]PolicyLimits{},
 				BlockIngestionPolicyUntil: map[string]dskit_flagext.Time{},
 			},
 		},
@@ -278,9 +317,18 @@ retention_stream:
 				// Rest from new defaults
 				RulerRemoteWriteHeaders:   OverwriteMarshalingStringMap{map[string]string{"a": "b"}},
 				OTLPConfig:                defaultOTLPConfig,
+				ElasticsearchBulkConfig:   push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SplunkHECConfig:           push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SyslogConfig:              push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				StreamRateLimits:          StreamRateLimitRules{},
+				FieldDetection:            FieldDetectionConfig{LogLevelMapping: map[string]string{}, Rules: []FieldDetectionRule{}},
+				IngestPipeline:            IngestPipelineConfig{LabelsToStructuredMetadata: []string{}, DropLines: []string{}, Redactions: []Redaction{}},
+				PIIRedaction:              PIIRedactionConfig{Rules: []PIIRedactionRule{}},
+				WebhookTeeTargets:         WebhookTeeTargets{},
 				EnforcedLabels:            []string{},
 				PolicyEnforcedLabels:      map[string][]string{},
 				PolicyStreamMapping:       PolicyStreamMapping{},
+				PolicyLimits:              map[string]PolicyLimits{},
 				BlockIngestionPolicyUntil: map[string]dskit_flagext.Time{},
 			},
 		},
@@ -304,9 +352,18 @@ reject_old_samples: true
 					},
 				},
 				OTLPConfig:                defaultOTLPConfig,
+				ElasticsearchBulkConfig:   push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SplunkHECConfig:           push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SyslogConfig:              push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				StreamRateLimits:          StreamRateLimitRules{},
+				FieldDetection:            FieldDetectionConfig{LogLevelMapping: map[string]string{}, Rules: []FieldDetectionRule{}},
+				IngestPipeline:            IngestPipelineConfig{LabelsToStructuredMetadata: []string{}, DropLines: []string{}, Redactions: []Redaction{}},
+				PIIRedaction:              PIIRedactionConfig{Rules: []PIIRedactionRule{}},
+				WebhookTeeTargets:         WebhookTeeTargets{},
 				EnforcedLabels:            []string{},
 				PolicyEnforcedLabels:      map[string][]string{},
 				PolicyStreamMapping:       PolicyStreamMapping{},
+				PolicyLimits:              map[string]PolicyLimits{},
 				BlockIngestionPolicyUntil: map[string]dskit_flagext.Time{},
 			},
 		},
@@ -331,9 +388,18 @@ query_timeout: 5m
 					},
 				},
 				OTLPConfig:                defaultOTLPConfig,
+				ElasticsearchBulkConfig:   push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SplunkHECConfig:           push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				SyslogConfig:              push.FieldMappingConfig{LabelFields: []string{}, StructuredMetadataFields: []string{}},
+				StreamRateLimits:          StreamRateLimitRules{},
+				FieldDetection:            FieldDetectionConfig{LogLevelMapping: map[string]string{}, Rules: []FieldDetectionRule{}},
+				IngestPipeline:            IngestPipelineConfig{LabelsToStructuredMetadata: []string{}, DropLines: []string{}, Redactions: []Redaction{}},
+				PIIRedaction:              PIIRedactionConfig{Rules: []PIIRedactionRule{}},
+				WebhookTeeTargets:         WebhookTeeTargets{},
 				EnforcedLabels:            []string{},
 				PolicyEnforcedLabels:      map[string][]string{},
 				PolicyStreamMapping:       PolicyStreamMapping{},
+				PolicyLimits:              map[string]PolicyLimits{},
 				BlockIngestionPolicyUntil: map[string]dskit_flagext.Time{},
 			},
 		},
@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/dskit/flagext"
+)
+
+const (
+	PIIRedactionActionMask = "mask"
+	PIIRedactionActionHash = "hash"
+
+	PIIRedactionTargetAll                = "all"
+	PIIRedactionTargetLine               = "line"
+	PIIRedactionTargetStructuredMetadata = "structured_metadata"
+)
+
+// PIIRedactionConfig configures the rules the distributors use to redact sensitive values,
+// such as credit card numbers, email addresses and tokens, from the pushed entries. The
+// detectors of the rules are implemented by the redaction package of the distributor.
+type PIIRedactionConfig struct {
+	Rules []PIIRedactionRule `yaml:"rules" json:"rules" doc:"description=Redaction rules, applied in order. Each rule has a name, a detector (regex, email, credit_card or secrets), an optional regex for the regex detector, an action (mask or hash) with its replacement or hash_key, a target (all, line or structured_metadata), and optionally the json_paths of the line fields and the structured_metadata_keys it is restricted to."`
+}
+
+type PIIRedactionRule struct {
+	Name                   string         `yaml:"name" json:"name" doc:"description=Name of the rule, used in the metrics of the redacted values."`
+	Detector               string         `yaml:"detector" json:"detector" doc:"description=Detector of the sensitive values. Supported detectors are regex, email, credit_card (validated with the Luhn algorithm) and secrets (well known formats of tokens and keys)."`
+	Regex                  string         `yaml:"regex" json:"regex" doc:"description=Regular expression matching the sensitive values, for the regex detector."`
+	Action                 string         `yaml:"action" json:"action" doc:"description=Action applied to the sensitive values: mask replaces them with the replacement, hash replaces them with an HMAC-SHA256 of their value, so that equal values can still be correlated. Defaults to mask."`
+	Replacement            string         `yaml:"replacement" json:"replacement" doc:"description=Replacement of the masked values. Defaults to [REDACTED]."`
+	HashKey                flagext.Secret `yaml:"hash_key" json:"hash_key" doc:"description=Secret key of the HMAC of the hashed values, required by the hash action. Without the key, the hashes of values with few possibilities, such as card numbers, cannot be reversed by brute force."`
+	Target                 string         `yaml:"target" json:"target" doc:"description=Part of the entries the rule is applied to: all, line or structured_metadata. Defaults to all."`
+	JSONPaths              []string       `yaml:"json_paths" json:"json_paths" doc:"description=Paths of the fields of JSON lines the rule is applied to, such as user.email. When set, the rule is only applied to these fields, and lines which are not JSON are left untouched."`
+	StructuredMetadataKeys []string       `yaml:"structured_metadata_keys" json:"structured_metadata_keys" doc:"description=Structured metadata the rule is applied to. When empty, the rule is applied to all structured metadata."`
+}
+
+// Validate validates the names, actions and targets of the rules. Their detectors are
+// validated by the distributors when they compile the rules.
+func (c *PIIRedactionConfig) Validate() error {
+	names := make(map[string]struct{}, len(c.Rules))
+	for _, r := range c.Rules {
+		if r.Name == "" {
+			return errors.New("redaction rule name cannot be empty")
+		}
+		if _, ok := names[r.Name]; ok {
+			return fmt.Errorf("duplicate redaction rule %q", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("invalid redaction rule %q: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *PIIRedactionRule) validate() error {
+	if r.Detector == "" {
+		return errors.New("the detector cannot be empty")
+	}
+
+	switch r.Action {
+	case "", PIIRedactionActionMask:
+	case PIIRedactionActionHash:
+		if r.HashKey.String() == "" {
+			return errors.New("the hash action requires a hash_key")
+		}
+	default:
+		return fmt.Errorf("unsupported action %q: supported actions are %s and %s", r.Action, PIIRedactionActionMask, PIIRedactionActionHash)
+	}
+
+	switch r.Target {
+	case "", PIIRedactionTargetAll, PIIRedactionTargetLine, PIIRedactionTargetStructuredMetadata:
+	default:
+		return fmt.Errorf("unsupported target %q: supported targets are %s, %s and %s", r.Target, PIIRedactionTargetAll, PIIRedactionTargetLine, PIIRedactionTargetStructuredMetadata)
+	}
+
+	for _, path := range r.JSONPaths {
+		if path == "" {
+			return errors.New("JSON paths cannot be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPIIRedactionConfig_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		rules       []PIIRedactionRule
+		expectedErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			rules: []PIIRedactionRule{
+				{Name: "cards", Detector: "credit_card"},
+				{Name: "ids", Detector: "regex", Regex: `id-\d+`, Action: PIIRedactionActionHash, HashKey: flagext.SecretWithValue("key"), Target: PIIRedactionTargetLine},
+			},
+		},
+		{
+			name:        "hash without key",
+			rules:       []PIIRedactionRule{{Name: "a", Detector: "email", Action: PIIRedactionActionHash}},
+			expectedErr: "the hash action requires a hash_key",
+		},
+		{
+			name:        "missing name",
+			rules:       []PIIRedactionRule{{Detector: "email"}},
+			expectedErr: "name cannot be empty",
+		},
+		{
+			name:        "duplicate name",
+			rules:       []PIIRedactionRule{{Name: "a", Detector: "email"}, {Name: "a", Detector: "secrets"}},
+			expectedErr: `duplicate redaction rule "a"`,
+		},
+		{
+			name:        "missing detector",
+			rules:       []PIIRedactionRule{{Name: "a"}},
+			expectedErr: "the detector cannot be empty",
+		},
+		{
+			name:        "unsupported action",
+			rules:       []PIIRedactionRule{{Name: "a", Detector: "email", Action: "drop"}},
+			expectedErr: `unsupported action "drop"`,
+		},
+		{
+			name:        "unsupported target",
+			rules:       []PIIRedactionRule{{Name: "a", Detector: "email", Target: "labels"}},
+			expectedErr: `unsupported target "labels"`,
+		},
+		{
+			name:        "empty JSON path",
+			rules:       []PIIRedactionRule{{Name: "a", Detector: "email", JSONPaths: []string{""}}},
+			expectedErr: "JSON paths cannot be empty",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := PIIRedactionConfig{Rules: tc.rules}
+			err := cfg.Validate()
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
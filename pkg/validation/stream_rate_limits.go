@@ -0,0 +1,141 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/util/flagext"
+)
+
+const (
+	// StreamRateLimitOverflowReject rejects the lines above the rate limit with a 429.
+	StreamRateLimitOverflowReject = "reject"
+	// StreamRateLimitOverflowSample accepts the lines of a stream above the rate limit with
+	// the probability of the limit over the rate of the stream, and discards the other ones.
+	// The sampled lines are charged to the rate limit, so the stream is sampled down to it.
+	StreamRateLimitOverflowSample = "sample"
+	// StreamRateLimitOverflowPriority accepts the lines above the rate limit matching the
+	// priority filter, up to the priority rate limit, and discards the other ones.
+	StreamRateLimitOverflowPriority = "priority"
+)
+
+// StreamRateLimitRule is a per-stream rate limit applied to the streams matching a selector.
+type StreamRateLimitRule struct {
+	Name              string           `yaml:"name" json:"name" doc:"description=Name of the rule, used in the metrics of the rate limited lines."`
+	Selector          string           `yaml:"selector" json:"selector" doc:"description=Stream selector of the streams the rule applies to. Defaults to all the streams."`
+	Priority          int              `yaml:"priority" json:"priority" doc:"description=The larger the value, the higher the priority. The rule with the highest priority applies to a stream matching multiple rules."`
+	RateLimit         flagext.ByteSize `yaml:"rate_limit" json:"rate_limit" doc:"description=Maximum byte rate per second of each stream matching the selector. Defaults to the per_stream_rate_limit of the tenant."`
+	RateLimitBurst    flagext.ByteSize `yaml:"rate_limit_burst" json:"rate_limit_burst" doc:"description=Maximum burst bytes of each stream matching the selector. Defaults to the per_stream_rate_limit_burst of the tenant."`
+	Overflow          string           `yaml:"overflow" json:"overflow" doc:"description=Behaviour for the lines above the rate limit: reject to reject them, sample to accept them with the probability of the rate limit over the rate of the stream and discard the other ones, so that the accepted lines add up to the rate limit, priority to only accept the ones matching the priority filter, up to priority_rate_limit. Discarded lines are not reported as errors to the clients. With sample, the lines above the limit are discarded during the first second of the stream, until its rate is measured. Defaults to reject."`
+	PriorityFilter    string           `yaml:"priority_filter" json:"priority_filter" doc:"description=LogQL line filter expression matching the lines accepted above the rate limit, with the priority overflow."`
+	PriorityRateLimit flagext.ByteSize `yaml:"priority_rate_limit" json:"priority_rate_limit" doc:"description=Maximum byte rate per second of the lines matching the priority filter accepted above the rate limit of each stream, with the priority overflow. Its burst is the one of the rate limit. Defaults to the rate limit of the stream."`
+
+	Matchers       []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+	priorityFilter log.Filterer
+}
+
+// Limit returns the rate limit of the rule, which defaults to the one of the tenant.
+func (r *StreamRateLimitRule) Limit(defaults RateLimit) RateLimit {
+	limit := defaults
+	if r.RateLimit > 0 {
+		limit.Limit = rate.Limit(float64(r.RateLimit.Val()))
+	}
+	if r.RateLimitBurst > 0 {
+		limit.Burst = r.RateLimitBurst.Val()
+	}
+	return limit
+}
+
+// PriorityLimit returns the rate limit of the lines matching the priority filter above the
+// rate limit of the stream, which defaults to this rate limit.
+func (r *StreamRateLimitRule) PriorityLimit(limit RateLimit) RateLimit {
+	if r.PriorityRateLimit > 0 {
+		limit.Limit = rate.Limit(float64(r.PriorityRateLimit.Val()))
+	}
+	return limit
+}
+
+// OverflowBehaviour returns the behaviour of the rule for the lines above the rate limit.
+func (r *StreamRateLimitRule) OverflowBehaviour() string {
+	if r.Overflow == "" {
+		return StreamRateLimitOverflowReject
+	}
+	return r.Overflow
+}
+
+// IsPriorityLine returns whether a line matches the priority filter of the rule.
+func (r *StreamRateLimitRule) IsPriorityLine(line []byte) bool {
+	return r.priorityFilter != nil && r.priorityFilter.Filter(line)
+}
+
+// Matches returns whether the rule applies to a stream.
+func (r *StreamRateLimitRule) Matches(lbs labels.Labels) bool {
+	for _, m := range r.Matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+type StreamRateLimitRules []StreamRateLimitRule
+
+// Validate validates the rules and compiles their selectors and priority filters.
+func (r StreamRateLimitRules) Validate() error {
+	names := make(map[string]struct{}, len(r))
+	for i, rule := range r {
+		if rule.Name == "" {
+			return errors.New("stream rate limit rule name cannot be empty")
+		}
+		if _, ok := names[rule.Name]; ok {
+			return fmt.Errorf("duplicate stream rate limit rule %q", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+
+		if rule.Selector != "" {
+			matchers, err := syntax.ParseMatchers(rule.Selector, true)
+			if err != nil {
+				return fmt.Errorf("invalid selector for stream rate limit rule %q: %w", rule.Name, err)
+			}
+			r[i].Matchers = matchers
+		}
+
+		switch rule.OverflowBehaviour() {
+		case StreamRateLimitOverflowReject, StreamRateLimitOverflowSample:
+			if rule.PriorityFilter != "" {
+				return fmt.Errorf("priority filter of stream rate limit rule %q requires the %s overflow", rule.Name, StreamRateLimitOverflowPriority)
+			}
+			if rule.PriorityRateLimit > 0 {
+				return fmt.Errorf("priority rate limit of stream rate limit rule %q requires the %s overflow", rule.Name, StreamRateLimitOverflowPriority)
+			}
+		case StreamRateLimitOverflowPriority:
+			filter, err := parseLineFilters(rule.PriorityFilter)
+			if err != nil {
+				return fmt.Errorf("invalid priority filter for stream rate limit rule %q: %w", rule.Name, err)
+			}
+			r[i].priorityFilter = filter
+		default:
+			return fmt.Errorf("unsupported overflow %q for stream rate limit rule %q: supported values are %s, %s and %s", rule.Overflow, rule.Name, StreamRateLimitOverflowReject, StreamRateLimitOverflowSample, StreamRateLimitOverflowPriority)
+		}
+	}
+	return nil
+}
+
+// Match returns the rule with the highest priority matching a stream, or nil.
+func (r StreamRateLimitRules) Match(lbs labels.Labels) *StreamRateLimitRule {
+	var matched *StreamRateLimitRule
+	for i := range r {
+		if !r[i].Matches(lbs) {
+			continue
+		}
+		if matched == nil || r[i].Priority > matched.Priority {
+			matched = &r[i]
+		}
+	}
+	return matched
+}
@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestStreamRateLimitRules_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		rules       StreamRateLimitRules
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			rules: StreamRateLimitRules{
+				{Name: "all"},
+				{Name: "debug", Selector: `{env="dev"}`, Overflow: StreamRateLimitOverflowSample},
+				{Name: "payments", Selector: `{app="payments"}`, Overflow: StreamRateLimitOverflowPriority, PriorityFilter: `|~ "(?i)error"`},
+			},
+		},
+		{
+			name:        "missing name",
+			rules:       StreamRateLimitRules{{Selector: `{env="dev"}`}},
+			expectedErr: "name cannot be empty",
+		},
+		{
+			name:        "duplicate name",
+			rules:       StreamRateLimitRules{{Name: "a"}, {Name: "a"}},
+			expectedErr: `duplicate stream rate limit rule "a"`,
+		},
+		{
+			name:        "invalid selector",
+			rules:       StreamRateLimitRules{{Name: "a", Selector: `{env=}`}},
+			expectedErr: "invalid selector",
+		},
+		{
+			name:        "unsupported overflow",
+			rules:       StreamRateLimitRules{{Name: "a", Overflow: "drop"}},
+			expectedErr: `unsupported overflow "drop"`,
+		},
+		{
+			name:        "missing priority filter",
+			rules:       StreamRateLimitRules{{Name: "a", Overflow: StreamRateLimitOverflowPriority}},
+			expectedErr: "invalid priority filter",
+		},
+		{
+			name:        "priority filter without priority overflow",
+			rules:       StreamRateLimitRules{{Name: "a", PriorityFilter: `|= "error"`}},
+			expectedErr: "requires the priority overflow",
+		},
+		{
+			name:        "priority rate limit without priority overflow",
+			rules:       StreamRateLimitRules{{Name: "a", Overflow: StreamRateLimitOverflowSample, PriorityRateLimit: 10}},
+			expectedErr: "priority rate limit of stream rate limit rule \"a\" requires the priority overflow",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rules.Validate()
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestStreamRateLimitRules_Match(t *testing.T) {
+	rules := StreamRateLimitRules{
+		{Name: "dev", Selector: `{env="dev"}`, RateLimit: 100},
+		{Name: "dev-api", Selector: `{env="dev", app="api"}`, Priority: 1, RateLimitBurst: 200},
+		{Name: "payments", Selector: `{app="payments"}`, Overflow: StreamRateLimitOverflowPriority, PriorityFilter: `|= "error"`, PriorityRateLimit: 5},
+	}
+	require.NoError(t, rules.Validate())
+
+	require.Nil(t, rules.Match(labels.FromStrings("env", "prod")))
+	require.Equal(t, "dev", rules.Match(labels.FromStrings("env", "dev")).Name)
+	require.Equal(t, "dev-api", rules.Match(labels.FromStrings("env", "dev", "app", "api")).Name)
+
+	defaults := RateLimit{Limit: 10, Burst: 20}
+	require.Equal(t, RateLimit{Limit: rate.Limit(100), Burst: 20}, rules[0].Limit(defaults))
+	require.Equal(t, RateLimit{Limit: 10, Burst: 200}, rules[1].Limit(defaults))
+	require.Equal(t, RateLimit{Limit: rate.Limit(5), Burst: 20}, rules[2].PriorityLimit(defaults))
+	require.Equal(t, defaults, rules[1].PriorityLimit(defaults))
+
+	require.Equal(t, StreamRateLimitOverflowReject, rules[0].OverflowBehaviour())
+	require.True(t, rules[2].IsPriorityLine([]byte("an error")))
+	require.False(t, rules[2].IsPriorityLine([]byte("info")))
+}
@@ -24,6 +24,10 @@ const (
 	// Declared here to avoid duplication in ingester and distributor.
 	RateLimited         = "rate_limited"
 	RateLimitedErrorMsg = "Ingestion rate limit exceeded for user %s (limit: %d bytes/sec) while attempting to ingest '%d' lines totaling '%d' bytes, reduce log volume or contact your Loki administrator to see if the limit can be increased"
+	// PolicyRateLimited is a reason for discarding samples when the ingestion rate limit
+	// of the policy of a stream is exceeded.
+	PolicyRateLimited         = "policy_rate_limited"
+	PolicyRateLimitedErrorMsg = "Ingestion rate limit exceeded for policy %s of user %s (limit: %d bytes/sec) while attempting to ingest '%d' lines totaling '%d' bytes, reduce log volume or contact your Loki administrator to see if the limit can be increased"
 	// LineTooLong is a reason for discarding too long log lines.
 	LineTooLong         = "line_too_long"
 	LineTooLongErrorMsg = "Max entry size '%d' bytes exceeded for stream '%s' while adding an entry with length '%d' bytes"
@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+const (
+	WebhookTeeFormatLoki = "loki"
+	WebhookTeeFormatJSON = "json"
+)
+
+// WebhookTeeTarget is a downstream the streams of a tenant matching a selector are forwarded to.
+type WebhookTeeTarget struct {
+	Name     string                    `yaml:"name" json:"name" doc:"description=Name of the target, used in the metrics of the forwarded streams."`
+	URL      string                    `yaml:"url" json:"url" doc:"description=URL the streams are pushed to."`
+	Format   string                    `yaml:"format" json:"format" doc:"description=Format of the pushed streams: loki for the snappy compressed protobuf Loki push protocol, json for a JSON webhook. Defaults to loki."`
+	Selector string                    `yaml:"selector" json:"selector" doc:"description=Stream selector of the forwarded streams."`
+	TenantID string                    `yaml:"tenant_id" json:"tenant_id" doc:"description=Tenant ID sent in the X-Scope-OrgID header. Defaults to the tenant of the streams."`
+	Headers  map[string]flagext.Secret `yaml:"headers" json:"headers" doc:"description=Additional HTTP headers sent with the requests, such as an Authorization header. Their values are secrets, which are redacted when the configuration is printed."`
+
+	Matchers []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+}
+
+func (t *WebhookTeeTarget) Matches(lbs labels.Labels) bool {
+	for _, m := range t.Matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+type WebhookTeeTargets []WebhookTeeTarget
+
+func (t WebhookTeeTargets) Validate() error {
+	names := make(map[string]struct{}, len(t))
+	for i, target := range t {
+		if target.Name == "" {
+			return errors.New("webhook tee target name cannot be empty")
+		}
+		if _, ok := names[target.Name]; ok {
+			return fmt.Errorf("duplicate webhook tee target %q", target.Name)
+		}
+		names[target.Name] = struct{}{}
+
+		u, err := url.Parse(target.URL)
+		if err != nil {
+			return fmt.Errorf("invalid URL for webhook tee target %q: %w", target.Name, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid URL for webhook tee target %q: an absolute http or https URL is required", target.Name)
+		}
+
+		switch target.Format {
+		case "", WebhookTeeFormatLoki, WebhookTeeFormatJSON:
+		default:
+			return fmt.Errorf("unsupported format %q for webhook tee target %q: supported formats are %s and %s", target.Format, target.Name, WebhookTeeFormatLoki, WebhookTeeFormatJSON)
+		}
+
+		matchers, err := syntax.ParseMatchers(target.Selector, true)
+		if err != nil {
+			return fmt.Errorf("invalid selector for webhook tee target %q: %w", target.Name, err)
+		}
+		t[i].Matchers = matchers
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package querytee
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+)
+
+// capturedHeadersDenylist lists the request headers which are never written to disk.
+var capturedHeadersDenylist = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// CapturedRequest is a request received by the proxy, as written to disk when the
+// responses of its backends did not match.
+type CapturedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// CapturedResponse is the response of a single backend to a captured request.
+type CapturedResponse struct {
+	Backend string `json:"backend"`
+	Status  int    `json:"status"`
+	Body    string `json:"body,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CapturedMismatch is a request whose response from a secondary backend did not match
+// the response from the preferred backend.
+type CapturedMismatch struct {
+	Time     time.Time        `json:"time"`
+	Route    string           `json:"route"`
+	Request  CapturedRequest  `json:"request"`
+	Expected CapturedResponse `json:"expected"`
+	Actual   CapturedResponse `json:"actual"`
+	Error    string           `json:"error"`
+}
+
+func newCapturedRequest(r *http.Request, body []byte) CapturedRequest {
+	header := r.Header.Clone()
+	for _, name := range capturedHeadersDenylist {
+		header.Del(name)
+	}
+
+	return CapturedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: header,
+		Body:   string(body),
+	}
+}
+
+func newCapturedResponse(res *backendResponse) CapturedResponse {
+	captured := CapturedResponse{
+		Backend: res.backend.name,
+		Status:  res.status,
+		Body:    string(res.body),
+	}
+	if res.err != nil {
+		captured.Error = res.err.Error()
+	}
+	return captured
+}
+
+// MismatchCapturer writes mismatching request/response pairs to a directory, one JSON
+// file per mismatch, so they can be inspected and replayed later.
+type MismatchCapturer struct {
+	dir      string
+	maxFiles int64
+	written  atomic.Int64
+}
+
+// NewMismatchCapturer creates the capture directory if needed. No more than maxFiles
+// mismatches are written by the capturer; 0 means no limit.
+func NewMismatchCapturer(dir string, maxFiles int) (*MismatchCapturer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "creating mismatch capture directory")
+	}
+	return &MismatchCapturer{
+		dir:      dir,
+		maxFiles: int64(maxFiles),
+	}, nil
+}
+
+// Capture writes a mismatch to disk. It returns false without writing anything once the
+// maximum number of captured mismatches has been reached.
+func (c *MismatchCapturer) Capture(m CapturedMismatch) (bool, error) {
+	if n := c.written.Inc(); c.maxFiles > 0 && n > c.maxFiles {
+		c.written.Dec()
+		return false, nil
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return false, err
+	}
+
+	name := fmt.Sprintf("%d-%s-%s.json", m.Time.UnixNano(), m.Route, m.Actual.Backend)
+	name = unsafeFileNameChars.ReplaceAllString(name, "_")
+
+	// Write to a temporary file first so that a replay never reads a partial capture.
+	tmp := filepath.Join(c.dir, "."+name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, filepath.Join(c.dir, name)); err != nil {
+		_ = os.Remove(tmp)
+		return false, err
+	}
+	return true, nil
+}
+
+// ReadCapturedMismatches reads all the mismatches captured in dir, ordered by capture time.
+func ReadCapturedMismatches(dir string) ([]CapturedMismatch, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches := make([]CapturedMismatch, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var m CapturedMismatch
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, errors.Wrapf(err, "unable to unmarshal captured mismatch %s", file)
+		}
+		mismatches = append(mismatches, m)
+	}
+
+	sort.SliceStable(mismatches, func(i, j int) bool { return mismatches[i].Time.Before(mismatches[j].Time) })
+	return mismatches, nil
+}
@@ -0,0 +1,272 @@
+package querytee
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+// maxReportedDifferences limits how many missing or unexpected values are listed in a comparison error.
+const maxReportedDifferences = 10
+
+// LabelsComparator compares responses of the label names and label values routes.
+// The order of the values is ignored.
+type LabelsComparator struct{}
+
+func NewLabelsComparator() *LabelsComparator {
+	return &LabelsComparator{}
+}
+
+func (c *LabelsComparator) Compare(expectedResponse, actualResponse []byte, _ time.Time) (*ComparisonSummary, error) {
+	var expected, actual loghttp.LabelResponse
+
+	if err := json.Unmarshal(expectedResponse, &expected); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal expected response")
+	}
+	if err := json.Unmarshal(actualResponse, &actual); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal actual response")
+	}
+
+	if expected.Status != actual.Status {
+		return nil, fmt.Errorf("expected status %s but got %s", expected.Status, actual.Status)
+	}
+
+	return compareValueSets("label values", expected.Data, actual.Data)
+}
+
+// SeriesComparator compares responses of the series route. The order of the series is ignored.
+type SeriesComparator struct{}
+
+func NewSeriesComparator() *SeriesComparator {
+	return &SeriesComparator{}
+}
+
+func (c *SeriesComparator) Compare(expectedResponse, actualResponse []byte, _ time.Time) (*ComparisonSummary, error) {
+	var expected, actual loghttp.SeriesResponse
+
+	if err := json.Unmarshal(expectedResponse, &expected); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal expected response")
+	}
+	if err := json.Unmarshal(actualResponse, &actual); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal actual response")
+	}
+
+	if expected.Status != actual.Status {
+		return nil, fmt.Errorf("expected status %s but got %s", expected.Status, actual.Status)
+	}
+
+	return compareValueSets("series", labelSetsToStrings(expected.Data), labelSetsToStrings(actual.Data))
+}
+
+func labelSetsToStrings(sets []loghttp.LabelSet) []string {
+	res := make([]string, 0, len(sets))
+	for _, s := range sets {
+		res = append(res, s.String())
+	}
+	return res
+}
+
+// compareValueSets compares two lists of values ignoring their order and reports the
+// values missing from, or unexpectedly present in, the actual list.
+func compareValueSets(kind string, expected, actual []string) (*ComparisonSummary, error) {
+	expectedSet := make(map[string]struct{}, len(expected))
+	for _, v := range expected {
+		expectedSet[v] = struct{}{}
+	}
+	actualSet := make(map[string]struct{}, len(actual))
+	for _, v := range actual {
+		actualSet[v] = struct{}{}
+	}
+
+	var missing, unexpected []string
+	for v := range expectedSet {
+		if _, ok := actualSet[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	for v := range actualSet {
+		if _, ok := expectedSet[v]; !ok {
+			unexpected = append(unexpected, v)
+		}
+	}
+
+	summary := &ComparisonSummary{missingMetrics: len(missing)}
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return summary, nil
+	}
+
+	var msgs []string
+	if len(missing) > 0 {
+		msgs = append(msgs, fmt.Sprintf("expected %s [%s] missing from actual response", kind, formatDifferences(missing)))
+	}
+	if len(unexpected) > 0 {
+		msgs = append(msgs, fmt.Sprintf("unexpected %s [%s] in actual response", kind, formatDifferences(unexpected)))
+	}
+	return summary, errors.New(strings.Join(msgs, "; "))
+}
+
+func formatDifferences(values []string) string {
+	sort.Strings(values)
+	if len(values) > maxReportedDifferences {
+		return fmt.Sprintf("%s, and %d more", strings.Join(values[:maxReportedDifferences], ", "), len(values)-maxReportedDifferences)
+	}
+	return strings.Join(values, ", ")
+}
+
+type patternsResponse struct {
+	Status string `json:"status"`
+	Data   []struct {
+		Pattern string     `json:"pattern"`
+		Samples [][2]int64 `json:"samples"`
+	} `json:"data"`
+}
+
+// PatternsComparator compares responses of the patterns route. Samples outside of the
+// comparable window are ignored, as are patterns without any sample left.
+type PatternsComparator struct {
+	opts SampleComparisonOptions
+}
+
+func NewPatternsComparator(opts SampleComparisonOptions) *PatternsComparator {
+	return &PatternsComparator{opts: opts}
+}
+
+func (c *PatternsComparator) Compare(expectedResponse, actualResponse []byte, evaluationTime time.Time) (*ComparisonSummary, error) {
+	var expected, actual patternsResponse
+
+	if err := json.Unmarshal(expectedResponse, &expected); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal expected response")
+	}
+	if err := json.Unmarshal(actualResponse, &actual); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal actual response")
+	}
+
+	if expected.Status != actual.Status {
+		return nil, fmt.Errorf("expected status %s but got %s", expected.Status, actual.Status)
+	}
+
+	expectedPatterns := c.patternSamples(expected, evaluationTime)
+	actualPatterns := c.patternSamples(actual, evaluationTime)
+	if len(expectedPatterns) == 0 && len(actualPatterns) == 0 {
+		return &ComparisonSummary{skipped: true}, nil
+	}
+
+	summary, err := compareValueSets("patterns", mapKeys(expectedPatterns), mapKeys(actualPatterns))
+	if err != nil {
+		return summary, err
+	}
+
+	for pattern, expectedSamples := range expectedPatterns {
+		actualSamples := actualPatterns[pattern]
+		if len(expectedSamples) != len(actualSamples) {
+			return nil, fmt.Errorf("expected %d samples for pattern %q but got %d", len(expectedSamples), pattern, len(actualSamples))
+		}
+		for i := range expectedSamples {
+			if expectedSamples[i] != actualSamples[i] {
+				return nil, fmt.Errorf("expected sample %v for pattern %q but got %v", expectedSamples[i], pattern, actualSamples[i])
+			}
+		}
+	}
+	return summary, nil
+}
+
+// patternSamples returns the samples of each pattern within the comparable window.
+func (c *PatternsComparator) patternSamples(res patternsResponse, evaluationTime time.Time) map[string][][2]int64 {
+	patterns := make(map[string][][2]int64, len(res.Data))
+	for _, p := range res.Data {
+		var samples [][2]int64
+		for _, s := range p.Samples {
+			if !c.opts.SkipSample(time.Unix(s[0], 0), evaluationTime) {
+				samples = append(samples, s)
+			}
+		}
+		if len(samples) > 0 {
+			patterns[p.Pattern] = append(patterns[p.Pattern], samples...)
+		}
+	}
+	for _, samples := range patterns {
+		sort.Slice(samples, func(i, j int) bool { return samples[i][0] < samples[j][0] })
+	}
+	return patterns
+}
+
+type detectedFieldsResponse struct {
+	Fields []struct {
+		Label       string   `json:"label"`
+		Type        string   `json:"type"`
+		Cardinality uint64   `json:"cardinality"`
+		Parsers     []string `json:"parsers"`
+	} `json:"fields"`
+}
+
+// DetectedFieldsComparator compares responses of the detected fields route. Cardinalities are
+// estimates, so they are compared using the configured value tolerance.
+type DetectedFieldsComparator struct {
+	opts SampleComparisonOptions
+}
+
+func NewDetectedFieldsComparator(opts SampleComparisonOptions) *DetectedFieldsComparator {
+	return &DetectedFieldsComparator{opts: opts}
+}
+
+func (c *DetectedFieldsComparator) Compare(expectedResponse, actualResponse []byte, _ time.Time) (*ComparisonSummary, error) {
+	var expected, actual detectedFieldsResponse
+
+	if err := json.Unmarshal(expectedResponse, &expected); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal expected response")
+	}
+	if err := json.Unmarshal(actualResponse, &actual); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal actual response")
+	}
+
+	expectedLabels := make([]string, 0, len(expected.Fields))
+	for _, f := range expected.Fields {
+		expectedLabels = append(expectedLabels, f.Label)
+	}
+	actualFields := make(map[string]int, len(actual.Fields))
+	actualLabels := make([]string, 0, len(actual.Fields))
+	for i, f := range actual.Fields {
+		actualFields[f.Label] = i
+		actualLabels = append(actualLabels, f.Label)
+	}
+
+	summary, err := compareValueSets("fields", expectedLabels, actualLabels)
+	if err != nil {
+		return summary, err
+	}
+
+	for _, e := range expected.Fields {
+		a := actual.Fields[actualFields[e.Label]]
+		if e.Type != a.Type {
+			return nil, fmt.Errorf("expected type %s for field %s but got %s", e.Type, e.Label, a.Type)
+		}
+
+		expectedParsers := append([]string(nil), e.Parsers...)
+		actualParsers := append([]string(nil), a.Parsers...)
+		sort.Strings(expectedParsers)
+		sort.Strings(actualParsers)
+		if strings.Join(expectedParsers, ",") != strings.Join(actualParsers, ",") {
+			return nil, fmt.Errorf("expected parsers [%s] for field %s but got [%s]", strings.Join(expectedParsers, ", "), e.Label, strings.Join(actualParsers, ", "))
+		}
+
+		if !compareSampleValue(model.SampleValue(e.Cardinality), model.SampleValue(a.Cardinality), c.opts) {
+			return nil, fmt.Errorf("expected cardinality %d for field %s but got %d", e.Cardinality, e.Label, a.Cardinality)
+		}
+	}
+	return summary, nil
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
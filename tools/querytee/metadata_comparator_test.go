@@ -0,0 +1,180 @@
+package querytee
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelsComparator(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected string
+		actual   string
+		err      error
+	}{
+		{
+			name:     "same values",
+			expected: `{"status":"success","data":["bar","foo"]}`,
+			actual:   `{"status":"success","data":["bar","foo"]}`,
+		},
+		{
+			name:     "same values in different order",
+			expected: `{"status":"success","data":["bar","foo"]}`,
+			actual:   `{"status":"success","data":["foo","bar"]}`,
+		},
+		{
+			name:     "difference in status",
+			expected: `{"status":"success","data":["foo"]}`,
+			actual:   `{"status":"error"}`,
+			err:      errors.New("expected status success but got error"),
+		},
+		{
+			name:     "missing and unexpected values",
+			expected: `{"status":"success","data":["bar","baz","foo"]}`,
+			actual:   `{"status":"success","data":["foo","qux"]}`,
+			err:      errors.New("expected label values [bar, baz] missing from actual response; unexpected label values [qux] in actual response"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewLabelsComparator().Compare([]byte(tc.expected), []byte(tc.actual), time.Now())
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err.Error())
+		})
+	}
+}
+
+func TestSeriesComparator(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected string
+		actual   string
+		err      error
+	}{
+		{
+			name:     "same series in different order",
+			expected: `{"status":"success","data":[{"app":"foo","env":"prod"},{"app":"bar"}]}`,
+			actual:   `{"status":"success","data":[{"app":"bar"},{"env":"prod","app":"foo"}]}`,
+		},
+		{
+			name:     "missing series",
+			expected: `{"status":"success","data":[{"app":"foo"},{"app":"bar"}]}`,
+			actual:   `{"status":"success","data":[{"app":"bar"}]}`,
+			err:      errors.New(`expected series [{app="foo"}] missing from actual response`),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewSeriesComparator().Compare([]byte(tc.expected), []byte(tc.actual), time.Now())
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err.Error())
+		})
+	}
+}
+
+func TestPatternsComparator(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		expected          string
+		actual            string
+		skipSamplesBefore time.Time
+		err               error
+	}{
+		{
+			name:     "same patterns in different order",
+			expected: `{"status":"success","data":[{"pattern":"foo <_>","samples":[[10,1],[20,2]]},{"pattern":"bar <_>","samples":[[10,3]]}]}`,
+			actual:   `{"status":"success","data":[{"pattern":"bar <_>","samples":[[10,3]]},{"pattern":"foo <_>","samples":[[10,1],[20,2]]}]}`,
+		},
+		{
+			name:     "missing pattern",
+			expected: `{"status":"success","data":[{"pattern":"foo <_>","samples":[[10,1]]},{"pattern":"bar <_>","samples":[[10,3]]}]}`,
+			actual:   `{"status":"success","data":[{"pattern":"foo <_>","samples":[[10,1]]}]}`,
+			err:      errors.New("expected patterns [bar <_>] missing from actual response"),
+		},
+		{
+			name:     "difference in samples",
+			expected: `{"status":"success","data":[{"pattern":"foo <_>","samples":[[10,1],[20,2]]}]}`,
+			actual:   `{"status":"success","data":[{"pattern":"foo <_>","samples":[[10,1],[20,3]]}]}`,
+			err:      errors.New(`expected sample [20 2] for pattern "foo <_>" but got [20 3]`),
+		},
+		{
+			name:              "samples outside the comparable window are ignored",
+			expected:          `{"status":"success","data":[{"pattern":"foo <_>","samples":[[10,1],[20,2]]},{"pattern":"bar <_>","samples":[[10,3]]}]}`,
+			actual:            `{"status":"success","data":[{"pattern":"foo <_>","samples":[[10,5],[20,2]]}]}`,
+			skipSamplesBefore: time.Unix(15, 0),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			comparator := NewPatternsComparator(SampleComparisonOptions{SkipSamplesBefore: tc.skipSamplesBefore})
+			_, err := comparator.Compare([]byte(tc.expected), []byte(tc.actual), time.Now())
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err.Error())
+		})
+	}
+}
+
+func TestDetectedFieldsComparator(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		expected  string
+		actual    string
+		tolerance float64
+		err       error
+	}{
+		{
+			name:     "same fields in different order",
+			expected: `{"fields":[{"label":"duration","type":"duration","cardinality":10,"parsers":["logfmt","json"]},{"label":"level","type":"string","cardinality":3,"parsers":["logfmt"]}],"limit":100}`,
+			actual:   `{"fields":[{"label":"level","type":"string","cardinality":3,"parsers":["logfmt"]},{"label":"duration","type":"duration","cardinality":10,"parsers":["json","logfmt"]}],"limit":100}`,
+		},
+		{
+			name:     "missing field",
+			expected: `{"fields":[{"label":"level","type":"string","cardinality":3,"parsers":["logfmt"]}]}`,
+			actual:   `{"fields":[]}`,
+			err:      errors.New("expected fields [level] missing from actual response"),
+		},
+		{
+			name:     "difference in type",
+			expected: `{"fields":[{"label":"status","type":"int","cardinality":3,"parsers":["logfmt"]}]}`,
+			actual:   `{"fields":[{"label":"status","type":"string","cardinality":3,"parsers":["logfmt"]}]}`,
+			err:      errors.New("expected type int for field status but got string"),
+		},
+		{
+			name:     "difference in parsers",
+			expected: `{"fields":[{"label":"status","type":"int","cardinality":3,"parsers":["logfmt"]}]}`,
+			actual:   `{"fields":[{"label":"status","type":"int","cardinality":3,"parsers":["json"]}]}`,
+			err:      errors.New("expected parsers [logfmt] for field status but got [json]"),
+		},
+		{
+			name:     "difference in cardinality",
+			expected: `{"fields":[{"label":"status","type":"int","cardinality":3,"parsers":["logfmt"]}]}`,
+			actual:   `{"fields":[{"label":"status","type":"int","cardinality":4,"parsers":["logfmt"]}]}`,
+			err:      errors.New("expected cardinality 3 for field status but got 4"),
+		},
+		{
+			name:      "difference in cardinality within tolerance",
+			expected:  `{"fields":[{"label":"status","type":"int","cardinality":100,"parsers":["logfmt"]}]}`,
+			actual:    `{"fields":[{"label":"status","type":"int","cardinality":101,"parsers":["logfmt"]}]}`,
+			tolerance: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			comparator := NewDetectedFieldsComparator(SampleComparisonOptions{Tolerance: tc.tolerance})
+			_, err := comparator.Compare([]byte(tc.expected), []byte(tc.actual), time.Now())
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err.Error())
+		})
+	}
+}
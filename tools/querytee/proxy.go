@@ -38,6 +38,8 @@ type ProxyConfig struct {
 	SkipSamplesBefore              flagext.Time
 	RequestURLFilter               *regexp.Regexp
 	InstrumentCompares             bool
+	CaptureMismatchesDir           string
+	CaptureMismatchesMaxFiles      int
 }
 
 func (cfg *ProxyConfig) RegisterFlags(f *flag.FlagSet) {
@@ -58,6 +60,8 @@ func (cfg *ProxyConfig) RegisterFlags(f *flag.FlagSet) {
 		return err
 	})
 	f.BoolVar(&cfg.InstrumentCompares, "proxy.compare-instrument", false, "Reports metrics on comparisons of responses between preferred and non-preferred endpoints for supported routes.")
+	f.StringVar(&cfg.CaptureMismatchesDir, "proxy.capture-mismatches-dir", "", "Directory where requests whose responses do not match between the preferred and secondary endpoints are written to, together with the responses. Captured requests can be replayed with the replay command. Empty to disable.")
+	f.IntVar(&cfg.CaptureMismatchesMaxFiles, "proxy.capture-mismatches-max-files", 1000, "Maximum number of mismatches written to the capture directory by a query-tee process. 0 to disable the limit.")
 }
 
 type Route struct {
@@ -74,6 +78,7 @@ type Proxy struct {
 	metrics     *ProxyMetrics
 	readRoutes  []Route
 	writeRoutes []Route
+	capturer    *MismatchCapturer
 
 	// The HTTP server used to run the proxy service.
 	srv         *http.Server
@@ -96,6 +101,10 @@ func NewProxy(cfg ProxyConfig, logger log.Logger, readRoutes, writeRoutes []Rout
 		return nil, fmt.Errorf("when enabling instrumentation of comparisons of results -proxy.compare-responses flag must be set")
 	}
 
+	if cfg.CaptureMismatchesDir != "" && !cfg.CompareResponses {
+		return nil, fmt.Errorf("when enabling capture of mismatching responses -proxy.compare-responses flag must be set")
+	}
+
 	p := &Proxy{
 		cfg:         cfg,
 		logger:      logger,
@@ -104,7 +113,43 @@ func NewProxy(cfg ProxyConfig, logger log.Logger, readRoutes, writeRoutes []Rout
 		writeRoutes: writeRoutes,
 	}
 
-	// Parse the backend endpoints (comma separated).
+	var err error
+	p.backends, err = newProxyBackends(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.CompareResponses && len(p.backends) < 2 {
+		return nil, fmt.Errorf("when enabling comparison of results number of backends should be at least 2")
+	}
+
+	// At least 2 backends are suggested
+	if len(p.backends) < 2 {
+		level.Warn(p.logger).Log("msg", "The proxy is running with only 1 backend. At least 2 backends are required to fulfil the purpose of the proxy and compare results.")
+	}
+
+	if cfg.CaptureMismatchesDir != "" {
+		p.capturer, err = NewMismatchCapturer(cfg.CaptureMismatchesDir, cfg.CaptureMismatchesMaxFiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.DisableBackendReadProxy != "" {
+		readDisabledBackendHosts := strings.Split(p.cfg.DisableBackendReadProxy, ",")
+		for _, host := range readDisabledBackendHosts {
+			if host == cfg.PreferredBackend {
+				return nil, fmt.Errorf("the preferred backend cannot be disabled for reading")
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// newProxyBackends parses the comma separated backend endpoints of the config.
+func newProxyBackends(cfg ProxyConfig) ([]*ProxyBackend, error) {
+	var backends []*ProxyBackend
 	parts := strings.Split(cfg.BackendEndpoints, ",")
 
 	for idx, part := range parts {
@@ -130,18 +175,18 @@ func NewProxy(cfg ProxyConfig, logger log.Logger, readRoutes, writeRoutes []Rout
 			preferred = preferredIdx == idx
 		}
 
-		p.backends = append(p.backends, NewProxyBackend(name, u, cfg.BackendReadTimeout, preferred))
+		backends = append(backends, NewProxyBackend(name, u, cfg.BackendReadTimeout, preferred))
 	}
 
 	// At least 1 backend is required
-	if len(p.backends) < 1 {
+	if len(backends) < 1 {
 		return nil, errMinBackends
 	}
 
 	// If the preferred backend is configured, then it must exists among the actual backends.
 	if cfg.PreferredBackend != "" {
 		exists := false
-		for _, b := range p.backends {
+		for _, b := range backends {
 			if b.preferred {
 				exists = true
 				break
@@ -153,25 +198,7 @@ func NewProxy(cfg ProxyConfig, logger log.Logger, readRoutes, writeRoutes []Rout
 		}
 	}
 
-	if cfg.CompareResponses && len(p.backends) < 2 {
-		return nil, fmt.Errorf("when enabling comparison of results number of backends should be at least 2")
-	}
-
-	// At least 2 backends are suggested
-	if len(p.backends) < 2 {
-		level.Warn(p.logger).Log("msg", "The proxy is running with only 1 backend. At least 2 backends are required to fulfil the purpose of the proxy and compare results.")
-	}
-
-	if cfg.DisableBackendReadProxy != "" {
-		readDisabledBackendHosts := strings.Split(p.cfg.DisableBackendReadProxy, ",")
-		for _, host := range readDisabledBackendHosts {
-			if host == cfg.PreferredBackend {
-				return nil, fmt.Errorf("the preferred backend cannot be disabled for reading")
-			}
-		}
-	}
-
-	return p, nil
+	return backends, nil
 }
 
 func (p *Proxy) Start() error {
@@ -194,7 +221,11 @@ func (p *Proxy) Start() error {
 		if p.cfg.CompareResponses {
 			comparator = route.ResponseComparator
 		}
-		router.Path(route.Path).Methods(route.Methods...).Handler(NewProxyEndpoint(filterReadDisabledBackends(p.backends, p.cfg.DisableBackendReadProxy), route.RouteName, p.metrics, p.logger, comparator, p.cfg.InstrumentCompares))
+		endpoint := NewProxyEndpoint(filterReadDisabledBackends(p.backends, p.cfg.DisableBackendReadProxy), route.RouteName, p.metrics, p.logger, comparator, p.cfg.InstrumentCompares)
+		if comparator != nil && p.capturer != nil {
+			endpoint = endpoint.WithMismatchCapturer(p.capturer)
+		}
+		router.Path(route.Path).Methods(route.Methods...).Handler(endpoint)
 	}
 
 	for _, route := range p.writeRoutes {
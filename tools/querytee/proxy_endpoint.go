@@ -28,6 +28,7 @@ type ProxyEndpoint struct {
 	metrics    *ProxyMetrics
 	logger     log.Logger
 	comparator ResponsesComparator
+	capturer   *MismatchCapturer
 
 	instrumentCompares bool
 
@@ -58,6 +59,12 @@ func NewProxyEndpoint(backends []*ProxyBackend, routeName string, metrics *Proxy
 	}
 }
 
+// WithMismatchCapturer makes the endpoint write the requests whose responses do not match to disk.
+func (p *ProxyEndpoint) WithMismatchCapturer(c *MismatchCapturer) *ProxyEndpoint {
+	p.capturer = c
+	return p
+}
+
 func (p *ProxyEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Send the same request to all backends.
 	resCh := make(chan *backendResponse, len(p.backends))
@@ -176,13 +183,18 @@ func (p *ProxyEndpoint) executeBackendRequests(r *http.Request, resCh chan *back
 			actualResponse := responses[i]
 
 			result := comparisonSuccess
-			summary, err := p.compareResponses(expectedResponse, actualResponse, time.Now().UTC())
+			now := time.Now().UTC()
+			summary, err := compareResponses(p.comparator, expectedResponse, actualResponse, now)
 			if err != nil {
 				level.Error(p.logger).Log("msg", "response comparison failed",
 					"backend-name", p.backends[i].name,
 					"route-name", p.routeName,
 					"query", r.URL.RawQuery, "err", err)
 				result = comparisonFailed
+
+				if p.capturer != nil {
+					p.captureMismatch(now, r, body, expectedResponse, actualResponse, err)
+				}
 			} else if summary != nil && summary.skipped {
 				result = comparisonSkipped
 			}
@@ -230,7 +242,25 @@ func (p *ProxyEndpoint) waitBackendResponseForDownstream(resCh chan *backendResp
 	return responses[0]
 }
 
-func (p *ProxyEndpoint) compareResponses(expectedResponse, actualResponse *backendResponse, queryEvalTime time.Time) (*ComparisonSummary, error) {
+func (p *ProxyEndpoint) captureMismatch(now time.Time, r *http.Request, body []byte, expectedResponse, actualResponse *backendResponse, compareErr error) {
+	captured, err := p.capturer.Capture(CapturedMismatch{
+		Time:     now,
+		Route:    p.routeName,
+		Request:  newCapturedRequest(r, body),
+		Expected: newCapturedResponse(expectedResponse),
+		Actual:   newCapturedResponse(actualResponse),
+		Error:    compareErr.Error(),
+	})
+	if err != nil {
+		level.Warn(p.logger).Log("msg", "Unable to capture mismatching responses", "route-name", p.routeName, "err", err)
+		return
+	}
+	if captured {
+		p.metrics.mismatchesCaptured.WithLabelValues(actualResponse.backend.name, p.routeName).Inc()
+	}
+}
+
+func compareResponses(comparator ResponsesComparator, expectedResponse, actualResponse *backendResponse, queryEvalTime time.Time) (*ComparisonSummary, error) {
 	if expectedResponse.err != nil {
 		return &ComparisonSummary{skipped: true}, nil
 	}
@@ -252,7 +282,7 @@ func (p *ProxyEndpoint) compareResponses(expectedResponse, actualResponse *backe
 		return nil, fmt.Errorf("expected status code %d but got %d", expectedResponse.status, actualResponse.status)
 	}
 
-	return p.comparator.Compare(expectedResponse.body, actualResponse.body, queryEvalTime)
+	return comparator.Compare(expectedResponse.body, actualResponse.body, queryEvalTime)
 }
 
 type backendResponse struct {
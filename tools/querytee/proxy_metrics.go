@@ -19,6 +19,7 @@ type ProxyMetrics struct {
 	responsesTotal         *prometheus.CounterVec
 	responsesComparedTotal *prometheus.CounterVec
 	missingMetrics         *prometheus.HistogramVec
+	mismatchesCaptured     *prometheus.CounterVec
 }
 
 func NewProxyMetrics(registerer prometheus.Registerer) *ProxyMetrics {
@@ -45,6 +46,11 @@ func NewProxyMetrics(registerer prometheus.Registerer) *ProxyMetrics {
 			Help:      "Number of missing metrics (series) in a vector response.",
 			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 0.75, 1, 1.5, 2, 3, 4, 5, 10, 25, 50, 100},
 		}, []string{"backend", "route", "status_code", "issuer"}),
+		mismatchesCaptured: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cortex_querytee",
+			Name:      "mismatches_captured_total",
+			Help:      "Total number of mismatching responses written to disk per route and backend name.",
+		}, []string{"backend", "route"}),
 	}
 
 	return m
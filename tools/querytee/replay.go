@@ -0,0 +1,119 @@
+package querytee
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// ReplayResult is the outcome of replaying a captured request against a secondary backend.
+type ReplayResult struct {
+	Route   string
+	Request CapturedRequest
+	Backend string
+	// Result is either "success", "fail" or "skipped".
+	Result string
+	Err    error
+}
+
+// Replayer re-issues captured requests against the configured backends and compares the
+// response of each secondary backend with the response of the preferred backend, the
+// same way the proxy does.
+type Replayer struct {
+	backends    []*ProxyBackend
+	comparators map[string]ResponsesComparator
+	logger      log.Logger
+}
+
+func NewReplayer(cfg ProxyConfig, routes []Route, logger log.Logger) (*Replayer, error) {
+	if cfg.PreferredBackend == "" {
+		return nil, fmt.Errorf("when replaying requests -backend.preferred flag must be set to hostname of preferred backend")
+	}
+
+	backends, err := newProxyBackends(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(backends) < 2 {
+		return nil, fmt.Errorf("when replaying requests number of backends should be at least 2")
+	}
+
+	comparators := make(map[string]ResponsesComparator, len(routes))
+	for _, route := range routes {
+		if route.ResponseComparator != nil {
+			comparators[route.RouteName] = route.ResponseComparator
+		}
+	}
+
+	return &Replayer{
+		backends:    backends,
+		comparators: comparators,
+		logger:      logger,
+	}, nil
+}
+
+// Replay sends a captured request to all backends and returns the comparison result of
+// each secondary backend.
+func (r *Replayer) Replay(m CapturedMismatch) ([]ReplayResult, error) {
+	comparator, ok := r.comparators[m.Route]
+	if !ok {
+		return nil, fmt.Errorf("route %s has no response comparator", m.Route)
+	}
+
+	req, err := http.NewRequest(m.Request.Method, (&url.URL{Path: m.Request.Path, RawQuery: m.Request.Query}).String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create request")
+	}
+	req.Header = m.Request.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+
+	var (
+		expectedResponse *backendResponse
+		responses        = make([]*backendResponse, 0, len(r.backends))
+	)
+	for _, b := range r.backends {
+		var body io.ReadCloser
+		if m.Request.Body != "" {
+			body = io.NopCloser(bytes.NewReader([]byte(m.Request.Body)))
+		}
+
+		status, resBody, err := b.ForwardRequest(req, body)
+		res := &backendResponse{backend: b, status: status, body: resBody, err: err}
+		level.Debug(r.logger).Log("msg", "Backend response", "path", m.Request.Path, "query", m.Request.Query, "backend", b.name, "status", status)
+
+		if b.preferred {
+			expectedResponse = res
+			continue
+		}
+		responses = append(responses, res)
+	}
+
+	results := make([]ReplayResult, 0, len(responses))
+	for _, actualResponse := range responses {
+		result := ReplayResult{
+			Route:   m.Route,
+			Request: m.Request,
+			Backend: actualResponse.backend.name,
+			Result:  comparisonSuccess,
+		}
+
+		summary, err := compareResponses(comparator, expectedResponse, actualResponse, time.Now().UTC())
+		if err != nil {
+			result.Result = comparisonFailed
+			result.Err = err
+		} else if summary != nil && summary.skipped {
+			result.Result = comparisonSkipped
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,96 @@
+package querytee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	prom_testutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestCaptureAndReplayMismatches(t *testing.T) {
+	var secondaryLabels atomic.String
+	secondaryLabels.Store(`{"status":"success","data":["app"]}`)
+
+	preferred := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":["app","env"]}`))
+	}))
+	defer preferred.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(secondaryLabels.Load()))
+	}))
+	defer secondary.Close()
+
+	preferredURL, err := url.Parse(preferred.URL)
+	require.NoError(t, err)
+	secondaryURL, err := url.Parse(secondary.URL)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	capturer, err := NewMismatchCapturer(dir, 1)
+	require.NoError(t, err)
+
+	metrics := NewProxyMetrics(prometheus.NewRegistry())
+	backends := []*ProxyBackend{
+		NewProxyBackend("preferred", preferredURL, time.Second, true),
+		NewProxyBackend("secondary", secondaryURL, time.Second, false),
+	}
+	endpoint := NewProxyEndpoint(backends, "api_v1_labels", metrics, log.NewNopLogger(), NewLabelsComparator(), false).WithMismatchCapturer(capturer)
+
+	// Only the first mismatch is captured because of the limit.
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest("GET", "/loki/api/v1/labels?start=1&end=2", nil)
+		r.Header.Set("X-Scope-OrgID", "tenant")
+		r.Header.Set("Authorization", "Bearer secret")
+		endpoint.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	require.Eventually(t, func() bool {
+		return prom_testutil.ToFloat64(metrics.responsesComparedTotal) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	mismatches, err := ReadCapturedMismatches(dir)
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+
+	m := mismatches[0]
+	require.Equal(t, "api_v1_labels", m.Route)
+	require.Equal(t, "GET", m.Request.Method)
+	require.Equal(t, "/loki/api/v1/labels", m.Request.Path)
+	require.Equal(t, "start=1&end=2", m.Request.Query)
+	require.Equal(t, "tenant", m.Request.Header.Get("X-Scope-OrgID"))
+	require.Empty(t, m.Request.Header.Get("Authorization"))
+	require.Equal(t, CapturedResponse{Backend: "preferred", Status: 200, Body: `{"status":"success","data":["app","env"]}`}, m.Expected)
+	require.Equal(t, CapturedResponse{Backend: "secondary", Status: 200, Body: `{"status":"success","data":["app"]}`}, m.Actual)
+	require.Equal(t, "expected label values [env] missing from actual response", m.Error)
+	require.Equal(t, 1.0, prom_testutil.ToFloat64(metrics.mismatchesCaptured))
+
+	replayer, err := NewReplayer(ProxyConfig{
+		BackendEndpoints:   preferred.URL + "," + secondary.URL,
+		PreferredBackend:   "0",
+		BackendReadTimeout: time.Second,
+	}, []Route{{RouteName: "api_v1_labels", ResponseComparator: NewLabelsComparator()}}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	results, err := replayer.Replay(m)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, comparisonFailed, results[0].Result)
+	require.EqualError(t, results[0].Err, "expected label values [env] missing from actual response")
+
+	// Once the secondary backend is fixed the replayed request matches.
+	secondaryLabels.Store(`{"status":"success","data":["env","app"]}`)
+	results, err = replayer.Replay(m)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, comparisonSuccess, results[0].Result)
+	require.NoError(t, results[0].Err)
+
+	_, err = replayer.Replay(CapturedMismatch{Route: "unknown"})
+	require.EqualError(t, err, "route unknown has no response comparator")
+}